@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func applyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "apply",
+		Usage: "Reconcile database entries from a file",
+		Description: `Create or update the database entries described in a file, for
+   configuration management tools such as Ansible or Terraform. Entries
+   have the same fields as in config.yaml; take passwords from password_env
+   or password_file. Applying the same file again changes nothing.
+
+   Entries in the config but not in the file are left alone, unless
+   --prune is given.
+
+   EXAMPLE databases.yaml:
+     databases:
+       shop:
+         type: mysql
+         host: db.example.com
+         port: 3306
+         user: backup
+         database: shop
+         password_file: /run/secrets/shop_password
+
+   USAGE:
+     cadangkan apply -f databases.yaml
+     cadangkan apply -f databases.yaml --dry-run   # Show what would change
+     cadangkan apply -f databases.yaml --prune     # Also remove other entries`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Databases file to apply",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "prune",
+				Usage: "Remove database entries that are not in the file",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would change without saving",
+			},
+		},
+		Action: runApply,
+	}
+}
+
+func runApply(c *cli.Context) error {
+	path := c.String("file")
+	desired, err := config.LoadDatabasesFile(path)
+	if err != nil {
+		return err
+	}
+
+	for name, db := range desired {
+		if db.Port == 0 {
+			if eng, err := backup.GetEngine(db.Type); err == nil {
+				db.Port = eng.DefaultPort()
+			}
+		}
+		if err := db.Validate(); err != nil {
+			return fmt.Errorf("database '%s': %w", name, err)
+		}
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	prune := c.Bool("prune")
+	if c.Bool("dry-run") {
+		changes := cfg.PlanApply(desired, prune)
+		printApplyChanges(changes)
+		if !config.HasChanges(changes) {
+			printInfo("Configuration is up to date")
+		} else {
+			printInfo("Dry run: no changes saved")
+		}
+		return nil
+	}
+
+	changes := cfg.Apply(desired, prune)
+	if !config.HasChanges(changes) {
+		printApplyChanges(changes)
+		printInfo("Configuration is up to date")
+		return nil
+	}
+
+	err = mgr.Save(cfg)
+	for _, change := range changes {
+		if action := applyAuditAction(change.Action); action != "" {
+			recordAudit(action, change.Name, "", err, "apply "+path)
+		}
+	}
+	if err != nil {
+		printError("Failed to save configuration")
+		return err
+	}
+
+	printApplyChanges(changes)
+	printSuccess(fmt.Sprintf("Applied %s", path))
+	return nil
+}
+
+// printApplyChanges lists what applying a file does to each entry.
+func printApplyChanges(changes []config.ApplyChange) {
+	for _, change := range changes {
+		color := ""
+		switch change.Action {
+		case config.ApplyCreate:
+			color = colorGreen
+		case config.ApplyUpdate:
+			color = colorYellow
+		case config.ApplyRemove:
+			color = colorRed
+		}
+		fmt.Printf("  %s%-10s%s %s\n", color, change.Action, colorReset, change.Name)
+	}
+}
+
+// applyAuditAction returns the audit action recording a change, or "" for
+// entries left unchanged.
+func applyAuditAction(action string) string {
+	switch action {
+	case config.ApplyCreate:
+		return audit.ActionConfigAdd
+	case config.ApplyUpdate:
+		return audit.ActionConfigEdit
+	case config.ApplyRemove:
+		return audit.ActionConfigRemove
+	}
+	return ""
+}
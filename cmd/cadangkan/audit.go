@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+func auditCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "audit",
+		Usage:     "Show the audit log of destructive operations",
+		ArgsUsage: "[name]",
+		Description: `Show restores, imports, prunes, locks and config changes recorded
+   in the audit log (default: audit.log in the data directory).
+
+   Entries can also be forwarded to syslog by setting in config.yaml:
+
+     audit:
+       syslog: true
+       syslog_tag: cadangkan
+
+   USAGE:
+     cadangkan audit                       # Show the most recent entries
+     cadangkan audit <name>                # Only entries for one database
+     cadangkan audit --action restore      # Only restores
+     cadangkan audit --format=json         # Output as JSON lines`,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "limit",
+				Value: 50,
+				Usage: "Show at most N most recent entries (0 for all)",
+			},
+			&cli.StringFlag{
+				Name:  "action",
+				Usage: "Only show entries for this action",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "table",
+				Usage: "Output format: table (default) or json",
+			},
+		},
+		Action: runAudit,
+	}
+}
+
+func runAudit(c *cli.Context) error {
+	format := c.String("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", format)
+	}
+
+	logger, err := auditLogger()
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	entries, err := audit.ReadEntries(logger.Path())
+	if err != nil {
+		return err
+	}
+
+	// Apply filters
+	database := c.Args().Get(0)
+	action := c.String("action")
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if database != "" && entry.Database != database {
+			continue
+		}
+		if action != "" && entry.Action != action {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	if limit := c.Int("limit"); limit > 0 && len(filtered) > limit {
+		filtered = filtered[len(filtered)-limit:]
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range filtered {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(filtered) == 0 {
+		printInfo("No audit entries found")
+		return nil
+	}
+
+	fmt.Printf("\n%sAudit log%s %s\n", colorCyan, colorReset, logger.Path())
+	fmt.Println(strings.Repeat("=", 110))
+	fmt.Printf("%-20s %-16s %-14s %-15s %-20s %-8s\n", "TIME", "USER", "ACTION", "DATABASE", "TARGET", "RESULT")
+	fmt.Println(strings.Repeat("-", 110))
+
+	for _, entry := range filtered {
+		result := colorGreen + entry.Result + colorReset
+		if entry.Result == audit.ResultFailure {
+			result = colorRed + entry.Result + colorReset
+		}
+		fmt.Printf("%-20s %-16s %-14s %-15s %-20s %s\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.User,
+			entry.Action,
+			entry.Database,
+			entry.Target,
+			result,
+		)
+		if entry.Error != "" {
+			fmt.Printf("  %serror:%s %s\n", colorRed, colorReset, entry.Error)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Showing %d of %d entries\n", len(filtered), len(entries))
+	return nil
+}
+
+// auditLogger creates the audit logger configured in config.yaml.
+func auditLogger() (*audit.Logger, error) {
+	var cfg *config.Config
+	if mgr, err := config.NewManager(); err == nil {
+		cfg, _ = mgr.Load()
+	}
+
+	logger, err := audit.FromConfig(cfg)
+	if err != nil && logger == nil {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return logger, nil
+}
+
+// recordAudit appends an entry to the audit log. Failures to write the
+// log are reported as warnings and never fail the audited operation.
+func recordAudit(action, database, target string, opErr error, details string) {
+	logger, err := auditLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to open audit log: %v\n", err)
+		return
+	}
+	defer logger.Close()
+
+	entry := audit.NewEntry(action, database, target, opErr)
+	entry.Details = details
+	if err := logger.Record(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write audit log: %v\n", err)
+	}
+}
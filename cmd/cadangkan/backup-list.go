@@ -3,16 +3,23 @@ package main
 import (
 	"fmt"
 	"os"
-	"sort"
-	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/urfave/cli/v2"
 )
 
+// backupListColumns are the columns of 'cadangkan backup-list'.
+var backupListColumns = []tableColumn{
+	{key: "id", title: "BACKUP ID", width: 20},
+	{key: "database", title: "DATABASE", width: 20, hidden: true},
+	{key: "date", title: "DATE", width: 20},
+	{key: "size", title: "SIZE", width: 12},
+	{key: "status", title: "STATUS", width: 12},
+	{key: "path", title: "PATH", width: 60, hidden: true},
+}
+
 // databaseBackups holds backups for a single database
 type databaseBackups struct {
 	database string
@@ -29,14 +36,21 @@ func backupListCommand() *cli.Command {
    USAGE:
      cadangkan backup-list                    # List backups for all databases
      cadangkan backup-list <database-name>    # List backups for specific database
-     cadangkan backup-list --format=json      # Output in JSON format`,
-		Flags: []cli.Flag{
+     cadangkan backup-list --format=json      # Output in JSON format
+
+   Filters apply to the backups of each database:
+     cadangkan backup-list --status failed --since 7d
+     cadangkan backup-list --sort size --limit 5 production
+     cadangkan backup-list --columns id,size,path production`,
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:  "format",
 				Value: "table",
 				Usage: "Output format: table (default) or json",
 			},
-		},
+			absoluteFlag(),
+		}, listFlags(sortDate, backupListColumns)...),
+		Before: applyAbsoluteFlag,
 		Action: runBackupList,
 	}
 }
@@ -46,9 +60,13 @@ func runBackupList(c *cli.Context) error {
 	if format != "table" && format != "json" {
 		return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", format)
 	}
+	opts, err := parseListOptions(c, backupListColumns)
+	if err != nil {
+		return err
+	}
 
 	// Create storage and backup service
-	storageInstance, err := storage.NewLocalStorage("")
+	storageInstance, err := newLocalStorage("")
 	if err != nil {
 		return fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -73,9 +91,13 @@ func runBackupList(c *cli.Context) error {
 
 	// If specific database requested, verify it exists
 	if targetDatabase != "" {
-		if _, exists := cfg.Databases[targetDatabase]; !exists {
+		dbConfig, exists := cfg.Database(targetDatabase)
+		if !exists {
 			return fmt.Errorf("database '%s' not found in configuration", targetDatabase)
 		}
+		if err := requireDatabase(dbConfig); err != nil {
+			return err
+		}
 	}
 
 	// Collect all backups
@@ -83,7 +105,7 @@ func runBackupList(c *cli.Context) error {
 
 	if targetDatabase != "" {
 		// List backups for specific database
-		backups, err := storageInstance.ListBackups(targetDatabase)
+		backups, err := storageInstance.At(cfg.GetEffectiveStoragePath(targetDatabase)).ListBackups(targetDatabase)
 		if err != nil {
 			return fmt.Errorf("failed to list backups for '%s': %w", targetDatabase, err)
 		}
@@ -100,6 +122,7 @@ func runBackupList(c *cli.Context) error {
 				Status:       entry.Status,
 				FilePath:     entry.FilePath,
 				MetadataPath: entry.MetadataPath,
+				Lock:         entry.Lock,
 			}
 		}
 
@@ -111,14 +134,13 @@ func runBackupList(c *cli.Context) error {
 		}
 	} else {
 		// List backups for all databases
-		dbNames := make([]string, 0, len(cfg.Databases))
-		for name := range cfg.Databases {
-			dbNames = append(dbNames, name)
+		stored, err := storageInstance.ListDatabasesIn(cfg.StoragePaths())
+		if err != nil {
+			return fmt.Errorf("failed to list backup directories: %w", err)
 		}
-		sort.Strings(dbNames)
 
-		for _, dbName := range dbNames {
-			backups, err := storageInstance.ListBackups(dbName)
+		for _, dbName := range cfg.BackupNames(stored) {
+			backups, err := storageInstance.At(cfg.GetEffectiveStoragePath(dbName)).ListBackups(dbName)
 			if err != nil {
 				// Log error but continue with other databases
 				fmt.Fprintf(os.Stderr, "Warning: failed to list backups for '%s': %v\n", dbName, err)
@@ -137,6 +159,7 @@ func runBackupList(c *cli.Context) error {
 					Status:       entry.Status,
 					FilePath:     entry.FilePath,
 					MetadataPath: entry.MetadataPath,
+					Lock:         entry.Lock,
 				}
 			}
 
@@ -149,15 +172,46 @@ func runBackupList(c *cli.Context) error {
 		}
 	}
 
+	// Apply sorting and filters to each database
+	filtered := allBackups[:0]
+	for _, dbBackups := range allBackups {
+		dbBackups.backups = filterBackups(opts, dbBackups.backups)
+		if len(dbBackups.backups) > 0 {
+			filtered = append(filtered, dbBackups)
+		}
+	}
+	allBackups = filtered
+
 	// Output results
 	if format == "json" {
 		return outputBackupsJSON(allBackups)
 	}
 
-	return outputBackupsTable(allBackups, targetDatabase)
+	return outputBackupsTable(allBackups, targetDatabase, opts.columns)
 }
 
-func outputBackupsTable(allBackups []databaseBackups, targetDatabase string) error {
+// filterBackups applies the sorting and filters of opts to backups.
+func filterBackups(opts *listOptions, backups []backup.BackupListEntry) []backup.BackupListEntry {
+	byID := make(map[string]backup.BackupListEntry, len(backups))
+	rows := make([]listRow, 0, len(backups))
+	for _, b := range backups {
+		status := b.Status
+		if status == "" {
+			status = "completed"
+		}
+		byID[b.BackupID] = b
+		rows = append(rows, listRow{name: b.BackupID, date: b.CreatedAt, size: b.SizeBytes, status: status})
+	}
+
+	rows = opts.apply(rows)
+	result := make([]backup.BackupListEntry, len(rows))
+	for i, row := range rows {
+		result[i] = byID[row.name]
+	}
+	return result
+}
+
+func outputBackupsTable(allBackups []databaseBackups, targetDatabase string, columns []tableColumn) error {
 	if len(allBackups) == 0 {
 		if targetDatabase != "" {
 			printInfo(fmt.Sprintf("No backups found for database '%s'", targetDatabase))
@@ -180,27 +234,25 @@ func outputBackupsTable(allBackups []databaseBackups, targetDatabase string) err
 			if i > 0 {
 				fmt.Println()
 			}
-			printBackupsForDatabase(dbBackups.database, dbBackups.backups)
+			printBackupsForDatabase(dbBackups.database, dbBackups.backups, columns)
 		}
 		fmt.Println()
 		fmt.Printf("Total: %d backup(s) across %d database(s)\n", totalBackups, len(allBackups))
 	} else {
 		// Single database view
 		dbBackups := allBackups[0]
-		printBackupsForDatabase(dbBackups.database, dbBackups.backups)
+		printBackupsForDatabase(dbBackups.database, dbBackups.backups, columns)
 	}
 
 	return nil
 }
 
-func printBackupsForDatabase(database string, backups []backup.BackupListEntry) {
+func printBackupsForDatabase(database string, backups []backup.BackupListEntry, columns []tableColumn) {
 	fmt.Printf("\n%sBackups for %s%s\n", colorCyan, colorReset, database)
-	fmt.Println(strings.Repeat("=", 100))
-	fmt.Printf("%-20s %-20s %-12s %-12s\n", "BACKUP ID", "DATE", "SIZE", "STATUS")
-	fmt.Println(strings.Repeat("-", 100))
 
+	rows := make([]listRow, 0, len(backups))
 	for _, b := range backups {
-		dateStr := b.CreatedAt.Format("2006-01-02 15:04:05")
+		dateStr := formatTimeAgo(b.CreatedAt)
 		sizeStr := b.SizeHuman
 		if sizeStr == "" {
 			sizeStr = backup.FormatBytes(b.SizeBytes)
@@ -210,9 +262,20 @@ func printBackupsForDatabase(database string, backups []backup.BackupListEntry)
 		if statusStr == "" {
 			statusStr = "completed"
 		}
+		if b.Lock.Active(time.Now()) {
+			statusStr += " (locked)"
+		}
 
-		fmt.Printf("%-20s %-20s %-12s %-12s\n", b.BackupID, dateStr, sizeStr, statusStr)
+		rows = append(rows, listRow{cells: map[string]string{
+			"id":       b.BackupID,
+			"database": database,
+			"date":     dateStr,
+			"size":     sizeStr,
+			"status":   statusStr,
+			"path":     b.FilePath,
+		}})
 	}
+	printTable(columns, rows)
 
 	fmt.Println()
 	fmt.Printf("Total: %d backup(s)\n", len(backups))
@@ -241,8 +304,9 @@ func outputBackupsJSON(allBackups []databaseBackups) error {
       "size_bytes": %d,
       "size_human": "%s",
       "status": "%s",
+      "locked": %t,
       "file_path": "%s"
-    }`, b.BackupID, b.Database, dateStr, b.SizeBytes, sizeStr, b.Status, b.FilePath)
+    }`, b.BackupID, b.Database, dateStr, b.SizeBytes, sizeStr, b.Status, b.Lock.Active(time.Now()), b.FilePath)
 		}
 	}
 
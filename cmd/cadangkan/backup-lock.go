@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/urfave/cli/v2"
+)
+
+func backupLockCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "backup-lock",
+		Usage:     "Protect a backup from cleanup and deletion",
+		ArgsUsage: "<name> <backup-id>",
+		Description: `Lock a backup so retention cleanup and delete refuse to remove it.
+   Useful for compliance snapshots and pre-migration restore points.
+
+   USAGE:
+     cadangkan backup-lock mydb 2025-01-15-143022                     # Lock indefinitely
+     cadangkan backup-lock mydb 2025-01-15-143022 --until 2026-01-01  # Lock until a date
+     cadangkan backup-lock mydb 2025-01-15-143022 --unlock            # Remove the lock`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "Lock expiry date (YYYY-MM-DD or RFC3339); default is no expiry",
+			},
+			&cli.StringFlag{
+				Name:  "reason",
+				Usage: "Note explaining why the backup is locked",
+			},
+			&cli.BoolFlag{
+				Name:  "unlock",
+				Usage: "Remove an existing lock",
+			},
+		},
+		Action: runBackupLock,
+	}
+}
+
+func runBackupLock(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return fmt.Errorf("usage: cadangkan backup-lock <name> <backup-id> [--until YYYY-MM-DD]")
+	}
+
+	name := c.Args().Get(0)
+	backupID := c.Args().Get(1)
+	unlock := c.Bool("unlock")
+
+	if unlock && (c.IsSet("until") || c.IsSet("reason")) {
+		return fmt.Errorf("--unlock cannot be combined with --until or --reason")
+	}
+
+	var lock *storage.LockInfo
+	if !unlock {
+		lock = &storage.LockInfo{
+			LockedAt: time.Now(),
+			Reason:   c.String("reason"),
+		}
+		if c.IsSet("until") {
+			until, err := parseLockUntil(c.String("until"))
+			if err != nil {
+				return err
+			}
+			if !until.After(lock.LockedAt) {
+				return fmt.Errorf("--until must be in the future")
+			}
+			lock.Until = &until
+		}
+	}
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		printError(i18n.T("Failed to create storage"))
+		return err
+	}
+
+	err = localStorage.SetBackupLock(name, backupID, lock)
+	if unlock {
+		recordAudit(audit.ActionUnlock, name, backupID, err, "")
+	} else {
+		recordAudit(audit.ActionLock, name, backupID, err, lock.Reason)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrBackupNotFound) {
+			printError(fmt.Sprintf("Backup '%s' not found for '%s'", backupID, name))
+			fmt.Println()
+			fmt.Printf("List backups with: %scadangkan backup-list %s%s\n", colorCyan, name, colorReset)
+		}
+		return err
+	}
+
+	if unlock {
+		printSuccess(fmt.Sprintf("Backup '%s' unlocked", backupID))
+		return nil
+	}
+
+	if lock.Until != nil {
+		printSuccess(fmt.Sprintf("Backup '%s' locked until %s", backupID, lock.Until.Format("2006-01-02 15:04:05")))
+	} else {
+		printSuccess(fmt.Sprintf("Backup '%s' locked", backupID))
+	}
+	return nil
+}
+
+// parseLockUntil parses a lock expiry as a local date or an RFC3339 timestamp.
+func parseLockUntil(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --until value: %s (use YYYY-MM-DD or RFC3339)", value)
+}
@@ -2,13 +2,20 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 )
 
 func backupCommand() *cli.Command {
@@ -21,39 +28,71 @@ func backupCommand() *cli.Command {
    USAGE MODES:
      1. Named mode (from config):
         cadangkan backup <name>
+
+        A server entry backs up each of its databases in turn, stored as
+        <name>.<database>.
         
      2. Direct mode (with flags):
         cadangkan backup --host=<host> --user=<user> --database=<db> --password=<pass>
 
-   Flags can override config values when using named mode.`,
+        --databases backs up several databases into one archive, with their
+        CREATE DATABASE statements, stored as <db1>+<db2>+...:
+
+        cadangkan backup --host=<host> --user=<user> --databases=shop,stats
+
+   Flags can override config values when using named mode. Connection flags
+   can also be set through CADANGKAN_HOST, CADANGKAN_PORT, CADANGKAN_USER,
+   CADANGKAN_DATABASE, CADANGKAN_PASSWORD and CADANGKAN_PASSWORD_FILE, as in
+   the manifests of 'cadangkan k8s generate'.
+
+   STREAMING:
+     Use --output - to write the compressed dump to stdout instead of managed
+     storage. Status messages go to stderr and no metadata is saved.
+
+        cadangkan backup mydb --output - | aws s3 cp - s3://bucket/mydb.sql.gz
+        cadangkan backup mydb --output - | age -r <recipient> > mydb.sql.gz.age`,
 		Flags: []cli.Flag{
 			// Database type
 			&cli.StringFlag{
 				Name:  "type",
-				Value: "mysql",
-				Usage: "Database type (mysql)",
+				Value: backup.EngineTypeMySQL,
+				Usage: fmt.Sprintf("Database type (%s)", strings.Join(backup.EngineNames(), "|")),
 			},
 
 			// Connection flags (now optional for named mode)
 			&cli.StringFlag{
-				Name:  "host",
-				Usage: "Database host (overrides config)",
+				Name:    "host",
+				Usage:   "Database host (overrides config)",
+				EnvVars: []string{"CADANGKAN_HOST"},
 			},
 			&cli.IntFlag{
-				Name:  "port",
-				Usage: "Database port (overrides config)",
+				Name:    "port",
+				Usage:   "Database port (overrides config)",
+				EnvVars: []string{"CADANGKAN_PORT"},
 			},
 			&cli.StringFlag{
-				Name:  "user",
-				Usage: "Database user (overrides config)",
+				Name:    "user",
+				Usage:   "Database user (overrides config)",
+				EnvVars: []string{"CADANGKAN_USER"},
 			},
 			&cli.StringFlag{
-				Name:  "password",
-				Usage: "Database password (overrides config)",
+				Name:    "password",
+				Usage:   "Database password (overrides config)",
+				EnvVars: []string{"CADANGKAN_PASSWORD"},
 			},
 			&cli.StringFlag{
-				Name:  "database",
-				Usage: "Database name (overrides config)",
+				Name:    "password-file",
+				Usage:   "File holding the database password, e.g. a mounted secret (overrides config)",
+				EnvVars: []string{"CADANGKAN_PASSWORD_FILE"},
+			},
+			&cli.StringFlag{
+				Name:    "database",
+				Usage:   "Database name (overrides config)",
+				EnvVars: []string{"CADANGKAN_DATABASE"},
+			},
+			&cli.StringSliceFlag{
+				Name:  "databases",
+				Usage: "Databases to backup into one archive (comma-separated, direct mode only)",
 			},
 
 			// Backup options
@@ -74,30 +113,122 @@ func backupCommand() *cli.Command {
 				Value: "gzip",
 				Usage: "Compression type (gzip|none)",
 			},
+			&cli.StringFlag{
+				Name:  "engine",
+				Value: backup.EngineMysqldump,
+				Usage: "Dump engine (mysqldump|mydumper)",
+			},
+			&cli.IntFlag{
+				Name:  "threads",
+				Usage: "Parallel dump threads (mydumper only)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "mysqldump-arg",
+				Usage: "Extra mysqldump flag, e.g. --mysqldump-arg=--hex-blob (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "mysqldump-disable",
+				Usage: "Default mysqldump flag to leave out, e.g. set-gtid-purged (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "mysqldump-path",
+				Usage: "mysqldump program to run, e.g. /opt/mysql-8.4/bin/mysqldump (overrides config)",
+			},
+			&cli.StringFlag{
+				Name:  "version-check",
+				Usage: "When mysqldump is older than the server or of another flavor: warn, fail or ignore (overrides config)",
+			},
+			&cli.StringFlag{
+				Name:  "definers",
+				Usage: "DEFINER clauses of views, triggers and routines: keep, strip or an account user@host (overrides config)",
+			},
+			&cli.BoolFlag{
+				Name:  "sql-security-invoker",
+				Usage: "Rewrite SQL SECURITY DEFINER views and routines to SQL SECURITY INVOKER (overrides config)",
+			},
+			&cli.BoolFlag{
+				Name:  "indexed",
+				Usage: "Write gzip output as indexed blocks for fast single-table reads",
+			},
 			&cli.StringFlag{
 				Name:  "output",
 				Value: "",
-				Usage: "Output directory (default: ~/.cadangkan/backups)",
+				Usage: "Output directory (default: backups in the data directory), or - for stdout",
 			},
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
 				Usage:   "Show verbose output including mysqldump command",
 			},
+			eventsFlag(),
 		},
-		Action: runBackup,
+		Action: withEvents("backup", runBackup),
 	}
 }
 
 func runBackup(c *cli.Context) error {
-	var host, user, password, database, configName string
+	name := c.Args().Get(0)
+	if name == "" {
+		return backupDatabase(c, "")
+	}
+
+	// A server entry is backed up one schema at a time
+	schemas, err := configSchemas(name)
+	if err != nil {
+		return err
+	}
+	if schemas == nil {
+		return backupDatabase(c, name)
+	}
+	if c.String("output") == "-" {
+		return fmt.Errorf("--output - cannot stream the backups of server entry '%s'; back up one of its databases, e.g. %s", name, config.SchemaName(name, schemas[0]))
+	}
+	if c.IsSet("database") || c.IsSet("databases") {
+		return fmt.Errorf("--database and --databases cannot be used with server entry '%s'", name)
+	}
+
+	var failed []string
+	for i, schema := range schemas {
+		schemaName := config.SchemaName(name, schema)
+		printInfo(fmt.Sprintf("Backing up %s (%d of %d)", schemaName, i+1, len(schemas)))
+		if err := backupDatabase(c, schemaName); err != nil {
+			printError(err.Error())
+			failed = append(failed, schemaName)
+		}
+		fmt.Fprintln(msgOut)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d backups failed: %s", len(failed), len(schemas), strings.Join(failed, ", "))
+	}
+	printSuccess(fmt.Sprintf("Backed up %d database(s) of '%s'", len(schemas), name))
+	return nil
+}
+
+// backupDatabase backs up the configured database name, or the database
+// given by flags when name is empty.
+func backupDatabase(c *cli.Context, name string) error {
+	var host, user, password, database, configName, mysqldumpPath, versionCheck, definers string
 	var port int
+	var sqlSecurityInvoker bool
+	var ssl *mysql.SSLConfig
 	var usingConfig bool
+	var dumpArgs, disableDumpDefaults, databases []string
+	var warningPolicy *backup.WarningPolicy
+	var longQueryPolicy *backup.LongQueryPolicy
+	var lockMonitorPolicy *backup.LockMonitorPolicy
+
+	// Streaming to stdout keeps status messages off the data stream
+	streaming := c.String("output") == "-"
+	if streaming {
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("refusing to write backup data to a terminal; pipe or redirect stdout")
+		}
+		msgOut = os.Stderr
+	}
 
 	// Check if using named mode (config) or direct mode (flags)
-	if c.NArg() > 0 {
+	if name != "" {
 		// Named mode - load from config
-		name := c.Args().Get(0)
 		configName = name
 		usingConfig = true
 
@@ -109,9 +240,9 @@ func runBackup(c *cli.Context) error {
 		dbConfig, err := mgr.GetDatabase(name)
 		if err != nil {
 			printError(fmt.Sprintf("Database '%s' not found in config", name))
-			fmt.Println()
-			fmt.Printf("Available databases: run %scadangkan list%s\n", colorCyan, colorReset)
-			fmt.Printf("Add a database:      run %scadangkan add mysql %s%s\n", colorCyan, name, colorReset)
+			fmt.Fprintln(msgOut)
+			fmt.Fprintf(msgOut, "Available databases: run %scadangkan list%s\n", colorCyan, colorReset)
+			fmt.Fprintf(msgOut, "Add a database:      run %scadangkan add mysql %s%s\n", colorCyan, name, colorReset)
 			return err
 		}
 
@@ -120,11 +251,35 @@ func runBackup(c *cli.Context) error {
 		port = dbConfig.Port
 		user = dbConfig.User
 		database = dbConfig.Database
+		ssl = backupconfig.SSL(dbConfig.TLS)
+		if dbConfig.Mysqldump != nil {
+			dumpArgs = append(dumpArgs, dbConfig.Mysqldump.ExtraArgs...)
+			disableDumpDefaults = append(disableDumpDefaults, dbConfig.Mysqldump.DisableDefaults...)
+			definers = dbConfig.Mysqldump.Definers
+			sqlSecurityInvoker = dbConfig.Mysqldump.SQLSecurity == "invoker"
+		}
+		warningPolicy, err = backupconfig.WarningPolicy(dbConfig.Mysqldump)
+		if err != nil {
+			return err
+		}
+		longQueryPolicy, err = backupconfig.LongQueryPolicy(dbConfig.LongQueries)
+		if err != nil {
+			return err
+		}
+		lockMonitorPolicy, err = backupconfig.LockMonitorPolicy(dbConfig.LockMonitor)
+		if err != nil {
+			return err
+		}
 
-		// Decrypt password
-		password, err = config.DecryptPassword(dbConfig.PasswordEncrypted)
+		// Resolve password
+		password, err = dbConfig.Password()
 		if err != nil {
-			return fmt.Errorf("failed to decrypt password: %w", err)
+			return err
+		}
+
+		if cfg, err := mgr.Load(); err == nil {
+			mysqldumpPath = cfg.GetEffectiveMysqldumpPath(name)
+			versionCheck = cfg.GetEffectiveVersionCheck(name)
 		}
 
 		printInfo(fmt.Sprintf("Using configuration for '%s'", name))
@@ -135,6 +290,19 @@ func runBackup(c *cli.Context) error {
 		user = c.String("user")
 		password = c.String("password")
 		database = c.String("database")
+		databases = c.StringSlice("databases")
+		if len(databases) > 0 {
+			if database != "" {
+				return fmt.Errorf("--database and --databases cannot be used together")
+			}
+			database = backup.SchemaSetName(databases)
+		}
+		if path := c.String("password-file"); path != "" {
+			var err error
+			if password, err = config.ReadPasswordFile(path); err != nil {
+				return err
+			}
+		}
 
 		// Validate required flags for direct mode
 		if host == "" {
@@ -144,7 +312,7 @@ func runBackup(c *cli.Context) error {
 			return fmt.Errorf("--user is required when not using named mode")
 		}
 		if database == "" {
-			return fmt.Errorf("--database is required when not using named mode")
+			return fmt.Errorf("--database or --databases is required when not using named mode")
 		}
 		if port == 0 {
 			port = 3306 // Default port
@@ -164,9 +332,27 @@ func runBackup(c *cli.Context) error {
 	if c.IsSet("password") && usingConfig {
 		password = c.String("password")
 	}
+	if c.IsSet("password-file") && usingConfig {
+		var err error
+		if password, err = config.ReadPasswordFile(c.String("password-file")); err != nil {
+			return err
+		}
+	}
 	if c.IsSet("database") && usingConfig {
 		database = c.String("database")
 	}
+	if c.IsSet("databases") && usingConfig {
+		return fmt.Errorf("--databases is only supported in direct mode")
+	}
+	if c.IsSet("mysqldump-path") {
+		mysqldumpPath = c.String("mysqldump-path")
+	}
+	if c.IsSet("version-check") {
+		versionCheck = c.String("version-check")
+		if err := backup.ValidateVersionCheck(versionCheck); err != nil {
+			return err
+		}
+	}
 
 	// Parse backup options
 	tables := c.StringSlice("tables")
@@ -175,45 +361,104 @@ func runBackup(c *cli.Context) error {
 	compression := c.String("compression")
 	outputDir := c.String("output")
 
-	// Validate database type
-	dbType := c.String("type")
-	if dbType != "mysql" {
-		return fmt.Errorf("unsupported database type: %s (only 'mysql' is supported)", dbType)
+	// Resolve database engine
+	eng, err := backup.GetEngine(c.String("type"))
+	if err != nil {
+		return err
 	}
 
-	// 2. Check for mysqldump availability
-	printInfo("Checking mysqldump availability...")
-	version, err := backup.CheckMySQLDump()
-	if err != nil {
-		printError("mysqldump not found")
-		fmt.Println("\nPlease install MySQL client tools:")
-		fmt.Println("  Ubuntu/Debian: sudo apt-get install mysql-client")
-		fmt.Println("  RHEL/CentOS:   sudo yum install mysql")
-		fmt.Println("  macOS:         brew install mysql-client")
+	engine := c.String("engine")
+	threads := c.Int("threads")
+	if engine != backup.EngineMysqldump && engine != backup.EngineMydumper {
+		return fmt.Errorf("unsupported engine: %s (use 'mysqldump' or 'mydumper')", engine)
+	}
+	if threads > 0 && engine != backup.EngineMydumper {
+		return fmt.Errorf("--threads is only supported with --engine mydumper")
+	}
+	dumpArgs = append(dumpArgs, c.StringSlice("mysqldump-arg")...)
+	disableDumpDefaults = append(disableDumpDefaults, c.StringSlice("mysqldump-disable")...)
+	if (len(dumpArgs) > 0 || len(disableDumpDefaults) > 0) && engine != backup.EngineMysqldump {
+		return fmt.Errorf("mysqldump flags are only supported with --engine mysqldump")
+	}
+	if err := backup.ValidateDumpArgs(dumpArgs, disableDumpDefaults); err != nil {
 		return err
 	}
-	printSuccess(fmt.Sprintf("Found %s", version))
+	if c.IsSet("definers") {
+		definers = c.String("definers")
+	}
+	if c.IsSet("sql-security-invoker") {
+		sqlSecurityInvoker = c.Bool("sql-security-invoker")
+	}
+	if err := backup.ValidateDefiners(definers); err != nil {
+		return err
+	}
+	if ((definers != "" && definers != backup.DefinersKeep) || sqlSecurityInvoker) && engine != backup.EngineMysqldump {
+		return fmt.Errorf("definer rewriting is only supported with --engine mysqldump")
+	}
 
-	// 3. Create MySQL config
+	indexed := c.Bool("indexed")
+	if indexed && (engine != backup.EngineMysqldump || compression != backup.CompressionGzip) {
+		return fmt.Errorf("--indexed requires --engine mysqldump and --compression gzip")
+	}
+	if indexed && streaming {
+		return fmt.Errorf("--indexed cannot be used with --output -")
+	}
+
+	// 2. Create MySQL config; several databases are dumped over a
+	// connection to none of them
+	connectDatabase := database
+	if len(databases) > 0 {
+		connectDatabase = ""
+	}
 	config := &mysql.Config{
-		Host:     host,
-		Port:     port,
-		User:     user,
-		Password: password,
-		Database: database,
-		Timeout:  10 * time.Second,
+		Host:          host,
+		Port:          port,
+		User:          user,
+		Password:      password,
+		Database:      connectDatabase,
+		Timeout:       10 * time.Second,
+		SSL:           ssl,
+		MysqldumpPath: mysqldumpPath,
+	}
+
+	// 3. Check for dump tool availability
+	if engine == backup.EngineMydumper {
+		printInfo("Checking mydumper availability...")
+		version, err := backup.CheckMydumper()
+		if err != nil {
+			printError("mydumper not found")
+			fmt.Fprintln(msgOut, "\nPlease install mydumper:")
+			fmt.Fprintln(msgOut, "  Ubuntu/Debian: sudo apt-get install mydumper")
+			fmt.Fprintln(msgOut, "  macOS:         brew install mydumper")
+			fmt.Fprintln(msgOut, "  Other:         https://github.com/mydumper/mydumper/releases")
+			return err
+		}
+		printSuccess(fmt.Sprintf("Found %s", version))
+	} else {
+		printInfo("Checking dump tool availability...")
+		version, err := eng.DumpToolVersion(backup.MySQLConnection(config))
+		if err != nil {
+			printError(fmt.Sprintf("%s dump tool not found", eng.DisplayName()))
+			fmt.Fprintln(msgOut)
+			for _, line := range eng.InstallHelp() {
+				fmt.Fprintln(msgOut, line)
+			}
+			return err
+		}
+		printSuccess(fmt.Sprintf("Found %s", version))
 	}
 
 	// 4. Create client and connect
+	events.phase("connect")
 	printInfo(fmt.Sprintf("Connecting to %s@%s:%d...", user, host, port))
-	client, err := mysql.NewClient(config)
+	client, err := eng.NewIntrospector(backup.MySQLConnection(config))
 	if err != nil {
-		printError("Failed to create MySQL client")
+		printError(fmt.Sprintf("Failed to create %s client", eng.DisplayName()))
 		return err
 	}
 
 	if err := client.Connect(); err != nil {
-		printError("Connection failed")
+		printError(i18n.T("Connection failed"))
 		return err
 	}
 	defer client.Close()
@@ -223,22 +468,26 @@ func runBackup(c *cli.Context) error {
 	if err != nil {
 		dbVersion = "unknown"
 	}
-	printSuccess(fmt.Sprintf("Connected to database (MySQL %s)", dbVersion))
+	printSuccess(fmt.Sprintf("Connected to database (%s %s)", eng.DisplayName(), dbVersion))
 
-	// 5. Create storage
+	// 5. Create storage (bypassed when streaming)
 	var localStorage *storage.LocalStorage
-	if outputDir != "" {
-		localStorage, err = storage.NewLocalStorage(outputDir)
-	} else {
-		localStorage, err = storage.NewLocalStorage("")
-	}
-	if err != nil {
-		printError("Failed to create storage")
-		return err
+	if !streaming {
+		if outputDir != "" {
+			localStorage, err = newLocalStorage(outputDir)
+		} else {
+			localStorage, err = newDatabaseStorage(name)
+		}
+		if err != nil {
+			printError(i18n.T("Failed to create storage"))
+			return err
+		}
 	}
 
 	// 6. Create backup service
 	service := backup.NewService(client, localStorage, config)
+	service.SetEngine(eng)
+	service.SetLogOutput(msgOut)
 
 	// Enable verbose mode if requested
 	verbose := c.Bool("verbose")
@@ -247,33 +496,162 @@ func runBackup(c *cli.Context) error {
 	}
 
 	// 7. Execute backup with progress
-	printInfo("Starting backup...")
+	events.phase("dump")
+	printInfo(i18n.T("Starting backup..."))
 
 	options := &backup.BackupOptions{
 		Database:      database,
+		Databases:     databases,
 		ConfigName:    configName,
 		Tables:        tables,
 		ExcludeTables: excludeTables,
 		SchemaOnly:    schemaOnly,
 		Compression:   compression,
+		Engine:        engine,
+		Threads:       threads,
+		Indexed:       indexed,
+
+		DumpArgs:            dumpArgs,
+		DisableDumpDefaults: disableDumpDefaults,
+		WarningPolicy:       warningPolicy,
+		LongQueryPolicy:     longQueryPolicy,
+		LockMonitorPolicy:   lockMonitorPolicy,
+		VersionCheck:        versionCheck,
+		Definers:            definers,
+		SQLSecurityInvoker:  sqlSecurityInvoker,
 	}
 
-	// Show a simple progress indicator
-	done := make(chan bool)
-	go showSpinner(done)
+	if streaming {
+		result, err := service.BackupToWriter(options, os.Stdout)
+		if err != nil {
+			printError(i18n.T("Backup failed"))
+			return err
+		}
+
+		printDumpWarnings(result.Warnings)
+		printSuccess("Backup streamed to stdout")
+		fmt.Fprintf(msgOut, "  %sDatabase:%s    %s\n", colorCyan, colorReset, database)
+		fmt.Fprintf(msgOut, "  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(result.SizeBytes))
+		fmt.Fprintf(msgOut, "  %sDuration:%s    %s\n", colorCyan, colorReset, backup.FormatDuration(result.Duration))
+		fmt.Fprintf(msgOut, "  %sChecksum:%s    %s\n", colorCyan, colorReset, result.Checksum)
+		return nil
+	}
 
+	// Apply storage quota
+	storageName := configName
+	if storageName == "" {
+		storageName = database
+	}
+	if err := applyQuota(service, localStorage, storageName, options); err != nil {
+		printError("Failed to apply storage quota")
+		return err
+	}
+
+	// Predict the duration from past backups and warn on overruns
+	stopOverrunAlert := alertOnOverrun(localStorage, storageName, options)
+
+	// Show the bytes dumped while the backup runs
+	bar := startProgress("Backing up", 0)
+	service.SetProgress(bar.update)
+
+	runBackupPlugins(storageName, nil, nil)
 	result, err := service.Backup(options)
-	done <- true
+	bar.stop()
+	stopOverrunAlert()
 
 	if err != nil {
-		printError("Backup failed")
+		printError(i18n.T("Backup failed"))
+		runBackupPlugins(storageName, nil, err)
 		return err
 	}
 
 	// 8. Display results
-	printSuccess("Backup completed!")
-	fmt.Println()
+	printDumpWarnings(result.Warnings)
+	printSuccess(i18n.T("Backup completed!"))
+	fmt.Fprintln(msgOut)
 	formatBackupResult(result, database)
+	events.complete(backupEventResult{
+		BackupID:        result.BackupID,
+		Database:        database,
+		File:            result.FilePath,
+		SizeBytes:       result.SizeBytes,
+		DurationSeconds: result.Duration.Seconds(),
+		Checksum:        result.Checksum,
+		Warnings:        result.Warnings,
+	})
+	runBackupPlugins(storageName, result, nil)
+
+	return nil
+}
+
+// alertOnOverrun shows how long the backup is expected to take, judging by
+// past backups, and warns once it runs longer than the configured multiple
+// of that. The returned function cancels the warning.
+func alertOnOverrun(localStorage *storage.LocalStorage, storageName string, options *backup.BackupOptions) func() {
+	estimate, err := backup.EstimateDuration(localStorage, storageName, options)
+	if err != nil || estimate == nil {
+		return func() {}
+	}
+	printInfo(fmt.Sprintf("Estimated duration: %s", estimate))
+
+	factor := 0.0
+	if mgr, err := config.NewManager(); err == nil {
+		if cfg, err := mgr.Load(); err == nil {
+			factor = cfg.GetOverrunFactor()
+		}
+	}
+	limit := estimate.Limit(factor)
+	timer := time.AfterFunc(limit, func() {
+		printWarning(fmt.Sprintf("Backup has run for %s, longer than expected (%s)",
+			backup.FormatDuration(limit), estimate))
+	})
+	return func() { timer.Stop() }
+}
+
+// printDumpWarnings shows stderr output that the dump tool printed on an
+// otherwise successful backup.
+func printDumpWarnings(warnings []string) {
+	for _, warning := range warnings {
+		printWarning(warning)
+	}
+}
+
+// applyQuota sets the configured storage quota on the service. When the
+// quota action is "prune", old backups are deleted first to make room.
+func applyQuota(service *backup.Service, localStorage *storage.LocalStorage, storageName string, options *backup.BackupOptions) error {
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	policy, err := backupconfig.QuotaPolicy(cfg, storageName)
+	if err != nil || policy == nil {
+		return err
+	}
+	service.SetQuota(&policy.Limits)
+
+	// Backups run during maintenance, but never delete old ones
+	if policy.Prune != (backup.Quota{}) {
+		if err := maintenance.Check("quota pruning"); err != nil {
+			printWarning(err.Error())
+			return nil
+		}
+	}
+
+	result, err := backup.NewRetentionService(localStorage).EnforceQuota(storageName, policy, service.EstimateSize(options))
+	if err != nil {
+		recordAudit(audit.ActionPrune, storageName, "", err, "quota")
+		return err
+	}
+	if len(result.ToDelete) > 0 {
+		recordAudit(audit.ActionPrune, storageName, "", nil, "quota: "+pruneAuditDetails(result))
+		printInfo(fmt.Sprintf("Pruned %d old backup(s) to stay within quota (%s reclaimed)",
+			len(result.ToDelete), backup.FormatBytes(result.SpaceReclaimed)))
+	}
 
 	return nil
 }
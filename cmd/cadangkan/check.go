@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/status"
+	"github.com/urfave/cli/v2"
+)
+
+func checkCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "check",
+		Usage:     "Check backups for monitoring systems (Nagios/Zabbix)",
+		ArgsUsage: "[flags] <name>",
+		Description: `Check that a database has recent backups, printing one line and
+   exiting with the Nagios plugin codes: 0 OK, 1 WARNING, 2 CRITICAL,
+   3 UNKNOWN. Performance data (backup age and count) follows the "|".
+
+   Without --max-age, the database's freshness SLA from the config is used.
+
+   USAGE:
+     cadangkan check --max-age 26h --min-count 1 production
+     cadangkan check --warn-age 25h --max-age 49h production
+
+   OUTPUT:
+     CADANGKAN OK - production: last backup 3h 12m ago, 14 backups | age=11520s;;93600 count=14;;1:`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "warn-age",
+				Usage: "Warn when the last successful backup is older than this, e.g. 25h",
+			},
+			&cli.StringFlag{
+				Name:  "max-age",
+				Usage: "Critical when the last successful backup is older than this, e.g. 26h or 2d",
+			},
+			&cli.IntFlag{
+				Name:  "min-count",
+				Usage: "Critical when there are fewer successful backups than this",
+			},
+		},
+		Action: runCheck,
+	}
+}
+
+func runCheck(c *cli.Context) error {
+	result, err := checkDatabase(c)
+	if err != nil {
+		fmt.Println(status.CheckError(err))
+		return cli.Exit("", int(status.CheckUnknown))
+	}
+
+	fmt.Println(result)
+	if result.State != status.CheckOK {
+		return cli.Exit("", int(result.State))
+	}
+	return nil
+}
+
+// checkDatabase checks the backups of the database named on the command
+// line against the thresholds given by the flags.
+func checkDatabase(c *cli.Context) (*status.CheckResult, error) {
+	if c.NArg() == 0 {
+		return nil, fmt.Errorf("database name is required")
+	}
+	name := c.Args().Get(0)
+
+	var thresholds status.CheckThresholds
+	var err error
+	if s := c.String("warn-age"); s != "" {
+		if thresholds.WarnAge, err = config.ParseAge(s); err != nil {
+			return nil, fmt.Errorf("invalid --warn-age: %w", err)
+		}
+	}
+	if s := c.String("max-age"); s != "" {
+		if thresholds.MaxAge, err = config.ParseAge(s); err != nil {
+			return nil, fmt.Errorf("invalid --max-age: %w", err)
+		}
+	}
+	thresholds.MinCount = c.Int("min-count")
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	dbConfig, exists := cfg.Database(name)
+	if !exists {
+		return nil, fmt.Errorf("database '%s' not found", name)
+	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return nil, err
+	}
+	if thresholds.MaxAge == 0 {
+		if thresholds.MaxAge, err = cfg.GetEffectiveFreshness(name); err != nil {
+			return nil, err
+		}
+	}
+
+	storageInstance, err := newDatabaseStorage(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	backups, err := storageInstance.ListBackups(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	return status.CheckBackups(name, backups, thresholds, time.Now()), nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ciBackupFlags are the backup flags ci-backup accepts.
+var ciBackupFlags = map[string]bool{
+	"type": true, "host": true, "port": true, "user": true, "password": true, "password-file": true,
+	"database": true, "databases": true, "tables": true, "exclude-tables": true, "schema-only": true,
+	"compression": true, "engine": true, "threads": true, "mysqldump-arg": true, "mysqldump-disable": true,
+	"mysqldump-path": true, "version-check": true, "definers": true, "sql-security-invoker": true,
+	"indexed": true, "output": true, "verbose": true,
+}
+
+// ciSummary is the JSON summary ci-backup writes to stdout.
+type ciSummary struct {
+	Status string `json:"status"` // success or failed
+	Error  string `json:"error,omitempty"`
+	*backupEventResult
+}
+
+func ciBackupCommand() *cli.Command {
+	// Reuse the backup flags, each also read from CADANGKAN_<FLAG>
+	var flags []cli.Flag
+	for _, flag := range backupCommand().Flags {
+		if ciBackupFlags[flag.Names()[0]] {
+			flags = append(flags, withEnvVar(flag))
+		}
+	}
+
+	return &cli.Command{
+		Name:  "ci-backup",
+		Usage: "Back up a database from a CI job, configured by environment variables",
+		Description: `Back up a database from an ephemeral CI runner without a config file.
+   Every flag can be given as an environment variable instead: CADANGKAN_
+   followed by the flag name in capitals with dashes as underscores, e.g.
+   CADANGKAN_HOST, CADANGKAN_DATABASE or CADANGKAN_EXCLUDE_TABLES.
+
+   Status messages go to stderr. Stdout receives one JSON object summarizing
+   the backup: its status, ID, file, size, duration and checksum. When
+   GITHUB_OUTPUT is set, as in GitHub Actions, the same values are written
+   as job outputs (status, backup_id, database, file, size_bytes,
+   checksum), so later steps can upload or attach the file.
+
+   EXAMPLE (GitHub Actions):
+     - run: cadangkan ci-backup --output ./backups
+       id: backup
+       env:
+         CADANGKAN_HOST: db.example.com
+         CADANGKAN_USER: ci
+         CADANGKAN_PASSWORD: ${{ secrets.DB_PASSWORD }}
+         CADANGKAN_DATABASE: shop
+     - uses: actions/upload-artifact@v4
+       with:
+         path: ${{ steps.backup.outputs.file }}`,
+		Flags:  flags,
+		Action: runCIBackup,
+	}
+}
+
+// withEnvVar lets a flag be set by CADANGKAN_<FLAG> unless it already has
+// environment variables.
+func withEnvVar(flag cli.Flag) cli.Flag {
+	env := []string{"CADANGKAN_" + strings.ToUpper(strings.ReplaceAll(flag.Names()[0], "-", "_"))}
+	switch f := flag.(type) {
+	case *cli.StringFlag:
+		if len(f.EnvVars) == 0 {
+			f.EnvVars = env
+		}
+	case *cli.BoolFlag:
+		if len(f.EnvVars) == 0 {
+			f.EnvVars = env
+		}
+	case *cli.IntFlag:
+		if len(f.EnvVars) == 0 {
+			f.EnvVars = env
+		}
+	case *cli.StringSliceFlag:
+		if len(f.EnvVars) == 0 {
+			f.EnvVars = env
+		}
+	}
+	return flag
+}
+
+func runCIBackup(c *cli.Context) error {
+	if c.String("output") == "-" {
+		return fmt.Errorf("--output - cannot be used with ci-backup, which writes its summary to stdout")
+	}
+
+	// Stdout carries only the summary; the result is taken from the
+	// backup's "complete" event
+	msgOut = os.Stderr
+	events = &eventStream{command: "ci-backup", enc: json.NewEncoder(io.Discard)}
+	defer func() { events = nil }()
+
+	err := backupDatabase(c, "")
+
+	summary := ciSummary{Status: "success"}
+	if result, ok := events.result.(backupEventResult); ok {
+		summary.backupEventResult = &result
+	}
+	if err != nil {
+		summary.Status = "failed"
+		summary.Error = err.Error()
+	}
+	if encodeErr := json.NewEncoder(os.Stdout).Encode(summary); encodeErr != nil && err == nil {
+		err = encodeErr
+	}
+	if outputErr := writeJobOutputs(os.Getenv("GITHUB_OUTPUT"), summary); outputErr != nil && err == nil {
+		err = outputErr
+	}
+	return err
+}
+
+// writeJobOutputs appends the summary to a GitHub Actions output file as
+// name=value lines. Nothing is written when path is empty.
+func writeJobOutputs(path string, summary ciSummary) error {
+	if path == "" {
+		return nil
+	}
+	outputs := [][2]string{{"status", summary.Status}}
+	if result := summary.backupEventResult; result != nil {
+		outputs = append(outputs,
+			[2]string{"backup_id", result.BackupID},
+			[2]string{"database", result.Database},
+			[2]string{"file", result.File},
+			[2]string{"size_bytes", fmt.Sprint(result.SizeBytes)},
+			[2]string{"checksum", result.Checksum},
+		)
+	}
+	if summary.Error != "" {
+		outputs = append(outputs, [2]string{"error", summary.Error})
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job outputs: %w", err)
+	}
+	defer file.Close()
+	for _, output := range outputs {
+		value := strings.NewReplacer("\r", " ", "\n", " ").Replace(output[1])
+		if _, err := fmt.Fprintf(file, "%s=%s\n", output[0], value); err != nil {
+			return fmt.Errorf("failed to write job outputs: %w", err)
+		}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/urfave/cli/v2"
 )
 
@@ -21,6 +25,8 @@ func cleanupCommand() *cli.Command {
      - Weekly:  Most recent backup each week (Sunday) for last N weeks
      - Monthly: Most recent backup each month (1st) for last N months
 
+   Backups locked with 'cadangkan backup-lock' are never deleted.
+
    By default, uses retention policy from config:
      daily: 7, weekly: 4, monthly: 12
 
@@ -30,6 +36,7 @@ func cleanupCommand() *cli.Command {
 				Name:  "dry-run",
 				Usage: "Show what would be deleted without actually deleting",
 			},
+			confirmFlag(),
 			&cli.IntFlag{
 				Name:  "daily",
 				Usage: "Override daily retention (keep last N daily backups)",
@@ -68,12 +75,16 @@ func runCleanup(c *cli.Context) error {
 	}
 
 	// Check if database exists in config
-	if _, exists := cfg.Databases[name]; !exists {
+	dbConfig, exists := cfg.Database(name)
+	if !exists {
 		printError(fmt.Sprintf("Database '%s' not found in config", name))
 		fmt.Println()
 		fmt.Printf("Available databases: run %scadangkan list%s\n", colorCyan, colorReset)
 		return fmt.Errorf("database not found")
 	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
 
 	// Get retention policy (from config or overrides)
 	policy := cfg.GetEffectiveRetention(name)
@@ -90,11 +101,19 @@ func runCleanup(c *cli.Context) error {
 	}
 
 	dryRun := c.Bool("dry-run")
+	if !dryRun {
+		if err := maintenance.Check("cleanup"); err != nil {
+			return err
+		}
+		if _, err := confirmProtected(c, name, "cleanup"); err != nil {
+			return err
+		}
+	}
 
 	// Create storage
-	localStorage, err := storage.NewLocalStorage("")
+	localStorage, err := newDatabaseStorage(name)
 	if err != nil {
-		printError("Failed to create storage")
+		printError(i18n.T("Failed to create storage"))
 		return err
 	}
 
@@ -117,7 +136,10 @@ func runCleanup(c *cli.Context) error {
 	fmt.Println()
 
 	// Apply retention policy
-	result, err := retentionService.ApplyRetentionPolicy(name, policy, dryRun)
+	result, err := retentionService.ApplyRetention(name, backupconfig.RetentionPolicy(policy), dryRun)
+	if !dryRun {
+		recordAudit(audit.ActionPrune, name, "", err, pruneAuditDetails(result))
+	}
 	if err != nil {
 		printError("Cleanup failed")
 		return err
@@ -125,7 +147,7 @@ func runCleanup(c *cli.Context) error {
 
 	// Display results
 	if len(result.ToDelete) == 0 {
-		printSuccess("No backups to delete")
+		printSuccess(i18n.T("No backups to delete"))
 		fmt.Println()
 		fmt.Printf("All %d backup(s) match the retention policy.\n", len(result.ToKeep))
 		return nil
@@ -157,6 +179,7 @@ func runCleanup(c *cli.Context) error {
 		weeklyBackups := []backup.CategorizedBackup{}
 		monthlyBackups := []backup.CategorizedBackup{}
 		keepBackups := []backup.CategorizedBackup{}
+		lockedBackups := []backup.CategorizedBackup{}
 
 		for _, cb := range result.ToKeep {
 			switch cb.Category {
@@ -168,6 +191,8 @@ func runCleanup(c *cli.Context) error {
 				monthlyBackups = append(monthlyBackups, cb)
 			case backup.CategoryKeep:
 				keepBackups = append(keepBackups, cb)
+			case backup.CategoryLocked:
+				lockedBackups = append(lockedBackups, cb)
 			}
 		}
 
@@ -183,6 +208,9 @@ func runCleanup(c *cli.Context) error {
 		if len(keepBackups) > 0 {
 			fmt.Printf("  %sAlways keep:%s %d\n", colorCyan, colorReset, len(keepBackups))
 		}
+		if len(lockedBackups) > 0 {
+			fmt.Printf("  %sLocked:%s %d\n", colorCyan, colorReset, len(lockedBackups))
+		}
 		fmt.Println()
 	}
 
@@ -191,7 +219,7 @@ func runCleanup(c *cli.Context) error {
 	if dryRun {
 		fmt.Printf("Space that would be reclaimed: %s%s%s\n", colorYellow, spaceHuman, colorReset)
 		fmt.Println()
-		printInfo("Run without --dry-run to delete these backups.")
+		printInfo(i18n.T("Run without --dry-run to delete these backups."))
 	} else {
 		printSuccess(fmt.Sprintf("Deleted %d backup(s)", len(result.ToDelete)))
 		fmt.Printf("Space reclaimed: %s%s%s\n", colorGreen, spaceHuman, colorReset)
@@ -199,3 +227,15 @@ func runCleanup(c *cli.Context) error {
 
 	return nil
 }
+
+// pruneAuditDetails lists the deleted backups for the audit log.
+func pruneAuditDetails(result *backup.CleanupResult) string {
+	if result == nil {
+		return ""
+	}
+	ids := make([]string, len(result.ToDelete))
+	for i, b := range result.ToDelete {
+		ids[i] = b.BackupID
+	}
+	return fmt.Sprintf("deleted %d backup(s): %s", len(ids), strings.Join(ids, ", "))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// ANSI color codes, cleared by setupColor when output is not colored
+var (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+)
+
+// Values of --color
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// colorFlags are the global flags controlling colored output.
+func colorFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "color",
+			Value:   colorAuto,
+			Usage:   "Color output: auto (only on a terminal), always or never",
+			EnvVars: []string{"CADANGKAN_COLOR"},
+		},
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colored output (same as --color=never or setting NO_COLOR)",
+		},
+	}
+}
+
+// setupColor applies the color flags before any command runs. In auto
+// mode, colors are used only when stdout is a terminal and neither
+// NO_COLOR (https://no-color.org) nor TERM=dumb asks otherwise.
+func setupColor(c *cli.Context) error {
+	mode := c.String("color")
+	if c.Bool("no-color") {
+		mode = colorNever
+	}
+
+	var enabled bool
+	switch mode {
+	case colorAlways:
+		enabled = true
+	case colorNever:
+		enabled = false
+	case colorAuto:
+		enabled = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" &&
+			term.IsTerminal(int(os.Stdout.Fd()))
+	default:
+		return fmt.Errorf("invalid --color: %s (must be 'auto', 'always' or 'never')", mode)
+	}
+
+	if !enabled {
+		colorReset, colorRed, colorGreen, colorYellow, colorBlue, colorCyan = "", "", "", "", "", ""
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func compareCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "compare",
+		Usage:     "Show what changed between two backups",
+		ArgsUsage: "[flags] <name> <backup-id-a> <backup-id-b>",
+		Description: `Compare the tables of two backups of a database: tables added and
+   removed, and the column, index and option lines of CREATE TABLE
+   statements that changed. With --data, also compare row counts and the
+   size of each table's data.
+
+   The backups are read in full; only mysqldump backups can be compared.
+   Either ID may be "latest".
+
+   EXAMPLES:
+     cadangkan compare production 2025-01-08-020000 2025-01-15-020000
+     cadangkan compare --data production 2025-01-08-020000 latest`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "data",
+				Usage: "Also compare row counts and data sizes",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Also list unchanged tables",
+			},
+		},
+		Action: runCompare,
+	}
+}
+
+func runCompare(c *cli.Context) error {
+	if c.NArg() < 3 {
+		return fmt.Errorf("database name and two backup IDs are required\n\nUsage: cadangkan compare <name> <backup-id-a> <backup-id-b>")
+	}
+	name := c.Args().Get(0)
+	withData := c.Bool("data")
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	ids := []string{c.Args().Get(1), c.Args().Get(2)}
+	for i, id := range ids {
+		if id != "latest" {
+			continue
+		}
+		latest, err := localStorage.GetLatestBackup(name)
+		if err != nil {
+			return fmt.Errorf("failed to find latest backup of '%s': %w", name, err)
+		}
+		ids[i] = latest.BackupID
+	}
+
+	summaries := make([]*backup.DumpSummary, len(ids))
+	for i, id := range ids {
+		printInfo(fmt.Sprintf("Reading backup %s...", id))
+		if summaries[i], err = backup.SummarizeBackup(localStorage, name, id, withData); err != nil {
+			return fmt.Errorf("failed to read backup %s: %w", id, err)
+		}
+	}
+
+	changes := backup.CompareDumps(summaries[0], summaries[1])
+
+	fmt.Printf("\n%sComparing %s%s: %s → %s\n", colorCyan, name, colorReset, ids[0], ids[1])
+	fmt.Println(strings.Repeat("=", 80))
+
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Change]++
+		if change.Change == backup.TableUnchanged && !c.Bool("all") && !dataChanged(change, withData) {
+			continue
+		}
+		printTableChange(change, withData)
+	}
+
+	fmt.Println()
+	fmt.Printf("Tables: %d added, %d removed, %d changed, %d unchanged\n",
+		counts[backup.TableAdded], counts[backup.TableRemoved], counts[backup.TableChanged], counts[backup.TableUnchanged])
+	return nil
+}
+
+// printTableChange shows one table's change, its schema diff, and with
+// withData its rows and data size in both backups.
+func printTableChange(change backup.TableChange, withData bool) {
+	marker, color := " ", ""
+	switch change.Change {
+	case backup.TableAdded:
+		marker, color = "+", colorGreen
+	case backup.TableRemoved:
+		marker, color = "-", colorRed
+	case backup.TableChanged:
+		marker, color = "~", colorYellow
+	}
+
+	line := fmt.Sprintf("%s%s %-30s%s %s", color, marker, change.Table, colorReset, change.Change)
+	if withData {
+		line += strings.Repeat(" ", len(backup.TableUnchanged)-len(change.Change)+2) + formatDataChange(change)
+	}
+	fmt.Println(line)
+
+	for _, diff := range change.SchemaDiff {
+		diffColor := colorGreen
+		if strings.HasPrefix(diff, "-") {
+			diffColor = colorRed
+		}
+		fmt.Printf("    %s%s%s\n", diffColor, diff, colorReset)
+	}
+}
+
+// formatDataChange describes how a table's rows and data size changed.
+func formatDataChange(change backup.TableChange) string {
+	rows := fmt.Sprintf("%d → %d rows", change.RowsBefore, change.RowsAfter)
+	if delta := change.RowsAfter - change.RowsBefore; delta != 0 {
+		rows += fmt.Sprintf(" (%+d)", delta)
+	}
+	return fmt.Sprintf("%s, %s → %s", rows,
+		backup.FormatBytes(change.BytesBefore), backup.FormatBytes(change.BytesAfter))
+}
+
+// dataChanged reports whether an unchanged table's data differs, when data
+// is compared.
+func dataChanged(change backup.TableChange, withData bool) bool {
+	return withData && (change.RowsBefore != change.RowsAfter || change.BytesBefore != change.BytesAfter)
+}
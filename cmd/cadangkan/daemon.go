@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/control"
 	"github.com/erickhilda/cadangkan/internal/scheduler"
-	"github.com/erickhilda/cadangkan/internal/storage"
 	"github.com/urfave/cli/v2"
 )
 
+// configPollInterval is how often the daemon checks config.yaml for changes.
+const configPollInterval = 30 * time.Second
+
 func daemonCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "daemon",
@@ -22,25 +30,172 @@ func daemonCommand() *cli.Command {
      - Load all configured schedules
      - Run backups at the scheduled times
      - Apply retention policies after backups
+     - Reload the configuration when config.yaml changes or on SIGHUP
      - Continue running until stopped (Ctrl+C)
 
+   When stopped, the daemon starts no new jobs and waits for running ones
+   up to daemon.shutdown_grace (default: 1m); a second signal stops it
+   immediately. Jobs still queued run after the next start, and runs cut
+   short are recorded as interrupted in the audit log.
+
+   While running, the daemon answers 'cadangkan status', 'cadangkan daemon
+   reload' and 'cadangkan daemon stop' on a control socket
+   (daemon.sock in the data directory).
+
+   Started as root, --user switches to a dedicated user before anything
+   else; its config and data directories are used unless CADANGKAN_HOME is
+   set. The daemon refuses to start when mysqldump (or mysql, for restore
+   tests) is not in that user's PATH.
+
    USAGE:
      cadangkan daemon              Run in foreground
-     cadangkan daemon --verbose    Run with verbose logging`,
+     cadangkan daemon --verbose    Run with verbose logging
+     cadangkan daemon --user cadangkan
+                                   Run as the cadangkan user when started as root
+     cadangkan daemon reload       Reload the configuration of a running daemon
+     cadangkan daemon stop         Stop a running daemon`,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
 				Usage:   "Enable verbose logging",
 			},
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "Switch to this user when started as root",
+			},
+		},
+		Subcommands: []*cli.Command{
+			daemonReloadCommand(),
+			daemonStopCommand(),
 		},
 		Action: runDaemon,
 	}
 }
 
+func daemonReloadCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "reload",
+		Usage:  "Reload the configuration of the running daemon",
+		Action: runDaemonReload,
+	}
+}
+
+func runDaemonReload(c *cli.Context) error {
+	response, err := queryDaemon(control.CommandReload)
+	if err != nil {
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Daemon configuration reloaded (%s)", response.Result))
+	return nil
+}
+
+func daemonStopCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "stop",
+		Usage:  "Stop the running daemon",
+		Action: runDaemonStop,
+	}
+}
+
+func runDaemonStop(c *cli.Context) error {
+	if _, err := queryDaemon(control.CommandStop); err != nil {
+		return err
+	}
+
+	printSuccess("Daemon is stopping")
+	return nil
+}
+
+// queryDaemon sends a command to the running daemon.
+func queryDaemon(command string) (*control.Response, error) {
+	socketPath, err := control.DefaultSocketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := control.Query(socketPath, command)
+	if errors.Is(err, control.ErrNotRunning) {
+		printError("The daemon is not running")
+		fmt.Println()
+		fmt.Println("Start it with:")
+		fmt.Printf("  %scadangkan daemon%s\n", colorCyan, colorReset)
+		return nil, err
+	}
+	return response, err
+}
+
+// daemonControl answers control socket requests. Reloads and stops are
+// handed to the daemon's main loop.
+type daemonControl struct {
+	sched     *scheduler.Scheduler
+	startedAt time.Time
+	reloads   chan chan reloadResult
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// reloadResult is the outcome of a configuration reload.
+type reloadResult struct {
+	summary string
+	err     error
+}
+
+func (d *daemonControl) Status() *control.DaemonState {
+	state := &control.DaemonState{
+		PID:       os.Getpid(),
+		StartedAt: d.startedAt,
+	}
+	for _, info := range d.sched.ListSchedules() {
+		state.Schedules = append(state.Schedules, control.ScheduleState{
+			Database: info.Database,
+			Schedule: info.Schedule,
+			Cron:     info.Cron,
+			NextRun:  info.NextRun,
+			PrevRun:  info.PrevRun,
+		})
+	}
+	for _, run := range d.sched.Running() {
+		state.Running = append(state.Running, control.RunState{Label: run.Label, StartedAt: run.Time})
+	}
+	for _, queued := range d.sched.Queued() {
+		state.Queued = append(state.Queued, control.QueuedState{Label: queued.Label, Priority: queued.Priority, QueuedAt: queued.QueuedAt})
+	}
+	for _, failure := range d.sched.Failures() {
+		state.LastErrors = append(state.LastErrors, control.RunError{Label: failure.Label, Time: failure.Time, Error: failure.Error})
+	}
+	return state
+}
+
+func (d *daemonControl) Reload() (string, error) {
+	reply := make(chan reloadResult, 1)
+	select {
+	case d.reloads <- reply:
+	case <-d.stop:
+		return "", fmt.Errorf("daemon is stopping")
+	}
+	result := <-reply
+	return result.summary, result.err
+}
+
+func (d *daemonControl) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
 func runDaemon(c *cli.Context) error {
 	verbose := c.Bool("verbose")
 
+	// Drop root before touching any file, then find the user's directories
+	if name := c.String("user"); name != "" {
+		if err := dropPrivileges(name); err != nil {
+			return err
+		}
+		setupWorkDir()
+	} else if os.Geteuid() == 0 {
+		printWarning("Running as root; use --user to run as a dedicated user")
+	}
+
 	// Load configuration
 	mgr, err := config.NewManager()
 	if err != nil {
@@ -51,9 +206,12 @@ func runDaemon(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := missingTools(requiredTools(cfg)); err != nil {
+		return fmt.Errorf("cannot run the configured schedules: %w", err)
+	}
 
 	// Create storage
-	localStorage, err := storage.NewLocalStorage("")
+	localStorage, err := newLocalStorage("")
 	if err != nil {
 		return fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -69,8 +227,36 @@ func runDaemon(c *cli.Context) error {
 		return fmt.Errorf("failed to load schedules: %w", err)
 	}
 
-	// Start scheduler
+	// Answer status, reload and stop requests from the CLI
+	ctl := &daemonControl{
+		sched:     sched,
+		startedAt: time.Now(),
+		reloads:   make(chan chan reloadResult),
+		stop:      make(chan struct{}),
+	}
+	if socketPath, err := control.DefaultSocketPath(); err != nil {
+		printWarning(fmt.Sprintf("Control socket disabled: %v", err))
+	} else if server, err := control.Listen(socketPath, ctl); err != nil {
+		return err
+	} else {
+		go server.Serve()
+		defer server.Close()
+	}
+
+	// Start scheduler, then the jobs left queued by the last shutdown
 	sched.Start()
+	queuePath, queueErr := scheduler.DefaultQueuePath()
+	if queueErr == nil {
+		if pending, err := scheduler.LoadQueue(queuePath); err != nil {
+			printWarning(err.Error())
+		} else if len(pending) > 0 {
+			printInfo(fmt.Sprintf("Resuming %d job(s) queued before the last shutdown", len(pending)))
+			sched.Resume(pending)
+		}
+		if err := scheduler.SaveQueue(queuePath, nil); err != nil {
+			printWarning(err.Error())
+		}
+	}
 
 	printSuccess("Cadangkan daemon started")
 	fmt.Println()
@@ -88,7 +274,7 @@ func runDaemon(c *cli.Context) error {
 		for _, info := range schedules {
 			fmt.Printf("  %s%-20s%s  Next: %s\n",
 				colorCyan,
-				info.Database,
+				config.ScheduleLabel(info.Database, info.Schedule),
 				colorReset,
 				formatNextRun(info.NextRun),
 			)
@@ -99,15 +285,122 @@ func runDaemon(c *cli.Context) error {
 	fmt.Println("Press Ctrl+C to stop")
 	fmt.Println()
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal, reloading on SIGHUP or config changes
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	configPath, _ := getConfigPath()
+	lastModified := configModTime(configPath)
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+wait:
+	for {
+		select {
+		case sig := <-sigChan:
+			if sig != syscall.SIGHUP {
+				break wait
+			}
+			printInfo("Received SIGHUP, reloading configuration...")
+			lastModified = configModTime(configPath)
+			reloadDaemonConfig(mgr, sched)
+		case reply := <-ctl.reloads:
+			printInfo("Reload requested, reloading configuration...")
+			lastModified = configModTime(configPath)
+			summary, err := reloadDaemonConfig(mgr, sched)
+			reply <- reloadResult{summary: summary, err: err}
+		case <-ctl.stop:
+			break wait
+		case <-ticker.C:
+			if modified := configModTime(configPath); !modified.Equal(lastModified) {
+				lastModified = modified
+				printInfo("Configuration file changed, reloading...")
+				reloadDaemonConfig(mgr, sched)
+			}
+		}
+	}
 
 	fmt.Println()
-	printInfo("Shutting down daemon...")
-	sched.Stop()
+	ctl.Stop()
+
+	grace, err := sched.Config().Daemon.ShutdownGraceDuration()
+	if err != nil {
+		grace = config.DefaultShutdownGrace
+	}
+	if running := sched.Running(); len(running) > 0 {
+		printInfo(fmt.Sprintf("Shutting down daemon, waiting up to %s for %d running job(s)...",
+			grace, len(running)))
+	} else {
+		printInfo("Shutting down daemon...")
+	}
+
+	// Drain running jobs, stopping at once on a second signal
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	go func() {
+		select {
+		case <-sigChan:
+			printWarning("Stopping without waiting for running jobs")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	pending, interrupted := sched.Shutdown(ctx)
+	cancel()
+
+	if len(interrupted) > 0 {
+		labels := make([]string, len(interrupted))
+		for i, run := range interrupted {
+			labels[i] = run.Label
+		}
+		printWarning(fmt.Sprintf("Interrupted %d run(s): %s", len(interrupted), strings.Join(labels, ", ")))
+	}
+	if queueErr == nil {
+		if err := scheduler.SaveQueue(queuePath, pending); err != nil {
+			printError(err.Error())
+		} else if len(pending) > 0 {
+			printInfo(fmt.Sprintf("Saved %d queued job(s) to run after the next start", len(pending)))
+		}
+	}
+
 	printSuccess("Daemon stopped")
 
 	return nil
 }
+
+// reloadDaemonConfig loads and validates the configuration and applies it
+// to the scheduler. An invalid configuration is rejected and the daemon
+// keeps running with the previous one. It returns a summary of the changes.
+func reloadDaemonConfig(mgr config.Manager, sched *scheduler.Scheduler) (string, error) {
+	cfg, err := mgr.Load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		printError(fmt.Sprintf("Configuration not reloaded, keeping the previous one: %v", err))
+		return "", fmt.Errorf("configuration not reloaded: %w", err)
+	}
+
+	changes, err := sched.Reload(cfg)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to reload schedules: %v", err))
+		return "", fmt.Errorf("failed to reload schedules: %w", err)
+	}
+
+	if err := missingTools(requiredTools(cfg)); err != nil {
+		printWarning(fmt.Sprintf("Schedules will fail: %v", err))
+	}
+
+	summary := fmt.Sprintf("%d schedule change(s)", len(changes))
+	printSuccess(fmt.Sprintf("Configuration reloaded (%s)", summary))
+	return summary, nil
+}
+
+// configModTime returns the modification time of the config file, or the
+// zero time if it cannot be read.
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
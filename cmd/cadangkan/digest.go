@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/notify"
+	"github.com/erickhilda/cadangkan/internal/status"
+	"github.com/urfave/cli/v2"
+)
+
+func digestCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "digest",
+		Usage: "Show or email the backup digest",
+		Description: `Summarize all databases over the last day or week: backups taken,
+   failures, storage used and growth, and backups due for deletion by
+   retention.
+
+   The daemon emails the digest on the schedule in the digest section of
+   the config. Use --send to email it now.
+
+   USAGE:
+     cadangkan digest                  # Show the daily digest
+     cadangkan digest --period weekly  # Show the weekly digest
+     cadangkan digest --send           # Email the digest to its recipients`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "period",
+				Usage: "Period to summarize: daily or weekly (default: from config, or daily)",
+			},
+			&cli.BoolFlag{
+				Name:  "send",
+				Usage: "Email the digest to the configured recipients",
+			},
+		},
+		Action: runDigest,
+	}
+}
+
+func runDigest(c *cli.Context) error {
+	storageInstance, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	period := c.String("period")
+	if period == "" && cfg.Digest != nil {
+		period = cfg.Digest.Period
+	}
+	switch period {
+	case "", config.DigestDaily, config.DigestWeekly:
+	default:
+		return fmt.Errorf("invalid period: %s (must be daily or weekly)", period)
+	}
+
+	digest, err := status.NewService(configManager, storageInstance).GetDigest(period)
+	if err != nil {
+		return fmt.Errorf("failed to build digest: %w", err)
+	}
+
+	if !c.Bool("send") {
+		fmt.Print(digest.Format())
+		return nil
+	}
+
+	if cfg.Digest == nil {
+		return fmt.Errorf("no digest configured; add a digest section with recipients and smtp to the config")
+	}
+	email := &notify.Email{
+		From:    cfg.Digest.SMTP.From,
+		To:      cfg.Digest.Recipients,
+		Subject: digest.Subject(),
+		Body:    digest.Format(),
+	}
+	if err := notify.Send(cfg.Digest.SMTP, email); err != nil {
+		printError("Failed to send digest")
+		return err
+	}
+	printSuccess(fmt.Sprintf("Digest sent to %s", strings.Join(cfg.Digest.Recipients, ", ")))
+	return nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/urfave/cli/v2"
+)
+
+func discoverCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "discover",
+		Usage:     "Add the databases found on a server",
+		ArgsUsage: "[mysql]",
+		Description: `Connect to a server, list its databases, leaving out the system
+   schemas (mysql, information_schema, performance_schema, sys), and add
+   a configuration for each one you confirm, or for all with --all. The
+   configurations share the server's connection and credentials.
+
+   Databases already configured on the same server are skipped.
+
+   EXAMPLES:
+     cadangkan discover --host=db.example.com --user=backup
+
+     Add every database, named prod_<database>:
+       cadangkan discover --host=db.example.com --user=backup --all --prefix=prod_
+
+     Leave out scratch schemas:
+       cadangkan discover --my-cnf=client --all --exclude='tmp_*' --exclude='*_test'`,
+		Flags: append(connectionFlags(),
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Add all databases found without asking",
+			},
+			&cli.StringFlag{
+				Name:  "prefix",
+				Usage: "Prefix of the configuration names, e.g. prod_",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Leave out databases matching a glob pattern (can be repeated)",
+			},
+		),
+		Action: runDiscover,
+	}
+}
+
+func runDiscover(c *cli.Context) error {
+	dbType := "mysql"
+	if c.NArg() > 0 {
+		dbType = c.Args().Get(0)
+	}
+	eng, err := backup.GetEngine(dbType)
+	if err != nil {
+		return err
+	}
+
+	conn, err := addConnection(c)
+	if err != nil {
+		return err
+	}
+	if conn.Host == "" {
+		return fmt.Errorf("--host is required (or give it with --url or --my-cnf)")
+	}
+	if conn.User == "" {
+		return fmt.Errorf("--user is required (or give it with --url or --my-cnf)")
+	}
+	if conn.Port == 0 {
+		conn.Port = eng.DefaultPort()
+	}
+	if err := conn.TLS.Validate("tls"); err != nil {
+		return err
+	}
+	for _, pattern := range c.StringSlice("exclude") {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	password, err := readPassword(c, conn.Password)
+	if err != nil {
+		return err
+	}
+
+	// List the databases on the server
+	printInfo(fmt.Sprintf("Connecting to %s@%s:%d...", conn.User, conn.Host, conn.Port))
+	client, err := eng.NewIntrospector(backup.MySQLConnection(&mysql.Config{
+		Host:     conn.Host,
+		Port:     conn.Port,
+		User:     conn.User,
+		Password: password,
+		Timeout:  10 * time.Second,
+		SSL:      backupconfig.SSL(conn.TLS),
+	}))
+	if err != nil {
+		printError(fmt.Sprintf("Failed to create %s client", eng.DisplayName()))
+		return err
+	}
+	if err := client.Connect(); err != nil {
+		printError(i18n.T("Connection failed"))
+		return err
+	}
+	databases, err := client.GetDatabases()
+	client.Close()
+	if err != nil {
+		return fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	candidates := discoverCandidates(c, cfg, conn, databases)
+	if len(candidates) == 0 {
+		printInfo("No new databases found")
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("Found %d new database(s):\n", len(candidates))
+	for _, candidate := range candidates {
+		fmt.Printf("  %s%-30s%s as %s\n", colorCyan, candidate.database, colorReset, candidate.name)
+	}
+	fmt.Println()
+
+	// Choose the databases to add
+	selected := candidates
+	if !c.Bool("all") {
+		selected = nil
+		p := &prompter{reader: bufio.NewReader(os.Stdin)}
+		for _, candidate := range candidates {
+			add, err := p.confirm(fmt.Sprintf("Add %s as '%s'?", candidate.database, candidate.name), true)
+			if err != nil {
+				return err
+			}
+			if add {
+				selected = append(selected, candidate)
+			}
+		}
+		fmt.Println()
+	}
+	if len(selected) == 0 {
+		printInfo(i18n.T("Cancelled"))
+		return nil
+	}
+
+	printInfo(i18n.T("Encrypting password..."))
+	encryptedPassword, err := config.EncryptPassword(password)
+	if err != nil {
+		printError(i18n.T("Failed to encrypt password"))
+		return err
+	}
+
+	if cfg.Databases == nil {
+		cfg.Databases = make(map[string]*config.DatabaseConfig)
+	}
+	for _, candidate := range selected {
+		cfg.Databases[candidate.name] = &config.DatabaseConfig{
+			Type:              eng.Name(),
+			Host:              conn.Host,
+			Port:              conn.Port,
+			Database:          candidate.database,
+			User:              conn.User,
+			PasswordEncrypted: encryptedPassword,
+			TLS:               conn.TLS,
+		}
+	}
+
+	printInfo(i18n.T("Saving configuration..."))
+	err = mgr.Save(cfg)
+	for _, candidate := range selected {
+		recordAudit(audit.ActionConfigAdd, candidate.name, "", err,
+			fmt.Sprintf("discover %s %s@%s:%d/%s", eng.Name(), conn.User, conn.Host, conn.Port, candidate.database))
+	}
+	if err != nil {
+		printError(i18n.T("Failed to save configuration"))
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Added %d database(s)", len(selected)))
+	fmt.Println()
+	fmt.Printf("You can now run: %scadangkan backup %s%s\n", colorCyan, selected[0].name, colorReset)
+	return nil
+}
+
+// discoverCandidate is a database found by discover and its configuration
+// name.
+type discoverCandidate struct {
+	database string
+	name     string
+}
+
+// discoverCandidates returns the databases found on the server that are
+// not system schemas, not excluded and not configured yet, with the names
+// they would be added as. Databases whose name is taken by another
+// configuration are left out with a warning.
+func discoverCandidates(c *cli.Context, cfg *config.Config, conn *config.Connection, databases []string) []discoverCandidate {
+	var candidates []discoverCandidate
+	names := make(map[string]bool)
+	for _, database := range databases {
+		if mysql.IsSystemDatabase(database) || discoverExcluded(c.StringSlice("exclude"), database) {
+			continue
+		}
+
+		configured := false
+		for _, db := range cfg.Databases {
+			if db.Host == conn.Host && db.Port == conn.Port && db.Database == database {
+				configured = true
+				break
+			}
+		}
+		if configured {
+			continue
+		}
+
+		name := config.SanitizeName(c.String("prefix") + database)
+		if _, taken := cfg.Databases[name]; taken || names[name] {
+			printWarning(fmt.Sprintf("Skipping %s: the name '%s' is already used by another database", database, name))
+			continue
+		}
+		names[name] = true
+		candidates = append(candidates, discoverCandidate{database: database, name: name})
+	}
+	return candidates
+}
+
+// discoverExcluded reports whether database matches an --exclude pattern.
+func discoverExcluded(patterns []string, database string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, database); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/paths"
+	"github.com/urfave/cli/v2"
+)
+
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check that this user can run backups",
+		Description: `Check the environment cadangkan runs in: the user, the permissions of
+   the config and data directories and of the encryption key, whether the
+   backup directories are writable and whether the MySQL client programs
+   are in PATH, or at the paths set by mysqldump_path and mysql_path.
+
+   To check a service account, run it as root with --user; the checks then
+   run as that user, just as 'cadangkan daemon --user' would.
+
+   A ~/.cadangkan directory left by an older version stays in use until
+   --migrate moves its files to the config and data directories. Stop the
+   daemon and any scheduled backups first, since files are moved while
+   they may be in use.
+
+   Exits non-zero when a check fails.
+
+   EXAMPLES:
+     cadangkan doctor
+     sudo cadangkan doctor --user cadangkan
+     cadangkan doctor --migrate`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "Switch to this user before checking (requires root)",
+			},
+			&cli.BoolFlag{
+				Name:  "migrate",
+				Usage: "Move the files of ~/.cadangkan to the config and data directories",
+			},
+		},
+		Action: runDoctor,
+	}
+}
+
+// doctorReport counts the outcome of the checks.
+type doctorReport struct {
+	failed   int
+	warnings int
+}
+
+func (r *doctorReport) ok(message string) {
+	printSuccess(message)
+}
+
+func (r *doctorReport) warn(message string) {
+	printWarning(message)
+	r.warnings++
+}
+
+func (r *doctorReport) fail(message string) {
+	printError(message)
+	r.failed++
+}
+
+func runDoctor(c *cli.Context) error {
+	if name := c.String("user"); name != "" {
+		if err := dropPrivileges(name); err != nil {
+			return err
+		}
+	}
+
+	report := &doctorReport{}
+
+	fmt.Printf("%sUser%s\n", colorCyan, colorReset)
+	doctorUser(report)
+
+	fmt.Printf("\n%sFiles%s\n", colorCyan, colorReset)
+	doctorLegacyDir(report, c.Bool("migrate"))
+	configDir, err := paths.ConfigDir()
+	if err != nil {
+		return err
+	}
+	dataDir, err := paths.DataDir()
+	if err != nil {
+		return err
+	}
+	doctorDir(report, "Config directory", configDir)
+	if dataDir != configDir {
+		doctorDir(report, "Data directory", dataDir)
+	}
+	doctorSecretFile(report, "config.yaml", filepath.Join(configDir, "config.yaml"), nil)
+	if keyPath, err := config.KeyPath(); err == nil {
+		doctorSecretFile(report, "Encryption key", keyPath, config.CheckKeyMode)
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		report.fail(fmt.Sprintf("config.yaml cannot be loaded: %v", err))
+		cfg = config.NewConfig()
+	}
+
+	fmt.Printf("\n%sStorage%s\n", colorCyan, colorReset)
+	if localStorage, err := newLocalStorage(""); err != nil {
+		report.fail(fmt.Sprintf("Storage: %v", err))
+	} else {
+		for _, stor := range storageRoots(localStorage, cfg) {
+			doctorWritable(report, stor.GetBasePath())
+		}
+	}
+
+	fmt.Printf("\n%sPrograms%s\n", colorCyan, colorReset)
+	doctorTools(report, requiredTools(cfg))
+
+	fmt.Println()
+	switch {
+	case report.failed > 0:
+		return fmt.Errorf("%d check(s) failed, %d warning(s)", report.failed, report.warnings)
+	case report.warnings > 0:
+		printWarning(fmt.Sprintf("All checks passed with %d warning(s)", report.warnings))
+	default:
+		printSuccess("All checks passed")
+	}
+	return nil
+}
+
+// doctorUser reports the user cadangkan runs as.
+func doctorUser(report *doctorReport) {
+	if os.Geteuid() == 0 {
+		report.warn("Running as root; run the daemon as a dedicated user with 'cadangkan daemon --user <name>'")
+		return
+	}
+	name := fmt.Sprintf("uid %d", os.Geteuid())
+	if u, err := user.LookupId(strconv.Itoa(os.Geteuid())); err == nil {
+		name = fmt.Sprintf("%s (uid %s)", u.Username, u.Uid)
+	}
+	report.ok("Running as " + name)
+}
+
+// doctorDir checks that a directory of cadangkan belongs to the current
+// user and is not writable by others.
+func doctorDir(report *doctorReport, label, dir string) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		report.ok(fmt.Sprintf("%s %s does not exist yet", label, dir))
+		return
+	}
+	if err != nil {
+		report.fail(fmt.Sprintf("%s %s: %v", label, dir, err))
+		return
+	}
+	if !info.IsDir() {
+		report.fail(fmt.Sprintf("%s %s is not a directory", label, dir))
+		return
+	}
+	if owner, ok := fileOwner(info); ok && owner != os.Geteuid() {
+		report.warn(fmt.Sprintf("%s %s belongs to uid %d, not to this user", label, dir, owner))
+		return
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		report.fail(fmt.Sprintf("%s %s is writable by other users (mode %04o); run: chmod go-w %s", label, dir, info.Mode().Perm(), dir))
+		return
+	}
+	report.ok(fmt.Sprintf("%s %s", label, dir))
+}
+
+// doctorSecretFile checks that a file holding secrets is readable by the
+// current user and by no one else. check, if not nil, decides which modes
+// are refused outright; other modes readable by the group are warned
+// about.
+func doctorSecretFile(report *doctorReport, label, path string, check func(string, os.FileMode) error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		report.ok(fmt.Sprintf("%s %s does not exist yet", label, path))
+		return
+	}
+	if err != nil {
+		report.fail(fmt.Sprintf("%s %s: %v", label, path, err))
+		return
+	}
+	if check != nil {
+		if err := check(path, info.Mode()); err != nil {
+			report.fail(err.Error())
+			return
+		}
+	}
+	if owner, ok := fileOwner(info); ok && owner != os.Geteuid() {
+		report.warn(fmt.Sprintf("%s %s belongs to uid %d, not to this user", label, path, owner))
+		return
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		report.warn(fmt.Sprintf("%s %s is readable by other users (mode %04o); run: chmod 600 %s", label, path, info.Mode().Perm(), path))
+		return
+	}
+	report.ok(fmt.Sprintf("%s %s (mode %04o)", label, path, info.Mode().Perm()))
+}
+
+// unixWriteOK is W_OK for access(2).
+const unixWriteOK = 0x2
+
+// doctorWritable checks that backups can be written to dir, or to the
+// nearest existing directory it would be created in.
+func doctorWritable(report *doctorReport, dir string) {
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	if err := syscall.Access(existing, unixWriteOK); err != nil {
+		report.fail(fmt.Sprintf("Backup directory %s is not writable: %v", dir, err))
+		return
+	}
+	if existing != dir {
+		report.ok(fmt.Sprintf("Backup directory %s can be created", dir))
+		return
+	}
+	report.ok("Backup directory " + dir)
+}
+
+// doctorTools checks that the MySQL client programs are in PATH and that
+// the programs configured by path exist. Those the schedules need fail the
+// check when missing; the others are only needed for manual backups and
+// restores, or for --engine mydumper.
+func doctorTools(report *doctorReport, required map[string][]string) {
+	standard := []string{"mysqldump", "mysql", "mydumper", "myloader"}
+	for _, tool := range slices.Sorted(maps.Keys(required)) {
+		if slices.Contains(standard, tool) {
+			continue
+		}
+		if _, err := exec.LookPath(tool); err != nil {
+			report.fail(fmt.Sprintf("%s cannot be run, needed by %s: %v", tool, strings.Join(required[tool], ", "), err))
+			continue
+		}
+		report.ok(fmt.Sprintf("%s (configured)", tool))
+	}
+	for _, tool := range standard {
+		path, err := exec.LookPath(tool)
+		switch {
+		case err == nil:
+			report.ok(fmt.Sprintf("%s: %s", tool, path))
+		case len(required[tool]) > 0:
+			report.fail(fmt.Sprintf("%s not found in PATH, needed by %s", tool, strings.Join(required[tool], ", ")))
+		case tool == "mysqldump" || tool == "mysql":
+			report.warn(fmt.Sprintf("%s not found in PATH", tool))
+		default:
+			printInfo(fmt.Sprintf("%s not found in PATH (only needed for --engine mydumper)", tool))
+		}
+	}
+	fmt.Printf("  PATH=%s\n", os.Getenv("PATH"))
+}
+
+// fileOwner returns the uid owning a file.
+func fileOwner(info os.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
@@ -9,7 +9,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/term"
@@ -48,7 +51,10 @@ func editCommand() *cli.Command {
      cadangkan edit --port=3307 production
      cadangkan edit --password production  # Interactive password prompt
      cadangkan edit --password=mypassword production  # Direct password (not recommended)
-     cadangkan edit --host=newhost --port=3307 --skip-test production`,
+     cadangkan edit --host=newhost --port=3307 --skip-test production
+
+   Fields that already have the given values are not changed; when nothing
+   changes, edit saves nothing and exits successfully.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "host",
@@ -124,7 +130,7 @@ func runEdit(c *cli.Context) error {
 	printInfo(fmt.Sprintf("Loading configuration for '%s'...", name))
 	dbConfig, err := mgr.GetDatabase(name)
 	if err != nil {
-		printError("Database not found")
+		printError(i18n.T("Database not found"))
 		return err
 	}
 
@@ -188,7 +194,7 @@ func runEdit(c *cli.Context) error {
 				password = strings.TrimSpace(string(passwordBytes))
 			} else {
 				// Interactive prompt (when --password is used without value)
-				fmt.Print("Enter new password: ")
+				fmt.Print(i18n.T("Enter new password: "))
 				passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
 				fmt.Println() // New line after password input
 				if err != nil {
@@ -202,11 +208,18 @@ func runEdit(c *cli.Context) error {
 			return fmt.Errorf("password cannot be empty")
 		}
 
-		passwordChanged = true
-		hasChanges = true
+		// Setting the same password again is not a change
+		if current, err := config.DecryptPassword(dbConfig.PasswordEncrypted); err != nil || current != password {
+			passwordChanged = true
+			hasChanges = true
+		}
 	}
 
 	// Check if any changes were made
+	if !hasChanges && editFlagsSet(c) {
+		printInfo(fmt.Sprintf("Database '%s' is already up to date", name))
+		return nil
+	}
 	if !hasChanges {
 		printInfo("No changes specified. Use flags to update fields.")
 		fmt.Println()
@@ -214,7 +227,7 @@ func runEdit(c *cli.Context) error {
 		fmt.Printf("  Host:     %s\n", dbConfig.Host)
 		fmt.Printf("  Port:     %d\n", dbConfig.Port)
 		fmt.Printf("  User:     %s\n", dbConfig.User)
-		fmt.Printf("  Database: %s\n", dbConfig.Database)
+		fmt.Printf("  Database: %s\n", databaseLabel(dbConfig))
 		return nil
 	}
 
@@ -245,11 +258,12 @@ func runEdit(c *cli.Context) error {
 				Password: testPassword,
 				Database: dbConfig.Database,
 				Timeout:  10 * time.Second,
+				SSL:      backupconfig.SSL(dbConfig.TLS),
 			}
 
 			client, err := mysql.NewClient(mysqlConfig)
 			if err != nil {
-				printError("Failed to create MySQL client")
+				printError(i18n.T("Failed to create MySQL client"))
 				return err
 			}
 
@@ -271,19 +285,21 @@ func runEdit(c *cli.Context) error {
 
 	// Encrypt password if it was changed
 	if passwordChanged {
-		printInfo("Encrypting password...")
+		printInfo(i18n.T("Encrypting password..."))
 		encryptedPassword, err := config.EncryptPassword(password)
 		if err != nil {
-			printError("Failed to encrypt password")
+			printError(i18n.T("Failed to encrypt password"))
 			return err
 		}
 		dbConfig.PasswordEncrypted = encryptedPassword
 	}
 
 	// Save updated config
-	printInfo("Saving configuration...")
-	if err := mgr.AddDatabase(name, dbConfig); err != nil {
-		printError("Failed to save configuration")
+	printInfo(i18n.T("Saving configuration..."))
+	err = mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, "")
+	if err != nil {
+		printError(i18n.T("Failed to save configuration"))
 		return err
 	}
 
@@ -293,3 +309,14 @@ func runEdit(c *cli.Context) error {
 
 	return nil
 }
+
+// editFlagsSet reports whether any field to update was given, so an edit
+// that changes nothing can be told apart from one that asked for nothing.
+func editFlagsSet(c *cli.Context) bool {
+	for _, name := range []string{"host", "port", "user", "database", "password", "password-stdin"} {
+		if c.IsSet(name) {
+			return true
+		}
+	}
+	return hasPasswordFlag()
+}
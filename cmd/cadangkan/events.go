@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+// eventsNDJSON is the --events format writing one JSON object per line.
+const eventsNDJSON = "ndjson"
+
+// eventProgressInterval is how often progress events are written.
+const eventProgressInterval = time.Second
+
+// Event types
+const (
+	eventStarted  = "started"
+	eventPhase    = "phase"
+	eventProgress = "progress"
+	eventMessage  = "message"
+	eventComplete = "complete"
+	eventFailed   = "failed"
+)
+
+// event is one line of the event stream.
+type event struct {
+	Time    time.Time   `json:"time"`
+	Command string      `json:"command"`
+	Type    string      `json:"type"`
+	Phase   string      `json:"phase,omitempty"`
+	Level   string      `json:"level,omitempty"` // For messages: info, success, warning or error
+	Message string      `json:"message,omitempty"`
+	Bytes   int64       `json:"bytes,omitempty"`
+	Total   int64       `json:"total,omitempty"` // Expected bytes, when known
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// eventStream writes events of a command to stdout.
+type eventStream struct {
+	command string
+	enc     *json.Encoder
+	mu      sync.Mutex
+
+	// result is the result of the "complete" event, once written
+	result interface{}
+}
+
+// events is the event stream selected by --events, or nil when events are
+// off. All methods do nothing on a nil stream.
+var events *eventStream
+
+// eventsFlag selects the event stream format.
+func eventsFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "events",
+		Usage: "Write progress and lifecycle events to stdout, one JSON object per line (ndjson); messages go to stderr",
+	}
+}
+
+// withEvents runs action with the event stream selected by --events, ending
+// the stream with a "failed" event when the action fails.
+func withEvents(command string, action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		switch c.String("events") {
+		case "":
+			return action(c)
+		case eventsNDJSON:
+		default:
+			return fmt.Errorf("invalid events format: %s (must be ndjson)", c.String("events"))
+		}
+		if c.String("output") == "-" {
+			return fmt.Errorf("--events cannot be used with --output -, which writes the backup to stdout")
+		}
+
+		// Stdout carries only events
+		msgOut = os.Stderr
+		events = &eventStream{command: command, enc: json.NewEncoder(os.Stdout)}
+		defer func() { events = nil }()
+
+		events.emit(event{Type: eventStarted})
+		err := action(c)
+		if err != nil {
+			events.emit(event{Type: eventFailed, Message: err.Error()})
+		}
+		return err
+	}
+}
+
+// emit writes an event, stamped with the time and command.
+func (s *eventStream) emit(e event) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.Time = time.Now().UTC()
+	e.Command = s.command
+	_ = s.enc.Encode(e)
+}
+
+// phase records that the command moved on to a new phase, e.g. "connect".
+func (s *eventStream) phase(phase string) {
+	s.emit(event{Type: eventPhase, Phase: phase})
+}
+
+// message records a status message shown to the user.
+func (s *eventStream) message(level, message string) {
+	s.emit(event{Type: eventMessage, Level: level, Message: message})
+}
+
+// progress records the bytes processed so far.
+func (s *eventStream) progress(label string, bytes, total int64) {
+	s.emit(event{Type: eventProgress, Message: label, Bytes: bytes, Total: total})
+}
+
+// complete records the result of a successful command.
+func (s *eventStream) complete(result interface{}) {
+	if s != nil {
+		s.result = result
+	}
+	s.emit(event{Type: eventComplete, Result: result})
+}
+
+// backupEventResult is the result of a "complete" event of a backup.
+type backupEventResult struct {
+	BackupID        string   `json:"backup_id"`
+	Database        string   `json:"database"`
+	File            string   `json:"file"`
+	SizeBytes       int64    `json:"size_bytes"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Checksum        string   `json:"checksum"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// restoreEventResult is the result of a "complete" event of a restore.
+type restoreEventResult struct {
+	BackupID        string  `json:"backup_id,omitempty"`
+	Source          string  `json:"source,omitempty"`
+	TargetDatabase  string  `json:"target_database"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	DryRun          bool    `json:"dry_run,omitempty"`
+
+	// Plan is what a dry run with --plan found the restore would execute
+	Plan *backup.RestorePlan `json:"plan,omitempty"`
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// timestampLayout is how absolute times are shown.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// absoluteTimes shows timestamps instead of relative times, set by
+// --absolute.
+var absoluteTimes bool
+
+// absoluteFlag shows timestamps instead of relative times.
+func absoluteFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "absolute",
+		Usage:   "Show timestamps instead of relative times such as '2 hours ago'",
+		EnvVars: []string{"CADANGKAN_ABSOLUTE_TIMES"},
+	}
+}
+
+// applyAbsoluteFlag is the Before hook of commands with absoluteFlag.
+func applyAbsoluteFlag(c *cli.Context) error {
+	absoluteTimes = c.Bool("absolute")
+	return nil
+}
+
+// formatTimestamp formats t in local time.
+func formatTimestamp(t time.Time) string {
+	return t.Local().Format(timestampLayout)
+}
+
+// formatRelative formats t relative to now, e.g. "2 hours ago" or
+// "in 3 days".
+func formatRelative(t time.Time) string {
+	diff := time.Until(t)
+	future := diff > 0
+	if !future {
+		diff = -diff
+	}
+
+	if diff < time.Minute {
+		if future {
+			return "in under a minute"
+		}
+		return "just now"
+	}
+
+	var amount string
+	switch {
+	case diff < time.Hour:
+		amount = plural(int(diff.Minutes()), "minute")
+	case diff < 24*time.Hour:
+		amount = plural(int(diff.Hours()), "hour")
+	case diff < 30*24*time.Hour:
+		amount = plural(int(diff.Hours()/24), "day")
+	case diff < 365*24*time.Hour:
+		amount = plural(int(diff.Hours()/(24*30)), "month")
+	default:
+		amount = plural(int(diff.Hours()/(24*365)), "year")
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// plural formats a count of unit, e.g. "1 hour" or "3 hours".
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// formatTimeAgo formats a past time as "X ago" (e.g., "2 hours ago"), or as
+// a timestamp with --absolute.
+func formatTimeAgo(t time.Time) string {
+	if absoluteTimes {
+		return formatTimestamp(t)
+	}
+	return formatRelative(t)
+}
+
+// formatNextRun formats a future time as "in X" (e.g., "in 3 days"), or as
+// a timestamp with --absolute. Past times are "overdue".
+func formatNextRun(t time.Time) string {
+	if time.Until(t) < 0 {
+		return "overdue"
+	}
+	if absoluteTimes {
+		return formatTimestamp(t)
+	}
+	return formatRelative(t)
+}
+
+// formatTimeDetail formats t as a timestamp followed by the relative time,
+// or as the timestamp alone with --absolute.
+func formatTimeDetail(t time.Time) string {
+	if absoluteTimes {
+		return formatTimestamp(t)
+	}
+	return fmt.Sprintf("%s (%s)", formatTimestamp(t), formatRelative(t))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/urfave/cli/v2"
+)
+
+func gcCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "gc",
+		Usage:     "Remove files left in storage by crashed or interrupted operations",
+		ArgsUsage: "[flags] [name]",
+		Description: `Find the files in the backup directories that no backup accounts for
+   and remove them. Backup listings skip these files, so without gc they
+   stay on disk forever:
+
+     orphaned   backup file without metadata
+     dangling   metadata whose backup file is missing
+     temp       temporary file or directory of an interrupted write
+
+   Every database is checked unless a name is given. Files modified within
+   --min-age are left alone, as they may belong to a backup still running.
+   Use --dry-run to see what would be removed.
+
+   EXAMPLES:
+     cadangkan gc --dry-run
+     cadangkan gc production`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be removed without removing anything",
+			},
+			&cli.DurationFlag{
+				Name:  "min-age",
+				Value: defaultGCMinAge,
+				Usage: "Only remove files not modified for this long",
+			},
+		},
+		Action: runGC,
+	}
+}
+
+// defaultGCMinAge is how long a file must be left unmodified before gc
+// considers it abandoned.
+const defaultGCMinAge = time.Hour
+
+func runGC(c *cli.Context) error {
+	dryRun := c.Bool("dry-run")
+	if !dryRun {
+		if err := maintenance.Check("gc"); err != nil {
+			return err
+		}
+	}
+
+	localStorage, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The databases to clean, with the storage holding their backups
+	type gcTarget struct {
+		name string
+		stor *storage.LocalStorage
+	}
+	var targets []gcTarget
+	if c.NArg() > 0 {
+		name := c.Args().First()
+		targets = append(targets, gcTarget{name, localStorage.At(cfg.GetEffectiveStoragePath(name))})
+	} else {
+		for _, stor := range storageRoots(localStorage, cfg) {
+			names, err := stor.ListDatabases()
+			if err != nil {
+				return fmt.Errorf("failed to list databases: %w", err)
+			}
+			for _, name := range names {
+				targets = append(targets, gcTarget{name, stor})
+			}
+		}
+	}
+
+	var found, removed, failed int
+	var reclaimed int64
+	for _, target := range targets {
+		name, stor := target.name, target.stor
+		strays, err := stor.FindStrayFiles(name, c.Duration("min-age"))
+		if err != nil {
+			printError(fmt.Sprintf("%s: %v", name, err))
+			failed++
+			continue
+		}
+		found += len(strays)
+
+		for _, stray := range strays {
+			path := stray.Path
+			if rel, err := filepath.Rel(stor.GetBasePath(), stray.Path); err == nil {
+				path = rel
+			}
+			line := fmt.Sprintf("%s%-9s%s %-10s %s", colorYellow, stray.Kind, colorReset, backup.FormatBytes(stray.SizeBytes), path)
+			if dryRun {
+				fmt.Println("  " + line)
+				reclaimed += stray.SizeBytes
+				continue
+			}
+
+			err := stor.RemoveStrayFile(stray)
+			recordAudit(audit.ActionGC, name, filepath.Base(stray.Path), err, stray.Kind)
+			if err != nil {
+				printError(fmt.Sprintf("%s: %v", path, err))
+				failed++
+				continue
+			}
+			fmt.Println("  " + line)
+			removed++
+			reclaimed += stray.SizeBytes
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case found == 0:
+		printSuccess("No stray files found")
+	case dryRun:
+		printInfo(fmt.Sprintf("Dry run: %d stray file(s) would be removed, reclaiming %s", found, backup.FormatBytes(reclaimed)))
+	default:
+		printSuccess(fmt.Sprintf("Removed %d stray file(s), reclaimed %s", removed, backup.FormatBytes(reclaimed)))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d stray file(s) or database(s) could not be cleaned", failed)
+	}
+	return nil
+}
+
+// storageRoots returns the storage in the default backup directory and in
+// each backup directory configured for a single database.
+func storageRoots(localStorage *storage.LocalStorage, cfg *config.Config) []*storage.LocalStorage {
+	stors := []*storage.LocalStorage{localStorage}
+	for _, path := range cfg.StoragePaths() {
+		if stor := localStorage.At(path); stor != localStorage {
+			stors = append(stors, stor)
+		}
+	}
+	return stors
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/urfave/cli/v2"
+)
+
+func grepCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "grep",
+		Usage:     "Search the contents of a backup without restoring it",
+		ArgsUsage: "[flags] <name> <pattern>",
+		Description: `Stream a backup through a search for a string, such as an email
+   address for a data subject request, and report the tables and rows that
+   contain it. The pattern is a plain string unless --regex is given.
+
+   Each row of an INSERT statement is matched on its own, so the context
+   shown is the matching row. Only mysqldump backups can be searched.
+   Exits with 1 when nothing matches.
+
+   EXAMPLES:
+     cadangkan grep production jane@example.com
+     cadangkan grep --from 2025-01-15-020000 -i production jane@example.com
+     cadangkan grep --all --regex production '[a-z]+@example\.com'`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Backup ID to search (default: latest)",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Search every backup of the database, newest first",
+			},
+			&cli.BoolFlag{
+				Name:  "regex",
+				Usage: "Treat the pattern as a regular expression",
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "Match regardless of case",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Value: 20,
+				Usage: "Matches shown per backup (0 = all); every match is counted",
+			},
+			&cli.IntFlag{
+				Name:  "context",
+				Value: backup.DefaultSearchContext,
+				Usage: "Longest context shown for a match, in bytes",
+			},
+		},
+		Action: runGrep,
+	}
+}
+
+func runGrep(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return fmt.Errorf("database name and pattern are required\n\nUsage: cadangkan grep [flags] <name> <pattern>")
+	}
+	name := c.Args().Get(0)
+	pattern := c.Args().Get(1)
+	if c.IsSet("from") && c.Bool("all") {
+		return fmt.Errorf("--from and --all cannot be used together")
+	}
+
+	expr := pattern
+	if !c.Bool("regex") {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if c.Bool("ignore-case") {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	var ids []string
+	switch {
+	case c.Bool("all"):
+		backups, err := localStorage.ListBackups(name)
+		if err != nil {
+			return fmt.Errorf("failed to list backups: %w", err)
+		}
+		for _, b := range backups {
+			if b.Status == backup.StatusCompleted || b.Status == "" {
+				ids = append(ids, b.BackupID)
+			}
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no backups found for '%s'", name)
+		}
+	case c.IsSet("from"):
+		ids = []string{c.String("from")}
+	default:
+		latest, err := localStorage.GetLatestBackup(name)
+		if err != nil {
+			return fmt.Errorf("failed to find latest backup of '%s': %w", name, err)
+		}
+		ids = []string{latest.BackupID}
+	}
+
+	options := backup.SearchOptions{Pattern: re, Limit: c.Int("limit"), Context: c.Int("context")}
+	total := 0
+	for _, id := range ids {
+		printInfo(fmt.Sprintf("Searching backup %s...", id))
+		result, err := searchBackup(localStorage, name, id, options)
+		recordAudit(audit.ActionSearch, name, id, err, fmt.Sprintf("pattern %q", pattern))
+		if err != nil {
+			// One unreadable backup should not hide matches in the others
+			if len(ids) > 1 {
+				printWarning(fmt.Sprintf("Skipping backup %s: %v", id, err))
+				continue
+			}
+			return fmt.Errorf("failed to search backup %s: %w", id, err)
+		}
+		total += result.Total
+		printSearchResult(id, result)
+	}
+
+	if total == 0 {
+		printInfo("No matches found")
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// searchBackup searches the SQL dump of a stored backup.
+func searchBackup(localStorage *storage.LocalStorage, name, id string, options backup.SearchOptions) (*backup.SearchResult, error) {
+	reader, err := backup.OpenDump(localStorage, name, id)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return backup.SearchDump(reader, options)
+}
+
+// printSearchResult shows the matches of one backup and how many each
+// table has.
+func printSearchResult(id string, result *backup.SearchResult) {
+	if result.Total == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s%s%s: %d match(es)\n", colorCyan, id, colorReset, result.Total)
+	for _, match := range result.Matches {
+		table := match.Table
+		if table == "" {
+			table = "-"
+		}
+		fmt.Printf("  %s%-24s%s %s\n", colorYellow, table, colorReset, match.Context)
+	}
+	if shown := len(result.Matches); shown < result.Total {
+		fmt.Printf("  ... %d more (use --limit 0 to show all)\n", result.Total-shown)
+	}
+
+	tables := make([]string, 0, len(result.Tables))
+	for table := range result.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	fmt.Print("  Tables:")
+	for _, table := range tables {
+		label := table
+		if label == "" {
+			label = "(header)"
+		}
+		fmt.Printf(" %s (%d)", label, result.Tables[table])
+	}
+	fmt.Println()
+}
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
 	"github.com/erickhilda/cadangkan/internal/config"
 	"github.com/erickhilda/cadangkan/internal/status"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/urfave/cli/v2"
 )
 
@@ -36,7 +35,7 @@ func runHealth(c *cli.Context) error {
 	dbName := c.Args().Get(0)
 
 	// Create storage and config manager
-	storageInstance, err := storage.NewLocalStorage("")
+	storageInstance, err := newDatabaseStorage(dbName)
 	if err != nil {
 		return fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -52,9 +51,13 @@ func runHealth(c *cli.Context) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if _, exists := cfg.Databases[dbName]; !exists {
+	dbConfig, exists := cfg.Database(dbName)
+	if !exists {
 		return fmt.Errorf("database '%s' not found", dbName)
 	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
 
 	// Get all backups for health calculation
 	storageBackups, err := storageInstance.ListBackups(dbName)
@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
 	"github.com/urfave/cli/v2"
 )
@@ -16,19 +19,25 @@ import (
 func importCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "import",
-		Usage:     "Import an external SQL dump file into a configured database",
+		Usage:     "Import an external SQL dump file or directory into a configured database",
 		ArgsUsage: "<config-name>",
 		Description: `Import an external SQL dump file (from mysqldump, DBeaver, TablePlus, etc.)
 into a database already configured in cadangkan.
 
+   --file may also point to a directory. Plain directories are imported file by
+   file in lexical order (.sql and .sql.gz). Directories created by mydumper are
+   detected by their "metadata" file and imported schemas first, then data,
+   then views, triggers and routines.
+
    EXAMPLES:
      cadangkan import mydb --file /path/to/dump.sql
+     cadangkan import mydb --file /path/to/dumps/ --continue-on-error
      cadangkan import mydb --file /path/to/dump.sql.gz --create-db --yes
      cadangkan import mydb --file /path/to/dump.sql --to other_db`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "file",
-				Usage:    "Path to the SQL dump file (.sql or .sql.gz)",
+				Usage:    "Path to the SQL dump file (.sql or .sql.gz) or a directory of dump files",
 				Required: true,
 			},
 			&cli.StringFlag{
@@ -39,11 +48,28 @@ into a database already configured in cadangkan.
 				Name:  "create-db",
 				Usage: "Create database if it doesn't exist",
 			},
+			&cli.BoolFlag{
+				Name:  "continue-on-error",
+				Usage: "Keep importing remaining files when one file fails (directory imports)",
+			},
+			&cli.StringFlag{
+				Name:  "mysql-path",
+				Usage: "mysql client program to run, e.g. /opt/mysql-8.4/bin/mysql (overrides config)",
+			},
+			&cli.StringFlag{
+				Name:  "sql-mode",
+				Usage: "Session sql_mode for the import: relaxed or a list of modes (default: the server's)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-fast",
+				Usage: "Keep foreign key checks, unique checks and autocommit on during the import",
+			},
 			&cli.BoolFlag{
 				Name:    "yes",
 				Aliases: []string{"y"},
 				Usage:   "Skip confirmation prompt",
 			},
+			confirmFlag(),
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
@@ -61,9 +87,17 @@ func runImport(c *cli.Context) error {
 	}
 	name := c.Args().Get(0)
 
-	// Validate file exists
+	if err := maintenance.Check("import"); err != nil {
+		return err
+	}
+
+	if err := backup.ValidateSQLMode(c.String("sql-mode")); err != nil {
+		return err
+	}
+
+	// Validate file or directory exists and collect files to import
 	filePath := c.String("file")
-	fileInfo, err := os.Stat(filePath)
+	plan, err := backup.PlanImport(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			printError(fmt.Sprintf("File not found: %s", filePath))
@@ -71,9 +105,6 @@ func runImport(c *cli.Context) error {
 		}
 		return fmt.Errorf("cannot access file: %w", err)
 	}
-	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", filePath)
-	}
 
 	// Load database config
 	mgr, err := config.NewManager()
@@ -89,23 +120,38 @@ func runImport(c *cli.Context) error {
 		fmt.Printf("Add a database:      run %scadangkan add mysql %s%s\n", colorCyan, name, colorReset)
 		return err
 	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
 
-	// Decrypt password
-	password, err := config.DecryptPassword(dbConfig.PasswordEncrypted)
+	// Resolve password
+	password, err := dbConfig.Password()
 	if err != nil {
-		return fmt.Errorf("failed to decrypt password: %w", err)
+		return err
 	}
 
-	// Detect compression from file extension
-	compression := backup.CompressionNone
-	lowerPath := strings.ToLower(filePath)
-	if strings.HasSuffix(lowerPath, ".gz") {
-		compression = backup.CompressionGzip
+	mysqlPath := c.String("mysql-path")
+	if !c.IsSet("mysql-path") {
+		if cfg, err := mgr.Load(); err == nil {
+			mysqlPath = cfg.GetEffectiveMysqlPath(name)
+		}
+	}
+
+	// Connect to MySQL server (without specifying database)
+	mysqlConfig := &mysql.Config{
+		Host:      dbConfig.Host,
+		Port:      dbConfig.Port,
+		User:      dbConfig.User,
+		Password:  password,
+		Database:  "",
+		Timeout:   10 * time.Second,
+		SSL:       backupconfig.SSL(dbConfig.TLS),
+		MysqlPath: mysqlPath,
 	}
 
 	// Check mysql CLI availability
 	printInfo("Checking mysql availability...")
-	version, err := backup.CheckMySQL()
+	version, err := backup.CheckMySQL(mysqlConfig)
 	if err != nil {
 		printError("mysql not found")
 		fmt.Println("\nPlease install MySQL client tools:")
@@ -122,25 +168,15 @@ func runImport(c *cli.Context) error {
 		targetDatabase = c.String("to")
 	}
 
-	// Connect to MySQL server (without specifying database)
-	mysqlConfig := &mysql.Config{
-		Host:     dbConfig.Host,
-		Port:     dbConfig.Port,
-		User:     dbConfig.User,
-		Password: password,
-		Database: "",
-		Timeout:  10 * time.Second,
-	}
-
 	printInfo(fmt.Sprintf("Connecting to %s@%s:%d...", dbConfig.User, dbConfig.Host, dbConfig.Port))
 	client, err := mysql.NewClient(mysqlConfig)
 	if err != nil {
-		printError("Failed to create MySQL client")
+		printError(i18n.T("Failed to create MySQL client"))
 		return err
 	}
 
 	if err := client.Connect(); err != nil {
-		printError("Connection failed")
+		printError(i18n.T("Connection failed"))
 		return err
 	}
 	defer client.Close()
@@ -150,6 +186,9 @@ func runImport(c *cli.Context) error {
 		dbVersion = "unknown"
 	}
 	printSuccess(fmt.Sprintf("Connected to database (MySQL %s)", dbVersion))
+	if warning := backup.ClientCompatibility(version, dbVersion); warning != "" {
+		printWarning(warning)
+	}
 
 	// Check if target database exists
 	dbExists, err := client.DatabaseExists(targetDatabase)
@@ -165,44 +204,50 @@ func runImport(c *cli.Context) error {
 
 	// Show confirmation summary
 	fmt.Println()
-	printWarning("WARNING: This will import data into the database")
+	printWarning(i18n.T("WARNING: This will import data into the database"))
 	if dbExists {
 		printWarning(fmt.Sprintf("Current data in '%s' may be overwritten!", targetDatabase))
 	}
 	fmt.Println()
 
-	compressionLabel := "none"
-	if compression == backup.CompressionGzip {
-		compressionLabel = "gzip"
+	if plan.Layout == backup.ImportLayoutFile {
+		fmt.Printf("Import file:\n")
+		fmt.Printf("  %sFile:%s        %s\n", colorCyan, colorReset, filePath)
+		fmt.Printf("  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(plan.TotalBytes))
+		fmt.Printf("  %sCompression:%s %s\n", colorCyan, colorReset, plan.Files[0].Compression)
+	} else {
+		fmt.Printf("Import directory:\n")
+		fmt.Printf("  %sPath:%s        %s\n", colorCyan, colorReset, filePath)
+		fmt.Printf("  %sLayout:%s      %s\n", colorCyan, colorReset, plan.Layout)
+		fmt.Printf("  %sFiles:%s       %d\n", colorCyan, colorReset, len(plan.Files))
+		fmt.Printf("  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(plan.TotalBytes))
 	}
-
-	fmt.Printf("Import file:\n")
-	fmt.Printf("  %sFile:%s        %s\n", colorCyan, colorReset, filePath)
-	fmt.Printf("  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(fileInfo.Size()))
-	fmt.Printf("  %sCompression:%s %s\n", colorCyan, colorReset, compressionLabel)
 	fmt.Println()
 
 	fmt.Printf("Target database:\n")
 	fmt.Printf("  %sName:%s        %s\n", colorCyan, colorReset, targetDatabase)
 	fmt.Printf("  %sHost:%s        %s:%d\n", colorCyan, colorReset, dbConfig.Host, dbConfig.Port)
 	if dbExists {
-		printInfo("Database exists - data may be overwritten")
+		printInfo(i18n.T("Database exists - data may be overwritten"))
 	} else {
-		printInfo("Database will be created")
+		printInfo(i18n.T("Database will be created"))
 	}
 	fmt.Println()
 
 	// Confirmation prompt
-	if !c.Bool("yes") {
-		fmt.Print("Continue? [y/N]: ")
+	confirmed, err := confirmProtected(c, name, "import")
+	if err != nil {
+		return err
+	}
+	if !confirmed && !c.Bool("yes") {
+		fmt.Print(i18n.T("Continue? [y/N]: "))
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
 		if err != nil {
 			return fmt.Errorf("failed to read confirmation: %w", err)
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			printInfo("Import cancelled")
+		if !i18n.Affirmative(response) {
+			printInfo(i18n.T("Import cancelled"))
 			return nil
 		}
 		fmt.Println()
@@ -218,39 +263,31 @@ func runImport(c *cli.Context) error {
 		printSuccess(fmt.Sprintf("Database '%s' created", targetDatabase))
 	}
 
-	// Open file
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	// Decompress if needed
-	decompressor := backup.NewDecompressor(compression)
-	sqlReader, err := decompressor.DecompressToReader(file)
-	if err != nil {
-		return fmt.Errorf("failed to decompress file: %w", err)
-	}
-	defer sqlReader.Close()
-
 	// Execute restore via MySQLRestorer
-	printInfo("Starting import...")
-
-	done := make(chan bool)
-	go showImportSpinner(done)
+	printInfo(i18n.T("Starting import..."))
 
 	startTime := time.Now()
 
 	// Use a separate config for the restorer without the short connection timeout,
 	// so the import gets the default 30-minute timeout instead of 60 seconds.
 	restorerConfig := &mysql.Config{
-		Host:     dbConfig.Host,
-		Port:     dbConfig.Port,
-		User:     dbConfig.User,
-		Password: password,
-		Database: "",
+		Host:      dbConfig.Host,
+		Port:      dbConfig.Port,
+		User:      dbConfig.User,
+		Password:  password,
+		Database:  "",
+		SSL:       mysqlConfig.SSL,
+		MysqlPath: mysqlConfig.MysqlPath,
 	}
 	restorer := backup.NewMySQLRestorer(restorerConfig)
+	restorer.SetFastMode(!c.Bool("no-fast"))
+	sqlMode, err := backup.ResolveSQLMode(c.String("sql-mode"), backup.DatabaseInfo{})
+	if err != nil {
+		return err
+	}
+	if sqlMode != "" {
+		restorer.SetSQLMode(sqlMode)
+	}
 
 	var cmdLogger func(string)
 	if c.Bool("verbose") {
@@ -259,19 +296,36 @@ func runImport(c *cli.Context) error {
 		}
 	}
 
-	err = restorer.RestoreWithCommand(targetDatabase, sqlReader, cmdLogger)
-	done <- true
+	failed, err := importFiles(plan, restorer, targetDatabase, cmdLogger, c.Bool("continue-on-error"))
 
-	if err != nil {
-		printError("Import failed")
+	duration := time.Since(startTime)
+
+	auditErr := err
+	if auditErr == nil && len(failed) > 0 {
+		auditErr = fmt.Errorf("%d of %d file(s) failed to import", len(failed), len(plan.Files))
+	}
+	recordAudit(audit.ActionImport, name, filePath, auditErr, fmt.Sprintf("into %s", targetDatabase))
+
+	if err != nil && plan.Layout == backup.ImportLayoutFile {
+		printError(i18n.T("Import failed"))
 		return err
 	}
 
-	duration := time.Since(startTime)
+	if len(failed) > 0 {
+		fmt.Println()
+		printError(fmt.Sprintf("Import finished with %d failed file(s):", len(failed)))
+		for _, name := range failed {
+			fmt.Printf("  - %s\n", name)
+		}
+		return fmt.Errorf("%d of %d file(s) failed to import", len(failed), len(plan.Files))
+	}
 
-	printSuccess("Import completed!")
+	printSuccess(i18n.T("Import completed!"))
 	fmt.Println()
 	fmt.Printf("  %sFile:%s        %s\n", colorCyan, colorReset, filePath)
+	if plan.Layout != backup.ImportLayoutFile {
+		fmt.Printf("  %sFiles:%s       %d\n", colorCyan, colorReset, len(plan.Files))
+	}
 	fmt.Printf("  %sDatabase:%s    %s\n", colorCyan, colorReset, targetDatabase)
 	fmt.Printf("  %sDuration:%s    %s\n", colorCyan, colorReset, backup.FormatDuration(duration))
 	fmt.Println()
@@ -280,19 +334,65 @@ func runImport(c *cli.Context) error {
 	return nil
 }
 
-// showImportSpinner displays a spinner during import
-func showImportSpinner(done chan bool) {
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	i := 0
-	for {
-		select {
-		case <-done:
-			fmt.Print("\r")
-			return
-		default:
-			fmt.Printf("\r%s Importing... ", spinner[i%len(spinner)])
-			i++
-			time.Sleep(100 * time.Millisecond)
+// importFiles imports each file of the plan in order and returns the names of
+// files that failed along with the first error. Without continueOnError it
+// stops at the first failure.
+func importFiles(plan *backup.ImportPlan, restorer *backup.MySQLRestorer, targetDatabase string, cmdLogger func(string), continueOnError bool) ([]string, error) {
+	var failed []string
+	var firstErr error
+	multi := len(plan.Files) > 1
+
+	for i, file := range plan.Files {
+		if multi {
+			fmt.Printf("[%d/%d] %s (%s)\n", i+1, len(plan.Files), file.Name, backup.FormatBytes(file.SizeBytes))
+		}
+
+		bar := startProgress("Importing", file.SizeBytes)
+		fileStart := time.Now()
+		err := importFile(file, restorer, targetDatabase, cmdLogger, bar.update)
+		bar.stop()
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed = append(failed, file.Name)
+			if !multi {
+				return failed, firstErr
+			}
+			printError(fmt.Sprintf("Failed to import %s: %v", file.Name, err))
+			if !continueOnError {
+				if multi && i < len(plan.Files)-1 {
+					printInfo(fmt.Sprintf("Skipped %d remaining file(s); use --continue-on-error to keep going", len(plan.Files)-i-1))
+				}
+				return failed, firstErr
+			}
+			continue
+		}
+
+		if multi {
+			printSuccess(fmt.Sprintf("Imported %s in %s", file.Name, backup.FormatDuration(time.Since(fileStart))))
 		}
 	}
+
+	return failed, firstErr
+}
+
+// importFile decompresses (if needed) and pipes a single dump file into mysql,
+// reporting the bytes of the file read so far to report.
+func importFile(file backup.ImportFile, restorer *backup.MySQLRestorer, targetDatabase string, cmdLogger func(string), report backup.ProgressFunc) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	decompressor := backup.NewDecompressor(file.Compression)
+	sqlReader, err := decompressor.DecompressToReader(backup.ProgressReader(f, report))
+	if err != nil {
+		return fmt.Errorf("failed to decompress file: %w", err)
+	}
+	defer sqlReader.Close()
+
+	return restorer.RestoreWithCommand(targetDatabase, sqlReader, cmdLogger)
 }
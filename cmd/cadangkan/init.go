@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func initCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "init",
+		Usage: "Set up cadangkan interactively",
+		Description: `Walk through adding a database, testing the connection, choosing
+   where backups are stored, scheduling backups and setting retention, then
+   write the configuration. Each question shows its default in brackets;
+   press Enter to accept it.
+
+   This does the work of add, schedule set and editing the storage and
+   retention settings in one guided flow.
+
+   EXAMPLES:
+     cadangkan init`,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "skip-test",
+				Usage: "Skip connection test",
+			},
+		},
+		Action: runInit,
+	}
+}
+
+// prompter asks the questions of the init wizard.
+type prompter struct {
+	reader *bufio.Reader
+}
+
+// ask asks a question, returning def when the answer is empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// askValid asks a question until parse accepts the answer.
+func (p *prompter) askValid(question, def string, parse func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if err := parse(answer); err != nil {
+			printError(err.Error())
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// askRequired asks a question until the answer is not empty.
+func (p *prompter) askRequired(question, def string) (string, error) {
+	return p.askValid(question, def, func(answer string) error {
+		if answer == "" {
+			return fmt.Errorf("an answer is required")
+		}
+		return nil
+	})
+}
+
+// askInt asks for a number of at least min.
+func (p *prompter) askInt(question string, def, min int) (int, error) {
+	answer, err := p.askValid(question, strconv.Itoa(def), func(answer string) error {
+		if n, err := strconv.Atoi(answer); err != nil || n < min {
+			return fmt.Errorf("enter a number of at least %d", min)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(answer)
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Printf("%s [%s]: ", question, hint)
+	answer, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	if strings.TrimSpace(answer) == "" {
+		return def, nil
+	}
+	return i18n.Affirmative(answer), nil
+}
+
+// askPassword asks for a password without echoing it on a terminal.
+func (p *prompter) askPassword(question string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return p.askRequired(question, "")
+	}
+	for {
+		fmt.Printf("%s: ", question)
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println() // New line after password input
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		if len(password) > 0 {
+			return string(password), nil
+		}
+		printError("password cannot be empty")
+	}
+}
+
+// initSection prints the heading of a step of the wizard.
+func initSection(title string) {
+	fmt.Printf("\n%s%s%s\n", colorCyan, title, colorReset)
+}
+
+func runInit(c *cli.Context) error {
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	p := &prompter{reader: bufio.NewReader(os.Stdin)}
+	fmt.Println("This wizard adds a database, schedules its backups and writes the configuration.")
+	fmt.Println("Press Enter to accept the default shown in brackets.")
+
+	// Database
+	initSection("Database")
+	engineName, err := p.askValid(fmt.Sprintf("Type (%s)", strings.Join(backup.EngineNames(), ", ")), "mysql",
+		func(answer string) error {
+			_, err := backup.GetEngine(answer)
+			return err
+		})
+	if err != nil {
+		return err
+	}
+	eng, _ := backup.GetEngine(engineName)
+
+	host, err := p.askRequired("Host", "localhost")
+	if err != nil {
+		return err
+	}
+	port, err := p.askInt("Port", eng.DefaultPort(), 1)
+	if err != nil {
+		return err
+	}
+	user, err := p.askRequired("User", "root")
+	if err != nil {
+		return err
+	}
+	database, err := p.askRequired("Database", "")
+	if err != nil {
+		return err
+	}
+	password, err := p.askPassword("Password")
+	if err != nil {
+		return err
+	}
+
+	name, err := p.askValid("Name in cadangkan", config.SanitizeName(database), func(answer string) error {
+		if config.SanitizeName(answer) != answer || answer == "" {
+			return fmt.Errorf("invalid name '%s': use lowercase letters, digits and '_'", answer)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if _, exists := cfg.Databases[name]; exists {
+		printWarning(fmt.Sprintf("Database '%s' already exists, it will be overwritten", name))
+	}
+
+	if !c.Bool("skip-test") {
+		fmt.Println()
+		err := testConnection(eng, &mysql.Config{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			Database: database,
+		})
+		if err != nil {
+			printError(err.Error())
+			keep, err := p.confirm("Save the configuration anyway?", false)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				printInfo(i18n.T("Cancelled"))
+				return nil
+			}
+		}
+	}
+
+	// Storage
+	initSection("Storage")
+	defaultStorage, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	storagePath, err := p.askRequired("Backup directory", defaultStorage.GetBasePath())
+	if err != nil {
+		return err
+	}
+
+	// Schedule
+	initSection("Schedule")
+	var cronExpr string
+	frequency, err := p.askValid("Back up daily, weekly or never", "daily", func(answer string) error {
+		switch answer {
+		case "daily", "weekly", "never":
+			return nil
+		}
+		return fmt.Errorf("answer daily, weekly or never")
+	})
+	if err != nil {
+		return err
+	}
+	switch frequency {
+	case "daily":
+		at, err := p.askValid("Time (HH:MM)", "02:00", func(answer string) error {
+			_, err := parseDailyCron(answer)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		cronExpr, _ = parseDailyCron(at)
+	case "weekly":
+		day, err := p.askValid("Day", "sunday", func(answer string) error {
+			_, err := parseWeeklyCron("00:00", answer)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		at, err := p.askValid("Time (HH:MM)", "03:00", func(answer string) error {
+			_, err := parseWeeklyCron(answer, day)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		cronExpr, _ = parseWeeklyCron(at, day)
+	}
+
+	// Retention
+	initSection("Retention")
+	retention := cfg.GetEffectiveRetention(name)
+	keepAll, err := p.confirm("Keep all backups forever?", retention.KeepAll)
+	if err != nil {
+		return err
+	}
+	policy := &config.RetentionPolicy{KeepAll: keepAll}
+	if !keepAll {
+		if policy.Daily, err = p.askInt("Daily backups to keep", retention.Daily, 0); err != nil {
+			return err
+		}
+		if policy.Weekly, err = p.askInt("Weekly backups to keep", retention.Weekly, 0); err != nil {
+			return err
+		}
+		if policy.Monthly, err = p.askInt("Monthly backups to keep", retention.Monthly, 0); err != nil {
+			return err
+		}
+	}
+
+	// Summary
+	schedule := "none"
+	if cronExpr != "" {
+		schedule = fmt.Sprintf("%s (%s)", frequency, cronExpr)
+	}
+	keep := "all backups"
+	if !keepAll {
+		keep = fmt.Sprintf("%d daily, %d weekly, %d monthly", policy.Daily, policy.Weekly, policy.Monthly)
+	}
+	initSection("Summary")
+	fmt.Printf("  %sName:%s      %s\n", colorCyan, colorReset, name)
+	fmt.Printf("  %sDatabase:%s  %s %s@%s:%d/%s\n", colorCyan, colorReset, eng.Name(), user, host, port, database)
+	fmt.Printf("  %sBackups:%s   %s\n", colorCyan, colorReset, storagePath)
+	fmt.Printf("  %sSchedule:%s  %s\n", colorCyan, colorReset, schedule)
+	fmt.Printf("  %sKeep:%s      %s\n", colorCyan, colorReset, keep)
+	fmt.Println()
+
+	write, err := p.confirm("Write the configuration?", true)
+	if err != nil {
+		return err
+	}
+	if !write {
+		printInfo(i18n.T("Cancelled"))
+		return nil
+	}
+
+	encryptedPassword, err := config.EncryptPassword(password)
+	if err != nil {
+		printError(i18n.T("Failed to encrypt password"))
+		return err
+	}
+	dbConfig := &config.DatabaseConfig{
+		Type:              eng.Name(),
+		Host:              host,
+		Port:              port,
+		Database:          database,
+		User:              user,
+		PasswordEncrypted: encryptedPassword,
+	}
+	// Only override the default retention when it was changed
+	defaultRetention := config.DefaultRetentionPolicy()
+	if cfg.Defaults != nil && cfg.Defaults.Retention != nil {
+		defaultRetention = cfg.Defaults.Retention
+	}
+	if *policy != *defaultRetention {
+		dbConfig.Retention = policy
+	}
+	if cronExpr != "" {
+		sc := dbConfig.EnsureSchedule("")
+		sc.Cron = cronExpr
+		sc.Enabled = true
+	}
+
+	if storagePath != defaultStorage.GetBasePath() {
+		if cfg.Storage == nil {
+			cfg.Storage = &config.StorageConfig{}
+		}
+		cfg.Storage.Path = storagePath
+	}
+	if cfg.Databases == nil {
+		cfg.Databases = make(map[string]*config.DatabaseConfig)
+	}
+	cfg.Databases[name] = dbConfig
+
+	printInfo(i18n.T("Saving configuration..."))
+	err = mgr.Save(cfg)
+	recordAudit(audit.ActionConfigAdd, name, "", err, fmt.Sprintf("init %s %s@%s:%d/%s", dbConfig.Type, user, host, port, database))
+	if err != nil {
+		printError(i18n.T("Failed to save configuration"))
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Database '%s' added successfully!", name))
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Printf("  Run a first backup:   %scadangkan backup %s%s\n", colorCyan, name, colorReset)
+	if cronExpr != "" {
+		fmt.Printf("  Start the scheduler:  %scadangkan daemon%s\n", colorCyan, colorReset)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/k8s"
+	"github.com/urfave/cli/v2"
+)
+
+func k8sCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "k8s",
+		Usage: "Generate Kubernetes manifests",
+		Subcommands: []*cli.Command{
+			k8sGenerateCommand(),
+		},
+	}
+}
+
+func k8sGenerateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "generate",
+		Usage:     "Generate a CronJob backing up a database in-cluster",
+		ArgsUsage: "<name>",
+		Description: `Print manifests running 'cadangkan backup' for a configured database
+   as a Kubernetes CronJob:
+
+     - a Secret holding the database password, mounted into the pod
+     - a PersistentVolumeClaim receiving the backups (unless --claim is set)
+     - a CronJob on the database's schedule, with its backup options
+
+   The pod connects using CADANGKAN_HOST, CADANGKAN_PORT, CADANGKAN_USER,
+   CADANGKAN_DATABASE and CADANGKAN_PASSWORD_FILE, so no config.yaml or
+   encryption key is needed in the cluster. The Secret contains the password
+   in plain text: review the output before committing it anywhere.
+
+   EXAMPLES:
+     cadangkan k8s generate production | kubectl apply -f -
+     cadangkan k8s generate production -s hourly --namespace=shop
+     cadangkan k8s generate production --cron="0 3 * * *" --claim=backups`,
+		Flags: []cli.Flag{
+			scheduleNameFlag(),
+			&cli.StringFlag{
+				Name:  "cron",
+				Usage: "Cron expression (default: the schedule's)",
+			},
+			&cli.StringFlag{
+				Name:  "namespace",
+				Value: k8s.DefaultNamespace,
+				Usage: "Namespace of the generated resources",
+			},
+			&cli.StringFlag{
+				Name:  "image",
+				Value: k8s.DefaultImage,
+				Usage: "Container image providing the cadangkan binary and mysqldump",
+			},
+			&cli.StringFlag{
+				Name:  "claim",
+				Usage: "Existing PersistentVolumeClaim for the backups (default: generate one)",
+			},
+			&cli.StringFlag{
+				Name:  "storage-size",
+				Value: k8s.DefaultStorageSize,
+				Usage: "Size of the generated PersistentVolumeClaim",
+			},
+		},
+		Action: runK8sGenerate,
+	}
+}
+
+func runK8sGenerate(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan k8s generate <name> [flags]")
+	}
+
+	name := c.Args().Get(0)
+
+	// Manifests go to stdout, messages to stderr
+	msgOut = os.Stderr
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil {
+		printError(fmt.Sprintf("Database '%s' not found in config", name))
+		return err
+	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
+
+	// Start from the configured schedule, if any
+	schedule := &config.ScheduleConfig{}
+	if configured := dbConfig.FindSchedule(c.String("schedule")); configured != nil {
+		*schedule = *configured
+	} else if c.IsSet("schedule") {
+		return fmt.Errorf("no schedule %q configured for %s", c.String("schedule"), name)
+	}
+	if c.IsSet("cron") {
+		schedule.Cron = c.String("cron")
+	}
+	if schedule.Cron == "" {
+		return fmt.Errorf("no schedule configured for %s; set one with --cron", name)
+	}
+
+	password, err := dbConfig.Password()
+	if err != nil {
+		return err
+	}
+
+	return k8s.Generate(os.Stdout, &k8s.Options{
+		Name:        name,
+		Database:    dbConfig,
+		Password:    password,
+		Schedule:    schedule,
+		Namespace:   c.String("namespace"),
+		Image:       c.String("image"),
+		ClaimName:   c.String("claim"),
+		StorageSize: c.String("storage-size"),
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+// langFlag is the global flag selecting the language of messages.
+func langFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "lang",
+		Usage:   "Language of messages: en or id (default: from LC_ALL, LC_MESSAGES or LANG)",
+		EnvVars: []string{"CADANGKAN_LANG"},
+	}
+}
+
+// setupLanguage selects the language of messages before any command runs.
+// An explicit --lang must be supported, while an unsupported locale from
+// the environment falls back to English.
+func setupLanguage(c *cli.Context) error {
+	lang := i18n.FromEnv()
+	if c.IsSet("lang") {
+		var err error
+		if lang, err = i18n.Parse(c.String("lang")); err != nil {
+			return err
+		}
+	}
+	i18n.SetLanguage(lang)
+	return nil
+}
+
+// setupGlobal applies the global flags before any command runs.
+func setupGlobal(c *cli.Context) error {
+	if err := setupLanguage(c); err != nil {
+		return err
+	}
+	if err := setupColor(c); err != nil {
+		return err
+	}
+	setupWorkDir()
+	return nil
+}
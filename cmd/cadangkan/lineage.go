@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func lineageCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "lineage",
+		Usage:     "Show how backups depend on each other",
+		ArgsUsage: "<name>",
+		Description: `Show the backups of a database, oldest first, with the links
+   recorded in their metadata:
+
+     - where each copy of the backup is stored
+     - the restores made from it, and the snapshot taken before each
+     - the restore a pre-restore snapshot was taken before
+     - the base backup an incremental backup depends on, and the backups
+       depending on it, which cannot be restored once it is pruned
+
+   EXAMPLES:
+     cadangkan lineage production`,
+		Action: runLineage,
+	}
+}
+
+func runLineage(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan lineage <name>")
+	}
+	name := c.Args().Get(0)
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	nodes, err := backup.BuildLineage(localStorage, name)
+	if err != nil {
+		return fmt.Errorf("failed to list backups for '%s': %w", name, err)
+	}
+	if len(nodes) == 0 {
+		printInfo(fmt.Sprintf("No backups found for '%s'", name))
+		return nil
+	}
+
+	fmt.Printf("\n%sLineage of %s%s\n", colorCyan, colorReset, name)
+	fmt.Println(strings.Repeat("=", 100))
+	for _, node := range nodes {
+		printLineageNode(node)
+	}
+	fmt.Printf("Total: %d backup(s)\n", len(nodes))
+	return nil
+}
+
+// printLineageNode shows a backup followed by its links, one per line.
+func printLineageNode(node *backup.LineageNode) {
+	entry := node.Entry
+	status := entry.Status
+	if entry.Lock.Active(time.Now()) {
+		status += ", locked"
+	}
+	fmt.Printf("%s%s%s  %s  %s  (%s)\n", colorCyan, entry.BackupID, colorReset,
+		entry.CreatedAt.Format("2006-01-02 15:04:05"), entry.SizeHuman, status)
+
+	var links []string
+	links = append(links, fmt.Sprintf("stored:      local %s", entry.FilePath))
+
+	if meta := node.Metadata; meta != nil {
+		if link := meta.PreRestore; link != nil {
+			links = append(links, fmt.Sprintf("snapshot of: %s before restoring %s (%s)",
+				link.TargetDatabase, link.Source, link.RestoredAt.Local().Format("2006-01-02 15:04")))
+		}
+		if meta.Base != "" {
+			base := "based on:    " + meta.Base
+			if node.MissingBase {
+				base += colorRed + " (missing: this backup cannot be restored)" + colorReset
+			}
+			links = append(links, base)
+		}
+		for _, restore := range meta.Restores {
+			line := fmt.Sprintf("restored to: %s on %s:%d (%s)", restore.TargetDatabase,
+				restore.Host, restore.Port, restore.RestoredAt.Local().Format("2006-01-02 15:04"))
+			if restore.SnapshotID != "" {
+				line += ", snapshot " + restore.SnapshotID
+			}
+			links = append(links, line)
+		}
+	} else {
+		links = append(links, colorYellow+"metadata unreadable"+colorReset)
+	}
+	if len(node.Dependents) > 0 {
+		links = append(links, fmt.Sprintf("needed by:   %s%s%s (prune them first)",
+			colorYellow, strings.Join(node.Dependents, ", "), colorReset))
+	}
+
+	for i, link := range links {
+		branch := "├─"
+		if i == len(links)-1 {
+			branch = "└─"
+		}
+		fmt.Printf("  %s %s\n", branch, link)
+	}
+	fmt.Println()
+}
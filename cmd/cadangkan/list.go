@@ -2,19 +2,46 @@ package main
 
 import (
 	"fmt"
-	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/urfave/cli/v2"
 )
 
+// listColumns are the columns of 'cadangkan list'.
+var listColumns = []tableColumn{
+	{key: "name", title: "NAME", width: 20},
+	{key: "type", title: "TYPE", width: 10},
+	{key: "host", title: "HOST", width: 30},
+	{key: "database", title: "DATABASE", width: 20},
+	{key: "backups", title: "BACKUPS", width: 8, hidden: true},
+	{key: "size", title: "SIZE", width: 12, hidden: true},
+	{key: "last_backup", title: "LAST BACKUP", width: 20, hidden: true},
+	{key: "status", title: "STATUS", width: 10, hidden: true},
+}
+
 func listCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "list",
 		Aliases: []string{"ls"},
 		Usage:   "List all configured databases",
-		Action:  runList,
+		Description: `List configured databases with their latest backup.
+
+   Dates and statuses are those of each database's latest backup; sizes are
+   the total of its backups.
+
+   EXAMPLES:
+     cadangkan list
+     cadangkan list --sort size --columns name,size,backups
+     cadangkan list --status failed
+     cadangkan list --before 2d --columns name,last_backup   # Backups older than 2 days`,
+		Flags:  append(listFlags(sortName, listColumns), absoluteFlag()),
+		Before: applyAbsoluteFlag,
+		Action: runList,
 	}
 }
 
@@ -33,34 +60,87 @@ func runList(c *cli.Context) error {
 
 	// Check if there are any databases
 	if len(cfg.Databases) == 0 {
-		printInfo("No databases configured")
+		printInfo(i18n.T("No databases configured"))
 		fmt.Println()
 		fmt.Printf("Add a database with: %scadangkan add mysql <name>%s\n", colorCyan, colorReset)
 		return nil
 	}
 
-	// Get database names and sort them
-	names := make([]string, 0, len(cfg.Databases))
-	for name := range cfg.Databases {
-		names = append(names, name)
+	opts, err := parseListOptions(c, listColumns)
+	if err != nil {
+		return err
 	}
-	sort.Strings(names)
 
-	// Print header
-	fmt.Printf("\n%sConfigured Databases%s\n", colorCyan, colorReset)
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("%-20s %-10s %-30s %s\n", "NAME", "TYPE", "HOST", "DATABASE")
-	fmt.Println(strings.Repeat("-", 80))
-
-	// Print each database
-	for _, name := range names {
-		db := cfg.Databases[name]
-		hostPort := fmt.Sprintf("%s:%d", db.Host, db.Port)
-		fmt.Printf("%-20s %-10s %-30s %s\n", name, db.Type, hostPort, db.Database)
+	localStorage, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	stored, err := localStorage.ListDatabasesIn(cfg.StoragePaths())
+	if err != nil {
+		printWarning(fmt.Sprintf("Failed to list backup directories: %v", err))
+	}
+	backupNames := cfg.BackupNames(stored)
+
+	rows := make([]listRow, 0, len(cfg.Databases))
+	for name, db := range cfg.Databases {
+		row := listRow{
+			name:   name,
+			status: "none",
+			cells: map[string]string{
+				"name":        name,
+				"type":        db.Type,
+				"host":        fmt.Sprintf("%s:%d", db.Host, db.Port),
+				"database":    databaseLabel(db),
+				"last_backup": "never",
+			},
+		}
+
+		// A server entry shows the backups of all its schemas
+		names := []string{name}
+		if db.IsServer() {
+			names = nil
+			for _, backupName := range backupNames {
+				if strings.HasPrefix(backupName, name+".") {
+					names = append(names, backupName)
+				}
+			}
+		}
+		var backups []storage.BackupListEntry
+		for _, backupName := range names {
+			entries, err := localStorage.At(cfg.GetEffectiveStoragePath(backupName)).ListBackups(backupName)
+			if err != nil {
+				printWarning(fmt.Sprintf("Failed to list backups for '%s': %v", backupName, err))
+			}
+			backups = append(backups, entries...)
+		}
+		for _, entry := range backups {
+			row.size += entry.SizeBytes
+			if entry.CreatedAt.After(row.date) {
+				row.date = entry.CreatedAt
+				row.status = entry.Status
+			}
+		}
+		if len(backups) > 0 {
+			row.cells["last_backup"] = formatTimeAgo(row.date)
+		}
+		row.cells["backups"] = strconv.Itoa(len(backups))
+		row.cells["size"] = backup.FormatBytes(row.size)
+		row.cells["status"] = row.status
+		rows = append(rows, row)
 	}
+	rows = opts.apply(rows)
+
+	// Print table
+	fmt.Printf("\n%sConfigured Databases%s\n", colorCyan, colorReset)
+	printTable(opts.columns, rows)
 
 	fmt.Println()
-	fmt.Printf("Total: %d database(s)\n", len(cfg.Databases))
+	if opts.filtering() {
+		fmt.Printf("Showing %d of %d database(s)\n", len(rows), len(cfg.Databases))
+	} else {
+		fmt.Printf("Total: %d database(s)\n", len(cfg.Databases))
+	}
 	fmt.Println()
 	fmt.Printf("Backup a database: %scadangkan backup <name>%s\n", colorCyan, colorReset)
 	fmt.Printf("Test connection:   %scadangkan test <name>%s\n", colorCyan, colorReset)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/erickhilda/cadangkan/internal/i18n"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,31 +19,56 @@ func main() {
 		Name:    AppName,
 		Version: AppVersion,
 		Usage:   AppUsage,
+		Flags:   append(colorFlags(), langFlag()),
+		Before:  setupGlobal,
+		After:   cleanupWorkDir,
 		Commands: []*cli.Command{
 			// Database management
+			initCommand(),
 			addCommand(),
+			discoverCommand(),
 			listCommand(),
 			testCommand(),
 			removeCommand(),
 			editCommand(),
+			applyCommand(),
 			// Backup operations
 			backupCommand(),
+			ciBackupCommand(),
 			backupListCommand(),
+			backupLockCommand(),
+			lineageCommand(),
+			compareCommand(),
+			grepCommand(),
+			redactCommand(),
 			restoreCommand(),
+			rollbackCommand(),
+			extractCommand(),
+			seedCommand(),
 			importCommand(),
 			cleanupCommand(),
+			gcCommand(),
+			repairCommand(),
 			// Scheduling
 			scheduleCommand(),
 			daemonCommand(),
+			k8sCommand(),
 			// Status & monitoring
 			statusCommand(),
 			healthCommand(),
+			checkCommand(),
 			storageCommand(),
+			digestCommand(),
+			reportCommand(),
+			auditCommand(),
+			maintenanceCommand(),
+			pluginsCommand(),
+			doctorCommand(),
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprint(os.Stderr, i18n.T("Error: %v\n", err))
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/urfave/cli/v2"
+)
+
+func maintenanceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "maintenance",
+		Usage: "Block restores and deletions (read-only maintenance mode)",
+		Description: `Turn maintenance mode on during incident response to prevent
+   accidental destructive operations by teammates or automation.
+
+   While it is on:
+     - restore, import and cleanup refuse to run (--dry-run still works)
+     - backups still run, but never prune old backups for a quota
+     - the daemon skips retention cleanup after scheduled backups
+
+   The state is kept in maintenance.json in the data directory and every
+   change is recorded in the audit log.
+
+   USAGE:
+     cadangkan maintenance on --reason "incident 42"
+     cadangkan maintenance off
+     cadangkan maintenance status`,
+		Subcommands: []*cli.Command{
+			maintenanceOnCommand(),
+			maintenanceOffCommand(),
+			maintenanceStatusCommand(),
+		},
+		Action: runMaintenanceStatus,
+	}
+}
+
+func maintenanceOnCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "on",
+		Usage: "Turn maintenance mode on",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "reason",
+				Usage: "Why maintenance mode is on, shown to anyone blocked by it",
+			},
+		},
+		Action: runMaintenanceOn,
+	}
+}
+
+func maintenanceOffCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "off",
+		Usage:  "Turn maintenance mode off",
+		Action: runMaintenanceOff,
+	}
+}
+
+func maintenanceStatusCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "status",
+		Usage:  "Show whether maintenance mode is on",
+		Action: runMaintenanceStatus,
+	}
+}
+
+func runMaintenanceOn(c *cli.Context) error {
+	path, err := maintenance.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	reason := strings.TrimSpace(c.String("reason"))
+	state, err := maintenance.Enable(path, reason)
+	recordAudit(audit.ActionMaintenanceOn, "", "", err, reason)
+	if err != nil {
+		return err
+	}
+
+	printSuccess(i18n.T("Maintenance mode is on"))
+	printMaintenanceState(state)
+	fmt.Println()
+	fmt.Println("Restores, imports and deletions are blocked; backups still run.")
+	fmt.Printf("Turn it off with: %scadangkan maintenance off%s\n", colorCyan, colorReset)
+	return nil
+}
+
+func runMaintenanceOff(c *cli.Context) error {
+	path, err := maintenance.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	state, err := maintenance.Load(path)
+	if err == nil && state == nil {
+		printInfo(i18n.T("Maintenance mode is already off"))
+		return nil
+	}
+
+	err = maintenance.Disable(path)
+	recordAudit(audit.ActionMaintenanceOff, "", "", err, "")
+	if err != nil {
+		return err
+	}
+
+	printSuccess(i18n.T("Maintenance mode is off"))
+	return nil
+}
+
+func runMaintenanceStatus(c *cli.Context) error {
+	path, err := maintenance.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	state, err := maintenance.Load(path)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		printInfo(i18n.T("Maintenance mode is off"))
+		return nil
+	}
+
+	printWarning(i18n.T("Maintenance mode is on"))
+	printMaintenanceState(state)
+	return nil
+}
+
+// printMaintenanceState shows who turned maintenance mode on, when and why.
+func printMaintenanceState(state *maintenance.State) {
+	fmt.Printf("  %sSince:%s  %s (%s)\n", colorCyan, colorReset,
+		state.Since.Local().Format("2006-01-02 15:04:05"), formatTimeAgo(state.Since))
+	fmt.Printf("  %sBy:%s     %s\n", colorCyan, colorReset, state.User)
+	if state.Reason != "" {
+		fmt.Printf("  %sReason:%s %s\n", colorCyan, colorReset, state.Reason)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// doctorLegacyDir reports a ~/.cadangkan directory left by an older
+// version, which stays in use until its files are moved to the XDG config
+// and data directories. With migrate, it moves them; a directory that
+// cannot be moved fails the check and stays in use.
+func doctorLegacyDir(report *doctorReport, migrate bool) {
+	legacy, err := paths.LegacyDir()
+	if err != nil || os.Getenv(paths.EnvHome) != "" {
+		return
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	if !migrate {
+		report.warn(fmt.Sprintf("Still using %s from an older version; run 'cadangkan doctor --migrate' to move its files", legacy))
+		return
+	}
+
+	var pinned []string
+	if mgr, err := config.NewManager(); err == nil {
+		if cfg, err := mgr.Load(); err == nil {
+			pinned = configuredPaths(cfg)
+		}
+	}
+
+	moved, err := paths.Migrate(pinned)
+	if err != nil {
+		report.fail(fmt.Sprintf("Still using %s: %v", legacy, err))
+		return
+	}
+	if moved {
+		configDir, _ := paths.ConfigDir()
+		dataDir, _ := paths.DataDir()
+		report.ok(fmt.Sprintf("Moved %s to %s (config) and %s (backups and state)", legacy, configDir, dataDir))
+	}
+}
+
+// configuredPaths returns the directories and files set in config.yaml.
+func configuredPaths(cfg *config.Config) []string {
+	var configured []string
+	if cfg.Storage != nil {
+		configured = append(configured, cfg.Storage.Path)
+	}
+	if cfg.Audit != nil {
+		configured = append(configured, cfg.Audit.Path)
+	}
+	if cfg.WorkDir != nil {
+		configured = append(configured, cfg.WorkDir.Path)
+	}
+	for _, db := range cfg.Databases {
+		configured = append(configured, db.StoragePath)
+		for _, schedule := range db.AllSchedules() {
+			configured = append(configured, schedule.Output)
+		}
+	}
+	return configured
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/plugin"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func pluginsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "plugins",
+		Usage: "Manage plugins for custom storage and notifications",
+		Description: `Plugins are external programs run on backup and restore events, for
+   uploading backups to custom storage or sending notifications. They are
+   configured in the plugins section of the config:
+
+     plugins:
+       s3-upload:
+         command: /usr/local/bin/cadangkan-s3
+         args: ["--bucket", "backups"]
+         hooks: [backup.completed]
+
+   Hooks: backup.started, backup.completed, backup.failed,
+   restore.completed, restore.failed. Each call gets a JSON request on
+   stdin; a failing plugin is reported but never fails the backup.
+
+   Args and input are templates with {{.Database}}, {{.BackupID}},
+   {{.SizeHuman}}, {{.Duration}}, {{.Status}}, {{.Error}} and more. A plugin
+   with an input template gets it on stdin instead of the JSON request:
+
+     plugins:
+       slack:
+         command: curl
+         args: ["-sSf", "-H", "Content-Type: application/json", "-d", "@-", "https://hooks.slack.com/services/..."]
+         input: '{"text": "Backup of {{.Database}} {{.Status}} ({{.SizeHuman}}, {{.Duration}})"}'
+         hooks: [backup.completed, backup.failed]
+
+   USAGE:
+     cadangkan plugins list`,
+		Subcommands: []*cli.Command{
+			pluginsListCommand(),
+		},
+		Action: runPluginsList,
+	}
+}
+
+func pluginsListCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "list",
+		Usage:  "List configured plugins and check that they respond",
+		Action: runPluginsList,
+	}
+}
+
+func runPluginsList(c *cli.Context) error {
+	configManager, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	plugins := plugin.NewRegistry(cfg.Plugins).Plugins()
+	if len(plugins) == 0 {
+		printInfo("No plugins configured")
+		return nil
+	}
+
+	for i, p := range plugins {
+		if i > 0 {
+			fmt.Println()
+		}
+		hooks := "all"
+		if len(p.Config.Hooks) > 0 {
+			hooks = strings.Join(p.Config.Hooks, ", ")
+		}
+
+		info, err := p.Describe()
+		if err != nil {
+			fmt.Printf("%s %s(not responding)%s\n", p.Name, colorRed, colorReset)
+		} else {
+			fmt.Printf("%s %s%s\n", p.Name, info.Version, describeKind(info.Kind))
+		}
+		fmt.Printf("  %sCommand:%s %s\n", colorCyan, colorReset, strings.Join(append([]string{p.Config.Command}, p.Config.Args...), " "))
+		fmt.Printf("  %sHooks:%s   %s\n", colorCyan, colorReset, hooks)
+		if err != nil {
+			fmt.Printf("  %sError:%s   %v\n", colorCyan, colorReset, err)
+		} else if info.Description != "" {
+			fmt.Printf("  %sAbout:%s   %s\n", colorCyan, colorReset, info.Description)
+		}
+	}
+	return nil
+}
+
+// describeKind formats the kind a plugin reports, if any.
+func describeKind(kind string) string {
+	if kind == "" {
+		return ""
+	}
+	return " (" + kind + ")"
+}
+
+// runBackupPlugins calls the plugins for a backup of database: started
+// when there is neither a result nor an error yet, then completed or failed.
+func runBackupPlugins(database string, result *backup.BackupResult, err error) {
+	switch {
+	case err != nil:
+		runPlugins(config.HookBackupFailed, &plugin.Event{Database: database, Error: err.Error()})
+	case result != nil:
+		runPlugins(config.HookBackupCompleted, &plugin.Event{
+			Database:        database,
+			BackupID:        result.BackupID,
+			File:            result.FilePath,
+			SizeBytes:       result.SizeBytes,
+			DurationSeconds: result.Duration.Seconds(),
+			Checksum:        result.Checksum,
+		})
+	default:
+		runPlugins(config.HookBackupStarted, &plugin.Event{Database: database})
+	}
+}
+
+// runRestorePlugins calls the plugins for a finished restore of database.
+func runRestorePlugins(database string, result *backup.RestoreResult, err error) {
+	if err != nil {
+		runPlugins(config.HookRestoreFailed, &plugin.Event{Database: database, Error: err.Error()})
+		return
+	}
+	runPlugins(config.HookRestoreCompleted, &plugin.Event{
+		Database:        database,
+		BackupID:        result.BackupID,
+		TargetDatabase:  result.TargetDatabase,
+		DurationSeconds: result.Duration.Seconds(),
+	})
+}
+
+// runPlugins calls the configured plugins for a hook and reports what they
+// did. Plugin failures are shown as warnings; they never fail the command.
+func runPlugins(hook string, event *plugin.Event) {
+	configManager, err := config.NewManager()
+	if err != nil {
+		return
+	}
+	cfg, err := configManager.Load()
+	if err != nil || len(cfg.Plugins) == 0 {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	for _, result := range plugin.NewRegistry(cfg.Plugins).Dispatch(hook, event) {
+		switch {
+		case result.Err != nil:
+			printWarning(fmt.Sprintf("Plugin %s failed on %s: %v", result.Plugin, hook, result.Err))
+		case result.Message != "":
+			printInfo(fmt.Sprintf("Plugin %s: %s", result.Plugin, result.Message))
+		}
+	}
+}
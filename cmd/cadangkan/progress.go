@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"golang.org/x/term"
+)
+
+const (
+	// progressRedraw is how often the progress line is redrawn on a terminal
+	progressRedraw = 100 * time.Millisecond
+
+	// progressLogInterval is how often progress is logged when the output
+	// is not a terminal
+	progressLogInterval = 10 * time.Second
+
+	// progressBarWidth is the width of the bar when the total is known
+	progressBarWidth = 20
+)
+
+// progress shows the bytes processed, throughput and elapsed time of a
+// backup, restore or import. On a terminal it redraws a single line; other
+// output, such as CI logs, gets a plain line every progressLogInterval.
+type progress struct {
+	label   string
+	total   int64 // Expected bytes; 0 when unknown
+	bytes   atomic.Int64
+	started time.Time
+	tty     bool
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startProgress starts showing progress of an operation expected to process
+// total bytes (0 when unknown). Feed it with progress.update and end it with
+// progress.stop.
+func startProgress(label string, total int64) *progress {
+	p := &progress{
+		label:   label,
+		total:   total,
+		started: time.Now(),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if f, ok := msgOut.(*os.File); ok {
+		p.tty = term.IsTerminal(int(f.Fd()))
+	}
+	go p.run()
+	return p
+}
+
+// update records the bytes processed so far; it is a backup.ProgressFunc.
+func (p *progress) update(bytes int64) {
+	p.bytes.Store(bytes)
+}
+
+// stop stops showing progress, clearing the progress line on a terminal.
+func (p *progress) stop() {
+	close(p.done)
+	<-p.stopped
+}
+
+func (p *progress) run() {
+	defer close(p.stopped)
+
+	interval := progressLogInterval
+	if p.tty {
+		interval = progressRedraw
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Events get progress at their own pace, whatever the display's
+	var eventTick <-chan time.Time
+	if events != nil {
+		eventTicker := time.NewTicker(eventProgressInterval)
+		defer eventTicker.Stop()
+		eventTick = eventTicker.C
+	}
+
+	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	for i := 0; ; i++ {
+		select {
+		case <-p.done:
+			if p.tty {
+				fmt.Fprint(msgOut, "\r\033[K")
+			}
+			events.progress(p.label, p.bytes.Load(), p.total)
+			return
+		case <-eventTick:
+			events.progress(p.label, p.bytes.Load(), p.total)
+		case <-ticker.C:
+			if p.tty {
+				fmt.Fprintf(msgOut, "\r\033[K%s %s", spinner[i%len(spinner)], p.status())
+			} else {
+				fmt.Fprintln(msgOut, p.status())
+			}
+		}
+	}
+}
+
+// status formats the progress, e.g.
+// "Restoring... [#####-----] 48% 1.2 GB / 2.5 GB, 40.1 MB/s, 31s".
+func (p *progress) status() string {
+	bytes := p.bytes.Load()
+	elapsed := time.Since(p.started)
+
+	var b strings.Builder
+	b.WriteString(p.label)
+	b.WriteString("... ")
+	if p.total > 0 {
+		fraction := float64(bytes) / float64(p.total)
+		if fraction > 1 {
+			fraction = 1
+		}
+		filled := int(fraction * progressBarWidth)
+		fmt.Fprintf(&b, "[%s%s] %d%% %s / %s", strings.Repeat("#", filled),
+			strings.Repeat("-", progressBarWidth-filled), int(fraction*100),
+			backup.FormatBytes(bytes), backup.FormatBytes(p.total))
+	} else {
+		b.WriteString(backup.FormatBytes(bytes))
+	}
+	if seconds := elapsed.Seconds(); seconds >= 1 {
+		fmt.Fprintf(&b, ", %s/s", backup.FormatBytes(int64(float64(bytes)/seconds)))
+	}
+	fmt.Fprintf(&b, ", %s", backup.FormatDuration(elapsed))
+	return b.String()
+}
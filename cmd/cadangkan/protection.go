@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// confirmFlag confirms operations on databases with strict protection.
+func confirmFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "confirm",
+		Usage: "Name of the database, confirming the operation on a strictly protected database",
+	}
+}
+
+// confirmProtected asks for the database name before an operation on a
+// database with strict protection. --yes and --force never skip it;
+// without a terminal the name must be passed with --confirm.
+//
+// It returns true when the operation was confirmed this way, so callers
+// can skip their own y/N prompt.
+func confirmProtected(c *cli.Context, name, operation string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return false, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil || !dbConfig.StrictProtection() {
+		return false, nil
+	}
+
+	operation = i18n.T(operation)
+	if c.IsSet("confirm") {
+		if c.String("confirm") != name {
+			return false, errors.New(i18n.T("%s cancelled: --confirm=%q does not match the protected database %q",
+				operation, c.String("confirm"), name))
+		}
+		return true, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, errors.New(i18n.T("%s of '%s' requires confirmation: it has strict protection; pass --confirm %s",
+			operation, name, name))
+	}
+
+	printWarning(i18n.T("'%s' has strict protection", name))
+	fmt.Fprint(msgOut, i18n.T("Type %s%s%s to confirm the %s: ", colorCyan, name, colorReset, operation))
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(response) != name {
+		return false, errors.New(i18n.T("%s cancelled: the name typed does not match '%s'", operation, name))
+	}
+	fmt.Fprintln(msgOut)
+	return true, nil
+}
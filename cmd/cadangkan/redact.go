@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func redactCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "redact",
+		Usage:     "Remove rows from stored backups (right to erasure)",
+		ArgsUsage: "[flags] <name>",
+		Description: `Erase a person's data from historical backups. Every backup of the
+   database (or the one given with --from) is decompressed, the rows of
+   --table matching --where are left out, and the backup is recompressed
+   in place with new checksums. Backups without matching rows are not
+   touched.
+
+   --where supports column = value and column IN (values...), joined by
+   AND; values are compared with the text of the dump, ignoring case like
+   MySQL's default collations (use --case-sensitive for binary or _cs
+   columns). Locked backups are
+   skipped unless --include-locked is given. Each rewritten backup records
+   the redaction (table, columns and row count, never the values) in its
+   metadata and in the audit log.
+
+   Run with --dry-run first to see which backups are affected. Copies of
+   the backups kept elsewhere must be replaced separately.
+
+   EXAMPLES:
+     cadangkan redact --table users --where "email='jane@example.com'" --dry-run production
+     cadangkan redact --table users --where "email='jane@example.com'" --yes production`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "table",
+				Usage:    "Table to remove rows from",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "where",
+				Usage:    "Rows to remove, e.g. \"email='jane@example.com'\"",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "case-sensitive",
+				Usage: "Match values exactly instead of ignoring case",
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Only redact this backup (default: every backup)",
+			},
+			&cli.BoolFlag{
+				Name:  "include-locked",
+				Usage: "Also rewrite locked backups",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Count matching rows without rewriting anything",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Skip confirmation prompt",
+			},
+			confirmFlag(),
+		},
+		Action: runRedact,
+	}
+}
+
+func runRedact(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan redact --table <table> --where <condition> <name>")
+	}
+	name := c.Args().Get(0)
+	table := c.String("table")
+	dryRun := c.Bool("dry-run")
+
+	if !dryRun {
+		if err := maintenance.Check("redact"); err != nil {
+			return err
+		}
+	}
+
+	cond, err := backup.ParseRowCondition(c.String("where"))
+	if err != nil {
+		return err
+	}
+	cond.CaseSensitive = c.Bool("case-sensitive")
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	backups, err := localStorage.ListBackups(name)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	var ids []string
+	for _, b := range backups {
+		if c.IsSet("from") && b.BackupID != c.String("from") {
+			continue
+		}
+		if b.Status != backup.StatusCompleted && b.Status != "" {
+			continue
+		}
+		if b.Lock != nil && !c.Bool("include-locked") {
+			printWarning(fmt.Sprintf("Skipping locked backup %s (use --include-locked to rewrite it)", b.BackupID))
+			continue
+		}
+		ids = append(ids, b.BackupID)
+	}
+	if len(ids) == 0 {
+		if c.IsSet("from") {
+			return fmt.Errorf("backup '%s' not found", c.String("from"))
+		}
+		return fmt.Errorf("no backups to redact for '%s'", name)
+	}
+
+	if !dryRun {
+		printWarning(fmt.Sprintf("This will permanently remove rows of '%s' from %d backup(s) of '%s'", table, len(ids), name))
+		confirmed, err := confirmProtected(c, name, "redact")
+		if err != nil {
+			return err
+		}
+		if !confirmed && !c.Bool("yes") {
+			fmt.Fprint(msgOut, i18n.T("Continue? [y/N]: "))
+			response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read confirmation: %w", err)
+			}
+			if !i18n.Affirmative(response) {
+				printInfo("Redaction cancelled")
+				return nil
+			}
+		}
+	}
+
+	var total int64
+	var rewritten, failed int
+	for _, id := range ids {
+		removed, err := backup.RedactBackup(localStorage, name, id, table, cond, dryRun)
+		if !dryRun && (removed > 0 || err != nil) {
+			// Only the columns are recorded: the values are what is being erased
+			recordAudit(audit.ActionRedact, name, id, err, fmt.Sprintf("table %s by %s, %d row(s)",
+				table, strings.Join(cond.Columns(), ", "), removed))
+		}
+		if err != nil {
+			printError(fmt.Sprintf("%s: %v", id, err))
+			failed++
+			continue
+		}
+		if removed == 0 {
+			continue
+		}
+		total += removed
+		rewritten++
+		if dryRun {
+			printInfo(fmt.Sprintf("%s: %d row(s) would be removed", id, removed))
+		} else {
+			printSuccess(fmt.Sprintf("%s: %d row(s) removed", id, removed))
+		}
+	}
+
+	fmt.Fprintln(msgOut)
+	switch {
+	case dryRun:
+		printInfo(fmt.Sprintf("Dry run: %d row(s) in %d of %d backup(s) match", total, rewritten, len(ids)))
+	case rewritten == 0:
+		printInfo(fmt.Sprintf("No matching rows in %d backup(s)", len(ids)))
+	default:
+		printSuccess(fmt.Sprintf("Removed %d row(s) from %d backup(s)", total, rewritten))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d backup(s) could not be redacted", failed)
+	}
+	return nil
+}
@@ -4,9 +4,10 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"strings"
 
+	"github.com/erickhilda/cadangkan/internal/audit"
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,6 +23,7 @@ func removeCommand() *cli.Command {
 				Aliases: []string{"f"},
 				Usage:   "Skip confirmation prompt",
 			},
+			confirmFlag(),
 		},
 		Action: runRemove,
 	}
@@ -45,41 +47,49 @@ func runRemove(c *cli.Context) error {
 	// Check if database exists
 	dbConfig, err := mgr.GetDatabase(name)
 	if err != nil {
-		printError("Database not found")
+		printError(i18n.T("Database not found"))
+		return err
+	}
+
+	// Removing the entry also drops its protection, so --force does not
+	// skip confirming a protected database
+	confirmed, err := confirmProtected(c, name, "removal")
+	if err != nil {
 		return err
 	}
 
 	// Confirm deletion (unless --force)
-	if !force {
+	if !confirmed && !force {
 		fmt.Printf("\n%sWarning:%s You are about to remove the database configuration:\n\n", colorYellow, colorReset)
 		fmt.Printf("  Name:     %s\n", name)
 		fmt.Printf("  Type:     %s\n", dbConfig.Type)
 		fmt.Printf("  Host:     %s:%d\n", dbConfig.Host, dbConfig.Port)
-		fmt.Printf("  Database: %s\n\n", dbConfig.Database)
+		fmt.Printf("  Database: %s\n\n", databaseLabel(dbConfig))
 		fmt.Printf("%sNote:%s This will only remove the configuration, not the actual database or backups.\n\n", colorYellow, colorReset)
 
-		fmt.Print("Are you sure? (yes/no): ")
+		fmt.Print(i18n.T("Are you sure? (yes/no): "))
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
 		if err != nil {
 			return fmt.Errorf("failed to read input: %w", err)
 		}
 
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "yes" && response != "y" {
-			printInfo("Cancelled")
+		if !i18n.Affirmative(response) {
+			printInfo(i18n.T("Cancelled"))
 			return nil
 		}
 	}
 
 	// Remove database
-	printInfo("Removing configuration...")
-	if err := mgr.RemoveDatabase(name); err != nil {
-		printError("Failed to remove configuration")
+	printInfo(i18n.T("Removing configuration..."))
+	err = mgr.RemoveDatabase(name)
+	recordAudit(audit.ActionConfigRemove, name, "", err, "")
+	if err != nil {
+		printError(i18n.T("Failed to remove configuration"))
 		return err
 	}
 
-	printSuccess(fmt.Sprintf("Database '%s' removed successfully!", name))
+	printSuccess(i18n.T("Database '%s' removed successfully!", name))
 
 	return nil
 }
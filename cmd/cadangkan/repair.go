@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+func repairCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "repair",
+		Usage:     "Rebuild the lost metadata of a backup file",
+		ArgsUsage: "<name> <file>",
+		Description: `Write new metadata for a backup file whose .meta.json was lost, so the
+   backup is listed and can be restored again. The file must be in the
+   backup directory of the database; give its path or just its name.
+
+   The backup ID and compression come from the file name and the backup
+   time from the file's modification time. The whole file is read to
+   compute its checksums, so a backup that cannot be decompressed is
+   refused. Options the backup was taken with (tables, schema-only) cannot
+   be recovered.
+
+   'cadangkan gc --dry-run' lists backup files without metadata.
+
+   EXAMPLES:
+     cadangkan repair production 2025-01-15-020000.sql.gz`,
+		Action: runRepair,
+	}
+}
+
+func runRepair(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return fmt.Errorf("database name and backup file are required\n\nUsage: cadangkan repair <name> <file>")
+	}
+	name := c.Args().Get(0)
+	file := c.Args().Get(1)
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil {
+		printError(fmt.Sprintf("Database '%s' not found in config", name))
+		return err
+	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	// A bare file name is looked up in the database's backup directory
+	path := file
+	if !strings.ContainsRune(file, os.PathSeparator) {
+		if backupID, _, ok := backup.ParseBackupFileName(file); ok {
+			path = filepath.Join(localStorage.GetBackupDir(name, backupID), file)
+		}
+	}
+
+	dbType := dbConfig.Type
+	if dbType == "" {
+		dbType = "mysql"
+	}
+	database := backup.DatabaseInfo{Type: dbType, Host: dbConfig.Host, Port: dbConfig.Port, Database: dbConfig.Database}
+
+	printInfo(fmt.Sprintf("Reading %s...", path))
+	metadata, err := backup.RepairMetadata(localStorage, name, database, path)
+	target := filepath.Base(path)
+	if metadata != nil {
+		target = metadata.BackupID
+	}
+	recordAudit(audit.ActionRepair, name, target, err, "metadata rebuilt from backup file")
+	if err != nil {
+		printError("Repair failed")
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Metadata rebuilt for backup %s", metadata.BackupID))
+	fmt.Printf("  %sCreated:%s     %s (file modification time)\n", colorCyan, colorReset, metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  %sSize:%s        %s\n", colorCyan, colorReset, metadata.Backup.SizeHuman)
+	fmt.Printf("  %sCompression:%s %s\n", colorCyan, colorReset, metadata.Backup.Compression)
+	fmt.Printf("  %sChecksum:%s    %s\n", colorCyan, colorReset, metadata.Backup.ChecksumFile)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/status"
+	"github.com/urfave/cli/v2"
+)
+
+func reportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "Generate a backup report for sharing",
+		Description: `Generate a report of every database over a period: how many days had a
+   successful backup, success rates, backup sizes and restore drill results.
+   The report is self-contained, for attaching to compliance reviews.
+
+   USAGE:
+     cadangkan report                                # Markdown, last 30 days
+     cadangkan report --format html -o report.html   # HTML page
+     cadangkan report --period 90d                   # Last 90 days`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Value: status.ReportMarkdown,
+				Usage: "Report format: md or html",
+			},
+			&cli.StringFlag{
+				Name:  "period",
+				Value: "30d",
+				Usage: "Period covered by the report, e.g. 7d, 30d, 90d",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the report to a file instead of stdout",
+			},
+		},
+		Action: runReport,
+	}
+}
+
+func runReport(c *cli.Context) error {
+	format := c.String("format")
+	if format != status.ReportMarkdown && format != status.ReportHTML {
+		return fmt.Errorf("invalid format: %s (must be md or html)", format)
+	}
+	period, err := config.ParseAge(c.String("period"))
+	if err != nil {
+		return fmt.Errorf("invalid period: %w", err)
+	}
+
+	storageInstance, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	report, err := status.NewService(configManager, storageInstance).GetReport(period)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if path := c.String("output"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if format == status.ReportHTML {
+		err = report.WriteHTML(w)
+	} else {
+		err = report.WriteMarkdown(w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if path := c.String("output"); path != "" {
+		printSuccess(fmt.Sprintf("Report written to %s", path))
+	}
+	return nil
+}
@@ -7,10 +7,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/urfave/cli/v2"
 )
 
@@ -28,13 +32,82 @@ func restoreCommand() *cli.Command {
      2. Direct mode (with flags):
         cadangkan restore --host=<host> --user=<user> --database=<db> --password=<pass>
 
-   Flags can override config values when using named mode.`,
+   Flags can override config values when using named mode.
+
+   EXTERNAL SOURCES:
+     Restore a dump that is not in managed storage with --from-file or
+     --from-url. Use --from-file - to read from stdin (requires --yes).
+     --checksum verifies the source before anything is restored.
+
+        cadangkan restore mydb --from-file ./dump.sql.gz
+        cadangkan restore mydb --from-url https://example.com/dump.sql.gz --checksum sha256:...
+        aws s3 cp s3://bucket/dump.sql.gz - | cadangkan restore mydb --from-file - --yes
+
+   RESTORE ENGINES:
+     By default dumps are loaded with the mysql command line client. With
+     --engine native cadangkan splits the dump into statements and runs
+     them itself, so the mysql client does not need to be installed.
+     mydumper backups always need myloader.
+
+        cadangkan restore mydb --engine native
+
+   SINGLE TABLES:
+     --table restores one table of a mysqldump backup into the same
+     database as <table>_restored_<date>, so rows can be recovered without
+     touching the current table. --table-as picks the name; give the
+     table's own name to replace it.
+
+        cadangkan restore mydb --from 2025-01-15-020000 --table users
+        cadangkan restore mydb --table users --table-as users_before_import
+
+     --exclude-tables leaves tables out instead, e.g. large caches that
+     need not be restored. Their structure, data and triggers are skipped.
+
+        cadangkan restore mydb --exclude-tables sessions,cache
+
+     --schema-only restores the structure of every table but the data of
+     --data-tables only, by default the database's reference_tables, giving
+     CI pipelines a small database with its lookup data.
+
+        cadangkan restore mydb --schema-only --to mydb_ci --create-db
+        cadangkan restore mydb --schema-only --data-tables countries,plans --to mydb_ci
+
+   ATOMIC RESTORES:
+     --atomic restores into <db>_restoring_<time> first and only swaps the
+     tables into the database, with a single RENAME TABLE, once the restore
+     succeeded. A failed restore leaves the database as it was. Tables the
+     backup does not contain are kept, and backups with views, triggers,
+     routines or events cannot be swapped in.
+
+        cadangkan restore mydb --atomic
+
+   ACTIVE CONNECTIONS:
+     Restoring under an application that is still connected mixes old and
+     new data, so restores refuse to start while connections are using the
+     database. --force restores anyway, --kill-sessions kills them first.
+
+        cadangkan restore mydb --kill-sessions
+
+   OTHER SERVERS:
+     Dumps from another server can fail on accounts or settings it lacks.
+     --skip-definers removes the DEFINER clauses of views, triggers and
+     routines, --defer-views creates views after all tables, and
+     --sql-mode relaxed loads the dump without strict mode.
+
+        cadangkan restore mydb --skip-definers --defer-views
+
+   MULTIPLE DATABASES:
+     Backups made with 'backup --databases' restore into the databases they
+     were taken from, creating any that are missing. --schemas restores
+     only some of them.
+
+        cadangkan restore --database shop+stats --schemas stats <connection flags>`,
 		Flags: []cli.Flag{
 			// Database type
 			&cli.StringFlag{
 				Name:  "type",
-				Value: "mysql",
-				Usage: "Database type (mysql)",
+				Value: backup.EngineTypeMySQL,
+				Usage: fmt.Sprintf("Database type (%s)", strings.Join(backup.EngineNames(), "|")),
 			},
 
 			// Backup selection
@@ -43,13 +116,56 @@ func restoreCommand() *cli.Command {
 				Usage: "Specific backup ID to restore (default: latest)",
 			},
 
+			&cli.StringFlag{
+				Name:  "from-file",
+				Usage: "Restore from a dump file outside managed storage (- for stdin)",
+			},
+			&cli.StringFlag{
+				Name:  "from-url",
+				Usage: "Restore from a dump downloaded over HTTP(S)",
+			},
+			&cli.StringFlag{
+				Name:  "checksum",
+				Usage: "Expected sha256 checksum of --from-file/--from-url source",
+			},
+			&cli.StringFlag{
+				Name:  "compression",
+				Usage: "Compression of --from-file/--from-url source (gzip|none, default: detect)",
+			},
+
 			// Target database
 			&cli.StringFlag{
 				Name:  "to",
 				Usage: "Target database name (overrides config database)",
 			},
 
+			&cli.StringFlag{
+				Name:  "table",
+				Usage: "Restore only this table, as <table>_restored_<date> unless --table-as is given",
+			},
+			&cli.StringFlag{
+				Name:  "table-as",
+				Usage: "Name to restore --table as (its own name replaces the current table)",
+			},
+
 			// Database creation
+			&cli.StringSliceFlag{
+				Name:    "exclude-tables",
+				Aliases: []string{"skip-tables"},
+				Usage:   "Tables to leave out of the restore (comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "schema-only",
+				Usage: "Restore the structure of every table but only the data of --data-tables",
+			},
+			&cli.StringSliceFlag{
+				Name:  "data-tables",
+				Usage: "Tables whose data --schema-only restores (comma-separated, default: reference_tables from config)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "schemas",
+				Usage: "Databases to restore from a backup of multiple databases (comma-separated, default all)",
+			},
 			&cli.BoolFlag{
 				Name:  "create-db",
 				Usage: "Create database if it doesn't exist",
@@ -82,29 +198,90 @@ func restoreCommand() *cli.Command {
 				Name:  "dry-run",
 				Usage: "Validate restore without executing",
 			},
+			&cli.BoolFlag{
+				Name:  "plan",
+				Usage: "With --dry-run, scan the dump and summarize the statements it would execute",
+			},
 			&cli.BoolFlag{
 				Name:  "backup-first",
 				Usage: "Backup target database before restore (only if DB exists)",
 			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Restore even if connections are using the target database",
+			},
+			&cli.BoolFlag{
+				Name:  "kill-sessions",
+				Usage: "Kill connections using the target database before restoring",
+			},
+			&cli.BoolFlag{
+				Name:  "atomic",
+				Usage: "Restore into a shadow database and swap its tables in once the restore succeeded",
+			},
+			&cli.BoolFlag{
+				Name:  "verify-first",
+				Usage: "Verify the backup checksum in a separate pass before restoring",
+			},
+			&cli.BoolFlag{
+				Name:  "ignore-charset",
+				Usage: "Restore even if the server lacks the backup's character set or collation",
+			},
+			&cli.StringFlag{
+				Name:  "sql-mode",
+				Usage: "Session sql_mode for the restore: source (the backup server's), relaxed, or a list of modes",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-definers",
+				Usage: "Remove DEFINER clauses so views, triggers and routines are owned by the restoring user",
+			},
+			&cli.BoolFlag{
+				Name:  "defer-views",
+				Usage: "Create views after all tables of the dump",
+			},
+			&cli.BoolFlag{
+				Name:  "no-fast",
+				Usage: "Keep foreign key checks, unique checks and autocommit on during the restore",
+			},
+			&cli.StringFlag{
+				Name:  "mysql-path",
+				Usage: "mysql client program to run, e.g. /opt/mysql-8.4/bin/mysql (overrides config)",
+			},
+			&cli.StringFlag{
+				Name:  "engine",
+				Value: backup.RestoreEngineMySQL,
+				Usage: "Restore engine (mysql|native); native runs the dump without the mysql client",
+			},
 			&cli.BoolFlag{
 				Name:    "yes",
 				Aliases: []string{"y"},
 				Usage:   "Skip confirmation prompt",
 			},
+			confirmFlag(),
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
 				Usage:   "Show verbose output including mysql command",
 			},
+			eventsFlag(),
 		},
-		Action: runRestore,
+		Action: withEvents("restore", runRestore),
 	}
 }
 
 func runRestore(c *cli.Context) error {
 	var host, user, password, database, configName string
+	var mysqlPath, mysqldumpPath string
 	var port int
+	var ssl *mysql.SSLConfig
 	var usingConfig bool
+	var referenceTables []string
+
+	// Validating with --dry-run changes nothing
+	if !c.Bool("dry-run") {
+		if err := maintenance.Check("restore"); err != nil {
+			return err
+		}
+	}
 
 	// Check if using named mode (config) or direct mode (flags)
 	if c.NArg() > 0 {
@@ -121,9 +298,12 @@ func runRestore(c *cli.Context) error {
 		dbConfig, err := mgr.GetDatabase(name)
 		if err != nil {
 			printError(fmt.Sprintf("Database '%s' not found in config", name))
-			fmt.Println()
-			fmt.Printf("Available databases: run %scadangkan list%s\n", colorCyan, colorReset)
-			fmt.Printf("Add a database:      run %scadangkan add mysql %s%s\n", colorCyan, name, colorReset)
+			fmt.Fprintln(msgOut)
+			fmt.Fprintf(msgOut, "Available databases: run %scadangkan list%s\n", colorCyan, colorReset)
+			fmt.Fprintf(msgOut, "Add a database:      run %scadangkan add mysql %s%s\n", colorCyan, name, colorReset)
+			return err
+		}
+		if err := requireDatabase(dbConfig); err != nil {
 			return err
 		}
 
@@ -132,11 +312,18 @@ func runRestore(c *cli.Context) error {
 		port = dbConfig.Port
 		user = dbConfig.User
 		database = dbConfig.Database
+		ssl = backupconfig.SSL(dbConfig.TLS)
+		referenceTables = dbConfig.ReferenceTables
 
-		// Decrypt password
-		password, err = config.DecryptPassword(dbConfig.PasswordEncrypted)
+		// Resolve password
+		password, err = dbConfig.Password()
 		if err != nil {
-			return fmt.Errorf("failed to decrypt password: %w", err)
+			return err
+		}
+
+		if cfg, err := mgr.Load(); err == nil {
+			mysqlPath = cfg.GetEffectiveMysqlPath(name)
+			mysqldumpPath = cfg.GetEffectiveMysqldumpPath(name)
 		}
 
 		printInfo(fmt.Sprintf("Using configuration for '%s'", name))
@@ -179,6 +366,61 @@ func runRestore(c *cli.Context) error {
 	if c.IsSet("database") && usingConfig {
 		database = c.String("database")
 	}
+	if c.IsSet("mysql-path") {
+		mysqlPath = c.String("mysql-path")
+	}
+
+	// Resolve external source (bypasses managed storage)
+	sourceLocation := c.String("from-file")
+	if c.IsSet("from-url") {
+		if sourceLocation != "" {
+			return fmt.Errorf("--from-file and --from-url cannot be used together")
+		}
+		sourceLocation = c.String("from-url")
+	}
+	if sourceLocation != "" && c.IsSet("from") {
+		return fmt.Errorf("--from cannot be combined with --from-file or --from-url")
+	}
+	if sourceLocation == "" && (c.IsSet("checksum") || c.IsSet("compression")) {
+		return fmt.Errorf("--checksum and --compression require --from-file or --from-url")
+	}
+	if sourceLocation == backup.StdinSource && !c.Bool("yes") && !c.Bool("dry-run") {
+		return fmt.Errorf("--yes is required when restoring from stdin")
+	}
+	table := c.String("table")
+	if table == "" && c.IsSet("table-as") {
+		return fmt.Errorf("--table-as requires --table")
+	}
+	if table != "" && sourceLocation != "" {
+		return fmt.Errorf("--table cannot be combined with --from-file or --from-url")
+	}
+	excludeTables := c.StringSlice("exclude-tables")
+	if table != "" && len(excludeTables) > 0 {
+		return fmt.Errorf("--table and --exclude-tables cannot be used together")
+	}
+	if c.Bool("plan") && !c.Bool("dry-run") {
+		return fmt.Errorf("--plan requires --dry-run")
+	}
+	if table != "" && c.Bool("atomic") {
+		return fmt.Errorf("--table and --atomic cannot be used together")
+	}
+	sample, err := restoreSample(c)
+	if err != nil {
+		return err
+	}
+	if c.IsSet("data-tables") && !c.Bool("schema-only") {
+		return fmt.Errorf("--data-tables requires --schema-only")
+	}
+	if c.Bool("schema-only") && sourceLocation != "" {
+		return fmt.Errorf("--schema-only cannot be combined with --from-file or --from-url")
+	}
+	dataTables := referenceTables
+	if c.IsSet("data-tables") {
+		dataTables = c.StringSlice("data-tables")
+	}
+	if c.IsSet("schemas") && sourceLocation != "" {
+		return fmt.Errorf("--schemas cannot be combined with --from-file or --from-url")
+	}
 
 	// Get target database (--to overrides)
 	targetDatabase := database
@@ -186,46 +428,62 @@ func runRestore(c *cli.Context) error {
 		targetDatabase = c.String("to")
 	}
 
-	// Validate database type
-	dbType := c.String("type")
-	if dbType != "mysql" {
-		return fmt.Errorf("unsupported database type: %s (only 'mysql' is supported)", dbType)
+	// Resolve database engine
+	eng, err := backup.GetEngine(c.String("type"))
+	if err != nil {
+		return err
 	}
 
-	// Check for mysql availability
-	printInfo("Checking mysql availability...")
-	version, err := backup.CheckMySQL()
-	if err != nil {
-		printError("mysql not found")
-		fmt.Println("\nPlease install MySQL client tools:")
-		fmt.Println("  Ubuntu/Debian: sudo apt-get install mysql-client")
-		fmt.Println("  RHEL/CentOS:   sudo yum install mysql")
-		fmt.Println("  macOS:         brew install mysql-client")
+	restoreEngine := c.String("engine")
+	if err := backup.ValidateRestoreEngine(restoreEngine); err != nil {
+		return err
+	}
+	if err := backup.ValidateSQLMode(c.String("sql-mode")); err != nil {
 		return err
 	}
-	printSuccess(fmt.Sprintf("Found %s", version))
 
 	// Create MySQL config
 	// Connect without specifying database so we can create/restore into any database
 	mysqlConfig := &mysql.Config{
-		Host:     host,
-		Port:     port,
-		User:     user,
-		Password: password,
-		Database: "", // Empty - connect to server, not specific database
-		Timeout:  10 * time.Second,
+		Host:          host,
+		Port:          port,
+		User:          user,
+		Password:      password,
+		Database:      "", // Empty - connect to server, not specific database
+		Timeout:       10 * time.Second,
+		SSL:           ssl,
+		MysqldumpPath: mysqldumpPath,
+		MysqlPath:     mysqlPath,
+	}
+
+	// Check for restore tool availability; the native engine needs none
+	var toolVersion string
+	if restoreEngine != backup.RestoreEngineNative {
+		printInfo("Checking restore tool availability...")
+		toolVersion, err = eng.RestoreToolVersion(backup.MySQLConnection(mysqlConfig))
+		if err != nil {
+			printError(fmt.Sprintf("%s restore tool not found", eng.DisplayName()))
+			fmt.Fprintln(msgOut)
+			for _, line := range eng.InstallHelp() {
+				fmt.Fprintln(msgOut, line)
+			}
+			fmt.Fprintf(msgOut, "Or restore without it: %s--engine native%s\n", colorCyan, colorReset)
+			return err
+		}
+		printSuccess(fmt.Sprintf("Found %s", toolVersion))
 	}
 
 	// Create client and connect
+	events.phase("connect")
 	printInfo(fmt.Sprintf("Connecting to %s@%s:%d...", user, host, port))
-	client, err := mysql.NewClient(mysqlConfig)
+	client, err := eng.NewIntrospector(backup.MySQLConnection(mysqlConfig))
 	if err != nil {
-		printError("Failed to create MySQL client")
+		printError(fmt.Sprintf("Failed to create %s client", eng.DisplayName()))
 		return err
 	}
 
 	if err := client.Connect(); err != nil {
-		printError("Connection failed")
+		printError(i18n.T("Connection failed"))
 		return err
 	}
 	defer client.Close()
@@ -235,17 +493,22 @@ func runRestore(c *cli.Context) error {
 	if err != nil {
 		dbVersion = "unknown"
 	}
-	printSuccess(fmt.Sprintf("Connected to database (MySQL %s)", dbVersion))
+	printSuccess(fmt.Sprintf("Connected to database (%s %s)", eng.DisplayName(), dbVersion))
+	if warning := backup.ClientCompatibility(toolVersion, dbVersion); warning != "" {
+		printWarning(warning)
+	}
 
 	// Create storage
-	localStorage, err := storage.NewLocalStorage("")
+	localStorage, err := newDatabaseStorage(configName)
 	if err != nil {
-		printError("Failed to create storage")
+		printError(i18n.T("Failed to create storage"))
 		return err
 	}
 
 	// Create restore service
 	service := backup.NewRestoreService(client, localStorage, mysqlConfig)
+	service.SetEngine(eng)
+	service.SetLogOutput(msgOut)
 
 	// Enable verbose mode if requested
 	verbose := c.Bool("verbose")
@@ -253,6 +516,10 @@ func runRestore(c *cli.Context) error {
 		service.SetVerbose(true)
 	}
 
+	if sourceLocation != "" {
+		return runSourceRestore(c, sourceLocation, service, client, eng, localStorage, mysqlConfig, configName, database, targetDatabase)
+	}
+
 	// Get backup ID
 	backupID := c.String("from")
 
@@ -301,178 +568,618 @@ func runRestore(c *cli.Context) error {
 		return fmt.Errorf("failed to load backup metadata: %w", err)
 	}
 
-	// Check if target database exists
-	dbExists, err := client.DatabaseExists(targetDatabase)
+	// Multi-database backups restore into the databases they were taken from
+	schemas, err := backup.SelectSchemas(metadata.Database, c.StringSlice("schemas"))
 	if err != nil {
-		return fmt.Errorf("failed to check if database exists: %w", err)
+		return err
+	}
+	schemaSet := len(metadata.Database.Databases) > 0
+	if schemaSet && (c.IsSet("to") || table != "" || c.Bool("backup-first") || c.Bool("atomic")) {
+		return fmt.Errorf("--to, --table, --backup-first and --atomic cannot be used with backups of multiple databases; select databases with --schemas")
+	}
+
+	// Single tables are restored beside the current one unless named otherwise
+	tableAs := c.String("table-as")
+	if table != "" {
+		if metadata.Backup.Format == backup.FormatMydumper {
+			return fmt.Errorf("--table is not supported for mydumper backups")
+		}
+		if tableAs == "" {
+			tableAs = backup.RestoredTableName(table, backupEntry.CreatedAt)
+		}
+	}
+	if len(excludeTables) > 0 && metadata.Backup.Format == backup.FormatMydumper {
+		return fmt.Errorf("--exclude-tables is not supported for mydumper backups")
+	}
+	if c.Bool("plan") && metadata.Backup.Format == backup.FormatMydumper {
+		return fmt.Errorf("--plan is not supported for mydumper backups")
+	}
+	if c.Bool("atomic") && metadata.Backup.Format == backup.FormatMydumper {
+		return fmt.Errorf("--atomic is not supported for mydumper backups")
+	}
+	if c.Bool("schema-only") && metadata.Backup.Format == backup.FormatMydumper {
+		return fmt.Errorf("--schema-only is not supported for mydumper backups")
+	}
+	if sample != (backup.RowSample{}) && metadata.Backup.Format == backup.FormatMydumper {
+		return fmt.Errorf("seeding is not supported for mydumper backups")
+	}
+
+	// mydumper backups are restored with myloader
+	if metadata.Backup.Format == backup.FormatMydumper {
+		printInfo("Checking myloader availability...")
+		loaderVersion, err := backup.CheckMyloader()
+		if err != nil {
+			printError("myloader not found (required to restore mydumper backups)")
+			return err
+		}
+		printSuccess(fmt.Sprintf("Found %s", loaderVersion))
+	}
+
+	// Refuse a server that cannot hold the backup's text before asking
+	if !c.Bool("ignore-charset") {
+		if err := backup.CheckCharset(client, metadata.Database); err != nil {
+			printError(err.Error())
+			return fmt.Errorf("character set check failed; pass --ignore-charset to restore anyway")
+		}
+	}
+
+	// Check if target database exists; multi-database dumps create their own
+	dbExists := schemaSet
+	if !schemaSet {
+		dbExists, err = client.DatabaseExists(targetDatabase)
+		if err != nil {
+			return fmt.Errorf("failed to check if database exists: %w", err)
+		}
 	}
 
 	// Show restore preview
-	fmt.Println()
-	printWarning("WARNING: This will restore the database")
-	if dbExists {
+	fmt.Fprintln(msgOut)
+	switch {
+	case table != "" && tableAs == table:
+		printWarning(fmt.Sprintf("WARNING: This will replace table '%s' in '%s'", table, targetDatabase))
+	case table != "":
+		printInfo(fmt.Sprintf("Table '%s' will be restored as '%s'; if '%s' exists it will be replaced", table, tableAs, tableAs))
+	default:
+		printWarning(i18n.T("WARNING: This will restore the database"))
+	}
+	switch {
+	case schemaSet:
+		printWarning(fmt.Sprintf("Current data in %s will be overwritten!", strings.Join(schemas, ", ")))
+	case dbExists && table == "":
 		printWarning(fmt.Sprintf("Current data in '%s' will be overwritten!", targetDatabase))
+	}
+	if dbExists {
+		databases := []string{targetDatabase}
+		if schemaSet {
+			databases = schemas
+		}
+		if err := previewSessions(client, databases, restoreSessionAction(c)); err != nil {
+			return err
+		}
+	}
+	if dbExists {
+		if warning := backup.CharsetMismatch(client, metadata.Database, targetDatabase); warning != "" {
+			printWarning(warning)
+		}
 	} else {
 		printInfo(fmt.Sprintf("Database '%s' does not exist", targetDatabase))
 		if !c.Bool("create-db") {
-			printError("Use --create-db to create the database")
+			printError(i18n.T("Use --create-db to create the database"))
 			return fmt.Errorf("database does not exist")
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(msgOut)
 
-	fmt.Printf("Backup to restore:\n")
-	fmt.Printf("  %sID:%s        %s\n", colorCyan, colorReset, backupEntry.BackupID)
-	fmt.Printf("  %sCreated:%s    %s\n", colorCyan, colorReset, backupEntry.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("  %sSize:%s       %s\n", colorCyan, colorReset, backupEntry.SizeHuman)
-	fmt.Printf("  %sDatabase:%s   %s\n", colorCyan, colorReset, metadata.Database.Database)
-	fmt.Println()
+	fmt.Fprintf(msgOut, "Backup to restore:\n")
+	fmt.Fprintf(msgOut, "  %sID:%s        %s\n", colorCyan, colorReset, backupEntry.BackupID)
+	fmt.Fprintf(msgOut, "  %sCreated:%s    %s\n", colorCyan, colorReset, backupEntry.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(msgOut, "  %sSize:%s       %s\n", colorCyan, colorReset, backupEntry.SizeHuman)
+	fmt.Fprintf(msgOut, "  %sDatabase:%s   %s\n", colorCyan, colorReset, metadata.Database.Database)
+	if metadata.Database.Charset != "" {
+		fmt.Fprintf(msgOut, "  %sCharset:%s    %s (%s)\n", colorCyan, colorReset, metadata.Database.Charset, metadata.Database.Collation)
+	}
+	if metadata.Database.SQLMode != "" {
+		fmt.Fprintf(msgOut, "  %sSQL mode:%s   %s\n", colorCyan, colorReset, metadata.Database.SQLMode)
+	}
+	fmt.Fprintln(msgOut)
 
-	fmt.Printf("Target database:\n")
-	fmt.Printf("  %sName:%s       %s\n", colorCyan, colorReset, targetDatabase)
-	fmt.Printf("  %sHost:%s       %s:%d\n", colorCyan, colorReset, host, port)
-	if dbExists {
-		printInfo("Database exists - data will be overwritten")
+	fmt.Fprintf(msgOut, "Target database:\n")
+	if schemaSet {
+		fmt.Fprintf(msgOut, "  %sDatabases:%s  %s\n", colorCyan, colorReset, strings.Join(schemas, ", "))
 	} else {
-		printInfo("Database will be created")
+		fmt.Fprintf(msgOut, "  %sName:%s       %s\n", colorCyan, colorReset, targetDatabase)
+	}
+	fmt.Fprintf(msgOut, "  %sHost:%s       %s:%d\n", colorCyan, colorReset, host, port)
+	if len(excludeTables) > 0 {
+		fmt.Fprintf(msgOut, "  %sExcluded:%s   %s\n", colorCyan, colorReset, strings.Join(excludeTables, ", "))
+	}
+	if sample != (backup.RowSample{}) {
+		fmt.Fprintf(msgOut, "  %sSample:%s     %s\n", colorCyan, colorReset, sample)
+	}
+	if c.Bool("schema-only") {
+		data := "none"
+		if len(dataTables) > 0 {
+			data = strings.Join(dataTables, ", ")
+		}
+		fmt.Fprintf(msgOut, "  %sData of:%s    %s (schema only)\n", colorCyan, colorReset, data)
+	}
+	if schemaSet {
+		printInfo("Missing databases will be created")
+	} else if table != "" {
+		fmt.Fprintf(msgOut, "  %sTable:%s      %s → %s\n", colorCyan, colorReset, table, tableAs)
+	} else if dbExists {
+		printInfo(i18n.T("Database exists - data will be overwritten"))
+	} else {
+		printInfo(i18n.T("Database will be created"))
+	}
+	if c.Bool("atomic") {
+		printInfo("Restoring into a shadow database; tables are swapped in once it succeeds")
+	}
+	fmt.Fprintln(msgOut)
+
+	// Restore options, also used to scan the dump in a dry run
+	options := &backup.RestoreOptions{
+		BackupID:         backupID,
+		Database:         database,
+		ConfigName:       configName,
+		TargetDatabase:   targetDatabase,
+		CreateDatabase:   c.Bool("create-db"),
+		DryRun:           c.Bool("dry-run"),
+		BackupFirst:      c.Bool("backup-first"),
+		SkipConfirmation: c.Bool("yes"),
+		VerifyFirst:      c.Bool("verify-first"),
+		DisableFastMode:  c.Bool("no-fast"),
+		Engine:           c.String("engine"),
+		Table:            table,
+		TableAs:          tableAs,
+		IgnoreCharset:    c.Bool("ignore-charset"),
+		SQLMode:          c.String("sql-mode"),
+		SkipDefiners:     c.Bool("skip-definers"),
+		DeferViews:       c.Bool("defer-views"),
+		ExcludeTables:    excludeTables,
+		Schemas:          c.StringSlice("schemas"),
+		Plan:             c.Bool("plan"),
+		Atomic:           c.Bool("atomic"),
+		SessionAction:    restoreSessionAction(c),
+		Sample:           sample,
+		SchemaOnly:       c.Bool("schema-only"),
+		DataTables:       dataTables,
 	}
-	fmt.Println()
 
-	// Dry-run mode
+	// Dry-run mode, scanning the dump if asked
 	if c.Bool("dry-run") {
-		printInfo("Dry-run mode: Validation only, no changes will be made")
-		fmt.Println()
-		printSuccess("Validation passed! Use without --dry-run to restore.")
+		printInfo(i18n.T("Dry-run mode: Validation only, no changes will be made"))
+		summary := restoreEventResult{BackupID: backupID, TargetDatabase: targetDatabase, DryRun: true}
+		if options.Plan {
+			bar := startProgress("Scanning", backupEntry.SizeBytes)
+			service.SetProgress(bar.update)
+			result, err := service.Restore(options)
+			bar.stop()
+			if err != nil {
+				printError("Failed to scan the backup")
+				return err
+			}
+			summary.Plan = result.Plan
+			fmt.Fprintln(msgOut)
+			printRestorePlan(result.Plan)
+		}
+		fmt.Fprintln(msgOut)
+		printSuccess(i18n.T("Validation passed! Use without --dry-run to restore."))
+		events.complete(summary)
 		return nil
 	}
 
 	// Confirmation prompt
-	if !c.Bool("yes") {
-		fmt.Print("Continue? [y/N]: ")
+	confirmed, err := confirmProtected(c, configName, "restore")
+	if err != nil {
+		return err
+	}
+	if !confirmed && !c.Bool("yes") {
+		fmt.Fprint(msgOut, i18n.T("Continue? [y/N]: "))
 		reader := bufio.NewReader(os.Stdin)
 		response, err := reader.ReadString('\n')
 		if err != nil {
 			return fmt.Errorf("failed to read confirmation: %w", err)
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			printInfo("Restore cancelled")
+		if !i18n.Affirmative(response) {
+			printInfo(i18n.T("Restore cancelled"))
 			return nil
 		}
-		fmt.Println()
+		fmt.Fprintln(msgOut)
 	}
 
 	// Backup-first option
+	var snapshotID string
 	if c.Bool("backup-first") && dbExists {
+		events.phase("safety-backup")
 		printInfo(fmt.Sprintf("Creating safety backup of '%s' before restore...", targetDatabase))
 
-		// Create backup service with the current database connection
 		backupConfig := &mysql.Config{
-			Host:     host,
-			Port:     port,
-			User:     user,
-			Password: password,
-			Database: targetDatabase,
-			Timeout:  10 * time.Second,
+			Host:          host,
+			Port:          port,
+			User:          user,
+			Password:      password,
+			Database:      targetDatabase,
+			Timeout:       10 * time.Second,
+			SSL:           ssl,
+			MysqldumpPath: mysqldumpPath,
 		}
-
-		// Create a new client for backup
-		backupClient, err := mysql.NewClient(backupConfig)
+		snapshotID, err = createSafetyBackup(eng, backupConfig, localStorage, configName, backupID, verbose)
 		if err != nil {
-			printError("Failed to create backup client")
-			return fmt.Errorf("backup-first failed: %w", err)
+			return err
 		}
+	}
 
-		if err := backupClient.Connect(); err != nil {
-			printError("Failed to connect for backup")
-			return fmt.Errorf("backup-first failed: %w", err)
-		}
+	// Execute restore
+	events.phase("restore")
+	printInfo(i18n.T("Starting restore..."))
+
+	// Show progress through the backup file during restore
+	bar := startProgress("Restoring", backupEntry.SizeBytes)
+	service.SetProgress(bar.update)
+
+	result, err := service.Restore(options)
+	bar.stop()
+
+	auditDetail := fmt.Sprintf("into %s on %s:%d", targetDatabase, host, port)
+	if table != "" {
+		auditDetail = fmt.Sprintf("table %s as %s %s", table, tableAs, auditDetail)
+	}
+	recordAudit(audit.ActionRestore, configName, backupID, err, auditDetail)
+	if !options.DryRun {
+		runRestorePlugins(storageName, result, err)
+	}
 
-		backupService := backup.NewService(backupClient, localStorage, backupConfig)
-		if verbose {
-			backupService.SetVerbose(true)
+	if err != nil {
+		printError(i18n.T("Restore failed"))
+		if backup.IsChecksumMismatchError(err) && !options.VerifyFirst {
+			printWarning("The backup was already restored when the mismatch was detected; use --verify-first to check before restoring")
 		}
+		return err
+	}
+
+	// Link the restore to the backup it came from
+	record := backup.RestoreRecord{
+		TargetDatabase: targetDatabase,
+		Host:           host,
+		Port:           port,
+		RestoredAt:     time.Now(),
+		SnapshotID:     snapshotID,
+	}
+	if err := backup.RecordRestore(localStorage, storageName, backupID, record); err != nil {
+		printWarning(fmt.Sprintf("Failed to record the restore in backup metadata: %v", err))
+	}
+
+	// Display results
+	printSuccess(i18n.T("Restore completed!"))
+	fmt.Fprintln(msgOut)
+	formatRestoreResult(result, targetDatabase)
+	events.complete(restoreEventResult{
+		BackupID:        result.BackupID,
+		TargetDatabase:  targetDatabase,
+		DurationSeconds: result.Duration.Seconds(),
+	})
+
+	return nil
+}
 
-		// Create backup with special naming to indicate it's a pre-restore backup
-		backupOptions := &backup.BackupOptions{
-			Database:    targetDatabase,
-			ConfigName:  configName,
-			Compression: backup.CompressionGzip,
-			Tables:      nil,
-			ExcludeTables: nil,
-			SchemaOnly:  false,
+// createSafetyBackup backs up the target database before it is overwritten
+// by restoring source. The snapshot is linked to the restore in its metadata
+// so 'cadangkan rollback' can find it. Returns the ID of the snapshot.
+func createSafetyBackup(eng backup.Engine, backupConfig *mysql.Config, localStorage *storage.LocalStorage, configName, source string, verbose bool) (string, error) {
+	// Create a new client for backup
+	backupClient, err := eng.NewIntrospector(backup.MySQLConnection(backupConfig))
+	if err != nil {
+		printError(i18n.T("Failed to create backup client"))
+		return "", fmt.Errorf("backup-first failed: %w", err)
+	}
+
+	if err := backupClient.Connect(); err != nil {
+		printError("Failed to connect for backup")
+		return "", fmt.Errorf("backup-first failed: %w", err)
+	}
+
+	backupService := backup.NewService(backupClient, localStorage, backupConfig)
+	backupService.SetEngine(eng)
+	backupService.SetLogOutput(msgOut)
+	if verbose {
+		backupService.SetVerbose(true)
+	}
+
+	// Create backup with special naming to indicate it's a pre-restore backup
+	backupOptions := &backup.BackupOptions{
+		Database:      backupConfig.Database,
+		ConfigName:    configName,
+		Compression:   backup.CompressionGzip,
+		Tables:        nil,
+		ExcludeTables: nil,
+		SchemaOnly:    false,
+		PreRestore: &backup.RestoreLink{
+			Source:         source,
+			TargetDatabase: backupConfig.Database,
+			RestoredAt:     time.Now(),
+		},
+	}
+
+	// Execute backup
+	backupResult, err := backupService.Backup(backupOptions)
+	backupClient.Close()
+
+	if err != nil {
+		printError(i18n.T("Failed to create safety backup"))
+		printWarning(i18n.T("Aborting restore to prevent data loss"))
+		return "", fmt.Errorf("backup-first failed: %w", err)
+	}
+
+	printSuccess(fmt.Sprintf("Safety backup created: %s (%s)", backupResult.BackupID, backup.FormatBytes(backupResult.SizeBytes)))
+	fmt.Fprintln(msgOut)
+
+	return backupResult.BackupID, nil
+}
+
+// runSourceRestore restores from a file, URL or stdin instead of managed storage.
+func runSourceRestore(c *cli.Context, location string, service *backup.RestoreService, client backup.Introspector, eng backup.Engine, localStorage *storage.LocalStorage, mysqlConfig *mysql.Config, configName, database, targetDatabase string) error {
+	source, err := backup.OpenRestoreSource(location)
+	if err != nil {
+		printError(fmt.Sprintf("Cannot open source '%s'", location))
+		return err
+	}
+	defer source.Close()
+
+	// Check if target database exists
+	dbExists, err := client.DatabaseExists(targetDatabase)
+	if err != nil {
+		return fmt.Errorf("failed to check if database exists: %w", err)
+	}
+
+	// Show restore preview
+	fmt.Fprintln(msgOut)
+	printWarning(i18n.T("WARNING: This will restore the database"))
+	if dbExists {
+		printWarning(fmt.Sprintf("Current data in '%s' will be overwritten!", targetDatabase))
+		if err := previewSessions(client, []string{targetDatabase}, restoreSessionAction(c)); err != nil {
+			return err
+		}
+	} else {
+		printInfo(fmt.Sprintf("Database '%s' does not exist", targetDatabase))
+		if !c.Bool("create-db") {
+			printError(i18n.T("Use --create-db to create the database"))
+			return fmt.Errorf("database does not exist")
 		}
+	}
+	fmt.Fprintln(msgOut)
+
+	sourceLabel := location
+	if location == backup.StdinSource {
+		sourceLabel = "stdin"
+	}
+	sizeLabel := "unknown"
+	if source.SizeBytes >= 0 {
+		sizeLabel = backup.FormatBytes(source.SizeBytes)
+	}
+	checksumLabel := "not verified"
+	if c.IsSet("checksum") {
+		checksumLabel = c.String("checksum")
+	}
 
-		// Execute backup
-		backupResult, err := backupService.Backup(backupOptions)
-		backupClient.Close()
+	fmt.Fprintf(msgOut, "Source to restore:\n")
+	fmt.Fprintf(msgOut, "  %sSource:%s     %s\n", colorCyan, colorReset, sourceLabel)
+	fmt.Fprintf(msgOut, "  %sSize:%s       %s\n", colorCyan, colorReset, sizeLabel)
+	fmt.Fprintf(msgOut, "  %sChecksum:%s   %s\n", colorCyan, colorReset, checksumLabel)
+	fmt.Fprintln(msgOut)
 
+	fmt.Fprintf(msgOut, "Target database:\n")
+	fmt.Fprintf(msgOut, "  %sName:%s       %s\n", colorCyan, colorReset, targetDatabase)
+	fmt.Fprintf(msgOut, "  %sHost:%s       %s:%d\n", colorCyan, colorReset, mysqlConfig.Host, mysqlConfig.Port)
+	if excludeTables := c.StringSlice("exclude-tables"); len(excludeTables) > 0 {
+		fmt.Fprintf(msgOut, "  %sExcluded:%s   %s\n", colorCyan, colorReset, strings.Join(excludeTables, ", "))
+	}
+	if c.Bool("atomic") {
+		printInfo("Restoring into a shadow database; tables are swapped in once it succeeds")
+	}
+	fmt.Fprintln(msgOut)
+
+	// Confirmation prompt
+	confirmed := false
+	if !c.Bool("dry-run") {
+		var err error
+		if confirmed, err = confirmProtected(c, configName, "restore"); err != nil {
+			return err
+		}
+	}
+	if !confirmed && !c.Bool("yes") && !c.Bool("dry-run") {
+		fmt.Fprint(msgOut, i18n.T("Continue? [y/N]: "))
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
 		if err != nil {
-			printError("Failed to create safety backup")
-			printWarning("Aborting restore to prevent data loss")
-			return fmt.Errorf("backup-first failed: %w", err)
+			return fmt.Errorf("failed to read confirmation: %w", err)
 		}
-
-		printSuccess(fmt.Sprintf("Safety backup created: %s (%s)", backupResult.BackupID, backup.FormatBytes(backupResult.SizeBytes)))
-		fmt.Println()
+		if !i18n.Affirmative(response) {
+			printInfo(i18n.T("Restore cancelled"))
+			return nil
+		}
+		fmt.Fprintln(msgOut)
 	}
 
-	// Execute restore
-	printInfo("Starting restore...")
+	verbose := c.Bool("verbose")
+	var snapshotID string
+	if c.Bool("backup-first") && dbExists && !c.Bool("dry-run") {
+		events.phase("safety-backup")
+		printInfo(fmt.Sprintf("Creating safety backup of '%s' before restore...", targetDatabase))
+		backupConfig := *mysqlConfig
+		backupConfig.Database = targetDatabase
+		snapshotID, err = createSafetyBackup(eng, &backupConfig, localStorage, configName, location, verbose)
+		if err != nil {
+			return err
+		}
+	}
 
 	options := &backup.RestoreOptions{
-		BackupID:         backupID,
-		Database:         database,
-		ConfigName:       configName,
-		TargetDatabase:   targetDatabase,
-		CreateDatabase:   c.Bool("create-db"),
-		DryRun:           c.Bool("dry-run"),
-		BackupFirst:      c.Bool("backup-first"),
-		SkipConfirmation: c.Bool("yes"),
+		Database:        database,
+		ConfigName:      configName,
+		TargetDatabase:  targetDatabase,
+		CreateDatabase:  c.Bool("create-db"),
+		DryRun:          c.Bool("dry-run"),
+		Checksum:        c.String("checksum"),
+		Compression:     c.String("compression"),
+		DisableFastMode: c.Bool("no-fast"),
+		Engine:          c.String("engine"),
+		SQLMode:         c.String("sql-mode"),
+		SkipDefiners:    c.Bool("skip-definers"),
+		DeferViews:      c.Bool("defer-views"),
+		ExcludeTables:   c.StringSlice("exclude-tables"),
+		Plan:            c.Bool("plan"),
+		Atomic:          c.Bool("atomic"),
+		SessionAction:   restoreSessionAction(c),
 	}
 
-	// Show spinner during restore
-	done := make(chan bool)
-	go showRestoreSpinner(done)
+	events.phase("restore")
+	if options.DryRun {
+		printInfo(i18n.T("Dry-run mode: Validation only, no changes will be made"))
+	} else {
+		printInfo(i18n.T("Starting restore..."))
+	}
 
-	result, err := service.Restore(options)
-	done <- true
+	bar := startProgress("Restoring", max(source.SizeBytes, 0))
+	service.SetProgress(bar.update)
+
+	result, err := service.RestoreFromSource(source, options)
+	bar.stop()
+
+	if !options.DryRun {
+		recordAudit(audit.ActionRestore, configName, sourceLabel, err, fmt.Sprintf("into %s on %s:%d", targetDatabase, mysqlConfig.Host, mysqlConfig.Port))
+	}
 
 	if err != nil {
-		printError("Restore failed")
+		printError(i18n.T("Restore failed"))
 		return err
 	}
 
-	// Display results
-	printSuccess("Restore completed!")
-	fmt.Println()
+	summary := restoreEventResult{
+		Source:          result.Source,
+		TargetDatabase:  targetDatabase,
+		DurationSeconds: result.Duration.Seconds(),
+		DryRun:          options.DryRun,
+		Plan:            result.Plan,
+	}
+	if options.DryRun {
+		if result.Plan != nil {
+			fmt.Fprintln(msgOut)
+			printRestorePlan(result.Plan)
+			fmt.Fprintln(msgOut)
+		}
+		printSuccess(i18n.T("Validation passed! Use without --dry-run to restore."))
+		events.complete(summary)
+		return nil
+	}
+
+	// Link the restore to the backup it came from when the source is a
+	// backup file in managed storage
+	storageName := configName
+	if storageName == "" {
+		storageName = database
+	}
+	record := backup.RestoreRecord{
+		TargetDatabase: targetDatabase,
+		Host:           mysqlConfig.Host,
+		Port:           mysqlConfig.Port,
+		RestoredAt:     time.Now(),
+		SnapshotID:     snapshotID,
+	}
+	backupID, err := backup.FindBackupByPath(localStorage, storageName, location)
+	if err == nil && backupID != "" {
+		err = backup.RecordRestore(localStorage, storageName, backupID, record)
+	}
+	if err != nil {
+		printWarning(fmt.Sprintf("Failed to record the restore in backup metadata: %v", err))
+	}
+
+	printSuccess(i18n.T("Restore completed!"))
+	fmt.Fprintln(msgOut)
 	formatRestoreResult(result, targetDatabase)
+	events.complete(summary)
 
 	return nil
 }
 
-// showRestoreSpinner displays a spinner during restore
-func showRestoreSpinner(done chan bool) {
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	i := 0
-	for {
-		select {
-		case <-done:
-			fmt.Print("\r") // Clear the spinner line
-			return
-		default:
-			fmt.Printf("\r%s Restoring... ", spinner[i%len(spinner)])
-			i++
-			time.Sleep(100 * time.Millisecond)
-		}
+// planListLimit is how many names of each kind a restore plan lists.
+const planListLimit = 10
+
+// restoreSessionAction returns what --force and --kill-sessions make the
+// restore do about connections using the target database.
+func restoreSessionAction(c *cli.Context) string {
+	switch {
+	case c.Bool("kill-sessions"):
+		return backup.SessionActionKill
+	case c.Bool("force"):
+		return backup.SessionActionWarn
+	default:
+		return backup.SessionActionBlock
 	}
 }
 
+// previewSessions lists the connections using databases before the restore
+// is confirmed, failing if they block it.
+func previewSessions(client backup.Introspector, databases []string, action string) error {
+	sessions, err := backup.ActiveSessions(client, databases)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		printWarning(backup.DescribeSession(session))
+	}
+	switch {
+	case len(sessions) == 0:
+	case action == backup.SessionActionBlock:
+		printError("Pass --force to restore anyway or --kill-sessions to kill them")
+		return fmt.Errorf("%d connections are using the database", len(sessions))
+	case action == backup.SessionActionKill:
+		printWarning(fmt.Sprintf("%d connections will be killed before restoring", len(sessions)))
+	}
+	return nil
+}
+
+// printRestorePlan displays what a restore would execute.
+func printRestorePlan(plan *backup.RestorePlan) {
+	fmt.Fprintf(msgOut, "Restore plan:\n")
+	if len(plan.DroppedDatabases) > 0 {
+		fmt.Fprintf(msgOut, "  %sDrop databases:%s   %s\n", colorCyan, colorReset, planList(plan.DroppedDatabases))
+	}
+	if len(plan.CreatedDatabases) > 0 {
+		fmt.Fprintf(msgOut, "  %sCreate databases:%s %s\n", colorCyan, colorReset, planList(plan.CreatedDatabases))
+	}
+	fmt.Fprintf(msgOut, "  %sDrop tables:%s      %s\n", colorCyan, colorReset, planList(plan.DroppedTables))
+	fmt.Fprintf(msgOut, "  %sCreate tables:%s    %s\n", colorCyan, colorReset, planList(plan.CreatedTables))
+	if len(plan.Views) > 0 {
+		fmt.Fprintf(msgOut, "  %sCreate views:%s     %s\n", colorCyan, colorReset, planList(plan.Views))
+	}
+	fmt.Fprintf(msgOut, "  %sStatements:%s       %d (%d INSERT)\n", colorCyan, colorReset, plan.Statements, plan.Inserts)
+	fmt.Fprintf(msgOut, "  %sSQL size:%s         %s\n", colorCyan, colorReset, backup.FormatBytes(plan.SizeBytes))
+}
+
+// planList formats names of a restore plan with their count, listing the
+// first planListLimit of them.
+func planList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	listed := names[:min(len(names), planListLimit)]
+	list := fmt.Sprintf("%d (%s", len(names), strings.Join(listed, ", "))
+	if len(names) > len(listed) {
+		list += fmt.Sprintf(" and %d more", len(names)-len(listed))
+	}
+	return list + ")"
+}
+
 // formatRestoreResult formats and displays the restore result
 func formatRestoreResult(result *backup.RestoreResult, database string) {
-	fmt.Printf("  %sBackup ID:%s       %s\n", colorCyan, colorReset, result.BackupID)
-	fmt.Printf("  %sTarget Database:%s %s\n", colorCyan, colorReset, database)
-	fmt.Printf("  %sDuration:%s        %s\n", colorCyan, colorReset, backup.FormatDuration(result.Duration))
-	fmt.Println()
-	fmt.Printf("Database '%s' has been restored successfully.\n", database)
+	if result.Source != "" {
+		fmt.Fprintf(msgOut, "  %sSource:%s          %s\n", colorCyan, colorReset, result.Source)
+	} else {
+		fmt.Fprintf(msgOut, "  %sBackup ID:%s       %s\n", colorCyan, colorReset, result.BackupID)
+	}
+	fmt.Fprintf(msgOut, "  %sTarget Database:%s %s\n", colorCyan, colorReset, database)
+	fmt.Fprintf(msgOut, "  %sDuration:%s        %s\n", colorCyan, colorReset, backup.FormatDuration(result.Duration))
+	fmt.Fprintln(msgOut)
+	fmt.Fprintf(msgOut, "Database '%s' has been restored successfully.\n", database)
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/i18n"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+// rollbackRestoreFlags are the restore flags rollback accepts.
+var rollbackRestoreFlags = map[string]bool{
+	"type": true, "host": true, "port": true, "user": true, "password": true,
+	"dry-run": true, "backup-first": true, "verify-first": true, "no-fast": true,
+	"yes": true, "confirm": true, "verbose": true, "force": true, "kill-sessions": true,
+}
+
+func rollbackCommand() *cli.Command {
+	// Reuse the restore flags; the backup and target are set from the
+	// snapshot, so those flags are hidden
+	var flags []cli.Flag
+	for _, flag := range restoreCommand().Flags {
+		if rollbackRestoreFlags[flag.Names()[0]] {
+			flags = append(flags, flag)
+		}
+	}
+	flags = append(flags,
+		&cli.StringFlag{Name: "from", Hidden: true},
+		&cli.StringFlag{Name: "to", Hidden: true},
+		&cli.BoolFlag{Name: "create-db", Hidden: true},
+	)
+
+	return &cli.Command{
+		Name:      "rollback",
+		Usage:     "Undo the last restore using its pre-restore snapshot",
+		ArgsUsage: "<name>",
+		Description: `Restore the safety snapshot taken by the most recent
+   'cadangkan restore --backup-first', putting the database back the way it
+   was before that restore.
+
+   Rollback refuses to run when the database was restored again after the
+   snapshot was taken (including by an earlier rollback), since the snapshot
+   would then undo more than the last restore. Restore the snapshot
+   explicitly with 'cadangkan restore --from <id>' in that case.
+
+   Use --backup-first to snapshot the database again first, so the rollback
+   itself can be rolled back.
+
+   EXAMPLES:
+     cadangkan restore --backup-first production
+     cadangkan rollback production
+     cadangkan rollback --dry-run production`,
+		Flags:  flags,
+		Action: runRollback,
+	}
+}
+
+func runRollback(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan rollback <name>")
+	}
+	name := c.Args().Get(0)
+
+	localStorage, err := newDatabaseStorage(name)
+	if err != nil {
+		printError(i18n.T("Failed to create storage"))
+		return err
+	}
+
+	snapshot, err := backup.LatestPreRestoreSnapshot(localStorage, name)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	if snapshot == nil {
+		printError(fmt.Sprintf("No pre-restore snapshot found for '%s'", name))
+		fmt.Fprintln(msgOut)
+		fmt.Fprintf(msgOut, "Snapshots are taken by %scadangkan restore --backup-first %s%s\n", colorCyan, name, colorReset)
+		return fmt.Errorf("nothing to roll back")
+	}
+	link := snapshot.PreRestore
+
+	if err := checkRestoredSince(name, snapshot); err != nil {
+		return err
+	}
+
+	printInfo(fmt.Sprintf("Rolling back the restore of %s into '%s' (%s)",
+		link.Source, link.TargetDatabase, formatTimeAgo(link.RestoredAt)))
+	printInfo(fmt.Sprintf("Using snapshot %s", snapshot.BackupID))
+	fmt.Fprintln(msgOut)
+
+	// Restore the snapshot into the database it was taken from
+	for flag, value := range map[string]string{
+		"from":      snapshot.BackupID,
+		"to":        link.TargetDatabase,
+		"create-db": "true",
+	} {
+		if err := c.Set(flag, value); err != nil {
+			return err
+		}
+	}
+	return runRestore(c)
+}
+
+// checkRestoredSince returns an error when the audit log shows more than one
+// successful restore of name since snapshot was taken: the snapshot then
+// predates a restore other than the last one.
+func checkRestoredSince(name string, snapshot *backup.BackupMetadata) error {
+	logger, err := auditLogger()
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	path := logger.Path()
+	logger.Close()
+
+	entries, err := audit.ReadEntries(path)
+	if err != nil {
+		return err
+	}
+
+	var restores []audit.Entry
+	for _, entry := range entries {
+		if entry.Action == audit.ActionRestore && entry.Database == name &&
+			entry.Result == audit.ResultSuccess && entry.Time.After(snapshot.PreRestore.RestoredAt) {
+			restores = append(restores, entry)
+		}
+	}
+	if len(restores) <= 1 {
+		return nil
+	}
+
+	last := restores[len(restores)-1]
+	printError(fmt.Sprintf("'%s' was restored again after snapshot %s was taken (last from %s, %s)",
+		name, snapshot.BackupID, last.Target, formatTimeAgo(last.Time)))
+	fmt.Fprintln(msgOut)
+	fmt.Fprintf(msgOut, "Restore the snapshot explicitly: %scadangkan restore --from %s %s%s\n",
+		colorCyan, snapshot.BackupID, name, colorReset)
+	return fmt.Errorf("the last restore of '%s' has no snapshot to roll back to", name)
+}
@@ -6,7 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/erickhilda/cadangkan/internal/audit"
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/scheduler"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/robfig/cron/v3"
 	"github.com/urfave/cli/v2"
 )
@@ -21,6 +24,8 @@ func scheduleCommand() *cli.Command {
 			scheduleDisableCommand(),
 			scheduleListCommand(),
 			scheduleNextCommand(),
+			scheduleRemoveCommand(),
+			scheduleRunCommand(),
 		},
 	}
 }
@@ -42,7 +47,20 @@ func scheduleSetCommand() *cli.Command {
      Custom cron expression (every 6 hours):
        cadangkan schedule set production --cron="0 */6 * * *"
 
-   CRON FORMAT: minute hour day month weekday
+     Daily at 2 AM in Jakarta, whatever the server timezone:
+       cadangkan schedule set production --daily --time=02:00 --timezone=Asia/Jakarta
+
+     Hourly, spread over 10 minutes, but not during office hours:
+       cadangkan schedule set production --cron="0 * * * *" --jitter=10m --blackout=08:00-20:00
+
+     Hourly schema-only backup next to the nightly full backup:
+       cadangkan schedule set production --schedule=hourly --cron="0 * * * *" --schema-only
+
+     Weekly restore test into a scratch database:
+       cadangkan schedule set production --restore-test --weekly --day=saturday --time=05:00
+
+   CRON FORMAT: [second] minute hour day month weekday
+     - second: 0-59 (optional)
      - minute: 0-59
      - hour: 0-23
      - day: 1-31
@@ -69,6 +87,55 @@ func scheduleSetCommand() *cli.Command {
 				Name:  "cron",
 				Usage: "Custom cron expression (e.g., '0 2 * * *')",
 			},
+			scheduleNameFlag(),
+			&cli.StringFlag{
+				Name:  "timezone",
+				Usage: "IANA timezone of the schedule, e.g. Asia/Jakarta (default: server local time)",
+			},
+			&cli.StringFlag{
+				Name:  "jitter",
+				Usage: "Delay each run by a random duration up to this (e.g., 10m)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "blackout",
+				Usage: "Daily window with no backups (HH:MM-HH:MM, repeatable)",
+			},
+			&cli.IntFlag{
+				Name:  "priority",
+				Usage: "Start before lower priority jobs when the daemon queues runs",
+			},
+			&cli.StringSliceFlag{
+				Name:  "tables",
+				Usage: "Specific tables to back up on this schedule (comma-separated)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude-tables",
+				Usage: "Tables to exclude on this schedule (comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "schema-only",
+				Usage: "Back up schema only (no data) on this schedule",
+			},
+			&cli.StringFlag{
+				Name:  "compression",
+				Usage: "Compression type on this schedule (gzip|none)",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Storage directory on this schedule (default: backups in the data directory)",
+			},
+			&cli.BoolFlag{
+				Name:  "restore-test",
+				Usage: "Schedule a restore test of the latest backup instead of a backup",
+			},
+			&cli.StringFlag{
+				Name:  "scratch-db",
+				Usage: "Scratch database for the restore test, dropped on every run (default: <database>_restore_test)",
+			},
+			&cli.StringFlag{
+				Name:  "scratch-server",
+				Usage: "Configured database whose server receives the restore test (default: same server)",
+			},
 		},
 		Action: runScheduleSet,
 	}
@@ -129,31 +196,66 @@ func runScheduleSet(c *cli.Context) error {
 	}
 
 	// Validate cron expression
-	_, err = cron.ParseStandard(cronExpr)
+	_, err = scheduler.ParseCron(cronExpr, c.String("timezone"))
 	if err != nil {
-		return fmt.Errorf("invalid cron expression '%s': %w", cronExpr, err)
+		return fmt.Errorf("invalid schedule '%s': %w", cronExpr, err)
+	}
+
+	if c.Bool("restore-test") {
+		return setRestoreTest(c, mgr, name, dbConfig, cronExpr)
 	}
 
 	// Update database config
-	if dbConfig.Schedule == nil {
-		dbConfig.Schedule = &config.ScheduleConfig{}
+	schedule := dbConfig.EnsureSchedule(c.String("schedule"))
+	schedule.Cron = cronExpr
+	schedule.Enabled = true
+	if c.IsSet("timezone") {
+		schedule.Timezone = c.String("timezone")
+	}
+	if c.IsSet("jitter") {
+		schedule.Jitter = c.String("jitter")
+	}
+	if c.IsSet("blackout") {
+		schedule.Blackout = c.StringSlice("blackout")
+	}
+	if c.IsSet("priority") {
+		schedule.Priority = c.Int("priority")
+	}
+	if c.IsSet("tables") {
+		schedule.Tables = c.StringSlice("tables")
+	}
+	if c.IsSet("exclude-tables") {
+		schedule.ExcludeTables = c.StringSlice("exclude-tables")
+	}
+	if c.IsSet("schema-only") {
+		schedule.SchemaOnly = c.Bool("schema-only")
+	}
+	if c.IsSet("compression") {
+		schedule.Compression = c.String("compression")
+	}
+	if c.IsSet("output") {
+		schedule.Output = c.String("output")
+	}
+	if err := dbConfig.Validate(); err != nil {
+		return err
 	}
-	dbConfig.Schedule.Cron = cronExpr
-	dbConfig.Schedule.Enabled = true
 
 	// Save configuration
-	if err := mgr.AddDatabase(name, dbConfig); err != nil {
+	err = mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, fmt.Sprintf("schedule %s set: %s", schedule.ScheduleName(), cronExpr))
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
 	// Calculate next run
-	sched, _ := cron.ParseStandard(cronExpr)
-	nextRun := sched.Next(time.Now())
+	sched, _ := scheduler.ParseCron(cronExpr, schedule.Timezone)
+	nextRun := scheduler.NextRun(sched, schedule, time.Now())
 
-	printSuccess(fmt.Sprintf("Schedule configured for '%s'", name))
+	printSuccess(fmt.Sprintf("Schedule configured for '%s'", config.ScheduleLabel(name, schedule.ScheduleName())))
 	fmt.Println()
 	fmt.Printf("  %sSchedule:%s  %s\n", colorCyan, colorReset, cronExpr)
-	fmt.Printf("  %sNext run:%s  %s (%s)\n", colorCyan, colorReset, nextRun.Format("2006-01-02 15:04:05"), formatNextRun(nextRun))
+	printScheduleRules(schedule)
+	fmt.Printf("  %sNext run:%s  %s\n", colorCyan, colorReset, formatScheduledRun(nextRun))
 	fmt.Printf("  %sStatus:%s    %sEnabled%s\n", colorCyan, colorReset, colorGreen, colorReset)
 	fmt.Println()
 	fmt.Println("The schedule will be active when the Cadangkan service is running.")
@@ -164,12 +266,70 @@ func runScheduleSet(c *cli.Context) error {
 	return nil
 }
 
+// setRestoreTest saves a restore test schedule for a database.
+func setRestoreTest(c *cli.Context, mgr config.Manager, name string, dbConfig *config.DatabaseConfig, cronExpr string) error {
+	if dbConfig.RestoreTest == nil {
+		dbConfig.RestoreTest = &config.RestoreTestConfig{}
+	}
+	dbConfig.RestoreTest.Cron = cronExpr
+	dbConfig.RestoreTest.Enabled = true
+	if c.IsSet("scratch-db") {
+		dbConfig.RestoreTest.ScratchDatabase = c.String("scratch-db")
+	}
+	if c.IsSet("scratch-server") {
+		server := c.String("scratch-server")
+		if server != "" {
+			if _, err := mgr.GetDatabase(server); err != nil {
+				printError(fmt.Sprintf("Scratch server '%s' not found", server))
+				return err
+			}
+		}
+		dbConfig.RestoreTest.Server = server
+	}
+	if err := dbConfig.Validate(); err != nil {
+		return err
+	}
+
+	err := mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, fmt.Sprintf("restore test set: %s", cronExpr))
+	if err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	scratch := dbConfig.RestoreTest.ScratchDatabase
+	if scratch == "" {
+		scratch = dbConfig.Database + backup.DefaultDrillSuffix
+	}
+	sched, _ := scheduler.ParseCron(cronExpr, "")
+	nextRun := sched.Next(time.Now())
+
+	printSuccess(fmt.Sprintf("Restore test configured for '%s'", name))
+	fmt.Println()
+	fmt.Printf("  %sSchedule:%s  %s\n", colorCyan, colorReset, cronExpr)
+	fmt.Printf("  %sScratch:%s   %s\n", colorCyan, colorReset, scratch)
+	if dbConfig.RestoreTest.Server != "" {
+		fmt.Printf("  %sServer:%s    %s\n", colorCyan, colorReset, dbConfig.RestoreTest.Server)
+	}
+	fmt.Printf("  %sNext run:%s  %s\n", colorCyan, colorReset, formatTimeDetail(nextRun))
+	fmt.Println()
+	printWarning(fmt.Sprintf("'%s' is dropped and recreated on every run", scratch))
+
+	return nil
+}
+
 func scheduleEnableCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "enable",
 		Usage:     "Enable backup schedule for a database",
 		ArgsUsage: "<name>",
-		Action:    runScheduleEnable,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "restore-test",
+				Usage: "Enable the restore test instead of the backup schedule",
+			},
+			scheduleNameFlag(),
+		},
+		Action: runScheduleEnable,
 	}
 }
 
@@ -191,17 +351,37 @@ func runScheduleEnable(c *cli.Context) error {
 		return err
 	}
 
-	if dbConfig.Schedule == nil || dbConfig.Schedule.Cron == "" {
-		return fmt.Errorf("no schedule configured for '%s'\n\nSet a schedule first: cadangkan schedule set %s --daily", name, name)
+	if c.Bool("restore-test") {
+		if dbConfig.RestoreTest == nil || dbConfig.RestoreTest.Cron == "" {
+			return fmt.Errorf("no restore test configured for '%s'\n\nSet one first: cadangkan schedule set %s --restore-test --weekly", name, name)
+		}
+		dbConfig.RestoreTest.Enabled = true
+
+		err = mgr.AddDatabase(name, dbConfig)
+		recordAudit(audit.ActionConfigEdit, name, "", err, "restore test enabled")
+		if err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+
+		printSuccess(fmt.Sprintf("Restore test enabled for '%s'", name))
+		return nil
 	}
 
-	dbConfig.Schedule.Enabled = true
+	label := config.ScheduleLabel(name, c.String("schedule"))
+	schedule := dbConfig.FindSchedule(c.String("schedule"))
+	if schedule == nil || schedule.Cron == "" {
+		return fmt.Errorf("no schedule configured for '%s'\n\nSet a schedule first: cadangkan schedule set %s --daily", label, name)
+	}
+
+	schedule.Enabled = true
 
-	if err := mgr.AddDatabase(name, dbConfig); err != nil {
+	err = mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, fmt.Sprintf("schedule %s enabled", schedule.ScheduleName()))
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
-	printSuccess(fmt.Sprintf("Schedule enabled for '%s'", name))
+	printSuccess(fmt.Sprintf("Schedule enabled for '%s'", label))
 	return nil
 }
 
@@ -210,7 +390,14 @@ func scheduleDisableCommand() *cli.Command {
 		Name:      "disable",
 		Usage:     "Disable backup schedule for a database",
 		ArgsUsage: "<name>",
-		Action:    runScheduleDisable,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "restore-test",
+				Usage: "Disable the restore test instead of the backup schedule",
+			},
+			scheduleNameFlag(),
+		},
+		Action: runScheduleDisable,
 	}
 }
 
@@ -232,18 +419,184 @@ func runScheduleDisable(c *cli.Context) error {
 		return err
 	}
 
-	if dbConfig.Schedule == nil {
-		printInfo(fmt.Sprintf("No schedule configured for '%s'", name))
+	if c.Bool("restore-test") {
+		if dbConfig.RestoreTest == nil {
+			printInfo(fmt.Sprintf("No restore test configured for '%s'", name))
+			return nil
+		}
+		dbConfig.RestoreTest.Enabled = false
+
+		err = mgr.AddDatabase(name, dbConfig)
+		recordAudit(audit.ActionConfigEdit, name, "", err, "restore test disabled")
+		if err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+
+		printSuccess(fmt.Sprintf("Restore test disabled for '%s'", name))
+		return nil
+	}
+
+	label := config.ScheduleLabel(name, c.String("schedule"))
+	schedule := dbConfig.FindSchedule(c.String("schedule"))
+	if schedule == nil {
+		printInfo(fmt.Sprintf("No schedule configured for '%s'", label))
 		return nil
 	}
 
-	dbConfig.Schedule.Enabled = false
+	schedule.Enabled = false
 
-	if err := mgr.AddDatabase(name, dbConfig); err != nil {
+	err = mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, fmt.Sprintf("schedule %s disabled", schedule.ScheduleName()))
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
-	printSuccess(fmt.Sprintf("Schedule disabled for '%s'", name))
+	printSuccess(fmt.Sprintf("Schedule disabled for '%s'", label))
+	return nil
+}
+
+func scheduleRemoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "remove",
+		Aliases:   []string{"rm"},
+		Usage:     "Remove backup schedule for a database",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "restore-test",
+				Usage: "Remove the restore test instead of the backup schedule",
+			},
+			scheduleNameFlag(),
+		},
+		Action: runScheduleRemove,
+	}
+}
+
+func runScheduleRemove(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan schedule remove <name>")
+	}
+
+	name := c.Args().Get(0)
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil {
+		printError(fmt.Sprintf("Database '%s' not found", name))
+		return err
+	}
+
+	if c.Bool("restore-test") {
+		if dbConfig.RestoreTest == nil {
+			printInfo(fmt.Sprintf("No restore test configured for '%s'", name))
+			return nil
+		}
+		dbConfig.RestoreTest = nil
+
+		err = mgr.AddDatabase(name, dbConfig)
+		recordAudit(audit.ActionConfigEdit, name, "", err, "restore test removed")
+		if err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+
+		printSuccess(fmt.Sprintf("Restore test removed for '%s'", name))
+		return nil
+	}
+
+	label := config.ScheduleLabel(name, c.String("schedule"))
+	schedule := dbConfig.FindSchedule(c.String("schedule"))
+	if schedule == nil {
+		printInfo(fmt.Sprintf("No schedule configured for '%s'", label))
+		return nil
+	}
+
+	dbConfig.RemoveSchedule(schedule.ScheduleName())
+
+	err = mgr.AddDatabase(name, dbConfig)
+	recordAudit(audit.ActionConfigEdit, name, "", err, fmt.Sprintf("schedule %s removed", schedule.ScheduleName()))
+	if err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	printSuccess(fmt.Sprintf("Schedule removed for '%s'", label))
+	return nil
+}
+
+func scheduleRunCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Run the scheduled backup of a database",
+		ArgsUsage: "<name>",
+		Description: `Run the scheduled backup of a database in the foreground with its
+   configured options, followed by its retention policy.
+
+   Without --now the schedule's jitter and blackout windows apply, exactly as
+   when the daemon runs it. The schedule does not have to be enabled.
+
+   EXAMPLES:
+     cadangkan schedule run production --now
+     cadangkan schedule run production --schedule=hourly --now`,
+		Flags: []cli.Flag{
+			scheduleNameFlag(),
+			&cli.BoolFlag{
+				Name:  "now",
+				Usage: "Start immediately, ignoring jitter and blackout windows",
+			},
+			&cli.BoolFlag{
+				Name:    "verbose",
+				Aliases: []string{"v"},
+				Usage:   "Enable verbose logging",
+			},
+		},
+		Action: runScheduleRun,
+	}
+}
+
+func runScheduleRun(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan schedule run <name> [--now]")
+	}
+
+	name := c.Args().Get(0)
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dbConfig, exists := cfg.Databases[name]
+	if !exists {
+		printError(fmt.Sprintf("Database '%s' not found", name))
+		return fmt.Errorf("database not found: %s", name)
+	}
+	label := config.ScheduleLabel(name, c.String("schedule"))
+	if schedule := dbConfig.FindSchedule(c.String("schedule")); schedule == nil || schedule.Cron == "" {
+		return fmt.Errorf("no schedule configured for '%s'\n\nSet a schedule first: cadangkan schedule set %s --daily", label, name)
+	}
+
+	localStorage, err := newLocalStorage("")
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	sched := scheduler.New(cfg, localStorage)
+	sched.SetVerbose(c.Bool("verbose"))
+
+	if err := sched.RunNow(name, c.String("schedule"), !c.Bool("now")); err != nil {
+		printError(fmt.Sprintf("Scheduled backup for '%s' failed", label))
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Scheduled backup for '%s' completed", label))
 	return nil
 }
 
@@ -266,22 +619,51 @@ func runScheduleList(c *cli.Context) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Collect scheduled databases
+	// Collect schedules, grouped by database
 	type scheduleEntry struct {
 		name     string
 		config   *config.DatabaseConfig
 		nextRun  time.Time
 		schedule cron.Schedule
 	}
+	type backupEntry struct {
+		config  *config.ScheduleConfig
+		nextRun time.Time
+	}
+
+	groups := make(map[string][]backupEntry)
+	var names []string
+	count := 0
+	for name, dbConfig := range cfg.Databases {
+		for _, schedule := range dbConfig.AllSchedules() {
+			if schedule.Cron == "" {
+				continue
+			}
+			sched, err := scheduler.ParseCron(schedule.Cron, schedule.Timezone)
+			if err != nil {
+				continue
+			}
+			if len(groups[name]) == 0 {
+				names = append(names, name)
+			}
+			groups[name] = append(groups[name], backupEntry{
+				config:  schedule,
+				nextRun: scheduler.NextRun(sched, schedule, time.Now()),
+			})
+			count++
+		}
+	}
+	sort.Strings(names)
 
-	var entries []scheduleEntry
+	// Collect restore tests
+	var drills []scheduleEntry
 	for name, dbConfig := range cfg.Databases {
-		if dbConfig.Schedule != nil && dbConfig.Schedule.Cron != "" {
-			sched, err := cron.ParseStandard(dbConfig.Schedule.Cron)
+		if dbConfig.RestoreTest != nil && dbConfig.RestoreTest.Cron != "" {
+			sched, err := scheduler.ParseCron(dbConfig.RestoreTest.Cron, "")
 			if err != nil {
 				continue
 			}
-			entries = append(entries, scheduleEntry{
+			drills = append(drills, scheduleEntry{
 				name:     name,
 				config:   dbConfig,
 				nextRun:  sched.Next(time.Now()),
@@ -290,7 +672,7 @@ func runScheduleList(c *cli.Context) error {
 		}
 	}
 
-	if len(entries) == 0 {
+	if count == 0 && len(drills) == 0 {
 		printInfo("No schedules configured")
 		fmt.Println()
 		fmt.Println("To add a schedule:")
@@ -298,31 +680,75 @@ func runScheduleList(c *cli.Context) error {
 		return nil
 	}
 
-	// Sort by next run time
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].nextRun.Before(entries[j].nextRun)
-	})
-
 	// Display schedules
 	fmt.Println()
-	fmt.Printf("Backup Schedules (%d)\n", len(entries))
+	fmt.Printf("Backup Schedules (%d)\n", count)
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println()
 
-	for _, entry := range entries {
-		status := colorRed + "Disabled" + colorReset
-		if entry.config.Schedule.Enabled {
-			status = colorGreen + "Enabled" + colorReset
-		}
+	for _, name := range names {
+		fmt.Printf("%s%s%s\n", colorCyan, name, colorReset)
+		for _, entry := range groups[name] {
+			schedule := entry.config
+			status := colorRed + "Disabled" + colorReset
+			if schedule.Enabled {
+				status = colorGreen + "Enabled" + colorReset
+			}
 
-		fmt.Printf("%s%-20s%s  %s\n", colorCyan, entry.name, colorReset, status)
-		fmt.Printf("  Schedule:  %s\n", entry.config.Schedule.Cron)
-		if entry.config.Schedule.Enabled {
-			fmt.Printf("  Next run:  %s (%s)\n", entry.nextRun.Format("2006-01-02 15:04:05"), formatNextRun(entry.nextRun))
+			fmt.Printf("  %-18s  %s\n", schedule.ScheduleName(), status)
+			fmt.Printf("    Schedule:  %s\n", schedule.Cron)
+			if schedule.Timezone != "" {
+				fmt.Printf("    Timezone:  %s\n", schedule.Timezone)
+			}
+			if schedule.Jitter != "" {
+				fmt.Printf("    Jitter:    up to %s\n", schedule.Jitter)
+			}
+			if len(schedule.Blackout) > 0 {
+				fmt.Printf("    Blackout:  %s\n", strings.Join(schedule.Blackout, ", "))
+			}
+			if options := scheduleOptions(schedule); options != "" {
+				fmt.Printf("    Options:   %s\n", options)
+			}
+			if schedule.Enabled {
+				fmt.Printf("    Next run:  %s\n", formatScheduledRun(entry.nextRun))
+			}
 		}
 		fmt.Println()
 	}
 
+	if len(drills) > 0 {
+		sort.Slice(drills, func(i, j int) bool {
+			return drills[i].nextRun.Before(drills[j].nextRun)
+		})
+
+		fmt.Printf("Restore Tests (%d)\n", len(drills))
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Println()
+
+		for _, entry := range drills {
+			drill := entry.config.RestoreTest
+			status := colorRed + "Disabled" + colorReset
+			if drill.Enabled {
+				status = colorGreen + "Enabled" + colorReset
+			}
+			scratch := drill.ScratchDatabase
+			if scratch == "" {
+				scratch = entry.config.Database + backup.DefaultDrillSuffix
+			}
+			if drill.Server != "" {
+				scratch += " on " + drill.Server
+			}
+
+			fmt.Printf("%s%-20s%s  %s\n", colorCyan, entry.name, colorReset, status)
+			fmt.Printf("  Schedule:  %s\n", drill.Cron)
+			fmt.Printf("  Scratch:   %s\n", scratch)
+			if drill.Enabled {
+				fmt.Printf("  Next run:  %s\n", formatTimeDetail(entry.nextRun))
+			}
+			fmt.Println()
+		}
+	}
+
 	fmt.Println("To start scheduled backups:")
 	fmt.Printf("  %scadangkan daemon%s\n", colorCyan, colorReset)
 	fmt.Println()
@@ -354,19 +780,35 @@ func runScheduleNext(c *cli.Context) error {
 		name    string
 		nextRun time.Time
 		cron    string
+		jitter  string
 	}
 
 	var entries []nextEntry
 	for name, dbConfig := range cfg.Databases {
-		if dbConfig.Schedule != nil && dbConfig.Schedule.Enabled {
-			sched, err := cron.ParseStandard(dbConfig.Schedule.Cron)
+		for _, schedule := range dbConfig.AllSchedules() {
+			if !schedule.Enabled {
+				continue
+			}
+			sched, err := scheduler.ParseCron(schedule.Cron, schedule.Timezone)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, nextEntry{
+				name:    config.ScheduleLabel(name, schedule.ScheduleName()),
+				nextRun: scheduler.NextRun(sched, schedule, time.Now()),
+				cron:    schedule.Cron,
+				jitter:  schedule.Jitter,
+			})
+		}
+		if dbConfig.RestoreTest != nil && dbConfig.RestoreTest.Enabled {
+			sched, err := scheduler.ParseCron(dbConfig.RestoreTest.Cron, "")
 			if err != nil {
 				continue
 			}
 			entries = append(entries, nextEntry{
-				name:    name,
+				name:    name + " (restore test)",
 				nextRun: sched.Next(time.Now()),
-				cron:    dbConfig.Schedule.Cron,
+				cron:    dbConfig.RestoreTest.Cron,
 			})
 		}
 	}
@@ -388,13 +830,22 @@ func runScheduleNext(c *cli.Context) error {
 	fmt.Println()
 
 	for _, entry := range entries {
+		if entry.nextRun.IsZero() {
+			fmt.Printf("%-20s  %snever (every run falls in a blackout window)%s\n", entry.name, colorYellow, colorReset)
+			continue
+		}
 		timeUntil := formatNextRun(entry.nextRun)
-		fmt.Printf("%-20s  %s  %s(%s)%s\n",
+		jitter := ""
+		if entry.jitter != "" {
+			jitter = fmt.Sprintf(" + up to %s jitter", entry.jitter)
+		}
+		fmt.Printf("%-20s  %s  %s(%s)%s%s\n",
 			entry.name,
 			entry.nextRun.Format("2006-01-02 15:04:05"),
 			colorCyan,
 			timeUntil,
 			colorReset,
+			jitter,
 		)
 	}
 	fmt.Println()
@@ -402,6 +853,67 @@ func runScheduleNext(c *cli.Context) error {
 	return nil
 }
 
+// scheduleNameFlag selects one of the named schedules of a database.
+func scheduleNameFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "schedule",
+		Aliases: []string{"s"},
+		Usage:   "Name of the schedule, for databases with several (default: the default schedule)",
+	}
+}
+
+// printScheduleRules shows the jitter and blackout windows of a schedule.
+func printScheduleRules(schedule *config.ScheduleConfig) {
+	if schedule.Timezone != "" {
+		fmt.Printf("  %sTimezone:%s  %s\n", colorCyan, colorReset, schedule.Timezone)
+	}
+	if schedule.Jitter != "" {
+		fmt.Printf("  %sJitter:%s    up to %s\n", colorCyan, colorReset, schedule.Jitter)
+	}
+	if len(schedule.Blackout) > 0 {
+		fmt.Printf("  %sBlackout:%s  %s\n", colorCyan, colorReset, strings.Join(schedule.Blackout, ", "))
+	}
+	if options := scheduleOptions(schedule); options != "" {
+		fmt.Printf("  %sOptions:%s   %s\n", colorCyan, colorReset, options)
+	}
+}
+
+// scheduleOptions summarizes the backup options of a schedule, or returns
+// an empty string for a full backup with default options.
+func scheduleOptions(schedule *config.ScheduleConfig) string {
+	var options []string
+	if schedule.Priority != 0 {
+		options = append(options, fmt.Sprintf("priority: %d", schedule.Priority))
+	}
+	if schedule.SchemaOnly {
+		options = append(options, "schema only")
+	}
+	if len(schedule.Tables) > 0 {
+		options = append(options, "tables: "+strings.Join(schedule.Tables, ","))
+	}
+	if len(schedule.ExcludeTables) > 0 {
+		options = append(options, "excluding: "+strings.Join(schedule.ExcludeTables, ","))
+	}
+	if schedule.Compression != "" {
+		options = append(options, "compression: "+schedule.Compression)
+	}
+	if schedule.Retention != nil {
+		options = append(options, "own retention")
+	}
+	if schedule.Output != "" {
+		options = append(options, "output: "+schedule.Output)
+	}
+	return strings.Join(options, ", ")
+}
+
+// formatScheduledRun formats a run time returned by scheduler.NextRun.
+func formatScheduledRun(t time.Time) string {
+	if t.IsZero() {
+		return "never (every run falls in a blackout window)"
+	}
+	return formatTimeDetail(t)
+}
+
 // parseDailyCron converts a time string (HH:MM) to a daily cron expression.
 func parseDailyCron(timeStr string) (string, error) {
 	t, err := time.Parse("15:04", timeStr)
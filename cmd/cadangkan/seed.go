@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+// seedRestoreFlags are the restore flags seed accepts.
+var seedRestoreFlags = map[string]bool{
+	"type": true, "from": true, "to": true, "exclude-tables": true,
+	"host": true, "port": true, "user": true, "password": true,
+	"dry-run": true, "plan": true, "force": true, "kill-sessions": true,
+	"ignore-charset": true, "sql-mode": true, "skip-definers": true, "defer-views": true,
+	"no-fast": true, "mysql-path": true, "engine": true, "yes": true, "confirm": true, "verbose": true,
+}
+
+func seedCommand() *cli.Command {
+	// Reuse the restore flags; the target database is always created
+	var flags []cli.Flag
+	for _, flag := range restoreCommand().Flags {
+		if seedRestoreFlags[flag.Names()[0]] {
+			flags = append(flags, flag)
+		}
+	}
+	flags = append(flags,
+		&cli.StringFlag{
+			Name:  "sample",
+			Usage: "Percentage of each table's rows to keep, e.g. 10%",
+		},
+		&cli.Int64Flag{
+			Name:  "max-rows",
+			Usage: "Keep at most this many rows of each table",
+		},
+		&cli.BoolFlag{Name: "create-db", Hidden: true},
+	)
+
+	return &cli.Command{
+		Name:      "seed",
+		Usage:     "Restore a down-sampled copy of a backup for development",
+		ArgsUsage: "[flags] <name>",
+		Description: `Restore a backup with its full schema but only a sample of the rows of
+   each table, into a developer's database. --sample keeps a percentage of
+   rows and --max-rows caps the rows of each table; give either or both.
+
+   Rows are picked by a hash of their first column, so the same rows are
+   kept on every run. Rows of a table with a foreign key are kept when the
+   row they reference is, so sampled orders belong to sampled users. Deeper
+   chains of foreign keys may leave some rows without their parent.
+
+   --to names the database to seed, which is created if needed. Point it at
+   another server with --host, --port, --user and --password.
+
+   EXAMPLES:
+     cadangkan seed --sample 10% --to shop_dev --host localhost --user dev production
+     cadangkan seed --from 2025-01-15-020000 --max-rows 1000 --to shop_dev production`,
+		Flags:  flags,
+		Action: runSeed,
+	}
+}
+
+func runSeed(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("database name is required\n\nUsage: cadangkan seed --sample <percent> --to <database> <name>")
+	}
+	name := c.Args().Get(0)
+
+	if !c.IsSet("sample") && !c.IsSet("max-rows") {
+		return fmt.Errorf("--sample or --max-rows is required")
+	}
+	if _, err := restoreSample(c); err != nil {
+		return err
+	}
+	if !c.IsSet("to") {
+		return fmt.Errorf("--to is required: the database to seed")
+	}
+
+	// Never seed over the database the backup was taken from
+	mgr, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil {
+		printError(fmt.Sprintf("Database '%s' not found in config", name))
+		return err
+	}
+	if c.String("to") == dbConfig.Database && !c.IsSet("host") {
+		return fmt.Errorf("refusing to seed '%s' over itself; choose another database with --to or another server with --host", dbConfig.Database)
+	}
+
+	if err := c.Set("create-db", "true"); err != nil {
+		return err
+	}
+	return runRestore(c)
+}
+
+// restoreSample returns the rows --sample and --max-rows keep of each table.
+func restoreSample(c *cli.Context) (backup.RowSample, error) {
+	var sample backup.RowSample
+	if s := c.String("sample"); s != "" {
+		percent, err := backup.ParseSamplePercent(s)
+		if err != nil {
+			return sample, err
+		}
+		sample.Percent = percent
+	}
+	sample.MaxRows = c.Int64("max-rows")
+	return sample, sample.Validate()
+}
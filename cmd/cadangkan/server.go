@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// configSchemas returns the schemas of the configured database name when it
+// is a server entry, connecting to the server for "*", and nil otherwise.
+func configSchemas(name string) ([]string, error) {
+	mgr, err := config.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	dbConfig, err := mgr.GetDatabase(name)
+	if err != nil || !dbConfig.IsServer() {
+		// Unknown names are reported by the command itself
+		return nil, nil
+	}
+
+	if dbConfig.Databases.All {
+		printInfo(fmt.Sprintf("Listing the databases of '%s' on %s:%d...", name, dbConfig.Host, dbConfig.Port))
+	}
+	return backupconfig.ServerSchemas(dbConfig, func() (backup.Introspector, error) {
+		password, err := dbConfig.Password()
+		if err != nil {
+			return nil, err
+		}
+		eng, err := backup.GetEngine(dbConfig.Type)
+		if err != nil {
+			return nil, err
+		}
+		client, err := eng.NewIntrospector(backup.MySQLConnection(&mysql.Config{
+			Host:     dbConfig.Host,
+			Port:     dbConfig.Port,
+			User:     dbConfig.User,
+			Password: password,
+			Timeout:  10 * time.Second,
+			SSL:      backupconfig.SSL(dbConfig.TLS),
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s client: %w", eng.DisplayName(), err)
+		}
+		if err := client.Connect(); err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		return client, nil
+	})
+}
+
+// requireDatabase returns an error when dbConfig is a server entry, for
+// commands working on a single database.
+func requireDatabase(dbConfig *config.DatabaseConfig) error {
+	if !dbConfig.IsServer() {
+		return nil
+	}
+	return fmt.Errorf("'%s' is a server entry; name one of its databases, e.g. %s",
+		dbConfig.Name, config.SchemaName(dbConfig.Name, "<database>"))
+}
+
+// databaseLabel describes the database of a configuration: its database,
+// or the schemas of a server entry.
+func databaseLabel(dbConfig *config.DatabaseConfig) string {
+	if dbConfig.IsServer() {
+		return "[" + dbConfig.Databases.String() + "]"
+	}
+	return dbConfig.Database
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/user"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+)
+
+// dropPrivileges switches the process from root to the named user, with
+// its primary and supplementary groups. HOME, USER and LOGNAME are set for
+// the user, so its config and data directories are used from then on
+// unless CADANGKAN_HOME is set. Not being root is fine if the process
+// already runs as that user.
+func dropPrivileges(name string) error {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return fmt.Errorf("unknown user %q: %w", name, err)
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return fmt.Errorf("user %q has a non-numeric uid %q", name, u.Uid)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return fmt.Errorf("user %q has a non-numeric gid %q", name, u.Gid)
+	}
+
+	switch os.Geteuid() {
+	case uid:
+		return nil
+	case 0:
+	default:
+		return fmt.Errorf("--user %s requires starting as root", name)
+	}
+
+	groupIDs, err := u.GroupIds()
+	if err != nil {
+		groupIDs = []string{u.Gid}
+	}
+	groups := make([]int, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if g, err := strconv.Atoi(id); err == nil {
+			groups = append(groups, g)
+		}
+	}
+
+	// Groups first: once the uid is dropped they can no longer be changed
+	if err := syscall.Setgroups(groups); err != nil {
+		return fmt.Errorf("failed to set groups: %w", err)
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return fmt.Errorf("failed to set gid %d: %w", gid, err)
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return fmt.Errorf("failed to set uid %d: %w", uid, err)
+	}
+	if syscall.Setuid(0) == nil {
+		return fmt.Errorf("failed to drop root privileges")
+	}
+
+	os.Setenv("HOME", u.HomeDir)
+	os.Setenv("USER", u.Username)
+	os.Setenv("LOGNAME", u.Username)
+	return nil
+}
+
+// requiredTools returns the client programs the schedules in cfg run,
+// each with the schedules that need it: mysqldump for backups and mysql
+// for restore tests, or the programs set by mysqldump_path and mysql_path.
+func requiredTools(cfg *config.Config) map[string][]string {
+	tools := make(map[string][]string)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Databases)) {
+		db := cfg.Databases[name]
+		mysqldump := cmp.Or(cfg.GetEffectiveMysqldumpPath(name), "mysqldump")
+		for _, schedule := range db.AllSchedules() {
+			if !schedule.Enabled {
+				continue
+			}
+			tools[mysqldump] = append(tools[mysqldump], config.ScheduleLabel(name, schedule.Name))
+		}
+		if db.RestoreTest != nil && db.RestoreTest.Enabled {
+			mysql := cmp.Or(cfg.GetEffectiveMysqlPath(name), "mysql")
+			tools[mysql] = append(tools[mysql], name)
+		}
+	}
+	return tools
+}
+
+// missingTools returns an error naming the programs in tools that are not
+// in PATH, or not executable when given as a path.
+func missingTools(tools map[string][]string) error {
+	var missing []string
+	for tool, users := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (needed by %s)", tool, strings.Join(users, ", ")))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("not found in PATH (%s): %s", os.Getenv("PATH"), strings.Join(missing, "; "))
+}
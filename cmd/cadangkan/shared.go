@@ -2,59 +2,43 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/paths"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 )
 
-// ANSI color codes
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorBlue   = "\033[34m"
-	colorCyan   = "\033[36m"
-)
+// msgOut is where status messages are written. It is switched to stderr
+// when command output (such as a backup stream) goes to stdout.
+var msgOut io.Writer = os.Stdout
 
 // printSuccess prints a success message with a green checkmark
 func printSuccess(message string) {
-	fmt.Printf("%s✓%s %s\n", colorGreen, colorReset, message)
+	fmt.Fprintf(msgOut, "%s✓%s %s\n", colorGreen, colorReset, message)
+	events.message("success", message)
 }
 
 // printError prints an error message with a red X
 func printError(message string) {
-	fmt.Printf("%s✗%s %s\n", colorRed, colorReset, message)
+	fmt.Fprintf(msgOut, "%s✗%s %s\n", colorRed, colorReset, message)
+	events.message("error", message)
 }
 
 // printInfo prints an info message with a blue icon
 func printInfo(message string) {
-	fmt.Printf("%sℹ%s %s\n", colorBlue, colorReset, message)
+	fmt.Fprintf(msgOut, "%sℹ%s %s\n", colorBlue, colorReset, message)
+	events.message("info", message)
 }
 
 // printWarning prints a warning message with a yellow icon
 func printWarning(message string) {
-	fmt.Printf("%s⚠%s %s\n", colorYellow, colorReset, message)
-}
-
-// showSpinner displays a simple spinner animation while backup is running
-func showSpinner(done chan bool) {
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	i := 0
-	for {
-		select {
-		case <-done:
-			fmt.Print("\r") // Clear the spinner line
-			return
-		default:
-			fmt.Printf("\r%s Backing up... ", spinner[i%len(spinner)])
-			i++
-			time.Sleep(100 * time.Millisecond)
-		}
-	}
+	fmt.Fprintf(msgOut, "%s⚠%s %s\n", colorYellow, colorReset, message)
+	events.message("warning", message)
 }
 
 // formatBackupResult formats and displays the backup result
@@ -72,101 +56,77 @@ func formatBackupResult(result *backup.BackupResult, database string) {
 		checksum = checksum[:23] + "..."
 	}
 
-	fmt.Printf("  %sBackup ID:%s   %s\n", colorCyan, colorReset, result.BackupID)
-	fmt.Printf("  %sDatabase:%s    %s\n", colorCyan, colorReset, database)
-	fmt.Printf("  %sFile:%s        %s\n", colorCyan, colorReset, displayPath)
-	fmt.Printf("  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(result.SizeBytes))
-	fmt.Printf("  %sDuration:%s    %s\n", colorCyan, colorReset, backup.FormatDuration(result.Duration))
-	fmt.Printf("  %sChecksum:%s    %s\n", colorCyan, colorReset, checksum)
-	fmt.Println()
-	fmt.Printf("Backup saved to: %s\n", displayPath)
+	fmt.Fprintf(msgOut, "  %sBackup ID:%s   %s\n", colorCyan, colorReset, result.BackupID)
+	fmt.Fprintf(msgOut, "  %sDatabase:%s    %s\n", colorCyan, colorReset, database)
+	fmt.Fprintf(msgOut, "  %sFile:%s        %s\n", colorCyan, colorReset, displayPath)
+	fmt.Fprintf(msgOut, "  %sSize:%s        %s\n", colorCyan, colorReset, backup.FormatBytes(result.SizeBytes))
+	fmt.Fprintf(msgOut, "  %sDuration:%s    %s\n", colorCyan, colorReset, backup.FormatDuration(result.Duration))
+	fmt.Fprintf(msgOut, "  %sChecksum:%s    %s\n", colorCyan, colorReset, checksum)
+	fmt.Fprintln(msgOut)
+	fmt.Fprintf(msgOut, "Backup saved to: %s\n", displayPath)
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// newLocalStorage creates local storage using the layout from config.yaml.
+// If basePath is empty, uses storage.path from config.yaml or the backups
+// directory in the data directory
+func newLocalStorage(basePath string) (*storage.LocalStorage, error) {
+	mgr, err := config.NewManager()
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
-	return filepath.Join(homeDir, ".cadangkan", "config.yaml"), nil
-}
 
-// ensureConfigDir ensures the config directory exists
-func ensureConfigDir() error {
-	homeDir, err := os.UserHomeDir()
+	if basePath == "" && cfg.Storage != nil {
+		basePath = cfg.Storage.Path
+	}
+	localStorage, err := storage.NewLocalStorage(basePath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if cfg.Storage != nil {
+		if err := localStorage.SetLayout(cfg.Storage.Layout); err != nil {
+			return nil, err
+		}
 	}
-	configDir := filepath.Join(homeDir, ".cadangkan")
-	return os.MkdirAll(configDir, 0755)
+
+	return localStorage, nil
 }
 
-// formatTimeAgo formats a time as "X ago" (e.g., "2 hours ago", "3 days ago")
-func formatTimeAgo(t time.Time) string {
-	now := time.Now()
-	diff := now.Sub(t)
+// newDatabaseStorage creates local storage for the backups of a database,
+// in its storage_path from config.yaml if it has one.
+func newDatabaseStorage(name string) (*storage.LocalStorage, error) {
+	localStorage, err := newLocalStorage("")
+	if err != nil {
+		return nil, err
+	}
 
-	if diff < time.Minute {
-		return "just now"
-	} else if diff < time.Hour {
-		minutes := int(diff.Minutes())
-		if minutes == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", minutes)
-	} else if diff < 24*time.Hour {
-		hours := int(diff.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	} else if diff < 30*24*time.Hour {
-		days := int(diff.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	} else if diff < 365*24*time.Hour {
-		months := int(diff.Hours() / (24 * 30))
-		if months == 1 {
-			return "1 month ago"
-		}
-		return fmt.Sprintf("%d months ago", months)
-	} else {
-		years := int(diff.Hours() / (24 * 365))
-		if years == 1 {
-			return "1 year ago"
-		}
-		return fmt.Sprintf("%d years ago", years)
+	mgr, err := config.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+	cfg, err := mgr.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	return localStorage.At(cfg.GetEffectiveStoragePath(name)), nil
 }
 
-// formatNextRun formats a time as "in X" (e.g., "in 22 hours", "in 6 days")
-func formatNextRun(t time.Time) string {
-	now := time.Now()
-	diff := t.Sub(now)
+// getConfigPath returns the path to the config file
+func getConfigPath() (string, error) {
+	return paths.ConfigFile("config.yaml")
+}
 
-	if diff < 0 {
-		return "overdue"
-	} else if diff < time.Hour {
-		minutes := int(diff.Minutes())
-		if minutes == 1 {
-			return "in 1 minute"
-		}
-		return fmt.Sprintf("in %d minutes", minutes)
-	} else if diff < 24*time.Hour {
-		hours := int(diff.Hours())
-		if hours == 1 {
-			return "in 1 hour"
-		}
-		return fmt.Sprintf("in %d hours", hours)
-	} else {
-		days := int(diff.Hours() / 24)
-		if days == 1 {
-			return "in 1 day"
-		}
-		return fmt.Sprintf("in %d days", days)
+// ensureConfigDir ensures the config directory exists
+func ensureConfigDir() error {
+	configDir, err := paths.ConfigDir()
+	if err != nil {
+		return err
 	}
+	return os.MkdirAll(configDir, 0700)
 }
 
 // getStatusIndicator returns a status indicator symbol based on status string
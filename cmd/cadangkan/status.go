@@ -5,10 +5,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
 	"github.com/erickhilda/cadangkan/internal/config"
 	"github.com/erickhilda/cadangkan/internal/status"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,13 +21,15 @@ func statusCommand() *cli.Command {
    USAGE:
      cadangkan status              # Show overall status for all databases
      cadangkan status <database>   # Show detailed status for a specific database`,
+		Flags:  []cli.Flag{absoluteFlag()},
+		Before: applyAbsoluteFlag,
 		Action: runStatus,
 	}
 }
 
 func runStatus(c *cli.Context) error {
 	// Create storage and config manager
-	storageInstance, err := storage.NewLocalStorage("")
+	storageInstance, err := newLocalStorage("")
 	if err != nil {
 		return fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -61,13 +62,30 @@ func showOverallStatus(svc *status.Service) error {
 
 	// Service status
 	serviceStatus := overall.ServiceStatus
-	if serviceStatus == "Not running" {
+	if serviceStatus == status.ServiceNotRunning {
 		fmt.Printf("Service: %s%s%s\n", colorYellow, serviceStatus, colorReset)
 	} else {
-		fmt.Printf("Service: %s%s%s\n", colorGreen, serviceStatus, colorReset)
+		fmt.Printf("Service: %s%s%s (up %s)\n", colorGreen, serviceStatus, colorReset,
+			backup.FormatDuration(time.Since(overall.Daemon.StartedAt).Round(time.Second)))
+		for _, run := range overall.Daemon.Running {
+			fmt.Printf("  Running: %s (started %s)\n", run.Label, formatTimeAgo(run.StartedAt))
+		}
+		for _, queued := range overall.Daemon.Queued {
+			fmt.Printf("  Queued:  %s (since %s, priority %d)\n", queued.Label, formatTimeAgo(queued.QueuedAt), queued.Priority)
+		}
+		for _, failure := range overall.Daemon.LastErrors {
+			fmt.Printf("  %sFailed:%s  %s %s: %s\n", colorRed, colorReset, failure.Label, formatTimeAgo(failure.Time), failure.Error)
+		}
 	}
 
 	// Database count
+	if state := overall.Maintenance; state != nil {
+		fmt.Printf("Maintenance: %sON%s since %s by %s", colorYellow, colorReset, formatTimeAgo(state.Since), state.User)
+		if state.Reason != "" {
+			fmt.Printf(" (%s)", state.Reason)
+		}
+		fmt.Println()
+	}
 	fmt.Printf("Databases: %d", overall.DatabaseCount)
 	if overall.ActiveCount > 0 {
 		fmt.Printf(" (%d active)", overall.ActiveCount)
@@ -105,6 +123,9 @@ func showOverallStatus(svc *status.Service) error {
 				lastBackupStr = formatTimeAgo(*db.LastBackup)
 			}
 			nextBackupStr := db.NextBackup
+			if db.NextRun != nil {
+				nextBackupStr = formatNextRun(*db.NextRun)
+			}
 
 			fmt.Printf("%-20s %-10s %-8s %-20s %-15s\n",
 				db.Name,
@@ -152,10 +173,13 @@ func showDatabaseStatus(svc *status.Service, dbName string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	dbConfig, exists := cfg.Databases[dbName]
+	dbConfig, exists := cfg.Database(dbName)
 	if !exists {
 		return fmt.Errorf("database '%s' not found", dbName)
 	}
+	if err := requireDatabase(dbConfig); err != nil {
+		return err
+	}
 
 	fmt.Printf("\n%sStatus for %s%s\n", colorCyan, colorReset, dbName)
 	fmt.Println(strings.Repeat("=", 80))
@@ -163,7 +187,7 @@ func showDatabaseStatus(svc *status.Service, dbName string) error {
 	// Database info
 	fmt.Printf("Type:     %s\n", dbStatus.Type)
 	fmt.Printf("Host:     %s:%d\n", dbConfig.Host, dbConfig.Port)
-	fmt.Printf("Database: %s\n", dbConfig.Database)
+	fmt.Printf("Database: %s\n", databaseLabel(dbConfig))
 	fmt.Printf("User:     %s\n", dbConfig.User)
 	fmt.Println()
 
@@ -181,22 +205,40 @@ func showDatabaseStatus(svc *status.Service, dbName string) error {
 	} else {
 		fmt.Printf("  Failed:            %d\n", dbStatus.FailedCount)
 	}
-	fmt.Printf("  Storage Used:      %s\n", backup.FormatBytes(dbStatus.StorageUsed))
+	fmt.Printf("  Storage Used:      %s (%s bytes)\n", backup.FormatBytes(dbStatus.StorageUsed), backup.FormatCount(dbStatus.StorageUsed))
 	fmt.Println()
 
 	// Last backup details
 	if dbStatus.LastBackup != nil {
 		fmt.Println("Last Backup:")
 		fmt.Printf("  ID:       %s\n", dbStatus.LastBackupID)
-		fmt.Printf("  Time:     %s (%s)\n", dbStatus.LastBackup.Format(time.RFC3339), formatTimeAgo(*dbStatus.LastBackup))
+		fmt.Printf("  Time:     %s\n", formatTimeDetail(*dbStatus.LastBackup))
 		fmt.Println()
 	} else {
 		fmt.Println("Last Backup: Never")
 		fmt.Println()
 	}
 
+	// Freshness SLA
+	if f := dbStatus.Freshness; f != nil {
+		lastSuccess := "no successful backup"
+		if f.LastSuccess != nil {
+			lastSuccess = "last successful backup " + formatTimeAgo(*f.LastSuccess)
+		}
+		if f.Breached {
+			fmt.Printf("Freshness SLA: %s (%s) %sBREACHED%s\n", backup.FormatDuration(f.SLA), lastSuccess, colorRed, colorReset)
+		} else {
+			fmt.Printf("Freshness SLA: %s (%s) %sOK%s\n", backup.FormatDuration(f.SLA), lastSuccess, colorGreen, colorReset)
+		}
+		fmt.Println()
+	}
+
 	// Next scheduled backup
-	fmt.Printf("Next Scheduled Backup: %s\n", dbStatus.NextBackup)
+	if dbStatus.NextRun != nil {
+		fmt.Printf("Next Scheduled Backup: %s (%s)\n", formatTimeDetail(*dbStatus.NextRun), dbStatus.NextBackup)
+	} else {
+		fmt.Printf("Next Scheduled Backup: %s\n", dbStatus.NextBackup)
+	}
 	fmt.Println()
 
 	// Recent backups
@@ -206,7 +248,7 @@ func showDatabaseStatus(svc *status.Service, dbName string) error {
 		fmt.Println(strings.Repeat("-", 80))
 
 		for _, b := range dbStatus.RecentBackups {
-			dateStr := b.CreatedAt.Format("2006-01-02 15:04:05")
+			dateStr := formatTimeAgo(b.CreatedAt)
 			sizeStr := b.SizeHuman
 			if sizeStr == "" {
 				sizeStr = backup.FormatBytes(b.SizeBytes)
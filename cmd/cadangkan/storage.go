@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
 	"github.com/erickhilda/cadangkan/internal/config"
 	"github.com/erickhilda/cadangkan/internal/status"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/urfave/cli/v2"
 )
 
@@ -28,7 +27,7 @@ func storageCommand() *cli.Command {
 
 func runStorage(c *cli.Context) error {
 	// Create storage and config manager
-	storageInstance, err := storage.NewLocalStorage("")
+	storageInstance, err := newLocalStorage("")
 	if err != nil {
 		return fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// Sort orders of the listing commands
+const (
+	sortName = "name"
+	sortDate = "date"
+	sortSize = "size"
+)
+
+// tableColumn is a column of a listing table.
+type tableColumn struct {
+	key    string // Name used by --columns
+	title  string
+	width  int
+	hidden bool // Only shown when selected with --columns
+}
+
+// listRow is a row of a listing: the fields it is sorted and filtered by,
+// and its cells by column key.
+type listRow struct {
+	name   string
+	date   time.Time
+	size   int64
+	status string
+	cells  map[string]string
+}
+
+// listOptions holds the sorting, filtering and column flags shared by the
+// listing commands.
+type listOptions struct {
+	sort    string
+	since   time.Time
+	before  time.Time
+	status  string
+	limit   int
+	columns []tableColumn
+}
+
+// listFlags returns the flags parsed by parseListOptions.
+func listFlags(defaultSort string, columns []tableColumn) []cli.Flag {
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = column.key
+	}
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sort",
+			Value: defaultSort,
+			Usage: "Sort by name, date (newest first) or size (largest first)",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Only show entries dated on or after a date (2006-01-02) or age (7d, 12h)",
+		},
+		&cli.StringFlag{
+			Name:  "before",
+			Usage: "Only show entries dated before a date (2006-01-02) or age (7d, 12h)",
+		},
+		&cli.StringFlag{
+			Name:  "status",
+			Usage: "Only show entries with this status, e.g. failed",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "Show at most N entries (0 for all)",
+		},
+		&cli.StringFlag{
+			Name:  "columns",
+			Usage: "Comma-separated columns to show: " + strings.Join(keys, ","),
+		},
+	}
+}
+
+// parseListOptions reads the flags of listFlags, selecting from columns.
+func parseListOptions(c *cli.Context, columns []tableColumn) (*listOptions, error) {
+	opts := &listOptions{
+		sort:   c.String("sort"),
+		status: c.String("status"),
+		limit:  c.Int("limit"),
+	}
+
+	switch opts.sort {
+	case sortName, sortDate, sortSize:
+	default:
+		return nil, fmt.Errorf("invalid sort: %s (must be 'name', 'date' or 'size')", opts.sort)
+	}
+	if opts.limit < 0 {
+		return nil, fmt.Errorf("--limit cannot be negative")
+	}
+
+	var err error
+	if value := c.String("since"); value != "" {
+		if opts.since, err = parseListTime(value); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if value := c.String("before"); value != "" {
+		if opts.before, err = parseListTime(value); err != nil {
+			return nil, fmt.Errorf("invalid --before: %w", err)
+		}
+	}
+
+	if opts.columns, err = selectColumns(columns, c.String("columns")); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// parseListTime parses a date, a date and time, or an age before now.
+func parseListTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	age, err := config.ParseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (2006-01-02) nor an age (7d)", value)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// selectColumns returns the columns named in keys, in that order, or the
+// columns that are not hidden when keys is empty.
+func selectColumns(columns []tableColumn, keys string) ([]tableColumn, error) {
+	if keys == "" {
+		var selected []tableColumn
+		for _, column := range columns {
+			if !column.hidden {
+				selected = append(selected, column)
+			}
+		}
+		return selected, nil
+	}
+
+	var selected []tableColumn
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		found := false
+		for _, column := range columns {
+			if column.key == key {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", key)
+		}
+	}
+	return selected, nil
+}
+
+// apply filters, sorts and limits rows.
+func (o *listOptions) apply(rows []listRow) []listRow {
+	filtered := make([]listRow, 0, len(rows))
+	for _, row := range rows {
+		if o.status != "" && row.status != o.status {
+			continue
+		}
+		if !o.since.IsZero() && row.date.Before(o.since) {
+			continue
+		}
+		if !o.before.IsZero() && !row.date.Before(o.before) {
+			continue
+		}
+		filtered = append(filtered, row)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		switch o.sort {
+		case sortDate:
+			if !a.date.Equal(b.date) {
+				return a.date.After(b.date)
+			}
+		case sortSize:
+			if a.size != b.size {
+				return a.size > b.size
+			}
+		}
+		return a.name < b.name
+	})
+
+	if o.limit > 0 && len(filtered) > o.limit {
+		filtered = filtered[:o.limit]
+	}
+	return filtered
+}
+
+// filtering reports whether any filter is set.
+func (o *listOptions) filtering() bool {
+	return o.status != "" || !o.since.IsZero() || !o.before.IsZero() || o.limit > 0
+}
+
+// printTable prints the header and rows of a listing table.
+func printTable(columns []tableColumn, rows []listRow) {
+	width := 0
+	for _, column := range columns {
+		width += column.width + 1
+	}
+	if width < 80 {
+		width = 80
+	}
+
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.title
+	}
+	fmt.Println(strings.Repeat("=", width))
+	printTableLine(columns, titles)
+	fmt.Println(strings.Repeat("-", width))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = row.cells[column.key]
+		}
+		printTableLine(columns, cells)
+	}
+}
+
+// printTableLine prints cells padded to their column widths; the last cell
+// is not padded.
+func printTableLine(columns []tableColumn, cells []string) {
+	var line strings.Builder
+	for i, cell := range cells {
+		if i == len(cells)-1 {
+			line.WriteString(cell)
+			break
+		}
+		fmt.Fprintf(&line, "%-*s ", columns[i].width, cell)
+	}
+	fmt.Println(line.String())
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/i18n"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
 	"github.com/urfave/cli/v2"
 )
@@ -36,14 +38,14 @@ func runTest(c *cli.Context) error {
 	printInfo(fmt.Sprintf("Loading configuration for '%s'...", name))
 	dbConfig, err := mgr.GetDatabase(name)
 	if err != nil {
-		printError("Database not found")
+		printError(i18n.T("Database not found"))
 		return err
 	}
 
-	// Decrypt password
-	password, err := config.DecryptPassword(dbConfig.PasswordEncrypted)
+	// Resolve password
+	password, err := dbConfig.Password()
 	if err != nil {
-		printError("Failed to decrypt password")
+		printError(i18n.T("Failed to get password"))
 		return err
 	}
 
@@ -57,16 +59,17 @@ func runTest(c *cli.Context) error {
 		Password: password,
 		Database: dbConfig.Database,
 		Timeout:  10 * time.Second,
+		SSL:      backupconfig.SSL(dbConfig.TLS),
 	}
 
 	client, err := mysql.NewClient(mysqlConfig)
 	if err != nil {
-		printError("Failed to create MySQL client")
+		printError(i18n.T("Failed to create MySQL client"))
 		return err
 	}
 
 	if err := client.Connect(); err != nil {
-		printError("Connection failed")
+		printError(i18n.T("Connection failed"))
 		return err
 	}
 	defer client.Close()
@@ -79,6 +82,11 @@ func runTest(c *cli.Context) error {
 
 	printSuccess(fmt.Sprintf("Connected successfully (MySQL %s)", dbVersion))
 
+	if dbConfig.IsServer() {
+		fmt.Printf("\n  %sDatabases:%s %s\n", colorCyan, colorReset, databaseLabel(dbConfig))
+		return nil
+	}
+
 	// Get database size
 	size, err := client.GetDatabaseSize(dbConfig.Database)
 	if err == nil {
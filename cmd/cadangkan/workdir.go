@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/urfave/cli/v2"
+)
+
+// setupWorkDir points scratch files at work_dir from config.yaml and
+// removes run directories left behind by processes that were killed. A
+// config that cannot be loaded is left for the command to report.
+func setupWorkDir() {
+	mgr, err := config.NewManager()
+	if err != nil {
+		return
+	}
+	cfg, err := mgr.Load()
+	if err != nil || cfg.WorkDir == nil {
+		return
+	}
+	minFree, err := cfg.WorkDir.MinFreeBytes()
+	if err != nil {
+		return
+	}
+
+	workDir := &backup.WorkDir{Path: cfg.WorkDir.Path, MinFree: minFree}
+	backup.SetWorkDir(workDir)
+	if _, err := workDir.CleanStale(); err != nil {
+		printWarning("Failed to remove stale work directories: " + err.Error())
+	}
+}
+
+// cleanupWorkDir removes the run directory of this process once the
+// command has finished.
+func cleanupWorkDir(c *cli.Context) error {
+	if err := backup.CurrentWorkDir().Cleanup(); err != nil {
+		printWarning("Failed to remove work directory: " + err.Error())
+	}
+	return nil
+}
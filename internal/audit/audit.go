@@ -0,0 +1,274 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionRestore        = "restore"
+	ActionRestoreTest    = "restore-test"
+	ActionExtract        = "extract"
+	ActionSearch         = "search"
+	ActionRedact         = "redact"
+	ActionImport         = "import"
+	ActionPrune          = "prune"
+	ActionGC             = "gc"
+	ActionRepair         = "repair"
+	ActionInterrupted    = "interrupted"
+	ActionDelete         = "delete"
+	ActionLock           = "lock"
+	ActionUnlock         = "unlock"
+	ActionConfigAdd      = "config.add"
+	ActionConfigEdit     = "config.edit"
+	ActionConfigRemove   = "config.remove"
+	ActionMaintenanceOn  = "maintenance.on"
+	ActionMaintenanceOff = "maintenance.off"
+)
+
+// Results recorded in the audit log
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// DefaultSyslogTag is the syslog tag used when none is configured.
+const DefaultSyslogTag = "cadangkan"
+
+// Entry is a single audit log record.
+type Entry struct {
+	// Time is when the operation finished
+	Time time.Time `json:"time"`
+
+	// User is the OS user that ran the operation
+	User string `json:"user"`
+
+	// Hostname is the host the operation ran on
+	Hostname string `json:"hostname"`
+
+	// Action is what was done (e.g., "restore", "prune")
+	Action string `json:"action"`
+
+	// Database is the configured database name
+	Database string `json:"database,omitempty"`
+
+	// Target is the object acted on (backup ID, source, target database)
+	Target string `json:"target,omitempty"`
+
+	// Result is "success" or "failure"
+	Result string `json:"result"`
+
+	// Error is the failure message, if any
+	Error string `json:"error,omitempty"`
+
+	// Details is free-form context about the operation
+	Details string `json:"details,omitempty"`
+}
+
+// NewEntry creates an entry for action whose outcome is err.
+func NewEntry(action, database, target string, err error) Entry {
+	entry := Entry{
+		Action:   action,
+		Database: database,
+		Target:   target,
+		Result:   ResultSuccess,
+	}
+	if err != nil {
+		entry.Result = ResultFailure
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// Logger appends entries to an append-only JSON lines file and,
+// optionally, forwards them to syslog.
+type Logger struct {
+	path   string
+	syslog io.WriteCloser
+	mu     sync.Mutex
+}
+
+// DefaultPath returns the default audit log path.
+func DefaultPath() (string, error) {
+	return paths.DataFile("audit.log")
+}
+
+// NewLogger creates a logger writing to path.
+// If path is empty, uses the default path.
+func NewLogger(path string) (*Logger, error) {
+	if path == "" {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Logger{path: path}, nil
+}
+
+// FromConfig creates a logger from the audit section of cfg.
+// A nil config or audit section uses the defaults.
+func FromConfig(cfg *config.Config) (*Logger, error) {
+	var auditCfg config.AuditConfig
+	if cfg != nil && cfg.Audit != nil {
+		auditCfg = *cfg.Audit
+	}
+
+	logger, err := NewLogger(auditCfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if auditCfg.Syslog {
+		tag := auditCfg.SyslogTag
+		if tag == "" {
+			tag = DefaultSyslogTag
+		}
+		if err := logger.EnableSyslog(tag); err != nil {
+			return logger, err
+		}
+	}
+
+	return logger, nil
+}
+
+// Path returns the audit log file path.
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// EnableSyslog forwards every recorded entry to the local syslog daemon.
+func (l *Logger) EnableSyslog(tag string) error {
+	writer, err := newSyslogWriter(tag)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.syslog != nil {
+		l.syslog.Close()
+	}
+	l.syslog = writer
+	return nil
+}
+
+// Record fills in the time, user and hostname of entry and appends it to the log.
+func (l *Logger) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.User == "" {
+		entry.User = CurrentUser()
+	}
+	if entry.Hostname == "" {
+		entry.Hostname, _ = os.Hostname()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	if l.syslog != nil {
+		if _, err := l.syslog.Write(data); err != nil {
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close releases the syslog connection, if any.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.syslog == nil {
+		return nil
+	}
+	err := l.syslog.Close()
+	l.syslog = nil
+	return err
+}
+
+// ReadEntries reads all entries from the audit log at path, oldest first.
+// A missing log file yields no entries.
+func ReadEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CurrentUser returns the name of the user running the process.
+// Under sudo, the invoking user is included.
+func CurrentUser() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != name {
+		name = fmt.Sprintf("%s (sudo by %s)", name, sudoUser)
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return name
+}
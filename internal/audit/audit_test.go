@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	entry := NewEntry(ActionRestore, "production", "2025-01-15-143022", nil)
+	assert.Equal(t, ResultSuccess, entry.Result)
+	assert.Empty(t, entry.Error)
+
+	entry = NewEntry(ActionRestore, "production", "2025-01-15-143022", errors.New("access denied"))
+	assert.Equal(t, ResultFailure, entry.Result)
+	assert.Equal(t, "access denied", entry.Error)
+}
+
+func TestLoggerRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	logger, err := NewLogger(path)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	require.NoError(t, logger.Record(NewEntry(ActionRestore, "production", "2025-01-15-143022", nil)))
+	require.NoError(t, logger.Record(NewEntry(ActionPrune, "production", "", errors.New("disk error"))))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := ReadEntries(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, ActionRestore, entries[0].Action)
+	assert.Equal(t, "2025-01-15-143022", entries[0].Target)
+	assert.NotEmpty(t, entries[0].User)
+	assert.NotEmpty(t, entries[0].Hostname)
+	assert.False(t, entries[0].Time.IsZero())
+
+	assert.Equal(t, ActionPrune, entries[1].Action)
+	assert.Equal(t, ResultFailure, entries[1].Result)
+	assert.Equal(t, "disk error", entries[1].Error)
+}
+
+func TestReadEntries(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		entries, err := ReadEntries(filepath.Join(t.TempDir(), "audit.log"))
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		require.NoError(t, os.WriteFile(path, []byte("{\"action\":\"restore\"}\nnot json\n"), 0600))
+
+		_, err := ReadEntries(path)
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.log")
+	cfg := config.NewConfig()
+	cfg.Audit = &config.AuditConfig{Path: path}
+
+	logger, err := FromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, path, logger.Path())
+
+	logger, err = FromConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "audit.log", filepath.Base(logger.Path()))
+}
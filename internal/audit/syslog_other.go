@@ -0,0 +1,13 @@
+//go:build !(unix || linux || darwin)
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter reports that syslog is unavailable on this platform.
+func newSyslogWriter(tag string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
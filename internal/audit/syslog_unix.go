@@ -0,0 +1,13 @@
+//go:build unix || linux || darwin
+
+package audit
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// newSyslogWriter connects to the local syslog daemon.
+func newSyslogWriter(tag string) (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+}
@@ -0,0 +1,148 @@
+// Package backupconfig builds the settings and policies of package backup
+// from a cadangkan configuration. It keeps the configuration types out of
+// the public backup API.
+package backupconfig
+
+import (
+	"fmt"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// SSL builds the SSL settings of a connection from a database's tls
+// config, or nil when it has none.
+func SSL(cfg *config.TLSConfig) *mysql.SSLConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &mysql.SSLConfig{
+		Mode: cfg.Mode,
+		CA:   cfg.CA,
+		Cert: cfg.Cert,
+		Key:  cfg.Key,
+	}
+}
+
+// ServerSchemas returns the schemas backed up for a server entry: its
+// listed databases, or for "*" the databases found on the server, leaving
+// out the system schemas. connect is only called for "*" and the client it
+// returns is closed.
+func ServerSchemas(dbConfig *config.DatabaseConfig, connect func() (backup.Introspector, error)) ([]string, error) {
+	if !dbConfig.Databases.All {
+		return dbConfig.Databases.Names, nil
+	}
+
+	client, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	schemas, err := backup.DiscoverSchemas(client)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no databases found on %s:%d", dbConfig.Host, dbConfig.Port)
+	}
+	return schemas, nil
+}
+
+// RetentionPolicy converts a retention config.
+func RetentionPolicy(cfg *config.RetentionPolicy) *backup.RetentionPolicy {
+	return &backup.RetentionPolicy{
+		Daily:   cfg.Daily,
+		Weekly:  cfg.Weekly,
+		Monthly: cfg.Monthly,
+		KeepAll: cfg.KeepAll,
+	}
+}
+
+// QuotaPolicy builds the quota policy for a database from config.
+// Returns nil if no quota applies.
+func QuotaPolicy(cfg *config.Config, dbName string) (*backup.QuotaPolicy, error) {
+	dbQuota := cfg.GetEffectiveQuota(dbName)
+	globalQuota := cfg.GetGlobalQuota()
+
+	dbLimit, err := dbQuota.Limit()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota for %s: %w", dbName, err)
+	}
+	totalLimit, err := globalQuota.Limit()
+	if err != nil {
+		return nil, fmt.Errorf("invalid global quota: %w", err)
+	}
+	if dbLimit <= 0 && totalLimit <= 0 {
+		return nil, nil
+	}
+
+	policy := &backup.QuotaPolicy{
+		Limits:  backup.Quota{DatabaseLimit: dbLimit, TotalLimit: totalLimit},
+		MinKeep: config.DefaultQuotaMinKeep,
+	}
+	if dbLimit > 0 && dbQuota.Action == config.QuotaActionPrune {
+		policy.Prune.DatabaseLimit = dbLimit
+		policy.MinKeep = dbQuota.GetMinKeep()
+	}
+	if totalLimit > 0 && globalQuota.Action == config.QuotaActionPrune {
+		policy.Prune.TotalLimit = totalLimit
+		if minKeep := globalQuota.GetMinKeep(); minKeep > policy.MinKeep {
+			policy.MinKeep = minKeep
+		}
+	}
+
+	return policy, nil
+}
+
+// WarningPolicy builds a policy from a database's mysqldump config.
+func WarningPolicy(cfg *config.MysqldumpConfig) (*backup.WarningPolicy, error) {
+	if cfg == nil || cfg.Warnings == nil {
+		return backup.DefaultWarningPolicy(), nil
+	}
+	return backup.NewWarningPolicy(cfg.Warnings.Action, cfg.Warnings.Allow)
+}
+
+// LongQueryPolicy builds a policy from a database's long query config, or
+// returns nil when the check is not configured.
+func LongQueryPolicy(cfg *config.LongQueryConfig) (*backup.LongQueryPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	policy := &backup.LongQueryPolicy{Threshold: backup.DefaultLongQueryThreshold, Action: cfg.Action}
+	if cfg.Threshold != "" {
+		threshold, err := config.ParseAge(cfg.Threshold)
+		if err != nil {
+			return nil, &backup.ValidationError{Field: "long_queries.threshold", Message: err.Error()}
+		}
+		policy.Threshold = threshold
+	}
+	return policy, policy.Validate()
+}
+
+// LockMonitorPolicy builds a policy from a database's lock monitor config,
+// or returns nil when monitoring is not configured.
+func LockMonitorPolicy(cfg *config.LockMonitorConfig) (*backup.LockMonitorPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	policy := &backup.LockMonitorPolicy{Threshold: backup.DefaultLockThreshold, Interval: backup.DefaultLockInterval, Action: cfg.Action}
+	if cfg.Threshold != "" {
+		threshold, err := config.ParseAge(cfg.Threshold)
+		if err != nil {
+			return nil, &backup.ValidationError{Field: "lock_monitor.threshold", Message: err.Error()}
+		}
+		policy.Threshold = threshold
+	}
+	if cfg.Interval != "" {
+		interval, err := config.ParseAge(cfg.Interval)
+		if err != nil {
+			return nil, &backup.ValidationError{Field: "lock_monitor.interval", Message: err.Error()}
+		}
+		policy.Interval = interval
+	}
+	return policy, policy.Validate()
+}
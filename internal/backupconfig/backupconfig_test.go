@@ -0,0 +1,162 @@
+package backupconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSL(t *testing.T) {
+	assert.Nil(t, SSL(nil))
+	assert.Equal(t, &mysql.SSLConfig{Mode: mysql.SSLModeVerifyCA, CA: "/etc/ca.pem", Cert: "/etc/client.pem", Key: "/etc/client.key"},
+		SSL(&config.TLSConfig{Mode: config.TLSModeVerifyCA, CA: "/etc/ca.pem", Cert: "/etc/client.pem", Key: "/etc/client.key"}))
+}
+
+func TestServerSchemasListed(t *testing.T) {
+	dbConfig := &config.DatabaseConfig{Databases: &config.SchemaList{Names: []string{"shop", "blog"}}}
+	schemas, err := ServerSchemas(dbConfig, func() (backup.Introspector, error) {
+		t.Fatal("listed schemas should not connect")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop", "blog"}, schemas)
+}
+
+func TestServerSchemasAll(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.Databases = []string{"shop", "mysql", "information_schema", "blog", "performance_schema", "sys"}
+	require.NoError(t, client.Connect())
+
+	dbConfig := &config.DatabaseConfig{Databases: &config.SchemaList{All: true}}
+	schemas, err := ServerSchemas(dbConfig, func() (backup.Introspector, error) { return client, nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blog", "shop"}, schemas)
+	assert.False(t, client.IsConnected())
+
+	client = mysql.NewMockClient()
+	client.Databases = []string{"mysql", "sys"}
+	require.NoError(t, client.Connect())
+	_, err = ServerSchemas(dbConfig, func() (backup.Introspector, error) { return client, nil })
+	assert.Error(t, err)
+
+	_, err = ServerSchemas(dbConfig, func() (backup.Introspector, error) { return nil, errors.New("refused") })
+	assert.EqualError(t, err, "refused")
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	assert.Equal(t, &backup.RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12},
+		RetentionPolicy(&config.RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12}))
+	assert.True(t, RetentionPolicy(&config.RetentionPolicy{KeepAll: true}).KeepAll)
+}
+
+func TestQuotaPolicy(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Databases["shop"] = &config.DatabaseConfig{}
+
+	t.Run("no quota", func(t *testing.T) {
+		policy, err := QuotaPolicy(cfg, "shop")
+		require.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+
+	t.Run("alert quota", func(t *testing.T) {
+		cfg.Defaults.Quota = &config.QuotaConfig{MaxSize: "1GB"}
+		defer func() { cfg.Defaults.Quota = nil }()
+
+		policy, err := QuotaPolicy(cfg, "shop")
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, int64(1<<30), policy.Limits.DatabaseLimit)
+		assert.Zero(t, policy.Prune.DatabaseLimit)
+	})
+
+	t.Run("prune quotas", func(t *testing.T) {
+		cfg.Databases["shop"].Quota = &config.QuotaConfig{MaxSize: "1GB", Action: config.QuotaActionPrune, MinKeep: 2}
+		cfg.Storage = &config.StorageConfig{Quota: &config.QuotaConfig{MaxSize: "10GB", Action: config.QuotaActionPrune, MinKeep: 5}}
+		defer func() {
+			cfg.Databases["shop"].Quota = nil
+			cfg.Storage = nil
+		}()
+
+		policy, err := QuotaPolicy(cfg, "shop")
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, int64(1<<30), policy.Prune.DatabaseLimit)
+		assert.Equal(t, int64(10<<30), policy.Prune.TotalLimit)
+		assert.Equal(t, 5, policy.MinKeep)
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		cfg.Databases["shop"].Quota = &config.QuotaConfig{MaxSize: "lots"}
+		defer func() { cfg.Databases["shop"].Quota = nil }()
+
+		_, err := QuotaPolicy(cfg, "shop")
+		assert.Error(t, err)
+	})
+}
+
+func TestWarningPolicy(t *testing.T) {
+	policy, err := WarningPolicy(nil)
+	require.NoError(t, err)
+	assert.Equal(t, backup.WarningActionFail, policy.Action)
+
+	policy, err = WarningPolicy(&config.MysqldumpConfig{
+		Warnings: &config.DumpWarningsConfig{Action: backup.WarningActionWarn},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, backup.WarningActionWarn, policy.Action)
+
+	_, err = WarningPolicy(&config.MysqldumpConfig{
+		Warnings: &config.DumpWarningsConfig{Action: "retry"},
+	})
+	assert.True(t, backup.IsValidationError(err))
+
+	_, err = WarningPolicy(&config.MysqldumpConfig{
+		Warnings: &config.DumpWarningsConfig{Allow: []string{"("}},
+	})
+	assert.True(t, backup.IsValidationError(err))
+}
+
+func TestLongQueryPolicy(t *testing.T) {
+	policy, err := LongQueryPolicy(nil)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = LongQueryPolicy(&config.LongQueryConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, backup.DefaultLongQueryThreshold, policy.Threshold)
+
+	policy, err = LongQueryPolicy(&config.LongQueryConfig{Threshold: "5m", Action: "kill"})
+	require.NoError(t, err)
+	assert.Equal(t, &backup.LongQueryPolicy{Threshold: 5 * time.Minute, Action: backup.LongQueryActionKill}, policy)
+
+	_, err = LongQueryPolicy(&config.LongQueryConfig{Threshold: "later"})
+	assert.True(t, backup.IsValidationError(err))
+	_, err = LongQueryPolicy(&config.LongQueryConfig{Action: "abort"})
+	assert.True(t, backup.IsValidationError(err))
+}
+
+func TestLockMonitorPolicy(t *testing.T) {
+	policy, err := LockMonitorPolicy(nil)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = LockMonitorPolicy(&config.LockMonitorConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, &backup.LockMonitorPolicy{Threshold: backup.DefaultLockThreshold, Interval: backup.DefaultLockInterval}, policy)
+
+	policy, err = LockMonitorPolicy(&config.LockMonitorConfig{Threshold: "2m", Interval: "10s", Action: "abort"})
+	require.NoError(t, err)
+	assert.Equal(t, &backup.LockMonitorPolicy{Threshold: 2 * time.Minute, Interval: 10 * time.Second, Action: backup.LockActionAbort}, policy)
+
+	_, err = LockMonitorPolicy(&config.LockMonitorConfig{Interval: "often"})
+	assert.True(t, backup.IsValidationError(err))
+	_, err = LockMonitorPolicy(&config.LockMonitorConfig{Action: "kill"})
+	assert.True(t, backup.IsValidationError(err))
+}
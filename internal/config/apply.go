@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of applying a databases file
+const (
+	ApplyCreate    = "create"
+	ApplyUpdate    = "update"
+	ApplyUnchanged = "unchanged"
+	ApplyRemove    = "remove"
+)
+
+// ApplyChange is what applying a databases file does to one entry.
+type ApplyChange struct {
+	Name   string
+	Action string
+}
+
+// DatabasesFile is a file of database entries reconciled into the config
+// by 'cadangkan apply'. Entries have the same fields as in config.yaml.
+type DatabasesFile struct {
+	Databases map[string]*DatabaseConfig `yaml:"databases"`
+}
+
+// LoadDatabasesFile reads a databases file. Unknown fields are rejected, so
+// typos do not silently drop settings.
+func LoadDatabasesFile(path string) (map[string]*DatabaseConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read databases file: %w", err)
+	}
+
+	var file DatabasesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse databases file: %w", err)
+	}
+
+	for name, db := range file.Databases {
+		if db == nil {
+			return nil, &ValidationError{Field: name, Message: "database entry is empty"}
+		}
+		if SanitizeName(name) != name {
+			return nil, &ValidationError{Field: name, Message: fmt.Sprintf("invalid database name (use '%s')", SanitizeName(name))}
+		}
+		db.Name = name
+	}
+	return file.Databases, nil
+}
+
+// PlanApply returns what applying desired to the databases of c would do,
+// sorted by name. With prune, entries missing from desired are removed;
+// otherwise they are left alone.
+func (c *Config) PlanApply(desired map[string]*DatabaseConfig, prune bool) []ApplyChange {
+	var changes []ApplyChange
+	for name, db := range desired {
+		current, exists := c.Databases[name]
+		switch {
+		case !exists:
+			changes = append(changes, ApplyChange{Name: name, Action: ApplyCreate})
+		case SameDatabase(current, db):
+			changes = append(changes, ApplyChange{Name: name, Action: ApplyUnchanged})
+		default:
+			changes = append(changes, ApplyChange{Name: name, Action: ApplyUpdate})
+		}
+	}
+	if prune {
+		for name := range c.Databases {
+			if _, wanted := desired[name]; !wanted {
+				changes = append(changes, ApplyChange{Name: name, Action: ApplyRemove})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// Apply makes the changes of PlanApply to c and returns them.
+func (c *Config) Apply(desired map[string]*DatabaseConfig, prune bool) []ApplyChange {
+	changes := c.PlanApply(desired, prune)
+	if c.Databases == nil {
+		c.Databases = make(map[string]*DatabaseConfig)
+	}
+	for _, change := range changes {
+		switch change.Action {
+		case ApplyCreate, ApplyUpdate:
+			c.Databases[change.Name] = desired[change.Name]
+		case ApplyRemove:
+			delete(c.Databases, change.Name)
+		}
+	}
+	return changes
+}
+
+// HasChanges reports whether any of changes modifies the config.
+func HasChanges(changes []ApplyChange) bool {
+	for _, change := range changes {
+		if change.Action != ApplyUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// SameDatabase reports whether two entries would be saved identically.
+func SameDatabase(a, b *DatabaseConfig) bool {
+	aYAML, aErr := yaml.Marshal(a)
+	bYAML, bErr := yaml.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aYAML, bYAML)
+}
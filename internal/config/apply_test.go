@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testDatabase(host string) *DatabaseConfig {
+	return &DatabaseConfig{Type: "mysql", Host: host, Port: 3306, User: "backup", Database: "shop", PasswordEnv: "SHOP_PASSWORD"}
+}
+
+func TestPlanApply(t *testing.T) {
+	cfg := &Config{Databases: map[string]*DatabaseConfig{
+		"shop":    testDatabase("db1"),
+		"blog":    testDatabase("db1"),
+		"archive": testDatabase("db1"),
+	}}
+	desired := map[string]*DatabaseConfig{
+		"shop": testDatabase("db1"),
+		"blog": testDatabase("db2"),
+		"wiki": testDatabase("db1"),
+	}
+
+	tests := []struct {
+		name  string
+		prune bool
+		want  []ApplyChange
+	}{
+		{"keep others", false, []ApplyChange{
+			{Name: "blog", Action: ApplyUpdate},
+			{Name: "shop", Action: ApplyUnchanged},
+			{Name: "wiki", Action: ApplyCreate},
+		}},
+		{"prune", true, []ApplyChange{
+			{Name: "archive", Action: ApplyRemove},
+			{Name: "blog", Action: ApplyUpdate},
+			{Name: "shop", Action: ApplyUnchanged},
+			{Name: "wiki", Action: ApplyCreate},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.PlanApply(desired, tt.prune)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanApply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	cfg := &Config{Databases: map[string]*DatabaseConfig{"archive": testDatabase("db1")}}
+	desired := map[string]*DatabaseConfig{"shop": testDatabase("db1")}
+
+	changes := cfg.Apply(desired, true)
+	if !HasChanges(changes) {
+		t.Fatalf("first Apply() = %v, want changes", changes)
+	}
+	if _, exists := cfg.Databases["archive"]; exists {
+		t.Error("archive was not removed")
+	}
+	if cfg.Databases["shop"] == nil {
+		t.Error("shop was not created")
+	}
+
+	if changes := cfg.Apply(desired, true); HasChanges(changes) {
+		t.Errorf("second Apply() = %v, want no changes", changes)
+	}
+}
+
+func TestLoadDatabasesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", "databases:\n  shop:\n    type: mysql\n    host: db1\n    port: 3306\n    user: backup\n    database: shop\n", false},
+		{"unknown field", "databases:\n  shop:\n    type: mysql\n    hots: db1\n", true},
+		{"invalid name", "databases:\n  My Shop:\n    type: mysql\n", true},
+		{"empty entry", "databases:\n  shop:\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "databases.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			databases, err := LoadDatabasesFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadDatabasesFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && databases["shop"].Name != "shop" {
+				t.Errorf("Name = %q, want shop", databases["shop"].Name)
+			}
+		})
+	}
+}
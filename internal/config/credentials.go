@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Password returns the database password from its password_file, its
+// password_env variable, or password_encrypted, in that order. A database
+// with none of them has an empty password.
+func (d *DatabaseConfig) Password() (string, error) {
+	switch {
+	case d.PasswordFile != "":
+		return ReadPasswordFile(d.PasswordFile)
+	case d.PasswordEnv != "":
+		password, ok := os.LookupEnv(d.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %s is not set", d.PasswordEnv)
+		}
+		return password, nil
+	case d.PasswordEncrypted != "":
+		password, err := DecryptPassword(d.PasswordEncrypted)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt password: %w", err)
+		}
+		return password, nil
+	}
+	return "", nil
+}
+
+// ReadPasswordFile reads a password from a file such as a mounted
+// Kubernetes secret. A trailing newline is not part of the password.
+func ReadPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
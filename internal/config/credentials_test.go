@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabasePassword(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CADANGKAN_TEST_PASSWORD", "from-env")
+
+	tests := []struct {
+		name    string
+		db      *DatabaseConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "no password", db: &DatabaseConfig{}, want: ""},
+		{name: "password file", db: &DatabaseConfig{PasswordFile: path}, want: "s3cret"},
+		{name: "password env", db: &DatabaseConfig{PasswordEnv: "CADANGKAN_TEST_PASSWORD"}, want: "from-env"},
+		{name: "file before env", db: &DatabaseConfig{PasswordFile: path, PasswordEnv: "CADANGKAN_TEST_PASSWORD"}, want: "s3cret"},
+		{name: "missing file", db: &DatabaseConfig{PasswordFile: filepath.Join(dir, "missing")}, wantErr: true},
+		{name: "unset env", db: &DatabaseConfig{PasswordEnv: "CADANGKAN_TEST_UNSET"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.db.Password()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Password() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Password() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
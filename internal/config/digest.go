@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Default digest schedules, at 08:00 server time.
+const (
+	DefaultDailyDigestCron  = "0 8 * * *"
+	DefaultWeeklyDigestCron = "0 8 * * 1"
+)
+
+// DefaultSMTPPort is the mail submission port, used when none is configured.
+const DefaultSMTPPort = 587
+
+// Window returns how far back a digest looks: a day, or a week when the
+// period is weekly.
+func (d *DigestConfig) Window() time.Duration {
+	if d.Period == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Schedule returns the cron expression of when the digest is sent.
+func (d *DigestConfig) Schedule() string {
+	switch {
+	case d.Cron != "":
+		return d.Cron
+	case d.Period == DigestWeekly:
+		return DefaultWeeklyDigestCron
+	default:
+		return DefaultDailyDigestCron
+	}
+}
+
+// Address returns the host:port of the mail server.
+func (s *SMTPConfig) Address() string {
+	port := s.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+	return net.JoinHostPort(s.Host, strconv.Itoa(port))
+}
+
+// Password returns the mail server password from its password_file or its
+// password_env variable, in that order, or an empty password when neither
+// is set.
+func (s *SMTPConfig) Password() (string, error) {
+	switch {
+	case s.PasswordFile != "":
+		return ReadPasswordFile(s.PasswordFile)
+	case s.PasswordEnv != "":
+		password, ok := os.LookupEnv(s.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %s is not set", s.PasswordEnv)
+		}
+		return password, nil
+	}
+	return "", nil
+}
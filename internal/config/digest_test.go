@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDigestSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		digest     *DigestConfig
+		wantCron   string
+		wantWindow time.Duration
+	}{
+		{"daily by default", &DigestConfig{}, DefaultDailyDigestCron, 24 * time.Hour},
+		{"weekly", &DigestConfig{Period: DigestWeekly}, DefaultWeeklyDigestCron, 7 * 24 * time.Hour},
+		{"custom cron", &DigestConfig{Period: DigestWeekly, Cron: "30 6 * * 5"}, "30 6 * * 5", 7 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.digest.Schedule(); got != tt.wantCron {
+				t.Errorf("Schedule() = %q, want %q", got, tt.wantCron)
+			}
+			if got := tt.digest.Window(); got != tt.wantWindow {
+				t.Errorf("Window() = %s, want %s", got, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestDigestValidate(t *testing.T) {
+	smtp := &SMTPConfig{Host: "smtp.example.com", From: "cadangkan@example.com"}
+	tests := []struct {
+		name    string
+		digest  *DigestConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &DigestConfig{Recipients: []string{"ops@example.com"}, SMTP: smtp}, false},
+		{"invalid period", &DigestConfig{Period: "hourly", Recipients: []string{"ops@example.com"}, SMTP: smtp}, true},
+		{"no recipients", &DigestConfig{SMTP: smtp}, true},
+		{"no smtp", &DigestConfig{Recipients: []string{"ops@example.com"}}, true},
+		{"no sender", &DigestConfig{Recipients: []string{"ops@example.com"}, SMTP: &SMTPConfig{Host: "smtp.example.com"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.digest.Validate("digest")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMTPAddress(t *testing.T) {
+	if got := (&SMTPConfig{Host: "smtp.example.com"}).Address(); got != "smtp.example.com:587" {
+		t.Errorf("Address() = %q, want default port", got)
+	}
+	if got := (&SMTPConfig{Host: "smtp.example.com", Port: 25}).Address(); got != "smtp.example.com:25" {
+		t.Errorf("Address() = %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"26h", 26 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"2d", 48 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{" 1h30m ", 90 * time.Minute, false},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"0h", 0, true},
+		{"-2d", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveFreshness(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["prod"] = &DatabaseConfig{Freshness: "26h"}
+	cfg.Databases["staging"] = &DatabaseConfig{}
+
+	if sla, err := cfg.GetEffectiveFreshness("staging"); err != nil || sla != 0 {
+		t.Errorf("expected no SLA without defaults, got %v, %v", sla, err)
+	}
+
+	cfg.Defaults.Freshness = "2d"
+	if sla, _ := cfg.GetEffectiveFreshness("staging"); sla != 48*time.Hour {
+		t.Errorf("expected default SLA, got %v", sla)
+	}
+	if sla, _ := cfg.GetEffectiveFreshness("prod"); sla != 26*time.Hour {
+		t.Errorf("expected database SLA, got %v", sla)
+	}
+}
+
+func TestConnectRetry(t *testing.T) {
+	var d *DaemonConfig
+	attempts, backoff, err := d.ConnectRetry()
+	if err != nil || attempts != DefaultConnectAttempts || backoff != DefaultConnectBackoff {
+		t.Errorf("nil daemon config = %d, %s, %v", attempts, backoff, err)
+	}
+
+	d = &DaemonConfig{ConnectAttempts: 5, ConnectBackoff: "10s"}
+	attempts, backoff, err = d.ConnectRetry()
+	if err != nil || attempts != 5 || backoff != 10*time.Second {
+		t.Errorf("configured = %d, %s, %v", attempts, backoff, err)
+	}
+
+	d = &DaemonConfig{ConnectBackoff: "soon"}
+	if _, _, err := d.ConnectRetry(); err == nil {
+		t.Error("expected an error for an invalid backoff")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/erickhilda/cadangkan/internal/paths"
 )
 
 const (
@@ -106,13 +108,16 @@ func (e *encryptor) decrypt(encoded string) (string, error) {
 
 // loadOrGenerateKey loads the encryption key from disk or generates a new one.
 func loadOrGenerateKey() ([]byte, error) {
-	keyPath, err := getKeyPath()
+	keyPath, err := KeyPath()
 	if err != nil {
 		return nil, err
 	}
 
-	// Try to load existing key
-	if _, err := os.Stat(keyPath); err == nil {
+	// Try to load existing key, refusing one other users can read
+	if info, err := os.Stat(keyPath); err == nil {
+		if err := CheckKeyMode(keyPath, info.Mode()); err != nil {
+			return nil, err
+		}
 		return os.ReadFile(keyPath)
 	}
 
@@ -135,11 +140,16 @@ func loadOrGenerateKey() ([]byte, error) {
 	return key, nil
 }
 
-// getKeyPath returns the path to the encryption key file.
-func getKeyPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+// CheckKeyMode returns an error if the encryption key at path, with file
+// mode mode, is accessible by users other than its owner and group.
+func CheckKeyMode(path string, mode os.FileMode) error {
+	if mode.Perm()&0007 != 0 {
+		return fmt.Errorf("encryption key %s is accessible by other users (mode %04o); restrict it with: chmod 600 %s", path, mode.Perm(), path)
 	}
-	return filepath.Join(homeDir, ".cadangkan", ".key"), nil
+	return nil
+}
+
+// KeyPath returns the path to the encryption key file.
+func KeyPath() (string, error) {
+	return paths.ConfigFile(".key")
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"testing"
 )
 
@@ -183,3 +184,16 @@ func TestEncryptorWithDifferentKeys(t *testing.T) {
 		t.Error("decrypt() with wrong key should fail")
 	}
 }
+
+func TestCheckKeyMode(t *testing.T) {
+	for _, mode := range []os.FileMode{0600, 0400, 0640} {
+		if err := CheckKeyMode("/tmp/.key", mode); err != nil {
+			t.Errorf("CheckKeyMode(%04o) = %v, want nil", mode, err)
+		}
+	}
+	for _, mode := range []os.FileMode{0644, 0604, 0666, 0601} {
+		if err := CheckKeyMode("/tmp/.key", mode); err == nil {
+			t.Errorf("CheckKeyMode(%04o) = nil, want error", mode)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables overriding config.yaml, so containers can be
+// configured without a config volume. The *_JSON variables hold the same
+// keys as the matching config.yaml section.
+const (
+	EnvStorageURL    = "CADANGKAN_STORAGE_URL"    // Backup directory, as a path or file:// URL
+	EnvStorageLayout = "CADANGKAN_STORAGE_LAYOUT" // storage.layout
+	EnvAuditPath     = "CADANGKAN_AUDIT_PATH"     // audit.path
+	EnvDefaultsJSON  = "CADANGKAN_DEFAULTS_JSON"  // defaults section
+	EnvDaemonJSON    = "CADANGKAN_DAEMON_JSON"    // daemon section
+	EnvDatabasesJSON = "CADANGKAN_DATABASES_JSON" // databases by name, replacing those of the same name
+)
+
+// envVariables lists the variables ApplyEnv reads, in the order applied.
+var envVariables = []string{
+	EnvStorageURL,
+	EnvStorageLayout,
+	EnvAuditPath,
+	EnvDefaultsJSON,
+	EnvDaemonJSON,
+	EnvDatabasesJSON,
+}
+
+// EnvOverrides returns the configuration variables set in the environment.
+func EnvOverrides() []string {
+	var set []string
+	for _, name := range envVariables {
+		if os.Getenv(name) != "" {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// ApplyEnv overrides cfg with the configuration variables set in the
+// environment.
+func ApplyEnv(cfg *Config) error {
+	if value := os.Getenv(EnvStorageURL); value != "" {
+		path, err := storagePathFromURL(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvStorageURL, err)
+		}
+		if cfg.Storage == nil {
+			cfg.Storage = &StorageConfig{}
+		}
+		cfg.Storage.Path = path
+	}
+	if value := os.Getenv(EnvStorageLayout); value != "" {
+		if cfg.Storage == nil {
+			cfg.Storage = &StorageConfig{}
+		}
+		cfg.Storage.Layout = value
+	}
+	if value := os.Getenv(EnvAuditPath); value != "" {
+		if cfg.Audit == nil {
+			cfg.Audit = &AuditConfig{}
+		}
+		cfg.Audit.Path = value
+	}
+	if value := os.Getenv(EnvDefaultsJSON); value != "" {
+		var defaults Defaults
+		if err := yaml.Unmarshal([]byte(value), &defaults); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", EnvDefaultsJSON, err)
+		}
+		cfg.Defaults = &defaults
+	}
+	if value := os.Getenv(EnvDaemonJSON); value != "" {
+		var daemon DaemonConfig
+		if err := yaml.Unmarshal([]byte(value), &daemon); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", EnvDaemonJSON, err)
+		}
+		cfg.Daemon = &daemon
+	}
+	if value := os.Getenv(EnvDatabasesJSON); value != "" {
+		var databases map[string]*DatabaseConfig
+		if err := yaml.Unmarshal([]byte(value), &databases); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", EnvDatabasesJSON, err)
+		}
+		if cfg.Databases == nil {
+			cfg.Databases = make(map[string]*DatabaseConfig)
+		}
+		for name, db := range databases {
+			if db == nil {
+				return fmt.Errorf("%s: database %q is empty", EnvDatabasesJSON, name)
+			}
+			if db.Type == "" {
+				db.Type = "mysql"
+			}
+			if db.Port == 0 {
+				db.Port = 3306
+			}
+			db.Name = name
+			cfg.Databases[name] = db
+		}
+	}
+	return nil
+}
+
+// storagePathFromURL returns the directory of a storage URL. Only local
+// storage is supported: a path or a file:// URL.
+func storagePathFromURL(value string) (string, error) {
+	if !strings.Contains(value, "://") {
+		return value, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid storage URL: %w", err)
+	}
+	if u.Scheme != "file" || u.Path == "" {
+		return "", fmt.Errorf("unsupported storage URL %q: use a local path or a file:// URL", value)
+	}
+	return u.Path, nil
+}
+
+// envManager overlays the configuration variables of the environment on a
+// YAMLManager. The configuration is then read-only, as saving it would
+// copy the overrides into config.yaml.
+type envManager struct {
+	file *YAMLManager
+}
+
+// Ensure envManager implements the Manager interface.
+var _ Manager = (*envManager)(nil)
+
+// Load loads config.yaml, if any, and applies the environment.
+func (m *envManager) Load() (*Config, error) {
+	cfg, err := m.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyEnv(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Save refuses to write a configuration set by the environment.
+func (m *envManager) Save(*Config) error {
+	return fmt.Errorf("configuration is set by environment variables (%s); change them instead",
+		strings.Join(EnvOverrides(), ", "))
+}
+
+// GetDatabase retrieves a specific database configuration, resolving
+// "server.schema" names to the schemas of server entries.
+func (m *envManager) GetDatabase(name string) (*DatabaseConfig, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	db, exists := cfg.Database(name)
+	if !exists {
+		return nil, &DatabaseNotFoundError{Name: name}
+	}
+	return db, nil
+}
+
+// AddDatabase refuses to change a configuration set by the environment.
+func (m *envManager) AddDatabase(name string, db *DatabaseConfig) error {
+	return m.Save(nil)
+}
+
+// RemoveDatabase refuses to change a configuration set by the environment.
+func (m *envManager) RemoveDatabase(name string) error {
+	return m.Save(nil)
+}
+
+// ListDatabases returns a list of all configured database names.
+func (m *envManager) ListDatabases() ([]string, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(cfg.Databases))
+	for name := range cfg.Databases {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// DatabaseExists checks if a database configuration exists.
+func (m *envManager) DatabaseExists(name string) (bool, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return false, err
+	}
+
+	_, exists := cfg.Databases[name]
+	return exists, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv(EnvStorageURL, "file:///var/lib/cadangkan")
+	t.Setenv(EnvStorageLayout, "date")
+	t.Setenv(EnvDaemonJSON, `{"max_concurrent": 2}`)
+	t.Setenv(EnvDatabasesJSON, `{
+		"shop": {"host": "mysql.shop.svc", "user": "backup", "database": "shop",
+		         "password_env": "SHOP_PASSWORD", "schedule": {"enabled": true, "cron": "0 2 * * *"}}
+	}`)
+
+	cfg := NewConfig()
+	cfg.Databases["shop"] = &DatabaseConfig{Type: "mysql", Host: "old", Port: 3307}
+	cfg.Databases["legacy"] = &DatabaseConfig{Type: "mysql", Host: "legacy", Port: 3306, User: "backup", Database: "legacy"}
+
+	if err := ApplyEnv(cfg); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	if cfg.Storage.Path != "/var/lib/cadangkan" || cfg.Storage.Layout != "date" {
+		t.Errorf("unexpected storage: %+v", cfg.Storage)
+	}
+	if cfg.Daemon == nil || cfg.Daemon.MaxConcurrent != 2 {
+		t.Errorf("unexpected daemon: %+v", cfg.Daemon)
+	}
+
+	shop := cfg.Databases["shop"]
+	if shop.Host != "mysql.shop.svc" || shop.Port != 3306 || shop.Type != "mysql" || shop.Name != "shop" {
+		t.Errorf("unexpected shop database: %+v", shop)
+	}
+	if shop.PasswordEnv != "SHOP_PASSWORD" || shop.Schedule == nil || shop.Schedule.Cron != "0 2 * * *" {
+		t.Errorf("unexpected shop settings: %+v", shop)
+	}
+	if cfg.Databases["legacy"] == nil {
+		t.Error("databases from the file should be kept")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	t.Setenv(EnvStorageURL, "s3://bucket/backups")
+	if err := ApplyEnv(NewConfig()); err == nil {
+		t.Error("expected error for unsupported storage URL")
+	}
+
+	t.Setenv(EnvStorageURL, "/backups")
+	t.Setenv(EnvDatabasesJSON, `{"shop": [`)
+	if err := ApplyEnv(NewConfig()); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestEnvManager(t *testing.T) {
+	t.Setenv(EnvDatabasesJSON, `{"shop": {"host": "db", "user": "backup", "database": "shop"}}`)
+
+	mgr := &envManager{file: &YAMLManager{configPath: filepath.Join(t.TempDir(), "config.yaml")}}
+
+	db, err := mgr.GetDatabase("shop")
+	if err != nil {
+		t.Fatalf("GetDatabase() error = %v", err)
+	}
+	if db.Host != "db" {
+		t.Errorf("GetDatabase() host = %v, want db", db.Host)
+	}
+	if exists, _ := mgr.DatabaseExists("other"); exists {
+		t.Error("DatabaseExists() = true for an unknown database")
+	}
+	if err := mgr.AddDatabase("other", &DatabaseConfig{}); err == nil {
+		t.Error("AddDatabase() should fail when configured by the environment")
+	}
+}
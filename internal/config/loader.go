@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/erickhilda/cadangkan/internal/fsutil"
+	"github.com/erickhilda/cadangkan/internal/paths"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,7 +19,9 @@ type YAMLManager struct {
 // Ensure YAMLManager implements the Manager interface.
 var _ Manager = (*YAMLManager)(nil)
 
-// NewManager creates a new YAML-based config manager (default).
+// NewManager creates a new YAML-based config manager (default). When
+// configuration variables are set in the environment (see ApplyEnv), they
+// take precedence over the file and the configuration is read-only.
 // Future: Could support --backend=sqlite flag or env var.
 func NewManager() (Manager, error) {
 	configPath, err := GetConfigPath()
@@ -24,9 +29,20 @@ func NewManager() (Manager, error) {
 		return nil, err
 	}
 
-	return &YAMLManager{
+	return NewManagerAt(configPath), nil
+}
+
+// NewManagerAt creates a YAML-based config manager for the config file at
+// path. Configuration variables in the environment take precedence, as
+// with NewManager.
+func NewManagerAt(configPath string) Manager {
+	manager := &YAMLManager{
 		configPath: configPath,
-	}, nil
+	}
+	if len(EnvOverrides()) > 0 {
+		return &envManager{file: manager}
+	}
+	return manager
 }
 
 // Load loads the configuration from disk.
@@ -82,27 +98,26 @@ func (m *YAMLManager) Save(config *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	// Write to file with restricted permissions
-	if err := os.WriteFile(m.configPath, data, 0600); err != nil {
+	// Write to file atomically with restricted permissions
+	if err := fsutil.WriteFileAtomic(m.configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
-// GetDatabase retrieves a specific database configuration.
+// GetDatabase retrieves a specific database configuration, resolving
+// "server.schema" names to the schemas of server entries.
 func (m *YAMLManager) GetDatabase(name string) (*DatabaseConfig, error) {
 	config, err := m.Load()
 	if err != nil {
 		return nil, err
 	}
 
-	db, exists := config.Databases[name]
+	db, exists := config.Database(name)
 	if !exists {
 		return nil, &DatabaseNotFoundError{Name: name}
 	}
-
-	db.Name = name
 	return db, nil
 }
 
@@ -113,6 +128,14 @@ func (m *YAMLManager) AddDatabase(name string, db *DatabaseConfig) error {
 		return err
 	}
 
+	// Schemas of server entries are changed through their server entry
+	if _, exists := config.Databases[name]; !exists {
+		if _, isSchema := config.Database(name); isSchema {
+			server, _, _ := strings.Cut(name, ".")
+			return &ValidationError{Field: "name", Message: fmt.Sprintf("'%s' belongs to server entry '%s'; change '%s' instead", name, server, server)}
+		}
+	}
+
 	// Set the name
 	db.Name = name
 
@@ -187,19 +210,14 @@ func (m *YAMLManager) LoadConfig() (*Config, error) {
 
 // GetConfigPath returns the path to the config file.
 func GetConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-	return filepath.Join(homeDir, ".cadangkan", "config.yaml"), nil
+	return paths.ConfigFile("config.yaml")
 }
 
 // EnsureConfigDir ensures the config directory exists.
 func EnsureConfigDir() error {
-	homeDir, err := os.UserHomeDir()
+	configDir, err := paths.ConfigDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
-	configDir := filepath.Join(homeDir, ".cadangkan")
 	return os.MkdirAll(configDir, 0700)
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPluginTimeout is how long a plugin call may take when no timeout
+// is configured.
+const DefaultPluginTimeout = 5 * time.Minute
+
+// PluginHooks lists every hook, in the order they fire.
+var PluginHooks = []string{
+	HookBackupStarted,
+	HookBackupCompleted,
+	HookBackupFailed,
+	HookRestoreCompleted,
+	HookRestoreFailed,
+}
+
+// PluginTemplateFuncs are the functions available in the args and input
+// templates of plugins:
+//
+//	json  escapes a value for use inside a JSON string, e.g.
+//	      {"text": "Backup failed: {{json .Error}}"}
+var PluginTemplateFuncs = template.FuncMap{
+	"json": jsonEscape,
+}
+
+// jsonEscape returns value formatted as text and escaped as the contents of
+// a JSON string, without the surrounding quotes.
+func jsonEscape(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(fmt.Sprint(value)); err != nil {
+		return "", err
+	}
+	quoted := strings.TrimSuffix(buf.String(), "\n")
+	return quoted[1 : len(quoted)-1], nil
+}
+
+// TimeoutDuration returns how long one call of the plugin may take.
+func (p *PluginConfig) TimeoutDuration() (time.Duration, error) {
+	if p.Timeout == "" {
+		return DefaultPluginTimeout, nil
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", p.Timeout, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive")
+	}
+	return d, nil
+}
+
+// Receives reports whether the plugin is called on hook.
+func (p *PluginConfig) Receives(hook string) bool {
+	if len(p.Hooks) == 0 {
+		return true
+	}
+	for _, h := range p.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package config
+
+// DefaultScheduleName names the schedule configured under `schedule`, as
+// opposed to the named schedules under `schedules`.
+const DefaultScheduleName = "default"
+
+// ScheduleName returns the name of the schedule, DefaultScheduleName if it
+// has none.
+func (sc *ScheduleConfig) ScheduleName() string {
+	if sc == nil || sc.Name == "" {
+		return DefaultScheduleName
+	}
+	return sc.Name
+}
+
+// ScheduleLabel identifies a schedule in output: the database name for its
+// default schedule, "database/schedule" otherwise.
+func ScheduleLabel(dbName, scheduleName string) string {
+	if scheduleName == "" || scheduleName == DefaultScheduleName {
+		return dbName
+	}
+	return dbName + "/" + scheduleName
+}
+
+// AllSchedules returns the default schedule, if any, followed by the named
+// schedules of the database.
+func (d *DatabaseConfig) AllSchedules() []*ScheduleConfig {
+	var schedules []*ScheduleConfig
+	if d.Schedule != nil {
+		schedules = append(schedules, d.Schedule)
+	}
+	for _, sc := range d.Schedules {
+		if sc != nil {
+			schedules = append(schedules, sc)
+		}
+	}
+	return schedules
+}
+
+// FindSchedule returns the schedule called name, or nil if there is none.
+// An empty name selects the default schedule.
+func (d *DatabaseConfig) FindSchedule(name string) *ScheduleConfig {
+	if name == "" || name == DefaultScheduleName {
+		return d.Schedule
+	}
+	for _, sc := range d.Schedules {
+		if sc != nil && sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// EnsureSchedule returns the schedule called name, adding an empty one if
+// there is none. An empty name selects the default schedule.
+func (d *DatabaseConfig) EnsureSchedule(name string) *ScheduleConfig {
+	if sc := d.FindSchedule(name); sc != nil {
+		return sc
+	}
+
+	if name == "" || name == DefaultScheduleName {
+		d.Schedule = &ScheduleConfig{}
+		return d.Schedule
+	}
+	sc := &ScheduleConfig{Name: name}
+	d.Schedules = append(d.Schedules, sc)
+	return sc
+}
+
+// RemoveSchedule removes the schedule called name and reports whether it
+// existed. An empty name selects the default schedule.
+func (d *DatabaseConfig) RemoveSchedule(name string) bool {
+	if name == "" || name == DefaultScheduleName {
+		existed := d.Schedule != nil
+		d.Schedule = nil
+		return existed
+	}
+	for i, sc := range d.Schedules {
+		if sc != nil && sc.Name == name {
+			d.Schedules = append(d.Schedules[:i], d.Schedules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package config
+
+import "testing"
+
+func TestDatabaseSchedules(t *testing.T) {
+	db := &DatabaseConfig{}
+
+	if got := db.AllSchedules(); len(got) != 0 {
+		t.Fatalf("expected no schedules, got %d", len(got))
+	}
+
+	nightly := db.EnsureSchedule("")
+	nightly.Cron = "0 2 * * *"
+	hourly := db.EnsureSchedule("hourly")
+	hourly.Cron = "0 * * * *"
+
+	if db.Schedule != nightly {
+		t.Error("empty name should select the default schedule")
+	}
+	if db.EnsureSchedule("hourly") != hourly {
+		t.Error("EnsureSchedule should return the existing schedule")
+	}
+	if db.FindSchedule(DefaultScheduleName) != nightly {
+		t.Error("default name should select the default schedule")
+	}
+
+	all := db.AllSchedules()
+	if len(all) != 2 || all[0] != nightly || all[1] != hourly {
+		t.Fatalf("unexpected schedules: %+v", all)
+	}
+	if all[0].ScheduleName() != DefaultScheduleName || all[1].ScheduleName() != "hourly" {
+		t.Errorf("unexpected names: %s, %s", all[0].ScheduleName(), all[1].ScheduleName())
+	}
+
+	if !db.RemoveSchedule("hourly") || db.FindSchedule("hourly") != nil {
+		t.Error("hourly schedule should be removed")
+	}
+	if db.RemoveSchedule("hourly") {
+		t.Error("removing a missing schedule should report false")
+	}
+	if !db.RemoveSchedule("") || db.Schedule != nil {
+		t.Error("default schedule should be removed")
+	}
+}
+
+func TestScheduleLabel(t *testing.T) {
+	if got := ScheduleLabel("production", DefaultScheduleName); got != "production" {
+		t.Errorf("got %q", got)
+	}
+	if got := ScheduleLabel("production", "hourly"); got != "production/hourly" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNamedSchedulesValidate(t *testing.T) {
+	base := func(schedules ...*ScheduleConfig) *DatabaseConfig {
+		return &DatabaseConfig{
+			Type:      "mysql",
+			Host:      "localhost",
+			Port:      3306,
+			Database:  "testdb",
+			User:      "testuser",
+			Schedules: schedules,
+		}
+	}
+
+	if err := base(&ScheduleConfig{Name: "hourly", Cron: "0 * * * *"}, &ScheduleConfig{Name: "weekly", Cron: "0 3 * * 0"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := base(&ScheduleConfig{Cron: "0 * * * *"}).Validate(); err == nil {
+		t.Error("expected error for unnamed schedule")
+	}
+	if err := base(&ScheduleConfig{Name: "a"}, &ScheduleConfig{Name: "a"}).Validate(); err == nil {
+		t.Error("expected error for duplicate names")
+	}
+	if err := base(&ScheduleConfig{Name: DefaultScheduleName}).Validate(); err == nil {
+		t.Error("expected error for reserved name")
+	}
+	if err := base(&ScheduleConfig{Name: "a", Compression: "lz4"}).Validate(); err == nil {
+		t.Error("expected error for invalid compression")
+	}
+}
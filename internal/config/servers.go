@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AllSchemas is the `databases` value selecting every schema on a server.
+const AllSchemas = "*"
+
+// SchemaList is the `databases` of a server entry: either a list of schema
+// names or AllSchemas.
+type SchemaList struct {
+	All   bool
+	Names []string
+}
+
+// UnmarshalYAML accepts a list of names or the string "*".
+func (l *SchemaList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value != AllSchemas {
+			return fmt.Errorf("line %d: databases must be a list or %q, got %q", node.Line, AllSchemas, node.Value)
+		}
+		*l = SchemaList{All: true}
+		return nil
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+		*l = SchemaList{Names: names}
+		return nil
+	}
+	return fmt.Errorf("line %d: databases must be a list or %q", node.Line, AllSchemas)
+}
+
+// MarshalYAML writes "*" for all schemas and the list of names otherwise.
+func (l SchemaList) MarshalYAML() (interface{}, error) {
+	if l.All {
+		return AllSchemas, nil
+	}
+	return l.Names, nil
+}
+
+// String returns "*" or the comma-separated names.
+func (l SchemaList) String() string {
+	if l.All {
+		return AllSchemas
+	}
+	return strings.Join(l.Names, ", ")
+}
+
+// Contains reports whether the list selects schema.
+func (l *SchemaList) Contains(schema string) bool {
+	if l.All {
+		return schema != ""
+	}
+	for _, name := range l.Names {
+		if name == schema {
+			return true
+		}
+	}
+	return false
+}
+
+// IsServer reports whether the entry is a server entry, backing up the
+// schemas under `databases` rather than a single database.
+func (d *DatabaseConfig) IsServer() bool {
+	return d != nil && d.Databases != nil
+}
+
+// SchemaName returns the name of a schema of a server entry, e.g.
+// "production.shop". Config names never contain dots, so these names cannot
+// collide with configured ones.
+func SchemaName(server, schema string) string {
+	return server + "." + schema
+}
+
+// ForSchema returns the configuration of one schema of a server entry: a
+// copy of the entry with the schema as its database, named after it.
+func (d *DatabaseConfig) ForSchema(schema string) *DatabaseConfig {
+	db := *d
+	db.Name = SchemaName(d.Name, schema)
+	db.Database = schema
+	db.Databases = nil
+	return &db
+}
+
+// Database returns the configuration of a database by name. Besides the
+// configured names, it resolves "server.schema" names to the schemas of
+// server entries.
+func (c *Config) Database(name string) (*DatabaseConfig, bool) {
+	if db, exists := c.Databases[name]; exists {
+		db.Name = name
+		return db, true
+	}
+	server, schema, found := strings.Cut(name, ".")
+	if !found {
+		return nil, false
+	}
+	db, exists := c.Databases[server]
+	if !exists || !db.IsServer() || !db.Databases.Contains(schema) {
+		return nil, false
+	}
+	db.Name = server
+	return db.ForSchema(schema), true
+}
+
+// BackupNames returns the sorted names the backups of the configured
+// databases are stored under: the configured names, with each server entry
+// replaced by the names of its schemas. The schemas of a "*" entry are only
+// known from its backups, so stored lists the names found in storage.
+func (c *Config) BackupNames(stored []string) []string {
+	seen := make(map[string]bool)
+	for name, db := range c.Databases {
+		if !db.IsServer() {
+			seen[name] = true
+			continue
+		}
+		for _, schema := range db.Databases.Names {
+			seen[SchemaName(name, schema)] = true
+		}
+	}
+	for _, name := range stored {
+		server, schema, found := strings.Cut(name, ".")
+		if db := c.Databases[server]; found && db.IsServer() && db.Databases.All && schema != "" {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
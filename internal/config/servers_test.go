@@ -0,0 +1,230 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchemaListYAML(t *testing.T) {
+	var db DatabaseConfig
+	if err := yaml.Unmarshal([]byte("databases: [shop, blog]\n"), &db); err != nil {
+		t.Fatalf("unmarshal list: %v", err)
+	}
+	if !db.IsServer() || db.Databases.All || !reflect.DeepEqual(db.Databases.Names, []string{"shop", "blog"}) {
+		t.Errorf("unexpected list: %+v", db.Databases)
+	}
+
+	db = DatabaseConfig{}
+	if err := yaml.Unmarshal([]byte("databases: \"*\"\n"), &db); err != nil {
+		t.Fatalf("unmarshal all: %v", err)
+	}
+	if !db.IsServer() || !db.Databases.All {
+		t.Errorf("expected all schemas, got %+v", db.Databases)
+	}
+
+	if err := yaml.Unmarshal([]byte("databases: shop\n"), &DatabaseConfig{}); err == nil {
+		t.Error("expected an error for a single name")
+	}
+	if err := yaml.Unmarshal([]byte("databases: {shop: true}\n"), &DatabaseConfig{}); err == nil {
+		t.Error("expected an error for a mapping")
+	}
+
+	db = DatabaseConfig{}
+	if err := yaml.Unmarshal([]byte("database: shop\n"), &db); err != nil || db.IsServer() {
+		t.Errorf("a database entry should not be a server entry: %v", err)
+	}
+
+	for _, list := range []SchemaList{{All: true}, {Names: []string{"shop", "blog"}}} {
+		data, err := yaml.Marshal(&DatabaseConfig{Databases: &list})
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if strings.Contains(string(data), "database:") {
+			t.Errorf("server entry should not write database: %s", data)
+		}
+		var decoded DatabaseConfig
+		if err := yaml.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("round trip: %v", err)
+		}
+		if !reflect.DeepEqual(*decoded.Databases, list) {
+			t.Errorf("round trip = %+v, want %+v", decoded.Databases, list)
+		}
+	}
+}
+
+func TestConfigDatabase(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["shop"] = &DatabaseConfig{Host: "db1", Database: "shop"}
+	cfg.Databases["production"] = &DatabaseConfig{Host: "db2", Retention: &RetentionPolicy{Daily: 3},
+		Databases: &SchemaList{Names: []string{"shop", "blog"}}}
+	cfg.Databases["staging"] = &DatabaseConfig{Host: "db3", Databases: &SchemaList{All: true}}
+
+	db, ok := cfg.Database("shop")
+	if !ok || db.Name != "shop" || db.Host != "db1" {
+		t.Errorf("Database(shop) = %+v, %v", db, ok)
+	}
+
+	db, ok = cfg.Database("production.blog")
+	if !ok || db.Name != "production.blog" || db.Database != "blog" || db.Host != "db2" || db.IsServer() {
+		t.Errorf("Database(production.blog) = %+v, %v", db, ok)
+	}
+	if cfg.Databases["production"].Database != "" || !cfg.Databases["production"].IsServer() {
+		t.Error("resolving a schema should not change the server entry")
+	}
+	if got := cfg.GetEffectiveRetention("production.blog"); got.Daily != 3 {
+		t.Errorf("schema should inherit the server retention, got %+v", got)
+	}
+
+	if db, ok = cfg.Database("staging.anything"); !ok || db.Database != "anything" {
+		t.Errorf("Database(staging.anything) = %+v, %v", db, ok)
+	}
+
+	for _, name := range []string{"production.wiki", "shop.orders", "staging.", "missing"} {
+		if _, ok := cfg.Database(name); ok {
+			t.Errorf("Database(%q) should not resolve", name)
+		}
+	}
+}
+
+func TestGetEffectiveStoragePath(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["shop"] = &DatabaseConfig{Database: "shop", StoragePath: "/srv/shop-backups"}
+	cfg.Databases["production"] = &DatabaseConfig{StoragePath: "/srv/production",
+		Databases: &SchemaList{Names: []string{"blog"}}}
+	cfg.Databases["staging"] = &DatabaseConfig{Database: "staging"}
+
+	if got := cfg.GetEffectiveStoragePath("staging"); got != "" {
+		t.Errorf("expected default path without storage.path, got %q", got)
+	}
+
+	cfg.Storage = &StorageConfig{Path: "/var/lib/cadangkan"}
+	tests := map[string]string{
+		"staging":         "/var/lib/cadangkan",
+		"shop":            "/srv/shop-backups",
+		"production.blog": "/srv/production",
+		"missing":         "/var/lib/cadangkan",
+	}
+	for name, want := range tests {
+		if got := cfg.GetEffectiveStoragePath(name); got != want {
+			t.Errorf("GetEffectiveStoragePath(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	cfg.Databases["orders"] = &DatabaseConfig{Database: "orders", StoragePath: "/srv/shop-backups"}
+	if got := cfg.StoragePaths(); !reflect.DeepEqual(got, []string{"/srv/production", "/srv/shop-backups"}) {
+		t.Errorf("StoragePaths() = %v", got)
+	}
+}
+
+func TestGetEffectiveClientPaths(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["legacy"] = &DatabaseConfig{Database: "legacy",
+		MysqldumpPath: "/opt/mysql-5.7/bin/mysqldump", MysqlPath: "/opt/mysql-5.7/bin/mysql"}
+	cfg.Databases["production"] = &DatabaseConfig{MysqldumpPath: "/opt/mysql-8.4/bin/mysqldump",
+		Databases: &SchemaList{Names: []string{"blog"}}}
+	cfg.Databases["staging"] = &DatabaseConfig{Database: "staging"}
+
+	if got := cfg.GetEffectiveMysqldumpPath("staging"); got != "" {
+		t.Errorf("expected mysqldump in PATH without defaults, got %q", got)
+	}
+
+	cfg.Defaults = &Defaults{MysqldumpPath: "/usr/local/bin/mysqldump", MysqlPath: "/usr/local/bin/mysql"}
+	tests := map[string][2]string{
+		"staging":         {"/usr/local/bin/mysqldump", "/usr/local/bin/mysql"},
+		"legacy":          {"/opt/mysql-5.7/bin/mysqldump", "/opt/mysql-5.7/bin/mysql"},
+		"production.blog": {"/opt/mysql-8.4/bin/mysqldump", "/usr/local/bin/mysql"},
+	}
+	for name, want := range tests {
+		if got := cfg.GetEffectiveMysqldumpPath(name); got != want[0] {
+			t.Errorf("GetEffectiveMysqldumpPath(%q) = %q, want %q", name, got, want[0])
+		}
+		if got := cfg.GetEffectiveMysqlPath(name); got != want[1] {
+			t.Errorf("GetEffectiveMysqlPath(%q) = %q, want %q", name, got, want[1])
+		}
+	}
+}
+
+func TestGetEffectiveVersionCheck(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["legacy"] = &DatabaseConfig{Database: "legacy", VersionCheck: "ignore"}
+	cfg.Databases["staging"] = &DatabaseConfig{Database: "staging"}
+
+	if got := cfg.GetEffectiveVersionCheck("staging"); got != "" {
+		t.Errorf("expected default version check, got %q", got)
+	}
+	cfg.Defaults = &Defaults{VersionCheck: "fail"}
+	if got := cfg.GetEffectiveVersionCheck("staging"); got != "fail" {
+		t.Errorf("GetEffectiveVersionCheck(staging) = %q, want fail", got)
+	}
+	if got := cfg.GetEffectiveVersionCheck("legacy"); got != "ignore" {
+		t.Errorf("GetEffectiveVersionCheck(legacy) = %q, want ignore", got)
+	}
+}
+
+func TestConfigBackupNames(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["shop"] = &DatabaseConfig{Database: "shop"}
+	cfg.Databases["production"] = &DatabaseConfig{Databases: &SchemaList{Names: []string{"shop", "blog"}}}
+	cfg.Databases["staging"] = &DatabaseConfig{Databases: &SchemaList{All: true}}
+
+	stored := []string{"shop", "production.wiki", "staging.shop", "staging.blog", "old", "staging"}
+	want := []string{"production.blog", "production.shop", "shop", "staging.blog", "staging.shop"}
+	if got := cfg.BackupNames(stored); !reflect.DeepEqual(got, want) {
+		t.Errorf("BackupNames() = %v, want %v", got, want)
+	}
+}
+
+func TestServerEntryValidate(t *testing.T) {
+	base := func(database string, list *SchemaList) *DatabaseConfig {
+		return &DatabaseConfig{Type: "mysql", Host: "localhost", Port: 3306, User: "backup",
+			Database: database, Databases: list}
+	}
+
+	tests := []struct {
+		name    string
+		db      *DatabaseConfig
+		wantErr bool
+	}{
+		{"list", base("", &SchemaList{Names: []string{"shop", "blog"}}), false},
+		{"all", base("", &SchemaList{All: true}), false},
+		{"with database", base("shop", &SchemaList{All: true}), true},
+		{"empty list", base("", &SchemaList{}), true},
+		{"duplicate", base("", &SchemaList{Names: []string{"shop", "shop"}}), true},
+		{"empty name", base("", &SchemaList{Names: []string{""}}), true},
+		{"star in list", base("", &SchemaList{Names: []string{"*"}}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.db.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	db := base("", &SchemaList{All: true})
+	db.RestoreTest = &RestoreTestConfig{Enabled: true, Cron: "0 4 * * 0"}
+	if err := db.Validate(); err == nil {
+		t.Error("restore tests should be rejected on server entries")
+	}
+}
+
+func TestAddDatabaseRejectsSchemaNames(t *testing.T) {
+	mgr := &YAMLManager{configPath: filepath.Join(t.TempDir(), "config.yaml")}
+	server := &DatabaseConfig{Type: "mysql", Host: "localhost", Port: 3306, User: "backup",
+		Databases: &SchemaList{Names: []string{"shop"}}}
+	if err := mgr.AddDatabase("production", server); err != nil {
+		t.Fatalf("AddDatabase(production) error = %v", err)
+	}
+
+	db, err := mgr.GetDatabase("production.shop")
+	if err != nil {
+		t.Fatalf("GetDatabase(production.shop) error = %v", err)
+	}
+	if err := mgr.AddDatabase("production.shop", db); err == nil {
+		t.Error("changing a schema of a server entry should fail")
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"512B", 512, false},
+		{"10KB", 10 * 1024, false},
+		{"512M", 512 * 1024 * 1024, false},
+		{"50GB", 50 * 1024 * 1024 * 1024, false},
+		{"50gb", 50 * 1024 * 1024 * 1024, false},
+		{"1.5TB", 3 * 1024 * 1024 * 1024 * 1024 / 2, false},
+		{" 2 G ", 2 * 1024 * 1024 * 1024, false},
+		{"", 0, true},
+		{"GB", 0, true},
+		{"-5GB", 0, true},
+		{"fifty", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveQuota(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Databases["prod"] = &DatabaseConfig{Quota: &QuotaConfig{MaxSize: "100GB"}}
+	cfg.Databases["staging"] = &DatabaseConfig{}
+
+	if q := cfg.GetEffectiveQuota("staging"); q != nil {
+		t.Errorf("expected no quota without defaults, got %+v", q)
+	}
+
+	cfg.Defaults.Quota = &QuotaConfig{MaxSize: "50GB"}
+	if q := cfg.GetEffectiveQuota("staging"); q == nil || q.MaxSize != "50GB" {
+		t.Errorf("expected default quota, got %+v", q)
+	}
+	if q := cfg.GetEffectiveQuota("prod"); q == nil || q.MaxSize != "100GB" {
+		t.Errorf("expected database quota, got %+v", q)
+	}
+
+	var nilQuota *QuotaConfig
+	if limit, err := nilQuota.Limit(); err != nil || limit != 0 {
+		t.Errorf("nil quota Limit() = %d, %v; want 0, nil", limit, err)
+	}
+	if got := nilQuota.GetMinKeep(); got != DefaultQuotaMinKeep {
+		t.Errorf("nil quota GetMinKeep() = %d, want %d", got, DefaultQuotaMinKeep)
+	}
+}
+
+func TestQuotaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quota   *QuotaConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid alert", &QuotaConfig{MaxSize: "50GB"}, false},
+		{"valid prune", &QuotaConfig{MaxSize: "50GB", Action: QuotaActionPrune, MinKeep: 5}, false},
+		{"invalid size", &QuotaConfig{MaxSize: "lots"}, true},
+		{"invalid action", &QuotaConfig{MaxSize: "50GB", Action: "panic"}, true},
+		{"negative min_keep", &QuotaConfig{MaxSize: "50GB", MinKeep: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quota.Validate("quota")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QuotaConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,15 +1,152 @@
 package config
 
+import (
+	"sort"
+	"time"
+)
+
 // Config represents the main configuration file.
 type Config struct {
 	Version   string                     `yaml:"version"`
 	Defaults  *Defaults                  `yaml:"defaults,omitempty"`
+	Storage   *StorageConfig             `yaml:"storage,omitempty"`
+	Audit     *AuditConfig               `yaml:"audit,omitempty"`
+	Daemon    *DaemonConfig              `yaml:"daemon,omitempty"`
+	Digest    *DigestConfig              `yaml:"digest,omitempty"`
+	WorkDir   *WorkDirConfig             `yaml:"work_dir,omitempty"`
+	Plugins   map[string]*PluginConfig   `yaml:"plugins,omitempty"`
 	Databases map[string]*DatabaseConfig `yaml:"databases"`
 }
 
+// StorageConfig controls how backups are laid out on disk.
+type StorageConfig struct {
+	Path   string       `yaml:"path,omitempty"`   // Backup directory (default: <data dir>/backups)
+	Layout string       `yaml:"layout,omitempty"` // "flat" (default) or "date" for {database}/{yyyy}/{mm}/
+	Quota  *QuotaConfig `yaml:"quota,omitempty"`  // Quota across all databases
+}
+
+// AuditConfig controls the audit log of destructive operations.
+type AuditConfig struct {
+	Path      string `yaml:"path,omitempty"`       // Audit log file (default: <data dir>/audit.log)
+	Syslog    bool   `yaml:"syslog,omitempty"`     // Also forward entries to syslog
+	SyslogTag string `yaml:"syslog_tag,omitempty"` // Syslog tag (default: cadangkan)
+}
+
+// WorkDirConfig controls where scratch files such as restore spools and
+// extracted archives are created.
+type WorkDirConfig struct {
+	Path    string `yaml:"path,omitempty"`     // Scratch directory (default: the OS temp directory)
+	MinFree string `yaml:"min_free,omitempty"` // Free space required before scratch space is used, e.g. "5GB"
+}
+
+// DaemonConfig controls how the daemon runs scheduled jobs.
+type DaemonConfig struct {
+	MaxConcurrent           int    `yaml:"max_concurrent,omitempty"`             // Jobs running at once (default: unlimited)
+	MaxConcurrentPerStorage int    `yaml:"max_concurrent_per_storage,omitempty"` // Jobs writing to one storage directory at once (default: unlimited)
+	ShutdownGrace           string `yaml:"shutdown_grace,omitempty"`             // How long a stopping daemon waits for running jobs (default: 1m)
+	ConnectAttempts         int    `yaml:"connect_attempts,omitempty"`           // Attempts to reach a database server before a job fails (default: 3)
+	ConnectBackoff          string `yaml:"connect_backoff,omitempty"`            // Wait before the second attempt, doubled for each further one (default: 2s)
+}
+
+// DefaultShutdownGrace is how long a stopping daemon waits for running jobs
+// when no shutdown_grace is configured.
+const DefaultShutdownGrace = time.Minute
+
+// ShutdownGraceDuration returns how long a stopping daemon waits for
+// running jobs to finish.
+func (d *DaemonConfig) ShutdownGraceDuration() (time.Duration, error) {
+	if d == nil || d.ShutdownGrace == "" {
+		return DefaultShutdownGrace, nil
+	}
+	return ParseAge(d.ShutdownGrace)
+}
+
+// Defaults of the daemon's connection retry.
+const (
+	DefaultConnectAttempts = 3
+	DefaultConnectBackoff  = 2 * time.Second
+)
+
+// ConnectRetry returns how many times the daemon tries to reach a database
+// server and the wait before the second attempt.
+func (d *DaemonConfig) ConnectRetry() (int, time.Duration, error) {
+	attempts, backoff := DefaultConnectAttempts, DefaultConnectBackoff
+	if d == nil {
+		return attempts, backoff, nil
+	}
+	if d.ConnectAttempts > 0 {
+		attempts = d.ConnectAttempts
+	}
+	if d.ConnectBackoff != "" {
+		var err error
+		if backoff, err = ParseAge(d.ConnectBackoff); err != nil {
+			return 0, 0, err
+		}
+	}
+	return attempts, backoff, nil
+}
+
+// DigestConfig controls the email digest summarizing all databases.
+type DigestConfig struct {
+	Period     string      `yaml:"period,omitempty"` // "daily" (default) or "weekly"
+	Cron       string      `yaml:"cron,omitempty"`   // When to send (default: 08:00 daily, or Mondays when weekly)
+	Recipients []string    `yaml:"recipients"`
+	SMTP       *SMTPConfig `yaml:"smtp"`
+}
+
+// Digest periods
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// SMTPConfig is the mail server digests are sent through.
+type SMTPConfig struct {
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port,omitempty"` // Default: 587, with STARTTLS when offered
+	Username     string `yaml:"username,omitempty"`
+	PasswordEnv  string `yaml:"password_env,omitempty"`  // Environment variable holding the password
+	PasswordFile string `yaml:"password_file,omitempty"` // File holding the password
+	From         string `yaml:"from"`
+}
+
+// PluginConfig is an external program run on backup and restore events,
+// e.g. to upload backups to custom storage or send notifications.
+type PluginConfig struct {
+	Command string   `yaml:"command"`           // Executable, found in PATH if not a path
+	Args    []string `yaml:"args,omitempty"`    // Arguments passed to the command, as templates
+	Input   string   `yaml:"input,omitempty"`   // Template written to stdin instead of the JSON request
+	Hooks   []string `yaml:"hooks,omitempty"`   // Events the plugin receives (default: all)
+	Timeout string   `yaml:"timeout,omitempty"` // How long one call may take (default: 5m)
+}
+
+// Plugin hooks, the events plugins are called on
+const (
+	HookBackupStarted    = "backup.started"
+	HookBackupCompleted  = "backup.completed"
+	HookBackupFailed     = "backup.failed"
+	HookRestoreCompleted = "restore.completed"
+	HookRestoreFailed    = "restore.failed"
+)
+
 // Defaults contains default settings for all databases.
 type Defaults struct {
 	Retention *RetentionPolicy `yaml:"retention,omitempty"`
+	Quota     *QuotaConfig     `yaml:"quota,omitempty"`     // Per-database quota
+	Freshness string           `yaml:"freshness,omitempty"` // Maximum age of the last successful backup, e.g. "26h"
+
+	// Client programs, found in PATH if not a path (default: mysqldump and mysql)
+	MysqldumpPath string `yaml:"mysqldump_path,omitempty"`
+	MysqlPath     string `yaml:"mysql_path,omitempty"`
+
+	// VersionCheck is what a backup does when mysqldump is older than the
+	// server or made for MySQL when the server runs MariaDB, or the other
+	// way around: warn (default), fail or ignore
+	VersionCheck string `yaml:"version_check,omitempty"`
+
+	// OverrunFactor is how many times its estimated duration a backup may
+	// run before an alert is raised (default: 2)
+	OverrunFactor float64 `yaml:"overrun_factor,omitempty"`
 }
 
 // RetentionPolicy defines how long to keep backups.
@@ -20,23 +157,174 @@ type RetentionPolicy struct {
 	KeepAll bool `yaml:"keep_all,omitempty"` // Never delete backups
 }
 
-// ScheduleConfig defines when backups should run.
+// QuotaConfig limits how much storage backups may use.
+type QuotaConfig struct {
+	MaxSize string `yaml:"max_size,omitempty"` // Maximum size, e.g. "50GB"
+	Action  string `yaml:"action,omitempty"`   // "alert" (default) or "prune" when a backup would exceed the quota
+	MinKeep int    `yaml:"min_keep,omitempty"` // Backups never pruned to satisfy the quota (default: 3)
+}
+
+// Constants for quota actions
+const (
+	QuotaActionAlert = "alert"
+	QuotaActionPrune = "prune"
+)
+
+// DefaultQuotaMinKeep is the number of newest backups never pruned for quota.
+const DefaultQuotaMinKeep = 3
+
+// ScheduleConfig defines when backups should run, and optionally how.
 type ScheduleConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Cron    string `yaml:"cron"` // Cron expression (e.g., "0 2 * * *" for daily at 2 AM)
+	Name          string           `yaml:"name,omitempty"` // Name among the database's schedules (required under schedules)
+	Enabled       bool             `yaml:"enabled"`
+	Cron          string           `yaml:"cron"`                     // Cron expression (e.g., "0 2 * * *" for daily at 2 AM), optionally with a leading seconds field
+	Timezone      string           `yaml:"timezone,omitempty"`       // IANA timezone of the cron expression and blackouts (default: server local time)
+	Jitter        string           `yaml:"jitter,omitempty"`         // Random delay up to this duration before each run, e.g. "10m"
+	Blackout      []string         `yaml:"blackout,omitempty"`       // Daily windows with no backups, e.g. "08:00-20:00"
+	Priority      int              `yaml:"priority,omitempty"`       // Higher runs first when the daemon queues jobs (default: 0)
+	Compression   string           `yaml:"compression,omitempty"`    // gzip (default) or none
+	SchemaOnly    bool             `yaml:"schema_only,omitempty"`    // Back up only the schema
+	Tables        []string         `yaml:"tables,omitempty"`         // Tables to include (default: all)
+	ExcludeTables []string         `yaml:"exclude_tables,omitempty"` // Tables to exclude
+	Retention     *RetentionPolicy `yaml:"retention,omitempty"`      // Override the database retention after runs of this schedule
+	Output        string           `yaml:"output,omitempty"`         // Storage directory (default: <data dir>/backups)
+}
+
+// JitterDuration returns the maximum random delay before each run.
+func (sc *ScheduleConfig) JitterDuration() (time.Duration, error) {
+	if sc == nil || sc.Jitter == "" {
+		return 0, nil
+	}
+	return ParseAge(sc.Jitter)
+}
+
+// BlackoutWindows returns the parsed blackout windows.
+func (sc *ScheduleConfig) BlackoutWindows() ([]Window, error) {
+	if sc == nil {
+		return nil, nil
+	}
+	windows := make([]Window, 0, len(sc.Blackout))
+	for _, value := range sc.Blackout {
+		w, err := ParseWindow(value)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// Location returns the timezone of the schedule, time.Local if none is set.
+func (sc *ScheduleConfig) Location() (*time.Location, error) {
+	if sc == nil || sc.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(sc.Timezone)
+}
+
+// InBlackout returns the blackout window containing t, if any. With a
+// timezone set, t is compared in the schedule's timezone.
+func (sc *ScheduleConfig) InBlackout(t time.Time) (Window, bool) {
+	if sc != nil && sc.Timezone != "" {
+		if loc, err := sc.Location(); err == nil {
+			t = t.In(loc)
+		}
+	}
+	windows, _ := sc.BlackoutWindows()
+	for _, w := range windows {
+		if w.Contains(t) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// RestoreTestConfig defines a scheduled restore drill: the latest backup is
+// restored into a scratch database and validated.
+type RestoreTestConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Cron            string `yaml:"cron"`
+	ScratchDatabase string `yaml:"scratch_database,omitempty"` // Dropped and recreated on each run (default: <database>_restore_test)
+	Server          string `yaml:"server,omitempty"`           // Configured database whose server receives the restore (default: same server)
+	Keep            bool   `yaml:"keep,omitempty"`             // Keep the scratch database after the drill
 }
 
 // DatabaseConfig represents a database configuration.
 type DatabaseConfig struct {
-	Name              string           `yaml:"-"` // Not stored in YAML, derived from map key
-	Type              string           `yaml:"type"`
-	Host              string           `yaml:"host"`
-	Port              int              `yaml:"port"`
-	Database          string           `yaml:"database"`
-	User              string           `yaml:"user"`
-	PasswordEncrypted string           `yaml:"password_encrypted,omitempty"`
-	Schedule          *ScheduleConfig  `yaml:"schedule,omitempty"`
-	Retention         *RetentionPolicy `yaml:"retention,omitempty"` // Override defaults
+	Name              string             `yaml:"-"` // Not stored in YAML, derived from map key
+	Type              string             `yaml:"type"`
+	Host              string             `yaml:"host"`
+	Port              int                `yaml:"port"`
+	Database          string             `yaml:"database,omitempty"`
+	Databases         *SchemaList        `yaml:"databases,omitempty"` // Schemas of a server entry, a list or "*"
+	User              string             `yaml:"user"`
+	PasswordEncrypted string             `yaml:"password_encrypted,omitempty"`
+	PasswordEnv       string             `yaml:"password_env,omitempty"`  // Environment variable holding the password
+	PasswordFile      string             `yaml:"password_file,omitempty"` // File holding the password, e.g. a mounted secret
+	Schedule          *ScheduleConfig    `yaml:"schedule,omitempty"`
+	Schedules         []*ScheduleConfig  `yaml:"schedules,omitempty"`    // Additional named schedules
+	Retention         *RetentionPolicy   `yaml:"retention,omitempty"`    // Override defaults
+	Quota             *QuotaConfig       `yaml:"quota,omitempty"`        // Override default quota
+	StoragePath       string             `yaml:"storage_path,omitempty"` // Backup directory, overriding storage.path
+	MysqldumpPath     string             `yaml:"mysqldump_path,omitempty"` // mysqldump program, overriding defaults.mysqldump_path
+	MysqlPath         string             `yaml:"mysql_path,omitempty"`     // mysql client program, overriding defaults.mysql_path
+	VersionCheck      string             `yaml:"version_check,omitempty"`  // Override defaults.version_check
+	Mysqldump         *MysqldumpConfig   `yaml:"mysqldump,omitempty"`    // Customize mysqldump flags
+	RestoreTest       *RestoreTestConfig `yaml:"restore_test,omitempty"` // Scheduled restore drill
+	Freshness         string             `yaml:"freshness,omitempty"`    // Override default freshness SLA, e.g. "26h"
+	Protection        string             `yaml:"protection,omitempty"`   // "strict" requires typing the name to restore or delete
+	TLS               *TLSConfig         `yaml:"tls,omitempty"`          // Encrypted connections
+	LongQueries       *LongQueryConfig   `yaml:"long_queries,omitempty"` // Check for long-running queries before a backup
+	LockMonitor       *LockMonitorConfig `yaml:"lock_monitor,omitempty"` // Watch for queries blocked during a backup
+	ReferenceTables   []string           `yaml:"reference_tables,omitempty"` // Tables whose data restore --schema-only keeps, e.g. countries
+}
+
+// TLSConfig configures encrypted connections to a database server.
+type TLSConfig struct {
+	Mode string `yaml:"mode,omitempty"` // disabled, preferred (default), required, verify-ca or verify-identity
+	CA   string `yaml:"ca,omitempty"`   // CA certificate file
+	Cert string `yaml:"cert,omitempty"` // Client certificate file
+	Key  string `yaml:"key,omitempty"`  // Client key file
+}
+
+// ProtectionStrict requires restores and deletions to be confirmed by
+// typing the database name, even with --yes.
+const ProtectionStrict = "strict"
+
+// StrictProtection reports whether restores and deletions against the
+// database must be confirmed by typing its name.
+func (d *DatabaseConfig) StrictProtection() bool {
+	return d != nil && d.Protection == ProtectionStrict
+}
+
+// MysqldumpConfig customizes the mysqldump command line for a database.
+type MysqldumpConfig struct {
+	ExtraArgs       []string            `yaml:"extra_args,omitempty"`       // Additional flags, e.g. "--hex-blob"
+	DisableDefaults []string            `yaml:"disable_defaults,omitempty"` // Default flags to leave out, e.g. "set-gtid-purged"
+	Warnings        *DumpWarningsConfig `yaml:"warnings,omitempty"`         // How stderr output of a successful dump is treated
+	Definers        string              `yaml:"definers,omitempty"`         // DEFINER clauses: keep (default), strip, or an account user@host to rewrite them to
+	SQLSecurity     string              `yaml:"sql_security,omitempty"`     // SQL SECURITY of views and routines: keep (default) or invoker
+}
+
+// DumpWarningsConfig controls which mysqldump warnings fail a backup.
+type DumpWarningsConfig struct {
+	Action string   `yaml:"action,omitempty"` // fail (default), warn, or ignore
+	Allow  []string `yaml:"allow,omitempty"`  // Regex patterns of warnings that never fail the backup
+}
+
+// LongQueryConfig checks the server for long-running queries before a
+// backup starts, as they can block the locks the dump takes.
+type LongQueryConfig struct {
+	Threshold string `yaml:"threshold,omitempty"` // Report queries running longer, e.g. "5m" (default 60s)
+	Action    string `yaml:"action,omitempty"`    // warn (default) or kill
+}
+
+// LockMonitorConfig watches the server for queries waiting on locks while
+// a backup runs, to show the impact of the backup on applications.
+type LockMonitorConfig struct {
+	Threshold string `yaml:"threshold,omitempty"` // Report queries waiting longer, e.g. "30s" (default 30s)
+	Interval  string `yaml:"interval,omitempty"`  // How often to check, e.g. "5s" (default 5s)
+	Action    string `yaml:"action,omitempty"`    // warn (default) or abort
 }
 
 // NewConfig creates a new Config with default values.
@@ -71,7 +359,7 @@ func DefaultRetentionPolicy() *RetentionPolicy {
 // GetEffectiveRetention returns the effective retention policy for a database.
 // Database-specific policy overrides defaults.
 func (c *Config) GetEffectiveRetention(dbName string) *RetentionPolicy {
-	db, exists := c.Databases[dbName]
+	db, exists := c.Database(dbName)
 	if !exists {
 		return DefaultRetentionPolicy()
 	}
@@ -89,3 +377,141 @@ func (c *Config) GetEffectiveRetention(dbName string) *RetentionPolicy {
 	// Fallback to default retention
 	return DefaultRetentionPolicy()
 }
+
+// GetEffectiveQuota returns the quota for a database, or nil if none is set.
+// Database-specific quota overrides defaults.
+func (c *Config) GetEffectiveQuota(dbName string) *QuotaConfig {
+	if db, exists := c.Database(dbName); exists && db.Quota != nil {
+		return db.Quota
+	}
+	if c.Defaults != nil && c.Defaults.Quota != nil {
+		return c.Defaults.Quota
+	}
+	return nil
+}
+
+// GetEffectiveStoragePath returns the directory the backups of a database
+// are stored in, or empty for the default backups directory.
+// Database-specific path overrides storage.path.
+func (c *Config) GetEffectiveStoragePath(dbName string) string {
+	if db, exists := c.Database(dbName); exists && db.StoragePath != "" {
+		return db.StoragePath
+	}
+	if c.Storage != nil {
+		return c.Storage.Path
+	}
+	return ""
+}
+
+// GetEffectiveMysqldumpPath returns the mysqldump program backups of a
+// database run, or empty for mysqldump in PATH.
+// Database-specific path overrides defaults.
+func (c *Config) GetEffectiveMysqldumpPath(dbName string) string {
+	if db, exists := c.Database(dbName); exists && db.MysqldumpPath != "" {
+		return db.MysqldumpPath
+	}
+	if c.Defaults != nil {
+		return c.Defaults.MysqldumpPath
+	}
+	return ""
+}
+
+// GetEffectiveMysqlPath returns the mysql client program restores of a
+// database run, or empty for mysql in PATH.
+// Database-specific path overrides defaults.
+func (c *Config) GetEffectiveMysqlPath(dbName string) string {
+	if db, exists := c.Database(dbName); exists && db.MysqlPath != "" {
+		return db.MysqlPath
+	}
+	if c.Defaults != nil {
+		return c.Defaults.MysqlPath
+	}
+	return ""
+}
+
+// GetEffectiveVersionCheck returns what a backup of a database does when
+// mysqldump does not suit the server's release, or empty for the default.
+// Database-specific setting overrides defaults.
+func (c *Config) GetEffectiveVersionCheck(dbName string) string {
+	if db, exists := c.Database(dbName); exists && db.VersionCheck != "" {
+		return db.VersionCheck
+	}
+	if c.Defaults != nil {
+		return c.Defaults.VersionCheck
+	}
+	return ""
+}
+
+// StoragePaths returns the backup directories configured for single
+// databases, sorted and without duplicates.
+func (c *Config) StoragePaths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, db := range c.Databases {
+		if db.StoragePath != "" && !seen[db.StoragePath] {
+			seen[db.StoragePath] = true
+			paths = append(paths, db.StoragePath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// GetEffectiveFreshness returns the maximum age of the last successful
+// backup of a database, or 0 if no freshness SLA is set. Database-specific
+// SLA overrides defaults.
+func (c *Config) GetEffectiveFreshness(dbName string) (time.Duration, error) {
+	value := ""
+	if c.Defaults != nil {
+		value = c.Defaults.Freshness
+	}
+	if db, exists := c.Database(dbName); exists && db.Freshness != "" {
+		value = db.Freshness
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return ParseAge(value)
+}
+
+// GetOverrunFactor returns how many times its estimated duration a backup
+// may run before an alert is raised, or 0 if none is set.
+func (c *Config) GetOverrunFactor() float64 {
+	if c.Defaults != nil {
+		return c.Defaults.OverrunFactor
+	}
+	return 0
+}
+
+// GetGlobalQuota returns the quota across all databases, or nil if none is set.
+func (c *Config) GetGlobalQuota() *QuotaConfig {
+	if c.Storage != nil {
+		return c.Storage.Quota
+	}
+	return nil
+}
+
+// Limit returns the quota's maximum size in bytes, or 0 if unlimited.
+func (q *QuotaConfig) Limit() (int64, error) {
+	if q == nil || q.MaxSize == "" {
+		return 0, nil
+	}
+	return ParseSize(q.MaxSize)
+}
+
+// MinFreeBytes returns the free space the work directory must have in
+// bytes, or 0 if no minimum is set.
+func (w *WorkDirConfig) MinFreeBytes() (int64, error) {
+	if w == nil || w.MinFree == "" {
+		return 0, nil
+	}
+	return ParseSize(w.MinFree)
+}
+
+// GetMinKeep returns the number of newest backups never pruned for quota.
+func (q *QuotaConfig) GetMinKeep() int {
+	if q == nil || q.MinKeep <= 0 {
+		return DefaultQuotaMinKeep
+	}
+	return q.MinKeep
+}
@@ -1,6 +1,11 @@
 package config
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
 
 // Validate validates the entire config.
 func (c *Config) Validate() error {
@@ -8,6 +13,68 @@ func (c *Config) Validate() error {
 		return &ValidationError{Field: "version", Message: "version is required"}
 	}
 
+	if c.Storage != nil {
+		switch c.Storage.Layout {
+		case "", "flat", "date":
+		default:
+			return &ValidationError{Field: "storage.layout", Message: "storage layout must be 'flat' or 'date'"}
+		}
+		if err := c.Storage.Quota.Validate("storage.quota"); err != nil {
+			return err
+		}
+	}
+
+	if c.Daemon != nil {
+		if c.Daemon.MaxConcurrent < 0 {
+			return &ValidationError{Field: "daemon.max_concurrent", Message: "max_concurrent cannot be negative"}
+		}
+		if c.Daemon.MaxConcurrentPerStorage < 0 {
+			return &ValidationError{Field: "daemon.max_concurrent_per_storage", Message: "max_concurrent_per_storage cannot be negative"}
+		}
+		if _, err := c.Daemon.ShutdownGraceDuration(); err != nil {
+			return &ValidationError{Field: "daemon.shutdown_grace", Message: err.Error()}
+		}
+		if c.Daemon.ConnectAttempts < 0 {
+			return &ValidationError{Field: "daemon.connect_attempts", Message: "connect_attempts cannot be negative"}
+		}
+		if _, _, err := c.Daemon.ConnectRetry(); err != nil {
+			return &ValidationError{Field: "daemon.connect_backoff", Message: err.Error()}
+		}
+	}
+
+	if err := c.Digest.Validate("digest"); err != nil {
+		return err
+	}
+
+	if c.WorkDir != nil {
+		if _, err := c.WorkDir.MinFreeBytes(); err != nil {
+			return &ValidationError{Field: "work_dir.min_free", Message: err.Error()}
+		}
+	}
+
+	for name, plugin := range c.Plugins {
+		if err := plugin.Validate("plugins." + name); err != nil {
+			return err
+		}
+	}
+
+	if c.Defaults != nil {
+		if err := c.Defaults.Quota.Validate("defaults.quota"); err != nil {
+			return err
+		}
+		if c.Defaults.Freshness != "" {
+			if _, err := ParseAge(c.Defaults.Freshness); err != nil {
+				return &ValidationError{Field: "defaults.freshness", Message: err.Error()}
+			}
+		}
+		if c.Defaults.OverrunFactor != 0 && c.Defaults.OverrunFactor < 1 {
+			return &ValidationError{Field: "defaults.overrun_factor", Message: "overrun_factor must be at least 1"}
+		}
+		if err := validateVersionCheck("defaults.version_check", c.Defaults.VersionCheck); err != nil {
+			return err
+		}
+	}
+
 	// Validate each database config
 	for name, db := range c.Databases {
 		db.Name = name // Ensure name is set
@@ -19,6 +86,100 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Validate validates a digest configuration. A nil digest is valid.
+func (d *DigestConfig) Validate(field string) error {
+	if d == nil {
+		return nil
+	}
+	switch d.Period {
+	case "", DigestDaily, DigestWeekly:
+	default:
+		return &ValidationError{Field: field + ".period", Message: "period must be 'daily' or 'weekly'"}
+	}
+	if len(d.Recipients) == 0 {
+		return &ValidationError{Field: field + ".recipients", Message: "at least one recipient is required"}
+	}
+	if d.SMTP == nil || d.SMTP.Host == "" {
+		return &ValidationError{Field: field + ".smtp.host", Message: "SMTP host is required"}
+	}
+	if d.SMTP.From == "" {
+		return &ValidationError{Field: field + ".smtp.from", Message: "sender address is required"}
+	}
+	if d.SMTP.Port < 0 || d.SMTP.Port > 65535 {
+		return &ValidationError{Field: field + ".smtp.port", Message: "port must be between 1 and 65535"}
+	}
+	return nil
+}
+
+// Validate validates a plugin configuration.
+func (p *PluginConfig) Validate(field string) error {
+	if p == nil || p.Command == "" {
+		return &ValidationError{Field: field + ".command", Message: "command is required"}
+	}
+	for _, hook := range p.Hooks {
+		if !isPluginHook(hook) {
+			return &ValidationError{Field: field + ".hooks", Message: fmt.Sprintf("unknown hook '%s' (must be one of %s)", hook, strings.Join(PluginHooks, ", "))}
+		}
+	}
+	for _, arg := range p.Args {
+		if _, err := template.New("arg").Funcs(PluginTemplateFuncs).Parse(arg); err != nil {
+			return &ValidationError{Field: field + ".args", Message: fmt.Sprintf("invalid template: %v", err)}
+		}
+	}
+	if _, err := template.New("input").Funcs(PluginTemplateFuncs).Parse(p.Input); err != nil {
+		return &ValidationError{Field: field + ".input", Message: fmt.Sprintf("invalid template: %v", err)}
+	}
+	if _, err := p.TimeoutDuration(); err != nil {
+		return &ValidationError{Field: field + ".timeout", Message: err.Error()}
+	}
+	return nil
+}
+
+func isPluginHook(hook string) bool {
+	for _, h := range PluginHooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates a quota configuration. A nil quota is valid.
+func (q *QuotaConfig) Validate(field string) error {
+	if q == nil {
+		return nil
+	}
+	if _, err := q.Limit(); err != nil {
+		return &ValidationError{Field: field + ".max_size", Message: err.Error()}
+	}
+	switch q.Action {
+	case "", QuotaActionAlert, QuotaActionPrune:
+	default:
+		return &ValidationError{Field: field + ".action", Message: "quota action must be 'alert' or 'prune'"}
+	}
+	if q.MinKeep < 0 {
+		return &ValidationError{Field: field + ".min_keep", Message: "min_keep cannot be negative"}
+	}
+	return nil
+}
+
+// Validate validates a TLS configuration; field is its name in errors.
+func (t *TLSConfig) Validate(field string) error {
+	if t == nil {
+		return nil
+	}
+	if mode, err := ParseTLSMode(t.Mode); t.Mode != "" && (err != nil || mode != t.Mode) {
+		return &ValidationError{Field: field + ".mode", Message: "tls mode must be 'disabled', 'preferred', 'required', 'verify-ca' or 'verify-identity'"}
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return &ValidationError{Field: field + ".cert", Message: "tls cert and key must be set together"}
+	}
+	if (t.Mode == TLSModeVerifyCA || t.Mode == TLSModeVerifyIdentity) && t.CA == "" {
+		return &ValidationError{Field: field + ".ca", Message: fmt.Sprintf("tls mode %s requires a ca", t.Mode)}
+	}
+	return nil
+}
+
 // Validate validates a database configuration.
 func (d *DatabaseConfig) Validate() error {
 	if d.Type == "" {
@@ -41,10 +202,224 @@ func (d *DatabaseConfig) Validate() error {
 		return &ValidationError{Field: "user", Message: "user is required"}
 	}
 
-	if d.Database == "" {
+	if d.Databases != nil {
+		if err := d.Databases.Validate(d.Database); err != nil {
+			return err
+		}
+		if d.RestoreTest != nil {
+			return &ValidationError{Field: "restore_test", Message: "restore tests are not supported on server entries"}
+		}
+	} else if d.Database == "" {
 		return &ValidationError{Field: "database", Message: "database name is required"}
 	}
 
+	if d.PasswordEnv != "" && d.PasswordFile != "" {
+		return &ValidationError{Field: "password_env", Message: "password_env and password_file cannot be combined"}
+	}
+
+	if d.Protection != "" && d.Protection != ProtectionStrict {
+		return &ValidationError{Field: "protection", Message: "protection must be 'strict' or empty"}
+	}
+
+	if err := d.TLS.Validate("tls"); err != nil {
+		return err
+	}
+
+	if err := d.Quota.Validate("quota"); err != nil {
+		return err
+	}
+
+	if d.Mysqldump != nil {
+		if err := d.Mysqldump.Warnings.Validate("mysqldump.warnings"); err != nil {
+			return err
+		}
+		if err := validateDefiners("mysqldump.definers", d.Mysqldump.Definers); err != nil {
+			return err
+		}
+		switch d.Mysqldump.SQLSecurity {
+		case "", "keep", "invoker":
+		default:
+			return &ValidationError{Field: "mysqldump.sql_security", Message: "sql_security must be 'keep' or 'invoker'"}
+		}
+	}
+
+	if err := d.LongQueries.Validate("long_queries"); err != nil {
+		return err
+	}
+
+	if err := validateVersionCheck("version_check", d.VersionCheck); err != nil {
+		return err
+	}
+
+	if err := d.LockMonitor.Validate("lock_monitor"); err != nil {
+		return err
+	}
+
+	if err := d.Schedule.Validate("schedule"); err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for i, sc := range d.Schedules {
+		field := fmt.Sprintf("schedules[%d]", i)
+		if sc == nil || sc.Name == "" {
+			return &ValidationError{Field: field + ".name", Message: "named schedules require a name"}
+		}
+		if sc.Name == DefaultScheduleName || names[sc.Name] {
+			return &ValidationError{Field: field + ".name", Message: fmt.Sprintf("duplicate schedule name %q", sc.Name)}
+		}
+		names[sc.Name] = true
+		if err := sc.Validate(field); err != nil {
+			return err
+		}
+	}
+
+	if d.Freshness != "" {
+		if _, err := ParseAge(d.Freshness); err != nil {
+			return &ValidationError{Field: "freshness", Message: err.Error()}
+		}
+	}
+
+	if d.RestoreTest != nil {
+		if d.RestoreTest.Enabled && d.RestoreTest.Cron == "" {
+			return &ValidationError{Field: "restore_test.cron", Message: "cron is required for an enabled restore test"}
+		}
+		if d.RestoreTest.ScratchDatabase == d.Database && d.RestoreTest.Server == "" {
+			return &ValidationError{Field: "restore_test.scratch_database", Message: "scratch database must differ from the backed up database"}
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the schemas of a server entry; database is the
+// entry's database, which must be empty.
+func (l *SchemaList) Validate(database string) error {
+	if database != "" {
+		return &ValidationError{Field: "databases", Message: "database and databases cannot be combined"}
+	}
+	if l.All {
+		return nil
+	}
+	if len(l.Names) == 0 {
+		return &ValidationError{Field: "databases", Message: "databases must list at least one database or be \"*\""}
+	}
+	seen := make(map[string]bool)
+	for _, name := range l.Names {
+		if name == "" || name == AllSchemas {
+			return &ValidationError{Field: "databases", Message: fmt.Sprintf("invalid database name %q", name)}
+		}
+		if seen[name] {
+			return &ValidationError{Field: "databases", Message: fmt.Sprintf("duplicate database %q", name)}
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Validate validates a schedule configuration. A nil config is valid.
+func (sc *ScheduleConfig) Validate(field string) error {
+	if sc == nil {
+		return nil
+	}
+	if _, err := sc.JitterDuration(); err != nil {
+		return &ValidationError{Field: field + ".jitter", Message: err.Error()}
+	}
+	if _, err := sc.BlackoutWindows(); err != nil {
+		return &ValidationError{Field: field + ".blackout", Message: err.Error()}
+	}
+	if _, err := sc.Location(); err != nil {
+		return &ValidationError{Field: field + ".timezone", Message: fmt.Sprintf("unknown timezone %q", sc.Timezone)}
+	}
+	switch sc.Compression {
+	case "", "gzip", "none":
+	default:
+		return &ValidationError{Field: field + ".compression", Message: "compression must be 'gzip' or 'none'"}
+	}
+	if len(sc.Tables) > 0 && len(sc.ExcludeTables) > 0 {
+		return &ValidationError{Field: field + ".tables", Message: "tables and exclude_tables cannot be combined"}
+	}
+	return nil
+}
+
+// Validate validates a dump warnings configuration. A nil config is valid.
+func (w *DumpWarningsConfig) Validate(field string) error {
+	if w == nil {
+		return nil
+	}
+	switch w.Action {
+	case "", "fail", "warn", "ignore":
+	default:
+		return &ValidationError{Field: field + ".action", Message: "warnings action must be 'fail', 'warn' or 'ignore'"}
+	}
+	for _, pattern := range w.Allow {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return &ValidationError{Field: field + ".allow", Message: "invalid pattern " + pattern + ": " + err.Error()}
+		}
+	}
+	return nil
+}
+
+// validateDefiners validates a definers setting: keep, strip or an
+// unquoted account user@host. Empty is valid.
+func validateDefiners(field, value string) error {
+	switch value {
+	case "", "keep", "strip":
+		return nil
+	}
+	at := strings.LastIndex(value, "@")
+	if at <= 0 || at == len(value)-1 || strings.ContainsAny(value, "`'\"") {
+		return &ValidationError{Field: field, Message: "definers must be 'keep', 'strip' or an account user@host"}
+	}
+	return nil
+}
+
+// validateVersionCheck validates a version_check setting. Empty is valid.
+func validateVersionCheck(field, value string) error {
+	switch value {
+	case "", "warn", "fail", "ignore":
+		return nil
+	}
+	return &ValidationError{Field: field, Message: "version_check must be 'warn', 'fail' or 'ignore'"}
+}
+
+// Validate validates a long query configuration. A nil config is valid.
+func (l *LongQueryConfig) Validate(field string) error {
+	if l == nil {
+		return nil
+	}
+	switch l.Action {
+	case "", "warn", "kill":
+	default:
+		return &ValidationError{Field: field + ".action", Message: "long query action must be 'warn' or 'kill'"}
+	}
+	if l.Threshold != "" {
+		if _, err := ParseAge(l.Threshold); err != nil {
+			return &ValidationError{Field: field + ".threshold", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// Validate validates a lock monitor configuration. A nil config is valid.
+func (l *LockMonitorConfig) Validate(field string) error {
+	if l == nil {
+		return nil
+	}
+	switch l.Action {
+	case "", "warn", "abort":
+	default:
+		return &ValidationError{Field: field + ".action", Message: "lock monitor action must be 'warn' or 'abort'"}
+	}
+	if l.Threshold != "" {
+		if _, err := ParseAge(l.Threshold); err != nil {
+			return &ValidationError{Field: field + ".threshold", Message: err.Error()}
+		}
+	}
+	if l.Interval != "" {
+		if _, err := ParseAge(l.Interval); err != nil {
+			return &ValidationError{Field: field + ".interval", Message: err.Error()}
+		}
+	}
 	return nil
 }
 
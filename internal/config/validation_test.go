@@ -56,6 +56,153 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "date storage layout",
+			config: &Config{
+				Version:   "1.0",
+				Storage:   &StorageConfig{Layout: "date"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid storage layout",
+			config: &Config{
+				Version:   "1.0",
+				Storage:   &StorageConfig{Layout: "hourly"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "daemon concurrency limits",
+			config: &Config{
+				Version:   "1.0",
+				Daemon:    &DaemonConfig{MaxConcurrent: 4, MaxConcurrentPerStorage: 2, ShutdownGrace: "5m"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid connect backoff",
+			config: &Config{
+				Version:   "1.0",
+				Daemon:    &DaemonConfig{ConnectAttempts: 5, ConnectBackoff: "soon"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative connect_attempts",
+			config: &Config{
+				Version:   "1.0",
+				Daemon:    &DaemonConfig{ConnectAttempts: -1},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid shutdown grace",
+			config: &Config{
+				Version:   "1.0",
+				Daemon:    &DaemonConfig{ShutdownGrace: "soon"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max_concurrent",
+			config: &Config{
+				Version:   "1.0",
+				Daemon:    &DaemonConfig{MaxConcurrent: -1},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "overrun factor",
+			config: &Config{
+				Version:   "1.0",
+				Defaults:  &Defaults{OverrunFactor: 1.5},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "overrun factor below 1",
+			config: &Config{
+				Version:   "1.0",
+				Defaults:  &Defaults{OverrunFactor: 0.5},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "work dir",
+			config: &Config{
+				Version:   "1.0",
+				WorkDir:   &WorkDirConfig{Path: "/var/tmp/cadangkan", MinFree: "5GB"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "work dir with invalid min_free",
+			config: &Config{
+				Version:   "1.0",
+				WorkDir:   &WorkDirConfig{MinFree: "lots"},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "plugin",
+			config: &Config{
+				Version: "1.0",
+				Plugins: map[string]*PluginConfig{
+					"s3":   {Command: "cadangkan-s3", Hooks: []string{HookBackupCompleted}, Timeout: "10m"},
+					"chat": {Command: "curl", Input: `{"text": "{{json .Error}}"}`},
+				},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "plugin without command",
+			config: &Config{
+				Version:   "1.0",
+				Plugins:   map[string]*PluginConfig{"s3": {}},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "plugin with unknown hook",
+			config: &Config{
+				Version:   "1.0",
+				Plugins:   map[string]*PluginConfig{"s3": {Command: "cadangkan-s3", Hooks: []string{"backup.done"}}},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "plugin with invalid input template",
+			config: &Config{
+				Version:   "1.0",
+				Plugins:   map[string]*PluginConfig{"chat": {Command: "curl", Input: `{"text": "{{.Database"}`}},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "plugin with invalid timeout",
+			config: &Config{
+				Version:   "1.0",
+				Plugins:   map[string]*PluginConfig{"s3": {Command: "cadangkan-s3", Timeout: "soon"}},
+				Databases: map[string]*DatabaseConfig{},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -85,6 +232,78 @@ func TestDatabaseConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "strict protection",
+			config: &DatabaseConfig{
+				Type:       "mysql",
+				Host:       "localhost",
+				Port:       3306,
+				Database:   "testdb",
+				User:       "testuser",
+				Protection: ProtectionStrict,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown protection",
+			config: &DatabaseConfig{
+				Type:       "mysql",
+				Host:       "localhost",
+				Port:       3306,
+				Database:   "testdb",
+				User:       "testuser",
+				Protection: "paranoid",
+			},
+			wantErr: true,
+		},
+		{
+			name: "tls verify-ca with ca",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				TLS:      &TLSConfig{Mode: TLSModeVerifyCA, CA: "/etc/ca.pem"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tls verify-ca without ca",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				TLS:      &TLSConfig{Mode: TLSModeVerifyCA},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tls unknown mode",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				TLS:      &TLSConfig{Mode: "VERIFY_CA", CA: "/etc/ca.pem"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tls cert without key",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				TLS:      &TLSConfig{Cert: "/etc/client.pem"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing type",
 			config: &DatabaseConfig{
@@ -162,6 +381,210 @@ func TestDatabaseConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid warnings action",
+			config: &DatabaseConfig{
+				Type:      "mysql",
+				Host:      "localhost",
+				Port:      3306,
+				Database:  "testdb",
+				User:      "testuser",
+				Mysqldump: &MysqldumpConfig{Warnings: &DumpWarningsConfig{Action: "retry"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid warnings pattern",
+			config: &DatabaseConfig{
+				Type:      "mysql",
+				Host:      "localhost",
+				Port:      3306,
+				Database:  "testdb",
+				User:      "testuser",
+				Mysqldump: &MysqldumpConfig{Warnings: &DumpWarningsConfig{Allow: []string{"("}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "definers rewritten",
+			config: &DatabaseConfig{
+				Type:      "mysql",
+				Host:      "localhost",
+				Port:      3306,
+				Database:  "testdb",
+				User:      "testuser",
+				Mysqldump: &MysqldumpConfig{Definers: "app@%", SQLSecurity: "invoker"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid definers",
+			config: &DatabaseConfig{
+				Type:      "mysql",
+				Host:      "localhost",
+				Port:      3306,
+				Database:  "testdb",
+				User:      "testuser",
+				Mysqldump: &MysqldumpConfig{Definers: "remove"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sql security",
+			config: &DatabaseConfig{
+				Type:      "mysql",
+				Host:      "localhost",
+				Port:      3306,
+				Database:  "testdb",
+				User:      "testuser",
+				Mysqldump: &MysqldumpConfig{SQLSecurity: "definer"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "long queries",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LongQueries: &LongQueryConfig{Threshold: "5m", Action: "kill"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid long query action",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LongQueries: &LongQueryConfig{Action: "abort"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid long query threshold",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LongQueries: &LongQueryConfig{Threshold: "soon"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid version check",
+			config: &DatabaseConfig{
+				Type:         "mysql",
+				Host:         "localhost",
+				Port:         3306,
+				Database:     "testdb",
+				User:         "testuser",
+				VersionCheck: "strict",
+			},
+			wantErr: true,
+		},
+		{
+			name: "lock monitor",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LockMonitor: &LockMonitorConfig{Threshold: "30s", Interval: "2s", Action: "abort"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid lock monitor interval",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LockMonitor: &LockMonitorConfig{Interval: "0s"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid lock monitor action",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				LockMonitor: &LockMonitorConfig{Action: "kill"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "restore test into source database",
+			config: &DatabaseConfig{
+				Type:        "mysql",
+				Host:        "localhost",
+				Port:        3306,
+				Database:    "testdb",
+				User:        "testuser",
+				RestoreTest: &RestoreTestConfig{Enabled: true, Cron: "0 5 * * 0", ScratchDatabase: "testdb"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schema-only schedule",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				Schedule: &ScheduleConfig{Cron: "0 * * * *", SchemaOnly: true, Compression: "none"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown schedule timezone",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				Schedule: &ScheduleConfig{Cron: "0 2 * * *", Timezone: "Mars/Olympus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid schedule compression",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				Schedule: &ScheduleConfig{Cron: "0 * * * *", Compression: "lz4"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule with tables and exclude tables",
+			config: &DatabaseConfig{
+				Type:     "mysql",
+				Host:     "localhost",
+				Port:     3306,
+				Database: "testdb",
+				User:     "testuser",
+				Schedule: &ScheduleConfig{Cron: "0 * * * *", Tables: []string{"users"}, ExcludeTables: []string{"logs"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
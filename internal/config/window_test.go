@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("08:00-20:30")
+	if err != nil {
+		t.Fatalf("ParseWindow() error = %v", err)
+	}
+	if w.Start != 8*time.Hour || w.End != 20*time.Hour+30*time.Minute {
+		t.Errorf("ParseWindow() = %+v", w)
+	}
+	if w.String() != "08:00-20:30" {
+		t.Errorf("String() = %s", w.String())
+	}
+
+	for _, value := range []string{"", "08:00", "8am-5pm", "25:00-26:00", "10:00-10:00"} {
+		if _, err := ParseWindow(value); err == nil {
+			t.Errorf("ParseWindow(%q) expected error", value)
+		}
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 1, 15, hour, minute, 0, 0, time.UTC)
+	}
+
+	day, _ := ParseWindow("08:00-20:00")
+	night, _ := ParseWindow("22:00-06:00")
+
+	tests := []struct {
+		window Window
+		t      time.Time
+		want   bool
+	}{
+		{day, at(8, 0), true},
+		{day, at(19, 59), true},
+		{day, at(20, 0), false},
+		{day, at(2, 0), false},
+		{night, at(23, 0), true},
+		{night, at(5, 59), true},
+		{night, at(6, 0), false},
+		{night, at(12, 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.window.Contains(tt.t); got != tt.want {
+			t.Errorf("%s.Contains(%s) = %v, want %v", tt.window, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestScheduleInBlackout(t *testing.T) {
+	sc := &ScheduleConfig{Blackout: []string{"08:00-12:00", "13:00-20:00"}}
+
+	if _, ok := sc.InBlackout(time.Date(2025, 1, 15, 12, 30, 0, 0, time.UTC)); ok {
+		t.Error("12:30 should be outside the blackout windows")
+	}
+	if w, ok := sc.InBlackout(time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)); !ok || w.String() != "13:00-20:00" {
+		t.Errorf("14:00 should be in 13:00-20:00, got %s, %v", w, ok)
+	}
+
+	var nilSchedule *ScheduleConfig
+	if _, ok := nilSchedule.InBlackout(time.Now()); ok {
+		t.Error("nil schedule has no blackout")
+	}
+}
+
+func TestScheduleInBlackoutTimezone(t *testing.T) {
+	sc := &ScheduleConfig{Timezone: "Asia/Jakarta", Blackout: []string{"08:00-20:00"}}
+
+	// 02:00 UTC is 09:00 in Jakarta
+	if _, ok := sc.InBlackout(time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)); !ok {
+		t.Error("02:00 UTC should be in the Jakarta blackout window")
+	}
+	// 14:00 UTC is 21:00 in Jakarta
+	if _, ok := sc.InBlackout(time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)); ok {
+		t.Error("14:00 UTC should be outside the Jakarta blackout window")
+	}
+}
@@ -0,0 +1,228 @@
+// Package control implements the local control socket of the daemon, used
+// by the CLI to query its state and to reload or stop it.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// Commands understood by the control socket
+const (
+	CommandStatus = "status"
+	CommandReload = "reload"
+	CommandStop   = "stop"
+)
+
+// dialTimeout bounds how long the CLI waits for the daemon to answer.
+const dialTimeout = 5 * time.Second
+
+// ErrNotRunning is returned when no daemon listens on the socket.
+var ErrNotRunning = errors.New("daemon is not running")
+
+// DaemonState is the state of a running daemon.
+type DaemonState struct {
+	// PID is the process ID of the daemon
+	PID int `json:"pid"`
+
+	// StartedAt is when the daemon started
+	StartedAt time.Time `json:"started_at"`
+
+	// Schedules are the active cron entries
+	Schedules []ScheduleState `json:"schedules"`
+
+	// Running lists the runs in progress
+	Running []RunState `json:"running,omitempty"`
+
+	// Queued lists the jobs waiting for a free slot, in the order they
+	// will start
+	Queued []QueuedState `json:"queued,omitempty"`
+
+	// LastErrors lists the most recent failure of each schedule whose
+	// last run failed
+	LastErrors []RunError `json:"last_errors,omitempty"`
+}
+
+// ScheduleState is an active schedule of the daemon.
+type ScheduleState struct {
+	Database string    `json:"database"`
+	Schedule string    `json:"schedule"`
+	Cron     string    `json:"cron"`
+	NextRun  time.Time `json:"next_run"`
+	PrevRun  time.Time `json:"prev_run,omitempty"`
+}
+
+// RunState is a backup or restore test in progress.
+type RunState struct {
+	Label     string    `json:"label"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// QueuedState is a job waiting for the daemon's concurrency limits.
+type QueuedState struct {
+	Label    string    `json:"label"`
+	Priority int       `json:"priority,omitempty"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// RunError is the failure of the last run of a schedule.
+type RunError struct {
+	Label string    `json:"label"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// Request is sent by the CLI, one JSON object per connection.
+type Request struct {
+	Command string `json:"command"`
+}
+
+// Response is the daemon's answer to a Request.
+type Response struct {
+	OK     bool         `json:"ok"`
+	Error  string       `json:"error,omitempty"`
+	State  *DaemonState `json:"state,omitempty"`
+	Result string       `json:"result,omitempty"`
+}
+
+// Handler executes control commands inside the daemon.
+type Handler interface {
+	// Status returns the current daemon state
+	Status() *DaemonState
+
+	// Reload reloads the configuration and describes the outcome
+	Reload() (string, error)
+
+	// Stop asks the daemon to shut down
+	Stop()
+}
+
+// DefaultSocketPath returns the default control socket path.
+func DefaultSocketPath() (string, error) {
+	return paths.DataFile("daemon.sock")
+}
+
+// Server serves control requests on a unix socket.
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+}
+
+// Listen creates the control socket at path, readable only by the current
+// user. It fails if another daemon already answers on it; a stale socket
+// left by a crashed daemon is replaced.
+func Listen(path string, handler Handler) (*Server, error) {
+	if _, err := Query(path, CommandStatus); err == nil {
+		return nil, fmt.Errorf("another daemon is already running (socket %s)", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to secure socket: %w", err)
+	}
+
+	return &Server{path: path, listener: listener, handler: handler}, nil
+}
+
+// Serve accepts connections until Close is called.
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops serving and removes the socket.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	var request Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&request); err != nil {
+		json.NewEncoder(conn).Encode(&Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	response := &Response{OK: true}
+	switch request.Command {
+	case CommandStatus:
+		response.State = s.handler.Status()
+	case CommandReload:
+		result, err := s.handler.Reload()
+		response.Result = result
+		if err != nil {
+			response.OK = false
+			response.Error = err.Error()
+		}
+	case CommandStop:
+		response.Result = "stopping"
+		defer s.handler.Stop()
+	default:
+		response.OK = false
+		response.Error = fmt.Sprintf("unknown command %q", request.Command)
+	}
+
+	json.NewEncoder(conn).Encode(response)
+}
+
+// Query sends command to the daemon listening on path. It returns
+// ErrNotRunning when no daemon answers, and the daemon's error when the
+// command failed.
+func Query(path, command string) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	if err := json.NewEncoder(conn).Encode(&Request{Command: command}); err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+
+	var response Response
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if !response.OK {
+		return &response, errors.New(response.Error)
+	}
+	return &response, nil
+}
+
+// GetState returns the state of the daemon listening on path.
+func GetState(path string) (*DaemonState, error) {
+	response, err := Query(path, CommandStatus)
+	if err != nil {
+		return nil, err
+	}
+	return response.State, nil
+}
@@ -0,0 +1,101 @@
+package control
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHandler struct {
+	reloadErr error
+	stopped   chan struct{}
+}
+
+func (h *fakeHandler) Status() *DaemonState {
+	return &DaemonState{
+		PID:       42,
+		Schedules: []ScheduleState{{Database: "shop", Schedule: "default", Cron: "0 2 * * *"}},
+		Running:   []RunState{{Label: "shop"}},
+	}
+}
+
+func (h *fakeHandler) Reload() (string, error) {
+	return "2 schedule change(s)", h.reloadErr
+}
+
+func (h *fakeHandler) Stop() {
+	close(h.stopped)
+}
+
+func startServer(t *testing.T, handler Handler) string {
+	// Unix socket paths are limited in length, so avoid deep temp dirs
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "daemon.sock")
+	server, err := Listen(path, handler)
+	require.NoError(t, err)
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return path
+}
+
+func TestControlSocket(t *testing.T) {
+	handler := &fakeHandler{stopped: make(chan struct{})}
+	path := startServer(t, handler)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	state, err := GetState(path)
+	require.NoError(t, err)
+	assert.Equal(t, 42, state.PID)
+	require.Len(t, state.Schedules, 1)
+	assert.Equal(t, "shop", state.Schedules[0].Database)
+
+	response, err := Query(path, CommandReload)
+	require.NoError(t, err)
+	assert.Equal(t, "2 schedule change(s)", response.Result)
+
+	handler.reloadErr = errors.New("invalid config")
+	_, err = Query(path, CommandReload)
+	assert.EqualError(t, err, "invalid config")
+
+	_, err = Query(path, "explode")
+	assert.Error(t, err)
+
+	_, err = Query(path, CommandStop)
+	require.NoError(t, err)
+	select {
+	case <-handler.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop was not called")
+	}
+
+	// A second daemon must not take over the socket
+	_, err = Listen(path, handler)
+	assert.Error(t, err)
+}
+
+func TestQueryNotRunning(t *testing.T) {
+	_, err := Query(filepath.Join(t.TempDir(), "missing.sock"), CommandStatus)
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	// A stale socket file is replaced
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "daemon.sock")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	server, err := Listen(path, &fakeHandler{})
+	require.NoError(t, err)
+	server.Close()
+}
@@ -0,0 +1,88 @@
+// Package i18n translates the user-facing messages of the CLI.
+//
+// Messages are looked up by their English text, so untranslated messages
+// and unsupported languages fall back to English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Supported languages
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// catalogs maps a language to its translations, keyed by the English text.
+var catalogs = map[string]map[string]string{
+	Indonesian: indonesian,
+}
+
+// current is the language messages are translated to.
+var current = English
+
+// Parse returns the supported language of a locale such as "id",
+// "id_ID.UTF-8" or "en-US", or an error for other languages.
+func Parse(locale string) (string, error) {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case English, "c", "posix":
+		return English, nil
+	case Indonesian, "in":
+		return Indonesian, nil
+	}
+	return "", fmt.Errorf("unsupported language: %s (must be 'en' or 'id')", locale)
+}
+
+// FromEnv returns the language of the locale set by LC_ALL, LC_MESSAGES or
+// LANG, in that order, or English when it is unset or not supported.
+func FromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			if lang, err := Parse(locale); err == nil {
+				return lang
+			}
+			return English
+		}
+	}
+	return English
+}
+
+// SetLanguage sets the language messages are translated to.
+func SetLanguage(lang string) {
+	current = lang
+}
+
+// Language returns the language messages are translated to.
+func Language() string {
+	return current
+}
+
+// T translates msg and formats it with args like fmt.Sprintf.
+func T(msg string, args ...interface{}) string {
+	if translated, ok := catalogs[current][msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Affirmative reports whether a response to a yes/no prompt is yes. The
+// English "y" and "yes" are accepted in every language.
+func Affirmative(response string) bool {
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return true
+	case "ya":
+		return current == Indonesian
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en", English},
+		{"en_US.UTF-8", English},
+		{"C", English},
+		{"POSIX", English},
+		{"id", Indonesian},
+		{"id_ID.UTF-8", Indonesian},
+		{"id-ID", Indonesian},
+		{"in_ID", Indonesian},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			lang, err := Parse(tt.locale)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, lang)
+		})
+	}
+
+	_, err := Parse("fr_FR.UTF-8")
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+	assert.Equal(t, English, FromEnv())
+
+	t.Setenv("LANG", "id_ID.UTF-8")
+	assert.Equal(t, Indonesian, FromEnv())
+
+	// LC_MESSAGES overrides LANG, and an unsupported locale is English
+	t.Setenv("LC_MESSAGES", "de_DE.UTF-8")
+	assert.Equal(t, English, FromEnv())
+
+	t.Setenv("LC_ALL", "id_ID")
+	assert.Equal(t, Indonesian, FromEnv())
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(Language())
+
+	SetLanguage(English)
+	assert.Equal(t, "Restore cancelled", T("Restore cancelled"))
+	assert.Equal(t, "Database 'prod' removed successfully!", T("Database '%s' removed successfully!", "prod"))
+
+	SetLanguage(Indonesian)
+	assert.Equal(t, "Pemulihan dibatalkan", T("Restore cancelled"))
+	assert.Equal(t, "Database 'prod' berhasil dihapus!", T("Database '%s' removed successfully!", "prod"))
+
+	// Untranslated messages fall back to English
+	assert.Equal(t, "Not translated 42", T("Not translated %d", 42))
+}
+
+func TestAffirmative(t *testing.T) {
+	defer SetLanguage(Language())
+
+	SetLanguage(English)
+	assert.True(t, Affirmative("y\n"))
+	assert.True(t, Affirmative(" YES "))
+	assert.False(t, Affirmative("ya"))
+	assert.False(t, Affirmative("n"))
+	assert.False(t, Affirmative(""))
+
+	SetLanguage(Indonesian)
+	assert.True(t, Affirmative("ya"))
+	assert.True(t, Affirmative("y"))
+	assert.False(t, Affirmative("tidak"))
+}
+
+func TestCatalogVerbs(t *testing.T) {
+	// Translations must keep the verbs of the English text, in order, or
+	// their arguments would be formatted wrongly
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			assert.Equal(t, verbs.FindAllString(msg, -1), verbs.FindAllString(translated, -1),
+				"%s translation of %q", lang, msg)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package i18n
+
+// indonesian holds the Bahasa Indonesia translations.
+var indonesian = map[string]string{
+	// Prompts and confirmations
+	"Continue? [y/N]: ":        "Lanjutkan? [y/N]: ",
+	"Are you sure? (yes/no): ": "Anda yakin? (ya/tidak): ",
+	"Enter password: ":         "Masukkan kata sandi: ",
+	"Enter new password: ":     "Masukkan kata sandi baru: ",
+	"Cancelled":                "Dibatalkan",
+	"Restore cancelled":        "Pemulihan dibatalkan",
+	"Import cancelled":         "Impor dibatalkan",
+
+	// Strict protection
+	"restore":                         "pemulihan",
+	"import":                          "impor",
+	"removal":                         "penghapusan",
+	"cleanup":                         "pembersihan",
+	"'%s' has strict protection":      "'%s' memiliki proteksi ketat",
+	"Type %s%s%s to confirm the %s: ": "Ketik %s%s%s untuk mengonfirmasi %s: ",
+	"%s cancelled: --confirm=%q does not match the protected database %q":           "%s dibatalkan: --confirm=%q tidak cocok dengan database terproteksi %q",
+	"%s of '%s' requires confirmation: it has strict protection; pass --confirm %s": "%s '%s' memerlukan konfirmasi: database ini memiliki proteksi ketat; gunakan --confirm %s",
+	"%s cancelled: the name typed does not match '%s'":                              "%s dibatalkan: nama yang diketik tidak cocok dengan '%s'",
+
+	// Configuration
+	"Database not found":                  "Database tidak ditemukan",
+	"No databases configured":             "Belum ada database yang dikonfigurasi",
+	"Saving configuration...":             "Menyimpan konfigurasi...",
+	"Failed to save configuration":        "Gagal menyimpan konfigurasi",
+	"Removing configuration...":           "Menghapus konfigurasi...",
+	"Failed to remove configuration":      "Gagal menghapus konfigurasi",
+	"Database '%s' removed successfully!": "Database '%s' berhasil dihapus!",
+	"Encrypting password...":              "Mengenkripsi kata sandi...",
+	"Failed to encrypt password":          "Gagal mengenkripsi kata sandi",
+	"Failed to get password":              "Gagal mendapatkan kata sandi",
+
+	// Connections and storage
+	"Connection failed":              "Koneksi gagal",
+	"Failed to create MySQL client":  "Gagal membuat klien MySQL",
+	"Failed to create backup client": "Gagal membuat klien backup",
+	"Failed to create storage":       "Gagal membuat penyimpanan",
+
+	// Backup
+	"Starting backup...": "Memulai pencadangan...",
+	"Backup completed!":  "Pencadangan selesai!",
+	"Backup failed":      "Pencadangan gagal",
+
+	// Restore and import
+	"WARNING: This will restore the database":                "PERINGATAN: Ini akan memulihkan database",
+	"WARNING: This will import data into the database":       "PERINGATAN: Ini akan mengimpor data ke database",
+	"Database exists - data will be overwritten":             "Database sudah ada - data akan ditimpa",
+	"Database exists - data may be overwritten":              "Database sudah ada - data mungkin ditimpa",
+	"Database will be created":                               "Database akan dibuat",
+	"Use --create-db to create the database":                 "Gunakan --create-db untuk membuat database",
+	"Dry-run mode: Validation only, no changes will be made": "Mode dry-run: hanya validasi, tidak ada perubahan yang dibuat",
+	"Validation passed! Use without --dry-run to restore.":   "Validasi berhasil! Jalankan tanpa --dry-run untuk memulihkan.",
+	"Failed to create safety backup":                         "Gagal membuat backup pengaman",
+	"Aborting restore to prevent data loss":                  "Pemulihan dihentikan untuk mencegah kehilangan data",
+	"Starting restore...":                                    "Memulai pemulihan...",
+	"Restore completed!":                                     "Pemulihan selesai!",
+	"Restore failed":                                         "Pemulihan gagal",
+	"Starting import...":                                     "Memulai impor...",
+	"Import completed!":                                      "Impor selesai!",
+	"Import failed":                                          "Impor gagal",
+
+	// Cleanup
+	"No backups to delete":                           "Tidak ada backup yang perlu dihapus",
+	"Run without --dry-run to delete these backups.": "Jalankan tanpa --dry-run untuk menghapus backup ini.",
+
+	// Maintenance mode
+	"Maintenance mode is on":          "Mode pemeliharaan aktif",
+	"Maintenance mode is off":         "Mode pemeliharaan nonaktif",
+	"Maintenance mode is already off": "Mode pemeliharaan sudah nonaktif",
+
+	// Errors
+	"Error: %v\n": "Kesalahan: %v\n",
+}
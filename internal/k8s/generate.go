@@ -0,0 +1,239 @@
+// Package k8s generates Kubernetes manifests that run scheduled backups
+// in-cluster as a CronJob.
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+)
+
+// Defaults of the generated manifests
+const (
+	DefaultNamespace   = "default"
+	DefaultImage       = "cadangkan:latest"
+	DefaultStorageSize = "10Gi"
+)
+
+// Paths inside the backup container
+const (
+	secretMountPath = "/var/run/secrets/cadangkan"
+	backupMountPath = "/backups"
+)
+
+// Options describes the manifests to generate.
+type Options struct {
+	// Name is the configured database name
+	Name string
+
+	// Database holds the connection settings
+	Database *config.DatabaseConfig
+
+	// Password is stored in the generated Secret
+	Password string
+
+	// Schedule supplies the cron expression, timezone and backup options
+	Schedule *config.ScheduleConfig
+
+	// Namespace of all resources (default: DefaultNamespace)
+	Namespace string
+
+	// Image runs the cadangkan binary (default: DefaultImage)
+	Image string
+
+	// ClaimName is an existing PersistentVolumeClaim receiving the backups;
+	// when empty, a claim of StorageSize is generated
+	ClaimName string
+
+	// StorageSize of the generated claim (default: DefaultStorageSize)
+	StorageSize string
+}
+
+// invalidNameChars matches characters not allowed in resource names.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ResourceName returns the name of the resources generated for a database,
+// a valid Kubernetes name derived from its configured name.
+func ResourceName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 40 {
+		name = strings.TrimRight(name[:40], "-")
+	}
+	return "cadangkan-" + name
+}
+
+// manifest is the data of manifestTemplate.
+type manifest struct {
+	Options
+	Resource   string
+	Cron       string
+	Args       []string
+	SecretPath string
+	BackupPath string
+	CreatePVC  bool
+}
+
+var manifestTemplate = template.Must(template.New("manifest").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# Generated by cadangkan k8s generate {{.Name}}
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{.Resource}}
+  namespace: {{.Namespace}}
+type: Opaque
+stringData:
+  password: {{quote .Password}}
+{{- if .CreatePVC}}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{.ClaimName}}
+  namespace: {{.Namespace}}
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: {{.StorageSize}}
+{{- end}}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{.Resource}}
+  namespace: {{.Namespace}}
+spec:
+  schedule: {{quote .Cron}}
+{{- if .Schedule.Timezone}}
+  timeZone: {{quote .Schedule.Timezone}}
+{{- end}}
+  concurrencyPolicy: Forbid
+  successfulJobsHistoryLimit: 3
+  failedJobsHistoryLimit: 3
+  jobTemplate:
+    spec:
+      backoffLimit: 1
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+            - name: cadangkan
+              image: {{.Image}}
+              args:
+{{- range .Args}}
+                - {{quote .}}
+{{- end}}
+              env:
+                - name: CADANGKAN_HOST
+                  value: {{quote .Database.Host}}
+                - name: CADANGKAN_PORT
+                  value: "{{.Database.Port}}"
+                - name: CADANGKAN_USER
+                  value: {{quote .Database.User}}
+                - name: CADANGKAN_DATABASE
+                  value: {{quote .Database.Database}}
+                - name: CADANGKAN_PASSWORD_FILE
+                  value: {{.SecretPath}}/password
+              volumeMounts:
+                - name: credentials
+                  mountPath: {{.SecretPath}}
+                  readOnly: true
+                - name: backups
+                  mountPath: {{.BackupPath}}
+          volumes:
+            - name: credentials
+              secret:
+                secretName: {{.Resource}}
+                defaultMode: 0400
+            - name: backups
+              persistentVolumeClaim:
+                claimName: {{.ClaimName}}
+`))
+
+// Generate writes a Secret holding the password, a PersistentVolumeClaim
+// unless opts names an existing one, and a CronJob running
+// 'cadangkan backup' on the schedule of opts.
+func Generate(w io.Writer, opts *Options) error {
+	if opts.Database == nil {
+		return fmt.Errorf("database settings are required")
+	}
+	if opts.Schedule == nil || opts.Schedule.Cron == "" {
+		return fmt.Errorf("a cron schedule is required")
+	}
+	cron := strings.TrimSpace(opts.Schedule.Cron)
+	if !strings.HasPrefix(cron, "@") && len(strings.Fields(cron)) != 5 {
+		return fmt.Errorf("a Kubernetes CronJob needs a five-field cron expression, got %q", cron)
+	}
+
+	data := manifest{
+		Options:    *opts,
+		Resource:   ResourceName(opts.Name),
+		Cron:       cron,
+		Args:       backupArgs(opts),
+		SecretPath: secretMountPath,
+		BackupPath: backupMountPath,
+	}
+	if data.Namespace == "" {
+		data.Namespace = DefaultNamespace
+	}
+	if data.Image == "" {
+		data.Image = DefaultImage
+	}
+	if data.ClaimName == "" {
+		data.ClaimName = data.Resource + "-backups"
+		data.CreatePVC = true
+		if data.StorageSize == "" {
+			data.StorageSize = DefaultStorageSize
+		}
+	}
+
+	return manifestTemplate.Execute(w, data)
+}
+
+// backupArgs returns the arguments of 'cadangkan backup' applying the
+// backup options of the schedule and database. Connection settings are
+// passed through the environment.
+func backupArgs(opts *Options) []string {
+	args := []string{"backup", "--output=" + backupMountPath}
+	if opts.Database.Type != "" {
+		args = append(args, "--type="+opts.Database.Type)
+	}
+
+	schedule := opts.Schedule
+	if schedule.SchemaOnly {
+		args = append(args, "--schema-only")
+	}
+	if len(schedule.Tables) > 0 {
+		args = append(args, "--tables="+strings.Join(schedule.Tables, ","))
+	}
+	if len(schedule.ExcludeTables) > 0 {
+		args = append(args, "--exclude-tables="+strings.Join(schedule.ExcludeTables, ","))
+	}
+	if schedule.Compression != "" {
+		args = append(args, "--compression="+schedule.Compression)
+	}
+
+	if dump := opts.Database.Mysqldump; dump != nil {
+		for _, arg := range dump.ExtraArgs {
+			args = append(args, "--mysqldump-arg="+arg)
+		}
+		for _, flag := range dump.DisableDefaults {
+			args = append(args, "--mysqldump-disable="+flag)
+		}
+		if dump.Definers != "" {
+			args = append(args, "--definers="+dump.Definers)
+		}
+		if dump.SQLSecurity == "invoker" {
+			args = append(args, "--sql-security-invoker")
+		}
+	}
+	return args
+}
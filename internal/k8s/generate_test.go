@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func decodeAll(t *testing.T, data []byte) []map[string]interface{} {
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs
+		}
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	opts := &Options{
+		Name: "shop_prod",
+		Database: &config.DatabaseConfig{
+			Type:      "mysql",
+			Host:      "mysql.shop.svc",
+			Port:      3306,
+			User:      "backup",
+			Database:  "shop",
+			Mysqldump: &config.MysqldumpConfig{ExtraArgs: []string{"--hex-blob"}, Definers: "strip"},
+		},
+		Password: `p"a:ss`,
+		Schedule: &config.ScheduleConfig{
+			Cron:          "0 2 * * *",
+			Timezone:      "Asia/Jakarta",
+			ExcludeTables: []string{"sessions", "logs"},
+		},
+		Namespace: "shop",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, opts))
+	docs := decodeAll(t, buf.Bytes())
+	require.Len(t, docs, 3)
+
+	secret, pvc, cronJob := docs[0], docs[1], docs[2]
+	assert.Equal(t, "Secret", secret["kind"])
+	assert.Equal(t, `p"a:ss`, secret["stringData"].(map[string]interface{})["password"])
+	assert.Equal(t, "PersistentVolumeClaim", pvc["kind"])
+	assert.Equal(t, "cadangkan-shop-prod-backups", pvc["metadata"].(map[string]interface{})["name"])
+
+	assert.Equal(t, "CronJob", cronJob["kind"])
+	assert.Equal(t, "shop", cronJob["metadata"].(map[string]interface{})["namespace"])
+	spec := cronJob["spec"].(map[string]interface{})
+	assert.Equal(t, "0 2 * * *", spec["schedule"])
+	assert.Equal(t, "Asia/Jakarta", spec["timeZone"])
+
+	podSpec := spec["jobTemplate"].(map[string]interface{})["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, DefaultImage, container["image"])
+	assert.Equal(t, []interface{}{
+		"backup", "--output=/backups", "--type=mysql", "--exclude-tables=sessions,logs", "--mysqldump-arg=--hex-blob",
+		"--definers=strip",
+	}, container["args"])
+
+	env := make(map[string]interface{})
+	for _, item := range container["env"].([]interface{}) {
+		variable := item.(map[string]interface{})
+		env[variable["name"].(string)] = variable["value"]
+	}
+	assert.Equal(t, "mysql.shop.svc", env["CADANGKAN_HOST"])
+	assert.Equal(t, "3306", env["CADANGKAN_PORT"])
+	assert.Equal(t, "/var/run/secrets/cadangkan/password", env["CADANGKAN_PASSWORD_FILE"])
+	assert.NotContains(t, buf.String(), "CADANGKAN_PASSWORD\n")
+}
+
+func TestGenerateExistingClaim(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, &Options{
+		Name:      "shop",
+		Database:  &config.DatabaseConfig{Host: "db", Port: 3306},
+		Schedule:  &config.ScheduleConfig{Cron: "@daily"},
+		ClaimName: "backups",
+	}))
+
+	docs := decodeAll(t, buf.Bytes())
+	require.Len(t, docs, 2)
+	assert.NotContains(t, buf.String(), "timeZone")
+	assert.Contains(t, buf.String(), "claimName: backups")
+}
+
+func TestGenerateRejectsSeconds(t *testing.T) {
+	err := Generate(io.Discard, &Options{
+		Name:     "shop",
+		Database: &config.DatabaseConfig{},
+		Schedule: &config.ScheduleConfig{Cron: "*/30 * * * * *"},
+	})
+	assert.Error(t, err)
+
+	err = Generate(io.Discard, &Options{Name: "shop", Database: &config.DatabaseConfig{}})
+	assert.Error(t, err)
+}
+
+func TestResourceName(t *testing.T) {
+	assert.Equal(t, "cadangkan-shop-prod", ResourceName("shop_prod"))
+	assert.Equal(t, "cadangkan-my-db", ResourceName("My.DB"))
+}
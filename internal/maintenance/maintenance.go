@@ -0,0 +1,117 @@
+// Package maintenance implements the read-only maintenance mode, which
+// blocks restores and deletions while still allowing backups.
+package maintenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/fsutil"
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// State describes maintenance mode while it is on.
+type State struct {
+	// Since is when maintenance mode was turned on
+	Since time.Time `json:"since"`
+
+	// User turned maintenance mode on
+	User string `json:"user"`
+
+	// Reason is why maintenance mode is on, if given
+	Reason string `json:"reason,omitempty"`
+}
+
+// BlockedError is returned for operations blocked by maintenance mode.
+type BlockedError struct {
+	Operation string
+	State     *State
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("%s blocked: maintenance mode is on since %s (by %s",
+		e.Operation, e.State.Since.Local().Format("2006-01-02 15:04"), e.State.User)
+	if e.State.Reason != "" {
+		msg += ": " + e.State.Reason
+	}
+	return msg + "); turn it off with 'cadangkan maintenance off'"
+}
+
+// DefaultPath returns the default maintenance state file path.
+func DefaultPath() (string, error) {
+	return paths.DataFile("maintenance.json")
+}
+
+// Load returns the maintenance state stored at path, or nil when
+// maintenance mode is off.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Enable turns maintenance mode on, recording the current user and reason.
+func Enable(path, reason string) (*State, error) {
+	state := &State{
+		Since:  time.Now(),
+		User:   audit.CurrentUser(),
+		Reason: reason,
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode maintenance state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write maintenance state: %w", err)
+	}
+	return state, nil
+}
+
+// Disable turns maintenance mode off.
+func Disable(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove maintenance state: %w", err)
+	}
+	return nil
+}
+
+// Check returns a *BlockedError for operation when maintenance mode is on.
+// A state file that cannot be read also blocks, so a damaged file never
+// silently allows destructive operations.
+func Check(operation string) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	return CheckAt(path, operation)
+}
+
+// CheckAt is Check with the state stored at path.
+func CheckAt(path, operation string) error {
+	state, err := Load(path)
+	if err != nil {
+		return fmt.Errorf("%s blocked: %w", operation, err)
+	}
+	if state != nil {
+		return &BlockedError{Operation: operation, State: state}
+	}
+	return nil
+}
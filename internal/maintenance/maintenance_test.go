@@ -0,0 +1,46 @@
+package maintenance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+
+	state, err := Load(path)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+	assert.NoError(t, CheckAt(path, "restore"))
+
+	enabled, err := Enable(path, "incident 42")
+	require.NoError(t, err)
+	assert.NotEmpty(t, enabled.User)
+
+	state, err = Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "incident 42", state.Reason)
+
+	err = CheckAt(path, "restore")
+	var blocked *BlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, "restore", blocked.Operation)
+	assert.Contains(t, err.Error(), "incident 42")
+
+	require.NoError(t, Disable(path))
+	assert.NoError(t, CheckAt(path, "restore"))
+	assert.NoError(t, Disable(path))
+}
+
+func TestCheckDamagedState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	assert.Error(t, CheckAt(path, "cleanup"))
+}
@@ -0,0 +1,61 @@
+// Package notify sends reports to people by email.
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+)
+
+// Email is a plain text message.
+type Email struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+	Date    time.Time
+}
+
+// Bytes returns the message in RFC 5322 format, with CRLF line endings.
+func (e *Email) Bytes() []byte {
+	date := e.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", e.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(e.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// Send delivers the email through the configured mail server, using
+// STARTTLS when the server offers it and logging in when a username is set.
+func Send(cfg *config.SMTPConfig, email *Email) error {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		password, err := cfg.Password()
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", cfg.Username, password, cfg.Host)
+	}
+
+	if err := smtp.SendMail(cfg.Address(), auth, email.From, email.To, email.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
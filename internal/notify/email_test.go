@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailBytes(t *testing.T) {
+	email := &Email{
+		From:    "cadangkan@example.com",
+		To:      []string{"ops@example.com", "dba@example.com"},
+		Subject: "Backup digest",
+		Body:    "line one\nline two\n",
+		Date:    time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC),
+	}
+
+	got := string(email.Bytes())
+	for _, want := range []string{
+		"From: cadangkan@example.com\r\n",
+		"To: ops@example.com, dba@example.com\r\n",
+		"Subject: Backup digest\r\n",
+		"Date: Wed, 15 Jan 2025 08:00:00 +0000\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailBytesEncodesSubject(t *testing.T) {
+	email := &Email{Subject: "Ringkasan cadangan — harian"}
+	if got := string(email.Bytes()); !strings.Contains(got, "Subject: =?utf-8?q?") {
+		t.Errorf("non-ASCII subject not encoded:\n%s", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package paths locates the files cadangkan keeps on disk.
+//
+// Configuration (config.yaml and the encryption key) lives in the config
+// directory, everything else (backups, the audit log, daemon state) in the
+// data directory. Both follow the XDG base directory specification:
+// $XDG_CONFIG_HOME/cadangkan and $XDG_DATA_HOME/cadangkan, by default
+// ~/.config/cadangkan and ~/.local/share/cadangkan. CADANGKAN_HOME puts
+// both in one directory instead, as system-wide installs do.
+//
+// Older versions kept everything in ~/.cadangkan. As long as that
+// directory exists it is used for both, until Migrate moves its files.
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables that choose the directories
+const (
+	// EnvHome is the directory for all files, overriding the XDG
+	// directories
+	EnvHome = "CADANGKAN_HOME"
+
+	envConfigHome = "XDG_CONFIG_HOME"
+	envDataHome   = "XDG_DATA_HOME"
+)
+
+// appName is the name of the cadangkan directories in the XDG directories.
+const appName = "cadangkan"
+
+// configFiles are the files of the legacy directory that belong in the
+// config directory; the rest is data.
+var configFiles = map[string]bool{
+	"config.yaml": true,
+	".key":        true,
+}
+
+// ConfigDir returns the directory holding config.yaml and the encryption
+// key.
+func ConfigDir() (string, error) {
+	return dir(envConfigHome, ".config")
+}
+
+// DataDir returns the directory holding the backups, the audit log and the
+// state of the daemon.
+func DataDir() (string, error) {
+	return dir(envDataHome, filepath.Join(".local", "share"))
+}
+
+// ConfigFile returns the path of a file in the config directory.
+func ConfigFile(name string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// DataFile returns the path of a file in the data directory.
+func DataFile(name string) (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// LegacyDir returns ~/.cadangkan, the directory older versions kept all
+// files in.
+func LegacyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".cadangkan"), nil
+}
+
+// dir returns CADANGKAN_HOME, the legacy directory while it exists, or the
+// cadangkan directory in the XDG directory named by env, which defaults to
+// fallback in the home directory.
+func dir(env, fallback string) (string, error) {
+	if home := os.Getenv(EnvHome); home != "" {
+		return home, nil
+	}
+	if legacy, ok := existingLegacyDir(); ok {
+		return legacy, nil
+	}
+	return xdgDir(env, fallback)
+}
+
+// xdgDir returns the cadangkan directory in the XDG directory named by env.
+// Relative paths are ignored, as the specification requires.
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, appName), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, fallback, appName), nil
+}
+
+// existingLegacyDir returns the legacy directory if it exists.
+func existingLegacyDir() (string, bool) {
+	legacy, err := LegacyDir()
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(legacy)
+	return legacy, err == nil && info.IsDir()
+}
+
+// Migrate moves the files of the legacy directory ~/.cadangkan to the XDG
+// config and data directories and removes it. It does nothing when
+// CADANGKAN_HOME is set or there is no legacy directory, and returns
+// whether files were moved.
+//
+// The legacy directory is left alone while the daemon is running in it, or
+// when one of pinned (paths set in config.yaml) lies inside it. Files are
+// renamed, so nothing is copied; if one cannot be moved, for example
+// because the XDG directory is on another filesystem, the files already
+// moved are moved back and the legacy directory stays in use.
+func Migrate(pinned []string) (bool, error) {
+	if os.Getenv(EnvHome) != "" {
+		return false, nil
+	}
+	legacy, ok := existingLegacyDir()
+	if !ok {
+		return false, nil
+	}
+
+	for _, path := range pinned {
+		if path != "" && within(legacy, path) {
+			return false, fmt.Errorf("%s is configured inside %s; move it or set %s=%s", path, legacy, EnvHome, legacy)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "daemon.sock")); err == nil {
+		return false, errors.New("the daemon is running; stop it to move its files")
+	}
+
+	configDir, err := xdgDir(envConfigHome, ".config")
+	if err != nil {
+		return false, err
+	}
+	dataDir, err := xdgDir(envDataHome, filepath.Join(".local", "share"))
+	if err != nil {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return false, err
+	}
+	type move struct{ from, to string }
+	moves := make([]move, 0, len(entries))
+	for _, entry := range entries {
+		dest := dataDir
+		if configFiles[entry.Name()] {
+			dest = configDir
+		}
+		to := filepath.Join(dest, entry.Name())
+		if _, err := os.Lstat(to); err == nil {
+			return false, fmt.Errorf("both %s and %s exist", filepath.Join(legacy, entry.Name()), to)
+		}
+		moves = append(moves, move{filepath.Join(legacy, entry.Name()), to})
+	}
+
+	for _, dir := range []string{configDir, dataDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return false, err
+		}
+	}
+	for i, m := range moves {
+		if err := os.Rename(m.from, m.to); err != nil {
+			for _, done := range moves[:i] {
+				os.Rename(done.to, done.from)
+			}
+			return false, fmt.Errorf("failed to move %s: %w", m.from, err)
+		}
+	}
+
+	if err := os.Remove(legacy); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// within reports whether path is dir or lies inside it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
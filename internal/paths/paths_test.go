@@ -0,0 +1,117 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setHome points the home directory at a temporary directory and clears
+// the variables that choose the cadangkan directories.
+func setHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvHome, "")
+	t.Setenv(envConfigHome, "")
+	t.Setenv(envDataHome, "")
+	return home
+}
+
+func TestDirs(t *testing.T) {
+	home := setHome(t)
+
+	configDir, err := ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "cadangkan"), configDir)
+	dataDir, err := DataDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "share", "cadangkan"), dataDir)
+
+	t.Setenv(envConfigHome, "/etc/xdg")
+	t.Setenv(envDataHome, "relative/data")
+	configFile, err := ConfigFile("config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/xdg/cadangkan/config.yaml", configFile)
+	dataDir, err = DataDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "share", "cadangkan"), dataDir, "relative XDG paths are ignored")
+
+	// An existing ~/.cadangkan wins over the XDG directories
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".cadangkan"), 0700))
+	configDir, err = ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cadangkan"), configDir)
+
+	t.Setenv(EnvHome, "/var/lib/cadangkan")
+	dataFile, err := DataFile("backups")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/cadangkan/backups", dataFile)
+	configDir, err = ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/cadangkan", configDir)
+}
+
+func TestMigrate(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".cadangkan")
+
+	moved, err := Migrate(nil)
+	require.NoError(t, err)
+	assert.False(t, moved, "nothing to migrate")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(legacy, "backups", "shop"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "backups", "shop", "2025-01-15-020000.sql.gz"), []byte("dump"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("version: \"1\"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, ".key"), []byte("key"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "audit.log"), nil, 0600))
+
+	// A path from config.yaml inside the legacy directory keeps it in use
+	_, err = Migrate([]string{"/srv/backups", filepath.Join(legacy, "backups")})
+	assert.Error(t, err)
+	assert.DirExists(t, legacy)
+
+	moved, err = Migrate([]string{"/srv/backups", filepath.Join(home, ".cadangkan-old")})
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.NoDirExists(t, legacy)
+
+	assert.FileExists(t, filepath.Join(home, ".config", "cadangkan", "config.yaml"))
+	assert.FileExists(t, filepath.Join(home, ".config", "cadangkan", ".key"))
+	assert.FileExists(t, filepath.Join(home, ".local", "share", "cadangkan", "audit.log"))
+	assert.FileExists(t, filepath.Join(home, ".local", "share", "cadangkan", "backups", "shop", "2025-01-15-020000.sql.gz"))
+
+	dataDir, err := DataDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "share", "cadangkan"), dataDir)
+}
+
+func TestMigrateConflict(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".cadangkan")
+	dataDir := filepath.Join(home, ".local", "share", "cadangkan")
+
+	require.NoError(t, os.MkdirAll(legacy, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "config.yaml"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "audit.log"), nil, 0600))
+	require.NoError(t, os.MkdirAll(dataDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "audit.log"), nil, 0600))
+
+	_, err := Migrate(nil)
+	assert.Error(t, err)
+	assert.FileExists(t, filepath.Join(legacy, "config.yaml"), "nothing is moved")
+	assert.NoFileExists(t, filepath.Join(home, ".config", "cadangkan", "config.yaml"))
+}
+
+func TestMigrateSkippedWithCadangkanHome(t *testing.T) {
+	home := setHome(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".cadangkan"), 0700))
+	t.Setenv(EnvHome, filepath.Join(home, "cadangkan"))
+
+	moved, err := Migrate(nil)
+	require.NoError(t, err)
+	assert.False(t, moved)
+	assert.DirExists(t, filepath.Join(home, ".cadangkan"))
+}
@@ -0,0 +1,250 @@
+// Package plugin runs external programs on backup and restore events, so
+// custom storage targets and notifiers can be added without changing
+// cadangkan.
+//
+// A plugin is any executable. For each call it is started with a JSON
+// request on stdin and may answer with a JSON response on stdout; exiting
+// non-zero fails the call. A plugin that uploads backups receives
+// "backup.completed" with the path of the backup file, for example:
+//
+//	{"protocol":1,"hook":"backup.completed","event":{"database":"production","backup_id":"2025-01-15-143022","file":"/home/me/.cadangkan/backups/production/2025-01-15-143022.sql.gz",...}}
+//
+// and answers
+//
+//	{"message":"uploaded to s3://backups/production/2025-01-15-143022.sql.gz"}
+//
+// The "describe" hook asks a plugin for its Info, shown by
+// 'cadangkan plugins list'.
+//
+// A plugin's args are text/template templates rendered with the hook's
+// TemplateData. A plugin with an input template gets that rendered on stdin
+// instead of the JSON request, and its stdout is ignored, so programs such
+// as curl can post a message without a wrapper script.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+)
+
+// ProtocolVersion is the version of the plugin protocol, sent with every
+// request.
+const ProtocolVersion = 1
+
+// HookDescribe asks a plugin to describe itself.
+const HookDescribe = "describe"
+
+// Plugin kinds, as reported by plugins in their Info
+const (
+	KindStorage  = "storage"
+	KindNotifier = "notifier"
+)
+
+// killWait is how long output is still read from a plugin after it is
+// killed on timeout, in case processes it started keep its pipes open.
+const killWait = time.Second
+
+// maxStderr is how much of a failing plugin's stderr is kept in its error.
+const maxStderr = 1024
+
+// Request is written to a plugin's stdin.
+type Request struct {
+	Protocol int    `json:"protocol"`
+	Hook     string `json:"hook"`
+	Event    *Event `json:"event,omitempty"`
+}
+
+// Event describes the backup or restore a hook fired for.
+type Event struct {
+	Time            time.Time `json:"time"`
+	Database        string    `json:"database"`
+	BackupID        string    `json:"backup_id,omitempty"`
+	File            string    `json:"file,omitempty"` // Backup file, for backup.completed
+	SizeBytes       int64     `json:"size_bytes,omitempty"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	Checksum        string    `json:"checksum,omitempty"`
+	TargetDatabase  string    `json:"target_database,omitempty"` // For restores
+	Error           string    `json:"error,omitempty"`           // For failures
+}
+
+// Response is read from a plugin's stdout. It is optional; a plugin that
+// prints nothing and exits zero succeeded.
+type Response struct {
+	Message string `json:"message,omitempty"` // Shown to the user
+	Error   string `json:"error,omitempty"`   // Fails the call
+}
+
+// Info is a plugin's answer to the describe hook.
+type Info struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version,omitempty"`
+	Kind        string   `json:"kind,omitempty"` // "storage" or "notifier"
+	Description string   `json:"description,omitempty"`
+	Hooks       []string `json:"hooks,omitempty"` // Hooks the plugin handles
+}
+
+// Plugin is a configured plugin.
+type Plugin struct {
+	Name   string
+	Config *config.PluginConfig
+}
+
+// Describe asks the plugin for its Info. A plugin with an input template
+// does not speak the protocol and is described by its config.
+func (p *Plugin) Describe() (*Info, error) {
+	if p.Config.Input != "" {
+		return &Info{Name: p.Name, Kind: KindNotifier, Hooks: p.Config.Hooks}, nil
+	}
+	var info Info
+	if err := p.run(Request{Protocol: ProtocolVersion, Hook: HookDescribe}, &info); err != nil {
+		return nil, err
+	}
+	if info.Name == "" {
+		info.Name = p.Name
+	}
+	return &info, nil
+}
+
+// Call runs the plugin for a hook and returns its message, if any.
+func (p *Plugin) Call(hook string, event *Event) (string, error) {
+	if p.Config.Input != "" {
+		input, err := render("input", p.Config.Input, NewTemplateData(hook, event))
+		if err != nil {
+			return "", err
+		}
+		_, err = p.exec(hook, event, []byte(input))
+		return "", err
+	}
+
+	var resp Response
+	if err := p.run(Request{Protocol: ProtocolVersion, Hook: hook, Event: event}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return resp.Message, nil
+}
+
+// run sends req to the plugin and decodes its response into resp.
+func (p *Plugin) run(req Request, resp interface{}) error {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	stdout, err := p.exec(req.Hook, req.Event, input)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stdout, resp); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// exec runs the plugin with its args rendered for the hook and input on
+// stdin, and returns its stdout.
+func (p *Plugin) exec(hook string, event *Event, input []byte) ([]byte, error) {
+	timeout, err := p.Config.TimeoutDuration()
+	if err != nil {
+		return nil, err
+	}
+	data := NewTemplateData(hook, event)
+	args := make([]string, len(p.Config.Args))
+	for i, arg := range p.Config.Args {
+		if args[i], err = render("args", arg, data); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Config.Command, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = killWait
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		if msg := lastLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the last non-empty line of a plugin's stderr, where it
+// is expected to explain why it failed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if len(line) > maxStderr {
+		line = line[:maxStderr]
+	}
+	return line
+}
+
+// Result is the outcome of calling one plugin for a hook.
+type Result struct {
+	Plugin  string
+	Message string
+	Err     error
+}
+
+// Registry holds the configured plugins.
+type Registry struct {
+	plugins []*Plugin
+}
+
+// NewRegistry returns a registry of the configured plugins, sorted by name.
+func NewRegistry(plugins map[string]*config.PluginConfig) *Registry {
+	r := &Registry{}
+	for name, cfg := range plugins {
+		r.plugins = append(r.plugins, &Plugin{Name: name, Config: cfg})
+	}
+	sort.Slice(r.plugins, func(i, j int) bool {
+		return r.plugins[i].Name < r.plugins[j].Name
+	})
+	return r
+}
+
+// Plugins returns the plugins in the registry.
+func (r *Registry) Plugins() []*Plugin {
+	if r == nil {
+		return nil
+	}
+	return r.plugins
+}
+
+// Dispatch calls every plugin that receives hook, one after another, and
+// returns their results. A failing plugin does not stop the others.
+func (r *Registry) Dispatch(hook string, event *Event) []Result {
+	var results []Result
+	for _, p := range r.Plugins() {
+		if !p.Config.Receives(hook) {
+			continue
+		}
+		message, err := p.Call(hook, event)
+		results = append(results, Result{Plugin: p.Name, Message: message, Err: err})
+	}
+	return results
+}
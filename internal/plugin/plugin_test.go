@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeScript writes an executable shell script and returns its path.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755))
+	return path
+}
+
+func TestCall(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+	script := writeScript(t, `cat > "$1"
+echo '{"message":"uploaded"}'
+`)
+	p := &Plugin{Name: "s3", Config: &config.PluginConfig{Command: script, Args: []string{requestFile}}}
+
+	message, err := p.Call(config.HookBackupCompleted, &Event{Database: "production", BackupID: "2025-01-15-143022"})
+	require.NoError(t, err)
+	assert.Equal(t, "uploaded", message)
+
+	request, err := os.ReadFile(requestFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(request), `"protocol":1`)
+	assert.Contains(t, string(request), `"hook":"backup.completed"`)
+	assert.Contains(t, string(request), `"backup_id":"2025-01-15-143022"`)
+}
+
+func TestCallNoResponse(t *testing.T) {
+	p := &Plugin{Name: "quiet", Config: &config.PluginConfig{Command: writeScript(t, "cat > /dev/null\n")}}
+
+	message, err := p.Call(config.HookBackupStarted, &Event{Database: "production"})
+	require.NoError(t, err)
+	assert.Empty(t, message)
+}
+
+func TestCallErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout string
+		want    string
+	}{
+		{"exit status", "echo 'bucket not found' >&2\nexit 1\n", "", "bucket not found"},
+		{"error response", `echo '{"error":"access denied"}'` + "\n", "", "access denied"},
+		{"invalid response", "echo not json\n", "", "invalid response"},
+		{"timeout", "exec sleep 5\n", "100ms", "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{Name: "s3", Config: &config.PluginConfig{Command: writeScript(t, tt.script), Timeout: tt.timeout}}
+			_, err := p.Call(config.HookBackupCompleted, &Event{Database: "production"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCallTemplates(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "input.json")
+	script := writeScript(t, `cat > "$2"
+echo "$1" > "$2.args"
+echo "ok"
+`)
+	p := &Plugin{Name: "chat", Config: &config.PluginConfig{
+		Command: script,
+		Args:    []string{"{{.Database}}-{{.Status}}", inputFile},
+		Input:   `{"text": "Backup {{.BackupID}} of {{.Database}} {{.Status}}: {{.SizeHuman}} in {{.Duration}}"}`,
+	}}
+
+	message, err := p.Call(config.HookBackupCompleted, &Event{
+		Database:        "production",
+		BackupID:        "2025-01-15-143022",
+		SizeBytes:       52428800,
+		DurationSeconds: 125,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, message)
+
+	input, err := os.ReadFile(inputFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"text": "Backup 2025-01-15-143022 of production completed: 50.0 MB in 2m 5s"}`, string(input))
+
+	args, err := os.ReadFile(inputFile + ".args")
+	require.NoError(t, err)
+	assert.Equal(t, "production-completed\n", string(args))
+
+	p.Config.Input = "{{.Size}}"
+	_, err = p.Call(config.HookBackupCompleted, &Event{Database: "production"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to render input")
+}
+
+func TestRenderJSON(t *testing.T) {
+	input := `{"text": "Backup of *{{json .Database}}* {{.Status}}{{if .Error}}: {{json .Error}}{{else}} ({{.SizeHuman}}){{end}}"}`
+	data := NewTemplateData(config.HookBackupFailed, &Event{
+		Database: "prod",
+		Error:    "exit status 2: \"access denied\"\nfor user <backup>",
+	})
+
+	rendered, err := render("input", input, data)
+	require.NoError(t, err)
+
+	var message struct{ Text string }
+	require.NoError(t, json.Unmarshal([]byte(rendered), &message), rendered)
+	assert.Equal(t, "Backup of *prod* failed: exit status 2: \"access denied\"\nfor user <backup>", message.Text)
+}
+
+func TestNewTemplateData(t *testing.T) {
+	data := NewTemplateData(config.HookRestoreFailed, &Event{Database: "production", Error: "access denied"})
+	assert.Equal(t, "failed", data.Status)
+	assert.Equal(t, "access denied", data.Error)
+	assert.Empty(t, data.SizeHuman)
+	assert.Empty(t, data.Duration)
+
+	assert.Equal(t, "describe", NewTemplateData(HookDescribe, nil).Status)
+}
+
+func TestDescribe(t *testing.T) {
+	script := writeScript(t, `echo '{"version":"1.2.0","kind":"storage","hooks":["backup.completed"]}'`+"\n")
+	p := &Plugin{Name: "s3", Config: &config.PluginConfig{Command: script}}
+
+	info, err := p.Describe()
+	require.NoError(t, err)
+	assert.Equal(t, "s3", info.Name)
+	assert.Equal(t, "1.2.0", info.Version)
+	assert.Equal(t, KindStorage, info.Kind)
+	assert.Equal(t, []string{config.HookBackupCompleted}, info.Hooks)
+}
+
+func TestDispatch(t *testing.T) {
+	ok := writeScript(t, `echo '{"message":"sent"}'`+"\n")
+	failing := writeScript(t, "exit 3\n")
+
+	registry := NewRegistry(map[string]*config.PluginConfig{
+		"slack":  {Command: ok, Hooks: []string{config.HookBackupFailed}},
+		"broken": {Command: failing},
+		"s3":     {Command: ok, Hooks: []string{config.HookBackupCompleted}},
+	})
+
+	names := []string{}
+	for _, p := range registry.Plugins() {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"broken", "s3", "slack"}, names)
+
+	results := registry.Dispatch(config.HookBackupFailed, &Event{Time: time.Now(), Database: "production", Error: "connection refused"})
+	require.Len(t, results, 2)
+	assert.Equal(t, "broken", results[0].Plugin)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, "slack", results[1].Plugin)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "sent", results[1].Message)
+}
+
+func TestDispatchNilRegistry(t *testing.T) {
+	var registry *Registry
+	assert.Empty(t, registry.Dispatch(config.HookBackupCompleted, &Event{}))
+}
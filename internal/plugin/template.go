@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+)
+
+// TemplateData is what a plugin's args and input templates are rendered
+// with, along with config.PluginTemplateFuncs, e.g.
+//
+//	{"text": "Backup of {{.Database}} {{.Status}}: {{json .Error}}"}
+type TemplateData struct {
+	Hook           string    // e.g. "backup.completed"
+	Status         string    // Last part of the hook: "started", "completed" or "failed"
+	Time           time.Time // When the hook fired
+	Database       string
+	BackupID       string
+	File           string
+	SizeBytes      int64
+	SizeHuman      string // e.g. "50.0 MB"
+	Duration       string // e.g. "2m 5s"
+	Checksum       string
+	TargetDatabase string
+	Error          string
+}
+
+// NewTemplateData returns the template data of a hook fired for event.
+func NewTemplateData(hook string, event *Event) *TemplateData {
+	data := &TemplateData{Hook: hook, Status: hook[strings.LastIndex(hook, ".")+1:]}
+	if event == nil {
+		return data
+	}
+	data.Time = event.Time
+	data.Database = event.Database
+	data.BackupID = event.BackupID
+	data.File = event.File
+	data.SizeBytes = event.SizeBytes
+	data.Checksum = event.Checksum
+	data.TargetDatabase = event.TargetDatabase
+	data.Error = event.Error
+	if event.SizeBytes > 0 {
+		data.SizeHuman = backup.FormatBytes(event.SizeBytes)
+	}
+	if event.DurationSeconds > 0 {
+		data.Duration = backup.FormatDuration(time.Duration(event.DurationSeconds * float64(time.Second)))
+	}
+	return data
+}
+
+// render executes the template text with data. Referring to a field that
+// does not exist is an error.
+func render(name, text string, data *TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(config.PluginTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errShuttingDown is returned for jobs that did not start because the
+// daemon is shutting down.
+var errShuttingDown = errors.New("daemon is shutting down")
+
+// runQueue limits how many jobs run at once, overall and per storage
+// directory. Jobs beyond the limits wait and start by priority, then in
+// the order they were queued.
+type runQueue struct {
+	mu            sync.Mutex
+	maxConcurrent int // 0 for unlimited
+	maxPerStorage int // 0 for unlimited
+	active        int
+	activeStorage map[string]int
+	waiting       []*queuedRun
+	seq           uint64
+	closed        bool
+	dropped       []QueuedInfo // jobs refused since close
+}
+
+// queuedRun is a job waiting for a slot.
+type queuedRun struct {
+	QueuedInfo
+	storage   string
+	seq       uint64
+	ready     chan struct{}
+	cancelled bool
+}
+
+func newRunQueue(maxConcurrent, maxPerStorage int) *runQueue {
+	return &runQueue{
+		maxConcurrent: maxConcurrent,
+		maxPerStorage: maxPerStorage,
+		activeStorage: make(map[string]int),
+	}
+}
+
+// setLimits changes the limits, starting waiting jobs they now allow.
+func (q *runQueue) setLimits(maxConcurrent, maxPerStorage int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.maxConcurrent = maxConcurrent
+	q.maxPerStorage = maxPerStorage
+	q.dispatch()
+}
+
+// acquire blocks until the job may run and returns the function releasing
+// its slot, and how long the job waited for it. Once the queue is closed
+// it returns errShuttingDown instead.
+func (q *runQueue) acquire(job QueuedInfo, storage string) (release func(), waited time.Duration, err error) {
+	job.QueuedAt = time.Now()
+
+	q.mu.Lock()
+	if q.closed {
+		q.dropped = append(q.dropped, job)
+		q.mu.Unlock()
+		return nil, 0, errShuttingDown
+	}
+	q.seq++
+	run := &queuedRun{
+		QueuedInfo: job,
+		storage:    storage,
+		seq:        q.seq,
+		ready:      make(chan struct{}),
+	}
+	q.waiting = append(q.waiting, run)
+	q.dispatch()
+	q.mu.Unlock()
+
+	<-run.ready
+	if run.cancelled {
+		return nil, 0, errShuttingDown
+	}
+	waited = time.Since(job.QueuedAt)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			q.active--
+			q.activeStorage[storage]--
+			if q.activeStorage[storage] == 0 {
+				delete(q.activeStorage, storage)
+			}
+			q.dispatch()
+		})
+	}, waited, nil
+}
+
+// close refuses all further jobs, including those waiting for a slot.
+// Running jobs keep their slots.
+func (q *runQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	for _, run := range q.waiting {
+		q.dropped = append(q.dropped, run.QueuedInfo)
+		run.cancelled = true
+		close(run.ready)
+	}
+	q.waiting = nil
+}
+
+// refused returns the jobs refused since close, in queue order.
+func (q *runQueue) refused() []QueuedInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]QueuedInfo(nil), q.dropped...)
+}
+
+// dispatch starts the waiting jobs the limits allow (internal, assumes
+// lock is held). A job blocked by its storage limit does not hold back
+// jobs for other storage.
+func (q *runQueue) dispatch() {
+	sort.SliceStable(q.waiting, func(i, j int) bool {
+		if q.waiting[i].Priority != q.waiting[j].Priority {
+			return q.waiting[i].Priority > q.waiting[j].Priority
+		}
+		return q.waiting[i].seq < q.waiting[j].seq
+	})
+
+	remaining := q.waiting[:0]
+	for _, run := range q.waiting {
+		if (q.maxConcurrent > 0 && q.active >= q.maxConcurrent) ||
+			(q.maxPerStorage > 0 && q.activeStorage[run.storage] >= q.maxPerStorage) {
+			remaining = append(remaining, run)
+			continue
+		}
+		q.active++
+		q.activeStorage[run.storage]++
+		close(run.ready)
+	}
+	q.waiting = remaining
+}
+
+// queued returns the waiting jobs in the order they will start.
+func (q *runQueue) queued() []QueuedInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queued := make([]QueuedInfo, len(q.waiting))
+	for i, run := range q.waiting {
+		queued[i] = run.QueuedInfo
+	}
+	return queued
+}
+
+// QueuedInfo describes a job waiting for a free slot. It is persisted
+// when the daemon shuts down, so the job runs after the next start.
+type QueuedInfo struct {
+	Label       string    `json:"label"`
+	Database    string    `json:"database"`
+	Schedule    string    `json:"schedule,omitempty"`
+	RestoreTest bool      `json:"restore_test,omitempty"`
+	Priority    int       `json:"priority,omitempty"`
+	QueuedAt    time.Time `json:"queued_at"`
+}
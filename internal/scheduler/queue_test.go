@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobs queues jobs that hold their slot until released.
+type fakeJobs struct {
+	t        *testing.T
+	queue    *runQueue
+	mu       sync.Mutex
+	started  []string
+	releases map[string]func()
+}
+
+func newFakeJobs(t *testing.T, maxConcurrent, maxPerStorage int) *fakeJobs {
+	return &fakeJobs{
+		t:        t,
+		queue:    newRunQueue(maxConcurrent, maxPerStorage),
+		releases: make(map[string]func()),
+	}
+}
+
+// enqueue queues a job and waits until it has started or is waiting.
+func (f *fakeJobs) enqueue(label, storage string, priority int) {
+	before := f.count()
+	go func() {
+		release, _, err := f.queue.acquire(QueuedInfo{Label: label, Priority: priority}, storage)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.started = append(f.started, label)
+		f.releases[label] = release
+	}()
+	require.Eventually(f.t, func() bool { return f.count() == before+1 }, time.Second, time.Millisecond)
+}
+
+// count returns the number of jobs started or waiting.
+func (f *fakeJobs) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.started) + len(f.queue.queued())
+}
+
+// release frees the slot of a started job.
+func (f *fakeJobs) release(label string) {
+	f.mu.Lock()
+	release := f.releases[label]
+	f.mu.Unlock()
+	require.NotNil(f.t, release, "%s has not started", label)
+	release()
+}
+
+// waitStarted waits until exactly the given jobs have started, in order.
+func (f *fakeJobs) waitStarted(labels ...string) {
+	require.Eventually(f.t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return assert.ObjectsAreEqual(labels, f.started)
+	}, time.Second, time.Millisecond, "want started %v", labels)
+}
+
+// queuedLabels returns the waiting jobs in the order they will start.
+func (f *fakeJobs) queuedLabels() []string {
+	var labels []string
+	for _, job := range f.queue.queued() {
+		labels = append(labels, job.Label)
+	}
+	return labels
+}
+
+func TestRunQueuePriority(t *testing.T) {
+	jobs := newFakeJobs(t, 1, 0)
+	jobs.enqueue("running", "/backups", 0)
+	jobs.waitStarted("running")
+
+	jobs.enqueue("low-1", "/backups", 0)
+	jobs.enqueue("high", "/backups", 10)
+	jobs.enqueue("low-2", "/backups", 0)
+	jobs.enqueue("mid", "/backups", 5)
+	assert.Equal(t, []string{"high", "mid", "low-1", "low-2"}, jobs.queuedLabels())
+
+	jobs.release("running")
+	jobs.waitStarted("running", "high")
+	jobs.release("high")
+	jobs.waitStarted("running", "high", "mid")
+	jobs.release("mid")
+	jobs.waitStarted("running", "high", "mid", "low-1")
+	jobs.release("low-1")
+	jobs.waitStarted("running", "high", "mid", "low-1", "low-2")
+	assert.Empty(t, jobs.queuedLabels())
+}
+
+func TestRunQueueStorageLimit(t *testing.T) {
+	jobs := newFakeJobs(t, 0, 1)
+	jobs.enqueue("a-1", "/a", 0)
+	jobs.enqueue("a-2", "/a", 0)
+	jobs.waitStarted("a-1")
+
+	// A job for other storage is not held back by the waiting one
+	jobs.enqueue("b-1", "/b", 0)
+	jobs.waitStarted("a-1", "b-1")
+	assert.Equal(t, []string{"a-2"}, jobs.queuedLabels())
+
+	jobs.release("b-1")
+	assert.Equal(t, []string{"a-2"}, jobs.queuedLabels())
+	jobs.release("a-1")
+	jobs.waitStarted("a-1", "b-1", "a-2")
+}
+
+func TestRunQueueRaisedLimit(t *testing.T) {
+	jobs := newFakeJobs(t, 1, 0)
+	jobs.enqueue("first", "/backups", 0)
+	jobs.enqueue("second", "/backups", 0)
+	jobs.enqueue("third", "/backups", 0)
+	jobs.waitStarted("first")
+	assert.Equal(t, []string{"second", "third"}, jobs.queuedLabels())
+
+	jobs.queue.setLimits(3, 0)
+	require.Eventually(t, func() bool {
+		jobs.mu.Lock()
+		defer jobs.mu.Unlock()
+		return len(jobs.started) == 3
+	}, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, jobs.started)
+	assert.Empty(t, jobs.queuedLabels())
+}
+
+func TestRunQueueClose(t *testing.T) {
+	jobs := newFakeJobs(t, 1, 0)
+	jobs.enqueue("running", "/backups", 0)
+	jobs.enqueue("waiting", "/backups", 0)
+	jobs.waitStarted("running")
+
+	jobs.queue.close()
+	release, _, err := jobs.queue.acquire(QueuedInfo{Label: "late"}, "/backups")
+	assert.ErrorIs(t, err, errShuttingDown)
+	assert.Nil(t, release)
+
+	var refused []string
+	for _, job := range jobs.queue.refused() {
+		refused = append(refused, job.Label)
+	}
+	assert.Equal(t, []string{"waiting", "late"}, refused)
+	assert.Empty(t, jobs.queuedLabels())
+
+	// The running job keeps its slot until released
+	jobs.release("running")
+	jobs.waitStarted("running")
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+)
+
+// Reload replaces the configuration and rebuilds all cron entries from it
+// in one step, so no schedule runs against a half-applied configuration.
+// Runs already in progress finish with the configuration they started
+// with. It returns the schedules that were added, removed or changed. An
+// invalid configuration is rejected and the current one kept.
+func (s *Scheduler) Reload(cfg *config.Config) ([]string, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := diffSchedules(s.config, cfg)
+	s.config = cfg
+	s.queue.setLimits(concurrencyLimits(cfg))
+
+	if auditLogger, err := audit.FromConfig(cfg); err != nil {
+		s.logger.Printf("Audit log: %v", err)
+	} else {
+		if s.audit != nil {
+			s.audit.Close()
+		}
+		s.audit = auditLogger
+	}
+
+	if err := s.loadSchedules(); err != nil {
+		return changes, err
+	}
+
+	s.logger.Printf("Configuration reloaded: %d schedule change(s)", len(changes))
+	for _, change := range changes {
+		s.logger.Printf("  %s", change)
+	}
+
+	return changes, nil
+}
+
+// scheduledRun describes one enabled cron entry of a configuration.
+type scheduledRun struct {
+	spec     string
+	settings interface{}
+}
+
+// scheduledRuns returns the enabled schedules, restore tests and digest of
+// cfg by label.
+func scheduledRuns(cfg *config.Config) map[string]scheduledRun {
+	runs := make(map[string]scheduledRun)
+	if cfg == nil {
+		return runs
+	}
+
+	for dbName, dbConfig := range cfg.Databases {
+		// Connection and dump settings affect every schedule of the database
+		shared := *dbConfig
+		shared.Schedule, shared.Schedules, shared.RestoreTest = nil, nil, nil
+
+		for _, schedule := range dbConfig.AllSchedules() {
+			if !schedule.Enabled {
+				continue
+			}
+			spec := schedule.Cron
+			if schedule.Timezone != "" {
+				spec += " (" + schedule.Timezone + ")"
+			}
+			runs[config.ScheduleLabel(dbName, schedule.ScheduleName())] = scheduledRun{
+				spec:     spec,
+				settings: []interface{}{shared, *schedule},
+			}
+		}
+		if drill := dbConfig.RestoreTest; drill != nil && drill.Enabled {
+			runs[dbName+" (restore test)"] = scheduledRun{
+				spec:     drill.Cron,
+				settings: []interface{}{shared, *drill},
+			}
+		}
+	}
+	if cfg.Digest != nil {
+		runs["digest"] = scheduledRun{spec: cfg.Digest.Schedule(), settings: *cfg.Digest}
+	}
+	return runs
+}
+
+// diffSchedules describes how the schedules of next differ from prev.
+func diffSchedules(prev, next *config.Config) []string {
+	before := scheduledRuns(prev)
+	after := scheduledRuns(next)
+
+	var changes []string
+	for label, run := range after {
+		old, existed := before[label]
+		switch {
+		case !existed:
+			changes = append(changes, fmt.Sprintf("added %s: %s", label, run.spec))
+		case old.spec != run.spec:
+			changes = append(changes, fmt.Sprintf("changed %s: %s -> %s", label, old.spec, run.spec))
+		case !reflect.DeepEqual(old.settings, run.settings):
+			changes = append(changes, fmt.Sprintf("updated %s: settings changed", label))
+		}
+	}
+	for label := range before {
+		if _, exists := after[label]; !exists {
+			changes = append(changes, fmt.Sprintf("removed %s", label))
+		}
+	}
+
+	sort.Strings(changes)
+	return changes
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scheduledConfig returns a valid configuration with a daily schedule for
+// each database, by cron expression.
+func scheduledConfig(schedules map[string]string) *config.Config {
+	cfg := config.NewConfig()
+	for name, cron := range schedules {
+		cfg.Databases[name] = &config.DatabaseConfig{
+			Type:     "mysql",
+			Host:     "localhost",
+			Port:     3306,
+			User:     "backup",
+			Database: name,
+			Schedule: &config.ScheduleConfig{Enabled: true, Cron: cron},
+		}
+	}
+	return cfg
+}
+
+func TestDiffSchedules(t *testing.T) {
+	prev := scheduledConfig(map[string]string{"shop": "0 2 * * *", "blog": "0 3 * * *", "crm": "0 4 * * *"})
+	next := scheduledConfig(map[string]string{"shop": "0 5 * * *", "crm": "0 4 * * *", "wiki": "0 6 * * *"})
+
+	assert.Equal(t, []string{
+		"added wiki: 0 6 * * *",
+		"changed shop: 0 2 * * * -> 0 5 * * *",
+		"removed blog",
+	}, diffSchedules(prev, next))
+
+	next.Databases["crm"].Host = "replica"
+	assert.Contains(t, diffSchedules(prev, next), "updated crm: settings changed")
+
+	next.Databases["wiki"].Schedule.Enabled = false
+	assert.NotContains(t, diffSchedules(prev, next), "added wiki: 0 6 * * *")
+
+	assert.Empty(t, diffSchedules(prev, prev))
+	assert.Len(t, diffSchedules(nil, prev), 3)
+}
+
+func TestReload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	prev := scheduledConfig(map[string]string{"shop": "0 2 * * *", "blog": "0 3 * * *"})
+	s := New(prev, nil)
+	require.NoError(t, s.LoadSchedules())
+	require.Len(t, s.jobs, 2)
+
+	next := scheduledConfig(map[string]string{"shop": "0 5 * * *", "wiki": "0 6 * * *"})
+	changes, err := s.Reload(next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"added wiki: 0 6 * * *",
+		"changed shop: 0 2 * * * -> 0 5 * * *",
+		"removed blog",
+	}, changes)
+	assert.Same(t, next, s.getConfig())
+	assert.Len(t, s.jobs, 2)
+	assert.Contains(t, s.jobs, scheduleKey{database: "wiki", schedule: config.DefaultScheduleName})
+	assert.NotContains(t, s.jobs, scheduleKey{database: "blog", schedule: config.DefaultScheduleName})
+
+	invalid := scheduledConfig(map[string]string{"shop": "0 7 * * *"})
+	invalid.Databases["shop"].Host = ""
+	_, err = s.Reload(invalid)
+	assert.Error(t, err)
+	assert.Same(t, next, s.getConfig())
+	assert.Len(t, s.jobs, 2)
+}
@@ -1,38 +1,97 @@
 package scheduler
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/internal/notify"
+	"github.com/erickhilda/cadangkan/internal/plugin"
+	"github.com/erickhilda/cadangkan/internal/status"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/robfig/cron/v3"
 )
 
+// freshnessCheckInterval is how often the daemon checks freshness SLAs.
+const freshnessCheckInterval = 15 * time.Minute
+
+// cronParser accepts standard five-field expressions, an optional leading
+// seconds field, and descriptors such as @daily.
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// scheduleKey identifies a backup schedule of a database.
+type scheduleKey struct {
+	database string
+	schedule string
+}
+
 // Scheduler manages scheduled backup jobs.
 type Scheduler struct {
 	cron      *cron.Cron
-	jobs      map[string]cron.EntryID // database name -> cron entry ID
+	jobs      map[scheduleKey]cron.EntryID
+	drills    map[string]cron.EntryID // database name -> restore test entry ID
+	freshness cron.EntryID            // freshness SLA check, 0 if none
+	digest    cron.EntryID            // email digest, 0 if none
+	breached  map[string]bool         // database name -> freshness SLA breached
+	running   map[string]RunInfo      // run label -> run in progress
+	failures  map[string]RunInfo      // run label -> failure of its last run
+	queue     *runQueue
+	delayed   sync.WaitGroup // jobs waiting out their jitter
+	stop      chan struct{}  // closed when Shutdown begins
+	stopping  bool
 	config    *config.Config
 	storage   *storage.LocalStorage
 	mu        sync.RWMutex
 	logger    *log.Logger
+	audit     *audit.Logger
 	verbose   bool
 }
 
 // New creates a new scheduler instance.
 func New(cfg *config.Config, stor *storage.LocalStorage) *Scheduler {
-	return &Scheduler{
-		cron:    cron.New(cron.WithLocation(time.Local)),
-		jobs:    make(map[string]cron.EntryID),
-		config:  cfg,
-		storage: stor,
-		logger:  log.New(log.Writer(), "[scheduler] ", log.LstdFlags),
+	s := &Scheduler{
+		cron:     cron.New(cron.WithLocation(time.Local)),
+		jobs:     make(map[scheduleKey]cron.EntryID),
+		drills:   make(map[string]cron.EntryID),
+		breached: make(map[string]bool),
+		running:  make(map[string]RunInfo),
+		failures: make(map[string]RunInfo),
+		queue:    newRunQueue(concurrencyLimits(cfg)),
+		stop:     make(chan struct{}),
+		config:   cfg,
+		storage:  stor,
+		logger:   log.New(log.Writer(), "[scheduler] ", log.LstdFlags),
+	}
+
+	auditLogger, err := audit.FromConfig(cfg)
+	if err != nil {
+		s.logger.Printf("Audit log: %v", err)
 	}
+	s.audit = auditLogger
+
+	return s
+}
+
+// concurrencyLimits returns the global and per-storage limits on jobs
+// running at once, 0 meaning unlimited.
+func concurrencyLimits(cfg *config.Config) (int, int) {
+	if cfg == nil || cfg.Daemon == nil {
+		return 0, 0
+	}
+	return cfg.Daemon.MaxConcurrent, cfg.Daemon.MaxConcurrentPerStorage
 }
 
 // SetVerbose enables or disables verbose logging.
@@ -40,9 +99,29 @@ func (s *Scheduler) SetVerbose(verbose bool) {
 	s.verbose = verbose
 }
 
+// getConfig returns the current configuration, which Reload may replace.
+func (s *Scheduler) getConfig() *config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// auditLogger returns the current audit logger, which Reload may replace.
+func (s *Scheduler) auditLogger() *audit.Logger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.audit
+}
+
+// Config returns the configuration the scheduler currently runs with.
+func (s *Scheduler) Config() *config.Config {
+	return s.getConfig()
+}
+
 // Start starts the scheduler.
 func (s *Scheduler) Start() {
 	s.cron.Start()
+	go s.checkFreshness()
 	if s.verbose {
 		s.logger.Println("Scheduler started")
 	}
@@ -61,19 +140,53 @@ func (s *Scheduler) LoadSchedules() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.loadSchedules()
+}
+
+// loadSchedules replaces all cron entries with those of the current config
+// (internal, assumes lock is held).
+func (s *Scheduler) loadSchedules() error {
 	// Clear existing jobs
-	for dbName, entryID := range s.jobs {
+	for key, entryID := range s.jobs {
 		s.cron.Remove(entryID)
-		delete(s.jobs, dbName)
+		delete(s.jobs, key)
+	}
+	for dbName, entryID := range s.drills {
+		s.cron.Remove(entryID)
+		delete(s.drills, dbName)
+	}
+	if s.freshness != 0 {
+		s.cron.Remove(s.freshness)
+		s.freshness = 0
+	}
+	if s.digest != 0 {
+		s.cron.Remove(s.digest)
+		s.digest = 0
 	}
 
 	// Register all enabled schedules
 	for dbName, dbConfig := range s.config.Databases {
-		if dbConfig.Schedule != nil && dbConfig.Schedule.Enabled {
-			if err := s.addSchedule(dbName, dbConfig); err != nil {
-				s.logger.Printf("Failed to add schedule for %s: %v", dbName, err)
+		for _, schedule := range dbConfig.AllSchedules() {
+			if !schedule.Enabled {
 				continue
 			}
+			if err := s.addSchedule(dbName, dbConfig, schedule); err != nil {
+				s.logger.Printf("Failed to add schedule for %s: %v", config.ScheduleLabel(dbName, schedule.ScheduleName()), err)
+			}
+		}
+		if dbConfig.RestoreTest != nil && dbConfig.RestoreTest.Enabled {
+			if err := s.addRestoreTest(dbName, dbConfig); err != nil {
+				s.logger.Printf("Failed to add restore test for %s: %v", dbName, err)
+			}
+		}
+	}
+
+	// Check freshness SLAs periodically
+	s.freshness = s.cron.Schedule(cron.Every(freshnessCheckInterval), cron.FuncJob(s.checkFreshness))
+
+	if s.config.Digest != nil {
+		if err := s.addDigest(s.config.Digest); err != nil {
+			s.logger.Printf("Failed to add digest: %v", err)
 		}
 	}
 
@@ -81,115 +194,701 @@ func (s *Scheduler) LoadSchedules() error {
 }
 
 // addSchedule adds a schedule for a database (internal, assumes lock is held).
-func (s *Scheduler) addSchedule(dbName string, dbConfig *config.DatabaseConfig) error {
-	if dbConfig.Schedule == nil || dbConfig.Schedule.Cron == "" {
+func (s *Scheduler) addSchedule(dbName string, dbConfig *config.DatabaseConfig, schedule *config.ScheduleConfig) error {
+	if schedule == nil || schedule.Cron == "" {
 		return fmt.Errorf("no schedule configured")
 	}
 
 	// Validate cron expression
-	_, err := cron.ParseStandard(dbConfig.Schedule.Cron)
+	sched, err := ParseCron(schedule.Cron, schedule.Timezone)
 	if err != nil {
-		return fmt.Errorf("invalid cron expression: %w", err)
+		return err
 	}
 
-	// Create backup job
-	job := s.createBackupJob(dbName, dbConfig)
+	// Create backup job, delayed by jitter and skipped during blackouts
+	label := config.ScheduleLabel(dbName, schedule.ScheduleName())
+	job := s.withScheduleRules(label, schedule, s.createBackupJob(dbName, dbConfig, schedule))
 
 	// Add to cron
-	entryID, err := s.cron.AddFunc(dbConfig.Schedule.Cron, job)
+	entryID := s.cron.Schedule(sched, cron.FuncJob(job))
+
+	s.jobs[scheduleKey{database: dbName, schedule: schedule.ScheduleName()}] = entryID
+
+	if s.verbose {
+		if schedule.Timezone != "" {
+			s.logger.Printf("Added schedule for %s: %s (%s)", label, schedule.Cron, schedule.Timezone)
+		} else {
+			s.logger.Printf("Added schedule for %s: %s", label, schedule.Cron)
+		}
+	}
+
+	return nil
+}
+
+// addRestoreTest adds a restore drill for a database (internal, assumes lock is held).
+func (s *Scheduler) addRestoreTest(dbName string, dbConfig *config.DatabaseConfig) error {
+	sched, err := ParseCron(dbConfig.RestoreTest.Cron, "")
+	if err != nil {
+		return err
+	}
+
+	entryID := s.cron.Schedule(sched, cron.FuncJob(s.createRestoreTestJob(dbName, dbConfig)))
+
+	s.drills[dbName] = entryID
+
+	if s.verbose {
+		s.logger.Printf("Added restore test for %s: %s", dbName, dbConfig.RestoreTest.Cron)
+	}
+
+	return nil
+}
+
+// addDigest schedules the email digest (internal, assumes lock is held).
+func (s *Scheduler) addDigest(digest *config.DigestConfig) error {
+	sched, err := ParseCron(digest.Schedule(), "")
 	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
+		return err
 	}
 
-	s.jobs[dbName] = entryID
+	s.digest = s.cron.Schedule(sched, cron.FuncJob(s.sendDigest))
 
 	if s.verbose {
-		s.logger.Printf("Added schedule for %s: %s", dbName, dbConfig.Schedule.Cron)
+		s.logger.Printf("Added digest: %s", digest.Schedule())
 	}
 
 	return nil
 }
 
-// createBackupJob creates a backup job function for a database.
-func (s *Scheduler) createBackupJob(dbName string, dbConfig *config.DatabaseConfig) func() {
+// withScheduleRules wraps job so it starts after a random delay of up to
+// the schedule's jitter, spreading jobs that share a cron time, and is
+// skipped when it would start inside a blackout window. Shutdown cuts the
+// delay short, so the job reaches the closed queue and is kept as pending.
+func (s *Scheduler) withScheduleRules(label string, schedule *config.ScheduleConfig, job func()) func() {
 	return func() {
-		s.logger.Printf("Running scheduled backup for %s", dbName)
+		if jitter, _ := schedule.JitterDuration(); jitter > 0 {
+			delay := rand.N(jitter)
+			if s.verbose {
+				s.logger.Printf("Delaying backup for %s by %s", label, delay.Round(time.Second))
+			}
+			if s.sleepUnlessStopped(delay) {
+				// Shutdown waits until the queue has refused the job
+				defer s.delayed.Done()
+			}
+		}
 
-		// Decrypt password
-		password, err := config.DecryptPassword(dbConfig.PasswordEncrypted)
-		if err != nil {
-			s.logger.Printf("Failed to decrypt password for %s: %v", dbName, err)
+		if window, ok := schedule.InBlackout(time.Now()); ok {
+			s.logger.Printf("Skipping backup for %s: inside blackout window %s", label, window)
 			return
 		}
 
-		// Create MySQL client
-		mysqlConfig := &mysql.Config{
-			Host:     dbConfig.Host,
-			Port:     dbConfig.Port,
-			User:     dbConfig.User,
-			Password: password,
-			Database: dbConfig.Database,
-			Timeout:  10 * time.Second,
+		job()
+	}
+}
+
+// sleepUnlessStopped waits for delay, or until Shutdown begins. It
+// returns true when cut short by Shutdown, leaving the job counted in
+// s.delayed for the caller to release.
+func (s *Scheduler) sleepUnlessStopped(delay time.Duration) bool {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return false
+	}
+	s.delayed.Add(1)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		s.delayed.Done()
+		return false
+	case <-s.stop:
+		return true
+	}
+}
+
+// ParseCron parses a cron expression of five fields, or six with leading
+// seconds. With a timezone (an IANA name such as "Asia/Jakarta") the
+// expression is evaluated there instead of in server local time.
+func ParseCron(expr, timezone string) (cron.Schedule, error) {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+		}
+		expr = "CRON_TZ=" + timezone + " " + expr
+	}
+
+	sched, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return sched, nil
+}
+
+// NextRun returns the first run of sched after from that does not start
+// inside one of the schedule's blackout windows. Jitter is not included.
+func NextRun(sched cron.Schedule, schedule *config.ScheduleConfig, from time.Time) time.Time {
+	next := sched.Next(from)
+	// Bounded so a schedule that always falls in a blackout terminates
+	for i := 0; i < 1000; i++ {
+		if _, ok := schedule.InBlackout(next); !ok {
+			return next
 		}
+		next = sched.Next(next)
+	}
+	return time.Time{}
+}
 
-		client, err := mysql.NewClient(mysqlConfig)
+// createRestoreTestJob creates a job that restores the latest backup of a
+// database into a scratch database and validates it.
+func (s *Scheduler) createRestoreTestJob(dbName string, dbConfig *config.DatabaseConfig) func() {
+	return func() {
+		label := dbName + " (restore test)"
+		release, waited, err := s.queue.acquire(QueuedInfo{Label: label, Database: dbName, RestoreTest: true}, s.storageFor(dbName, nil).GetBasePath())
 		if err != nil {
-			s.logger.Printf("Failed to create client for %s: %v", dbName, err)
+			s.logger.Printf("Not starting restore test for %s: %v", dbName, err)
 			return
 		}
+		defer release()
 
-		if err := client.Connect(); err != nil {
-			s.logger.Printf("Failed to connect to %s: %v", dbName, err)
+		s.logger.Printf("Running restore test for %s%s", dbName, queuedFor(waited))
+
+		done := s.track(dbName, label)
+		report, err := s.runRestoreTest(dbName, dbConfig)
+		if err == nil && !report.Passed() {
+			err = fmt.Errorf("validation failed: %s", strings.Join(report.Problems, "; "))
+		}
+		done(err)
+		s.recordRestoreTest(dbName, report, err)
+
+		if err != nil {
+			s.logger.Printf("ALERT: Restore test failed for %s: %v", dbName, err)
 			return
 		}
-		defer client.Close()
+		s.logger.Printf("Restore test passed for %s: %s restored into %s (%d tables, %s)",
+			dbName, report.BackupID, report.ScratchDatabase, report.Tables, backup.FormatDuration(report.Duration))
+	}
+}
 
-		// Create backup service
-		backupService := backup.NewService(client, s.storage, mysqlConfig)
-		if s.verbose {
-			backupService.SetVerbose(true)
+// runRestoreTest connects to the scratch server and runs the drill.
+func (s *Scheduler) runRestoreTest(dbName string, dbConfig *config.DatabaseConfig) (*backup.DrillReport, error) {
+	serverConfig := dbConfig
+	if name := dbConfig.RestoreTest.Server; name != "" {
+		var ok bool
+		if serverConfig, ok = s.getConfig().Databases[name]; !ok {
+			return nil, fmt.Errorf("scratch server %q is not configured", name)
 		}
+	}
 
-		// Backup options
-		backupOptions := &backup.BackupOptions{
-			Database:      dbConfig.Database,
-			ConfigName:    dbName,
-			Compression:   backup.CompressionGzip,
-			Tables:        nil,
-			ExcludeTables: nil,
-			SchemaOnly:    false,
+	eng, client, mysqlConfig, err := s.connect(serverConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	restoreService := backup.NewRestoreService(client, s.storageFor(dbName, nil), mysqlConfig)
+	restoreService.SetEngine(eng)
+	restoreService.SetVerbose(s.verbose)
+
+	return restoreService.Drill(&backup.DrillOptions{
+		Database:        dbConfig.Database,
+		ConfigName:      dbName,
+		ScratchDatabase: dbConfig.RestoreTest.ScratchDatabase,
+		Keep:            dbConfig.RestoreTest.Keep,
+	})
+}
+
+// recordRestoreTest writes the outcome of a restore drill to the audit log.
+func (s *Scheduler) recordRestoreTest(dbName string, report *backup.DrillReport, drillErr error) {
+	auditLogger := s.auditLogger()
+	if auditLogger == nil {
+		return
+	}
+
+	target := ""
+	if report != nil {
+		target = report.BackupID
+	}
+	entry := audit.NewEntry(audit.ActionRestoreTest, dbName, target, drillErr)
+	if report != nil {
+		entry.Details = fmt.Sprintf("into %s: %d table(s), %s in %s",
+			report.ScratchDatabase, report.Tables, backup.FormatBytes(report.SizeBytes), backup.FormatDuration(report.Duration))
+		if len(report.MissingTables) > 0 {
+			entry.Details += "; missing: " + strings.Join(report.MissingTables, ", ")
 		}
+	}
 
-		// Execute backup
-		result, err := backupService.Backup(backupOptions)
+	if err := auditLogger.Record(entry); err != nil {
+		s.logger.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// createBackupJob creates a backup job function for a database schedule.
+func (s *Scheduler) createBackupJob(dbName string, dbConfig *config.DatabaseConfig, schedule *config.ScheduleConfig) func() {
+	return func() {
+		// Failures are logged by runBackup
+		_ = s.runBackup(dbName, dbConfig, schedule)
+	}
+}
+
+// RunNow runs a scheduled backup of a database immediately with its
+// configured options. An empty scheduleName selects the default schedule.
+// With applyRules the schedule's jitter and blackout windows apply as they
+// would in the daemon.
+func (s *Scheduler) RunNow(dbName, scheduleName string, applyRules bool) error {
+	dbConfig, ok := s.getConfig().Database(dbName)
+	if !ok {
+		return fmt.Errorf("database %q is not configured", dbName)
+	}
+	schedule := dbConfig.FindSchedule(scheduleName)
+	if schedule == nil {
+		return fmt.Errorf("no schedule %q configured for %s", scheduleName, dbName)
+	}
+
+	if !applyRules {
+		return s.runBackup(dbName, dbConfig, schedule)
+	}
+
+	runErr := fmt.Errorf("skipped: inside a blackout window")
+	s.withScheduleRules(config.ScheduleLabel(dbName, schedule.ScheduleName()), schedule, func() {
+		runErr = s.runBackup(dbName, dbConfig, schedule)
+	})()
+	return runErr
+}
+
+// runServerBackup runs a backup of each schema of a server entry, one
+// after the other. Each schema is queued and recorded as its own run.
+func (s *Scheduler) runServerBackup(dbName string, dbConfig *config.DatabaseConfig, schedule *config.ScheduleConfig) error {
+	label := config.ScheduleLabel(dbName, schedule.ScheduleName())
+	schemas, err := backupconfig.ServerSchemas(dbConfig, func() (backup.Introspector, error) {
+		_, client, _, err := s.connect(dbConfig)
+		return client, err
+	})
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+
+	s.logger.Printf("Backing up %d database(s) of %s", len(schemas), label)
+	var errs []error
+	for _, schema := range schemas {
+		if err := s.runBackup(config.SchemaName(dbName, schema), dbConfig.ForSchema(schema), schedule); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", schema, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runBackup runs one backup of a database with the options of schedule,
+// followed by its retention policy. Failures are logged and returned.
+func (s *Scheduler) runBackup(dbName string, dbConfig *config.DatabaseConfig, schedule *config.ScheduleConfig) (err error) {
+	if dbConfig.IsServer() {
+		return s.runServerBackup(dbName, dbConfig, schedule)
+	}
+	label := config.ScheduleLabel(dbName, schedule.ScheduleName())
+
+	// Wait for a free slot under the daemon's concurrency limits
+	stor, priority := s.storageFor(dbName, schedule), 0
+	if schedule != nil {
+		priority = schedule.Priority
+	}
+	job := QueuedInfo{Label: label, Database: dbName, Schedule: schedule.ScheduleName(), Priority: priority}
+	release, waited, err := s.queue.acquire(job, stor.GetBasePath())
+	if err != nil {
+		s.logger.Printf("Not starting backup for %s: %v", label, err)
+		return err
+	}
+	defer release()
+
+	s.logger.Printf("Running scheduled backup for %s%s", label, queuedFor(waited))
+
+	done := s.track(dbName, label)
+	defer func() { done(err) }()
+
+	eng, client, mysqlConfig, err := s.connect(dbConfig)
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+	defer client.Close()
+
+	// Create backup service
+	backupService := backup.NewService(client, stor, mysqlConfig)
+	backupService.SetEngine(eng)
+	if s.verbose {
+		backupService.SetVerbose(true)
+	}
+
+	// Backup options
+	backupOptions := &backup.BackupOptions{
+		Database:    dbConfig.Database,
+		ConfigName:  dbName,
+		Compression: backup.CompressionGzip,
+	}
+	if schedule != nil {
+		if schedule.Compression != "" {
+			backupOptions.Compression = schedule.Compression
+		}
+		backupOptions.SchemaOnly = schedule.SchemaOnly
+		backupOptions.Tables = schedule.Tables
+		backupOptions.ExcludeTables = schedule.ExcludeTables
+	}
+	if dbConfig.Mysqldump != nil {
+		backupOptions.DumpArgs = dbConfig.Mysqldump.ExtraArgs
+		backupOptions.DisableDumpDefaults = dbConfig.Mysqldump.DisableDefaults
+		backupOptions.Definers = dbConfig.Mysqldump.Definers
+		backupOptions.SQLSecurityInvoker = dbConfig.Mysqldump.SQLSecurity == "invoker"
+	}
+	warningPolicy, err := backupconfig.WarningPolicy(dbConfig.Mysqldump)
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+	backupOptions.WarningPolicy = warningPolicy
+	backupOptions.LongQueryPolicy, err = backupconfig.LongQueryPolicy(dbConfig.LongQueries)
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+	backupOptions.LockMonitorPolicy, err = backupconfig.LockMonitorPolicy(dbConfig.LockMonitor)
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+	backupOptions.VersionCheck = s.getConfig().GetEffectiveVersionCheck(dbName)
+
+	// Apply storage quota, pruning old backups first if configured
+	quotaPolicy, err := backupconfig.QuotaPolicy(s.getConfig(), dbName)
+	if err != nil {
+		s.logger.Printf("Skipping backup for %s: %v", label, err)
+		return err
+	}
+	if quotaPolicy != nil {
+		backupService.SetQuota(&quotaPolicy.Limits)
+		if err := maintenance.Check("quota pruning"); err != nil && quotaPolicy.Prune != (backup.Quota{}) {
+			s.logger.Printf("Not pruning for quota before backup of %s: %v", label, err)
+		} else {
+			s.enforceQuota(stor, dbName, quotaPolicy, backupService.EstimateSize(backupOptions))
+		}
+	}
+
+	// Predict the duration from past backups and alert on overruns
+	var overrun *time.Timer
+	if estimate, _ := backup.EstimateDuration(stor, dbName, backupOptions); estimate != nil {
+		s.logger.Printf("Estimated duration for %s: %s", label, estimate)
+		limit := estimate.Limit(s.getConfig().GetOverrunFactor())
+		overrun = time.AfterFunc(limit, func() {
+			s.logger.Printf("ALERT: Backup of %s has run for %s, longer than expected (%s)",
+				label, backup.FormatDuration(limit), estimate)
+		})
+	}
+
+	// Execute backup
+	s.runPlugins(config.HookBackupStarted, &plugin.Event{Database: dbName})
+	result, err := backupService.Backup(backupOptions)
+	if overrun != nil {
+		overrun.Stop()
+	}
+	if err != nil {
+		s.runPlugins(config.HookBackupFailed, &plugin.Event{Database: dbName, Error: err.Error()})
+		if backup.IsQuotaExceededError(err) {
+			s.logger.Printf("ALERT: Backup skipped for %s: %v", label, err)
+			return err
+		}
+		s.logger.Printf("Backup failed for %s: %v", label, err)
+		return err
+	}
+
+	s.logger.Printf("Backup completed for %s: %s (%s)", label, result.BackupID, backup.FormatBytes(result.SizeBytes))
+	for _, warning := range result.Warnings {
+		s.logger.Printf("Backup warning for %s: %s", label, warning)
+	}
+	s.runPlugins(config.HookBackupCompleted, &plugin.Event{
+		Database:        dbName,
+		BackupID:        result.BackupID,
+		File:            result.FilePath,
+		SizeBytes:       result.SizeBytes,
+		DurationSeconds: result.Duration.Seconds(),
+		Checksum:        result.Checksum,
+	})
+
+	// Apply retention policy if configured, the schedule's overriding the database's
+	retention := dbConfig.Retention
+	if schedule != nil && schedule.Retention != nil {
+		retention = schedule.Retention
+	}
+	if retention != nil && !retention.KeepAll {
+		if err := maintenance.Check("retention cleanup"); err != nil {
+			s.logger.Printf("Skipping retention cleanup for %s: %v", dbName, err)
+			return nil
+		}
+		retentionService := backup.NewRetentionService(stor)
+		cleanupResult, err := retentionService.ApplyRetention(dbName, backupconfig.RetentionPolicy(retention), false)
+		s.recordPrune(dbName, "retention", cleanupResult, err)
 		if err != nil {
-			s.logger.Printf("Backup failed for %s: %v", dbName, err)
-			return
+			s.logger.Printf("Retention cleanup failed for %s: %v", dbName, err)
+		} else if len(cleanupResult.ToDelete) > 0 {
+			s.logger.Printf("Cleaned up %d old backup(s) for %s", len(cleanupResult.ToDelete), dbName)
 		}
+	}
+
+	return nil
+}
 
-		s.logger.Printf("Backup completed for %s: %s (%s)", dbName, result.BackupID, backup.FormatBytes(result.SizeBytes))
+// runPlugins calls the configured plugins for a hook and logs what they did.
+func (s *Scheduler) runPlugins(hook string, event *plugin.Event) {
+	registry := plugin.NewRegistry(s.getConfig().Plugins)
+	if len(registry.Plugins()) == 0 {
+		return
+	}
 
-		// Apply retention policy if configured
-		if dbConfig.Retention != nil && !dbConfig.Retention.KeepAll {
-			retentionService := backup.NewRetentionService(s.storage)
-			cleanupResult, err := retentionService.ApplyRetentionPolicy(dbName, dbConfig.Retention, false)
-			if err != nil {
-				s.logger.Printf("Retention cleanup failed for %s: %v", dbName, err)
-			} else if len(cleanupResult.ToDelete) > 0 {
-				s.logger.Printf("Cleaned up %d old backup(s) for %s", len(cleanupResult.ToDelete), dbName)
+	event.Time = time.Now().UTC()
+	for _, result := range registry.Dispatch(hook, event) {
+		switch {
+		case result.Err != nil:
+			s.logger.Printf("Plugin %s failed on %s for %s: %v", result.Plugin, hook, event.Database, result.Err)
+		case result.Message != "":
+			s.logger.Printf("Plugin %s on %s for %s: %s", result.Plugin, hook, event.Database, result.Message)
+		}
+	}
+}
+
+// checkFreshness logs an alert when a database's last successful backup
+// becomes older than its freshness SLA, and again once it recovers.
+func (s *Scheduler) checkFreshness() {
+	cfg := s.getConfig()
+	stored, _ := s.storage.ListDatabasesIn(cfg.StoragePaths())
+	for _, dbName := range cfg.BackupNames(stored) {
+		sla, err := cfg.GetEffectiveFreshness(dbName)
+		if err != nil || sla == 0 {
+			continue
+		}
+
+		// A database without backups has no successful backup
+		backups, _ := s.storageFor(dbName, nil).ListBackups(dbName)
+		freshness := status.CheckFreshness(backups, sla, time.Now())
+
+		s.mu.Lock()
+		wasBreached := s.breached[dbName]
+		s.breached[dbName] = freshness.Breached
+		s.mu.Unlock()
+
+		switch {
+		case freshness.Breached && !wasBreached:
+			if freshness.LastSuccess == nil {
+				s.logger.Printf("ALERT: Freshness SLA breached for %s: no successful backup (SLA %s)",
+					dbName, backup.FormatDuration(sla))
+			} else {
+				s.logger.Printf("ALERT: Freshness SLA breached for %s: last successful backup is %s old (SLA %s)",
+					dbName, backup.FormatDuration(freshness.Age), backup.FormatDuration(sla))
 			}
+		case !freshness.Breached && wasBreached:
+			s.logger.Printf("Freshness SLA restored for %s", dbName)
 		}
 	}
 }
 
-// GetNextRun returns the next run time for a database schedule.
-func (s *Scheduler) GetNextRun(dbName string) (time.Time, error) {
+// sendDigest emails the digest summarizing all databases to its recipients.
+func (s *Scheduler) sendDigest() {
+	cfg := s.getConfig()
+	if cfg.Digest == nil {
+		return
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil {
+		s.logger.Printf("Failed to build digest: %v", err)
+		return
+	}
+	digest, err := status.NewService(mgr, s.storage).GetDigest(cfg.Digest.Period)
+	if err != nil {
+		s.logger.Printf("Failed to build digest: %v", err)
+		return
+	}
+
+	email := &notify.Email{
+		From:    cfg.Digest.SMTP.From,
+		To:      cfg.Digest.Recipients,
+		Subject: digest.Subject(),
+		Body:    digest.Format(),
+	}
+	if err := notify.Send(cfg.Digest.SMTP, email); err != nil {
+		s.logger.Printf("Failed to send digest: %v", err)
+		return
+	}
+	s.logger.Printf("Sent %s digest to %s", digest.Period, strings.Join(cfg.Digest.Recipients, ", "))
+}
+
+// connect resolves the engine of dbConfig and opens a client to its server,
+// retrying as configured for the daemon. The client reconnects when its
+// connections die during a long run.
+func (s *Scheduler) connect(dbConfig *config.DatabaseConfig) (backup.Engine, backup.Introspector, *mysql.Config, error) {
+	cfg := s.getConfig()
+	attempts, backoff, err := cfg.Daemon.ConnectRetry()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	password, err := dbConfig.Password()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	eng, err := backup.GetEngine(dbConfig.Type)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	mysqlConfig := &mysql.Config{
+		Host:     dbConfig.Host,
+		Port:     dbConfig.Port,
+		User:     dbConfig.User,
+		Password: password,
+		Database: dbConfig.Database,
+		Timeout:  10 * time.Second,
+		SSL:      backupconfig.SSL(dbConfig.TLS),
+
+		MysqldumpPath: cfg.GetEffectiveMysqldumpPath(dbConfig.Name),
+		MysqlPath:     cfg.GetEffectiveMysqlPath(dbConfig.Name),
+
+		ConnectAttempts: attempts,
+		ConnectBackoff:  backoff,
+		AutoReconnect:   true,
+	}
+
+	client, err := eng.NewIntrospector(backup.MySQLConnection(mysqlConfig))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := client.Connect(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return eng, client, mysqlConfig, nil
+}
+
+// storageFor returns the storage receiving the backups of dbName with
+// schedule: the schedule's output directory, the database's storage_path or
+// the daemon's storage, all in the same layout. schedule may be nil.
+func (s *Scheduler) storageFor(dbName string, schedule *config.ScheduleConfig) *storage.LocalStorage {
+	if schedule != nil && schedule.Output != "" {
+		return s.storage.At(schedule.Output)
+	}
+	return s.storage.At(s.getConfig().GetEffectiveStoragePath(dbName))
+}
+
+// enforceQuota prunes the oldest backups so a backup of size needed fits
+// within the quota policy.
+func (s *Scheduler) enforceQuota(stor *storage.LocalStorage, dbName string, policy *backup.QuotaPolicy, needed int64) {
+	retentionService := backup.NewRetentionService(stor)
+	result, err := retentionService.EnforceQuota(dbName, policy, needed)
+	if err != nil {
+		s.recordPrune(dbName, "quota", result, err)
+		s.logger.Printf("Quota cleanup failed for %s: %v", dbName, err)
+		return
+	}
+	if len(result.ToDelete) > 0 {
+		s.recordPrune(dbName, "quota", result, nil)
+		s.logger.Printf("Pruned %d old backup(s) for %s to stay within quota (%s reclaimed)",
+			len(result.ToDelete), dbName, backup.FormatBytes(result.SpaceReclaimed))
+	}
+}
+
+// recordPrune writes a retention or quota cleanup to the audit log.
+func (s *Scheduler) recordPrune(dbName, reason string, result *backup.CleanupResult, pruneErr error) {
+	auditLogger := s.auditLogger()
+	if auditLogger == nil {
+		return
+	}
+
+	entry := audit.NewEntry(audit.ActionPrune, dbName, "", pruneErr)
+	if result != nil {
+		ids := make([]string, len(result.ToDelete))
+		for i, b := range result.ToDelete {
+			ids[i] = b.BackupID
+		}
+		entry.Details = fmt.Sprintf("deleted %d backup(s) by scheduler (%s): %s", len(ids), reason, strings.Join(ids, ", "))
+	}
+
+	if err := auditLogger.Record(entry); err != nil {
+		s.logger.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// queuedFor describes how long a job waited in the queue, if noticeably.
+func queuedFor(waited time.Duration) string {
+	if waited < time.Second {
+		return ""
+	}
+	return fmt.Sprintf(" (queued for %s)", backup.FormatDuration(waited.Round(time.Second)))
+}
+
+// Queued returns the jobs waiting for a free slot, in the order they will
+// start.
+func (s *Scheduler) Queued() []QueuedInfo {
+	return s.queue.queued()
+}
+
+// track records the start of a run and returns a function recording its
+// outcome, so Running and Failures reflect what the daemon is doing.
+func (s *Scheduler) track(dbName, label string) func(error) {
+	s.mu.Lock()
+	s.running[label] = RunInfo{Label: label, Database: dbName, Time: time.Now()}
+	s.mu.Unlock()
+
+	return func(err error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.running, label)
+		if err != nil {
+			s.failures[label] = RunInfo{Label: label, Database: dbName, Time: time.Now(), Error: err.Error()}
+		} else {
+			delete(s.failures, label)
+		}
+	}
+}
+
+// Running returns the runs in progress, oldest first.
+func (s *Scheduler) Running() []RunInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := make([]RunInfo, 0, len(s.running))
+	for _, run := range s.running {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Time.Before(runs[j].Time) })
+	return runs
+}
+
+// Failures returns the schedules whose last run failed, most recent first.
+func (s *Scheduler) Failures() []RunInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	failures := make([]RunInfo, 0, len(s.failures))
+	for _, failure := range s.failures {
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Time.After(failures[j].Time) })
+	return failures
+}
+
+// GetNextRun returns the next run time for a database schedule. An empty
+// scheduleName selects the default schedule.
+func (s *Scheduler) GetNextRun(dbName, scheduleName string) (time.Time, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	entryID, exists := s.jobs[dbName]
+	if scheduleName == "" {
+		scheduleName = config.DefaultScheduleName
+	}
+	entryID, exists := s.jobs[scheduleKey{database: dbName, schedule: scheduleName}]
 	if !exists {
-		return time.Time{}, fmt.Errorf("no active schedule for %s", dbName)
+		return time.Time{}, fmt.Errorf("no active schedule for %s", config.ScheduleLabel(dbName, scheduleName))
 	}
 
 	entry := s.cron.Entry(entryID)
@@ -206,18 +905,26 @@ func (s *Scheduler) ListSchedules() []ScheduleInfo {
 	defer s.mu.RUnlock()
 
 	var schedules []ScheduleInfo
-	for dbName, entryID := range s.jobs {
+	for key, entryID := range s.jobs {
 		entry := s.cron.Entry(entryID)
 		if entry.ID == 0 {
 			continue
 		}
 
-		dbConfig := s.config.Databases[dbName]
+		schedule := s.config.Databases[key.database].FindSchedule(key.schedule)
+		if schedule == nil {
+			continue
+		}
+		nextRun := entry.Next
+		if _, ok := schedule.InBlackout(nextRun); ok {
+			nextRun = NextRun(entry.Schedule, schedule, nextRun)
+		}
 		schedules = append(schedules, ScheduleInfo{
-			Database: dbName,
-			Cron:     dbConfig.Schedule.Cron,
-			Enabled:  dbConfig.Schedule.Enabled,
-			NextRun:  entry.Next,
+			Database: key.database,
+			Schedule: key.schedule,
+			Cron:     schedule.Cron,
+			Enabled:  schedule.Enabled,
+			NextRun:  nextRun,
 			PrevRun:  entry.Prev,
 		})
 	}
@@ -225,9 +932,19 @@ func (s *Scheduler) ListSchedules() []ScheduleInfo {
 	return schedules
 }
 
+// RunInfo describes a run in progress (Time is its start) or the failure of
+// a schedule's last run (Time is when it failed).
+type RunInfo struct {
+	Label    string
+	Database string
+	Time     time.Time
+	Error    string
+}
+
 // ScheduleInfo contains information about a scheduled backup.
 type ScheduleInfo struct {
 	Database string
+	Schedule string // Schedule name, config.DefaultScheduleName for the default schedule
 	Cron     string
 	Enabled  bool
 	NextRun  time.Time
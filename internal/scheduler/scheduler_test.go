@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		wantNext time.Time
+		wantErr  string
+	}{
+		{
+			name:     "five fields",
+			expr:     "30 2 * * *",
+			wantNext: time.Date(2025, 1, 16, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "leading seconds field",
+			expr:     "15 30 2 * * *",
+			wantNext: time.Date(2025, 1, 16, 2, 30, 15, 0, time.UTC),
+		},
+		{
+			name:     "descriptor",
+			expr:     "@daily",
+			wantNext: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone",
+			expr:     "0 2 * * *",
+			timezone: "Asia/Jakarta",
+			wantNext: time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone with seconds",
+			expr:     "30 0 2 * * *",
+			timezone: "Asia/Jakarta",
+			wantNext: time.Date(2025, 1, 15, 19, 0, 30, 0, time.UTC),
+		},
+		{
+			name:     "CRON_TZ prefix",
+			expr:     "CRON_TZ=Asia/Jakarta 0 2 * * *",
+			wantNext: time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "unknown timezone",
+			expr:     "0 2 * * *",
+			timezone: "Mars/Olympus_Mons",
+			wantErr:  "unknown timezone",
+		},
+		{
+			name:    "unknown CRON_TZ",
+			expr:    "CRON_TZ=Mars/Olympus_Mons 0 2 * * *",
+			wantErr: "invalid cron expression",
+		},
+		{
+			name:    "too few fields",
+			expr:    "0 2 * *",
+			wantErr: "invalid cron expression",
+		},
+		{
+			name:    "too many fields",
+			expr:    "0 0 2 * * * 2025",
+			wantErr: "invalid cron expression",
+		},
+		{
+			name:    "out of range",
+			expr:    "0 25 * * *",
+			wantErr: "invalid cron expression",
+		},
+		{
+			name:    "empty",
+			expr:    "",
+			wantErr: "invalid cron expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := ParseCron(tt.expr, tt.timezone)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNext, sched.Next(from).UTC())
+		})
+	}
+}
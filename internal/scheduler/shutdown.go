@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/fsutil"
+	"github.com/erickhilda/cadangkan/internal/paths"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+)
+
+// drainPollInterval is how often Shutdown checks for running jobs.
+const drainPollInterval = 200 * time.Millisecond
+
+// Shutdown stops starting jobs and waits for the running ones to finish
+// until ctx is done. It returns the jobs that were queued but never
+// started, and the runs still in progress when it gave up, which are
+// recorded as interrupted in the audit log.
+func (s *Scheduler) Shutdown(ctx context.Context) (pending []QueuedInfo, interrupted []RunInfo) {
+	s.cron.Stop()
+	s.queue.close()
+
+	// Jobs waiting out their jitter wake up and are refused by the queue
+	s.mu.Lock()
+	if !s.stopping {
+		s.stopping = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	s.delayed.Wait()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+drain:
+	for len(s.Running()) > 0 {
+		select {
+		case <-ctx.Done():
+			break drain
+		case <-ticker.C:
+		}
+	}
+
+	interrupted = s.Running()
+	for _, run := range interrupted {
+		s.logger.Printf("Interrupted %s after %s", run.Label, backup.FormatDuration(time.Since(run.Time).Round(time.Second)))
+		s.recordInterrupted(run)
+	}
+
+	return s.queue.refused(), interrupted
+}
+
+// Resume queues jobs left pending by a previous Shutdown, once per
+// schedule. Jobs whose database or schedule is no longer configured are
+// dropped.
+func (s *Scheduler) Resume(pending []QueuedInfo) {
+	cfg := s.getConfig()
+	seen := make(map[string]bool)
+	for _, job := range pending {
+		if seen[job.Label] {
+			continue
+		}
+		seen[job.Label] = true
+
+		dbConfig, ok := cfg.Database(job.Database)
+		if !ok {
+			s.logger.Printf("Dropping queued %s: database is no longer configured", job.Label)
+			continue
+		}
+
+		if job.RestoreTest {
+			if dbConfig.RestoreTest == nil {
+				s.logger.Printf("Dropping queued %s: restore test is no longer configured", job.Label)
+				continue
+			}
+			s.logger.Printf("Resuming queued %s", job.Label)
+			go s.createRestoreTestJob(job.Database, dbConfig)()
+			continue
+		}
+
+		schedule := dbConfig.FindSchedule(job.Schedule)
+		if schedule == nil {
+			s.logger.Printf("Dropping queued %s: schedule is no longer configured", job.Label)
+			continue
+		}
+		s.logger.Printf("Resuming queued %s", job.Label)
+		go s.createBackupJob(job.Database, dbConfig, schedule)()
+	}
+}
+
+// recordInterrupted writes a run cut short by shutdown to the audit log.
+func (s *Scheduler) recordInterrupted(run RunInfo) {
+	auditLogger := s.auditLogger()
+	if auditLogger == nil {
+		return
+	}
+
+	entry := audit.NewEntry(audit.ActionInterrupted, run.Database, run.Label, errors.New("daemon stopped before the run finished"))
+	entry.Details = fmt.Sprintf("started %s, ran for %s",
+		run.Time.Format(time.RFC3339), backup.FormatDuration(time.Since(run.Time).Round(time.Second)))
+
+	if err := auditLogger.Record(entry); err != nil {
+		s.logger.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// DefaultQueuePath returns where the daemon persists its queue on shutdown.
+func DefaultQueuePath() (string, error) {
+	return paths.DataFile("queue.json")
+}
+
+// SaveQueue writes the pending jobs to path, removing the file when there
+// are none.
+func SaveQueue(path string, pending []QueuedInfo) error {
+	if len(pending) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove queue file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queue: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write queue file: %w", err)
+	}
+	return nil
+}
+
+// LoadQueue reads the jobs persisted by SaveQueue. A missing file is an
+// empty queue.
+func LoadQueue(path string) ([]QueuedInfo, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue file: %w", err)
+	}
+
+	var pending []QueuedInfo
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse queue file %s: %w", path, err)
+	}
+	return pending, nil
+}
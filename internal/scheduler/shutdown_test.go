@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestScheduler returns a scheduler for cfg storing backups in a
+// temporary directory.
+func newTestScheduler(t *testing.T, cfg *config.Config) *Scheduler {
+	t.Setenv("HOME", t.TempDir())
+
+	stor, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	return New(cfg, stor)
+}
+
+// refusedLabels returns the labels of the jobs the closed queue refused.
+func refusedLabels(s *Scheduler) []string {
+	var labels []string
+	for _, job := range s.queue.refused() {
+		labels = append(labels, job.Label)
+	}
+	return labels
+}
+
+func TestSaveLoadQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	pending, err := LoadQueue(path)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+
+	queuedAt := time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)
+	saved := []QueuedInfo{
+		{Label: "shop", Database: "shop", Schedule: config.DefaultScheduleName, Priority: 10, QueuedAt: queuedAt},
+		{Label: "shop restore test", Database: "shop", RestoreTest: true, QueuedAt: queuedAt},
+	}
+	require.NoError(t, SaveQueue(path, saved))
+
+	pending, err = LoadQueue(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, pending)
+
+	// Saving an empty queue removes the file
+	require.NoError(t, SaveQueue(path, nil))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, SaveQueue(path, nil))
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+	_, err = LoadQueue(path)
+	assert.ErrorContains(t, err, "failed to parse queue file")
+}
+
+func TestResume(t *testing.T) {
+	cfg := scheduledConfig(map[string]string{"shop": "0 2 * * *", "blog": "0 3 * * *"})
+	s := newTestScheduler(t, cfg)
+
+	// A closed queue refuses the resumed jobs instead of running them
+	s.queue.close()
+	s.Resume([]QueuedInfo{
+		{Label: "shop", Database: "shop", Schedule: config.DefaultScheduleName},
+		{Label: "shop", Database: "shop", Schedule: config.DefaultScheduleName},
+		{Label: "blog/hourly", Database: "blog", Schedule: "hourly"},
+		{Label: "blog restore test", Database: "blog", RestoreTest: true},
+		{Label: "wiki", Database: "wiki", Schedule: config.DefaultScheduleName},
+	})
+
+	require.Eventually(t, func() bool { return len(s.queue.refused()) > 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"shop"}, refusedLabels(s))
+}
+
+func TestShutdownDuringJitter(t *testing.T) {
+	cfg := scheduledConfig(map[string]string{"shop": "0 2 * * *"})
+	cfg.Databases["shop"].Schedule.Jitter = "1h"
+	s := newTestScheduler(t, cfg)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- s.RunNow("shop", "", true) }()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pending, interrupted := s.Shutdown(ctx)
+
+	assert.Empty(t, interrupted)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "shop", pending[0].Label)
+	assert.Equal(t, config.DefaultScheduleName, pending[0].Schedule)
+	assert.ErrorIs(t, <-runErr, errShuttingDown)
+}
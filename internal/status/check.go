@@ -0,0 +1,145 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// CheckState is the outcome of a monitoring check. Its value is the exit
+// code of the check, following the Nagios plugin conventions.
+type CheckState int
+
+// Check states
+const (
+	CheckOK       CheckState = 0
+	CheckWarning  CheckState = 1
+	CheckCritical CheckState = 2
+	CheckUnknown  CheckState = 3
+)
+
+// String returns the state as shown in a check's output.
+func (s CheckState) String() string {
+	switch s {
+	case CheckOK:
+		return "OK"
+	case CheckWarning:
+		return "WARNING"
+	case CheckCritical:
+		return "CRITICAL"
+	}
+	return "UNKNOWN"
+}
+
+// CheckThresholds are the limits a check compares the backups against.
+// Zero values are not checked.
+type CheckThresholds struct {
+	// WarnAge is the age of the last successful backup that is a warning
+	WarnAge time.Duration
+
+	// MaxAge is the age of the last successful backup that is critical
+	MaxAge time.Duration
+
+	// MinCount is the number of successful backups below which it is critical
+	MinCount int
+}
+
+// CheckResult is the outcome of checking the backups of one database.
+type CheckResult struct {
+	State       CheckState
+	Database    string
+	Count       int        // Successful backups
+	LastSuccess *time.Time // When the last successful backup was taken, if any
+	Age         time.Duration
+	Problems    []string // Why the state is not OK
+	Thresholds  CheckThresholds
+}
+
+// CheckBackups checks the backups of a database, sorted newest first,
+// against the thresholds at now.
+func CheckBackups(database string, backups []storage.BackupListEntry, thresholds CheckThresholds, now time.Time) *CheckResult {
+	result := &CheckResult{State: CheckOK, Database: database, Thresholds: thresholds}
+
+	for _, b := range backups {
+		if b.Status != backup.StatusCompleted && b.Status != "" {
+			continue
+		}
+		result.Count++
+		if result.LastSuccess == nil {
+			createdAt := b.CreatedAt
+			result.LastSuccess = &createdAt
+			result.Age = now.Sub(createdAt)
+		}
+	}
+
+	if thresholds.MinCount > 0 && result.Count < thresholds.MinCount {
+		result.raise(CheckCritical, fmt.Sprintf("%d backups, expected at least %d", result.Count, thresholds.MinCount))
+	}
+
+	switch {
+	case result.LastSuccess == nil:
+		if limit := thresholds.MaxAge; limit > 0 || thresholds.WarnAge > 0 {
+			if limit == 0 {
+				limit = thresholds.WarnAge
+			}
+			result.raise(CheckCritical, fmt.Sprintf("no backup within %s", backup.FormatDuration(limit)))
+		}
+	case thresholds.MaxAge > 0 && result.Age > thresholds.MaxAge:
+		result.raise(CheckCritical, fmt.Sprintf("last backup older than %s", backup.FormatDuration(thresholds.MaxAge)))
+	case thresholds.WarnAge > 0 && result.Age > thresholds.WarnAge:
+		result.raise(CheckWarning, fmt.Sprintf("last backup older than %s", backup.FormatDuration(thresholds.WarnAge)))
+	}
+	return result
+}
+
+// raise records a problem, keeping the most severe state.
+func (r *CheckResult) raise(state CheckState, problem string) {
+	if state > r.State {
+		r.State = state
+	}
+	r.Problems = append(r.Problems, problem)
+}
+
+// String returns the one-line check output: the state, a summary, and
+// performance data after "|".
+func (r *CheckResult) String() string {
+	var summary string
+	if r.LastSuccess == nil {
+		summary = fmt.Sprintf("%s: no successful backup", r.Database)
+	} else {
+		summary = fmt.Sprintf("%s: last backup %s ago, %d backups",
+			r.Database, backup.FormatDuration(r.Age.Truncate(time.Second)), r.Count)
+	}
+	if len(r.Problems) > 0 {
+		summary += " (" + strings.Join(r.Problems, "; ") + ")"
+	}
+
+	perf := fmt.Sprintf("count=%d;;%s", r.Count, minThreshold(r.Thresholds.MinCount))
+	if r.LastSuccess != nil {
+		perf = fmt.Sprintf("age=%ds;%s;%s %s", int64(r.Age.Seconds()),
+			secondsThreshold(r.Thresholds.WarnAge), secondsThreshold(r.Thresholds.MaxAge), perf)
+	}
+	return fmt.Sprintf("CADANGKAN %s - %s | %s", r.State, summary, perf)
+}
+
+// CheckError returns the output of a check that could not run.
+func CheckError(err error) string {
+	return fmt.Sprintf("CADANGKAN %s - %v", CheckUnknown, err)
+}
+
+func secondsThreshold(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", int64(d.Seconds()))
+}
+
+func minThreshold(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:", n)
+}
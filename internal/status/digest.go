@@ -0,0 +1,188 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// Digest summarizes the backups of all databases over a period, for
+// sending to people who do not watch the daemon's log.
+type Digest struct {
+	Period    string // config.DigestDaily or config.DigestWeekly
+	Since     time.Time
+	Until     time.Time
+	Databases []DatabaseDigest
+
+	BackupsTaken  int
+	Failures      int
+	StorageUsed   int64
+	StorageGrowth int64
+}
+
+// DatabaseDigest summarizes the backups of one database over a period.
+type DatabaseDigest struct {
+	Name         string
+	Status       string // "healthy", "warning", "critical"
+	BackupsTaken int
+	Failures     []string // Failed backups and operations, oldest first
+	StorageUsed  int64
+
+	// Growth is how much the latest backup grew over the period, compared
+	// to the last one taken before it
+	Growth int64
+
+	// PendingDeletions are the backups the next retention cleanup deletes
+	PendingDeletions []storage.BackupListEntry
+}
+
+// GetDigest summarizes the backups of all databases taken in the period
+// ending now. Failures are taken from the daemon and the audit log.
+func (s *Service) GetDigest(period string) (*Digest, error) {
+	cfg, err := s.configManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	window := (&config.DigestConfig{Period: period}).Window()
+	digest := &Digest{Period: period, Until: time.Now()}
+	if digest.Period == "" {
+		digest.Period = config.DigestDaily
+	}
+	digest.Since = digest.Until.Add(-window)
+
+	failures := s.recentFailures(cfg, digest.Since)
+
+	for _, dbName := range s.backupNames(cfg) {
+		dbStatus, err := s.GetDatabaseStatus(dbName)
+		if err != nil {
+			continue
+		}
+		backups, _ := s.storageFor(cfg, dbName).ListBackups(dbName)
+
+		db := DatabaseDigest{
+			Name:        dbName,
+			Status:      dbStatus.Status,
+			StorageUsed: dbStatus.StorageUsed,
+			Failures:    failures[dbName],
+		}
+
+		// Backups are sorted newest first
+		var latest, previous *storage.BackupListEntry
+		for i := range backups {
+			b := &backups[i]
+			if b.CreatedAt.After(digest.Since) {
+				db.BackupsTaken++
+				if latest == nil {
+					latest = b
+				}
+			} else if previous == nil {
+				previous = b
+			}
+		}
+		if latest != nil && previous != nil {
+			db.Growth = latest.SizeBytes - previous.SizeBytes
+		}
+
+		if dbConfig, ok := cfg.Database(dbName); ok && dbConfig.Retention != nil && !dbConfig.Retention.KeepAll {
+			result, err := backup.NewRetentionService(s.storage).ApplyRetention(dbName, backupconfig.RetentionPolicy(dbConfig.Retention), true)
+			if err == nil {
+				db.PendingDeletions = result.ToDelete
+			}
+		}
+
+		digest.Databases = append(digest.Databases, db)
+		digest.BackupsTaken += db.BackupsTaken
+		digest.Failures += len(db.Failures)
+		digest.StorageUsed += db.StorageUsed
+		digest.StorageGrowth += db.Growth
+	}
+
+	return digest, nil
+}
+
+// recentFailures returns the failures since a time by database: the last
+// failed run of each daemon schedule and failed operations in the audit log.
+func (s *Service) recentFailures(cfg *config.Config, since time.Time) map[string][]string {
+	failures := make(map[string][]string)
+
+	if logger, err := audit.FromConfig(cfg); err == nil {
+		entries, _ := audit.ReadEntries(logger.Path())
+		for _, entry := range entries {
+			if entry.Result != audit.ResultFailure || entry.Time.Before(since) {
+				continue
+			}
+			failures[entry.Database] = append(failures[entry.Database],
+				fmt.Sprintf("%s %s failed: %s", entry.Time.Format("2006-01-02 15:04"), entry.Action, entry.Error))
+		}
+	}
+
+	if daemon := s.daemon(); daemon != nil {
+		for _, run := range daemon.LastErrors {
+			if run.Time.Before(since) {
+				continue
+			}
+			dbName, _, _ := strings.Cut(run.Label, "/")
+			failures[dbName] = append(failures[dbName],
+				fmt.Sprintf("%s backup of %s failed: %s", run.Time.Format("2006-01-02 15:04"), run.Label, run.Error))
+		}
+	}
+
+	return failures
+}
+
+// Subject returns the email subject of the digest.
+func (d *Digest) Subject() string {
+	subject := fmt.Sprintf("cadangkan %s digest: %d backup(s)", d.Period, d.BackupsTaken)
+	if d.Failures > 0 {
+		subject += fmt.Sprintf(", %d failure(s)", d.Failures)
+	}
+	return subject
+}
+
+// Format renders the digest as plain text.
+func (d *Digest) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Backup digest for %s to %s\n\n",
+		d.Since.Format("2006-01-02 15:04"), d.Until.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "Backups taken:  %d\n", d.BackupsTaken)
+	fmt.Fprintf(&b, "Failures:       %d\n", d.Failures)
+	fmt.Fprintf(&b, "Storage used:   %s (%s)\n", backup.FormatBytes(d.StorageUsed), formatGrowth(d.StorageGrowth))
+
+	if len(d.Databases) == 0 {
+		b.WriteString("\nNo databases configured.\n")
+	}
+
+	for _, db := range d.Databases {
+		fmt.Fprintf(&b, "\n%s [%s]\n", db.Name, db.Status)
+		fmt.Fprintf(&b, "  Backups taken:  %d\n", db.BackupsTaken)
+		fmt.Fprintf(&b, "  Storage used:   %s (%s)\n", backup.FormatBytes(db.StorageUsed), formatGrowth(db.Growth))
+		for _, failure := range db.Failures {
+			fmt.Fprintf(&b, "  Failure:        %s\n", failure)
+		}
+		if n := len(db.PendingDeletions); n > 0 {
+			var size int64
+			for _, entry := range db.PendingDeletions {
+				size += entry.SizeBytes
+			}
+			fmt.Fprintf(&b, "  Retention:      %d backup(s) due for deletion (%s)\n", n, backup.FormatBytes(size))
+		}
+	}
+
+	return b.String()
+}
+
+// formatGrowth describes a change in size, e.g. "+1.2 MB".
+func formatGrowth(bytes int64) string {
+	if bytes < 0 {
+		return "-" + backup.FormatBytes(-bytes)
+	}
+	return "+" + backup.FormatBytes(bytes)
+}
@@ -0,0 +1,44 @@
+package status
+
+import (
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// Freshness compares the age of a database's last successful backup with
+// its freshness SLA.
+type Freshness struct {
+	// SLA is the maximum allowed age of the last successful backup
+	SLA time.Duration
+
+	// LastSuccess is when the last successful backup was taken, if any
+	LastSuccess *time.Time
+
+	// Age is how old the last successful backup is at check time
+	Age time.Duration
+
+	// Breached is true when there is no successful backup younger than SLA
+	Breached bool
+}
+
+// CheckFreshness evaluates backups, sorted newest first, against sla at now.
+// A zero sla is never breached.
+func CheckFreshness(backups []storage.BackupListEntry, sla time.Duration, now time.Time) Freshness {
+	freshness := Freshness{SLA: sla}
+
+	for _, b := range backups {
+		if b.Status == backup.StatusCompleted || b.Status == "" {
+			createdAt := b.CreatedAt
+			freshness.LastSuccess = &createdAt
+			freshness.Age = now.Sub(createdAt)
+			break
+		}
+	}
+
+	if sla > 0 {
+		freshness.Breached = freshness.LastSuccess == nil || freshness.Age > sla
+	}
+	return freshness
+}
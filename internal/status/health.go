@@ -4,7 +4,7 @@ import (
 	"math"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 )
 
 const (
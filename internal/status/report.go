@@ -0,0 +1,294 @@
+package status
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+)
+
+// Report formats
+const (
+	ReportMarkdown = "md"
+	ReportHTML     = "html"
+)
+
+// Report describes the backups of all databases over a period, for
+// compliance reviews and other readers without access to the server.
+type Report struct {
+	Since     time.Time
+	Until     time.Time
+	Databases []DatabaseReport
+}
+
+// DatabaseReport describes the backups of one database over a period.
+type DatabaseReport struct {
+	Name   string
+	Type   string
+	Status string // "healthy", "warning", "critical"
+
+	// Days is the length of the period in days, and CoveredDays how many
+	// of them have at least one successful backup
+	Days        int
+	CoveredDays int
+
+	Successful int
+	Failed     int // Failed backups still on disk and the daemon's last failed run
+
+	LastBackup  *time.Time
+	LatestSize  int64
+	AverageSize int64 // Of the successful backups in the period
+	StorageUsed int64
+
+	// Drills are the restore tests run in the period, newest first
+	Drills []DrillResult
+}
+
+// DrillResult is the outcome of a restore drill, as recorded in the audit
+// log.
+type DrillResult struct {
+	Time     time.Time
+	BackupID string
+	Passed   bool
+	Details  string // What was restored, or why the drill failed
+}
+
+// SuccessRate returns the percentage of backups in the period that
+// succeeded, or 0 when there were none.
+func (r *DatabaseReport) SuccessRate() float64 {
+	total := r.Successful + r.Failed
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Successful) / float64(total) * 100
+}
+
+// Coverage returns the percentage of days in the period with a successful
+// backup.
+func (r *DatabaseReport) Coverage() float64 {
+	if r.Days == 0 {
+		return 0
+	}
+	return float64(r.CoveredDays) / float64(r.Days) * 100
+}
+
+// GetReport describes the backups of all databases over the period ending
+// now. Restore drill results are taken from the audit log.
+func (s *Service) GetReport(period time.Duration) (*Report, error) {
+	cfg, err := s.configManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	report := &Report{Until: time.Now()}
+	report.Since = report.Until.Add(-period)
+	days := int((period + 24*time.Hour - 1) / (24 * time.Hour))
+
+	drills := s.drillResults(cfg, report.Since)
+
+	var lastErrors map[string]time.Time
+	if daemon := s.daemon(); daemon != nil {
+		lastErrors = make(map[string]time.Time)
+		for _, run := range daemon.LastErrors {
+			dbName, _, _ := strings.Cut(run.Label, "/")
+			lastErrors[dbName] = run.Time
+		}
+	}
+
+	for _, dbName := range s.backupNames(cfg) {
+		dbStatus, err := s.GetDatabaseStatus(dbName)
+		if err != nil {
+			continue
+		}
+		backups, _ := s.storageFor(cfg, dbName).ListBackups(dbName)
+
+		db := DatabaseReport{
+			Name:        dbName,
+			Type:        dbStatus.Type,
+			Status:      dbStatus.Status,
+			Days:        days,
+			StorageUsed: dbStatus.StorageUsed,
+			Drills:      drills[dbName],
+		}
+
+		covered := make(map[string]bool)
+		var totalSize int64
+		for _, b := range backups {
+			if b.CreatedAt.Before(report.Since) {
+				continue
+			}
+			switch b.Status {
+			case backup.StatusCompleted, "":
+				db.Successful++
+				totalSize += b.SizeBytes
+				covered[b.CreatedAt.Format("2006-01-02")] = true
+				if db.LastBackup == nil {
+					createdAt := b.CreatedAt
+					db.LastBackup = &createdAt
+					db.LatestSize = b.SizeBytes
+				}
+			case backup.StatusFailed:
+				db.Failed++
+			}
+		}
+		if failedAt, ok := lastErrors[dbName]; ok && failedAt.After(report.Since) {
+			db.Failed++
+		}
+		db.CoveredDays = min(len(covered), days)
+		if db.Successful > 0 {
+			db.AverageSize = totalSize / int64(db.Successful)
+		}
+
+		report.Databases = append(report.Databases, db)
+	}
+
+	return report, nil
+}
+
+// drillResults returns the restore drills run since a time by database,
+// newest first.
+func (s *Service) drillResults(cfg *config.Config, since time.Time) map[string][]DrillResult {
+	drills := make(map[string][]DrillResult)
+
+	logger, err := audit.FromConfig(cfg)
+	if err != nil {
+		return drills
+	}
+	entries, _ := audit.ReadEntries(logger.Path())
+
+	// Entries are oldest first
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Action != audit.ActionRestoreTest || entry.Time.Before(since) {
+			continue
+		}
+		result := DrillResult{
+			Time:     entry.Time,
+			BackupID: entry.Target,
+			Passed:   entry.Result == audit.ResultSuccess,
+			Details:  entry.Details,
+		}
+		if !result.Passed {
+			result.Details = entry.Error
+		}
+		drills[entry.Database] = append(drills[entry.Database], result)
+	}
+	return drills
+}
+
+// WriteMarkdown writes the report as a Markdown document.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Backup Report\n\n")
+	fmt.Fprintf(&b, "Period: %s to %s\n\n", r.Since.Format("2006-01-02"), r.Until.Format("2006-01-02"))
+
+	b.WriteString("| Database | Status | Coverage | Success rate | Backups | Latest size | Average size | Storage used | Drills passed |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, db := range r.Databases {
+		fmt.Fprintf(&b, "| %s | %s | %d/%d days (%.0f%%) | %.1f%% | %d ok, %d failed | %s | %s | %s | %s |\n",
+			db.Name, db.Status, db.CoveredDays, db.Days, db.Coverage(), db.SuccessRate(),
+			db.Successful, db.Failed, backup.FormatBytes(db.LatestSize), backup.FormatBytes(db.AverageSize),
+			backup.FormatBytes(db.StorageUsed), drillSummary(db.Drills))
+	}
+
+	for _, db := range r.Databases {
+		fmt.Fprintf(&b, "\n## %s\n\n", db.Name)
+		if db.LastBackup != nil {
+			fmt.Fprintf(&b, "Last backup: %s\n\n", db.LastBackup.Format("2006-01-02 15:04"))
+		} else {
+			b.WriteString("Last backup: none in this period\n\n")
+		}
+		if len(db.Drills) == 0 {
+			b.WriteString("No restore drills in this period.\n")
+			continue
+		}
+		b.WriteString("| Time | Backup | Result | Details |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, drill := range db.Drills {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				drill.Time.Format("2006-01-02 15:04"), drill.BackupID, drillOutcome(drill), strings.ReplaceAll(drill.Details, "|", "\\|"))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// reportTemplate renders a report as a self-contained HTML page.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes":   backup.FormatBytes,
+	"drills":  drillSummary,
+	"outcome": drillOutcome,
+	"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+	"time":    func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Backup Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.healthy { color: #080; } .warning { color: #b60; } .critical { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Backup Report</h1>
+<p>Period: {{date .Since}} to {{date .Until}}</p>
+<table>
+<tr><th>Database</th><th>Status</th><th>Coverage</th><th>Success rate</th><th>Backups</th><th>Latest size</th><th>Average size</th><th>Storage used</th><th>Drills passed</th></tr>
+{{- range .Databases}}
+<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.CoveredDays}}/{{.Days}} days ({{printf "%.0f" .Coverage}}%)</td><td>{{printf "%.1f" .SuccessRate}}%</td><td>{{.Successful}} ok, {{.Failed}} failed</td><td>{{bytes .LatestSize}}</td><td>{{bytes .AverageSize}}</td><td>{{bytes .StorageUsed}}</td><td>{{drills .Drills}}</td></tr>
+{{- end}}
+</table>
+{{- range .Databases}}
+<h2>{{.Name}}</h2>
+<p>Last backup: {{if .LastBackup}}{{time .LastBackup}}{{else}}none in this period{{end}}</p>
+{{- if .Drills}}
+<table>
+<tr><th>Time</th><th>Backup</th><th>Result</th><th>Details</th></tr>
+{{- range .Drills}}
+<tr><td>{{time .Time}}</td><td>{{.BackupID}}</td><td>{{outcome .}}</td><td>{{.Details}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No restore drills in this period.</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a self-contained HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// drillSummary describes how many drills passed, e.g. "3/4".
+func drillSummary(drills []DrillResult) string {
+	if len(drills) == 0 {
+		return "-"
+	}
+	passed := 0
+	for _, drill := range drills {
+		if drill.Passed {
+			passed++
+		}
+	}
+	return fmt.Sprintf("%d/%d", passed, len(drills))
+}
+
+// drillOutcome returns "passed" or "failed".
+func drillOutcome(drill DrillResult) string {
+	if drill.Passed {
+		return "passed"
+	}
+	return "failed"
+}
@@ -3,27 +3,55 @@ package status
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
 	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/internal/control"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 )
 
 // Service provides status and health monitoring functionality.
 type Service struct {
 	configManager config.Manager
 	storage       *storage.LocalStorage
+	socketPath    string
+
+	daemonOnce  sync.Once
+	daemonState *control.DaemonState
 }
 
 // NewService creates a new status service.
 func NewService(configManager config.Manager, stor *storage.LocalStorage) *Service {
+	socketPath, _ := control.DefaultSocketPath()
 	return &Service{
 		configManager: configManager,
 		storage:       stor,
+		socketPath:    socketPath,
 	}
 }
 
+// SetSocketPath sets the control socket used to query the daemon.
+func (s *Service) SetSocketPath(path string) {
+	s.socketPath = path
+}
+
+// daemon returns the state of the running daemon, or nil if it is not
+// running. The daemon is queried once per service.
+func (s *Service) daemon() *control.DaemonState {
+	s.daemonOnce.Do(func() {
+		if s.socketPath == "" {
+			return
+		}
+		if state, err := control.GetState(s.socketPath); err == nil {
+			s.daemonState = state
+		}
+	})
+	return s.daemonState
+}
+
 // GetOverallStatus returns the overall status across all databases.
 func (s *Service) GetOverallStatus() (*OverallStatus, error) {
 	// Load configuration
@@ -33,7 +61,7 @@ func (s *Service) GetOverallStatus() (*OverallStatus, error) {
 	}
 
 	overall := &OverallStatus{
-		ServiceStatus:    "Not running", // Placeholder until service is implemented
+		ServiceStatus:    ServiceNotRunning,
 		DatabaseCount:    len(cfg.Databases),
 		ActiveCount:      0,
 		TotalBackups:     0,
@@ -44,31 +72,33 @@ func (s *Service) GetOverallStatus() (*OverallStatus, error) {
 		HealthSummary:    []string{},
 	}
 
+	if path, err := maintenance.DefaultPath(); err == nil {
+		overall.Maintenance, _ = maintenance.Load(path)
+	}
+
+	if daemon := s.daemon(); daemon != nil {
+		overall.ServiceStatus = fmt.Sprintf("Running (pid %d)", daemon.PID)
+		overall.Daemon = daemon
+	}
+
 	// Get available disk space
 	available, err := s.storage.CheckDiskSpace()
 	if err == nil {
 		overall.StorageAvailable = available
 	}
 
-	// Process each database
+	// Process each database, server entries by schema
 	var latestBackupTime *time.Time
-	dbNames := make([]string, 0, len(cfg.Databases))
-	for name := range cfg.Databases {
-		dbNames = append(dbNames, name)
-	}
-	sort.Strings(dbNames)
+	dbNames := s.backupNames(cfg)
+	overall.DatabaseCount = len(dbNames)
 
 	for _, dbName := range dbNames {
-		dbConfig := cfg.Databases[dbName]
 		dbStatus, err := s.GetDatabaseStatus(dbName)
 		if err != nil {
 			// Skip databases with errors but continue processing others
 			continue
 		}
 
-		// Set type from config
-		dbStatus.Type = dbConfig.Type
-
 		overall.Databases = append(overall.Databases, *dbStatus)
 		overall.TotalBackups += dbStatus.BackupCount
 		overall.StorageUsed += dbStatus.StorageUsed
@@ -93,6 +123,19 @@ func (s *Service) GetOverallStatus() (*OverallStatus, error) {
 	return overall, nil
 }
 
+// backupNames returns the names the backups of the configured databases
+// are stored under, see config.Config.BackupNames.
+func (s *Service) backupNames(cfg *config.Config) []string {
+	stored, _ := s.storage.ListDatabasesIn(cfg.StoragePaths())
+	return cfg.BackupNames(stored)
+}
+
+// storageFor returns the storage holding the backups of dbName, which may
+// have a backup directory of its own.
+func (s *Service) storageFor(cfg *config.Config, dbName string) *storage.LocalStorage {
+	return s.storage.At(cfg.GetEffectiveStoragePath(dbName))
+}
+
 // GetDatabaseStatus returns detailed status for a specific database.
 func (s *Service) GetDatabaseStatus(dbName string) (*DatabaseStatus, error) {
 	// Load configuration
@@ -101,7 +144,7 @@ func (s *Service) GetDatabaseStatus(dbName string) (*DatabaseStatus, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	dbConfig, exists := cfg.Databases[dbName]
+	dbConfig, exists := cfg.Database(dbName)
 	if !exists {
 		return nil, fmt.Errorf("database '%s' not found", dbName)
 	}
@@ -109,15 +152,25 @@ func (s *Service) GetDatabaseStatus(dbName string) (*DatabaseStatus, error) {
 	status := &DatabaseStatus{
 		Name:          dbName,
 		Type:          dbConfig.Type,
-		NextBackup:    "Not scheduled", // Placeholder until scheduling is implemented
+		NextBackup:    "Not scheduled",
 		RecentBackups: []backup.BackupListEntry{},
 	}
+	s.setNextBackup(status, dbConfig)
+
+	sla, err := cfg.GetEffectiveFreshness(dbName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid freshness SLA for '%s': %w", dbName, err)
+	}
 
 	// Get all backups for this database
-	backups, err := s.storage.ListBackups(dbName)
+	backups, err := s.storageFor(cfg, dbName).ListBackups(dbName)
 	if err != nil {
 		// If no backups exist, return empty status
 		status.Status = "critical"
+		if sla > 0 {
+			freshness := CheckFreshness(nil, sla, time.Now())
+			status.Freshness = &freshness
+		}
 		return status, nil
 	}
 
@@ -160,9 +213,44 @@ func (s *Service) GetDatabaseStatus(dbName string) (*DatabaseStatus, error) {
 	healthScore := CalculateHealthScore(backupEntries)
 	status.Status = GetHealthStatus(healthScore.TotalScore)
 
+	// A breached freshness SLA is critical regardless of the health score
+	if sla > 0 {
+		freshness := CheckFreshness(backups, sla, time.Now())
+		status.Freshness = &freshness
+		if freshness.Breached {
+			status.Status = "critical"
+		}
+	}
+
 	return status, nil
 }
 
+// setNextBackup fills in the next scheduled backup of a database from the
+// running daemon.
+func (s *Service) setNextBackup(status *DatabaseStatus, dbConfig *config.DatabaseConfig) {
+	daemon := s.daemon()
+	if daemon == nil {
+		for _, schedule := range dbConfig.AllSchedules() {
+			if schedule.Enabled {
+				status.NextBackup = "Daemon not running"
+				return
+			}
+		}
+		return
+	}
+
+	for _, schedule := range daemon.Schedules {
+		if schedule.Database != status.Name || schedule.NextRun.IsZero() {
+			continue
+		}
+		if status.NextRun == nil || schedule.NextRun.Before(*status.NextRun) {
+			next := schedule.NextRun
+			status.NextRun = &next
+			status.NextBackup = config.ScheduleLabel(schedule.Database, schedule.Schedule)
+		}
+	}
+}
+
 // GetStorageUsage returns storage usage information.
 func (s *Service) GetStorageUsage() (*StorageUsage, error) {
 	// Load configuration
@@ -184,14 +272,8 @@ func (s *Service) GetStorageUsage() (*StorageUsage, error) {
 
 	// Collect all backups across all databases
 	var allBackups []backup.BackupListEntry
-	dbNames := make([]string, 0, len(cfg.Databases))
-	for name := range cfg.Databases {
-		dbNames = append(dbNames, name)
-	}
-	sort.Strings(dbNames)
-
-	for _, dbName := range dbNames {
-		backups, err := s.storage.ListBackups(dbName)
+	for _, dbName := range s.backupNames(cfg) {
+		backups, err := s.storageFor(cfg, dbName).ListBackups(dbName)
 		if err != nil {
 			continue
 		}
@@ -248,6 +330,7 @@ func (s *Service) generateHealthSummary(databases []DatabaseStatus) []string {
 	warningCount := 0
 	criticalCount := 0
 	neverBackedUp := 0
+	breachedCount := 0
 
 	for _, db := range databases {
 		switch db.Status {
@@ -262,6 +345,9 @@ func (s *Service) generateHealthSummary(databases []DatabaseStatus) []string {
 		if db.BackupCount == 0 {
 			neverBackedUp++
 		}
+		if db.Freshness != nil && db.Freshness.Breached {
+			breachedCount++
+		}
 	}
 
 	if healthyCount > 0 && warningCount == 0 && criticalCount == 0 {
@@ -282,6 +368,10 @@ func (s *Service) generateHealthSummary(databases []DatabaseStatus) []string {
 		summary = append(summary, fmt.Sprintf("⚠ %d database(s) never backed up", neverBackedUp))
 	}
 
+	if breachedCount > 0 {
+		summary = append(summary, fmt.Sprintf("✗ %d database(s) breach their freshness SLA", breachedCount))
+	}
+
 	// Check for failed backups
 	totalFailed := 0
 	for _, db := range databases {
@@ -3,12 +3,19 @@ package status
 import (
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/backup"
+	"github.com/erickhilda/cadangkan/internal/control"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
 )
 
+// ServiceNotRunning is the service status when no daemon answers.
+const ServiceNotRunning = "Not running"
+
 // OverallStatus represents the overall status of all databases.
 type OverallStatus struct {
 	ServiceStatus    string
+	Daemon           *control.DaemonState // nil when the daemon is not running
+	Maintenance      *maintenance.State   // nil when maintenance mode is off
 	DatabaseCount    int
 	ActiveCount      int
 	TotalBackups     int
@@ -26,12 +33,14 @@ type DatabaseStatus struct {
 	Status          string // "healthy", "warning", "critical"
 	LastBackup      *time.Time
 	LastBackupID    string
-	NextBackup      string // "Not scheduled" for now
+	NextBackup      string     // Label of the next scheduled backup, or why there is none
+	NextRun         *time.Time // nil when the daemon has no schedule for the database
 	BackupCount     int
 	SuccessfulCount int
 	FailedCount     int
 	StorageUsed     int64
 	RecentBackups   []backup.BackupListEntry
+	Freshness       *Freshness // nil when no freshness SLA is configured
 }
 
 // HealthScore represents the health score for a database.
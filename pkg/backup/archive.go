@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArchiveDirectory packs the regular files of srcDir into a single tar file
+// at outputPath so multi-file dumps can be stored as one managed backup.
+// The checksum is calculated on the archive, matching VerifyChecksum(). The
+// archive itself is not compressed, so the raw checksum is the same.
+func ArchiveDirectory(srcDir, outputPath string) (*CompressResult, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, WrapCompressionError(srcDir, "failed to read dump directory", err)
+	}
+
+	// Sort for a deterministic archive layout
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return nil, WrapCompressionError(outputPath, "failed to create archive file", err)
+	}
+	defer outFile.Close()
+
+	hasher := sha256.New()
+	counter := NewCountingWriter(io.MultiWriter(outFile, hasher))
+	tw := tar.NewWriter(counter)
+
+	var bytesRead int64
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		n, err := addFileToArchive(tw, filepath.Join(srcDir, entry.Name()))
+		if err != nil {
+			return nil, WrapCompressionError(outputPath, fmt.Sprintf("failed to archive %s", entry.Name()), err)
+		}
+		bytesRead += n
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, WrapCompressionError(outputPath, "failed to finalize archive", err)
+	}
+
+	checksum := fmt.Sprintf("sha256:%x", hasher.Sum(nil))
+	return &CompressResult{
+		BytesRead:    bytesRead,
+		BytesWritten: counter.BytesWritten(),
+		Checksum:     checksum,
+		RawChecksum:  checksum,
+	}, nil
+}
+
+// addFileToArchive writes a single file into the tar archive.
+func addFileToArchive(tw *tar.Writer, path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return 0, err
+	}
+
+	return copyBuffered(tw, file, DefaultBufferSize)
+}
+
+// ExtractArchive unpacks a tar archive created by ArchiveDirectory into destDir.
+// Only regular files at the top level are extracted.
+func ExtractArchive(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return WrapCompressionError(archivePath, "failed to open archive", err)
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return WrapCompressionError(archivePath, "failed to read archive", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Reject anything that would escape destDir
+		name := header.Name
+		if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return WrapCompressionError(archivePath, "invalid archive entry", fmt.Errorf("unexpected path %q", name))
+		}
+
+		if err := extractArchiveFile(tr, filepath.Join(destDir, name)); err != nil {
+			return WrapCompressionError(archivePath, fmt.Sprintf("failed to extract %s", name), err)
+		}
+	}
+}
+
+// extractArchiveFile writes the current tar entry to path.
+func extractArchiveFile(r io.Reader, path string) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := copyBuffered(out, r, DefaultBufferSize); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
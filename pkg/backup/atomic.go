@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// TableSwapper is implemented by introspectors that can move tables between
+// databases. mysql.DatabaseClient satisfies it.
+type TableSwapper interface {
+	GetSchemaObjects(database string) (*mysql.SchemaObjects, error)
+	RenameTables(renames []mysql.TableRename) error
+}
+
+// ShadowDatabaseName returns the database an atomic restore of database
+// loads the backup into before swapping it in: <database>_restoring_<time>.
+func ShadowDatabaseName(database string, t time.Time) string {
+	return swapDatabaseName(database, "_restoring_", t)
+}
+
+// replacedDatabaseName returns the database holding the tables an atomic
+// restore of database replaces until they are dropped.
+func replacedDatabaseName(database string, t time.Time) string {
+	return swapDatabaseName(database, "_replaced_", t)
+}
+
+func swapDatabaseName(database, infix string, t time.Time) string {
+	suffix := infix + t.Format("20060102150405")
+	if len(database)+len(suffix) > maxIdentifierLength {
+		database = database[:maxIdentifierLength-len(suffix)]
+	}
+	return database + suffix
+}
+
+// checkAtomic verifies the server can restore atomically: the shadow
+// database's tables must be moved into the target with RENAME TABLE.
+func checkAtomic(client Introspector) error {
+	if _, ok := client.(TableSwapper); !ok {
+		return fmt.Errorf("the %T client cannot rename tables", client)
+	}
+	return nil
+}
+
+// createShadow creates the shadow database of an atomic restore.
+func (s *RestoreService) createShadow(target string, t time.Time) (string, error) {
+	shadow := ShadowDatabaseName(target, t)
+	if s.verbose {
+		fmt.Fprintf(s.logOutput, "[DEBUG] Restoring into shadow database %s\n", shadow)
+	}
+	if err := s.client.CreateDatabase(shadow); err != nil {
+		return "", err
+	}
+	return shadow, nil
+}
+
+// swapShadow moves the tables of an atomic restore's shadow database into
+// target in a single RENAME TABLE, together with moving the tables they
+// replace out of the way, and drops what is left of both. Tables of target
+// the backup does not contain are left alone, as in a plain restore.
+// Views, triggers, routines and events cannot be renamed into another
+// database, so shadows holding any are refused before target is touched.
+func (s *RestoreService) swapShadow(shadow, target string, t time.Time) error {
+	swapper, ok := s.client.(TableSwapper)
+	if !ok {
+		return checkAtomic(s.client)
+	}
+
+	objects, err := swapper.GetSchemaObjects(shadow)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", shadow, err)
+	}
+	if objects.Views+objects.Triggers+objects.Routines+objects.Events > 0 {
+		return fmt.Errorf("the backup has %d views, %d triggers, %d routines and %d events, which cannot be swapped in; restore without --atomic",
+			objects.Views, objects.Triggers, objects.Routines, objects.Events)
+	}
+
+	tables, err := s.client.GetTables(shadow)
+	if err != nil {
+		return fmt.Errorf("failed to list the tables of %s: %w", shadow, err)
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("the backup restored no tables into %s", shadow)
+	}
+	current, err := s.client.GetTables(target)
+	if err != nil {
+		return fmt.Errorf("failed to list the tables of %s: %w", target, err)
+	}
+
+	replaced := replacedDatabaseName(target, t)
+	if err := s.client.CreateDatabase(replaced); err != nil {
+		return err
+	}
+	defer s.client.DropDatabase(replaced)
+
+	var renames []mysql.TableRename
+	for _, table := range tables {
+		if slices.Contains(current, table) {
+			renames = append(renames, mysql.TableRename{FromDatabase: target, FromTable: table, ToDatabase: replaced, ToTable: table})
+		}
+		renames = append(renames, mysql.TableRename{FromDatabase: shadow, FromTable: table, ToDatabase: target, ToTable: table})
+	}
+	if err := swapper.RenameTables(renames); err != nil {
+		return fmt.Errorf("failed to swap tables into %s: %w", target, err)
+	}
+	return nil
+}
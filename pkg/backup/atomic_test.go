@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shadowRestorer creates tables in the database it restores into, like the
+// dump it is given would.
+type shadowRestorer struct {
+	fakeRestorer
+	client  *mysql.MockClient
+	tables  []string
+	objects *mysql.SchemaObjects
+	err     error
+}
+
+func (r *shadowRestorer) RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error {
+	if err := r.fakeRestorer.RestoreWithCommand(database, sqlReader, cmdLogger); err != nil {
+		return err
+	}
+	r.client.Tables[database] = append([]string{}, r.tables...)
+	if r.objects != nil {
+		r.client.Objects = map[string]*mysql.SchemaObjects{database: r.objects}
+	}
+	return r.err
+}
+
+func TestShadowDatabaseName(t *testing.T) {
+	at := time.Date(2025, 1, 15, 14, 30, 22, 0, time.UTC)
+	assert.Equal(t, "shop_restoring_20250115143022", ShadowDatabaseName("shop", at))
+	assert.Equal(t, "shop_replaced_20250115143022", replacedDatabaseName("shop", at))
+
+	name := ShadowDatabaseName(strings.Repeat("a", 64), at)
+	assert.Len(t, name, maxIdentifierLength)
+	assert.True(t, strings.HasSuffix(name, "_restoring_20250115143022"))
+}
+
+func TestRestoreServiceRestoreAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), tableDump)
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip"))
+
+	setup := func() (*RestoreService, *mysql.MockClient, *shadowRestorer) {
+		restorer := &shadowRestorer{tables: []string{"orders", "users"}}
+		service := newSourceRestoreService(restorer, "testdb")
+		service.storage = localStorage
+		client := service.client.(*mysql.MockClient)
+		client.Tables["testdb"] = []string{"audit", "users"}
+		restorer.client = client
+		return service, client, restorer
+	}
+	options := &RestoreOptions{Database: "testdb", BackupID: backupID, Atomic: true}
+
+	t.Run("swaps the shadow in", func(t *testing.T) {
+		service, client, restorer := setup()
+
+		_, err := service.Restore(options)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(restorer.database, "testdb_restoring_"))
+		assert.ElementsMatch(t, []string{"audit", "orders", "users"}, client.Tables["testdb"])
+		assert.Equal(t, []string{"testdb"}, client.Databases)
+		assert.Equal(t, 1, client.GetCallCount("RenameTables"))
+	})
+
+	t.Run("failed restore leaves the target alone", func(t *testing.T) {
+		service, client, restorer := setup()
+		restorer.err = errors.New("syntax error")
+
+		_, err := service.Restore(options)
+		assert.True(t, IsRestoreError(err))
+		assert.Equal(t, []string{"audit", "users"}, client.Tables["testdb"])
+		assert.Equal(t, []string{"testdb"}, client.Databases)
+		assert.Zero(t, client.GetCallCount("RenameTables"))
+	})
+
+	t.Run("refuses shadows with views", func(t *testing.T) {
+		service, client, restorer := setup()
+		restorer.objects = &mysql.SchemaObjects{Views: 1}
+
+		_, err := service.Restore(options)
+		assert.ErrorContains(t, err, "without --atomic")
+		assert.Equal(t, []string{"audit", "users"}, client.Tables["testdb"])
+		assert.Equal(t, []string{"testdb"}, client.Databases)
+	})
+
+	t.Run("rejects single tables", func(t *testing.T) {
+		service, _, _ := setup()
+
+		_, err := service.Restore(&RestoreOptions{Database: "testdb", BackupID: backupID, Atomic: true, Table: "users"})
+		assert.True(t, IsRestoreError(err))
+	})
+}
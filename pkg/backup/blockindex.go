@@ -0,0 +1,342 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// DefaultIndexBlockSize is the amount of uncompressed data stored in each
+// gzip member of an indexed backup.
+const DefaultIndexBlockSize = 4 * 1024 * 1024
+
+// maxIndexedLine bounds how much of each line is kept while looking for
+// table markers.
+const maxIndexedLine = 512
+
+var (
+	tableMarker   = []byte("-- Table structure for table `")
+	trailerMarker = [][]byte{
+		[]byte("-- Dumping events for database"),
+		[]byte("-- Dumping routines for database"),
+	}
+)
+
+// BlockIndex describes a gzip backup written as a series of independent
+// gzip members, so a reader can start decompressing at any block instead
+// of at the beginning of the file. Offsets without a prefix refer to the
+// uncompressed SQL stream.
+type BlockIndex struct {
+	// BlockSize is the uncompressed size of every block except the last
+	BlockSize int64 `json:"block_size"`
+
+	// Size is the total uncompressed size of the dump
+	Size int64 `json:"size"`
+
+	// Blocks lists where each gzip member starts
+	Blocks []BlockEntry `json:"blocks"`
+
+	// Tables lists where each table's section of the dump starts
+	Tables []TableOffset `json:"tables,omitempty"`
+
+	// TrailerOffset is where the events and routines section starts, or
+	// zero if the dump has none
+	TrailerOffset int64 `json:"trailer_offset,omitempty"`
+}
+
+// BlockEntry is the position of one gzip member.
+type BlockEntry struct {
+	// Offset of the block's first byte in the uncompressed stream
+	Offset int64 `json:"offset"`
+
+	// CompressedOffset of the gzip member in the backup file
+	CompressedOffset int64 `json:"compressed_offset"`
+}
+
+// TableOffset is the position of a table's section in the uncompressed stream.
+type TableOffset struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+}
+
+// TableRange returns the uncompressed byte range [start, end) holding the
+// structure and data of table.
+func (idx *BlockIndex) TableRange(table string) (start, end int64, ok bool) {
+	for i, t := range idx.Tables {
+		if t.Name != table {
+			continue
+		}
+		end = idx.Size
+		if i+1 < len(idx.Tables) {
+			end = idx.Tables[i+1].Offset
+		} else if idx.TrailerOffset > t.Offset {
+			end = idx.TrailerOffset
+		}
+		return t.Offset, end, true
+	}
+	return 0, 0, false
+}
+
+// HeaderSize returns the size of the session settings that precede the
+// first table in the dump.
+func (idx *BlockIndex) HeaderSize() int64 {
+	if len(idx.Tables) == 0 {
+		return idx.Size
+	}
+	return idx.Tables[0].Offset
+}
+
+// blockFor returns the block containing the uncompressed offset.
+func (idx *BlockIndex) blockFor(offset int64) BlockEntry {
+	i := sort.Search(len(idx.Blocks), func(i int) bool {
+		return idx.Blocks[i].Offset > offset
+	})
+	if i == 0 {
+		return BlockEntry{}
+	}
+	return idx.Blocks[i-1]
+}
+
+// blockGzipWriter compresses its input as independent gzip members of
+// blockSize uncompressed bytes and records a BlockIndex while writing.
+// Concatenated gzip members are a valid gzip stream, so the output can be
+// decompressed like any other gzip backup.
+type blockGzipWriter struct {
+	out       *CountingWriter
+	gz        *gzip.Writer
+	blockSize int
+	buf       []byte
+	index     *BlockIndex
+
+	// Line tracking for table markers
+	scanned   int64
+	lineStart int64
+	line      []byte
+}
+
+func newBlockGzipWriter(w io.Writer, level, blockSize int) (*blockGzipWriter, error) {
+	out := NewCountingWriter(w)
+	gz, err := gzip.NewWriterLevel(out, level)
+	if err != nil {
+		return nil, err
+	}
+	return &blockGzipWriter{
+		out:       out,
+		gz:        gz,
+		blockSize: blockSize,
+		buf:       make([]byte, 0, blockSize),
+		index:     &BlockIndex{BlockSize: int64(blockSize)},
+		line:      make([]byte, 0, maxIndexedLine),
+	}, nil
+}
+
+// Write buffers p and writes out every block that fills up.
+func (w *blockGzipWriter) Write(p []byte) (int, error) {
+	w.scan(p)
+
+	written := 0
+	for len(p) > 0 {
+		n := w.blockSize - len(w.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == w.blockSize {
+			if err := w.flushBlock(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the final partial block.
+func (w *blockGzipWriter) Close() error {
+	w.endLine()
+	if len(w.buf) > 0 || len(w.index.Blocks) == 0 {
+		return w.flushBlock()
+	}
+	return nil
+}
+
+// flushBlock compresses the buffered data as one gzip member.
+func (w *blockGzipWriter) flushBlock() error {
+	w.index.Blocks = append(w.index.Blocks, BlockEntry{
+		Offset:           w.index.Size,
+		CompressedOffset: w.out.BytesWritten(),
+	})
+
+	w.gz.Reset(w.out)
+	if _, err := w.gz.Write(w.buf); err != nil {
+		return err
+	}
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+
+	w.index.Size += int64(len(w.buf))
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// scan records the offsets of table markers in p. Only the start of each
+// line is kept, so lines split across writes are still recognised.
+func (w *blockGzipWriter) scan(p []byte) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		segment := p
+		if i >= 0 {
+			segment = p[:i]
+		}
+
+		if room := maxIndexedLine - len(w.line); room > 0 {
+			if room > len(segment) {
+				room = len(segment)
+			}
+			w.line = append(w.line, segment[:room]...)
+		}
+
+		if i < 0 {
+			w.scanned += int64(len(p))
+			return
+		}
+
+		w.scanned += int64(i + 1)
+		w.endLine()
+		w.lineStart = w.scanned
+		p = p[i+1:]
+	}
+}
+
+// endLine checks the current line for a marker and starts a new one.
+func (w *blockGzipWriter) endLine() {
+	defer func() { w.line = w.line[:0] }()
+
+	if name, ok := parseTableMarker(w.line); ok {
+		w.index.Tables = append(w.index.Tables, TableOffset{Name: name, Offset: w.lineStart})
+		return
+	}
+	if w.index.TrailerOffset == 0 && len(w.index.Tables) > 0 {
+		for _, marker := range trailerMarker {
+			if bytes.HasPrefix(w.line, marker) {
+				w.index.TrailerOffset = w.lineStart
+				return
+			}
+		}
+	}
+}
+
+// parseTableMarker extracts the table name from a mysqldump
+// "-- Table structure for table `name`" comment.
+func parseTableMarker(line []byte) (string, bool) {
+	if !bytes.HasPrefix(line, tableMarker) {
+		return "", false
+	}
+	rest := line[len(tableMarker):]
+	end := bytes.IndexByte(rest, '`')
+	if end <= 0 {
+		return "", false
+	}
+	return string(rest[:end]), true
+}
+
+// OpenRange returns a reader for the uncompressed bytes [start, end) of an
+// indexed gzip backup, decompressing from the nearest block rather than
+// from the beginning of the file. A negative end reads to the end of the
+// dump. The returned reader must be closed by the caller.
+func OpenRange(path string, index *BlockIndex, start, end int64) (io.ReadCloser, error) {
+	if index == nil || len(index.Blocks) == 0 {
+		return nil, &CompressionError{File: path, Message: "backup has no block index"}
+	}
+	if end < 0 || end > index.Size {
+		end = index.Size
+	}
+	if start < 0 || start > end {
+		return nil, &CompressionError{
+			File:    path,
+			Message: fmt.Sprintf("invalid range %d-%d", start, end),
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, WrapCompressionError(path, "failed to open backup file", err)
+	}
+
+	block := index.blockFor(start)
+	if _, err := file.Seek(block.CompressedOffset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, WrapCompressionError(path, "failed to seek to block", err)
+	}
+
+	gzReader, err := gzip.NewReader(bufio.NewReaderSize(file, DefaultBufferSize))
+	if err != nil {
+		file.Close()
+		return nil, WrapCompressionError(path, "failed to create gzip reader", err)
+	}
+
+	if _, err := io.CopyN(io.Discard, gzReader, start-block.Offset); err != nil {
+		gzReader.Close()
+		file.Close()
+		return nil, WrapCompressionError(path, "failed to skip to offset", err)
+	}
+
+	return &rangeReader{
+		Reader:  io.LimitReader(gzReader, end-start),
+		closers: []io.Closer{gzReader, file},
+	}, nil
+}
+
+// OpenTable returns a reader for a restorable dump of a single table: the
+// session settings at the top of the backup followed by the table's
+// structure and data. The returned reader must be closed by the caller.
+func OpenTable(path string, index *BlockIndex, table string) (io.ReadCloser, error) {
+	if index == nil {
+		return nil, &CompressionError{File: path, Message: "backup has no block index"}
+	}
+	start, end, ok := index.TableRange(table)
+	if !ok {
+		return nil, &CompressionError{
+			File:    path,
+			Message: fmt.Sprintf("table %s not found in backup index", table),
+		}
+	}
+
+	header, err := OpenRange(path, index, 0, index.HeaderSize())
+	if err != nil {
+		return nil, err
+	}
+	body, err := OpenRange(path, index, start, end)
+	if err != nil {
+		header.Close()
+		return nil, err
+	}
+
+	return &rangeReader{
+		Reader:  io.MultiReader(header, body),
+		closers: []io.Closer{header, body},
+	}, nil
+}
+
+// rangeReader closes every underlying reader when closed.
+type rangeReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *rangeReader) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
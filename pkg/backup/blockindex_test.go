@@ -0,0 +1,130 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleDump builds a mysqldump-style dump with the given tables.
+func sampleDump(tables ...string) string {
+	var b strings.Builder
+	b.WriteString("-- MySQL dump\n/*!40101 SET NAMES utf8mb4 */;\n")
+	for _, table := range tables {
+		fmt.Fprintf(&b, "--\n-- Table structure for table `%s`\n--\n\n", table)
+		fmt.Fprintf(&b, "CREATE TABLE `%s` (`id` int);\n", table)
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(&b, "INSERT INTO `%s` VALUES (%d);\n", table, i)
+		}
+	}
+	b.WriteString("--\n-- Dumping routines for database 'shop'\n--\n")
+	return b.String()
+}
+
+func writeIndexed(t *testing.T, dump string, blockSize int) (string, *BlockIndex) {
+	compressor := NewCompressor(CompressionGzip)
+	compressor.SetBlockSize(blockSize)
+	// Small writes exercise markers split across Write calls
+	compressor.SetBufferSize(7)
+
+	path := filepath.Join(t.TempDir(), "backup.sql.gz")
+	result, err := compressor.StreamCompress(strings.NewReader(dump), path)
+	require.NoError(t, err)
+	require.NotNil(t, result.Index)
+	return path, result.Index
+}
+
+func readAll(t *testing.T, r io.ReadCloser) string {
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestIndexedCompression(t *testing.T) {
+	dump := sampleDump("users", "orders")
+	path, index := writeIndexed(t, dump, 256)
+
+	assert.Equal(t, int64(len(dump)), index.Size)
+	assert.Greater(t, len(index.Blocks), 1)
+	require.Len(t, index.Tables, 2)
+	assert.Equal(t, "users", index.Tables[0].Name)
+	assert.Equal(t, int64(strings.Index(dump, "-- Table structure for table `users`")), index.Tables[0].Offset)
+	assert.Equal(t, int64(strings.Index(dump, "-- Dumping routines")), index.TrailerOffset)
+
+	// The block stream is still a regular gzip file
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	gzReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	assert.Equal(t, dump, readAll(t, gzReader))
+}
+
+func TestOpenRange(t *testing.T) {
+	dump := sampleDump("users", "orders")
+	path, index := writeIndexed(t, dump, 100)
+
+	for _, r := range [][2]int64{{0, 10}, {95, 205}, {450, -1}, {int64(len(dump)), -1}} {
+		reader, err := OpenRange(path, index, r[0], r[1])
+		require.NoError(t, err)
+		end := r[1]
+		if end < 0 {
+			end = int64(len(dump))
+		}
+		assert.Equal(t, dump[r[0]:end], readAll(t, reader))
+	}
+
+	_, err := OpenRange(path, index, 10, 5)
+	assert.True(t, IsCompressionError(err))
+
+	_, err = OpenRange(path, nil, 0, 5)
+	assert.True(t, IsCompressionError(err))
+}
+
+func TestOpenTable(t *testing.T) {
+	dump := sampleDump("users", "orders", "items")
+	path, index := writeIndexed(t, dump, 128)
+
+	reader, err := OpenTable(path, index, "orders")
+	require.NoError(t, err)
+	sql := readAll(t, reader)
+
+	assert.True(t, strings.HasPrefix(sql, "-- MySQL dump\n"))
+	assert.Contains(t, sql, "CREATE TABLE `orders`")
+	assert.NotContains(t, sql, "`users`")
+	assert.NotContains(t, sql, "`items`")
+
+	// The last table stops before the routines section
+	reader, err = OpenTable(path, index, "items")
+	require.NoError(t, err)
+	sql = readAll(t, reader)
+	assert.Contains(t, sql, "INSERT INTO `items` VALUES (49);")
+	assert.NotContains(t, sql, "Dumping routines")
+
+	_, err = OpenTable(path, index, "missing")
+	assert.True(t, IsCompressionError(err))
+}
+
+func TestParseTableMarker(t *testing.T) {
+	name, ok := parseTableMarker([]byte("-- Table structure for table `order_items`"))
+	assert.True(t, ok)
+	assert.Equal(t, "order_items", name)
+
+	_, ok = parseTableMarker([]byte("-- Dumping data for table `order_items`"))
+	assert.False(t, ok)
+
+	_, ok = parseTableMarker([]byte("-- Table structure for table `"))
+	assert.False(t, ok)
+
+	_, ok = parseTableMarker(bytes.Repeat([]byte("x"), 10))
+	assert.False(t, ok)
+}
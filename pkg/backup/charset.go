@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// CharsetInspector is implemented by introspectors that can read the
+// character sets of databases and the server. mysql.DatabaseClient
+// satisfies it.
+type CharsetInspector interface {
+	GetDatabaseCharset(database string) (*mysql.Charset, error)
+	GetCollations(charset string) ([]string, error)
+}
+
+// CharsetSetter is implemented by restorers whose client connection
+// character set can be chosen.
+type CharsetSetter interface {
+	// SetCharacterSet sets the character set the client sends and expects
+	SetCharacterSet(charset string)
+}
+
+// DumpCharset returns the connection character set to dump a database
+// with the default character set charset in. The dump then holds the bytes
+// as stored, without conversions that can corrupt data labeled with the
+// wrong character set. utf8 (utf8mb3) is dumped as its superset utf8mb4.
+func DumpCharset(charset string) string {
+	switch charset {
+	case "utf8", "utf8mb3":
+		return "utf8mb4"
+	}
+	return charset
+}
+
+// canonicalCharset returns the name of a character set without aliases:
+// utf8 is utf8mb3 until MySQL makes it utf8mb4.
+func canonicalCharset(charset string) string {
+	if charset == "utf8" {
+		return "utf8mb3"
+	}
+	return charset
+}
+
+// databaseCharset returns the default character set of a database, or nil
+// if the introspector cannot tell.
+func databaseCharset(client Introspector, database string) *mysql.Charset {
+	inspector, ok := client.(CharsetInspector)
+	if !ok {
+		return nil
+	}
+	charset, err := inspector.GetDatabaseCharset(database)
+	if err != nil || charset.Name == "" {
+		return nil
+	}
+	return charset
+}
+
+// CheckCharset verifies that the server restored into supports the
+// character set and collation of a backup. Restoring anyway can fail on
+// the first CREATE TABLE or, worse, convert text through a character set
+// that cannot hold it.
+func CheckCharset(client Introspector, info DatabaseInfo) error {
+	if info.Charset == "" {
+		return nil
+	}
+	inspector, ok := client.(CharsetInspector)
+	if !ok {
+		return nil
+	}
+	collations, err := inspector.GetCollations(info.Charset)
+	if err != nil {
+		return fmt.Errorf("failed to list the server's collations: %w", err)
+	}
+	if len(collations) == 0 {
+		return fmt.Errorf("the server does not support character set %s of the backup", info.Charset)
+	}
+	if info.Collation != "" && !slices.Contains(collations, info.Collation) {
+		return fmt.Errorf("the server does not support collation %s of the backup", info.Collation)
+	}
+	return nil
+}
+
+// CharsetMismatch returns a warning when an existing database restored
+// into has another default character set than the backup, e.g. utf8mb3
+// for a utf8mb4 backup: tables created later without an explicit
+// character set would then get the narrower one.
+func CharsetMismatch(client Introspector, info DatabaseInfo, target string) string {
+	if info.Charset == "" {
+		return ""
+	}
+	current := databaseCharset(client, target)
+	if current == nil || canonicalCharset(current.Name) == canonicalCharset(info.Charset) {
+		return ""
+	}
+	return fmt.Sprintf("'%s' uses character set %s, the backup was taken from a %s database", target, current.Name, info.Charset)
+}
+
+// applyCharset sets the connection character set of a restorer to the one
+// the backup was dumped in.
+func applyCharset(restorer Restorer, info DatabaseInfo) {
+	if info.Charset == "" {
+		return
+	}
+	if setter, ok := restorer.(CharsetSetter); ok {
+		setter.SetCharacterSet(DumpCharset(info.Charset))
+	}
+}
@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"io"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpCharset(t *testing.T) {
+	assert.Equal(t, "utf8mb4", DumpCharset("utf8"))
+	assert.Equal(t, "utf8mb4", DumpCharset("utf8mb3"))
+	assert.Equal(t, "utf8mb4", DumpCharset("utf8mb4"))
+	assert.Equal(t, "latin1", DumpCharset("latin1"))
+	assert.Equal(t, "", DumpCharset(""))
+}
+
+func TestCheckCharset(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Collations = map[string][]string{
+		"utf8mb4": {"utf8mb4_general_ci", "utf8mb4_unicode_ci"},
+	}
+
+	assert.NoError(t, CheckCharset(client, DatabaseInfo{}))
+	assert.NoError(t, CheckCharset(client, DatabaseInfo{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}))
+
+	err := CheckCharset(client, DatabaseInfo{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"})
+	assert.ErrorContains(t, err, "collation utf8mb4_0900_ai_ci")
+
+	err = CheckCharset(client, DatabaseInfo{Charset: "gb18030", Collation: "gb18030_chinese_ci"})
+	assert.ErrorContains(t, err, "character set gb18030")
+}
+
+func TestCharsetMismatch(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Charsets = map[string]*mysql.Charset{
+		"legacy": {Name: "utf8", Collation: "utf8_general_ci"},
+		"modern": {Name: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+	}
+	info := DatabaseInfo{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}
+
+	assert.Contains(t, CharsetMismatch(client, info, "legacy"), "'legacy' uses character set utf8")
+	assert.Empty(t, CharsetMismatch(client, info, "modern"))
+	assert.Empty(t, CharsetMismatch(client, info, "missing"))
+	assert.Empty(t, CharsetMismatch(client, DatabaseInfo{Charset: "utf8mb3"}, "legacy"))
+	assert.Empty(t, CharsetMismatch(client, DatabaseInfo{}, "legacy"))
+}
+
+func TestBackupToWriterRecordsCharset(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Charsets = map[string]*mysql.Charset{
+		"shop": {Name: "utf8mb3", Collation: "utf8mb3_general_ci"},
+	}
+	service := NewService(client, nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: &fakeDumper{data: "SELECT 1;\n"}})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+
+	result, err := service.BackupToWriter(options, io.Discard)
+	require.NoError(t, err)
+	require.NotNil(t, result.Charset)
+	assert.Equal(t, "utf8mb3", result.Charset.Name)
+	assert.Equal(t, "utf8mb3_general_ci", result.Charset.Collation)
+}
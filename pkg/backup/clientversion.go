@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Actions for a dump tool that does not suit the server's release.
+const (
+	// VersionCheckWarn prints a warning and backs up anyway
+	VersionCheckWarn = "warn"
+
+	// VersionCheckFail fails the backup before the dump starts
+	VersionCheckFail = "fail"
+
+	// VersionCheckIgnore skips the check
+	VersionCheckIgnore = "ignore"
+)
+
+// ValidateVersionCheck checks a version check action. Empty means
+// VersionCheckWarn.
+func ValidateVersionCheck(action string) error {
+	switch action {
+	case "", VersionCheckWarn, VersionCheckFail, VersionCheckIgnore:
+		return nil
+	}
+	return &ValidationError{
+		Field:   "version_check",
+		Message: fmt.Sprintf("invalid version check %q (must be warn, fail or ignore)", action),
+	}
+}
+
+// clientVersionPatterns find the release in the --version output of the
+// MySQL and MariaDB client programs, most specific first:
+//
+//	mysqldump  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)
+//	mysqldump  Ver 10.19 Distrib 10.11.6-MariaDB, for debian-linux-gnu (x86_64)
+//	mysqldump from 11.4.2-MariaDB, client 10.19 for Linux (x86_64)
+var clientVersionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Distrib ([0-9][^,\s]*)`),
+	regexp.MustCompile(`from ([0-9][^,\s]*)`),
+	regexp.MustCompile(`Ver ([0-9][^,\s]*)`),
+}
+
+// majorMinorPattern matches the major and minor version of a release.
+var majorMinorPattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// release is a MySQL or MariaDB release.
+type release struct {
+	mariadb      bool
+	major, minor int
+}
+
+func (r release) String() string {
+	flavor := "MySQL"
+	if r.mariadb {
+		flavor = "MariaDB"
+	}
+	return fmt.Sprintf("%s %d.%d", flavor, r.major, r.minor)
+}
+
+// olderThan reports whether r is an earlier major.minor release than other.
+func (r release) olderThan(other release) bool {
+	return r.major < other.major || (r.major == other.major && r.minor < other.minor)
+}
+
+// parseRelease parses a version such as "8.0.36" or "10.11.6-MariaDB-log".
+func parseRelease(version string) (release, bool) {
+	match := majorMinorPattern.FindStringSubmatch(version)
+	if match == nil {
+		return release{}, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return release{
+		mariadb: strings.Contains(strings.ToLower(version), "mariadb"),
+		major:   major,
+		minor:   minor,
+	}, true
+}
+
+// parseClientRelease parses the --version output of a client program.
+func parseClientRelease(output string) (release, bool) {
+	for _, pattern := range clientVersionPatterns {
+		if match := pattern.FindStringSubmatch(output); match != nil {
+			r, ok := parseRelease(match[1])
+			r.mariadb = r.mariadb || strings.Contains(strings.ToLower(output), "mariadb")
+			return r, ok
+		}
+	}
+	return release{}, false
+}
+
+// ClientCompatibility returns a warning when a client program is likely to
+// fail against a server or produce a dump it cannot load: MySQL programs
+// with a MariaDB server or the other way around, or programs of an older
+// release than the server. clientVersion is the --version output of the
+// program and serverVersion the version the server reports. It returns
+// empty when the versions match or cannot be parsed.
+func ClientCompatibility(clientVersion, serverVersion string) string {
+	client, ok := parseClientRelease(clientVersion)
+	if !ok {
+		return ""
+	}
+	server, ok := parseRelease(serverVersion)
+	if !ok {
+		return ""
+	}
+
+	program := strings.Fields(clientVersion)[0]
+	switch {
+	case client.mariadb != server.mariadb:
+		return fmt.Sprintf("%s is from %s but the server runs %s; set mysqldump_path or mysql_path to the programs of the server's release", program, client, server)
+	case client.olderThan(server):
+		return fmt.Sprintf("%s is from %s, older than the server's %s; set mysqldump_path or mysql_path to the programs of the server's release", program, client, server)
+	}
+	return ""
+}
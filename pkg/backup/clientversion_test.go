@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCompatibility(t *testing.T) {
+	const (
+		mysql80   = "mysqldump  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)"
+		mysql57   = "mysql  Ver 14.14 Distrib 5.7.44, for Linux (x86_64) using  EditLine wrapper"
+		mariadb10 = "mysqldump  Ver 10.19 Distrib 10.11.6-MariaDB, for debian-linux-gnu (x86_64)"
+		mariadb11 = "mysqldump from 11.4.2-MariaDB, client 10.19 for Linux (x86_64)"
+	)
+
+	tests := []struct {
+		name    string
+		client  string
+		server  string
+		warning string
+	}{
+		{"same release", mysql80, "8.0.36", ""},
+		{"newer client", mysql80, "5.7.44-log", ""},
+		{"older client", mysql57, "8.0.36", "mysql is from MySQL 5.7, older than the server's MySQL 8.0"},
+		{"mariadb", mariadb10, "10.11.6-MariaDB-0ubuntu0.24.04.1", ""},
+		{"mariadb from", mariadb11, "11.4.2-MariaDB", ""},
+		{"mariadb client on mysql", mariadb10, "8.0.36", "mysqldump is from MariaDB 10.11 but the server runs MySQL 8.0"},
+		{"mysql client on mariadb", mysql80, "10.11.6-MariaDB", "mysqldump is from MySQL 8.0 but the server runs MariaDB 10.11"},
+		{"unknown client", "fakedump 1.0", "8.0.36", ""},
+		{"unknown server", mysql80, "unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning := ClientCompatibility(tt.client, tt.server)
+			if tt.warning == "" {
+				assert.Empty(t, warning)
+				return
+			}
+			assert.Contains(t, warning, tt.warning)
+		})
+	}
+}
+
+func TestBackupToWriterVersionCheck(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Version = "8.0.36"
+	service := NewService(client, nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{
+		name:        "fake",
+		dumper:      &fakeDumper{data: "SELECT 1;\n"},
+		dumpVersion: "mysqldump  Ver 14.14 Distrib 5.7.44, for Linux (x86_64)",
+	})
+	var log bytes.Buffer
+	service.SetLogOutput(&log)
+
+	options := DefaultOptions()
+	options.Database = "shop"
+
+	result, err := service.BackupToWriter(options, io.Discard)
+	require.NoError(t, err)
+	assert.Contains(t, log.String(), "older than the server's MySQL 8.0")
+	assert.Equal(t, "8.0.36", result.ServerVersion)
+	assert.Contains(t, result.DumperVersion, "5.7.44")
+	assert.NotEmpty(t, result.Compatibility)
+
+	options.VersionCheck = VersionCheckFail
+	_, err = service.BackupToWriter(options, io.Discard)
+	assert.ErrorContains(t, err, "version check failed")
+
+	log.Reset()
+	options.VersionCheck = VersionCheckIgnore
+	result, err = service.BackupToWriter(options, io.Discard)
+	require.NoError(t, err)
+	assert.Empty(t, log.String())
+	assert.Empty(t, result.Compatibility)
+}
+
+func TestValidateVersionCheck(t *testing.T) {
+	for _, action := range []string{"", VersionCheckWarn, VersionCheckFail, VersionCheckIgnore} {
+		assert.NoError(t, ValidateVersionCheck(action))
+	}
+	assert.Error(t, ValidateVersionCheck("strict"))
+}
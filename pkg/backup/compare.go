@@ -0,0 +1,314 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// Kinds of table changes between two backups
+const (
+	TableAdded     = "added"
+	TableRemoved   = "removed"
+	TableChanged   = "changed"
+	TableUnchanged = "unchanged"
+)
+
+var (
+	createTablePrefix = []byte("CREATE TABLE `")
+	insertIntoPrefix  = []byte("INSERT INTO `")
+	valuesKeyword     = []byte(" VALUES ")
+
+	// autoIncrementOption changes with every insert, so it is not part of
+	// the schema
+	autoIncrementOption = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+)
+
+// TableSummary describes a table as found in a SQL dump.
+type TableSummary struct {
+	Name string
+
+	// Schema is the CREATE TABLE statement, without AUTO_INCREMENT
+	Schema string
+
+	// Rows is the number of rows in the table's INSERT statements, when
+	// counted
+	Rows int64
+
+	// DataBytes is the size of the table's INSERT statements
+	DataBytes int64
+}
+
+// DumpSummary describes the tables of a SQL dump.
+type DumpSummary struct {
+	Tables map[string]*TableSummary
+}
+
+// SummarizeDump reads a mysqldump SQL dump and records the schema and data
+// size of each table. With countRows the rows of extended INSERT statements
+// are counted too, which means scanning every value.
+func SummarizeDump(r io.Reader, countRows bool) (*DumpSummary, error) {
+	s := &dumpScanner{
+		summary:   &DumpSummary{Tables: make(map[string]*TableSummary)},
+		countRows: countRows,
+	}
+
+	reader := bufio.NewReaderSize(r, DefaultBufferSize)
+	lineStart := true
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			s.scan(fragment, lineStart)
+			lineStart = fragment[len(fragment)-1] == '\n'
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	return s.summary, nil
+}
+
+// dumpScanner holds the state of SummarizeDump between line fragments.
+type dumpScanner struct {
+	summary   *DumpSummary
+	countRows bool
+
+	create *TableSummary   // Table whose CREATE TABLE is being read
+	schema strings.Builder // Lines of that CREATE TABLE so far
+
+	insert   *TableSummary // Table whose INSERT is being read
+	depth    int           // Parenthesis depth in the INSERT
+	inString bool          // Inside a quoted value
+	escaped  bool          // After a backslash in a quoted value
+}
+
+// scan processes a fragment of a line; lineStart is set for the first
+// fragment of each line.
+func (s *dumpScanner) scan(fragment []byte, lineStart bool) {
+	if lineStart {
+		switch {
+		case s.create != nil:
+			// Still inside a CREATE TABLE
+		case bytes.HasPrefix(fragment, createTablePrefix):
+			s.create = s.table(fragment[len(createTablePrefix):])
+			s.schema.Reset()
+		case bytes.HasPrefix(fragment, insertIntoPrefix):
+			s.insert = s.table(fragment[len(insertIntoPrefix):])
+			s.depth, s.inString, s.escaped = 0, false, false
+		default:
+			s.insert = nil
+		}
+	}
+
+	if s.create != nil {
+		s.schema.Write(fragment)
+		if bytes.HasSuffix(bytes.TrimSpace(fragment), []byte(";")) {
+			s.create.Schema = autoIncrementOption.ReplaceAllString(strings.TrimSpace(s.schema.String()), "")
+			s.create = nil
+		}
+		return
+	}
+
+	if s.insert != nil {
+		s.insert.DataBytes += int64(len(fragment))
+		if s.countRows {
+			if lineStart {
+				// Skip the column list of a --complete-insert dump
+				if i := bytes.Index(fragment, valuesKeyword); i >= 0 {
+					fragment = fragment[i:]
+				}
+			}
+			s.countTuples(fragment)
+		}
+	}
+}
+
+// countTuples counts the top-level parenthesised value lists of an INSERT.
+func (s *dumpScanner) countTuples(fragment []byte) {
+	for _, c := range fragment {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString:
+			if c == '\\' {
+				s.escaped = true
+			} else if c == '\'' {
+				s.inString = false
+			}
+		case c == '\'':
+			s.inString = true
+		case c == '(':
+			if s.depth == 0 {
+				s.insert.Rows++
+			}
+			s.depth++
+		case c == ')':
+			if s.depth > 0 {
+				s.depth--
+			}
+		}
+	}
+}
+
+// table returns the summary of the table whose name starts rest, which
+// follows an opening backquote.
+func (s *dumpScanner) table(rest []byte) *TableSummary {
+	name := string(rest)
+	if end := bytes.IndexByte(rest, '`'); end >= 0 {
+		name = string(rest[:end])
+	}
+	table, ok := s.summary.Tables[name]
+	if !ok {
+		table = &TableSummary{Name: name}
+		s.summary.Tables[name] = table
+	}
+	return table
+}
+
+// SummarizeBackup summarizes the SQL dump of a stored backup. Only
+// single-file mysqldump backups can be summarized.
+func SummarizeBackup(stor *storage.LocalStorage, database, backupID string, countRows bool) (*DumpSummary, error) {
+	reader, err := OpenDump(stor, database, backupID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return SummarizeDump(reader, countRows)
+}
+
+// OpenDump returns a reader for the decompressed SQL dump of a stored
+// backup. Only single-file mysqldump backups can be read this way. The
+// returned reader must be closed by the caller.
+func OpenDump(stor *storage.LocalStorage, database, backupID string) (io.ReadCloser, error) {
+	var metadata BackupMetadata
+	if err := stor.LoadMetadata(database, backupID, &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Backup.Format == FormatMydumper {
+		return nil, fmt.Errorf("backup %s is a mydumper backup; only mysqldump backups can be read", backupID)
+	}
+
+	compression := metadata.Backup.Compression
+	if compression == "" {
+		compression = CompressionGzip
+	}
+
+	file, err := os.Open(filepath.Join(stor.GetBackupDir(database, backupID), metadata.Backup.File))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+
+	reader, err := NewDecompressor(compression).DecompressToReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &rangeReader{Reader: reader, closers: []io.Closer{reader, file}}, nil
+}
+
+// TableChange is the difference of one table between two backups.
+type TableChange struct {
+	Table  string
+	Change string // added, removed, changed or unchanged
+
+	// SchemaDiff lists the lines of the CREATE TABLE statement only in the
+	// older backup, prefixed "- ", and only in the newer one, prefixed "+ "
+	SchemaDiff []string
+
+	RowsBefore, RowsAfter   int64
+	BytesBefore, BytesAfter int64
+}
+
+// CompareDumps returns the differences of every table between an older and
+// a newer dump, sorted by table name.
+func CompareDumps(older, newer *DumpSummary) []TableChange {
+	names := make(map[string]bool)
+	for name := range older.Tables {
+		names[name] = true
+	}
+	for name := range newer.Tables {
+		names[name] = true
+	}
+
+	changes := make([]TableChange, 0, len(names))
+	for name := range names {
+		before, after := older.Tables[name], newer.Tables[name]
+		change := TableChange{Table: name}
+		if before != nil {
+			change.RowsBefore, change.BytesBefore = before.Rows, before.DataBytes
+		}
+		if after != nil {
+			change.RowsAfter, change.BytesAfter = after.Rows, after.DataBytes
+		}
+
+		switch {
+		case before == nil:
+			change.Change = TableAdded
+		case after == nil:
+			change.Change = TableRemoved
+		case before.Schema != after.Schema:
+			change.Change = TableChanged
+			change.SchemaDiff = diffSchema(before.Schema, after.Schema)
+		default:
+			change.Change = TableUnchanged
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Table < changes[j].Table
+	})
+	return changes
+}
+
+// diffSchema lists the lines of two CREATE TABLE statements that only one
+// of them has. Trailing commas are ignored, so adding a column at the end
+// does not mark the previous one as changed.
+func diffSchema(before, after string) []string {
+	beforeLines, afterLines := schemaLines(before), schemaLines(after)
+	var diff []string
+	for _, line := range beforeLines {
+		if !containsLine(afterLines, line) {
+			diff = append(diff, "- "+line)
+		}
+	}
+	for _, line := range afterLines {
+		if !containsLine(beforeLines, line) {
+			diff = append(diff, "+ "+line)
+		}
+	}
+	return diff
+}
+
+func schemaLines(schema string) []string {
+	var lines []string
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
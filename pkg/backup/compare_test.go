@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const olderDump = "-- MySQL dump\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"-- Table structure for table `users`\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(100) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb4;\n" +
+	"INSERT INTO `users` VALUES (1,'a@example.com'),(2,'b (x), \\'quoted\\'');\n" +
+	"CREATE TABLE `sessions` (\n" +
+	"  `id` int NOT NULL\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `sessions` VALUES (1);\n"
+
+const newerDump = "-- MySQL dump\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(255) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=9 DEFAULT CHARSET=utf8mb4;\n" +
+	"INSERT INTO `users` (`id`, `email`) VALUES (1,'a@example.com'),(2,'b'),(3,'c');\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` int NOT NULL\n" +
+	") ENGINE=InnoDB;\n"
+
+func TestSummarizeDump(t *testing.T) {
+	summary, err := SummarizeDump(strings.NewReader(olderDump), true)
+	require.NoError(t, err)
+	require.Len(t, summary.Tables, 2)
+
+	users := summary.Tables["users"]
+	assert.Equal(t, int64(2), users.Rows)
+	assert.Positive(t, users.DataBytes)
+	assert.Contains(t, users.Schema, "`email` varchar(100)")
+	assert.NotContains(t, users.Schema, "AUTO_INCREMENT=3")
+	assert.Equal(t, int64(1), summary.Tables["sessions"].Rows)
+
+	// Rows are only counted when asked
+	summary, err = SummarizeDump(strings.NewReader(olderDump), false)
+	require.NoError(t, err)
+	assert.Zero(t, summary.Tables["users"].Rows)
+}
+
+func TestCompareDumps(t *testing.T) {
+	older, err := SummarizeDump(strings.NewReader(olderDump), true)
+	require.NoError(t, err)
+	newer, err := SummarizeDump(strings.NewReader(newerDump), true)
+	require.NoError(t, err)
+
+	changes := CompareDumps(older, newer)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, "orders", changes[0].Table)
+	assert.Equal(t, TableAdded, changes[0].Change)
+
+	assert.Equal(t, "sessions", changes[1].Table)
+	assert.Equal(t, TableRemoved, changes[1].Change)
+
+	users := changes[2]
+	assert.Equal(t, TableChanged, users.Change)
+	assert.Equal(t, []string{
+		"- `email` varchar(100) NOT NULL",
+		"+ `email` varchar(255) NOT NULL",
+	}, users.SchemaDiff)
+	assert.Equal(t, int64(2), users.RowsBefore)
+	assert.Equal(t, int64(3), users.RowsAfter)
+}
+
+func TestCompareDumpsIgnoresAutoIncrement(t *testing.T) {
+	a := "CREATE TABLE `t` (\n  `id` int\n) ENGINE=InnoDB AUTO_INCREMENT=5;\n"
+	b := "CREATE TABLE `t` (\n  `id` int\n) ENGINE=InnoDB AUTO_INCREMENT=50;\n"
+
+	older, err := SummarizeDump(strings.NewReader(a), false)
+	require.NoError(t, err)
+	newer, err := SummarizeDump(strings.NewReader(b), false)
+	require.NoError(t, err)
+
+	changes := CompareDumps(older, newer)
+	require.Len(t, changes, 1)
+	assert.Equal(t, TableUnchanged, changes[0].Change)
+}
+
+func TestSummarizeBackup(t *testing.T) {
+	stor, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-020000"
+	require.NoError(t, stor.EnsureBackupDir("shop", backupID))
+	path := stor.GetBackupPath("shop", backupID, CompressionGzip)
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(olderDump))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+
+	metadata := createTestMetadata(backupID, "shop", path, CompressionGzip)
+	metadata.CreatedAt = time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)
+	require.NoError(t, stor.SaveMetadata("shop", backupID, metadata))
+
+	summary, err := SummarizeBackup(stor, "shop", backupID, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.Tables["users"].Rows)
+}
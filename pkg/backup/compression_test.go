@@ -219,6 +219,12 @@ func TestCompressChecksumMatchesVerification(t *testing.T) {
 		calculatedChecksum, err := CalculateChecksum(outputPath)
 		require.NoError(t, err)
 		assert.Equal(t, result.Checksum, calculatedChecksum, "Compressed checksum should match calculated checksum")
+
+		// The raw checksum covers the uncompressed input
+		rawChecksum, err := CalculateChecksumFromReader(bytes.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, rawChecksum, result.RawChecksum)
+		assert.NotEqual(t, result.Checksum, result.RawChecksum)
 	})
 
 	t.Run("no compression", func(t *testing.T) {
@@ -243,6 +249,7 @@ func TestCompressChecksumMatchesVerification(t *testing.T) {
 		calculatedChecksum, err := CalculateChecksum(outputPath)
 		require.NoError(t, err)
 		assert.Equal(t, result.Checksum, calculatedChecksum, "Uncompressed checksum should match calculated checksum")
+		assert.Equal(t, result.Checksum, result.RawChecksum)
 	})
 }
 
@@ -0,0 +1,156 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// The functions in this file take types of the CLI's configuration package,
+// which code outside this module cannot construct. They are kept for one
+// minor release and then removed; see the package documentation.
+
+// ServerSchemas returns the schemas backed up for a server entry.
+//
+// Deprecated: use DiscoverSchemas for servers backed up with "*" and pass
+// listed schemas through as they are.
+func ServerSchemas(dbConfig *config.DatabaseConfig, connect func() (Introspector, error)) ([]string, error) {
+	if !dbConfig.Databases.All {
+		return dbConfig.Databases.Names, nil
+	}
+
+	client, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	schemas, err := DiscoverSchemas(client)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no databases found on %s:%d", dbConfig.Host, dbConfig.Port)
+	}
+	return schemas, nil
+}
+
+// SSLConfigFromConfig builds the SSL settings of a connection from a
+// database's tls config, or nil when it has none.
+//
+// Deprecated: build a mysql.SSLConfig, or the SSLOptions of a
+// ConnectionConfig, directly.
+func SSLConfigFromConfig(cfg *config.TLSConfig) *mysql.SSLConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &mysql.SSLConfig{Mode: cfg.Mode, CA: cfg.CA, Cert: cfg.Cert, Key: cfg.Key}
+}
+
+// ApplyRetentionPolicy applies retention policy and returns backups to delete.
+//
+// Deprecated: use ApplyRetention with a RetentionPolicy.
+func (s *RetentionService) ApplyRetentionPolicy(databaseName string, policy *config.RetentionPolicy, dryRun bool) (*CleanupResult, error) {
+	return s.ApplyRetention(databaseName, &RetentionPolicy{
+		Daily:   policy.Daily,
+		Weekly:  policy.Weekly,
+		Monthly: policy.Monthly,
+		KeepAll: policy.KeepAll,
+	}, dryRun)
+}
+
+// QuotaPolicyFromConfig builds the quota policy for a database from config.
+// Returns nil if no quota applies.
+//
+// Deprecated: build a QuotaPolicy directly.
+func QuotaPolicyFromConfig(cfg *config.Config, dbName string) (*QuotaPolicy, error) {
+	dbQuota := cfg.GetEffectiveQuota(dbName)
+	globalQuota := cfg.GetGlobalQuota()
+
+	dbLimit, err := dbQuota.Limit()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota for %s: %w", dbName, err)
+	}
+	totalLimit, err := globalQuota.Limit()
+	if err != nil {
+		return nil, fmt.Errorf("invalid global quota: %w", err)
+	}
+	if dbLimit <= 0 && totalLimit <= 0 {
+		return nil, nil
+	}
+
+	policy := &QuotaPolicy{
+		Limits:  Quota{DatabaseLimit: dbLimit, TotalLimit: totalLimit},
+		MinKeep: config.DefaultQuotaMinKeep,
+	}
+	if dbLimit > 0 && dbQuota.Action == config.QuotaActionPrune {
+		policy.Prune.DatabaseLimit = dbLimit
+		policy.MinKeep = dbQuota.GetMinKeep()
+	}
+	if totalLimit > 0 && globalQuota.Action == config.QuotaActionPrune {
+		policy.Prune.TotalLimit = totalLimit
+		if minKeep := globalQuota.GetMinKeep(); minKeep > policy.MinKeep {
+			policy.MinKeep = minKeep
+		}
+	}
+
+	return policy, nil
+}
+
+// WarningPolicyFromConfig builds a policy from a database's mysqldump config.
+//
+// Deprecated: use NewWarningPolicy.
+func WarningPolicyFromConfig(cfg *config.MysqldumpConfig) (*WarningPolicy, error) {
+	if cfg == nil || cfg.Warnings == nil {
+		return DefaultWarningPolicy(), nil
+	}
+	return NewWarningPolicy(cfg.Warnings.Action, cfg.Warnings.Allow)
+}
+
+// LongQueryPolicyFromConfig builds a policy from a database's long query
+// config, or returns nil when the check is not configured.
+//
+// Deprecated: build a LongQueryPolicy directly.
+func LongQueryPolicyFromConfig(cfg *config.LongQueryConfig) (*LongQueryPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	policy := &LongQueryPolicy{Threshold: DefaultLongQueryThreshold, Action: cfg.Action}
+	if cfg.Threshold != "" {
+		threshold, err := config.ParseAge(cfg.Threshold)
+		if err != nil {
+			return nil, &ValidationError{Field: "long_queries.threshold", Message: err.Error()}
+		}
+		policy.Threshold = threshold
+	}
+	return policy, policy.Validate()
+}
+
+// LockMonitorPolicyFromConfig builds a policy from a database's lock
+// monitor config, or returns nil when monitoring is not configured.
+//
+// Deprecated: build a LockMonitorPolicy directly.
+func LockMonitorPolicyFromConfig(cfg *config.LockMonitorConfig) (*LockMonitorPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	policy := &LockMonitorPolicy{Threshold: DefaultLockThreshold, Interval: DefaultLockInterval, Action: cfg.Action}
+	if cfg.Threshold != "" {
+		threshold, err := config.ParseAge(cfg.Threshold)
+		if err != nil {
+			return nil, &ValidationError{Field: "lock_monitor.threshold", Message: err.Error()}
+		}
+		policy.Threshold = threshold
+	}
+	if cfg.Interval != "" {
+		interval, err := config.ParseAge(cfg.Interval)
+		if err != nil {
+			return nil, &ValidationError{Field: "lock_monitor.interval", Message: err.Error()}
+		}
+		policy.Interval = interval
+	}
+	return policy, policy.Validate()
+}
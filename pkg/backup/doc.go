@@ -0,0 +1,42 @@
+// Package backup implements the backup and restore pipelines of Cadangkan:
+// dumping a database through an Engine, compressing and checksumming the
+// dump, writing its metadata, and restoring it again.
+//
+// Most programs should use package cadangkan, which drives these services
+// from a cadangkan configuration file. This package is for callers that need
+// finer control, such as their own storage layout or progress reporting.
+//
+// # Backing Up
+//
+// A Service dumps one database into a storage.LocalStorage:
+//
+//	stor, err := storage.NewLocalStorage("/var/backups/cadangkan")
+//	service := backup.NewService(client, stor, mysqlConfig)
+//	result, err := service.Backup(&backup.BackupOptions{
+//		Database:    "mydb",
+//		Compression: backup.CompressionGzip,
+//	})
+//
+// # Restoring
+//
+// A RestoreService restores a stored backup, by default the latest:
+//
+//	service := backup.NewRestoreService(client, stor, mysqlConfig)
+//	result, err := service.Restore(&backup.RestoreOptions{
+//		Database:         "mydb",
+//		SkipConfirmation: true,
+//	})
+//
+// # Deprecations
+//
+// Exported identifiers that are replaced are marked "Deprecated:" in their
+// documentation, keep working for one minor release and are then removed.
+// Deprecated identifiers live in deprecated.go.
+//
+// The functions named *FromConfig, ServerSchemas and
+// RetentionService.ApplyRetentionPolicy are deprecated because they take
+// types of the CLI's configuration package, which other modules cannot
+// import. Build the policies directly, or use NewWarningPolicy,
+// DiscoverSchemas and RetentionService.ApplyRetention with a
+// RetentionPolicy.
+package backup
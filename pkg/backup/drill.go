@@ -0,0 +1,165 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultDrillSuffix is appended to the database name to form the scratch
+// database of a restore drill when none is configured.
+const DefaultDrillSuffix = "_restore_test"
+
+// DrillOptions configures a restore drill.
+type DrillOptions struct {
+	// Database is the source database whose latest backup is restored
+	Database string
+
+	// ConfigName is the configuration name (used for storage paths)
+	ConfigName string
+
+	// ScratchDatabase receives the restore. It is dropped and recreated,
+	// so it must never hold real data.
+	ScratchDatabase string
+
+	// Keep leaves the scratch database in place after the drill
+	Keep bool
+}
+
+// DrillReport is the validation report of a restore drill.
+type DrillReport struct {
+	// BackupID is the backup that was restored
+	BackupID string
+
+	// ScratchDatabase is the database the backup was restored into
+	ScratchDatabase string
+
+	// Tables is the number of tables found after the restore
+	Tables int
+
+	// MissingTables lists tables recorded in the backup but not restored
+	MissingTables []string
+
+	// SizeBytes is the size of the restored database
+	SizeBytes int64
+
+	// Duration is how long the restore took
+	Duration time.Duration
+
+	// Problems lists every validation failure; empty means the drill passed
+	Problems []string
+}
+
+// Passed reports whether the restored database passed validation.
+func (r *DrillReport) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+// Drill restores the latest backup into a scratch database and validates
+// the result, proving the backup can actually be restored. An error is
+// returned when the restore itself fails; validation failures are listed
+// in the report.
+func (s *RestoreService) Drill(options *DrillOptions) (*DrillReport, error) {
+	if options == nil || options.Database == "" {
+		return nil, WrapRestoreError("", "source database is required", fmt.Errorf("empty database name"))
+	}
+
+	scratch := options.ScratchDatabase
+	if scratch == "" {
+		scratch = options.Database + DefaultDrillSuffix
+	}
+	if scratch == options.Database {
+		return nil, &ValidationError{
+			Field:   "ScratchDatabase",
+			Message: "scratch database must differ from the source database",
+		}
+	}
+
+	// Start from an empty database so leftovers of a previous drill
+	// cannot hide missing tables
+	if err := s.client.DropDatabase(scratch); err != nil {
+		return nil, WrapRestoreError(scratch, "failed to drop scratch database", err)
+	}
+
+	restoreOptions := &RestoreOptions{
+		Database:         options.Database,
+		ConfigName:       options.ConfigName,
+		TargetDatabase:   scratch,
+		CreateDatabase:   true,
+		SkipConfirmation: true,
+	}
+	result, err := s.Restore(restoreOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DrillReport{
+		BackupID:        result.BackupID,
+		ScratchDatabase: scratch,
+		Duration:        result.Duration,
+	}
+	s.validateDrill(report, getStorageNameForRestore(restoreOptions))
+
+	if !options.Keep {
+		if err := s.client.DropDatabase(scratch); err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("failed to drop scratch database: %v", err))
+		}
+	}
+
+	return report, nil
+}
+
+// validateDrill compares the restored scratch database with the tables
+// recorded in the backup metadata.
+func (s *RestoreService) validateDrill(report *DrillReport, storageName string) {
+	// The restore may have run long enough for the pool to die
+	if err := s.client.Ping(); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("lost connection after restore: %v", err))
+		return
+	}
+
+	tables, err := s.client.GetTables(report.ScratchDatabase)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("failed to list restored tables: %v", err))
+		return
+	}
+	report.Tables = len(tables)
+	if len(tables) == 0 {
+		report.Problems = append(report.Problems, "no tables were restored")
+	}
+
+	if size, err := s.client.GetDatabaseSize(report.ScratchDatabase); err == nil {
+		report.SizeBytes = size
+	}
+
+	var metadata BackupMetadata
+	if err := s.storage.LoadMetadata(storageName, report.BackupID, &metadata); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("failed to load backup metadata: %v", err))
+		return
+	}
+
+	restored := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		restored[table] = true
+	}
+	for _, table := range expectedTables(&metadata) {
+		if !restored[table] {
+			report.MissingTables = append(report.MissingTables, table)
+		}
+	}
+	if len(report.MissingTables) > 0 {
+		report.Problems = append(report.Problems, fmt.Sprintf("%d table(s) missing after restore", len(report.MissingTables)))
+	}
+}
+
+// expectedTables returns the tables a backup is known to contain: the
+// tables of its block index, or the tables it was limited to.
+func expectedTables(metadata *BackupMetadata) []string {
+	if index := metadata.Backup.Index; index != nil && len(index.Tables) > 0 {
+		names := make([]string, len(index.Tables))
+		for i, table := range index.Tables {
+			names[i] = table.Name
+		}
+		return names
+	}
+	return metadata.Options.Tables
+}
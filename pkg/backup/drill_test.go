@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tableRestorer creates the given tables in the mock client when restoring.
+type tableRestorer struct {
+	client *mysql.MockClient
+	tables []string
+}
+
+func (r *tableRestorer) RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error {
+	_, err := io.Copy(io.Discard, sqlReader)
+	r.client.SetTables(database, r.tables)
+	return err
+}
+
+func TestRestoreServiceDrill(t *testing.T) {
+	setup := func(t *testing.T, restoredTables []string) (*RestoreService, *mysql.MockClient) {
+		tmpDir := t.TempDir()
+		localStorage, err := storage.NewLocalStorage(tmpDir)
+		require.NoError(t, err)
+
+		backupID := "2025-01-15-143022"
+		dbPath := filepath.Join(tmpDir, "testdb")
+		require.NoError(t, os.MkdirAll(dbPath, 0755))
+		createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), "CREATE TABLE users (id INT);")
+
+		metadata := createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip")
+		metadata.Options.Tables = []string{"users", "orders"}
+		saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+
+		client := mysql.NewMockClient()
+		client.SetConnected(true)
+		client.Databases = []string{"testdb"}
+
+		service := NewRestoreService(client, localStorage, &mysql.Config{Host: "localhost", User: "root"})
+		service.SetEngine(&fakeEngine{name: "fake", restorer: &tableRestorer{client: client, tables: restoredTables}})
+		return service, client
+	}
+
+	t.Run("passes and drops scratch database", func(t *testing.T) {
+		service, client := setup(t, []string{"users", "orders"})
+
+		report, err := service.Drill(&DrillOptions{Database: "testdb"})
+		require.NoError(t, err)
+		assert.True(t, report.Passed(), report.Problems)
+		assert.Equal(t, "testdb_restore_test", report.ScratchDatabase)
+		assert.Equal(t, 2, report.Tables)
+		assert.Equal(t, 2, client.GetCallCount("DropDatabase"))
+		assert.NotContains(t, client.Databases, "testdb_restore_test")
+	})
+
+	t.Run("missing tables fail validation", func(t *testing.T) {
+		service, _ := setup(t, []string{"users"})
+
+		report, err := service.Drill(&DrillOptions{Database: "testdb", ScratchDatabase: "scratch", Keep: true})
+		require.NoError(t, err)
+		assert.False(t, report.Passed())
+		assert.Equal(t, []string{"orders"}, report.MissingTables)
+	})
+
+	t.Run("empty restore fails validation", func(t *testing.T) {
+		service, _ := setup(t, nil)
+
+		report, err := service.Drill(&DrillOptions{Database: "testdb"})
+		require.NoError(t, err)
+		assert.Contains(t, report.Problems, "no tables were restored")
+	})
+
+	t.Run("scratch must differ from source", func(t *testing.T) {
+		service, client := setup(t, nil)
+
+		_, err := service.Drill(&DrillOptions{Database: "testdb", ScratchDatabase: "testdb"})
+		assert.True(t, IsValidationError(err))
+		assert.Equal(t, 0, client.GetCallCount("DropDatabase"))
+	})
+}
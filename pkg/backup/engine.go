@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnectionConfig describes how to reach a database server, independent
+// of the engine. Each engine translates it into its own client settings.
+type ConnectionConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+
+	// Database is the database to connect to, if any.
+	Database string
+
+	// Timeout is the connection timeout (0 = the engine's default).
+	Timeout time.Duration
+
+	// SSL configures encrypted connections, nil for the engine's default.
+	SSL *SSLOptions
+
+	// ConnectAttempts is how many times to try reaching the server before
+	// giving up (0 or 1 = no retry), waiting ConnectBackoff before the
+	// second attempt and doubling it before each further one.
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
+
+	// AutoReconnect replaces dead connections when they are found.
+	AutoReconnect bool
+
+	// DumpToolPath and RestoreToolPath are the engine's client programs
+	// (default: found in PATH).
+	DumpToolPath    string
+	RestoreToolPath string
+}
+
+// SSLOptions configures an encrypted connection.
+type SSLOptions struct {
+	// Mode is disabled, preferred, required, verify-ca or verify-identity.
+	Mode string
+
+	// CA is the file of the certificate authority the server certificate
+	// must be signed by.
+	CA string
+
+	// Cert and Key are the files of the client certificate and its key.
+	Cert string
+	Key  string
+}
+
+// Introspector inspects a live database server.
+// mysql.DatabaseClient satisfies this interface.
+type Introspector interface {
+	Connect() error
+	Ping() error
+	Close() error
+	IsConnected() bool
+
+	GetVersion() (string, error)
+	GetDatabases() ([]string, error)
+	GetTables(database string) ([]string, error)
+	GetDatabaseSize(database string) (int64, error)
+	DatabaseExists(database string) (bool, error)
+	CreateDatabase(database string) error
+	DropDatabase(database string) error
+}
+
+// Dumper produces a logical dump of a database as a stream.
+type Dumper interface {
+	// DumpWithCommand starts a dump and returns a reader for its output.
+	// The caller is responsible for closing the returned reader.
+	DumpWithCommand(database string, options *DumpOptions, cmdLogger func(string)) (io.ReadCloser, error)
+}
+
+// Restorer loads a logical dump into a database.
+type Restorer interface {
+	// RestoreWithCommand executes the dump read from sqlReader against database.
+	RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error
+}
+
+// WarningReporter is implemented by dump readers that record the warnings
+// printed by the dump tool. Warnings are available after Close.
+type WarningReporter interface {
+	Warnings() []string
+}
+
+// StderrLogger is implemented by dumpers and restorers that can report the
+// stderr of their child process line by line while it is still running.
+type StderrLogger interface {
+	// SetStderrLogger sets a function called with each stderr line.
+	SetStderrLogger(logger func(string))
+}
+
+// FastModeSetter is implemented by restorers that can relax integrity
+// checks and batch the import into one transaction to restore faster.
+type FastModeSetter interface {
+	// SetFastMode enables or disables fast import mode.
+	SetFastMode(enabled bool)
+}
+
+// VersionChecker reports the availability of an engine's external tools.
+type VersionChecker interface {
+	// DumpToolVersion returns the version of the dump tool the config
+	// selects, or an error if it is missing. config may be nil.
+	DumpToolVersion(config *ConnectionConfig) (string, error)
+
+	// RestoreToolVersion returns the version of the restore tool the config
+	// selects, or an error if it is missing. config may be nil.
+	RestoreToolVersion(config *ConnectionConfig) (string, error)
+}
+
+// Engine is a database engine that cadangkan can back up and restore.
+type Engine interface {
+	VersionChecker
+
+	// Name is the engine name used for --type and in config files.
+	Name() string
+
+	// DisplayName is the human-readable engine name (e.g., "MySQL").
+	DisplayName() string
+
+	// DefaultPort is the default server port for the engine.
+	DefaultPort() int
+
+	// InstallHelp describes how to install the engine's client tools.
+	InstallHelp() []string
+
+	// NewIntrospector creates a client for the given connection config.
+	NewIntrospector(config *ConnectionConfig) (Introspector, error)
+
+	// NewDumper creates a dumper for the given connection config.
+	NewDumper(config *ConnectionConfig) Dumper
+
+	// NewRestorer creates a restorer for the given connection config.
+	NewRestorer(config *ConnectionConfig) Restorer
+}
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]Engine)
+)
+
+// RegisterEngine makes an engine available by name.
+// It panics if an engine with the same name is already registered.
+func RegisterEngine(engine Engine) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+
+	name := engine.Name()
+	if _, exists := engines[name]; exists {
+		panic(fmt.Sprintf("backup: engine %q registered twice", name))
+	}
+	engines[name] = engine
+}
+
+// GetEngine returns the engine registered under name.
+func GetEngine(name string) (Engine, error) {
+	enginesMu.RLock()
+	engine, ok := engines[name]
+	enginesMu.RUnlock()
+
+	if !ok {
+		return nil, &ValidationError{
+			Field:   "type",
+			Message: fmt.Sprintf("unsupported database type: %s (supported: %s)", name, strings.Join(EngineNames(), ", ")),
+		}
+	}
+	return engine, nil
+}
+
+// EngineNames returns the names of all registered engines, sorted.
+func EngineNames() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultEngine returns the engine used when none is configured.
+func defaultEngine() Engine {
+	engine, err := GetEngine(EngineTypeMySQL)
+	if err != nil {
+		panic(err)
+	}
+	return engine
+}
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// EngineTypeMySQL is the registry name of the MySQL engine.
+const EngineTypeMySQL = "mysql"
+
+func init() {
+	RegisterEngine(&MySQLEngine{})
+}
+
+// MySQLEngine implements Engine using the MySQL client library,
+// mysqldump and the mysql command line client.
+type MySQLEngine struct{}
+
+// Name implements Engine.
+func (e *MySQLEngine) Name() string {
+	return EngineTypeMySQL
+}
+
+// DisplayName implements Engine.
+func (e *MySQLEngine) DisplayName() string {
+	return "MySQL"
+}
+
+// DefaultPort implements Engine.
+func (e *MySQLEngine) DefaultPort() int {
+	return mysql.DefaultPort
+}
+
+// InstallHelp implements Engine.
+func (e *MySQLEngine) InstallHelp() []string {
+	return []string{
+		"Please install MySQL client tools:",
+		"  Ubuntu/Debian: sudo apt-get install mysql-client",
+		"  RHEL/CentOS:   sudo yum install mysql",
+		"  macOS:         brew install mysql-client",
+	}
+}
+
+// MySQLConnection returns the engine-neutral form of a MySQL connection
+// config, or nil for a nil config.
+func MySQLConnection(config *mysql.Config) *ConnectionConfig {
+	if config == nil {
+		return nil
+	}
+	conn := &ConnectionConfig{
+		Host:            config.Host,
+		Port:            config.Port,
+		User:            config.User,
+		Password:        config.Password,
+		Database:        config.Database,
+		Timeout:         config.Timeout,
+		ConnectAttempts: config.ConnectAttempts,
+		ConnectBackoff:  config.ConnectBackoff,
+		AutoReconnect:   config.AutoReconnect,
+		DumpToolPath:    config.MysqldumpPath,
+		RestoreToolPath: config.MysqlPath,
+	}
+	if config.SSL != nil {
+		conn.SSL = &SSLOptions{Mode: config.SSL.Mode, CA: config.SSL.CA, Cert: config.SSL.Cert, Key: config.SSL.Key}
+	}
+	return conn
+}
+
+// mysqlConfig returns the MySQL client config of a connection, or nil for
+// a nil connection.
+func mysqlConfig(conn *ConnectionConfig) *mysql.Config {
+	if conn == nil {
+		return nil
+	}
+	config := &mysql.Config{
+		Host:            conn.Host,
+		Port:            conn.Port,
+		User:            conn.User,
+		Password:        conn.Password,
+		Database:        conn.Database,
+		Timeout:         conn.Timeout,
+		ConnectAttempts: conn.ConnectAttempts,
+		ConnectBackoff:  conn.ConnectBackoff,
+		AutoReconnect:   conn.AutoReconnect,
+		MysqldumpPath:   conn.DumpToolPath,
+		MysqlPath:       conn.RestoreToolPath,
+	}
+	if conn.SSL != nil {
+		config.SSL = &mysql.SSLConfig{Mode: conn.SSL.Mode, CA: conn.SSL.CA, Cert: conn.SSL.Cert, Key: conn.SSL.Key}
+	}
+	return config
+}
+
+// NewIntrospector implements Engine.
+func (e *MySQLEngine) NewIntrospector(config *ConnectionConfig) (Introspector, error) {
+	client, err := mysql.NewClient(mysqlConfig(config))
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewDumper implements Engine.
+func (e *MySQLEngine) NewDumper(config *ConnectionConfig) Dumper {
+	return NewMySQLDumper(mysqlConfig(config))
+}
+
+// NewRestorer implements Engine.
+func (e *MySQLEngine) NewRestorer(config *ConnectionConfig) Restorer {
+	return NewMySQLRestorer(mysqlConfig(config))
+}
+
+// DumpToolVersion implements VersionChecker.
+func (e *MySQLEngine) DumpToolVersion(config *ConnectionConfig) (string, error) {
+	return CheckMySQLDump(mysqlConfig(config))
+}
+
+// RestoreToolVersion implements VersionChecker.
+func (e *MySQLEngine) RestoreToolVersion(config *ConnectionConfig) (string, error) {
+	return CheckMySQL(mysqlConfig(config))
+}
+
+// Ensure the MySQL implementations satisfy the engine interfaces.
+var (
+	_ Engine       = (*MySQLEngine)(nil)
+	_ Introspector = (mysql.DatabaseClient)(nil)
+	_ Dumper       = (*MySQLDumper)(nil)
+	_ Restorer     = (*MySQLRestorer)(nil)
+)
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine is a minimal engine used to exercise the registry.
+type fakeEngine struct {
+	name        string
+	dumper      Dumper
+	restorer    Restorer
+	dumpVersion string // default: "fakedump 1.0"
+}
+
+func (e *fakeEngine) Name() string          { return e.name }
+func (e *fakeEngine) DisplayName() string   { return "Fake" }
+func (e *fakeEngine) DefaultPort() int      { return 1234 }
+func (e *fakeEngine) InstallHelp() []string { return nil }
+
+func (e *fakeEngine) RestoreToolVersion(*ConnectionConfig) (string, error) {
+	return "fakeload 1.0", nil
+}
+
+func (e *fakeEngine) DumpToolVersion(*ConnectionConfig) (string, error) {
+	if e.dumpVersion != "" {
+		return e.dumpVersion, nil
+	}
+	return "fakedump 1.0", nil
+}
+
+func (e *fakeEngine) NewIntrospector(config *ConnectionConfig) (Introspector, error) {
+	return mysql.NewMockClient(), nil
+}
+
+func (e *fakeEngine) NewDumper(config *ConnectionConfig) Dumper {
+	return e.dumper
+}
+
+func (e *fakeEngine) NewRestorer(config *ConnectionConfig) Restorer {
+	return e.restorer
+}
+
+func unregisterEngine(name string) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	delete(engines, name)
+}
+
+func TestGetEngineMySQL(t *testing.T) {
+	engine, err := GetEngine(EngineTypeMySQL)
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", engine.Name())
+	assert.Equal(t, "MySQL", engine.DisplayName())
+	assert.Equal(t, 3306, engine.DefaultPort())
+
+	assert.IsType(t, &MySQLDumper{}, engine.NewDumper(&ConnectionConfig{}))
+	assert.IsType(t, &MySQLRestorer{}, engine.NewRestorer(&ConnectionConfig{}))
+}
+
+func TestMySQLConnection(t *testing.T) {
+	config := &mysql.Config{
+		Host:            "db.example.com",
+		Port:            3307,
+		User:            "backup",
+		Password:        "secret",
+		Database:        "shop",
+		ConnectAttempts: 3,
+		AutoReconnect:   true,
+		MysqldumpPath:   "/opt/mysql/bin/mysqldump",
+		SSL:             &mysql.SSLConfig{Mode: mysql.SSLModeVerifyCA, CA: "/etc/ca.pem"},
+	}
+	conn := MySQLConnection(config)
+	assert.Equal(t, "db.example.com", conn.Host)
+	assert.Equal(t, "/opt/mysql/bin/mysqldump", conn.DumpToolPath)
+	assert.Equal(t, "/etc/ca.pem", conn.SSL.CA)
+	assert.Equal(t, config, mysqlConfig(conn))
+
+	assert.Nil(t, MySQLConnection(nil))
+	assert.Nil(t, mysqlConfig(nil))
+}
+
+func TestGetEngineUnsupported(t *testing.T) {
+	_, err := GetEngine("oracle")
+	require.Error(t, err)
+	assert.True(t, IsValidationError(err))
+	assert.Contains(t, err.Error(), "oracle")
+	assert.Contains(t, err.Error(), "mysql")
+}
+
+func TestRegisterEngine(t *testing.T) {
+	RegisterEngine(&fakeEngine{name: "fake"})
+	defer unregisterEngine("fake")
+
+	engine, err := GetEngine("fake")
+	require.NoError(t, err)
+	assert.Equal(t, 1234, engine.DefaultPort())
+	assert.Contains(t, EngineNames(), "fake")
+
+	assert.Panics(t, func() {
+		RegisterEngine(&fakeEngine{name: "fake"})
+	})
+}
+
+func TestServiceSetEngine(t *testing.T) {
+	service := NewService(mysql.NewMockClient(), nil, &mysql.Config{})
+	assert.Equal(t, EngineTypeMySQL, service.engine.Name())
+
+	fake := &fakeEngine{name: "fake"}
+	service.SetEngine(fake)
+	assert.Equal(t, fake, service.engine)
+}
@@ -276,6 +276,31 @@ func (e *ChecksumMismatchError) Error() string {
 	return fmt.Sprintf("checksum mismatch for backup %s: expected %s, got %s", e.BackupID, e.ExpectedChecksum, e.ActualChecksum)
 }
 
+// QuotaExceededError indicates that a backup would exceed a storage quota.
+type QuotaExceededError struct {
+	// Scope is the database name, or empty for the global quota
+	Scope  string
+	Limit  int64
+	Usage  int64
+	Needed int64
+}
+
+// Error returns the error message.
+func (e *QuotaExceededError) Error() string {
+	scope := "global storage quota"
+	if e.Scope != "" {
+		scope = fmt.Sprintf("storage quota for %s", e.Scope)
+	}
+	return fmt.Sprintf("%s exceeded: %s used + ~%s needed > %s limit",
+		scope, FormatBytes(e.Usage), FormatBytes(e.Needed), FormatBytes(e.Limit))
+}
+
+// IsQuotaExceededError checks if an error is a QuotaExceededError.
+func IsQuotaExceededError(err error) bool {
+	var quotaErr *QuotaExceededError
+	return errors.As(err, &quotaErr)
+}
+
 // IsRestoreError checks if the error is a RestoreError.
 func IsRestoreError(err error) bool {
 	var restoreErr *RestoreError
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// estimateSamples is how many recent backups an estimate is based on.
+const estimateSamples = 5
+
+// DefaultOverrunFactor is how many times its estimate a backup may run
+// before an alert is raised, when no factor is configured.
+const DefaultOverrunFactor = 2.0
+
+// DurationEstimate predicts how long the next backup of a database will
+// take from its recent backups.
+type DurationEstimate struct {
+	// Duration is the predicted duration
+	Duration time.Duration
+
+	// Samples is the number of backups the estimate is based on
+	Samples int
+}
+
+// String describes the estimate, e.g. "~4m 10s (from 5 backups)".
+func (e *DurationEstimate) String() string {
+	return fmt.Sprintf("~%s (from %d backups)", FormatDuration(e.Duration), e.Samples)
+}
+
+// Limit returns how long the backup may run before it is overdue.
+func (e *DurationEstimate) Limit(factor float64) time.Duration {
+	if factor <= 0 {
+		factor = DefaultOverrunFactor
+	}
+	return time.Duration(float64(e.Duration) * factor)
+}
+
+// EstimateDuration predicts how long a backup with options will take from
+// the most recent completed full backups of the database. Backups grow, so
+// the prediction is the size of the latest backup divided by the average
+// rate of the recent ones. It returns nil when there is no history to go by
+// or options select only part of the database, as partial backups are not
+// comparable.
+func EstimateDuration(stor *storage.LocalStorage, storageName string, options *BackupOptions) (*DurationEstimate, error) {
+	if options != nil && (options.SchemaOnly || len(options.Tables) > 0 || len(options.ExcludeTables) > 0) {
+		return nil, nil
+	}
+
+	backups, err := stor.ListBackups(storageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples int
+	var totalBytes, latestBytes int64
+	var totalDuration time.Duration
+	for _, entry := range backups {
+		if samples == estimateSamples {
+			break
+		}
+		if entry.Status != StatusCompleted {
+			continue
+		}
+
+		var metadata BackupMetadata
+		if err := stor.LoadMetadata(storageName, entry.BackupID, &metadata); err != nil {
+			continue
+		}
+		if metadata.Options.SchemaOnly || len(metadata.Options.Tables) > 0 || len(metadata.Options.ExcludeTables) > 0 {
+			continue
+		}
+
+		if samples == 0 {
+			latestBytes = metadata.Backup.SizeBytes
+		}
+		samples++
+		totalBytes += metadata.Backup.SizeBytes
+		totalDuration += time.Duration(metadata.DurationSeconds) * time.Second
+	}
+
+	// Backups finishing within a second each leave nothing to predict
+	if samples == 0 || totalDuration == 0 {
+		return nil, nil
+	}
+
+	estimate := totalDuration / time.Duration(samples)
+	if totalBytes > 0 {
+		estimate = time.Duration(float64(totalDuration) * float64(latestBytes) / float64(totalBytes))
+	}
+	return &DurationEstimate{Duration: estimate.Round(time.Second), Samples: samples}, nil
+}
+
+// EstimateSizeFromHistory predicts the size of a backup with options of a
+// database of databaseSize bytes from its most recent completed full
+// backups with the same compression. When their metadata records the size
+// of the database, the highest ratio of backup to database size among them
+// is applied to databaseSize, erring towards more space; otherwise the size
+// of the latest backup is used. It returns 0 when there is no history to go
+// by or options select only part of the database.
+func EstimateSizeFromHistory(stor *storage.LocalStorage, storageName string, options *BackupOptions, databaseSize int64) (int64, error) {
+	if options == nil || options.SchemaOnly || len(options.Tables) > 0 || len(options.ExcludeTables) > 0 {
+		return 0, nil
+	}
+
+	backups, err := stor.ListBackups(storageName)
+	if err != nil {
+		return 0, err
+	}
+
+	format := ""
+	if options.Engine == EngineMydumper {
+		format = FormatMydumper
+	}
+
+	var samples int
+	var latestBytes int64
+	var ratio float64
+	for _, entry := range backups {
+		if samples == estimateSamples {
+			break
+		}
+		if entry.Status != StatusCompleted {
+			continue
+		}
+
+		var metadata BackupMetadata
+		if err := stor.LoadMetadata(storageName, entry.BackupID, &metadata); err != nil {
+			continue
+		}
+		if metadata.Options.SchemaOnly || len(metadata.Options.Tables) > 0 || len(metadata.Options.ExcludeTables) > 0 {
+			continue
+		}
+		if metadata.Backup.Compression != options.Compression || metadata.Backup.Format != format || metadata.Backup.SizeBytes <= 0 {
+			continue
+		}
+
+		if samples == 0 {
+			latestBytes = metadata.Backup.SizeBytes
+		}
+		samples++
+		if metadata.Database.SizeBytes > 0 {
+			if r := float64(metadata.Backup.SizeBytes) / float64(metadata.Database.SizeBytes); r > ratio {
+				ratio = r
+			}
+		}
+	}
+
+	if databaseSize > 0 && ratio > 0 {
+		return int64(float64(databaseSize) * ratio), nil
+	}
+	return latestBytes, nil
+}
@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveEstimateBackup records a backup of testdb that took seconds to dump
+// size bytes, created days ago.
+func saveEstimateBackup(t *testing.T, tmpDir string, days int, seconds, size int64, schemaOnly bool) {
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+
+	backupID := fmt.Sprintf("backup-%d", days)
+	backupFile := filepath.Join(dbPath, backupID+".sql.gz")
+	createTestBackupFile(t, backupFile, "CREATE TABLE test (id INT);")
+
+	metadata := createTestMetadata(backupID, "testdb", backupFile, "gzip")
+	metadata.CreatedAt = time.Now().AddDate(0, 0, -days)
+	metadata.DurationSeconds = seconds
+	metadata.Backup.SizeBytes = size
+	metadata.Options.SchemaOnly = schemaOnly
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+}
+
+func TestEstimateDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	t.Run("no history", func(t *testing.T) {
+		estimate, err := EstimateDuration(localStorage, "testdb", &BackupOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, estimate)
+	})
+
+	// 1 MB/s on average, with the latest backup at 300 MB
+	saveEstimateBackup(t, tmpDir, 1, 300, 300_000_000, false)
+	saveEstimateBackup(t, tmpDir, 2, 100, 200_000_000, false)
+	saveEstimateBackup(t, tmpDir, 3, 1, 1_000, true) // schema-only, ignored
+
+	t.Run("scales recent rate to latest size", func(t *testing.T) {
+		estimate, err := EstimateDuration(localStorage, "testdb", &BackupOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, 2, estimate.Samples)
+		assert.Equal(t, 240*time.Second, estimate.Duration)
+		assert.Equal(t, 480*time.Second, estimate.Limit(0))
+		assert.Equal(t, 360*time.Second, estimate.Limit(1.5))
+	})
+
+	t.Run("partial backups are not estimated", func(t *testing.T) {
+		estimate, err := EstimateDuration(localStorage, "testdb", &BackupOptions{Tables: []string{"users"}})
+		require.NoError(t, err)
+		assert.Nil(t, estimate)
+	})
+}
+
+func TestEstimateSizeFromHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+	options := &BackupOptions{Compression: CompressionGzip}
+
+	t.Run("no history", func(t *testing.T) {
+		size, err := EstimateSizeFromHistory(localStorage, "testdb", options, 1000)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+	})
+
+	saveEstimateBackup(t, tmpDir, 2, 10, 300, false)
+	saveEstimateBackup(t, tmpDir, 3, 1, 10, true) // schema-only, ignored
+
+	t.Run("latest size without database sizes", func(t *testing.T) {
+		size, err := EstimateSizeFromHistory(localStorage, "testdb", options, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, int64(300), size)
+	})
+
+	// Ratios of 0.3 and 0.2: the higher one is applied
+	saveEstimateBackup(t, tmpDir, 1, 10, 200, false)
+	for id, databaseSize := range map[string]int64{"backup-1": 1000, "backup-2": 1000} {
+		var metadata BackupMetadata
+		require.NoError(t, localStorage.LoadMetadata("testdb", id, &metadata))
+		metadata.Database.SizeBytes = databaseSize
+		require.NoError(t, localStorage.SaveMetadata("testdb", id, &metadata))
+	}
+
+	t.Run("applies observed ratio", func(t *testing.T) {
+		size, err := EstimateSizeFromHistory(localStorage, "testdb", options, 2000)
+		require.NoError(t, err)
+		assert.Equal(t, int64(600), size)
+	})
+
+	t.Run("other compression is not comparable", func(t *testing.T) {
+		size, err := EstimateSizeFromHistory(localStorage, "testdb", &BackupOptions{Compression: CompressionNone}, 2000)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+	})
+
+	t.Run("partial backups are not estimated", func(t *testing.T) {
+		size, err := EstimateSizeFromHistory(localStorage, "testdb", &BackupOptions{Compression: CompressionGzip, SchemaOnly: true}, 2000)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+	})
+}
@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// DefaultExtractSuffix is appended to the database name to form the
+// scratch database of an extract when none is given.
+const DefaultExtractSuffix = "_extract"
+
+// RowStreamer is implemented by clients that can stream the rows of a
+// query, which extracting rows requires.
+type RowStreamer interface {
+	ExecuteQueryStream(ctx context.Context, query string, fn mysql.RowFunc, args ...interface{}) (int64, error)
+}
+
+// ExtractOptions configures the extraction of rows from a backup.
+type ExtractOptions struct {
+	// Database is the source database of the backup
+	Database string
+
+	// ConfigName is the configuration name (used for storage paths)
+	ConfigName string
+
+	// BackupID is the backup to extract from (empty = latest)
+	BackupID string
+
+	// Table is the table whose rows are extracted
+	Table string
+
+	// Where selects the rows, as the condition of a WHERE clause
+	// (empty = every row)
+	Where string
+
+	// ScratchDatabase receives the restored table. It is dropped and
+	// recreated, so it must never hold real data.
+	ScratchDatabase string
+
+	// Keep leaves the scratch database in place afterwards
+	Keep bool
+}
+
+// ExtractResult describes an extraction.
+type ExtractResult struct {
+	// BackupID is the backup the rows were extracted from
+	BackupID string
+
+	// ScratchDatabase is the database the table was restored into
+	ScratchDatabase string
+
+	// Rows is the number of rows written
+	Rows int64
+
+	// Duration is how long the restore and query took
+	Duration time.Duration
+}
+
+// Extract restores one table of a backup into a scratch database, selects
+// the rows matching the options' condition, and writes them to w as
+// INSERT statements for the original table. The scratch database is
+// dropped afterwards unless options.Keep is set.
+func (s *RestoreService) Extract(options *ExtractOptions, w io.Writer) (*ExtractResult, error) {
+	if options == nil || options.Database == "" {
+		return nil, WrapRestoreError("", "source database is required", fmt.Errorf("empty database name"))
+	}
+	if options.Table == "" {
+		return nil, &ValidationError{Field: "Table", Message: "table is required"}
+	}
+	streamer, ok := s.client.(RowStreamer)
+	if !ok {
+		return nil, WrapRestoreError(options.Database, "rows cannot be extracted", fmt.Errorf("the database client cannot stream query results"))
+	}
+
+	scratch := options.ScratchDatabase
+	if scratch == "" {
+		scratch = options.Database + DefaultExtractSuffix
+	}
+	if scratch == options.Database {
+		return nil, &ValidationError{
+			Field:   "ScratchDatabase",
+			Message: "scratch database must differ from the source database",
+		}
+	}
+
+	if err := s.client.DropDatabase(scratch); err != nil {
+		return nil, WrapRestoreError(scratch, "failed to drop scratch database", err)
+	}
+	if !options.Keep {
+		defer s.client.DropDatabase(scratch)
+	}
+
+	restored, err := s.Restore(&RestoreOptions{
+		BackupID:         options.BackupID,
+		Database:         options.Database,
+		ConfigName:       options.ConfigName,
+		TargetDatabase:   scratch,
+		CreateDatabase:   true,
+		SkipConfirmation: true,
+		Table:            options.Table,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExtractResult{BackupID: restored.BackupID, ScratchDatabase: scratch}
+	fmt.Fprintf(w, "-- Rows of %s extracted from backup %s of %s\n", quoteIdentifier(options.Table), restored.BackupID, options.Database)
+	if options.Where != "" {
+		fmt.Fprintf(w, "-- WHERE %s\n", strings.Join(strings.Fields(options.Where), " "))
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s.%s", quoteIdentifier(scratch), quoteIdentifier(options.Table))
+	if options.Where != "" {
+		query += " WHERE " + options.Where
+	}
+	result.Rows, err = streamer.ExecuteQueryStream(context.Background(), query, func(columns []string, row []sql.RawBytes) error {
+		_, err := io.WriteString(w, InsertStatement(options.Table, columns, row))
+		return err
+	})
+	if err != nil {
+		return nil, WrapRestoreError(scratch, "failed to select rows", err)
+	}
+
+	result.Duration = time.Since(restored.StartedAt)
+	return result, nil
+}
+
+// InsertStatement returns an INSERT statement, ending in a newline, that
+// adds the row with the given columns to table. A nil value is NULL.
+func InsertStatement(table string, columns []string, row []sql.RawBytes) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(quoteIdentifier(table))
+	b.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quoteIdentifier(column))
+	}
+	b.WriteString(") VALUES (")
+	for i, value := range row {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writeLiteral(&b, value)
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// writeLiteral writes a value as a SQL literal: NULL, a quoted string, or
+// a hex literal for binary data that is not valid UTF-8.
+func writeLiteral(b *strings.Builder, value []byte) {
+	if value == nil {
+		b.WriteString("NULL")
+		return
+	}
+	if !utf8.Valid(value) {
+		fmt.Fprintf(b, "0x%X", value)
+		return
+	}
+
+	b.WriteByte('\'')
+	for _, c := range value {
+		switch c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		case '\\', '\'':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+}
+
+// quoteIdentifier quotes a table or column name with backquotes.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertStatement(t *testing.T) {
+	row := []sql.RawBytes{
+		sql.RawBytes("1"),
+		sql.RawBytes("O'Brien\\\n"),
+		nil,
+		sql.RawBytes{0xff, 0x00},
+	}
+	stmt := InsertStatement("users", []string{"id", "name", "deleted_at", "avatar"}, row)
+	assert.Equal(t, "INSERT INTO `users` (`id`, `name`, `deleted_at`, `avatar`) VALUES ('1', 'O\\'Brien\\\\\\n', NULL, 0xFF00);\n", stmt)
+}
+
+func TestRestoreServiceExtract(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), tableDump)
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip"))
+
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Databases = []string{"testdb"}
+	client.QueryRows = rows
+
+	restorer := &fakeRestorer{}
+	service := NewRestoreService(client, localStorage, &mysql.Config{Host: "localhost", User: "root"})
+	service.SetEngine(&fakeEngine{name: "fake", restorer: restorer})
+
+	var out bytes.Buffer
+	result, err := service.Extract(&ExtractOptions{Database: "testdb", Table: "users", Where: "id = 2"}, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, backupID, result.BackupID)
+	assert.Equal(t, int64(1), result.Rows)
+	assert.Equal(t, "testdb_extract", restorer.database)
+	assert.NotContains(t, restorer.sql, "orders")
+	assert.Contains(t, out.String(), "INSERT INTO `users` (`id`) VALUES ('2');\n")
+	assert.NotContains(t, client.Databases, "testdb_extract")
+
+	calls := client.GetCalls()
+	var query string
+	for _, call := range calls {
+		if call.Method == "QueryStream" {
+			query = call.Args[0].(string)
+		}
+	}
+	assert.Equal(t, "SELECT * FROM `testdb_extract`.`users` WHERE id = 2", query)
+}
+
+func TestRestoreServiceExtractValidation(t *testing.T) {
+	service := newSourceRestoreService(&fakeRestorer{}, "testdb")
+
+	_, err := service.Extract(&ExtractOptions{Database: "testdb"}, &bytes.Buffer{})
+	assert.True(t, IsValidationError(err))
+
+	_, err = service.Extract(&ExtractOptions{Database: "testdb", Table: "users", ScratchDatabase: "testdb"}, &bytes.Buffer{})
+	assert.True(t, IsValidationError(err))
+}
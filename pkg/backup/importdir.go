@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Import source layouts.
+const (
+	ImportLayoutFile      = "file"
+	ImportLayoutDirectory = "directory"
+	ImportLayoutMydumper  = "mydumper"
+)
+
+// ImportFile is a single SQL file that is part of an import.
+type ImportFile struct {
+	// Path is the full path to the file
+	Path string
+
+	// Name is the file name relative to the import directory
+	Name string
+
+	// Compression is detected from the file extension
+	Compression string
+
+	// SizeBytes is the size of the file on disk
+	SizeBytes int64
+}
+
+// ImportPlan lists the files to import, in execution order.
+type ImportPlan struct {
+	// Source is the path given by the user
+	Source string
+
+	// Layout is one of ImportLayoutFile, ImportLayoutDirectory, ImportLayoutMydumper
+	Layout string
+
+	// Files to import, in order
+	Files []ImportFile
+
+	// TotalBytes is the combined size of all files
+	TotalBytes int64
+}
+
+// DetectCompression returns the compression used by a dump file based on its extension.
+func DetectCompression(path string) string {
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return CompressionGzip
+	}
+	return CompressionNone
+}
+
+// isSQLFile reports whether the file name looks like a SQL dump.
+func isSQLFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".sql") || strings.HasSuffix(lower, ".sql.gz")
+}
+
+// isMydumperDir reports whether dir was produced by mydumper.
+// mydumper always writes a "metadata" file next to the dump files.
+func isMydumperDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "metadata"))
+	return err == nil && !info.IsDir()
+}
+
+// PlanImport builds an import plan for a file or directory.
+// Plain directories are imported in lexical order. mydumper directories are
+// imported schemas first, then table data, then views, triggers and routines.
+func PlanImport(source string) (*ImportPlan, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ImportPlan{Source: source}
+
+	if !info.IsDir() {
+		plan.Layout = ImportLayoutFile
+		plan.Files = []ImportFile{{
+			Path:        source,
+			Name:        filepath.Base(source),
+			Compression: DetectCompression(source),
+			SizeBytes:   info.Size(),
+		}}
+		plan.TotalBytes = info.Size()
+		return plan, nil
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isSQLFile(entry.Name()) {
+			continue
+		}
+		entryInfo, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		plan.Files = append(plan.Files, ImportFile{
+			Path:        filepath.Join(source, entry.Name()),
+			Name:        entry.Name(),
+			Compression: DetectCompression(entry.Name()),
+			SizeBytes:   entryInfo.Size(),
+		})
+	}
+
+	if isMydumperDir(source) {
+		plan.Layout = ImportLayoutMydumper
+		plan.Files = orderMydumperFiles(plan.Files)
+	} else {
+		plan.Layout = ImportLayoutDirectory
+		sort.Slice(plan.Files, func(i, j int) bool {
+			return plan.Files[i].Name < plan.Files[j].Name
+		})
+	}
+
+	if len(plan.Files) == 0 {
+		return nil, fmt.Errorf("no .sql or .sql.gz files found in %s", source)
+	}
+
+	for _, f := range plan.Files {
+		plan.TotalBytes += f.SizeBytes
+	}
+
+	return plan, nil
+}
+
+// mydumperStage returns the import stage for a mydumper file, or -1 if the
+// file should be skipped.
+func mydumperStage(name string) int {
+	base := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(name), ".gz"), ".sql")
+
+	switch {
+	case strings.HasSuffix(base, "-schema-create"):
+		// CREATE DATABASE for the source name; the target database is
+		// selected (and created if needed) by cadangkan instead.
+		return -1
+	case strings.HasSuffix(base, "-schema"):
+		return 0
+	case strings.HasSuffix(base, "-schema-view"):
+		return 2
+	case strings.HasSuffix(base, "-schema-triggers"):
+		return 3
+	case strings.HasSuffix(base, "-schema-post"):
+		return 4
+	default:
+		return 1
+	}
+}
+
+// orderMydumperFiles sorts mydumper files into a safe import order.
+func orderMydumperFiles(files []ImportFile) []ImportFile {
+	ordered := make([]ImportFile, 0, len(files))
+	for _, f := range files {
+		if mydumperStage(f.Name) >= 0 {
+			ordered = append(ordered, f)
+		}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		si, sj := mydumperStage(ordered[i].Name), mydumperStage(ordered[j].Name)
+		if si != sj {
+			return si < sj
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	return ordered
+}
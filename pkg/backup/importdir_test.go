@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;\n"), 0644))
+	}
+}
+
+func importFileNames(plan *ImportPlan) []string {
+	names := make([]string, 0, len(plan.Files))
+	for _, f := range plan.Files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestPlanImportSingleFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, "dump.sql.gz")
+
+	plan, err := PlanImport(filepath.Join(tmpDir, "dump.sql.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, ImportLayoutFile, plan.Layout)
+	require.Len(t, plan.Files, 1)
+	assert.Equal(t, CompressionGzip, plan.Files[0].Compression)
+	assert.Equal(t, int64(10), plan.TotalBytes)
+}
+
+func TestPlanImportDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, "02_data.sql.gz", "01_schema.sql", "README.md", "03_views.SQL")
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "nested.sql"), 0755))
+
+	plan, err := PlanImport(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, ImportLayoutDirectory, plan.Layout)
+	assert.Equal(t, []string{"01_schema.sql", "02_data.sql.gz", "03_views.SQL"}, importFileNames(plan))
+	assert.Equal(t, CompressionNone, plan.Files[0].Compression)
+	assert.Equal(t, CompressionGzip, plan.Files[1].Compression)
+}
+
+func TestPlanImportEmptyDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, "notes.txt")
+
+	_, err := PlanImport(tmpDir)
+	assert.Error(t, err)
+}
+
+func TestPlanImportMydumper(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir,
+		"metadata",
+		"shop-schema-create.sql",
+		"shop.orders.00000.sql.gz",
+		"shop.orders.00001.sql.gz",
+		"shop.orders-schema.sql.gz",
+		"shop.customers.sql",
+		"shop.customers-schema.sql",
+		"shop.order_totals-schema-view.sql",
+		"shop.orders-schema-triggers.sql",
+		"shop-schema-post.sql",
+	)
+
+	plan, err := PlanImport(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, ImportLayoutMydumper, plan.Layout)
+	assert.Equal(t, []string{
+		"shop.customers-schema.sql",
+		"shop.orders-schema.sql.gz",
+		"shop.customers.sql",
+		"shop.orders.00000.sql.gz",
+		"shop.orders.00001.sql.gz",
+		"shop.order_totals-schema-view.sql",
+		"shop.orders-schema-triggers.sql",
+		"shop-schema-post.sql",
+	}, importFileNames(plan))
+}
+
+func TestPlanImportNotFound(t *testing.T) {
+	_, err := PlanImport(filepath.Join(t.TempDir(), "missing.sql"))
+	assert.True(t, os.IsNotExist(err))
+}
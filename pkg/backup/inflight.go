@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// inFlightBackup is the space a running backup is expected to need.
+type inFlightBackup struct {
+	device   uint64 // Filesystem the backup is written to
+	path     string // Backup file being written
+	estimate int64  // Expected size of the finished backup
+}
+
+// inFlight tracks the backups running in this process, so that backups
+// running at once (as the daemon does) do not each count the same free
+// space as their own.
+var inFlight = struct {
+	sync.Mutex
+	backups map[*inFlightBackup]struct{}
+}{backups: make(map[*inFlightBackup]struct{})}
+
+// reserveSpace checks that stor has room for a backup of estimate bytes
+// written to path on top of the backups already in flight on the same
+// filesystem, and reserves it. It returns whether there was room and the
+// space still needed by the other backups. The returned function releases
+// the reservation and must be called once the backup has finished.
+func reserveSpace(stor *storage.LocalStorage, path string, estimate int64) (func(), bool, int64, error) {
+	device, _ := deviceOf(stor.GetBasePath())
+
+	inFlight.Lock()
+	defer inFlight.Unlock()
+
+	pending := pendingBytes(device)
+	ok, err := stor.HasEnoughSpace(estimate + pending)
+	if err != nil || !ok {
+		return func() {}, false, pending, err
+	}
+
+	reservation := &inFlightBackup{device: device, path: path, estimate: estimate}
+	inFlight.backups[reservation] = struct{}{}
+	return func() {
+		inFlight.Lock()
+		delete(inFlight.backups, reservation)
+		inFlight.Unlock()
+	}, true, pending, nil
+}
+
+// pendingBytes returns the space backups in flight on device still need:
+// their estimates less what they have written so far. The caller must hold
+// the inFlight lock.
+func pendingBytes(device uint64) int64 {
+	var pending int64
+	for b := range inFlight.backups {
+		if b.device != device {
+			continue
+		}
+		remaining := b.estimate
+		if info, err := os.Stat(b.path); err == nil {
+			remaining -= info.Size()
+		}
+		if remaining > 0 {
+			pending += remaining
+		}
+	}
+	return pending
+}
+
+// deviceOf returns the ID of the filesystem holding path.
+func deviceOf(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveSpace(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+	available, err := localStorage.CheckDiskSpace()
+	require.NoError(t, err)
+
+	// Each of the two backups fits on its own, but not both at once
+	half := int64(available / 2)
+	first := filepath.Join(tmpDir, "first.sql.gz")
+	release, ok, pending, err := reserveSpace(localStorage, first, half)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Zero(t, pending)
+
+	_, ok, pending, err = reserveSpace(localStorage, filepath.Join(tmpDir, "second.sql.gz"), half)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, half, pending)
+
+	// What the first backup has written no longer counts as pending
+	require.NoError(t, os.WriteFile(first, make([]byte, 1000), 0644))
+	_, _, pending, err = reserveSpace(localStorage, filepath.Join(tmpDir, "second.sql.gz"), half)
+	require.NoError(t, err)
+	assert.Equal(t, half-1000, pending)
+
+	release()
+	next, ok, pending, err := reserveSpace(localStorage, filepath.Join(tmpDir, "second.sql.gz"), half/2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Zero(t, pending)
+	next()
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"path/filepath"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// LineageNode is a backup with its links to other backups.
+type LineageNode struct {
+	// Entry is the listing entry of the backup
+	Entry storage.BackupListEntry
+
+	// Metadata is nil when the metadata cannot be read
+	Metadata *BackupMetadata
+
+	// Dependents are the incremental backups based on this backup, which
+	// cannot be restored once it is pruned
+	Dependents []string
+
+	// MissingBase is set when the base of an incremental backup no longer
+	// exists
+	MissingBase bool
+}
+
+// RecordRestore appends a successful restore to the metadata of the
+// restored backup.
+func RecordRestore(stor *storage.LocalStorage, database, backupID string, record RestoreRecord) error {
+	var metadata BackupMetadata
+	if err := stor.LoadMetadata(database, backupID, &metadata); err != nil {
+		return err
+	}
+	metadata.Restores = append(metadata.Restores, record)
+	return stor.SaveMetadata(database, backupID, &metadata)
+}
+
+// FindBackupByPath returns the ID of the backup of database whose backup
+// file is at path, or "" when path is not a backup in managed storage.
+func FindBackupByPath(stor *storage.LocalStorage, database, path string) (string, error) {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	backups, err := stor.ListBackups(database)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range backups {
+		if filePath, err := filepath.Abs(entry.FilePath); err == nil && filePath == target {
+			return entry.BackupID, nil
+		}
+	}
+	return "", nil
+}
+
+// BuildLineage returns the backups of database, oldest first, linked to the
+// backups they depend on and the backups depending on them.
+func BuildLineage(stor *storage.LocalStorage, database string) ([]*LineageNode, error) {
+	backups, err := stor.ListBackups(database)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*LineageNode, 0, len(backups))
+	byID := make(map[string]*LineageNode, len(backups))
+	for i := len(backups) - 1; i >= 0; i-- {
+		node := &LineageNode{Entry: backups[i]}
+		var metadata BackupMetadata
+		if err := stor.LoadMetadata(database, backups[i].BackupID, &metadata); err == nil {
+			node.Metadata = &metadata
+		}
+		nodes = append(nodes, node)
+		byID[node.Entry.BackupID] = node
+	}
+
+	for _, node := range nodes {
+		if node.Metadata == nil || node.Metadata.Base == "" {
+			continue
+		}
+		if base, ok := byID[node.Metadata.Base]; ok {
+			base.Dependents = append(base.Dependents, node.Entry.BackupID)
+		} else {
+			node.MissingBase = true
+		}
+	}
+	return nodes, nil
+}
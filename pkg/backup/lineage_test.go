@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	createRetentionBackup(t, tmpDir, backupID, time.Now())
+
+	record := RestoreRecord{TargetDatabase: "testdb", Host: "db1", Port: 3306, RestoredAt: time.Now(), SnapshotID: "2025-01-16-090000"}
+	require.NoError(t, RecordRestore(localStorage, "testdb", backupID, record))
+	require.NoError(t, RecordRestore(localStorage, "testdb", backupID, RestoreRecord{TargetDatabase: "staging", RestoredAt: time.Now()}))
+
+	var metadata BackupMetadata
+	require.NoError(t, localStorage.LoadMetadata("testdb", backupID, &metadata))
+	assert.Equal(t, StatusCompleted, metadata.Status)
+	require.Len(t, metadata.Restores, 2)
+	assert.Equal(t, "2025-01-16-090000", metadata.Restores[0].SnapshotID)
+	assert.Equal(t, "staging", metadata.Restores[1].TargetDatabase)
+}
+
+func TestFindBackupByPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	createRetentionBackup(t, tmpDir, backupID, time.Now())
+
+	found, err := FindBackupByPath(localStorage, "testdb", filepath.Join(tmpDir, "testdb", backupID+".sql.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, backupID, found)
+
+	for _, path := range []string{filepath.Join(t.TempDir(), backupID+".sql.gz"), StdinSource, "https://example.com/backup.sql.gz"} {
+		found, err := FindBackupByPath(localStorage, "testdb", path)
+		require.NoError(t, err)
+		assert.Empty(t, found, path)
+	}
+
+	found, err = FindBackupByPath(localStorage, "otherdb", filepath.Join(tmpDir, "testdb", backupID+".sql.gz"))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestBuildLineage(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	setBase := func(backupID, base string) {
+		var metadata BackupMetadata
+		require.NoError(t, localStorage.LoadMetadata("testdb", backupID, &metadata))
+		metadata.Base = base
+		require.NoError(t, localStorage.SaveMetadata("testdb", backupID, &metadata))
+	}
+
+	now := time.Now()
+	createRetentionBackup(t, tmpDir, "2025-01-01-120000", now.Add(-72*time.Hour))
+	createRetentionBackup(t, tmpDir, "2025-01-02-120000", now.Add(-48*time.Hour))
+	setBase("2025-01-02-120000", "2025-01-01-120000")
+	createRetentionBackup(t, tmpDir, "2025-01-03-120000", now.Add(-24*time.Hour))
+	setBase("2025-01-03-120000", "2024-12-31-120000")
+
+	nodes, err := BuildLineage(localStorage, "testdb")
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+
+	// Oldest first
+	assert.Equal(t, "2025-01-01-120000", nodes[0].Entry.BackupID)
+	assert.Equal(t, []string{"2025-01-02-120000"}, nodes[0].Dependents)
+	assert.False(t, nodes[1].MissingBase)
+	assert.True(t, nodes[2].MissingBase)
+	require.NotNil(t, nodes[2].Metadata)
+	assert.Equal(t, "2024-12-31-120000", nodes[2].Metadata.Base)
+}
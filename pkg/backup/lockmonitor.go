@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// Actions for queries found blocked while a backup runs.
+const (
+	// LockActionWarn reports blocked queries and lets the backup finish
+	LockActionWarn = "warn"
+
+	// LockActionAbort stops the backup to release its locks
+	LockActionAbort = "abort"
+)
+
+// Lock monitor defaults.
+const (
+	DefaultLockThreshold = 30 * time.Second
+	DefaultLockInterval  = 5 * time.Second
+)
+
+// LockInspector is implemented by introspectors that can list the
+// connections waiting for locks. mysql.DatabaseClient satisfies it.
+type LockInspector interface {
+	GetLockWaits() ([]mysql.Process, error)
+}
+
+// Canceler is implemented by dump readers that can stop their dump tool.
+type Canceler interface {
+	Cancel()
+}
+
+// LockMonitorPolicy decides what to do about queries waiting on locks while
+// a backup runs, which are most likely blocked by the dump.
+type LockMonitorPolicy struct {
+	// Threshold is how long a query must have waited to be reported
+	Threshold time.Duration
+
+	// Interval is how often the server is checked
+	Interval time.Duration
+
+	// Action is LockActionWarn (default) or LockActionAbort
+	Action string
+}
+
+// Validate checks the policy action and interval.
+func (p *LockMonitorPolicy) Validate() error {
+	switch p.Action {
+	case "", LockActionWarn, LockActionAbort:
+	default:
+		return &ValidationError{
+			Field:   "lock_monitor.action",
+			Message: fmt.Sprintf("invalid lock monitor action: %s (must be warn or abort)", p.Action),
+		}
+	}
+	if p.Interval <= 0 {
+		return &ValidationError{Field: "lock_monitor.interval", Message: "interval must be positive"}
+	}
+	return nil
+}
+
+// LockMonitor polls the server for blocked queries while a dump runs.
+type LockMonitor struct {
+	policy    *LockMonitorPolicy
+	inspector LockInspector
+	warn      func(string)
+
+	// warned holds the connections already reported
+	warned map[int64]bool
+
+	mu   sync.Mutex
+	err  error // Why the backup was aborted, if it was
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLockMonitor returns a monitor checking client with the policy and
+// passing warnings to warn, or nil when client cannot list lock waits.
+func NewLockMonitor(policy *LockMonitorPolicy, client Introspector, warn func(string)) *LockMonitor {
+	inspector, ok := client.(LockInspector)
+	if !ok {
+		return nil
+	}
+	return &LockMonitor{
+		policy:    policy,
+		inspector: inspector,
+		warn:      warn,
+		warned:    make(map[int64]bool),
+	}
+}
+
+// Watch polls the server until Stop is called. With LockActionAbort, the
+// first blocked query stops dump, if it can be canceled, and makes reads
+// from the returned reader fail.
+func (m *LockMonitor) Watch(dump io.Reader) io.Reader {
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+
+			ok, err := m.check()
+			if !ok {
+				return
+			}
+			if err != nil {
+				m.mu.Lock()
+				m.err = err
+				m.mu.Unlock()
+				if c, ok := dump.(Canceler); ok {
+					c.Cancel()
+				}
+				return
+			}
+		}
+	}()
+
+	return &lockMonitorReader{reader: dump, monitor: m}
+}
+
+// check reports the queries blocked for longer than the threshold, and
+// returns an error when the backup should be aborted. It returns false when
+// the server cannot be checked, which stops monitoring but not the backup.
+func (m *LockMonitor) check() (bool, error) {
+	waits, err := m.inspector.GetLockWaits()
+	if err != nil {
+		m.warn(fmt.Sprintf("lock monitoring stopped: %v", err))
+		return false, nil
+	}
+
+	for _, wait := range waits {
+		if wait.Time < m.policy.Threshold || m.warned[wait.ID] {
+			continue
+		}
+		m.warned[wait.ID] = true
+
+		message := fmt.Sprintf("query %d by %s@%s blocked for %s (%s): %s",
+			wait.ID, wait.User, wait.Host, wait.Time, wait.State, truncateQuery(wait.Info))
+		if m.policy.Action == LockActionAbort {
+			return true, fmt.Errorf("backup aborted, %s", message)
+		}
+		m.warn(message)
+	}
+	return true, nil
+}
+
+// Stop ends monitoring and returns the reason the backup was aborted, if
+// it was.
+func (m *LockMonitor) Stop() error {
+	close(m.stop)
+	<-m.done
+	return m.aborted()
+}
+
+// aborted returns the reason the backup was aborted, if it was.
+func (m *LockMonitor) aborted() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// lockMonitorReader fails reads once the monitor aborts the backup.
+type lockMonitorReader struct {
+	reader  io.Reader
+	monitor *LockMonitor
+}
+
+// Read implements io.Reader. The abort is checked after reading too, as a
+// canceled dump ends with what looks like a complete read.
+func (r *lockMonitorReader) Read(p []byte) (int, error) {
+	if err := r.monitor.aborted(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	if abortErr := r.monitor.aborted(); abortErr != nil {
+		return n, abortErr
+	}
+	return n, err
+}
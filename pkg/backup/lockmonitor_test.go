@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stalledDump is a dump that produces no data until it is canceled.
+type stalledDump struct {
+	canceled chan struct{}
+	once     sync.Once
+}
+
+func newStalledDump() *stalledDump {
+	return &stalledDump{canceled: make(chan struct{})}
+}
+
+func (d *stalledDump) Read(p []byte) (int, error) {
+	<-d.canceled
+	return 0, io.EOF
+}
+
+func (d *stalledDump) Close() error { return nil }
+
+func (d *stalledDump) Cancel() {
+	d.once.Do(func() { close(d.canceled) })
+}
+
+func (d *stalledDump) DumpWithCommand(database string, options *DumpOptions, cmdLogger func(string)) (io.ReadCloser, error) {
+	return d, nil
+}
+
+// lockWaitClient returns a connected mock client with a query blocked for
+// a minute.
+func lockWaitClient() *mysql.MockClient {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.LockWaits = []mysql.Process{
+		{ID: 20, User: "app", Host: "10.0.0.5", Command: "Query", Time: time.Minute,
+			State: "Waiting for table metadata lock", Info: "ALTER TABLE orders ADD note TEXT"},
+		{ID: 21, User: "app", Host: "10.0.0.5", Command: "Query", Time: time.Second,
+			State: "Waiting for table flush", Info: "SELECT 1"},
+	}
+	return client
+}
+
+func TestLockMonitorWarn(t *testing.T) {
+	var mu sync.Mutex
+	var warnings []string
+	monitor := NewLockMonitor(&LockMonitorPolicy{Threshold: 30 * time.Second, Interval: time.Millisecond},
+		lockWaitClient(), func(message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, message)
+		})
+	require.NotNil(t, monitor)
+
+	input := monitor.Watch(strings.NewReader("dump"))
+	time.Sleep(20 * time.Millisecond)
+	data, err := io.ReadAll(input)
+	require.NoError(t, err)
+	assert.Equal(t, "dump", string(data))
+	require.NoError(t, monitor.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, warnings, 1, "each blocked query is reported once")
+	assert.Equal(t, "query 20 by app@10.0.0.5 blocked for 1m0s (Waiting for table metadata lock): ALTER TABLE orders ADD note TEXT", warnings[0])
+}
+
+func TestLockMonitorAbort(t *testing.T) {
+	dump := newStalledDump()
+	monitor := NewLockMonitor(&LockMonitorPolicy{Threshold: 30 * time.Second, Interval: time.Millisecond, Action: LockActionAbort},
+		lockWaitClient(), func(string) {})
+
+	input := monitor.Watch(dump)
+	_, err := io.ReadAll(input)
+	assert.ErrorContains(t, err, "backup aborted, query 20")
+	assert.ErrorContains(t, monitor.Stop(), "backup aborted")
+}
+
+func TestLockMonitorCheckFails(t *testing.T) {
+	client := lockWaitClient()
+	client.LockWaitErr = errors.New("access denied")
+	var warning string
+	monitor := NewLockMonitor(&LockMonitorPolicy{Interval: time.Millisecond, Action: LockActionAbort},
+		client, func(message string) { warning = message })
+
+	monitor.Watch(strings.NewReader(""))
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, monitor.Stop())
+	assert.Equal(t, "lock monitoring stopped: access denied", warning)
+	assert.Equal(t, 1, client.GetCallCount("GetLockWaits"))
+}
+
+func TestBackupToWriterLockMonitorAbort(t *testing.T) {
+	service := NewService(lockWaitClient(), nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: newStalledDump()})
+	service.SetLogOutput(&bytes.Buffer{})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.LockMonitorPolicy = &LockMonitorPolicy{Threshold: 30 * time.Second, Interval: time.Millisecond, Action: LockActionAbort}
+
+	_, err := service.BackupToWriter(options, io.Discard)
+	assert.ErrorContains(t, err, "backup aborted")
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// Actions for long-running queries found before a backup.
+const (
+	// LongQueryActionWarn reports long-running queries and backs up anyway
+	LongQueryActionWarn = "warn"
+
+	// LongQueryActionKill kills the connections running them first
+	LongQueryActionKill = "kill"
+)
+
+// DefaultLongQueryThreshold is how long a query must run to be reported
+// when no threshold is configured.
+const DefaultLongQueryThreshold = 60 * time.Second
+
+// maxQueryLength is how much of a statement is shown in warnings.
+const maxQueryLength = 80
+
+// ProcessInspector is implemented by introspectors that can list and kill
+// the connections on the server. mysql.DatabaseClient satisfies it.
+type ProcessInspector interface {
+	GetProcessList() ([]mysql.Process, error)
+	KillConnection(id int64) error
+}
+
+// LongQueryPolicy decides what to do about queries that have been running
+// for a long time when a backup starts. They can block the FLUSH TABLES and
+// metadata locks the dump takes, stalling it and every query queued behind
+// it.
+type LongQueryPolicy struct {
+	// Threshold is how long a query must have run to count as long
+	Threshold time.Duration
+
+	// Action is LongQueryActionWarn (default) or LongQueryActionKill
+	Action string
+}
+
+// Validate checks the policy action.
+func (p *LongQueryPolicy) Validate() error {
+	switch p.Action {
+	case "", LongQueryActionWarn, LongQueryActionKill:
+		return nil
+	default:
+		return &ValidationError{
+			Field:   "long_queries.action",
+			Message: fmt.Sprintf("invalid long query action: %s (must be warn or kill)", p.Action),
+		}
+	}
+}
+
+// Check lists the queries running longer than the threshold and, with
+// LongQueryActionKill, kills their connections. It returns a warning for
+// each one. Servers that cannot list their connections are not checked.
+func (p *LongQueryPolicy) Check(client Introspector) ([]string, error) {
+	inspector, ok := client.(ProcessInspector)
+	if !ok {
+		return nil, nil
+	}
+
+	processes, err := inspector.GetProcessList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running queries: %w", err)
+	}
+
+	var warnings []string
+	for _, process := range processes {
+		if process.Idle() || process.Time < p.Threshold {
+			continue
+		}
+
+		warning := fmt.Sprintf("query %d by %s@%s has been running for %s: %s",
+			process.ID, process.User, process.Host, process.Time, truncateQuery(process.Info))
+		if p.Action == LongQueryActionKill {
+			if err := inspector.KillConnection(process.ID); err != nil {
+				return warnings, fmt.Errorf("failed to kill connection %d: %w", process.ID, err)
+			}
+			warning += " (killed)"
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
+
+// truncateQuery shortens a statement to one line for warnings.
+func truncateQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxQueryLength {
+		return query[:maxQueryLength] + "..."
+	}
+	return query
+}
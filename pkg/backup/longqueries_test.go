@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// longQueryClient returns a connected mock client with a long-running
+// query, a short one and an idle connection.
+func longQueryClient() *mysql.MockClient {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Processes = []mysql.Process{
+		{ID: 10, User: "app", Host: "10.0.0.5", Command: "Query", Time: 10 * time.Minute, Info: "SELECT *\n  FROM orders"},
+		{ID: 11, User: "app", Host: "10.0.0.5", Command: "Query", Time: 5 * time.Second, Info: "SELECT 1"},
+		{ID: 12, User: "app", Host: "10.0.0.6", Command: "Sleep", Time: time.Hour},
+	}
+	return client
+}
+
+func TestLongQueryPolicyCheck(t *testing.T) {
+	t.Run("warn", func(t *testing.T) {
+		client := longQueryClient()
+		policy := &LongQueryPolicy{Threshold: time.Minute}
+
+		warnings, err := policy.Check(client)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"query 10 by app@10.0.0.5 has been running for 10m0s: SELECT * FROM orders"}, warnings)
+		assert.Equal(t, 0, client.GetCallCount("KillConnection"))
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		client := longQueryClient()
+		policy := &LongQueryPolicy{Threshold: time.Minute, Action: LongQueryActionKill}
+
+		warnings, err := policy.Check(client)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "(killed)")
+		assert.Len(t, client.Processes, 2)
+	})
+
+	t.Run("kill fails", func(t *testing.T) {
+		client := longQueryClient()
+		client.KillErr = errors.New("access denied")
+		policy := &LongQueryPolicy{Threshold: time.Minute, Action: LongQueryActionKill}
+
+		_, err := policy.Check(client)
+		assert.ErrorContains(t, err, "failed to kill connection 10")
+	})
+
+	t.Run("process list fails", func(t *testing.T) {
+		client := longQueryClient()
+		client.ProcessErr = errors.New("access denied")
+
+		_, err := (&LongQueryPolicy{Threshold: time.Minute}).Check(client)
+		assert.ErrorContains(t, err, "failed to list running queries")
+	})
+}
+
+func TestTruncateQuery(t *testing.T) {
+	assert.Equal(t, "SELECT 1", truncateQuery("  SELECT\n\t1 "))
+	long := truncateQuery(string(bytes.Repeat([]byte("x"), 200)))
+	assert.Len(t, long, maxQueryLength+3)
+}
+
+func TestBackupToWriterLongQueries(t *testing.T) {
+	client := longQueryClient()
+	service := NewService(client, nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: &fakeDumper{data: "SELECT 1;\n"}})
+	var log bytes.Buffer
+	service.SetLogOutput(&log)
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.LongQueryPolicy = &LongQueryPolicy{Threshold: time.Minute}
+
+	_, err := service.BackupToWriter(options, io.Discard)
+	require.NoError(t, err)
+	assert.Contains(t, log.String(), "long-running query 10")
+
+	client.ProcessErr = errors.New("access denied")
+	_, err = service.BackupToWriter(options, io.Discard)
+	assert.ErrorContains(t, err, "long query check failed")
+}
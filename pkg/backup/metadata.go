@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 )
 
 const (
@@ -21,11 +22,11 @@ const (
 
 // MetadataGenerator creates backup metadata.
 type MetadataGenerator struct {
-	client mysql.DatabaseClient
+	client Introspector
 }
 
 // NewMetadataGenerator creates a new MetadataGenerator.
-func NewMetadataGenerator(client mysql.DatabaseClient) *MetadataGenerator {
+func NewMetadataGenerator(client Introspector) *MetadataGenerator {
 	return &MetadataGenerator{
 		client: client,
 	}
@@ -37,16 +38,20 @@ func (g *MetadataGenerator) Generate(
 	dbConfig *mysql.Config,
 	result *BackupResult,
 	options *BackupOptions,
-	mysqldumpVersion string,
+	dumperVersion string,
 ) (*BackupMetadata, error) {
-	// Get database version if client is available and connected
+	// Get database version if client is available and connected. The dump
+	// may have run long enough for the pool to die, so ping it first.
 	var dbVersion string
-	if g.client != nil && g.client.IsConnected() {
+	if g.client != nil && g.client.IsConnected() && g.client.Ping() == nil {
 		version, err := g.client.GetVersion()
 		if err == nil {
 			dbVersion = version
 		}
 	}
+	if dbVersion == "" {
+		dbVersion = result.ServerVersion
+	}
 
 	// Get file name from path
 	fileName := filepath.Base(result.FilePath)
@@ -56,35 +61,61 @@ func (g *MetadataGenerator) Generate(
 		Version:  MetadataVersion,
 		BackupID: backupID,
 		Database: DatabaseInfo{
-			Type:     "mysql",
-			Host:     dbConfig.Host,
-			Port:     dbConfig.Port,
-			Database: options.Database,
-			Version:  dbVersion,
+			Type:      "mysql",
+			Host:      dbConfig.Host,
+			Port:      dbConfig.Port,
+			Database:  options.Database,
+			Databases: options.Databases,
+			Version:   dbVersion,
+			SizeBytes: result.DatabaseSize,
 		},
 		CreatedAt:       result.StartedAt,
 		CompletedAt:     result.CompletedAt,
 		DurationSeconds: int64(result.Duration.Seconds()),
 		Status:          result.Status,
 		Backup: BackupFileInfo{
-			File:        fileName,
-			SizeBytes:   result.SizeBytes,
-			SizeHuman:   FormatBytes(result.SizeBytes),
-			Compression: options.Compression,
-			Checksum:    result.Checksum,
+			File:         fileName,
+			SizeBytes:    result.SizeBytes,
+			SizeHuman:    FormatBytes(result.SizeBytes),
+			Compression:  options.Compression,
+			Checksum:     result.Checksum,
+			ChecksumFile: result.Checksum,
+			ChecksumRaw:  result.RawChecksum,
+			Index:        result.Index,
 		},
 		Options: BackupOptionsInfo{
 			SchemaOnly:    options.SchemaOnly,
 			Tables:        options.Tables,
 			ExcludeTables: options.ExcludeTables,
+			DumpCommand:   result.DumpCommand,
+			Definers:      options.Definers,
 		},
 		Tool: ToolInfo{
-			Name:             ToolName,
-			Version:          ToolVersion,
-			MySQLDumpVersion: mysqldumpVersion,
+			Name:    ToolName,
+			Version: ToolVersion,
 		},
 	}
 
+	// Record which dump tool produced the backup
+	if options.Engine == EngineMydumper {
+		metadata.Backup.Format = FormatMydumper
+		metadata.Tool.MydumperVersion = dumperVersion
+	} else {
+		metadata.Tool.MySQLDumpVersion = dumperVersion
+	}
+
+	metadata.Tool.Compatibility = result.Compatibility
+	if result.Charset != nil {
+		metadata.Database.Charset = result.Charset.Name
+		metadata.Database.Collation = result.Charset.Collation
+	}
+	metadata.Database.SQLMode = result.SQLMode
+	if options.SQLSecurityInvoker {
+		metadata.Options.SQLSecurity = "invoker"
+	}
+	metadata.Warnings = result.Warnings
+	metadata.PreRestore = options.PreRestore
+
 	// Set error if backup failed
 	if result.Status == StatusFailed && result.Error != nil {
 		metadata.Error = result.Error.Error()
@@ -142,6 +173,52 @@ func GenerateSimple(
 	}
 }
 
+// FileChecksum returns the checksum of the backup file, falling back to the
+// checksum field of metadata written before checksum_file existed.
+func (b *BackupFileInfo) FileChecksum() string {
+	if b.ChecksumFile != "" {
+		return b.ChecksumFile
+	}
+	return b.Checksum
+}
+
+// MigrateChecksums fills checksum_file in metadata written by older
+// versions, which only recorded the checksum of the file. The raw checksum
+// of such backups is unknown and stays empty. Returns true if the metadata
+// was changed.
+func MigrateChecksums(metadata *BackupMetadata) bool {
+	if metadata.Backup.ChecksumFile != "" || metadata.Backup.Checksum == "" {
+		return false
+	}
+	metadata.Backup.ChecksumFile = metadata.Backup.Checksum
+	return true
+}
+
+// MigrateMetadata rewrites the metadata of a database's backups that
+// predate checksum_file. Returns the number of files migrated.
+func MigrateMetadata(stor *storage.LocalStorage, database string) (int, error) {
+	backups, err := stor.ListBackups(database)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, entry := range backups {
+		var metadata BackupMetadata
+		if err := stor.LoadMetadata(database, entry.BackupID, &metadata); err != nil {
+			return migrated, err
+		}
+		if !MigrateChecksums(&metadata) {
+			continue
+		}
+		if err := stor.SaveMetadata(database, entry.BackupID, &metadata); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
 // UpdateMetadata updates an existing metadata with final information.
 func UpdateMetadata(metadata *BackupMetadata, result *BackupResult) {
 	metadata.CompletedAt = result.CompletedAt
@@ -150,6 +227,8 @@ func UpdateMetadata(metadata *BackupMetadata, result *BackupResult) {
 	metadata.Backup.SizeBytes = result.SizeBytes
 	metadata.Backup.SizeHuman = FormatBytes(result.SizeBytes)
 	metadata.Backup.Checksum = result.Checksum
+	metadata.Backup.ChecksumFile = result.Checksum
+	metadata.Backup.ChecksumRaw = result.RawChecksum
 
 	if result.Error != nil {
 		metadata.Error = result.Error.Error()
@@ -169,10 +248,11 @@ func CreateInitialMetadata(
 		Version:  MetadataVersion,
 		BackupID: backupID,
 		Database: DatabaseInfo{
-			Type:     "mysql",
-			Host:     dbConfig.Host,
-			Port:     dbConfig.Port,
-			Database: database,
+			Type:      "mysql",
+			Host:      dbConfig.Host,
+			Port:      dbConfig.Port,
+			Database:  database,
+			Databases: options.Databases,
 		},
 		CreatedAt:       now,
 		DurationSeconds: 0,
@@ -186,6 +266,7 @@ func CreateInitialMetadata(
 			Name:    ToolName,
 			Version: ToolVersion,
 		},
+		PreRestore: options.PreRestore,
 	}
 }
 
@@ -251,14 +332,19 @@ func FormatDuration(d time.Duration) string {
 		seconds := int(d.Seconds()) - (minutes * 60)
 		return fmt.Sprintf("%dm %ds", minutes, seconds)
 	}
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) - (hours * 60)
-	return fmt.Sprintf("%dh %dm", hours, minutes)
+	if d < 24*time.Hour {
+		hours := int(d.Hours())
+		minutes := int(d.Minutes()) - (hours * 60)
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	days := int(d.Hours() / 24)
+	hours := int(d.Hours()) - (days * 24)
+	return fmt.Sprintf("%dd %dh", days, hours)
 }
 
 // GetMySQLDumpVersion gets the mysqldump version.
 func GetMySQLDumpVersion() string {
-	version, err := CheckMySQLDump()
+	version, err := CheckMySQLDump(nil)
 	if err != nil {
 		return "unknown"
 	}
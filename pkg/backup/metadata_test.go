@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,6 +72,7 @@ func TestUpdateMetadata(t *testing.T) {
 		BackupID:    "test-backup",
 		SizeBytes:   1024000,
 		Checksum:    "sha256:test",
+		RawChecksum: "sha256:raw",
 		Duration:    30 * time.Second,
 		Status:      StatusCompleted,
 		StartedAt:   time.Now().Add(-30 * time.Second),
@@ -82,9 +84,48 @@ func TestUpdateMetadata(t *testing.T) {
 	assert.Equal(t, StatusCompleted, metadata.Status)
 	assert.Equal(t, int64(1024000), metadata.Backup.SizeBytes)
 	assert.Equal(t, "sha256:test", metadata.Backup.Checksum)
+	assert.Equal(t, "sha256:test", metadata.Backup.ChecksumFile)
+	assert.Equal(t, "sha256:raw", metadata.Backup.ChecksumRaw)
 	assert.Equal(t, int64(30), metadata.DurationSeconds)
 }
 
+func TestMigrateChecksums(t *testing.T) {
+	legacy := &BackupMetadata{Backup: BackupFileInfo{Checksum: "sha256:file"}}
+	assert.True(t, MigrateChecksums(legacy))
+	assert.Equal(t, "sha256:file", legacy.Backup.ChecksumFile)
+	assert.Empty(t, legacy.Backup.ChecksumRaw)
+	assert.False(t, MigrateChecksums(legacy))
+
+	assert.False(t, MigrateChecksums(&BackupMetadata{}))
+}
+
+func TestMigrateMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	createRetentionBackup(t, tmpDir, "2025-01-01-120000", time.Now())
+
+	var before BackupMetadata
+	require.NoError(t, localStorage.LoadMetadata("testdb", "2025-01-01-120000", &before))
+	before.Backup.Checksum = "sha256:legacy"
+	before.Backup.ChecksumFile = ""
+	require.NoError(t, localStorage.SaveMetadata("testdb", "2025-01-01-120000", &before))
+
+	migrated, err := MigrateMetadata(localStorage, "testdb")
+	require.NoError(t, err)
+	assert.Equal(t, 1, migrated)
+
+	var after BackupMetadata
+	require.NoError(t, localStorage.LoadMetadata("testdb", "2025-01-01-120000", &after))
+	assert.Equal(t, "sha256:legacy", after.Backup.ChecksumFile)
+	assert.Equal(t, "sha256:legacy", after.Backup.FileChecksum())
+
+	migrated, err = MigrateMetadata(localStorage, "testdb")
+	require.NoError(t, err)
+	assert.Zero(t, migrated)
+}
+
 func TestMarkFailed(t *testing.T) {
 	metadata := &BackupMetadata{
 		BackupID:  "test-backup",
@@ -205,6 +246,8 @@ func TestFormatDuration(t *testing.T) {
 		{5 * time.Minute, "5m 0s"},
 		{65 * time.Minute, "1h 5m"},
 		{125 * time.Minute, "2h 5m"},
+		{26 * time.Hour, "1d 2h"},
+		{8 * 24 * time.Hour, "8d 0h"},
 	}
 
 	for _, tt := range tests {
@@ -243,6 +286,7 @@ func TestMetadataGenerator(t *testing.T) {
 		FilePath:    "/backups/testdb/2025-01-02-143022.sql.gz",
 		SizeBytes:   1024000,
 		Checksum:    "sha256:abc123",
+		DumpCommand: "mysqldump --host=localhost --password=*** testdb",
 		Duration:    2 * time.Minute,
 		Status:      StatusCompleted,
 		StartedAt:   time.Now().Add(-2 * time.Minute),
@@ -264,4 +308,31 @@ func TestMetadataGenerator(t *testing.T) {
 	assert.Equal(t, result.SizeBytes, metadata.Backup.SizeBytes)
 	assert.Equal(t, result.Checksum, metadata.Backup.Checksum)
 	assert.Equal(t, "mysqldump 8.0.35", metadata.Tool.MySQLDumpVersion)
+	assert.Equal(t, result.DumpCommand, metadata.Options.DumpCommand)
+}
+
+func TestMetadataGeneratorStartVersions(t *testing.T) {
+	// The connection died during the dump, so the server version comes
+	// from the start of the backup
+	generator := NewMetadataGenerator(mysql.NewMockClient())
+
+	options := DefaultOptions()
+	options.Database = "testdb"
+	result := &BackupResult{
+		BackupID:      "2025-01-02-143022",
+		FilePath:      "/backups/testdb/2025-01-02-143022.sql.gz",
+		Status:        StatusCompleted,
+		ServerVersion: "8.4.0",
+		Compatibility: "mysqldump is from MySQL 8.0, older than the server's MySQL 8.4",
+		Charset:       &mysql.Charset{Name: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+		SQLMode:       "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES",
+	}
+
+	metadata, err := generator.Generate(result.BackupID, mysql.NewConfig(), result, options, "mysqldump  Ver 8.0.36")
+	require.NoError(t, err)
+	assert.Equal(t, "8.4.0", metadata.Database.Version)
+	assert.Equal(t, result.Compatibility, metadata.Tool.Compatibility)
+	assert.Equal(t, "utf8mb4", metadata.Database.Charset)
+	assert.Equal(t, "utf8mb4_0900_ai_ci", metadata.Database.Collation)
+	assert.Equal(t, result.SQLMode, metadata.Database.SQLMode)
 }
@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// MydumperDumper executes mydumper to create multi-file database backups.
+type MydumperDumper struct {
+	config  *mysql.Config
+	timeout time.Duration
+}
+
+// NewMydumperDumper creates a new MydumperDumper.
+func NewMydumperDumper(config *mysql.Config) *MydumperDumper {
+	timeout := 30 * time.Minute // Default 30 minute timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout * 6 // Multiply by 6 for dump operations
+	}
+
+	return &MydumperDumper{
+		config:  config,
+		timeout: timeout,
+	}
+}
+
+// DumpToDirectory runs mydumper and writes its output files into outputDir.
+// If cmdLogger is provided, it will be called with the full command for debugging.
+func (d *MydumperDumper) DumpToDirectory(database, outputDir string, options *DumpOptions, threads int, compress bool, cmdLogger func(string)) error {
+	if options == nil {
+		options = DefaultDumpOptions()
+	}
+
+	args := d.buildArgs(database, outputDir, options, threads, compress)
+
+	if cmdLogger != nil {
+		cmdLogger(fmt.Sprintf("mydumper %s", strings.Join(maskPasswordArgs(args), " ")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "mydumper", args...)
+	cmd.Env = passwordEnv(d.config.Password)
+
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	if err := cmd.Run(); err != nil {
+		return WrapDumpError(database, "mydumper", stderrBuf.String(), getExitCode(err), err)
+	}
+
+	return nil
+}
+
+// buildArgs builds the mydumper command arguments.
+func (d *MydumperDumper) buildArgs(database, outputDir string, options *DumpOptions, threads int, compress bool) []string {
+	args := []string{
+		fmt.Sprintf("--host=%s", d.config.Host),
+		fmt.Sprintf("--port=%d", d.config.Port),
+		fmt.Sprintf("--user=%s", d.config.User),
+	}
+	args = append(args, mydumperSSLArgs(d.config.SSL)...)
+
+	// The password is passed in MYSQL_PWD, see passwordEnv
+
+	args = append(args,
+		fmt.Sprintf("--database=%s", database),
+		fmt.Sprintf("--outputdir=%s", outputDir),
+	)
+
+	if threads > 0 {
+		args = append(args, fmt.Sprintf("--threads=%d", threads))
+	}
+
+	if compress {
+		args = append(args, "--compress")
+	}
+
+	// Add routines, triggers, events if requested
+	if options.Routines {
+		args = append(args, "--routines")
+	}
+	if options.Triggers {
+		args = append(args, "--triggers")
+	}
+	if options.Events {
+		args = append(args, "--events")
+	}
+
+	// Schema-only or no-data
+	if options.SchemaOnly || options.NoData {
+		args = append(args, "--no-data")
+	}
+
+	// Specific tables
+	if len(options.Tables) > 0 {
+		qualified := make([]string, len(options.Tables))
+		for i, table := range options.Tables {
+			qualified[i] = database + "." + table
+		}
+		args = append(args, fmt.Sprintf("--tables-list=%s", strings.Join(qualified, ",")))
+	}
+
+	// Exclude tables (mydumper has no ignore flag, use a negative regex)
+	if len(options.ExcludeTables) > 0 {
+		excluded := make([]string, len(options.ExcludeTables))
+		for i, table := range options.ExcludeTables {
+			excluded[i] = regexp.QuoteMeta(database + "." + table)
+		}
+		args = append(args, fmt.Sprintf("--regex=^(?!(%s)$)", strings.Join(excluded, "|")))
+	}
+
+	return args
+}
+
+// MyloaderRestorer executes myloader to restore mydumper backups.
+type MyloaderRestorer struct {
+	config  *mysql.Config
+	timeout time.Duration
+}
+
+// NewMyloaderRestorer creates a new MyloaderRestorer.
+func NewMyloaderRestorer(config *mysql.Config) *MyloaderRestorer {
+	timeout := 30 * time.Minute // Default 30 minute timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout * 6 // Multiply by 6 for restore operations
+	}
+
+	return &MyloaderRestorer{
+		config:  config,
+		timeout: timeout,
+	}
+}
+
+// RestoreDirectory runs myloader on a mydumper output directory.
+// If cmdLogger is provided, it will be called with the full command for debugging.
+func (r *MyloaderRestorer) RestoreDirectory(database, inputDir string, threads int, cmdLogger func(string)) error {
+	if database == "" {
+		return WrapRestoreError("", "database name is required", fmt.Errorf("empty database name"))
+	}
+
+	args := r.buildArgs(database, inputDir, threads)
+
+	if cmdLogger != nil {
+		cmdLogger(fmt.Sprintf("myloader %s", strings.Join(maskPasswordArgs(args), " ")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "myloader", args...)
+	cmd.Env = passwordEnv(r.config.Password)
+
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	if err := cmd.Run(); err != nil {
+		exitCode := getRestoreExitCode(err)
+		return WrapRestoreError(database, fmt.Sprintf("myloader restore failed (exit code %d)", exitCode), fmt.Errorf("stderr: %s", stderrBuf.String()))
+	}
+
+	return nil
+}
+
+// buildArgs builds the myloader command arguments.
+func (r *MyloaderRestorer) buildArgs(database, inputDir string, threads int) []string {
+	args := []string{
+		fmt.Sprintf("--host=%s", r.config.Host),
+		fmt.Sprintf("--port=%d", r.config.Port),
+		fmt.Sprintf("--user=%s", r.config.User),
+	}
+	args = append(args, mydumperSSLArgs(r.config.SSL)...)
+
+	// The password is passed in MYSQL_PWD, see passwordEnv
+
+	args = append(args,
+		fmt.Sprintf("--directory=%s", inputDir),
+		fmt.Sprintf("--database=%s", database),
+		"--overwrite-tables",
+	)
+
+	if threads > 0 {
+		args = append(args, fmt.Sprintf("--threads=%d", threads))
+	}
+
+	return args
+}
+
+// CheckMydumper checks if mydumper is available and returns its version.
+func CheckMydumper() (string, error) {
+	cmd := exec.Command("mydumper", "--version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("mydumper not found or not executable: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckMyloader checks if myloader is available and returns its version.
+func CheckMyloader() (string, error) {
+	cmd := exec.Command("myloader", "--version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("myloader not found or not executable: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// maskPasswordArgs returns a copy of args with the password value masked.
+func maskPasswordArgs(args []string) []string {
+	logArgs := make([]string, len(args))
+	copy(logArgs, args)
+	for i, arg := range logArgs {
+		if strings.HasPrefix(arg, "--password=") {
+			logArgs[i] = "--password=***"
+		}
+	}
+	return logArgs
+}
@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMydumperBuildArgs(t *testing.T) {
+	config := &mysql.Config{
+		Host:     "localhost",
+		Port:     3306,
+		User:     "root",
+		Password: "secret",
+	}
+	dumper := NewMydumperDumper(config)
+
+	t.Run("defaults", func(t *testing.T) {
+		args := dumper.buildArgs("shop", "/tmp/out", DefaultDumpOptions(), 0, true)
+		assert.Contains(t, args, "--host=localhost")
+		for _, arg := range args {
+			assert.NotContains(t, arg, "secret")
+		}
+		assert.Contains(t, args, "--database=shop")
+		assert.Contains(t, args, "--outputdir=/tmp/out")
+		assert.Contains(t, args, "--compress")
+		assert.Contains(t, args, "--routines")
+		assert.Contains(t, args, "--triggers")
+		assert.Contains(t, args, "--events")
+		assert.NotContains(t, args, "--no-data")
+		for _, arg := range args {
+			assert.NotContains(t, arg, "--threads")
+		}
+	})
+
+	t.Run("tables schema-only and threads", func(t *testing.T) {
+		options := &DumpOptions{
+			Tables:     []string{"orders", "customers"},
+			SchemaOnly: true,
+		}
+		args := dumper.buildArgs("shop", "/tmp/out", options, 8, false)
+		assert.Contains(t, args, "--tables-list=shop.orders,shop.customers")
+		assert.Contains(t, args, "--no-data")
+		assert.Contains(t, args, "--threads=8")
+		assert.NotContains(t, args, "--compress")
+	})
+
+	t.Run("exclude tables", func(t *testing.T) {
+		options := &DumpOptions{
+			ExcludeTables: []string{"logs", "audit_trail"},
+		}
+		args := dumper.buildArgs("shop", "/tmp/out", options, 0, true)
+		assert.Contains(t, args, `--regex=^(?!(shop\.logs|shop\.audit_trail)$)`)
+	})
+}
+
+func TestMyloaderBuildArgs(t *testing.T) {
+	config := &mysql.Config{
+		Host: "db.example.com",
+		Port: 3307,
+		User: "admin",
+	}
+	restorer := NewMyloaderRestorer(config)
+
+	args := restorer.buildArgs("shop_copy", "/tmp/in", 4)
+	assert.Contains(t, args, "--host=db.example.com")
+	assert.Contains(t, args, "--port=3307")
+	assert.Contains(t, args, "--directory=/tmp/in")
+	assert.Contains(t, args, "--database=shop_copy")
+	assert.Contains(t, args, "--overwrite-tables")
+	assert.Contains(t, args, "--threads=4")
+	for _, arg := range args {
+		assert.NotContains(t, arg, "--password")
+	}
+}
+
+func TestMaskPasswordArgs(t *testing.T) {
+	args := []string{"--user=root", "--password=secret"}
+	masked := maskPasswordArgs(args)
+	assert.Equal(t, []string{"--user=root", "--password=***"}, masked)
+	assert.Equal(t, "--password=secret", args[1])
+}
+
+func TestArchiveDirectoryRoundTrip(t *testing.T) {
+	srcDir := t.TempDir()
+	files := map[string]string{
+		"metadata":                  "Started dump at: 2025-01-02 14:30:22\n",
+		"shop.orders-schema.sql.gz": "schema",
+		"shop.orders.00000.sql.gz":  "data",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(srcDir, "subdir"), 0755))
+
+	archivePath := filepath.Join(t.TempDir(), "backup.tar")
+	result, err := ArchiveDirectory(srcDir, archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("Started dump at: 2025-01-02 14:30:22\n")+len("schema")+len("data")), result.BytesRead)
+
+	info, err := os.Stat(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), result.BytesWritten)
+
+	valid, err := VerifyChecksum(archivePath, result.Checksum)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	destDir := t.TempDir()
+	require.NoError(t, ExtractArchive(archivePath, destDir))
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(destDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+}
+
+func TestValidateOptionsEngine(t *testing.T) {
+	service := &Service{}
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.Engine = EngineMydumper
+	assert.NoError(t, service.validateOptions(options))
+
+	options.Engine = "xtrabackup"
+	assert.Error(t, service.validateOptions(options))
+
+	options.Engine = EngineMydumper
+	options.Threads = -1
+	assert.Error(t, service.validateOptions(options))
+}
@@ -0,0 +1,522 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// MySQLDumper executes mysqldump to create database backups.
+type MySQLDumper struct {
+	config       *mysql.Config
+	timeout      time.Duration
+	stderrLogger func(string)
+}
+
+// NewMySQLDumper creates a new MySQLDumper.
+func NewMySQLDumper(config *mysql.Config) *MySQLDumper {
+	timeout := 30 * time.Minute // Default 30 minute timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout * 6 // Multiply by 6 for dump operations
+	}
+
+	return &MySQLDumper{
+		config:  config,
+		timeout: timeout,
+	}
+}
+
+// SetStderrLogger implements StderrLogger.
+func (d *MySQLDumper) SetStderrLogger(logger func(string)) {
+	d.stderrLogger = logger
+}
+
+// DumpOptions configures mysqldump execution.
+type DumpOptions struct {
+	Tables        []string
+	ExcludeTables []string
+	SchemaOnly    bool
+	NoData        bool
+	Routines      bool
+	Triggers      bool
+	Events        bool
+
+	// ExtraArgs are additional mysqldump flags, validated by ValidateDumpArgs
+	ExtraArgs []string
+
+	// DisableDefaults names default flags to leave out, e.g. "set-gtid-purged"
+	DisableDefaults []string
+
+	// WarningPolicy decides which stderr messages fail the dump (default: DefaultWarningPolicy)
+	WarningPolicy *WarningPolicy
+
+	// CharacterSet is passed as --default-character-set unless empty or
+	// disabled; an extra --default-character-set argument overrides it
+	CharacterSet string
+
+	// Databases dumps these databases with --databases, including their
+	// CREATE DATABASE and USE statements, instead of the database argument
+	Databases []string
+}
+
+// defaultDumpFlags are passed to every mysqldump run unless disabled.
+var defaultDumpFlags = []struct {
+	name string
+	arg  string
+}{
+	{"single-transaction", "--single-transaction"}, // Consistent snapshot without locking tables
+	{"quick", "--quick"},                           // Don't buffer entire result in memory
+	{"skip-lock-tables", "--skip-lock-tables"},     // Don't lock tables (use single-transaction instead)
+	{"no-tablespaces", "--no-tablespaces"},         // Avoid tablespace issues
+	{"set-gtid-purged", "--set-gtid-purged=OFF"},   // Don't include GTID info (causes issues with some setups)
+}
+
+// allowedDumpArgs are the extra mysqldump flags that may be configured.
+// Flags that change where output goes, how to connect or authenticate, or
+// that would break the streamed single-file dump are deliberately missing.
+var allowedDumpArgs = map[string]bool{
+	"add-drop-database":        true,
+	"add-drop-table":           true,
+	"skip-add-drop-table":      true,
+	"add-locks":                true,
+	"skip-add-locks":           true,
+	"allow-keywords":           true,
+	"apply-replica-statements": true,
+	"apply-slave-statements":   true,
+	"column-statistics":        true,
+	"skip-column-statistics":   true,
+	"comments":                 true,
+	"skip-comments":            true,
+	"compact":                  true,
+	"complete-insert":          true,
+	"compress":                 true,
+	"compression-algorithms":   true,
+	"create-options":           true,
+	"skip-create-options":      true,
+	"default-character-set":    true,
+	"disable-keys":             true,
+	"skip-disable-keys":        true,
+	"dump-date":                true,
+	"skip-dump-date":           true,
+	"extended-insert":          true,
+	"skip-extended-insert":     true,
+	"hex-blob":                 true,
+	"include-source-host-port": true,
+	"insert-ignore":            true,
+	"master-data":              true,
+	"max-allowed-packet":       true,
+	"net-buffer-length":        true,
+	"no-create-db":             true,
+	"no-create-info":           true,
+	"order-by-primary":         true,
+	"replace":                  true,
+	"set-charset":              true,
+	"skip-set-charset":         true,
+	"set-gtid-purged":          true,
+	"source-data":              true,
+	"ssl-mode":                 true,
+	"ssl-ca":                   true,
+	"ssl-cert":                 true,
+	"ssl-key":                  true,
+	"tz-utc":                   true,
+	"skip-tz-utc":              true,
+}
+
+// dumpArgName returns the normalized name of a long flag such as
+// "--max_allowed_packet=64M" ("max-allowed-packet").
+func dumpArgName(arg string) string {
+	name := strings.TrimPrefix(arg, "--")
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// ValidateDumpArgs checks extra mysqldump flags against the allowlist and
+// that every disabled default is known.
+func ValidateDumpArgs(extraArgs, disableDefaults []string) error {
+	for _, arg := range extraArgs {
+		if !strings.HasPrefix(arg, "--") {
+			return &ValidationError{
+				Field:   "DumpArgs",
+				Message: fmt.Sprintf("invalid mysqldump argument %q: only long --flags are supported", arg),
+			}
+		}
+		if !allowedDumpArgs[dumpArgName(arg)] {
+			return &ValidationError{
+				Field:   "DumpArgs",
+				Message: fmt.Sprintf("mysqldump argument %q is not allowed", arg),
+			}
+		}
+	}
+
+	for _, name := range disableDefaults {
+		known := false
+		for _, flag := range defaultDumpFlags {
+			if flag.name == dumpArgName(name) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return &ValidationError{
+				Field:   "DisableDefaults",
+				Message: fmt.Sprintf("unknown default mysqldump flag %q", name),
+			}
+		}
+	}
+
+	return nil
+}
+
+// DefaultDumpOptions returns optimal default options for mysqldump.
+func DefaultDumpOptions() *DumpOptions {
+	return &DumpOptions{
+		SchemaOnly: false,
+		NoData:     false,
+		Routines:   true,
+		Triggers:   true,
+		Events:     true,
+	}
+}
+
+// DumpResult contains the result of a dump operation.
+type DumpResult struct {
+	BytesWritten int64
+	Duration     time.Duration
+	ExitCode     int
+	Stderr       string
+}
+
+// Dump executes mysqldump and returns a reader for the output.
+// The caller is responsible for closing the returned reader.
+func (d *MySQLDumper) Dump(database string, options *DumpOptions) (io.ReadCloser, error) {
+	return d.DumpWithCommand(database, options, nil)
+}
+
+// DumpWithCommand executes mysqldump and returns a reader for the output.
+// If cmdLogger is provided, it will be called with the full command for debugging.
+func (d *MySQLDumper) DumpWithCommand(database string, options *DumpOptions, cmdLogger func(string)) (io.ReadCloser, error) {
+	if options == nil {
+		options = DefaultDumpOptions()
+	}
+
+	// Build mysqldump command, keeping the password out of argv
+	optionFile, cleanup, err := clientOptionFile(d.config.Password)
+	if err != nil {
+		return nil, WrapDumpError(database, "mysqldump", "failed to prepare credentials", 0, err)
+	}
+	args := withOptionFile(optionFile, d.buildArgs(database, options))
+
+	// Log command if logger provided (for debugging)
+	if cmdLogger != nil {
+		cmdLogger(fmt.Sprintf("%s %s", d.config.MysqldumpCommand(), strings.Join(maskPasswordArgs(args), " ")))
+	}
+
+	// Create command with context for timeout
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+
+	cmd := exec.CommandContext(ctx, d.config.MysqldumpCommand(), args...)
+
+	// Capture stderr to detect warnings/errors
+	var stderrBuf bytes.Buffer
+	stderr, flushStderr := stderrWriter(&stderrBuf, d.stderrLogger, d.config.Password)
+	cmd.Stderr = stderr
+
+	// Get stdout pipe
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		cleanup()
+		return nil, WrapDumpError(database, "mysqldump", "failed to create stdout pipe", 0, err)
+	}
+
+	// Start command
+	if err := cmd.Start(); err != nil {
+		cancel()
+		cleanup()
+		return nil, WrapDumpError(database, "mysqldump", "failed to start mysqldump", 0, err)
+	}
+
+	// Return a reader that will handle cleanup
+	return &dumpReader{
+		reader:      stdout,
+		cmd:         cmd,
+		cancel:      cancel,
+		cleanup:     cleanup,
+		database:    database,
+		stderr:      &stderrBuf,
+		flushStderr: flushStderr,
+		policy:      options.WarningPolicy,
+	}, nil
+}
+
+// DumpToWriter executes mysqldump and writes output directly to a writer.
+func (d *MySQLDumper) DumpToWriter(database string, writer io.Writer, options *DumpOptions) (*DumpResult, error) {
+	if options == nil {
+		options = DefaultDumpOptions()
+	}
+
+	startTime := time.Now()
+
+	// Build mysqldump command, keeping the password out of argv
+	optionFile, cleanup, err := clientOptionFile(d.config.Password)
+	if err != nil {
+		return nil, WrapDumpError(database, "mysqldump", "failed to prepare credentials", 0, err)
+	}
+	defer cleanup()
+	args := withOptionFile(optionFile, d.buildArgs(database, options))
+
+	// Create command with context for timeout
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, d.config.MysqldumpCommand(), args...)
+
+	// Capture stderr
+	var stderrBuf bytes.Buffer
+	stderrOut, flushStderr := stderrWriter(&stderrBuf, d.stderrLogger, d.config.Password)
+	defer flushStderr()
+	cmd.Stderr = stderrOut
+
+	// Get stdout pipe
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, WrapDumpError(database, "mysqldump", "failed to create stdout pipe", 0, err)
+	}
+
+	// Start command
+	if err := cmd.Start(); err != nil {
+		return nil, WrapDumpError(database, "mysqldump", "failed to start mysqldump", 0, err)
+	}
+
+	// Copy output to writer
+	bytesWritten, err := io.Copy(writer, stdout)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, WrapDumpError(database, "mysqldump", "failed to copy output", 0, err)
+	}
+
+	// Wait for command to finish
+	if err := cmd.Wait(); err != nil {
+		stderr := stderrBuf.String()
+		exitCode := getExitCode(err)
+		return nil, WrapDumpError(database, strings.Join(maskPasswordArgs(args), " "), stderr, exitCode, err)
+	}
+
+	duration := time.Since(startTime)
+
+	return &DumpResult{
+		BytesWritten: bytesWritten,
+		Duration:     duration,
+		ExitCode:     0,
+		Stderr:       stderrBuf.String(),
+	}, nil
+}
+
+// buildArgs builds the mysqldump command arguments.
+func (d *MySQLDumper) buildArgs(database string, options *DumpOptions) []string {
+	args := []string{
+		fmt.Sprintf("--host=%s", d.config.Host),
+		fmt.Sprintf("--port=%d", d.config.Port),
+		fmt.Sprintf("--user=%s", d.config.User),
+	}
+	args = append(args, sslArgs(d.config.SSL)...)
+
+	// The password is passed in an option file, see clientOptionFile
+
+	// Optimal flags for consistency and performance, unless disabled or
+	// overridden by an extra argument with the same name
+	skip := make(map[string]bool)
+	for _, name := range options.DisableDefaults {
+		skip[dumpArgName(name)] = true
+	}
+	for _, arg := range options.ExtraArgs {
+		skip[dumpArgName(arg)] = true
+	}
+	for _, flag := range defaultDumpFlags {
+		if !skip[flag.name] {
+			args = append(args, flag.arg)
+		}
+	}
+	if options.CharacterSet != "" && !skip["default-character-set"] {
+		args = append(args, "--default-character-set="+options.CharacterSet)
+	}
+	args = append(args, options.ExtraArgs...)
+
+	// Add routines, triggers, events if requested
+	if options.Routines {
+		args = append(args, "--routines")
+	}
+	if options.Triggers {
+		args = append(args, "--triggers")
+	}
+	if options.Events {
+		args = append(args, "--events")
+	}
+
+	// Schema-only or no-data
+	if options.SchemaOnly || options.NoData {
+		args = append(args, "--no-data")
+	}
+
+	// Several databases are dumped whole
+	if len(options.Databases) > 0 {
+		return append(append(args, "--databases"), options.Databases...)
+	}
+
+	// Add database name
+	args = append(args, database)
+
+	// Specific tables
+	if len(options.Tables) > 0 {
+		args = append(args, options.Tables...)
+	}
+
+	// Exclude tables
+	for _, table := range options.ExcludeTables {
+		args = append(args, fmt.Sprintf("--ignore-table=%s.%s", database, table))
+	}
+
+	return args
+}
+
+// CheckMySQLDump checks if the mysqldump of config is available and returns
+// its version. A nil config checks mysqldump in PATH.
+func CheckMySQLDump(config *mysql.Config) (string, error) {
+	cmd := exec.Command(config.MysqldumpCommand(), "--version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s not found or not executable: %w", config.MysqldumpCommand(), err)
+	}
+
+	version := strings.TrimSpace(string(output))
+	return version, nil
+}
+
+// dumpReader wraps the stdout pipe and handles command cleanup.
+type dumpReader struct {
+	reader   io.ReadCloser
+	cmd      *exec.Cmd
+	cancel   context.CancelFunc
+	cleanup  func()
+	database string
+	stderr   *bytes.Buffer
+	closed   bool
+
+	// flushStderr logs any unterminated last stderr line
+	flushStderr func()
+
+	// policy classifies stderr output; warnings holds the recorded lines
+	policy   *WarningPolicy
+	warnings []string
+}
+
+// Read implements io.Reader.
+func (r *dumpReader) Read(p []byte) (n int, err error) {
+	return r.reader.Read(p)
+}
+
+// Close implements io.Closer.
+func (r *dumpReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.cleanup != nil {
+		defer r.cleanup()
+	}
+
+	// Close the reader
+	if err := r.reader.Close(); err != nil {
+		r.cancel()
+		return err
+	}
+
+	// Wait for command to finish
+	err := r.cmd.Wait()
+	if r.flushStderr != nil {
+		r.flushStderr()
+	}
+	stderr := ""
+	if r.stderr != nil {
+		stderr = r.stderr.String()
+	}
+	r.cancel()
+
+	if err != nil {
+		exitCode := getExitCode(err)
+		return WrapDumpError(r.database, "mysqldump", stderr, exitCode, err)
+	}
+
+	// Check for warnings in stderr even if exit code is 0
+	// mysqldump may succeed but only dump schema if there are permission issues
+	if stderr != "" {
+		warnings, err := r.policy.Classify(stderr)
+		r.warnings = warnings
+		if err != nil {
+			// Return error with stderr to surface the warning
+			return WrapDumpError(r.database, "mysqldump", stderr, 0, err)
+		}
+	}
+
+	return nil
+}
+
+// Cancel implements Canceler, killing mysqldump.
+func (r *dumpReader) Cancel() {
+	r.cancel()
+}
+
+// Warnings returns the stderr lines recorded when the dump was closed.
+func (r *dumpReader) Warnings() []string {
+	return r.warnings
+}
+
+// getExitCode extracts exit code from command error.
+func getExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// ExecuteMySQLDump executes mysqldump and streams output to writer with compression.
+// This is the main high-level function used by the backup service.
+func ExecuteMySQLDump(config *mysql.Config, database string, writer io.Writer, options *DumpOptions) (*DumpResult, error) {
+	dumper := NewMySQLDumper(config)
+	return dumper.DumpToWriter(database, writer, options)
+}
+
+// StreamMySQLDump executes mysqldump and returns a reader for streaming.
+// Useful when you need more control over the output processing.
+func StreamMySQLDump(config *mysql.Config, database string, options *DumpOptions) (io.ReadCloser, error) {
+	dumper := NewMySQLDumper(config)
+	return dumper.Dump(database, options)
+}
+
+// ValidateMySQLDumpOptions validates dump options.
+func ValidateMySQLDumpOptions(options *DumpOptions) error {
+	if options == nil {
+		return fmt.Errorf("dump options cannot be nil")
+	}
+
+	// Check for conflicting options
+	if options.SchemaOnly && len(options.Tables) > 0 {
+		// This is allowed but warn in logs
+	}
+
+	return nil
+}
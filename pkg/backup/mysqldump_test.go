@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLDumperBuildArgs(t *testing.T) {
+	dumper := NewMySQLDumper(&mysql.Config{Host: "localhost", Port: 3306, User: "root"})
+
+	t.Run("defaults", func(t *testing.T) {
+		args := dumper.buildArgs("shop", DefaultDumpOptions())
+		assert.Contains(t, args, "--single-transaction")
+		assert.Contains(t, args, "--no-tablespaces")
+		assert.Contains(t, args, "--set-gtid-purged=OFF")
+		assert.Equal(t, "shop", args[len(args)-1])
+	})
+
+	t.Run("disabled defaults", func(t *testing.T) {
+		options := DefaultDumpOptions()
+		options.DisableDefaults = []string{"set-gtid-purged", "--no-tablespaces"}
+
+		args := dumper.buildArgs("shop", options)
+		assert.NotContains(t, args, "--set-gtid-purged=OFF")
+		assert.NotContains(t, args, "--no-tablespaces")
+		assert.Contains(t, args, "--single-transaction")
+	})
+
+	t.Run("extra args override defaults", func(t *testing.T) {
+		options := DefaultDumpOptions()
+		options.ExtraArgs = []string{"--set-gtid-purged=AUTO", "--hex-blob"}
+
+		args := dumper.buildArgs("shop", options)
+		assert.NotContains(t, args, "--set-gtid-purged=OFF")
+		assert.Contains(t, args, "--set-gtid-purged=AUTO")
+		assert.Contains(t, args, "--hex-blob")
+	})
+
+	t.Run("character set", func(t *testing.T) {
+		options := DefaultDumpOptions()
+		options.CharacterSet = "utf8mb4"
+		assert.Contains(t, dumper.buildArgs("shop", options), "--default-character-set=utf8mb4")
+
+		options.ExtraArgs = []string{"--default-character-set=latin1"}
+		args := dumper.buildArgs("shop", options)
+		assert.NotContains(t, args, "--default-character-set=utf8mb4")
+		assert.Contains(t, args, "--default-character-set=latin1")
+	})
+
+	t.Run("databases", func(t *testing.T) {
+		options := DefaultDumpOptions()
+		options.Databases = []string{"shop", "stats"}
+		args := dumper.buildArgs("shop+stats", options)
+		assert.Equal(t, []string{"--databases", "shop", "stats"}, args[len(args)-3:])
+		assert.NotContains(t, args, "shop+stats")
+	})
+}
+
+func TestValidateDumpArgs(t *testing.T) {
+	assert.NoError(t, ValidateDumpArgs(nil, nil))
+	assert.NoError(t, ValidateDumpArgs([]string{"--hex-blob", "--max_allowed_packet=64M"}, []string{"set-gtid-purged"}))
+
+	for _, arg := range []string{"--result-file=/tmp/x", "--password=x", "--tab=/tmp", "-r", "hex-blob"} {
+		err := ValidateDumpArgs([]string{arg}, nil)
+		assert.True(t, IsValidationError(err), arg)
+	}
+
+	err := ValidateDumpArgs(nil, []string{"lock-all-tables"})
+	assert.True(t, IsValidationError(err))
+}
@@ -0,0 +1,217 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// Fast mode session settings. The dump is wrapped in a single transaction
+// with foreign key and unique checks off, and the client allows large
+// packets and slow network reads so big extended inserts do not fail.
+const (
+	fastModePrologue = "SET FOREIGN_KEY_CHECKS=0;\nSET UNIQUE_CHECKS=0;\nSET autocommit=0;\n"
+	fastModeEpilogue = "\nCOMMIT;\nSET UNIQUE_CHECKS=1;\nSET FOREIGN_KEY_CHECKS=1;\n"
+
+	fastModeMaxAllowedPacket = "1G"
+	fastModeNetTimeout       = 3600 // seconds
+)
+
+// MySQLRestorer executes mysql command to restore database backups.
+type MySQLRestorer struct {
+	config       *mysql.Config
+	timeout      time.Duration
+	stderrLogger func(string)
+	fastMode     bool
+	charset      string
+	sqlMode      string
+}
+
+// NewMySQLRestorer creates a new MySQLRestorer.
+func NewMySQLRestorer(config *mysql.Config) *MySQLRestorer {
+	timeout := 30 * time.Minute // Default 30 minute timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout * 6 // Multiply by 6 for restore operations
+	}
+
+	return &MySQLRestorer{
+		config:   config,
+		timeout:  timeout,
+		fastMode: true,
+	}
+}
+
+// SetFastMode implements FastModeSetter. Fast mode is enabled by default.
+func (r *MySQLRestorer) SetFastMode(enabled bool) {
+	r.fastMode = enabled
+}
+
+// SetCharacterSet implements CharsetSetter. By default the mysql client
+// chooses.
+func (r *MySQLRestorer) SetCharacterSet(charset string) {
+	r.charset = charset
+}
+
+// SetSQLMode implements SQLModeSetter. By default the server's sql_mode
+// is kept.
+func (r *MySQLRestorer) SetSQLMode(mode string) {
+	r.sqlMode = mode
+}
+
+// SetStderrLogger implements StderrLogger.
+func (r *MySQLRestorer) SetStderrLogger(logger func(string)) {
+	r.stderrLogger = logger
+}
+
+// Restore executes mysql command with SQL input from reader.
+func (r *MySQLRestorer) Restore(database string, sqlReader io.Reader) error {
+	return r.RestoreWithCommand(database, sqlReader, nil)
+}
+
+// RestoreWithCommand executes mysql command with SQL input from reader.
+// If cmdLogger is provided, it will be called with the full command for debugging.
+func (r *MySQLRestorer) RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error {
+	if database == "" {
+		return WrapRestoreError("", "database name is required", fmt.Errorf("empty database name"))
+	}
+
+	// Build mysql command arguments, keeping the password out of argv
+	optionFile, cleanup, err := clientOptionFile(r.config.Password)
+	if err != nil {
+		return WrapRestoreError(database, "failed to prepare credentials", err)
+	}
+	defer cleanup()
+	args := withOptionFile(optionFile, r.buildArgs(database))
+
+	// Log command if logger provided (for debugging)
+	if cmdLogger != nil {
+		cmdLogger(fmt.Sprintf("%s %s", r.config.MysqlCommand(), strings.Join(maskPasswordArgs(args), " ")))
+	}
+
+	// Create command with context for timeout
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, r.config.MysqlCommand(), args...)
+
+	// Set stdin to read from sqlReader
+	cmd.Stdin = r.wrapInput(sqlReader)
+
+	// Capture stderr to detect errors
+	var stderrBuf bytes.Buffer
+	stderrOut, flushStderr := stderrWriter(&stderrBuf, r.stderrLogger, r.config.Password)
+	cmd.Stderr = stderrOut
+
+	// Execute command
+	err = cmd.Run()
+	flushStderr()
+	if err != nil {
+		stderr := stderrBuf.String()
+		exitCode := getRestoreExitCode(err)
+		return WrapRestoreError(database, fmt.Sprintf("mysql restore failed (exit code %d)", exitCode), fmt.Errorf("stderr: %s", stderr))
+	}
+
+	// Check for warnings/errors in stderr even if exit code is 0
+	stderr := stderrBuf.String()
+	if stderr != "" {
+		// Check for common error patterns
+		errorPatterns := []string{
+			"error",
+			"failed",
+			"cannot",
+			"denied",
+			"access denied",
+			"unknown database",
+		}
+
+		lowerStderr := strings.ToLower(stderr)
+		for _, pattern := range errorPatterns {
+			if strings.Contains(lowerStderr, pattern) {
+				return WrapRestoreError(database, "mysql restore completed but reported errors", fmt.Errorf("stderr: %s", stderr))
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildArgs builds the mysql command arguments.
+func (r *MySQLRestorer) buildArgs(database string) []string {
+	args := []string{
+		fmt.Sprintf("--host=%s", r.config.Host),
+		fmt.Sprintf("--port=%d", r.config.Port),
+		fmt.Sprintf("--user=%s", r.config.User),
+	}
+	args = append(args, sslArgs(r.config.SSL)...)
+
+	// The password is passed in an option file, see clientOptionFile
+
+	if r.charset != "" {
+		args = append(args, "--default-character-set="+r.charset)
+	}
+
+	// Session settings run on connect, in one init command
+	var session []string
+	if r.fastMode {
+		args = append(args, "--max-allowed-packet="+fastModeMaxAllowedPacket)
+		session = append(session,
+			fmt.Sprintf("SESSION net_read_timeout=%d", fastModeNetTimeout),
+			fmt.Sprintf("SESSION net_write_timeout=%d", fastModeNetTimeout),
+		)
+	}
+	if r.sqlMode != "" {
+		session = append(session, fmt.Sprintf("SESSION sql_mode='%s'", r.sqlMode))
+	}
+	if len(session) > 0 {
+		args = append(args, "--init-command=SET "+strings.Join(session, ", "))
+	}
+
+	// Add database name
+	args = append(args, database)
+
+	return args
+}
+
+// wrapInput surrounds the SQL with the fast mode session settings.
+func (r *MySQLRestorer) wrapInput(sqlReader io.Reader) io.Reader {
+	if !r.fastMode {
+		return sqlReader
+	}
+	return io.MultiReader(
+		strings.NewReader(fastModePrologue),
+		sqlReader,
+		strings.NewReader(fastModeEpilogue),
+	)
+}
+
+// CheckMySQL checks if the mysql client of config is available and returns
+// its version. A nil config checks mysql in PATH.
+func CheckMySQL(config *mysql.Config) (string, error) {
+	cmd := exec.Command(config.MysqlCommand(), "--version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s not found or not executable: %w", config.MysqlCommand(), err)
+	}
+
+	version := strings.TrimSpace(string(output))
+	return version, nil
+}
+
+// getRestoreExitCode extracts exit code from command error.
+func getRestoreExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMySQLRestorer(t *testing.T) {
@@ -57,7 +58,7 @@ func TestMySQLRestorerBuildArgs(t *testing.T) {
 		assert.Contains(t, args, "--host=localhost")
 		assert.Contains(t, args, "--port=3306")
 		assert.Contains(t, args, "--user=root")
-		assert.Contains(t, args, "--password=secret")
+		assert.NotContains(t, strings.Join(args, " "), "secret", "password must not be passed in argv")
 		assert.Contains(t, args, "testdb")
 	})
 
@@ -94,9 +95,57 @@ func TestMySQLRestorerBuildArgs(t *testing.T) {
 		assert.Contains(t, args, "--host=remote.example.com")
 		assert.Contains(t, args, "--port=3307")
 		assert.Contains(t, args, "--user=backup_user")
-		assert.Contains(t, args, "--password=mypassword")
+		assert.NotContains(t, strings.Join(args, " "), "mypassword")
 		assert.Contains(t, args, "mydb")
 	})
+
+	t.Run("character set", func(t *testing.T) {
+		restorer := NewMySQLRestorer(&mysql.Config{Host: "localhost", Port: 3306, User: "root"})
+		assert.NotContains(t, strings.Join(restorer.buildArgs("mydb"), " "), "--default-character-set")
+
+		restorer.SetCharacterSet("utf8mb4")
+		assert.Contains(t, restorer.buildArgs("mydb"), "--default-character-set=utf8mb4")
+	})
+
+	t.Run("sql mode", func(t *testing.T) {
+		restorer := NewMySQLRestorer(&mysql.Config{Host: "localhost", Port: 3306, User: "root"})
+		restorer.SetSQLMode("NO_ENGINE_SUBSTITUTION")
+		assert.Contains(t, restorer.buildArgs("mydb"),
+			"--init-command=SET SESSION net_read_timeout=3600, SESSION net_write_timeout=3600, SESSION sql_mode='NO_ENGINE_SUBSTITUTION'")
+
+		restorer.SetFastMode(false)
+		assert.Contains(t, restorer.buildArgs("mydb"), "--init-command=SET SESSION sql_mode='NO_ENGINE_SUBSTITUTION'")
+	})
+}
+
+func TestMySQLRestorerFastMode(t *testing.T) {
+	config := &mysql.Config{Host: "localhost", Port: 3306, User: "root"}
+
+	t.Run("enabled by default", func(t *testing.T) {
+		script := installFakeClient(t, "mysql")
+		restorer := NewMySQLRestorer(config)
+
+		require.NoError(t, restorer.Restore("shop", strings.NewReader("INSERT INTO t VALUES (1);")))
+
+		args := readRecorded(t, script+".args")
+		assert.Contains(t, args, "--max-allowed-packet=1G")
+		assert.Contains(t, args, "--init-command=SET SESSION net_read_timeout=3600")
+
+		stdin := readRecorded(t, script+".stdin")
+		assert.True(t, strings.HasPrefix(stdin, "SET FOREIGN_KEY_CHECKS=0;\nSET UNIQUE_CHECKS=0;\nSET autocommit=0;\n"))
+		assert.Contains(t, stdin, "INSERT INTO t VALUES (1);\nCOMMIT;\n")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		script := installFakeClient(t, "mysql")
+		restorer := NewMySQLRestorer(config)
+		restorer.SetFastMode(false)
+
+		require.NoError(t, restorer.Restore("shop", strings.NewReader("INSERT INTO t VALUES (1);")))
+
+		assert.NotContains(t, readRecorded(t, script+".args"), "--max-allowed-packet")
+		assert.Equal(t, "INSERT INTO t VALUES (1);", readRecorded(t, script+".stdin"))
+	})
 }
 
 func TestMySQLRestorerRestore(t *testing.T) {
@@ -141,9 +190,9 @@ func TestMySQLRestorerRestore(t *testing.T) {
 			assert.Contains(t, loggedCommand, "--host=localhost")
 			assert.Contains(t, loggedCommand, "--port=3306")
 			assert.Contains(t, loggedCommand, "--user=root")
-			// Password should be masked
-			assert.Contains(t, loggedCommand, "--password=***")
-			assert.NotContains(t, loggedCommand, "--password=secret")
+			// Password is passed in an option file
+			assert.Contains(t, loggedCommand, "--defaults-extra-file=")
+			assert.NotContains(t, loggedCommand, "secret")
 			assert.Contains(t, loggedCommand, "testdb")
 		}
 
@@ -155,7 +204,7 @@ func TestMySQLRestorerRestore(t *testing.T) {
 }
 
 func TestMySQLRestorerRestoreWithCommand(t *testing.T) {
-	t.Run("password masking in logs", func(t *testing.T) {
+	t.Run("password kept out of logs", func(t *testing.T) {
 		config := &mysql.Config{
 			Host:     "localhost",
 			Port:     3306,
@@ -173,8 +222,6 @@ func TestMySQLRestorerRestoreWithCommand(t *testing.T) {
 		_ = restorer.RestoreWithCommand("testdb", sqlData, cmdLogger)
 
 		if loggedCommand != "" {
-			// Verify password is masked
-			assert.Contains(t, loggedCommand, "--password=***")
 			assert.NotContains(t, loggedCommand, "super_secret_password_123")
 		}
 	})
@@ -196,7 +243,7 @@ func TestMySQLRestorerRestoreWithCommand(t *testing.T) {
 func TestCheckMySQL(t *testing.T) {
 	// This test requires mysql to be available
 	// If not available, it will fail but that's expected
-	version, err := CheckMySQL()
+	version, err := CheckMySQL(nil)
 
 	if err != nil {
 		// mysql not found - this is acceptable in test environments
@@ -0,0 +1,166 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// nativeBatchSize is how many statements fast mode commits at once, so a
+// large dump does not build one huge transaction on the server.
+const nativeBatchSize = 1000
+
+// Session settings around a native restore. Foreign key checks are always
+// off, as the statements of a dump are not ordered by foreign keys; fast
+// mode also turns off unique checks and autocommit.
+var (
+	nativePrologue     = []string{"SET FOREIGN_KEY_CHECKS=0"}
+	nativeFastPrologue = []string{"SET FOREIGN_KEY_CHECKS=0", "SET UNIQUE_CHECKS=0", "SET autocommit=0"}
+	nativeEpilogue     = []string{"SET FOREIGN_KEY_CHECKS=1"}
+	nativeFastEpilogue = []string{"COMMIT", "SET UNIQUE_CHECKS=1", "SET FOREIGN_KEY_CHECKS=1"}
+)
+
+// NativeRestorer restores SQL dumps through the MySQL client library,
+// without the mysql command line client. The dump is split into statements
+// and executed on a single connection with foreign key checks disabled.
+type NativeRestorer struct {
+	config    *mysql.Config
+	timeout   time.Duration
+	fastMode  bool
+	sqlMode   string
+	batchSize int
+
+	// newClient creates the client used to restore; replaced in tests
+	newClient func(config *mysql.Config) (mysql.DatabaseClient, error)
+}
+
+// NewNativeRestorer creates a new NativeRestorer.
+func NewNativeRestorer(config *mysql.Config) *NativeRestorer {
+	timeout := 30 * time.Minute // Default 30 minute timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout * 6 // Multiply by 6 for restore operations
+	}
+
+	return &NativeRestorer{
+		config:    config,
+		timeout:   timeout,
+		fastMode:  true,
+		batchSize: nativeBatchSize,
+		newClient: func(config *mysql.Config) (mysql.DatabaseClient, error) {
+			return mysql.NewClient(config)
+		},
+	}
+}
+
+// SetFastMode implements FastModeSetter. Fast mode is enabled by default.
+func (r *NativeRestorer) SetFastMode(enabled bool) {
+	r.fastMode = enabled
+}
+
+// SetSQLMode implements SQLModeSetter. By default the server's sql_mode
+// is kept.
+func (r *NativeRestorer) SetSQLMode(mode string) {
+	r.sqlMode = mode
+}
+
+// Restore executes the SQL from reader against the database.
+func (r *NativeRestorer) Restore(database string, sqlReader io.Reader) error {
+	return r.RestoreWithCommand(database, sqlReader, nil)
+}
+
+// RestoreWithCommand executes the SQL from reader against the database.
+// If cmdLogger is provided, it is called with a description of the restore.
+func (r *NativeRestorer) RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error {
+	if database == "" {
+		return WrapRestoreError("", "database name is required", fmt.Errorf("empty database name"))
+	}
+
+	config := *r.config
+	config.Database = database
+	if cmdLogger != nil {
+		cmdLogger(fmt.Sprintf("native restore into %s@%s:%d/%s (fast mode: %t)",
+			config.User, config.Host, config.Port, database, r.fastMode))
+	}
+
+	client, err := r.newClient(&config)
+	if err != nil {
+		return WrapRestoreError(database, "failed to create client", err)
+	}
+	if err := client.Connect(); err != nil {
+		return WrapRestoreError(database, "failed to connect", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if _, err := client.ExecuteMulti(ctx, r.statements(sqlReader)); err != nil {
+		return WrapRestoreError(database, "native restore failed", err)
+	}
+	return nil
+}
+
+// statements returns the statements of the dump wrapped in the session
+// settings of the restore, with a COMMIT after every batch in fast mode.
+func (r *NativeRestorer) statements(sqlReader io.Reader) *nativeStatements {
+	stmts := &nativeStatements{
+		scanner:  mysql.NewStatementScanner(sqlReader),
+		pending:  nativePrologue,
+		epilogue: nativeEpilogue,
+	}
+	if r.fastMode {
+		stmts.pending, stmts.epilogue = nativeFastPrologue, nativeFastEpilogue
+		stmts.batchSize = r.batchSize
+	}
+	if r.sqlMode != "" {
+		stmts.pending = append([]string{fmt.Sprintf("SET SESSION sql_mode='%s'", r.sqlMode)}, stmts.pending...)
+	}
+	return stmts
+}
+
+// nativeStatements is the mysql.StatementReader of a native restore.
+type nativeStatements struct {
+	scanner   *mysql.StatementScanner
+	pending   []string // Statements to return before reading on
+	epilogue  []string
+	batchSize int // Commit every batchSize statements, 0 for never
+	count     int
+	done      bool // The dump has been read
+}
+
+// Next implements mysql.StatementReader.
+func (s *nativeStatements) Next() (string, error) {
+	for len(s.pending) == 0 {
+		if s.done {
+			return "", io.EOF
+		}
+		stmt, err := s.scanner.Next()
+		if err == io.EOF {
+			s.done = true
+			s.pending = s.epilogue
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read dump: %w", err)
+		}
+		s.count++
+		s.pending = []string{stmt}
+		if s.batchSize > 0 && s.count%s.batchSize == 0 {
+			s.pending = append(s.pending, "COMMIT")
+		}
+	}
+
+	stmt := s.pending[0]
+	s.pending = s.pending[1:]
+	return stmt, nil
+}
+
+// Ensure NativeRestorer satisfies the restore interfaces.
+var (
+	_ Restorer       = (*NativeRestorer)(nil)
+	_ FastModeSetter = (*NativeRestorer)(nil)
+	_ SQLModeSetter  = (*NativeRestorer)(nil)
+)
@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nativeTestRestorer returns a NativeRestorer running against a mock client.
+func nativeTestRestorer(mock *mysql.MockClient) (*NativeRestorer, *mysql.Config) {
+	restorer := NewNativeRestorer(&mysql.Config{Host: "localhost", Port: 3306, User: "root"})
+	var used mysql.Config
+	restorer.newClient = func(config *mysql.Config) (mysql.DatabaseClient, error) {
+		used = *config
+		return mock, nil
+	}
+	return restorer, &used
+}
+
+// executedStatements returns the statements the mock client executed.
+func executedStatements(mock *mysql.MockClient) []string {
+	var stmts []string
+	for _, call := range mock.GetCalls() {
+		if call.Method == "Execute" {
+			stmts = append(stmts, call.Args[0].(string))
+		}
+	}
+	return stmts
+}
+
+func TestNewNativeRestorer(t *testing.T) {
+	restorer := NewNativeRestorer(&mysql.Config{Timeout: 10 * time.Second})
+	assert.Equal(t, 60*time.Second, restorer.timeout)
+	assert.True(t, restorer.fastMode)
+
+	restorer = NewNativeRestorer(&mysql.Config{})
+	assert.Equal(t, 30*time.Minute, restorer.timeout)
+}
+
+func TestNativeRestorerRestore(t *testing.T) {
+	dump := `-- MySQL dump
+/*!40101 SET NAMES utf8mb4 */;
+CREATE TABLE t (id INT, note TEXT);
+INSERT INTO t VALUES (1,'a;b'),(2,'c');
+DELIMITER ;;
+CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.note = 'x'; END ;;
+DELIMITER ;
+`
+
+	t.Run("fast mode", func(t *testing.T) {
+		mock := mysql.NewMockClient()
+		restorer, used := nativeTestRestorer(mock)
+
+		var logged string
+		err := restorer.RestoreWithCommand("shop", strings.NewReader(dump), func(msg string) { logged = msg })
+		require.NoError(t, err)
+
+		assert.Equal(t, "shop", used.Database)
+		assert.Contains(t, logged, "native restore into root@localhost:3306/shop")
+		assert.Equal(t, []string{
+			"SET FOREIGN_KEY_CHECKS=0",
+			"SET UNIQUE_CHECKS=0",
+			"SET autocommit=0",
+			"/*!40101 SET NAMES utf8mb4 */",
+			"CREATE TABLE t (id INT, note TEXT)",
+			"INSERT INTO t VALUES (1,'a;b'),(2,'c')",
+			"CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.note = 'x'; END",
+			"COMMIT",
+			"SET UNIQUE_CHECKS=1",
+			"SET FOREIGN_KEY_CHECKS=1",
+		}, executedStatements(mock))
+		assert.False(t, mock.IsConnected(), "client should be closed")
+	})
+
+	t.Run("without fast mode", func(t *testing.T) {
+		mock := mysql.NewMockClient()
+		restorer, _ := nativeTestRestorer(mock)
+		restorer.SetFastMode(false)
+
+		require.NoError(t, restorer.Restore("shop", strings.NewReader("INSERT INTO t VALUES (1);\n")))
+		assert.Equal(t, []string{
+			"SET FOREIGN_KEY_CHECKS=0",
+			"INSERT INTO t VALUES (1)",
+			"SET FOREIGN_KEY_CHECKS=1",
+		}, executedStatements(mock))
+	})
+
+	t.Run("sql mode", func(t *testing.T) {
+		mock := mysql.NewMockClient()
+		restorer, _ := nativeTestRestorer(mock)
+		restorer.SetFastMode(false)
+		restorer.SetSQLMode("NO_ENGINE_SUBSTITUTION")
+
+		require.NoError(t, restorer.Restore("shop", strings.NewReader("INSERT INTO t VALUES (1);\n")))
+		assert.Equal(t, "SET SESSION sql_mode='NO_ENGINE_SUBSTITUTION'", executedStatements(mock)[0])
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		mock := mysql.NewMockClient()
+		restorer, _ := nativeTestRestorer(mock)
+		restorer.batchSize = 2
+
+		dump := strings.Repeat("INSERT INTO t VALUES (1);\n", 5)
+		require.NoError(t, restorer.Restore("shop", strings.NewReader(dump)))
+
+		commits := 0
+		for _, stmt := range executedStatements(mock) {
+			if stmt == "COMMIT" {
+				commits++
+			}
+		}
+		assert.Equal(t, 3, commits, "two batch commits and the final one")
+	})
+}
+
+func TestNativeRestorerErrors(t *testing.T) {
+	restorer, _ := nativeTestRestorer(mysql.NewMockClient())
+	err := restorer.Restore("", strings.NewReader(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database name is required")
+
+	mock := mysql.NewMockClient()
+	mock.ConnectErr = errors.New("refused")
+	restorer, _ = nativeTestRestorer(mock)
+	err = restorer.Restore("shop", strings.NewReader("SELECT 1;"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect")
+
+	mock = mysql.NewMockClient()
+	mock.ExecErr = errors.New("syntax error")
+	restorer, _ = nativeTestRestorer(mock)
+	err = restorer.Restore("shop", strings.NewReader(strings.Repeat("SELECT 1;\n", 10000)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "native restore failed")
+}
+
+func TestValidateRestoreEngine(t *testing.T) {
+	assert.NoError(t, ValidateRestoreEngine(""))
+	assert.NoError(t, ValidateRestoreEngine(RestoreEngineMySQL))
+	assert.NoError(t, ValidateRestoreEngine(RestoreEngineNative))
+	assert.Error(t, ValidateRestoreEngine("myloader"))
+}
@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// optionFileEscaper escapes a value for a double-quoted option file entry.
+var optionFileEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// clientOptionFile writes password to a temporary option file readable only
+// by the current user, to be passed with --defaults-extra-file so the
+// password never appears in the process list. No file is needed for an
+// empty password and the returned path is empty. The cleanup function
+// removes the file and must always be called.
+func clientOptionFile(password string) (string, func(), error) {
+	noop := func() {}
+	if password == "" {
+		return "", noop, nil
+	}
+
+	file, err := CurrentWorkDir().CreateTemp("cadangkan-*.cnf")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create option file: %w", err)
+	}
+	cleanup := func() {
+		os.Remove(file.Name())
+	}
+
+	content := fmt.Sprintf("[client]\npassword=\"%s\"\n", optionFileEscaper.Replace(password))
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		cleanup()
+		return "", noop, fmt.Errorf("failed to secure option file: %w", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		cleanup()
+		return "", noop, fmt.Errorf("failed to write option file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to write option file: %w", err)
+	}
+
+	return file.Name(), cleanup, nil
+}
+
+// withOptionFile prepends --defaults-extra-file to args. MySQL clients only
+// accept it as the first argument.
+func withOptionFile(path string, args []string) []string {
+	if path == "" {
+		return args
+	}
+	return append([]string{"--defaults-extra-file=" + path}, args...)
+}
+
+// passwordEnv returns the environment for a child process with password in
+// MYSQL_PWD, for tools that do not read MySQL option files.
+func passwordEnv(password string) []string {
+	env := os.Environ()
+	if password != "" {
+		env = append(env, "MYSQL_PWD="+password)
+	}
+	return env
+}
@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClientScript records the arguments, option file, MYSQL_PWD and stdin
+// a client tool was started with into files next to the script.
+const fakeClientScript = `#!/bin/sh
+echo "$@" > "$0.args"
+case "$1" in
+--defaults-extra-file=*) cat "${1#--defaults-extra-file=}" > "$0.cnf" ;;
+esac
+echo "$MYSQL_PWD" > "$0.env"
+cat > "$0.stdin"
+`
+
+// installFakeClient puts a fake client tool called name first on PATH and
+// returns the path prefix of its recorded files.
+func installFakeClient(t *testing.T, name string) string {
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(fakeClientScript), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return path
+}
+
+func readRecorded(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+const testPassword = `s3cr"et\pw`
+
+func testClientConfig() *mysql.Config {
+	return &mysql.Config{Host: "localhost", Port: 3306, User: "root", Password: testPassword}
+}
+
+func TestClientOptionFile(t *testing.T) {
+	path, cleanup, err := clientOptionFile(testPassword)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.Equal(t, "[client]\npassword=\"s3cr\\\"et\\\\pw\"\n", readRecorded(t, path))
+
+	cleanup()
+	assert.NoFileExists(t, path)
+
+	path, cleanup, err = clientOptionFile("")
+	require.NoError(t, err)
+	cleanup()
+	assert.Empty(t, path)
+}
+
+func TestMySQLDumperPasswordNotInArgv(t *testing.T) {
+	script := installFakeClient(t, "mysqldump")
+
+	reader, err := NewMySQLDumper(testClientConfig()).Dump("shop", nil)
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	args := readRecorded(t, script+".args")
+	assert.True(t, strings.HasPrefix(args, "--defaults-extra-file="))
+	assert.NotContains(t, args, "s3cr")
+	assert.Contains(t, readRecorded(t, script+".cnf"), "password=")
+
+	// The option file is removed once the dump is closed
+	optionFile := strings.TrimPrefix(strings.Fields(args)[0], "--defaults-extra-file=")
+	assert.NoFileExists(t, optionFile)
+}
+
+func TestMySQLRestorerPasswordNotInArgv(t *testing.T) {
+	script := installFakeClient(t, "mysql")
+
+	err := NewMySQLRestorer(testClientConfig()).Restore("shop", bytes.NewReader([]byte("SELECT 1;")))
+	require.NoError(t, err)
+
+	args := readRecorded(t, script+".args")
+	assert.True(t, strings.HasPrefix(args, "--defaults-extra-file="))
+	assert.NotContains(t, args, "s3cr")
+	assert.Contains(t, readRecorded(t, script+".cnf"), "password=")
+}
+
+func TestMydumperPasswordNotInArgv(t *testing.T) {
+	script := installFakeClient(t, "mydumper")
+
+	err := NewMydumperDumper(testClientConfig()).DumpToDirectory("shop", t.TempDir(), nil, 0, false, nil)
+	require.NoError(t, err)
+
+	assert.NotContains(t, readRecorded(t, script+".args"), "s3cr")
+	assert.Equal(t, testPassword+"\n", readRecorded(t, script+".env"))
+}
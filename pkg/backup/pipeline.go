@@ -0,0 +1,253 @@
+package backup
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// DefaultBufferSize is the size of buffers used when streaming backup data.
+	// Large buffers reduce syscall overhead on multi-GB dumps.
+	DefaultBufferSize = 256 * 1024
+
+	// pipelineDepth is the number of buffers that may be in flight between
+	// two pipeline stages before the producer blocks.
+	pipelineDepth = 4
+)
+
+// bufferPool recycles streaming buffers of DefaultBufferSize.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, DefaultBufferSize)
+		return &buf
+	},
+}
+
+// getBuffer returns a buffer of at least size bytes.
+// Buffers of DefaultBufferSize come from the shared pool.
+func getBuffer(size int) *[]byte {
+	if size == DefaultBufferSize {
+		return bufferPool.Get().(*[]byte)
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// putBuffer returns a buffer to the pool if it has the pooled size.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) == DefaultBufferSize {
+		*buf = (*buf)[:DefaultBufferSize]
+		bufferPool.Put(buf)
+	}
+}
+
+// copyBuffered copies from src to dst using a pooled buffer.
+func copyBuffered(dst io.Writer, src io.Reader, size int) (int64, error) {
+	buf := getBuffer(size)
+	defer putBuffer(buf)
+	return io.CopyBuffer(dst, src, *buf)
+}
+
+// chunk is a unit of data passed between pipeline stages.
+type chunk struct {
+	buf *[]byte
+	n   int
+	err error
+}
+
+// asyncReader reads from an underlying reader in a separate goroutine so
+// that reading the dump overlaps with compression.
+type asyncReader struct {
+	chunks  chan chunk
+	done    chan struct{}
+	current chunk
+	offset  int
+	err     error
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// newAsyncReader starts reading src in the background.
+func newAsyncReader(src io.Reader, size int) *asyncReader {
+	r := &asyncReader{
+		chunks: make(chan chunk, pipelineDepth),
+		done:   make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer close(r.chunks)
+		for {
+			buf := getBuffer(size)
+			n, err := io.ReadFull(src, *buf)
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			select {
+			case r.chunks <- chunk{buf: buf, n: n, err: err}:
+			case <-r.done:
+				putBuffer(buf)
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// Read implements io.Reader.
+func (r *asyncReader) Read(p []byte) (int, error) {
+	for {
+		if r.current.buf != nil && r.offset < r.current.n {
+			n := copy(p, (*r.current.buf)[r.offset:r.current.n])
+			r.offset += n
+			return n, nil
+		}
+
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if r.current.buf != nil {
+			putBuffer(r.current.buf)
+			r.current.buf = nil
+		}
+
+		c, ok := <-r.chunks
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		r.current = c
+		r.offset = 0
+		if c.err != nil {
+			r.err = c.err
+		}
+	}
+}
+
+// Close stops the background reader and releases buffers.
+func (r *asyncReader) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+		for c := range r.chunks {
+			putBuffer(c.buf)
+		}
+		r.wg.Wait()
+		if r.current.buf != nil {
+			putBuffer(r.current.buf)
+			r.current.buf = nil
+		}
+	})
+	return nil
+}
+
+// asyncWriter batches writes into large buffers and hands them to a
+// background goroutine so that disk writes overlap with compression.
+type asyncWriter struct {
+	dst     io.Writer
+	size    int
+	chunks  chan chunk
+	current *[]byte
+	used    int
+	errMu   sync.Mutex
+	err     error
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// newAsyncWriter starts a background writer for dst.
+func newAsyncWriter(dst io.Writer, size int) *asyncWriter {
+	w := &asyncWriter{
+		dst:    dst,
+		size:   size,
+		chunks: make(chan chunk, pipelineDepth),
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for c := range w.chunks {
+			if w.getErr() == nil {
+				if _, err := w.dst.Write((*c.buf)[:c.n]); err != nil {
+					w.setErr(err)
+				}
+			}
+			putBuffer(c.buf)
+		}
+	}()
+
+	return w
+}
+
+func (w *asyncWriter) getErr() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
+
+func (w *asyncWriter) setErr(err error) {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// Write implements io.Writer.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if err := w.getErr(); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if w.current == nil {
+			w.current = getBuffer(w.size)
+			w.used = 0
+		}
+
+		n := copy((*w.current)[w.used:], p)
+		w.used += n
+		written += n
+		p = p[n:]
+
+		if w.used == len(*w.current) {
+			w.flush()
+		}
+	}
+
+	return written, nil
+}
+
+// flush hands the current buffer to the background goroutine.
+func (w *asyncWriter) flush() {
+	if w.current == nil {
+		return
+	}
+	if w.used == 0 {
+		putBuffer(w.current)
+		w.current = nil
+		return
+	}
+	w.chunks <- chunk{buf: w.current, n: w.used}
+	w.current = nil
+	w.used = 0
+}
+
+// Close flushes pending data and waits for all writes to complete.
+func (w *asyncWriter) Close() error {
+	if w.closed {
+		return w.getErr()
+	}
+	w.closed = true
+
+	w.flush()
+	close(w.chunks)
+	w.wg.Wait()
+	return w.getErr()
+}
@@ -0,0 +1,186 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateDumpData produces size bytes of SQL-like data that compresses
+// roughly as well as a real mysqldump output.
+func generateDumpData(size int) []byte {
+	var buf bytes.Buffer
+	buf.Grow(size + 128)
+	for i := 0; buf.Len() < size; i++ {
+		buf.WriteString("INSERT INTO `orders` VALUES (")
+		buf.WriteString(strconv.Itoa(i))
+		buf.WriteString(",'customer-")
+		buf.WriteString(strconv.Itoa(i * 7919 % 100003))
+		buf.WriteString("','2024-01-01 00:00:00',")
+		buf.WriteString(strconv.Itoa(i * 31 % 9973))
+		buf.WriteString(");\n")
+	}
+	return buf.Bytes()[:size]
+}
+
+func TestCompressPipelinedMatchesSequential(t *testing.T) {
+	data := generateDumpData(3*DefaultBufferSize + 12345)
+
+	for _, compression := range []string{CompressionGzip, CompressionNone} {
+		t.Run(compression, func(t *testing.T) {
+			sequential := NewCompressor(compression)
+			var seqOut bytes.Buffer
+			seqResult, err := sequential.Compress(bytes.NewReader(data), &seqOut)
+			require.NoError(t, err)
+
+			pipelined := NewCompressor(compression)
+			pipelined.SetPipelined(true)
+			var pipeOut bytes.Buffer
+			pipeResult, err := pipelined.Compress(bytes.NewReader(data), &pipeOut)
+			require.NoError(t, err)
+
+			assert.Equal(t, seqOut.Bytes(), pipeOut.Bytes())
+			assert.Equal(t, seqResult.Checksum, pipeResult.Checksum)
+			assert.Equal(t, int64(len(data)), pipeResult.BytesRead)
+			assert.Equal(t, int64(pipeOut.Len()), pipeResult.BytesWritten)
+		})
+	}
+}
+
+func TestCompressReportsBytesWritten(t *testing.T) {
+	compressor := NewCompressor(CompressionGzip)
+
+	var output bytes.Buffer
+	result, err := compressor.Compress(bytes.NewReader([]byte("SELECT 1;")), &output)
+	require.NoError(t, err)
+	assert.Equal(t, int64(output.Len()), result.BytesWritten)
+}
+
+func TestCompressSmallBufferSize(t *testing.T) {
+	data := generateDumpData(100000)
+
+	compressor := NewCompressor(CompressionNone)
+	compressor.SetBufferSize(1000)
+	compressor.SetPipelined(true)
+
+	var output bytes.Buffer
+	_, err := compressor.Compress(bytes.NewReader(data), &output)
+	require.NoError(t, err)
+	assert.Equal(t, data, output.Bytes())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection lost")
+}
+
+func TestCompressPipelinedWriteError(t *testing.T) {
+	compressor := NewCompressor(CompressionGzip)
+	compressor.SetPipelined(true)
+
+	data := generateDumpData(4 * DefaultBufferSize)
+	_, err := compressor.Compress(bytes.NewReader(data), failingWriter{})
+	require.Error(t, err)
+	assert.True(t, IsCompressionError(err))
+	assert.Contains(t, err.Error(), "disk full")
+}
+
+func TestCompressPipelinedReadError(t *testing.T) {
+	compressor := NewCompressor(CompressionGzip)
+	compressor.SetPipelined(true)
+
+	var output bytes.Buffer
+	_, err := compressor.Compress(io.MultiReader(bytes.NewReader([]byte("partial")), failingReader{}), &output)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection lost")
+}
+
+func TestAsyncReaderCloseEarly(t *testing.T) {
+	data := generateDumpData(10 * DefaultBufferSize)
+	reader := newAsyncReader(bytes.NewReader(data), DefaultBufferSize)
+
+	buf := make([]byte, 10)
+	_, err := reader.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, data[:10], buf)
+
+	// Closing before the source is drained must not block
+	assert.NoError(t, reader.Close())
+	assert.NoError(t, reader.Close())
+}
+
+// benchmarkSize returns the amount of data used by the compression benchmarks.
+// Set CADANGKAN_BENCH_SIZE (in bytes) to benchmark multi-GB dumps.
+func benchmarkSize(b *testing.B) int {
+	size := 64 * 1024 * 1024
+	if env := os.Getenv("CADANGKAN_BENCH_SIZE"); env != "" {
+		parsed, err := strconv.Atoi(env)
+		if err != nil {
+			b.Fatalf("invalid CADANGKAN_BENCH_SIZE: %v", err)
+		}
+		size = parsed
+	}
+	return size
+}
+
+// repeatingReader yields size bytes by cycling over data, so large
+// benchmarks do not need the whole dump in memory.
+type repeatingReader struct {
+	data      []byte
+	offset    int
+	remaining int64
+}
+
+func (r *repeatingReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n := copy(p, r.data[r.offset:])
+	r.offset = (r.offset + n) % len(r.data)
+	r.remaining -= int64(n)
+	return n, nil
+}
+
+func benchmarkStreamCompress(b *testing.B, pipelined bool) {
+	size := benchmarkSize(b)
+	sample := generateDumpData(8 * 1024 * 1024)
+	outputPath := filepath.Join(b.TempDir(), "bench.sql.gz")
+
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		compressor := NewCompressor(CompressionGzip)
+		compressor.SetPipelined(pipelined)
+
+		reader := &repeatingReader{data: sample, remaining: int64(size)}
+		if _, err := compressor.StreamCompress(reader, outputPath); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamCompressSequential(b *testing.B) {
+	benchmarkStreamCompress(b, false)
+}
+
+func BenchmarkStreamCompressPipelined(b *testing.B) {
+	benchmarkStreamCompress(b, true)
+}
@@ -0,0 +1,179 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// planHeadSize is how much of each line ScanDump keeps to classify the
+// statement it starts.
+const planHeadSize = 256
+
+var (
+	planDropDatabasePrefixes = [][]byte{[]byte("DROP DATABASE"), []byte("/*!40000 DROP DATABASE")}
+	planCreateDatabasePrefix = []byte("CREATE DATABASE")
+	planDropTablePrefix      = []byte("DROP TABLE")
+	planDelimiterPrefix      = []byte("DELIMITER ")
+	planCommentPrefix        = []byte("--")
+)
+
+// RestorePlan summarizes what restoring a SQL dump would do, as found by
+// ScanDump. Tables are named database.table in dumps of several databases.
+type RestorePlan struct {
+	// DroppedDatabases and CreatedDatabases are dropped and created by
+	// dumps taken with --databases
+	DroppedDatabases []string `json:"dropped_databases,omitempty"`
+	CreatedDatabases []string `json:"created_databases,omitempty"`
+
+	// DroppedTables and CreatedTables are replaced by the dump
+	DroppedTables []string `json:"dropped_tables,omitempty"`
+	CreatedTables []string `json:"created_tables,omitempty"`
+
+	// Views are created by the dump
+	Views []string `json:"views,omitempty"`
+
+	// Statements is the number of SQL statements executed, Inserts the
+	// number of them that load data
+	Statements int `json:"statements"`
+	Inserts    int `json:"inserts"`
+
+	// SizeBytes is the size of the SQL sent to the server
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// ScanDump reads a SQL dump to the end and returns what restoring it
+// would do. Statements are found line by line the way mysqldump writes
+// them: a statement ends with a line ending in the current delimiter.
+func ScanDump(r io.Reader) (*RestorePlan, error) {
+	s := &planScanner{
+		plan:      &RestorePlan{},
+		delimiter: []byte(";"),
+		lineStart: true,
+	}
+	reader := bufio.NewReaderSize(r, DefaultBufferSize)
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			s.scan(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			if !s.lineStart {
+				s.endLine()
+			}
+			return s.plan, nil
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			return nil, fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+}
+
+// planScanner holds the state of ScanDump between fragments of lines.
+type planScanner struct {
+	plan      *RestorePlan
+	delimiter []byte
+	database  string // Database of the last USE statement
+
+	lineStart   bool
+	head        []byte // Start of the current line
+	tail        []byte // Last bytes of the current line, without spaces
+	inStatement bool
+}
+
+// scan takes in a fragment of a line.
+func (s *planScanner) scan(fragment []byte) {
+	s.plan.SizeBytes += int64(len(fragment))
+	if s.lineStart {
+		s.head = append(s.head[:0], fragment[:min(len(fragment), planHeadSize)]...)
+	}
+	if trimmed := bytes.TrimRight(fragment, " \t\r\n"); len(trimmed) > 0 {
+		s.tail = append(s.tail, trimmed...)
+		if len(s.tail) > planHeadSize {
+			s.tail = append(s.tail[:0], s.tail[len(s.tail)-len(s.delimiter):]...)
+		}
+	}
+	s.lineStart = fragment[len(fragment)-1] == '\n'
+	if s.lineStart {
+		s.endLine()
+	}
+}
+
+// endLine classifies the line just read.
+func (s *planScanner) endLine() {
+	head := bytes.TrimLeft(s.head, " \t")
+	ended := bytes.HasSuffix(s.tail, s.delimiter)
+	s.head, s.tail = s.head[:0], s.tail[:0]
+
+	if !s.inStatement {
+		switch {
+		case bytes.HasPrefix(head, finalViewMarker):
+			s.plan.Views = append(s.plan.Views, s.qualify(quotedName(head[len(finalViewMarker)-1:])))
+			return
+		case len(bytes.TrimSpace(head)) == 0 || bytes.HasPrefix(head, planCommentPrefix):
+			return
+		case bytes.HasPrefix(head, planDelimiterPrefix):
+			if delimiter := bytes.TrimSpace(head[len(planDelimiterPrefix):]); len(delimiter) > 0 {
+				s.delimiter = append([]byte{}, delimiter...)
+			}
+			return
+		}
+		s.startStatement(head)
+	}
+	if ended {
+		s.inStatement = false
+	}
+}
+
+// startStatement counts the statement starting with head.
+func (s *planScanner) startStatement(head []byte) {
+	s.inStatement = true
+	s.plan.Statements++
+	switch {
+	case hasAnyPrefix(head, planDropDatabasePrefixes):
+		s.plan.DroppedDatabases = append(s.plan.DroppedDatabases, quotedName(head))
+	case bytes.HasPrefix(head, planCreateDatabasePrefix):
+		s.plan.CreatedDatabases = append(s.plan.CreatedDatabases, quotedName(head))
+	case bytes.HasPrefix(head, useDatabasePrefix):
+		s.database = quotedName(head)
+	case bytes.HasPrefix(head, planDropTablePrefix):
+		s.plan.DroppedTables = append(s.plan.DroppedTables, s.qualify(quotedName(head)))
+	case bytes.HasPrefix(head, createTablePrefix):
+		s.plan.CreatedTables = append(s.plan.CreatedTables, s.qualify(quotedName(head)))
+	case bytes.HasPrefix(head, insertIntoPrefix):
+		s.plan.Inserts++
+	}
+}
+
+// qualify prefixes a table with its database in dumps that switch between
+// databases.
+func (s *planScanner) qualify(table string) string {
+	if s.database == "" || table == "" {
+		return table
+	}
+	return s.database + "." + table
+}
+
+// quotedName returns the first backquoted identifier in line, or empty.
+func quotedName(line []byte) string {
+	start := bytes.IndexByte(line, '`')
+	if start < 0 {
+		return ""
+	}
+	var name []byte
+	for i := start + 1; i < len(line); i++ {
+		if line[i] != '`' {
+			name = append(name, line[i])
+			continue
+		}
+		if i+1 < len(line) && line[i+1] == '`' {
+			name = append(name, '`')
+			i++
+			continue
+		}
+		return string(name)
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDump(t *testing.T) {
+	plan, err := ScanDump(strings.NewReader(tableDump))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "users"}, plan.DroppedTables)
+	assert.Equal(t, []string{"orders", "users"}, plan.CreatedTables)
+	assert.Equal(t, 2, plan.Inserts)
+	// SET NAMES, two DROP, CREATE and INSERT each, LOCK, UNLOCK and the trigger
+	assert.Equal(t, 10, plan.Statements)
+	assert.Equal(t, int64(len(tableDump)), plan.SizeBytes)
+
+	t.Run("several databases", func(t *testing.T) {
+		dump := "CREATE DATABASE /*!32312 IF NOT EXISTS*/ `shop` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;\n" +
+			"USE `shop`;\n" +
+			"CREATE TABLE `orders` (`id` int);\n" +
+			strings.TrimPrefix(viewsDump, "-- MySQL dump\n")
+		plan, err := ScanDump(strings.NewReader(dump))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shop"}, plan.CreatedDatabases)
+		assert.Equal(t, []string{"shop.orders", "stats.daily"}, plan.CreatedTables)
+		assert.Equal(t, []string{"shop.report"}, plan.Views)
+	})
+
+	t.Run("unterminated last line", func(t *testing.T) {
+		plan, err := ScanDump(strings.NewReader("INSERT INTO `t` VALUES (1);\nINSERT INTO `t` VALUES (2);"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, plan.Statements)
+	})
+}
+
+func TestRestoreServiceDryRunPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), tableDump)
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip"))
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer, "testdb")
+	service.storage = localStorage
+
+	options := &RestoreOptions{Database: "testdb", BackupID: backupID, DryRun: true, Plan: true, ExcludeTables: []string{"orders"}}
+	result, err := service.Restore(options)
+	require.NoError(t, err)
+	require.NotNil(t, result.Plan)
+	assert.Equal(t, []string{"users"}, result.Plan.CreatedTables)
+	assert.Empty(t, restorer.sql)
+
+	options.Plan = false
+	result, err = service.Restore(options)
+	require.NoError(t, err)
+	assert.Nil(t, result.Plan)
+}
@@ -0,0 +1,33 @@
+package backup
+
+import "io"
+
+// ProgressFunc receives the number of bytes processed so far by a backup,
+// restore or import. It is called from the goroutine moving the data, so
+// it must return quickly.
+type ProgressFunc func(bytes int64)
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	reader io.Reader
+	bytes  int64
+	report ProgressFunc
+}
+
+// ProgressReader wraps r to report the bytes read through it to report.
+// A nil report returns r unchanged.
+func ProgressReader(r io.Reader, report ProgressFunc) io.Reader {
+	if report == nil {
+		return r
+	}
+	return &progressReader{reader: r, report: report}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if n > 0 {
+		p.bytes += int64(n)
+		p.report(p.bytes)
+	}
+	return n, err
+}
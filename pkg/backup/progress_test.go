@@ -0,0 +1,34 @@
+package backup
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressReader(t *testing.T) {
+	t.Run("reports running totals", func(t *testing.T) {
+		var reports []int64
+		r := ProgressReader(strings.NewReader(strings.Repeat("x", 10)), func(bytes int64) {
+			reports = append(reports, bytes)
+		})
+
+		buf := make([]byte, 4)
+		for {
+			_, err := r.Read(buf)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []int64{4, 8, 10}, reports)
+	})
+
+	t.Run("nil report leaves reader unchanged", func(t *testing.T) {
+		src := strings.NewReader("data")
+		assert.Same(t, src, ProgressReader(src, nil))
+	})
+}
@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// Quota limits the storage used by backups. Zero limits are unlimited.
+type Quota struct {
+	// DatabaseLimit is the maximum size in bytes of one database's backups
+	DatabaseLimit int64
+
+	// TotalLimit is the maximum size in bytes of all backups
+	TotalLimit int64
+}
+
+// QuotaPolicy describes how quotas apply to one database.
+type QuotaPolicy struct {
+	// Limits are checked before every backup
+	Limits Quota
+
+	// Prune holds the limits that are enforced by deleting old backups
+	// instead of failing the backup
+	Prune Quota
+
+	// MinKeep is the number of newest backups never pruned for quota
+	MinKeep int
+}
+
+// EnforceQuota deletes the oldest backups of a database until a new backup
+// of size needed fits within the policy's prune limits. The newest MinKeep
+// backups and locked backups are never deleted, so the quota may still be
+// exceeded afterwards.
+func (s *RetentionService) EnforceQuota(databaseName string, policy *QuotaPolicy, needed int64) (*CleanupResult, error) {
+	result := &CleanupResult{
+		ToDelete: []storage.BackupListEntry{},
+		ToKeep:   []CategorizedBackup{},
+	}
+	if policy == nil || (policy.Prune.DatabaseLimit <= 0 && policy.Prune.TotalLimit <= 0) {
+		return result, nil
+	}
+
+	// Backups are listed newest first
+	backups, err := s.storage.ListBackups(databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var dbUsage int64
+	for _, b := range backups {
+		dbUsage += b.SizeBytes
+	}
+
+	var totalUsage int64
+	if policy.Prune.TotalLimit > 0 {
+		totalUsage, err = s.storage.TotalUsageBytes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate storage usage: %w", err)
+		}
+	}
+
+	overQuota := func() bool {
+		if policy.Prune.DatabaseLimit > 0 && dbUsage+needed > policy.Prune.DatabaseLimit {
+			return true
+		}
+		return policy.Prune.TotalLimit > 0 && totalUsage+needed > policy.Prune.TotalLimit
+	}
+
+	now := time.Now()
+	deleted := make(map[string]bool)
+	for i := len(backups) - 1; i >= policy.MinKeep && overQuota(); i-- {
+		b := backups[i]
+		if b.Lock.Active(now) {
+			continue
+		}
+		if err := s.storage.DeleteBackup(databaseName, b.BackupID); err != nil {
+			return nil, fmt.Errorf("failed to delete backup %s: %w", b.BackupID, err)
+		}
+		deleted[b.BackupID] = true
+		result.ToDelete = append(result.ToDelete, b)
+		result.SpaceReclaimed += b.SizeBytes
+		dbUsage -= b.SizeBytes
+		totalUsage -= b.SizeBytes
+	}
+
+	for _, b := range backups {
+		if deleted[b.BackupID] {
+			continue
+		}
+		category := CategoryKeep
+		if b.Lock.Active(now) {
+			category = CategoryLocked
+		}
+		result.ToKeep = append(result.ToKeep, CategorizedBackup{Backup: b, Category: category})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceQuota(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	// Five backups, one day apart; "backup-4" is the oldest
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		createRetentionBackup(t, tmpDir, fmt.Sprintf("backup-%d", i), now.AddDate(0, 0, -i))
+	}
+	require.NoError(t, localStorage.SetBackupLock("testdb", "backup-4", &storage.LockInfo{LockedAt: now}))
+
+	backups, err := localStorage.ListBackups("testdb")
+	require.NoError(t, err)
+	size := backups[0].SizeBytes
+
+	service := NewRetentionService(localStorage)
+
+	t.Run("alert-only policy prunes nothing", func(t *testing.T) {
+		policy := &QuotaPolicy{Limits: Quota{DatabaseLimit: size}, MinKeep: 1}
+		result, err := service.EnforceQuota("testdb", policy, size)
+		require.NoError(t, err)
+		assert.Empty(t, result.ToDelete)
+	})
+
+	t.Run("prunes oldest unlocked backups", func(t *testing.T) {
+		policy := &QuotaPolicy{Prune: Quota{DatabaseLimit: 3 * size}, MinKeep: 1}
+		result, err := service.EnforceQuota("testdb", policy, size)
+		require.NoError(t, err)
+
+		deleted := make([]string, len(result.ToDelete))
+		for i, b := range result.ToDelete {
+			deleted[i] = b.BackupID
+		}
+		assert.Equal(t, []string{"backup-3", "backup-2", "backup-1"}, deleted)
+		assert.Equal(t, 3*size, result.SpaceReclaimed)
+
+		remaining, err := localStorage.ListBackups("testdb")
+		require.NoError(t, err)
+		require.Len(t, remaining, 2)
+		assert.Equal(t, "backup-0", remaining[0].BackupID)
+		assert.Equal(t, "backup-4", remaining[1].BackupID)
+	})
+
+	t.Run("never prunes below min keep", func(t *testing.T) {
+		policy := &QuotaPolicy{Prune: Quota{DatabaseLimit: 1}, MinKeep: 1}
+		result, err := service.EnforceQuota("testdb", policy, size)
+		require.NoError(t, err)
+		assert.Empty(t, result.ToDelete)
+	})
+}
+
+func TestServiceCheckQuota(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+	createRetentionBackup(t, tmpDir, "backup-0", time.Now())
+
+	usage, err := localStorage.UsageBytes("testdb")
+	require.NoError(t, err)
+
+	mockClient := mysql.NewMockClient()
+	mockClient.SetConnected(true)
+	mockClient.DBSizes = map[string]int64{"testdb": 1000}
+
+	service := NewService(mockClient, localStorage, &mysql.Config{Host: "localhost"})
+	options := DefaultOptions()
+	options.Database = "testdb"
+	// The only backup does not record the database size, so its own size
+	// is the estimate
+	needed := service.EstimateSize(options)
+	assert.Equal(t, int64(1000), needed)
+
+	service.SetQuota(&Quota{DatabaseLimit: usage + needed})
+	assert.NoError(t, service.checkQuota("testdb", needed))
+
+	service.SetQuota(&Quota{DatabaseLimit: usage + needed - 1})
+	err = service.checkQuota("testdb", needed)
+	assert.True(t, IsQuotaExceededError(err))
+	assert.Contains(t, err.Error(), "storage quota for testdb exceeded")
+
+	service.SetQuota(&Quota{TotalLimit: usage})
+	err = service.checkQuota("testdb", needed)
+	assert.True(t, IsQuotaExceededError(err))
+	assert.Contains(t, err.Error(), "global storage quota exceeded")
+}
@@ -0,0 +1,503 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// RowCondition selects rows of a dump by the values of their columns. It
+// is the subset of a WHERE clause that can be checked against the text of
+// a dump: column = value and column IN (values...), joined by AND.
+//
+// Like MySQL's default collations, values match regardless of case unless
+// CaseSensitive is set, which suits columns with a binary or _cs collation.
+type RowCondition struct {
+	// CaseSensitive compares values byte for byte
+	CaseSensitive bool
+
+	terms []conditionTerm
+}
+
+// conditionTerm is one column = value or column IN (...) test. A nil
+// value stands for NULL, which never matches.
+type conditionTerm struct {
+	column string
+	values []*string
+}
+
+// ParseRowCondition parses a condition such as
+// "email = 'jane@example.com' AND tenant_id IN (1, 2)".
+func ParseRowCondition(where string) (*RowCondition, error) {
+	p := &conditionParser{input: where}
+	cond := &RowCondition{}
+	for {
+		term, err := p.term()
+		if err != nil {
+			return nil, &ValidationError{Field: "where", Message: err.Error()}
+		}
+		cond.terms = append(cond.terms, term)
+
+		token := p.next()
+		if token == "" {
+			return cond, nil
+		}
+		if !strings.EqualFold(token, "AND") {
+			return nil, &ValidationError{Field: "where", Message: fmt.Sprintf("expected AND, found %q (only =, IN and AND are supported)", token)}
+		}
+	}
+}
+
+// Columns returns the columns the condition tests.
+func (c *RowCondition) Columns() []string {
+	columns := make([]string, len(c.terms))
+	for i, term := range c.terms {
+		columns[i] = term.column
+	}
+	return columns
+}
+
+// matches reports whether a row, given as the values of its columns,
+// satisfies every term.
+func (c *RowCondition) matches(columns []string, row []*string) bool {
+	for _, term := range c.terms {
+		i := indexOf(columns, term.column)
+		if i < 0 || i >= len(row) || row[i] == nil {
+			return false
+		}
+		found := false
+		for _, value := range term.values {
+			if value != nil && c.equal(*value, *row[i]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares a value of the condition with one of the dump.
+func (c *RowCondition) equal(value, dumped string) bool {
+	if c.CaseSensitive {
+		return value == dumped
+	}
+	return strings.EqualFold(value, dumped)
+}
+
+// conditionParser reads the tokens of a row condition.
+type conditionParser struct {
+	input string
+	pos   int
+}
+
+func (p *conditionParser) term() (conditionTerm, error) {
+	column := p.next()
+	if column == "" || !isIdentifierToken(column) {
+		return conditionTerm{}, fmt.Errorf("expected a column name, found %q", column)
+	}
+	term := conditionTerm{column: strings.Trim(column, "`")}
+
+	switch op := p.next(); {
+	case op == "=":
+		value, err := p.value()
+		if err != nil {
+			return conditionTerm{}, err
+		}
+		term.values = []*string{value}
+	case strings.EqualFold(op, "IN"):
+		if p.next() != "(" {
+			return conditionTerm{}, fmt.Errorf("expected ( after IN")
+		}
+		for {
+			value, err := p.value()
+			if err != nil {
+				return conditionTerm{}, err
+			}
+			term.values = append(term.values, value)
+			if sep := p.next(); sep == ")" {
+				break
+			} else if sep != "," {
+				return conditionTerm{}, fmt.Errorf("expected , or ) in IN list, found %q", sep)
+			}
+		}
+	default:
+		return conditionTerm{}, fmt.Errorf("expected = or IN after %s, found %q", term.column, op)
+	}
+	return term, nil
+}
+
+// value reads a literal: a quoted string, a number, or NULL.
+func (p *conditionParser) value() (*string, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("expected a value")
+	case token[0] == '\'' || token[0] == '"':
+		value := unquoteValue([]byte(token))
+		return &value, nil
+	case strings.EqualFold(token, "NULL"):
+		return nil, nil
+	case isIdentifierToken(token):
+		return nil, fmt.Errorf("expected a value, found %q", token)
+	}
+	return &token, nil
+}
+
+// next returns the next token, or "" at the end of the input.
+func (p *conditionParser) next() string {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return ""
+	}
+
+	start := p.pos
+	switch c := p.input[p.pos]; c {
+	case '=', '(', ')', ',':
+		p.pos++
+	case '\'', '"', '`':
+		p.pos++
+		for p.pos < len(p.input) && p.input[p.pos] != c {
+			if p.input[p.pos] == '\\' && c != '`' {
+				p.pos++
+			}
+			p.pos++
+		}
+		p.pos++
+	default:
+		for p.pos < len(p.input) && !strings.ContainsRune(" \t\r\n=(),'\"`", rune(p.input[p.pos])) {
+			p.pos++
+		}
+	}
+	if p.pos > len(p.input) {
+		p.pos = len(p.input)
+	}
+	return p.input[start:p.pos]
+}
+
+func isIdentifierToken(token string) bool {
+	if token[0] == '`' {
+		return true
+	}
+	c := token[0]
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// RedactDump copies a mysqldump SQL dump from r to w, leaving out the rows
+// of table that match cond. INSERT statements left without rows are
+// dropped. Returns the number of rows removed.
+func RedactDump(r io.Reader, w io.Writer, table string, cond *RowCondition) (int64, error) {
+	reader := bufio.NewReaderSize(r, DefaultBufferSize)
+	writer := bufio.NewWriterSize(w, DefaultBufferSize)
+
+	var removed int64
+	var columns []string
+	inCreate := false
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return removed, fmt.Errorf("failed to read dump: %w", readErr)
+		}
+
+		owner, _ := sectionOwner(line)
+		switch {
+		case inCreate:
+			if name, ok := columnDefinition(line); ok {
+				columns = append(columns, name)
+			}
+			inCreate = !bytes.HasSuffix(bytes.TrimSpace(line), []byte(";"))
+		case owner == table && bytes.HasPrefix(line, createTablePrefix):
+			columns, inCreate = nil, true
+		case owner == table && bytes.HasPrefix(line, insertIntoPrefix):
+			var n int64
+			var err error
+			if line, n, err = redactInsert(line, columns, cond); err != nil {
+				return removed, fmt.Errorf("table %s: %w", table, err)
+			}
+			removed += n
+		}
+
+		if _, err := writer.Write(line); err != nil {
+			return removed, fmt.Errorf("failed to write dump: %w", err)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return removed, fmt.Errorf("failed to write dump: %w", err)
+	}
+	return removed, nil
+}
+
+// redactInsert returns an INSERT statement without the rows matching cond,
+// or nothing if no row is left, and the number of rows removed.
+func redactInsert(line []byte, columns []string, cond *RowCondition) ([]byte, int64, error) {
+	i := bytes.Index(line, valuesKeyword)
+	if i < 0 {
+		return line, 0, nil
+	}
+	head := line[:i+len(valuesKeyword)]
+
+	// A --complete-insert dump names the columns of each statement
+	if list := head[len(insertIntoPrefix):]; bytes.Contains(list, []byte("` (`")) {
+		columns = nil
+		for _, field := range bytes.Split(list[bytes.IndexByte(list, '(')+1:bytes.LastIndexByte(list, ')')], []byte(",")) {
+			columns = append(columns, string(bytes.Trim(bytes.TrimSpace(field), "`")))
+		}
+	}
+	for _, column := range cond.Columns() {
+		if indexOf(columns, column) < 0 {
+			return nil, 0, fmt.Errorf("column %s not found", column)
+		}
+	}
+
+	rows := insertRows(line)
+	kept := make([][]byte, 0, len(rows))
+	for _, row := range rows {
+		if !cond.matches(columns, rowValues(row)) {
+			kept = append(kept, row)
+		}
+	}
+
+	removed := int64(len(rows) - len(kept))
+	if removed == 0 {
+		return line, 0, nil
+	}
+	if len(kept) == 0 {
+		return nil, removed, nil
+	}
+
+	var out bytes.Buffer
+	out.Write(head)
+	out.Write(bytes.Join(kept, []byte(",")))
+	out.WriteString(";\n")
+	return out.Bytes(), removed, nil
+}
+
+// rowValues splits a parenthesised row of an INSERT into its values,
+// unquoting strings. NULL is returned as nil.
+func rowValues(row []byte) []*string {
+	row = bytes.TrimSuffix(bytes.TrimPrefix(row, []byte("(")), []byte(")"))
+
+	var values []*string
+	start := 0
+	inString, escaped := false, false
+	for i := 0; i <= len(row); i++ {
+		if i < len(row) {
+			c := row[i]
+			switch {
+			case escaped:
+				escaped = false
+				continue
+			case inString:
+				if c == '\\' {
+					escaped = true
+				} else if c == '\'' {
+					inString = false
+				}
+				continue
+			case c == '\'':
+				inString = true
+				continue
+			case c != ',':
+				continue
+			}
+		}
+
+		field := bytes.TrimSpace(row[start:i])
+		start = i + 1
+		if bytes.EqualFold(field, []byte("NULL")) {
+			values = append(values, nil)
+			continue
+		}
+		value := string(field)
+		if len(field) > 0 && field[0] == '\'' {
+			value = unquoteValue(field)
+		}
+		values = append(values, &value)
+	}
+	return values
+}
+
+// unquoteValue removes the quotes and backslash escapes of a SQL string.
+func unquoteValue(quoted []byte) string {
+	if len(quoted) >= 2 {
+		quoted = quoted[1 : len(quoted)-1]
+	}
+	var b strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		c := quoted[i]
+		if c != '\\' || i+1 == len(quoted) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch quoted[i] {
+		case '0':
+			b.WriteByte(0)
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'Z':
+			b.WriteByte(0x1a)
+		default:
+			b.WriteByte(quoted[i])
+		}
+	}
+	return b.String()
+}
+
+// columnDefinition returns the column defined by a line of a CREATE TABLE
+// statement, if it defines one.
+func columnDefinition(line []byte) (string, bool) {
+	line = bytes.TrimLeft(line, " ")
+	if len(line) == 0 || line[0] != '`' {
+		return "", false
+	}
+	end := bytes.IndexByte(line[1:], '`')
+	if end < 0 {
+		return "", false
+	}
+	return string(line[1 : end+1]), true
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Redaction records rows removed from a backup after it was taken.
+type Redaction struct {
+	// Table the rows were removed from
+	Table string `json:"table"`
+
+	// Columns the rows were selected by; the values are not recorded
+	Columns []string `json:"columns"`
+
+	// Rows is the number of rows removed
+	Rows int64 `json:"rows"`
+
+	// RedactedAt is when the backup was rewritten
+	RedactedAt time.Time `json:"redacted_at"`
+}
+
+// RedactBackup removes the rows of table matching cond from a stored
+// backup: the dump is decompressed, filtered and recompressed to a
+// temporary file that replaces the backup, and the checksums, size and
+// block index in its metadata are updated. The backup's checksum is
+// verified first. With dryRun the rows are only counted. Returns the
+// number of rows removed; a backup without matching rows is left as is.
+func RedactBackup(stor *storage.LocalStorage, database, backupID, table string, cond *RowCondition, dryRun bool) (int64, error) {
+	var metadata BackupMetadata
+	if err := stor.LoadMetadata(database, backupID, &metadata); err != nil {
+		return 0, err
+	}
+	if metadata.Backup.Format == FormatMydumper {
+		return 0, fmt.Errorf("backup %s is a mydumper backup; only mysqldump backups can be redacted", backupID)
+	}
+	MigrateChecksums(&metadata)
+
+	backupPath := filepath.Join(stor.GetBackupDir(database, backupID), metadata.Backup.File)
+	if checksum := metadata.Backup.FileChecksum(); checksum != "" {
+		valid, err := VerifyChecksum(backupPath, checksum)
+		if err != nil {
+			return 0, fmt.Errorf("failed to verify checksum: %w", err)
+		}
+		if !valid {
+			actual, err := CalculateChecksum(backupPath)
+			if err != nil {
+				actual = fmt.Sprintf("<failed to calculate: %v>", err)
+			}
+			return 0, &ChecksumMismatchError{BackupID: backupID, ExpectedChecksum: checksum, ActualChecksum: actual}
+		}
+	}
+
+	dump, err := OpenDump(stor, database, backupID)
+	if err != nil {
+		return 0, err
+	}
+	defer dump.Close()
+
+	if dryRun {
+		return RedactDump(dump, io.Discard, table, cond)
+	}
+
+	compression := metadata.Backup.Compression
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	compressor := NewCompressor(compression)
+	if index := metadata.Backup.Index; index != nil {
+		compressor.SetBlockSize(int(index.BlockSize))
+	}
+
+	// Filter into the compressor through a pipe
+	pipeReader, pipeWriter := io.Pipe()
+	var removed int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var err error
+		removed, err = RedactDump(dump, pipeWriter, table, cond)
+		pipeWriter.CloseWithError(err)
+	}()
+
+	tmpPath := backupPath + ".redact"
+	result, err := compressor.StreamCompress(pipeReader, tmpPath)
+	pipeReader.Close()
+	<-done
+	if err == nil {
+		// Keep the permissions of the original file
+		if info, statErr := os.Stat(backupPath); statErr == nil {
+			err = os.Chmod(tmpPath, info.Mode().Perm())
+		}
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, os.Remove(tmpPath)
+	}
+
+	if err := os.Rename(tmpPath, backupPath); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace backup file: %w", err)
+	}
+
+	metadata.Backup.SizeBytes = result.BytesWritten
+	metadata.Backup.SizeHuman = FormatBytes(result.BytesWritten)
+	metadata.Backup.Checksum = result.Checksum
+	metadata.Backup.ChecksumFile = result.Checksum
+	metadata.Backup.ChecksumRaw = result.RawChecksum
+	metadata.Backup.Index = result.Index
+	metadata.Redactions = append(metadata.Redactions, Redaction{
+		Table:      table,
+		Columns:    cond.Columns(),
+		Rows:       removed,
+		RedactedAt: time.Now(),
+	})
+	if err := stor.SaveMetadata(database, backupID, &metadata); err != nil {
+		return removed, fmt.Errorf("backup rewritten but failed to update metadata: %w", err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,168 @@
+package backup
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redactDump = "-- Table structure for table `users`\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `email` varchar(100) DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `users` VALUES (1,'jane@example.com'),(2,'o\\'neil, (x)'),(3,NULL);\n" +
+	"INSERT INTO `users` VALUES (4,'jane@example.com');\n" +
+	"-- Table structure for table `orders`\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `email` varchar(100) DEFAULT NULL\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `orders` VALUES (1,'jane@example.com');\n"
+
+func TestParseRowCondition(t *testing.T) {
+	cond, err := ParseRowCondition("email = 'jane@example.com' AND `id` IN (1, 4)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "id"}, cond.Columns())
+
+	columns := []string{"id", "email"}
+	email := "jane@example.com"
+	one, two := "1", "2"
+	assert.True(t, cond.matches(columns, []*string{&one, &email}))
+	assert.False(t, cond.matches(columns, []*string{&two, &email}))
+	assert.False(t, cond.matches(columns, []*string{&one, nil}))
+
+	for _, where := range []string{
+		"",
+		"email LIKE 'x%'",
+		"email = 'a' OR id = 1",
+		"id IN (1, 2",
+		"id = other_column",
+	} {
+		_, err := ParseRowCondition(where)
+		assert.True(t, IsValidationError(err), where)
+	}
+}
+
+func TestRedactDump(t *testing.T) {
+	t.Run("removes matching rows of the table only", func(t *testing.T) {
+		cond, err := ParseRowCondition("email = 'jane@example.com'")
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		removed, err := RedactDump(strings.NewReader(redactDump), &out, "users", cond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), removed)
+		assert.Contains(t, out.String(), "INSERT INTO `users` VALUES (2,'o\\'neil, (x)'),(3,NULL);\n")
+		assert.NotContains(t, out.String(), "(4,")
+		assert.Contains(t, out.String(), "INSERT INTO `orders` VALUES (1,'jane@example.com');\n")
+	})
+
+	t.Run("case", func(t *testing.T) {
+		dump := "INSERT INTO `users` (`id`, `email`) VALUES (1,'Jane@Example.com'),(2,'jane@example.com');\n"
+		cond, err := ParseRowCondition("email = 'jane@example.com'")
+		require.NoError(t, err)
+
+		removed, err := RedactDump(strings.NewReader(dump), &bytes.Buffer{}, "users", cond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), removed)
+
+		cond.CaseSensitive = true
+		var out bytes.Buffer
+		removed, err = RedactDump(strings.NewReader(dump), &out, "users", cond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+		assert.Equal(t, "INSERT INTO `users` (`id`, `email`) VALUES (1,'Jane@Example.com');\n", out.String())
+	})
+
+	t.Run("escaped strings", func(t *testing.T) {
+		cond, err := ParseRowCondition(`email = 'o\'neil, (x)'`)
+		require.NoError(t, err)
+
+		removed, err := RedactDump(strings.NewReader(redactDump), &bytes.Buffer{}, "users", cond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+	})
+
+	t.Run("complete inserts", func(t *testing.T) {
+		dump := "INSERT INTO `users` (`email`, `id`) VALUES ('a@example.com',1),('b@example.com',2);\n"
+		cond, err := ParseRowCondition("id = 2")
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		removed, err := RedactDump(strings.NewReader(dump), &out, "users", cond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+		assert.Equal(t, "INSERT INTO `users` (`email`, `id`) VALUES ('a@example.com',1);\n", out.String())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		cond, err := ParseRowCondition("mail = 'x'")
+		require.NoError(t, err)
+
+		_, err = RedactDump(strings.NewReader(redactDump), &bytes.Buffer{}, "users", cond)
+		assert.ErrorContains(t, err, "column mail not found")
+	})
+}
+
+func TestRedactBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	stor, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	backupPath := filepath.Join(dbPath, backupID+".sql.gz")
+	createTestBackupFile(t, backupPath, redactDump)
+
+	metadata := createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip")
+	metadata.Backup.Checksum, err = CalculateChecksum(backupPath)
+	require.NoError(t, err)
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+
+	cond, err := ParseRowCondition("email = 'jane@example.com'")
+	require.NoError(t, err)
+
+	// A dry run only counts
+	removed, err := RedactBackup(stor, "testdb", backupID, "users", cond, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	var unchanged BackupMetadata
+	require.NoError(t, stor.LoadMetadata("testdb", backupID, &unchanged))
+	assert.Empty(t, unchanged.Redactions)
+
+	removed, err = RedactBackup(stor, "testdb", backupID, "users", cond, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	var updated BackupMetadata
+	require.NoError(t, stor.LoadMetadata("testdb", backupID, &updated))
+	require.Len(t, updated.Redactions, 1)
+	assert.Equal(t, "users", updated.Redactions[0].Table)
+	assert.Equal(t, []string{"email"}, updated.Redactions[0].Columns)
+	assert.Equal(t, int64(2), updated.Redactions[0].Rows)
+
+	valid, err := VerifyChecksum(backupPath, updated.Backup.ChecksumFile)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	summary, err := SummarizeBackup(stor, "testdb", backupID, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.Tables["users"].Rows)
+	assert.Equal(t, int64(1), summary.Tables["orders"].Rows)
+
+	// Nothing left to remove leaves the backup alone
+	removed, err = RedactBackup(stor, "testdb", backupID, "users", cond, false)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+	require.NoError(t, stor.LoadMetadata("testdb", backupID, &updated))
+	assert.Len(t, updated.Redactions, 1)
+}
@@ -0,0 +1,162 @@
+package backup
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// backupFileTypes maps the extensions of backup files to their compression.
+var backupFileTypes = []struct {
+	suffix      string
+	compression string
+}{
+	{".sql.gz", CompressionGzip},
+	{".sql.zst", CompressionZstd},
+	{".sql", CompressionNone},
+	{".tar", ""}, // mydumper archive; compression is that of the files inside
+}
+
+// RepairMetadata writes minimal metadata for a backup file in storage whose
+// metadata was lost, so the backup is listed and can be restored again. The
+// backup ID and compression come from the file name, the timestamps from
+// its modification time, and the checksums are computed by reading the
+// whole file, which also proves it can be decompressed. database describes
+// the database the backup belongs to.
+func RepairMetadata(stor *storage.LocalStorage, storageName string, database DatabaseInfo, path string) (*BackupMetadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, &ValidationError{Field: "file", Message: fmt.Sprintf("%s is not a regular file", path)}
+	}
+
+	name := filepath.Base(path)
+	backupID, compression, ok := ParseBackupFileName(name)
+	if !ok {
+		return nil, &ValidationError{Field: "file", Message: fmt.Sprintf("%s is not a backup file (<backup-id>.sql.gz, .sql or .tar)", name)}
+	}
+	if compression == CompressionZstd {
+		return nil, &ValidationError{Field: "file", Message: "zstd backups are not supported"}
+	}
+
+	backupDir := stor.GetBackupDir(storageName, backupID)
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	if want, err := filepath.Abs(backupDir); err != nil || dir != want {
+		return nil, &ValidationError{Field: "file", Message: fmt.Sprintf("%s is not in the backup directory of %s (%s)", name, storageName, backupDir)}
+	}
+	if _, err := os.Stat(stor.GetMetadataPath(storageName, backupID)); err == nil {
+		return nil, &ValidationError{Field: "file", Message: fmt.Sprintf("backup %s already has metadata", backupID)}
+	}
+
+	checksum, err := CalculateChecksum(path)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &BackupMetadata{
+		Version:     MetadataVersion,
+		BackupID:    backupID,
+		Database:    database,
+		CreatedAt:   info.ModTime(),
+		CompletedAt: info.ModTime(),
+		Status:      StatusCompleted,
+		Backup: BackupFileInfo{
+			File:         name,
+			SizeBytes:    info.Size(),
+			SizeHuman:    FormatBytes(info.Size()),
+			Compression:  compression,
+			Checksum:     checksum,
+			ChecksumFile: checksum,
+		},
+		Tool: ToolInfo{
+			Name:    ToolName,
+			Version: ToolVersion,
+		},
+	}
+
+	if strings.HasSuffix(name, ".tar") {
+		// The archive is not compressed itself
+		metadata.Backup.Format = FormatMydumper
+		metadata.Backup.ChecksumRaw = checksum
+		if metadata.Backup.Compression, err = archiveCompression(path); err != nil {
+			return nil, err
+		}
+	} else if metadata.Backup.ChecksumRaw, err = rawChecksum(path, compression); err != nil {
+		return nil, err
+	}
+
+	repairedAt := time.Now()
+	metadata.RepairedAt = &repairedAt
+
+	if err := stor.SaveMetadata(storageName, backupID, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ParseBackupFileName returns the backup ID and compression of a backup
+// file name; the compression of a mydumper archive is empty.
+func ParseBackupFileName(name string) (string, string, bool) {
+	for _, t := range backupFileTypes {
+		if id, ok := strings.CutSuffix(name, t.suffix); ok && id != "" && !strings.HasPrefix(id, ".") {
+			return id, t.compression, true
+		}
+	}
+	return "", "", false
+}
+
+// rawChecksum returns the checksum of the uncompressed content of a backup
+// file.
+func rawChecksum(path, compression string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := NewDecompressor(compression).Decompress(file, hasher); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), nil
+}
+
+// archiveCompression returns the compression of the files in a mydumper
+// archive, reading every entry to prove the archive is complete.
+func archiveCompression(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	compression := CompressionNone
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return compression, nil
+		}
+		if err != nil {
+			return "", WrapCompressionError(path, "failed to read archive", err)
+		}
+		if strings.HasSuffix(header.Name, ".gz") {
+			compression = CompressionGzip
+		}
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return "", WrapCompressionError(path, "failed to read archive", err)
+		}
+	}
+}
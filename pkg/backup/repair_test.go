@@ -0,0 +1,102 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	stor, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	backupPath := filepath.Join(dbPath, backupID+".sql.gz")
+	createTestBackupFile(t, backupPath, "CREATE TABLE test (id INT);\n")
+	modTime := time.Date(2025, 1, 15, 14, 31, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(backupPath, modTime, modTime))
+
+	database := DatabaseInfo{Type: "mysql", Host: "localhost", Port: 3306, Database: "testdb"}
+	metadata, err := RepairMetadata(stor, "testdb", database, backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, backupID, metadata.BackupID)
+	assert.Equal(t, CompressionGzip, metadata.Backup.Compression)
+	assert.True(t, metadata.CreatedAt.Equal(modTime))
+	assert.NotNil(t, metadata.RepairedAt)
+
+	valid, err := VerifyChecksum(backupPath, metadata.Backup.ChecksumFile)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.NotEqual(t, metadata.Backup.ChecksumFile, metadata.Backup.ChecksumRaw)
+
+	// The backup is listed again
+	backups, err := stor.ListBackups("testdb")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, backupID, backups[0].BackupID)
+	assert.Equal(t, StatusCompleted, backups[0].Status)
+
+	_, err = RepairMetadata(stor, "testdb", database, backupPath)
+	assert.ErrorContains(t, err, "already has metadata")
+}
+
+func TestRepairMetadataRejects(t *testing.T) {
+	tmpDir := t.TempDir()
+	stor, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	database := DatabaseInfo{Database: "testdb"}
+
+	t.Run("not a backup file name", func(t *testing.T) {
+		path := filepath.Join(dbPath, "notes.txt")
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+		_, err := RepairMetadata(stor, "testdb", database, path)
+		assert.True(t, IsValidationError(err))
+	})
+
+	t.Run("outside the backup directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "2025-01-15-143022.sql.gz")
+		createTestBackupFile(t, path, "SELECT 1;")
+		_, err := RepairMetadata(stor, "testdb", database, path)
+		assert.ErrorContains(t, err, "not in the backup directory")
+	})
+
+	t.Run("corrupt backup", func(t *testing.T) {
+		path := filepath.Join(dbPath, "2025-01-16-143022.sql.gz")
+		require.NoError(t, os.WriteFile(path, []byte("not gzip"), 0644))
+		_, err := RepairMetadata(stor, "testdb", database, path)
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dbPath, "2025-01-16-143022.meta.json"))
+	})
+}
+
+func TestParseBackupFileName(t *testing.T) {
+	tests := []struct {
+		name        string
+		backupID    string
+		compression string
+		ok          bool
+	}{
+		{"2025-01-15-143022.sql.gz", "2025-01-15-143022", CompressionGzip, true},
+		{"2025-01-15-143022.sql", "2025-01-15-143022", CompressionNone, true},
+		{"2025-01-15-143022.tar", "2025-01-15-143022", "", true},
+		{".sql.gz", "", "", false},
+		{".2025-01-15-143022.meta.json.tmp-1", "", "", false},
+		{"2025-01-15-143022.meta.json", "", "", false},
+	}
+	for _, tt := range tests {
+		backupID, compression, ok := ParseBackupFileName(tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.backupID, backupID, tt.name)
+		assert.Equal(t, tt.compression, compression, tt.name)
+	}
+}
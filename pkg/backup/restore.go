@@ -0,0 +1,837 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// RestoreService orchestrates restore operations.
+type RestoreService struct {
+	client  Introspector
+	engine  Engine
+	storage *storage.LocalStorage
+	config  *mysql.Config
+	verbose bool
+
+	// logOutput receives debug messages and the restore tool's stderr
+	logOutput io.Writer
+
+	// progress receives the bytes of the backup read so far, if set
+	progress ProgressFunc
+}
+
+// NewRestoreService creates a new restore service using the MySQL engine.
+func NewRestoreService(client Introspector, stor *storage.LocalStorage, config *mysql.Config) *RestoreService {
+	return &RestoreService{
+		client:    client,
+		engine:    defaultEngine(),
+		storage:   stor,
+		config:    config,
+		verbose:   false,
+		logOutput: os.Stdout,
+	}
+}
+
+// SetLogOutput sets where debug messages are written.
+func (s *RestoreService) SetLogOutput(w io.Writer) {
+	s.logOutput = w
+}
+
+// SetEngine sets the database engine used to restore backups.
+func (s *RestoreService) SetEngine(engine Engine) {
+	s.engine = engine
+}
+
+// SetVerbose enables or disables verbose logging.
+func (s *RestoreService) SetVerbose(verbose bool) {
+	s.verbose = verbose
+}
+
+// SetProgress reports the bytes of the backup file or source read so far
+// to fn while restoring. These are compressed bytes for compressed backups,
+// so they can be compared with the size of the backup file.
+func (s *RestoreService) SetProgress(fn ProgressFunc) {
+	s.progress = fn
+}
+
+// Restore performs a complete restore operation.
+func (s *RestoreService) Restore(options *RestoreOptions) (*RestoreResult, error) {
+	if options == nil {
+		return nil, WrapRestoreError("", "restore options are required", fmt.Errorf("nil options"))
+	}
+
+	startTime := time.Now()
+
+	// Determine target database
+	targetDatabase := options.Database
+	if options.TargetDatabase != "" {
+		targetDatabase = options.TargetDatabase
+	}
+
+	if targetDatabase == "" {
+		return nil, WrapRestoreError("", "target database is required", fmt.Errorf("empty database name"))
+	}
+	if err := ValidateSQLMode(options.SQLMode); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid sql_mode", err)
+	}
+	if err := ValidateSessionAction(options.SessionAction); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid session action", err)
+	}
+	if err := options.Sample.Validate(); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid sample", err)
+	}
+
+	// Initialize result
+	result := &RestoreResult{
+		TargetDatabase: targetDatabase,
+		StartedAt:      startTime,
+		Status:         RestoreStatusFailed,
+	}
+
+	// Get storage name (config name if available, otherwise database name)
+	storageName := getStorageNameForRestore(options)
+
+	// Load backup metadata
+	backupEntry, err := s.loadBackupMetadata(storageName, options.BackupID)
+	if err != nil {
+		result.Error = err
+		return nil, err
+	}
+
+	result.BackupID = backupEntry.BackupID
+
+	// Load full metadata to get compression info
+	var metadata BackupMetadata
+	err = s.storage.LoadMetadata(storageName, backupEntry.BackupID, &metadata)
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "failed to load backup metadata", err)
+		return nil, result.Error
+	}
+
+	// Validate backup file exists
+	backupPath := backupEntry.FilePath
+	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+		result.Error = &BackupNotFoundError{
+			BackupID: backupEntry.BackupID,
+			Database: storageName,
+		}
+		return nil, result.Error
+	}
+
+	// Multi-database backups restore into the databases they were taken from
+	schemas, err := SelectSchemas(metadata.Database, options.Schemas)
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "invalid database selection", err)
+		return nil, result.Error
+	}
+	schemaSet := len(metadata.Database.Databases) > 0
+	if schemaSet && (targetDatabase != options.Database || options.Table != "") {
+		result.Error = WrapRestoreError(targetDatabase, "backups of multiple databases cannot be restored into another database or by table", fmt.Errorf("select databases with --schemas instead"))
+		return nil, result.Error
+	}
+
+	// mydumper archives are loaded with myloader, which has no native path
+	if metadata.Backup.Format == FormatMydumper && options.Engine == RestoreEngineNative {
+		result.Error = WrapRestoreError(targetDatabase, "mydumper backups cannot be restored natively", fmt.Errorf("use --engine %s", RestoreEngineMySQL))
+		return nil, result.Error
+	}
+	if metadata.Backup.Format == FormatMydumper && options.Table != "" {
+		result.Error = WrapRestoreError(targetDatabase, "single tables cannot be restored from mydumper backups", fmt.Errorf("restore the whole backup with --to instead"))
+		return nil, result.Error
+	}
+	if metadata.Backup.Format == FormatMydumper && len(options.ExcludeTables) > 0 {
+		result.Error = WrapRestoreError(targetDatabase, "tables cannot be excluded from mydumper backups", fmt.Errorf("restore the whole backup instead"))
+		return nil, result.Error
+	}
+	if metadata.Backup.Format == FormatMydumper && options.SchemaOnly {
+		result.Error = WrapRestoreError(targetDatabase, "mydumper backups cannot be restored without data", fmt.Errorf("restore the whole backup instead"))
+		return nil, result.Error
+	}
+	if metadata.Backup.Format == FormatMydumper && options.Sample.enabled() {
+		result.Error = WrapRestoreError(targetDatabase, "mydumper backups cannot be sampled", fmt.Errorf("restore the whole backup instead"))
+		return nil, result.Error
+	}
+	if options.Table != "" && len(options.ExcludeTables) > 0 {
+		result.Error = WrapRestoreError(targetDatabase, "cannot restore a single table and exclude tables", fmt.Errorf("use either --table or --exclude-tables"))
+		return nil, result.Error
+	}
+	if options.Atomic && (schemaSet || options.Table != "" || metadata.Backup.Format == FormatMydumper) {
+		result.Error = WrapRestoreError(targetDatabase, "atomic restores need a whole SQL backup of a single database", fmt.Errorf("restore without --atomic"))
+		return nil, result.Error
+	}
+	if options.Atomic {
+		if err := checkAtomic(s.client); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "atomic restore not supported", err)
+			return nil, result.Error
+		}
+	}
+
+	// Verify checksum up front when requested, for dry runs, and for
+	// mydumper archives; otherwise it is verified while streaming
+	MigrateChecksums(&metadata)
+	expectedChecksum := metadata.Backup.FileChecksum()
+	verifyFirst := options.VerifyFirst || options.DryRun || metadata.Backup.Format == FormatMydumper
+	if expectedChecksum != "" && verifyFirst {
+		valid, err := VerifyChecksum(backupPath, expectedChecksum)
+		if err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to verify checksum", err)
+			return nil, result.Error
+		}
+		if !valid {
+			// Calculate actual checksum for error reporting
+			actualChecksum, calcErr := CalculateChecksum(backupPath)
+			if calcErr != nil {
+				// If we can't calculate checksum, still report mismatch but with error note
+				actualChecksum = fmt.Sprintf("<failed to calculate: %v>", calcErr)
+			}
+			result.Error = &ChecksumMismatchError{
+				BackupID:         backupEntry.BackupID,
+				ExpectedChecksum: expectedChecksum,
+				ActualChecksum:   actualChecksum,
+			}
+			return nil, result.Error
+		}
+	}
+
+	// Refuse a server that cannot hold the backup's text
+	if !options.IgnoreCharset {
+		if err := CheckCharset(s.client, metadata.Database); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "character set check failed", fmt.Errorf("%w; pass --ignore-charset to restore anyway", err))
+			return nil, result.Error
+		}
+	}
+
+	// Check if database exists; multi-database dumps create their own
+	dbExists := schemaSet
+	if !schemaSet {
+		dbExists, err = s.client.DatabaseExists(targetDatabase)
+		if err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to check if database exists", err)
+			return nil, result.Error
+		}
+	}
+
+	// Refuse, or clear, connections using the databases about to be written
+	if dbExists {
+		databases := []string{targetDatabase}
+		if schemaSet {
+			databases = schemas
+		}
+		if err := s.checkSessions(databases, options); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "active connections check failed", err)
+			return nil, result.Error
+		}
+	}
+
+	// Create database if needed
+	if !dbExists {
+		if options.CreateDatabase {
+			if s.verbose {
+				fmt.Fprintf(s.logOutput, "[DEBUG] Creating database %s\n", targetDatabase)
+			}
+			if err := s.client.CreateDatabase(targetDatabase); err != nil {
+				result.Error = WrapRestoreError(targetDatabase, "failed to create database", err)
+				return nil, result.Error
+			}
+		} else {
+			result.Error = WrapRestoreError(targetDatabase, "database does not exist", fmt.Errorf("use --create-db to create it"))
+			return nil, result.Error
+		}
+	}
+
+	// Note: backup-first functionality is handled at the CLI layer (cmd/cadangkan/restore.go)
+	// This keeps the service layer focused on restore logic while the CLI orchestrates
+	// the backup-before-restore workflow with proper user feedback.
+
+	// Dry-run: validate without executing, scanning the dump if asked
+	if options.DryRun {
+		if options.Plan && metadata.Backup.Format != FormatMydumper {
+			if result.Plan, err = s.planBackup(backupPath, &metadata, options, schemas); err != nil {
+				result.Error = WrapRestoreError(targetDatabase, "failed to scan backup", err)
+				return nil, result.Error
+			}
+		}
+		result.Status = RestoreStatusCompleted
+		result.CompletedAt = time.Now()
+		result.Duration = result.CompletedAt.Sub(result.StartedAt)
+		return result, nil
+	}
+
+	// Multi-file mydumper backups are restored with myloader
+	if metadata.Backup.Format == FormatMydumper {
+		if err := s.restoreMydumper(backupPath, targetDatabase); err != nil {
+			result.Error = err
+			return nil, result.Error
+		}
+
+		result.Status = RestoreStatusCompleted
+		result.CompletedAt = time.Now()
+		result.Duration = result.CompletedAt.Sub(result.StartedAt)
+		return result, nil
+	}
+
+	// Decompress and restore
+	compression := metadata.Backup.Compression
+	if compression == "" {
+		compression = CompressionGzip // Default
+	}
+
+	// A single table of an indexed backup is read from its blocks without
+	// decompressing the rest of the file, which is then not hashed: its
+	// checksum is only verified with VerifyFirst
+	indexed := options.Table != "" && metadata.Backup.Index != nil
+	if indexed {
+		if _, _, ok := metadata.Backup.Index.TableRange(options.Table); !ok {
+			result.Error = WrapRestoreError(targetDatabase, "table not found in backup", fmt.Errorf("no table %s in backup %s", options.Table, backupEntry.BackupID))
+			return nil, result.Error
+		}
+	}
+
+	// Open backup file
+	var backupFile *os.File
+	if !indexed {
+		if backupFile, err = os.Open(backupPath); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to open backup file", err)
+			return nil, result.Error
+		}
+		defer backupFile.Close()
+	}
+
+	// Create MySQL restorer with config that includes target database
+	// The restorer needs the database name for the mysql command
+	connectDatabase := targetDatabase
+	if schemaSet {
+		connectDatabase = schemaSetConnectDatabase
+	}
+
+	// Atomic restores load a shadow database that is swapped in once the
+	// restore is verified, so a failure leaves the target as it was
+	var shadow string
+	if options.Atomic {
+		if shadow, err = s.createShadow(targetDatabase, startTime); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to create shadow database", err)
+			return nil, result.Error
+		}
+		defer s.client.DropDatabase(shadow)
+		connectDatabase = shadow
+	}
+	restorerConfig := &mysql.Config{
+		Host:      s.config.Host,
+		Port:      s.config.Port,
+		User:      s.config.User,
+		Password:  s.config.Password,
+		Database:  connectDatabase, // Target database for restore command
+		Timeout:   s.config.Timeout,
+		SSL:       s.config.SSL,
+		MysqlPath: s.config.MysqlPath,
+	}
+	restorer := s.newRestorer(restorerConfig, options)
+	s.attachStderrLogger(restorer)
+	applyFastMode(restorer, options)
+	applyCharset(restorer, metadata.Database)
+	sqlMode, err := ResolveSQLMode(options.SQLMode, metadata.Database)
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "invalid sql_mode in backup metadata", err)
+		return nil, result.Error
+	}
+	applySQLMode(restorer, sqlMode)
+
+	// Restore with decompression
+	var cmdLogger func(string)
+	if s.verbose {
+		cmdLogger = func(cmd string) {
+			fmt.Fprintf(s.logOutput, "[DEBUG] %s\n", cmd)
+		}
+	}
+
+	var hasher, rawHasher hash.Hash
+	var sqlReader io.Reader
+	if indexed {
+		tableReader, err := OpenTable(backupPath, metadata.Backup.Index, options.Table)
+		if err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to read table from backup", err)
+			return nil, result.Error
+		}
+		defer tableReader.Close()
+		sqlReader = tableReader
+	} else {
+		// Hash the file as it is read so the backup is only read once
+		input := ProgressReader(backupFile, s.progress)
+		if expectedChecksum != "" && !verifyFirst {
+			hasher = sha256.New()
+			input = io.TeeReader(input, hasher)
+		}
+
+		decompressedReader, err := NewDecompressor(compression).DecompressToReader(input)
+		if err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to decompress backup", err)
+			return nil, result.Error
+		}
+		defer decompressedReader.Close()
+
+		// Also hash the decompressed SQL when its checksum is known
+		sqlReader = decompressedReader
+		if metadata.Backup.ChecksumRaw != "" && !verifyFirst {
+			rawHasher = sha256.New()
+			sqlReader = io.TeeReader(decompressedReader, rawHasher)
+		}
+	}
+
+	restoreInput, tableFilter := filterDump(sqlReader, options, metadata.Database, schemas)
+
+	// Execute restore
+	if err := restorer.RestoreWithCommand(connectDatabase, restoreInput, cmdLogger); err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "restore failed", err)
+		return nil, result.Error
+	}
+	if tableFilter != nil {
+		// The table may only be known to be missing once the dump is read
+		if _, err := io.Copy(io.Discard, tableFilter); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to read backup", err)
+			return nil, result.Error
+		}
+		if !tableFilter.Found() {
+			result.Error = WrapRestoreError(targetDatabase, "table not found in backup", fmt.Errorf("no table %s in backup %s", options.Table, backupEntry.BackupID))
+			return nil, result.Error
+		}
+	}
+
+	// The data has already been applied, so a mismatch can only be reported
+	if rawHasher != nil {
+		// Include any SQL the restorer did not consume
+		if _, err := io.Copy(io.Discard, sqlReader); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to verify checksum", err)
+			return nil, result.Error
+		}
+		if err := checkStreamedChecksum(backupEntry.BackupID, metadata.Backup.ChecksumRaw, rawHasher); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "checksum mismatch detected after restore; restored data may be corrupt", err)
+			return nil, result.Error
+		}
+	}
+	if hasher != nil {
+		// Include any trailing bytes the decompressor did not consume
+		if _, err := io.Copy(hasher, backupFile); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to verify checksum", err)
+			return nil, result.Error
+		}
+		if err := checkStreamedChecksum(backupEntry.BackupID, expectedChecksum, hasher); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "checksum mismatch detected after restore; restored data may be corrupt", err)
+			return nil, result.Error
+		}
+	}
+
+	if shadow != "" {
+		if err := s.swapShadow(shadow, targetDatabase, startTime); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "atomic swap failed; the database was left unchanged", err)
+			return nil, result.Error
+		}
+	}
+
+	// Success
+	result.Status = RestoreStatusCompleted
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(result.StartedAt)
+
+	return result, nil
+}
+
+// RestoreFromSource restores a dump that lives outside managed storage.
+// The checksum in options (if any) is verified before the database is touched.
+func (s *RestoreService) RestoreFromSource(source *RestoreSource, options *RestoreOptions) (*RestoreResult, error) {
+	if options == nil {
+		return nil, WrapRestoreError("", "restore options are required", fmt.Errorf("nil options"))
+	}
+
+	targetDatabase := options.Database
+	if options.TargetDatabase != "" {
+		targetDatabase = options.TargetDatabase
+	}
+
+	if targetDatabase == "" {
+		return nil, WrapRestoreError("", "target database is required", fmt.Errorf("empty database name"))
+	}
+	if err := ValidateSQLMode(options.SQLMode); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid sql_mode", err)
+	}
+	if err := ValidateSessionAction(options.SessionAction); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid session action", err)
+	}
+	if err := options.Sample.Validate(); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid sample", err)
+	}
+	if _, err := SelectSchemas(DatabaseInfo{}, options.Schemas); err != nil {
+		return nil, WrapRestoreError(targetDatabase, "invalid database selection", err)
+	}
+	if options.Atomic {
+		if options.Table != "" {
+			return nil, WrapRestoreError(targetDatabase, "atomic restores need a whole SQL backup of a single database", fmt.Errorf("restore without --atomic"))
+		}
+		if err := checkAtomic(s.client); err != nil {
+			return nil, WrapRestoreError(targetDatabase, "atomic restore not supported", err)
+		}
+	}
+
+	result := &RestoreResult{
+		Source:         source.Location,
+		TargetDatabase: targetDatabase,
+		StartedAt:      time.Now(),
+		Status:         RestoreStatusFailed,
+	}
+
+	// Verify checksum before anything reaches the database
+	reader, cleanup, err := verifiedReader(source, options.Checksum)
+	defer cleanup()
+	if err != nil {
+		if _, ok := err.(*ChecksumMismatchError); ok {
+			result.Error = err
+		} else {
+			result.Error = WrapRestoreError(targetDatabase, "failed to verify checksum", err)
+		}
+		return nil, result.Error
+	}
+
+	// Validate target database
+	dbExists, err := s.client.DatabaseExists(targetDatabase)
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "failed to check if database exists", err)
+		return nil, result.Error
+	}
+	if !dbExists && !options.CreateDatabase {
+		result.Error = WrapRestoreError(targetDatabase, "database does not exist", fmt.Errorf("use --create-db to create it"))
+		return nil, result.Error
+	}
+	if dbExists {
+		if err := s.checkSessions([]string{targetDatabase}, options); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "active connections check failed", err)
+			return nil, result.Error
+		}
+	}
+
+	if options.DryRun {
+		if options.Plan {
+			if result.Plan, err = s.planSource(reader, source, options); err != nil {
+				result.Error = WrapRestoreError(targetDatabase, "failed to scan source", err)
+				return nil, result.Error
+			}
+		}
+		result.Status = RestoreStatusCompleted
+		result.CompletedAt = time.Now()
+		result.Duration = result.CompletedAt.Sub(result.StartedAt)
+		return result, nil
+	}
+
+	if !dbExists {
+		if s.verbose {
+			fmt.Fprintf(s.logOutput, "[DEBUG] Creating database %s\n", targetDatabase)
+		}
+		if err := s.client.CreateDatabase(targetDatabase); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to create database", err)
+			return nil, result.Error
+		}
+	}
+
+	decompressedReader, err := s.decompressSource(reader, source, options)
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "failed to decompress backup", err)
+		return nil, result.Error
+	}
+	defer decompressedReader.Close()
+
+	var cmdLogger func(string)
+	if s.verbose {
+		cmdLogger = func(cmd string) {
+			fmt.Fprintf(s.logOutput, "[DEBUG] %s\n", cmd)
+		}
+	}
+
+	loadDatabase := targetDatabase
+	var shadow string
+	if options.Atomic {
+		if shadow, err = s.createShadow(targetDatabase, result.StartedAt); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "failed to create shadow database", err)
+			return nil, result.Error
+		}
+		defer s.client.DropDatabase(shadow)
+		loadDatabase = shadow
+	}
+
+	restorerConfig := &mysql.Config{
+		Host:      s.config.Host,
+		Port:      s.config.Port,
+		User:      s.config.User,
+		Password:  s.config.Password,
+		Database:  loadDatabase,
+		Timeout:   s.config.Timeout,
+		SSL:       s.config.SSL,
+		MysqlPath: s.config.MysqlPath,
+	}
+	restorer := s.newRestorer(restorerConfig, options)
+	s.attachStderrLogger(restorer)
+	applyFastMode(restorer, options)
+	sqlMode, err := ResolveSQLMode(options.SQLMode, DatabaseInfo{})
+	if err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "invalid sql_mode", err)
+		return nil, result.Error
+	}
+	applySQLMode(restorer, sqlMode)
+
+	if err := restorer.RestoreWithCommand(loadDatabase, rewriteDump(decompressedReader, options), cmdLogger); err != nil {
+		result.Error = WrapRestoreError(targetDatabase, "restore failed", err)
+		return nil, result.Error
+	}
+	if shadow != "" {
+		if err := s.swapShadow(shadow, targetDatabase, result.StartedAt); err != nil {
+			result.Error = WrapRestoreError(targetDatabase, "atomic swap failed; the database was left unchanged", err)
+			return nil, result.Error
+		}
+	}
+
+	result.Status = RestoreStatusCompleted
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(result.StartedAt)
+
+	return result, nil
+}
+
+// checkSessions applies the session action of the options to the
+// connections using databases. Dry runs never kill them.
+func (s *RestoreService) checkSessions(databases []string, options *RestoreOptions) error {
+	action := options.SessionAction
+	if options.DryRun && action == SessionActionKill {
+		action = SessionActionWarn
+	}
+	sessions, err := CheckSessions(s.client, databases, action)
+	if s.verbose {
+		for _, session := range sessions {
+			fmt.Fprintf(s.logOutput, "[DEBUG] %s\n", DescribeSession(session))
+		}
+	}
+	return err
+}
+
+// decompressSource returns a reader of the SQL of a source. Compression is
+// detected from the data when not known from the options or name.
+func (s *RestoreService) decompressSource(reader io.Reader, source *RestoreSource, options *RestoreOptions) (io.ReadCloser, error) {
+	buffered := bufio.NewReaderSize(ProgressReader(reader, s.progress), DefaultBufferSize)
+	compression := options.Compression
+	if compression == "" {
+		compression = source.Compression
+	}
+	if compression == "" {
+		compression = sniffCompression(buffered)
+	}
+	return NewDecompressor(compression).DecompressToReader(buffered)
+}
+
+// planBackup scans the dump of a stored backup as it would be restored
+// with options.
+func (s *RestoreService) planBackup(path string, metadata *BackupMetadata, options *RestoreOptions, schemas []string) (*RestorePlan, error) {
+	if options.Table != "" && metadata.Backup.Index != nil {
+		if _, _, ok := metadata.Backup.Index.TableRange(options.Table); !ok {
+			return nil, fmt.Errorf("no table %s in backup %s", options.Table, metadata.BackupID)
+		}
+		tableReader, err := OpenTable(path, metadata.Backup.Index, options.Table)
+		if err != nil {
+			return nil, err
+		}
+		defer tableReader.Close()
+		input, _ := filterDump(tableReader, options, metadata.Database, schemas)
+		return ScanDump(input)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	compression := metadata.Backup.Compression
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	sqlReader, err := NewDecompressor(compression).DecompressToReader(ProgressReader(file, s.progress))
+	if err != nil {
+		return nil, err
+	}
+	defer sqlReader.Close()
+
+	input, tableFilter := filterDump(sqlReader, options, metadata.Database, schemas)
+	plan, err := ScanDump(input)
+	if err != nil {
+		return nil, err
+	}
+	if tableFilter != nil && !tableFilter.Found() {
+		return nil, fmt.Errorf("no table %s in backup %s", options.Table, metadata.BackupID)
+	}
+	return plan, nil
+}
+
+// planSource scans the dump of a source as it would be restored with
+// options.
+func (s *RestoreService) planSource(reader io.Reader, source *RestoreSource, options *RestoreOptions) (*RestorePlan, error) {
+	sqlReader, err := s.decompressSource(reader, source, options)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlReader.Close()
+	return ScanDump(rewriteDump(sqlReader, options))
+}
+
+// filterDump wraps the SQL dump of a backup in the filters and rewrites the
+// options ask for: only the requested table, renamed if asked, and only
+// the selected databases of a multi-database backup. The table filter, if
+// any, tells whether the table was found once the dump has been read.
+func filterDump(r io.Reader, options *RestoreOptions, info DatabaseInfo, schemas []string) (io.Reader, *TableFilter) {
+	var tableFilter *TableFilter
+	if options.Table != "" {
+		tableFilter = NewTableFilter(r, options.Table, options.TableAs)
+		r = tableFilter
+	}
+	if len(schemas) < len(info.Databases) {
+		r = NewSchemaFilter(r, schemas)
+	}
+	return rewriteDump(r, options), tableFilter
+}
+
+// newRestorer returns the restorer of the engine chosen in options.
+func (s *RestoreService) newRestorer(config *mysql.Config, options *RestoreOptions) Restorer {
+	if options.Engine == RestoreEngineNative {
+		return NewNativeRestorer(config)
+	}
+	return s.engine.NewRestorer(MySQLConnection(config))
+}
+
+// ValidateRestoreEngine checks the restore engine of the options.
+func ValidateRestoreEngine(engine string) error {
+	switch engine {
+	case "", RestoreEngineMySQL, RestoreEngineNative:
+		return nil
+	}
+	return fmt.Errorf("unsupported restore engine: %s (use '%s' or '%s')", engine, RestoreEngineMySQL, RestoreEngineNative)
+}
+
+// attachStderrLogger shows the restore tool's stderr as it runs in verbose mode.
+func (s *RestoreService) attachStderrLogger(restorer Restorer) {
+	if sl, ok := restorer.(StderrLogger); ok && s.verbose {
+		sl.SetStderrLogger(func(line string) {
+			fmt.Fprintf(s.logOutput, "[STDERR] %s\n", line)
+		})
+	}
+}
+
+// applyFastMode turns off fast import mode when the options ask for it.
+func applyFastMode(restorer Restorer, options *RestoreOptions) {
+	if fm, ok := restorer.(FastModeSetter); ok {
+		fm.SetFastMode(!options.DisableFastMode)
+	}
+}
+
+// rewriteDump wraps a SQL dump in the table exclusions and rewrites the
+// options ask for.
+func rewriteDump(r io.Reader, options *RestoreOptions) io.Reader {
+	if len(options.ExcludeTables) > 0 {
+		r = NewTableExcluder(r, options.ExcludeTables)
+	}
+	if options.SchemaOnly {
+		r = NewDataFilter(r, options.DataTables)
+	}
+	if options.Sample.enabled() {
+		r = NewRowSampler(r, options.Sample)
+	}
+	rewrite := DumpRewriteOptions{
+		SkipDefiners: options.SkipDefiners,
+		DeferViews:   options.DeferViews,
+	}
+	if !rewrite.enabled() {
+		return r
+	}
+	return NewDumpRewriter(r, rewrite)
+}
+
+// checkStreamedChecksum compares the digest of a streamed restore with the
+// checksum recorded in metadata.
+func checkStreamedChecksum(backupID, expected string, hasher hash.Hash) error {
+	if actual := fmt.Sprintf("sha256:%x", hasher.Sum(nil)); actual != expected {
+		return &ChecksumMismatchError{
+			BackupID:         backupID,
+			ExpectedChecksum: expected,
+			ActualChecksum:   actual,
+		}
+	}
+	return nil
+}
+
+// restoreMydumper extracts a mydumper archive and loads it with myloader.
+func (s *RestoreService) restoreMydumper(archivePath, targetDatabase string) error {
+	tmpDir, err := CurrentWorkDir().MkdirTemp("myloader-")
+	if err != nil {
+		return WrapRestoreError(targetDatabase, "failed to create temporary directory", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := ExtractArchive(archivePath, tmpDir); err != nil {
+		return WrapRestoreError(targetDatabase, "failed to extract backup archive", err)
+	}
+
+	var cmdLogger func(string)
+	if s.verbose {
+		cmdLogger = func(cmd string) {
+			fmt.Fprintf(s.logOutput, "[DEBUG] %s\n", cmd)
+		}
+	}
+
+	restorer := NewMyloaderRestorer(s.config)
+	if err := restorer.RestoreDirectory(targetDatabase, tmpDir, 0, cmdLogger); err != nil {
+		return WrapRestoreError(targetDatabase, "restore failed", err)
+	}
+
+	return nil
+}
+
+// loadBackupMetadata loads backup metadata (latest or specific).
+func (s *RestoreService) loadBackupMetadata(storageName, backupID string) (*storage.BackupListEntry, error) {
+	if backupID == "" {
+		// Get latest backup
+		entry, err := s.storage.GetLatestBackup(storageName)
+		if err != nil {
+			return nil, &BackupNotFoundError{
+				BackupID: "latest",
+				Database: storageName,
+			}
+		}
+		return entry, nil
+	}
+
+	// Get specific backup
+	backups, err := s.storage.ListBackups(storageName)
+	if err != nil {
+		return nil, WrapRestoreError(storageName, "failed to list backups", err)
+	}
+
+	for _, backup := range backups {
+		if backup.BackupID == backupID {
+			return &backup, nil
+		}
+	}
+
+	return nil, &BackupNotFoundError{
+		BackupID: backupID,
+		Database: storageName,
+	}
+}
+
+// getStorageNameForRestore returns the storage name for restore operations.
+func getStorageNameForRestore(options *RestoreOptions) string {
+	if options.ConfigName != "" {
+		return options.ConfigName
+	}
+	return options.Database
+}
@@ -8,8 +8,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/storage"
 	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -379,6 +379,81 @@ func TestRestoreServiceRestore(t *testing.T) {
 	})
 }
 
+func TestRestoreServiceStreamingChecksum(t *testing.T) {
+	setup := func(t *testing.T, checksum func(string) string) (*RestoreService, *fakeRestorer, *RestoreOptions) {
+		tmpDir := t.TempDir()
+		localStorage, err := storage.NewLocalStorage(tmpDir)
+		require.NoError(t, err)
+
+		backupID := "2025-01-15-143022"
+		dbPath := filepath.Join(tmpDir, "testdb")
+		require.NoError(t, os.MkdirAll(dbPath, 0755))
+
+		backupFile := filepath.Join(dbPath, backupID+".sql.gz")
+		createTestBackupFile(t, backupFile, "CREATE TABLE test (id INT);")
+
+		metadata := createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip")
+		metadata.Backup.Checksum = checksum(backupFile)
+		saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+
+		restorer := &fakeRestorer{}
+		service := newSourceRestoreService(restorer, "testdb")
+		service.storage = localStorage
+		options := &RestoreOptions{Database: "testdb", BackupID: backupID, ConfigName: "testdb"}
+		return service, restorer, options
+	}
+
+	t.Run("valid checksum", func(t *testing.T) {
+		service, restorer, options := setup(t, func(path string) string {
+			checksum, err := CalculateChecksum(path)
+			require.NoError(t, err)
+			return checksum
+		})
+
+		result, err := service.Restore(options)
+		require.NoError(t, err)
+		assert.Equal(t, RestoreStatusCompleted, result.Status)
+		assert.Equal(t, "CREATE TABLE test (id INT);", restorer.sql)
+	})
+
+	t.Run("mismatch reported after restore", func(t *testing.T) {
+		service, restorer, options := setup(t, func(string) string { return "sha256:wrong" })
+
+		_, err := service.Restore(options)
+		assert.True(t, IsChecksumMismatchError(err))
+		assert.True(t, IsRestoreError(err))
+		assert.Contains(t, err.Error(), "after restore")
+		assert.NotEmpty(t, restorer.sql)
+	})
+
+	t.Run("raw checksum mismatch", func(t *testing.T) {
+		service, _, options := setup(t, func(path string) string {
+			checksum, err := CalculateChecksum(path)
+			require.NoError(t, err)
+			return checksum
+		})
+
+		var metadata BackupMetadata
+		require.NoError(t, service.storage.LoadMetadata("testdb", options.BackupID, &metadata))
+		metadata.Backup.ChecksumRaw = "sha256:wrong"
+		require.NoError(t, service.storage.SaveMetadata("testdb", options.BackupID, &metadata))
+
+		_, err := service.Restore(options)
+		var checksumErr *ChecksumMismatchError
+		require.ErrorAs(t, err, &checksumErr)
+		assert.Equal(t, "sha256:wrong", checksumErr.ExpectedChecksum)
+	})
+
+	t.Run("verify first aborts before restore", func(t *testing.T) {
+		service, restorer, options := setup(t, func(string) string { return "sha256:wrong" })
+		options.VerifyFirst = true
+
+		_, err := service.Restore(options)
+		assert.True(t, IsChecksumMismatchError(err))
+		assert.Empty(t, restorer.sql)
+	})
+}
+
 func TestRestoreServiceLoadBackupMetadata(t *testing.T) {
 	t.Run("latest backup", func(t *testing.T) {
 		mockClient := mysql.NewMockClient()
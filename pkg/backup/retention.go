@@ -5,8 +5,7 @@ import (
 	"sort"
 	"time"
 
-	"github.com/erickhilda/cadangkan/internal/config"
-	"github.com/erickhilda/cadangkan/internal/storage"
+	"github.com/erickhilda/cadangkan/pkg/storage"
 )
 
 // RetentionService manages backup retention policies.
@@ -30,6 +29,7 @@ const (
 	CategoryMonthly
 	CategoryKeep // Always keep
 	CategoryDelete
+	CategoryLocked // Protected by a backup lock
 )
 
 // CategorizedBackup represents a backup with its category.
@@ -46,8 +46,16 @@ type CleanupResult struct {
 	DryRun        bool
 }
 
-// ApplyRetentionPolicy applies retention policy and returns backups to delete.
-func (s *RetentionService) ApplyRetentionPolicy(databaseName string, policy *config.RetentionPolicy, dryRun bool) (*CleanupResult, error) {
+// RetentionPolicy describes which backups of a database to keep.
+type RetentionPolicy struct {
+	Daily   int  // Keep last N daily backups
+	Weekly  int  // Keep last N weekly backups (Sunday)
+	Monthly int  // Keep last N monthly backups (1st of month)
+	KeepAll bool // Never delete backups
+}
+
+// ApplyRetention applies retention policy and returns backups to delete.
+func (s *RetentionService) ApplyRetention(databaseName string, policy *RetentionPolicy, dryRun bool) (*CleanupResult, error) {
 	// Get all backups for this database
 	backups, err := s.storage.ListBackups(databaseName)
 	if err != nil {
@@ -72,7 +80,7 @@ func (s *RetentionService) ApplyRetentionPolicy(databaseName string, policy *con
 	}
 
 	// Sort backups by date (newest first)
-	sort.Slice(backups, func(i, j int) bool {
+	sort.SliceStable(backups, func(i, j int) bool {
 		return backups[i].CreatedAt.After(backups[j].CreatedAt)
 	})
 
@@ -87,7 +95,13 @@ func (s *RetentionService) ApplyRetentionPolicy(databaseName string, policy *con
 		DryRun:         dryRun,
 	}
 
+	now := time.Now()
 	for _, cb := range categorized {
+		// Locked backups are never pruned
+		if cb.Category == CategoryDelete && cb.Backup.Lock.Active(now) {
+			cb.Category = CategoryLocked
+		}
+
 		if cb.Category == CategoryDelete {
 			result.ToDelete = append(result.ToDelete, cb.Backup)
 			result.SpaceReclaimed += cb.Backup.SizeBytes
@@ -109,7 +123,7 @@ func (s *RetentionService) ApplyRetentionPolicy(databaseName string, policy *con
 }
 
 // categorizeBackups categorizes backups based on retention policy.
-func (s *RetentionService) categorizeBackups(backups []storage.BackupListEntry, policy *config.RetentionPolicy) []CategorizedBackup {
+func (s *RetentionService) categorizeBackups(backups []storage.BackupListEntry, policy *RetentionPolicy) []CategorizedBackup {
 	result := make([]CategorizedBackup, 0, len(backups))
 
 	// Track what we've seen
@@ -193,6 +207,8 @@ func FormatCategory(category BackupCategory) string {
 		return "keep"
 	case CategoryDelete:
 		return "delete"
+	case CategoryLocked:
+		return "locked"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRetentionBackup writes a backup file and metadata created at createdAt.
+func createRetentionBackup(t *testing.T, tmpDir, backupID string, createdAt time.Time) {
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+
+	backupFile := filepath.Join(dbPath, backupID+".sql.gz")
+	createTestBackupFile(t, backupFile, "CREATE TABLE test (id INT);")
+
+	metadata := createTestMetadata(backupID, "testdb", backupFile, "gzip")
+	metadata.CreatedAt = createdAt
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+}
+
+func TestBackupLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	createRetentionBackup(t, tmpDir, backupID, time.Now())
+
+	t.Run("lock preserves metadata", func(t *testing.T) {
+		until := time.Now().Add(24 * time.Hour)
+		lock := &storage.LockInfo{LockedAt: time.Now(), Until: &until, Reason: "pre-migration"}
+		require.NoError(t, localStorage.SetBackupLock("testdb", backupID, lock))
+
+		var metadata BackupMetadata
+		require.NoError(t, localStorage.LoadMetadata("testdb", backupID, &metadata))
+		assert.Equal(t, StatusCompleted, metadata.Status)
+		assert.Equal(t, "8.0.35", metadata.Database.Version)
+		require.NotNil(t, metadata.Lock)
+		assert.Equal(t, "pre-migration", metadata.Lock.Reason)
+		assert.True(t, metadata.Lock.Active(time.Now()))
+	})
+
+	t.Run("delete refuses locked backup", func(t *testing.T) {
+		err := localStorage.DeleteBackup("testdb", backupID)
+		assert.True(t, storage.IsBackupLocked(err))
+		assert.FileExists(t, filepath.Join(tmpDir, "testdb", backupID+".sql.gz"))
+	})
+
+	t.Run("unlock allows delete", func(t *testing.T) {
+		require.NoError(t, localStorage.SetBackupLock("testdb", backupID, nil))
+		require.NoError(t, localStorage.DeleteBackup("testdb", backupID))
+		assert.NoFileExists(t, filepath.Join(tmpDir, "testdb", backupID+".sql.gz"))
+	})
+
+	t.Run("missing backup", func(t *testing.T) {
+		err := localStorage.SetBackupLock("testdb", "missing", &storage.LockInfo{LockedAt: time.Now()})
+		assert.ErrorIs(t, err, storage.ErrBackupNotFound)
+	})
+}
+
+func TestLockInfoActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	var nilLock *storage.LockInfo
+	assert.False(t, nilLock.Active(now))
+	assert.True(t, (&storage.LockInfo{LockedAt: past}).Active(now))
+	assert.True(t, (&storage.LockInfo{LockedAt: past, Until: &future}).Active(now))
+	assert.False(t, (&storage.LockInfo{LockedAt: past, Until: &past}).Active(now))
+}
+
+func TestApplyRetentionSkipsLockedBackups(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	createRetentionBackup(t, tmpDir, "newest", now)
+	createRetentionBackup(t, tmpDir, "locked", now.AddDate(0, 0, -10))
+	createRetentionBackup(t, tmpDir, "expired-lock", now.AddDate(0, 0, -20))
+
+	require.NoError(t, localStorage.SetBackupLock("testdb", "locked", &storage.LockInfo{LockedAt: now}))
+	past := now.Add(-time.Hour)
+	require.NoError(t, localStorage.SetBackupLock("testdb", "expired-lock", &storage.LockInfo{LockedAt: now, Until: &past}))
+
+	service := NewRetentionService(localStorage)
+	policy := &RetentionPolicy{Daily: 1}
+
+	result, err := service.ApplyRetention("testdb", policy, false)
+	require.NoError(t, err)
+
+	require.Len(t, result.ToDelete, 1)
+	assert.Equal(t, "expired-lock", result.ToDelete[0].BackupID)
+
+	categories := make(map[string]BackupCategory)
+	for _, cb := range result.ToKeep {
+		categories[cb.Backup.BackupID] = cb.Category
+	}
+	assert.Equal(t, CategoryDaily, categories["newest"])
+	assert.Equal(t, CategoryLocked, categories["locked"])
+	assert.FileExists(t, filepath.Join(tmpDir, "testdb", "locked.sql.gz"))
+}
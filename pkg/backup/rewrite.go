@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Choices for the DEFINER clauses of a backup besides an account to
+// rewrite them to.
+const (
+	// DefinersKeep leaves DEFINER clauses as dumped
+	DefinersKeep = "keep"
+
+	// DefinersStrip removes DEFINER clauses
+	DefinersStrip = "strip"
+)
+
+var (
+	// Lines that start a section of a dump other than a view's
+	currentDatabaseMarker = []byte("-- Current Database: `")
+	viewSectionEnds       = append([][]byte{tableMarker, currentDatabaseMarker}, trailerMarker...)
+
+	// The first statements of mysqldump's footer, which restores the
+	// session settings of its header
+	dumpFooterMarkers = [][]byte{
+		[]byte("/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;"),
+		[]byte("/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;"),
+	}
+
+	useDatabasePrefix = []byte("USE `")
+
+	// createViewPattern matches the first line of a CREATE VIEW statement
+	// written on one line or a few, as by SHOW CREATE VIEW
+	createViewPattern = regexp.MustCompile("(?i)^(/\\*!\\d+\\s*)?CREATE\\s+(OR\\s+REPLACE\\s+)?(ALGORITHM\\s*=\\s*\\w+\\s+)?(DEFINER\\s*=\\s*\\S+\\s+)?(SQL\\s+SECURITY\\s+\\w+\\s+)?VIEW\\s")
+
+	// definerPattern matches a DEFINER clause with the space after it:
+	// DEFINER=`user`@`host`, DEFINER='user'@'host' or DEFINER=CURRENT_USER
+	definerPattern = regexp.MustCompile("(?i)DEFINER\\s*=\\s*(" + definerPart + "\\s*@\\s*" + definerPart + "|CURRENT_USER(\\(\\))?)\\s*")
+
+	// definerLinePrefixes start the lines that can hold a DEFINER clause:
+	// CREATE statements and mysqldump's versioned comments around them
+	definerLinePrefixes = [][]byte{[]byte("CREATE "), []byte("create "), []byte("/*!50")}
+
+	// sqlSecurityPattern matches the SQL SECURITY DEFINER characteristic
+	sqlSecurityPattern = regexp.MustCompile(`(?i)SQL\s+SECURITY\s+DEFINER`)
+	sqlSecurityPrefix  = []byte("SQL SECURITY")
+)
+
+// definerPart matches the user or host of a DEFINER clause.
+const definerPart = "(`[^`]*`|'[^']*'|\"[^\"]*\"|[A-Za-z0-9_.$%-]+)"
+
+// Sections of a dump as seen by DumpRewriter
+const (
+	viewSectionNone      = iota // Not a view's section
+	viewSectionTemporary        // mysqldump's placeholder table of a view
+	viewSectionFinal            // mysqldump's final definition of a view
+)
+
+// DumpRewriteOptions select the rewrites of a DumpRewriter.
+type DumpRewriteOptions struct {
+	// SkipDefiners removes the DEFINER clauses of views, triggers,
+	// routines and events, so they are created with the restoring user as
+	// definer and restore on servers that lack the original accounts
+	SkipDefiners bool
+
+	// DeferViews moves view definitions to the end of the dump, after the
+	// tables of every database in it
+	DeferViews bool
+
+	// Definer, if set, is the account user@host that replaces the definer
+	// of every view, trigger, routine and event; SkipDefiners wins
+	Definer string
+
+	// SQLSecurityInvoker turns SQL SECURITY DEFINER views and routines
+	// into SQL SECURITY INVOKER ones, which run with the privileges of
+	// their caller
+	SQLSecurityInvoker bool
+}
+
+// enabled reports whether the options rewrite anything.
+func (o DumpRewriteOptions) enabled() bool {
+	return o.SkipDefiners || o.DeferViews || o.Definer != "" || o.SQLSecurityInvoker
+}
+
+// ValidateDefiners checks the definers option of a backup: empty,
+// DefinersKeep, DefinersStrip or an account user@host.
+func ValidateDefiners(definers string) error {
+	switch definers {
+	case "", DefinersKeep, DefinersStrip:
+		return nil
+	}
+	if _, _, err := parseDefiner(definers); err != nil {
+		return &ValidationError{Field: "definers", Message: err.Error()}
+	}
+	return nil
+}
+
+// DefinerRewrite returns the rewrite options that apply a definers option
+// and SQL SECURITY choice to a dump.
+func DefinerRewrite(definers string, sqlSecurityInvoker bool) DumpRewriteOptions {
+	options := DumpRewriteOptions{SQLSecurityInvoker: sqlSecurityInvoker}
+	switch definers {
+	case "", DefinersKeep:
+	case DefinersStrip:
+		options.SkipDefiners = true
+	default:
+		options.Definer = definers
+	}
+	return options
+}
+
+// parseDefiner splits an account user@host at its last @.
+func parseDefiner(account string) (user, host string, err error) {
+	i := strings.LastIndex(account, "@")
+	if i <= 0 || i == len(account)-1 {
+		return "", "", fmt.Errorf("invalid definer %q (must be keep, strip or an account user@host)", account)
+	}
+	user, host = account[:i], account[i+1:]
+	if strings.ContainsAny(account, "`'\"\n") {
+		return "", "", fmt.Errorf("invalid definer %q (user and host must not be quoted)", account)
+	}
+	return user, host, nil
+}
+
+// DumpRewriter reads a SQL dump and rewrites it for the server it is
+// restored into, line by line as it streams through. Deferred views are
+// held in memory until the end of the dump.
+type DumpRewriter struct {
+	reader  *bufio.Reader
+	options DumpRewriteOptions
+	definer []byte // Replacement of DEFINER clauses, empty to remove them
+
+	out         bytes.Buffer
+	deferred    bytes.Buffer
+	err         error
+	lineStart   bool
+	section     int
+	inView      bool   // Inside a CREATE VIEW statement being deferred
+	inRoutine   bool   // Between DELIMITER ;; and DELIMITER ;
+	useLine     []byte // The last USE statement of the dump
+	deferredUse []byte // The USE statement in effect at the end of deferred
+}
+
+// NewDumpRewriter returns a reader of the dump in r with the rewrites of
+// options applied.
+func NewDumpRewriter(r io.Reader, options DumpRewriteOptions) *DumpRewriter {
+	d := &DumpRewriter{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		options:   options,
+		lineStart: true,
+	}
+	if user, host, err := parseDefiner(options.Definer); err == nil && !options.SkipDefiners {
+		d.definer = fmt.Appendf(nil, "DEFINER=`%s`@`%s` ", user, host)
+	}
+	return d
+}
+
+// Read implements io.Reader.
+func (d *DumpRewriter) Read(p []byte) (int, error) {
+	for d.out.Len() == 0 && d.err == nil {
+		fragment, err := d.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			d.rewrite(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			d.flushDeferred()
+			d.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			d.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if d.out.Len() > 0 {
+		return d.out.Read(p)
+	}
+	return 0, d.err
+}
+
+// rewrite passes on a fragment of a line, now or at the end of the dump.
+func (d *DumpRewriter) rewrite(fragment []byte) {
+	if d.lineStart {
+		fragment = d.startLine(fragment)
+	}
+	d.lineStart = fragment[len(fragment)-1] == '\n'
+
+	if !d.deferring() {
+		d.out.Write(fragment)
+		return
+	}
+	d.deferred.Write(fragment)
+	if d.lineStart && d.inView && bytes.HasSuffix(bytes.TrimSpace(fragment), []byte(";")) {
+		d.inView = false
+	}
+}
+
+// startLine tracks the section the line starting with fragment belongs to
+// and returns the fragment rewritten.
+func (d *DumpRewriter) startLine(fragment []byte) []byte {
+	if d.options.DeferViews {
+		d.route(fragment)
+	}
+	definerLine := hasAnyPrefix(fragment, definerLinePrefixes)
+	if definerLine && (d.options.SkipDefiners || d.definer != nil) {
+		fragment = definerPattern.ReplaceAllLiteral(fragment, d.definer)
+	}
+	if d.options.SQLSecurityInvoker && (definerLine || bytes.HasPrefix(bytes.TrimLeft(fragment, " \t"), sqlSecurityPrefix)) {
+		fragment = sqlSecurityPattern.ReplaceAllLiteral(fragment, []byte("SQL SECURITY INVOKER"))
+	}
+	return fragment
+}
+
+// route decides whether the line starting with fragment is deferred.
+func (d *DumpRewriter) route(fragment []byte) {
+	switch {
+	case bytes.HasPrefix(fragment, temporaryViewMarker):
+		d.section = viewSectionTemporary
+	case bytes.HasPrefix(fragment, finalViewMarker):
+		d.section = viewSectionFinal
+	case hasAnyPrefix(fragment, viewSectionEnds):
+		d.section = viewSectionNone
+	case hasAnyPrefix(fragment, dumpFooterMarkers):
+		// Create the views while the dump's session settings still apply
+		d.section = viewSectionNone
+		d.flushDeferred()
+	}
+
+	switch line := bytes.TrimSpace(fragment); {
+	case bytes.Equal(line, triggerStart):
+		d.inRoutine = true
+	case bytes.Equal(line, triggerEnd):
+		d.inRoutine = false
+	case bytes.HasPrefix(line, useDatabasePrefix) && fragment[len(fragment)-1] == '\n':
+		d.useLine = append([]byte{}, fragment...)
+	}
+
+	if d.section == viewSectionNone && !d.inView && !d.inRoutine && createViewPattern.Match(fragment) {
+		d.inView = true
+	}
+
+	// Deferred views are created in the database they were dumped in
+	if d.deferring() && !bytes.Equal(d.deferredUse, d.useLine) {
+		d.deferred.Write(d.useLine)
+		d.deferredUse = d.useLine
+	}
+}
+
+// deferring reports whether the current line is held back for the end of
+// the dump.
+func (d *DumpRewriter) deferring() bool {
+	return d.section == viewSectionFinal || d.inView
+}
+
+// flushDeferred passes on the deferred views.
+func (d *DumpRewriter) flushDeferred() {
+	if d.deferred.Len() == 0 {
+		return
+	}
+	d.out.Write(d.deferred.Bytes())
+	d.deferred.Reset()
+
+	// Switch back to the database the rest of the dump expects
+	if !bytes.Equal(d.deferredUse, d.useLine) {
+		d.out.Write(d.useLine)
+	}
+	d.deferredUse = nil
+}
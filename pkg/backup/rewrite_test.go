@@ -0,0 +1,153 @@
+package backup
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// viewsDump is a mysqldump --databases dump of two databases whose view
+// selects from the second database.
+const viewsDump = "-- MySQL dump\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Current Database: `shop`\n" +
+	"--\n" +
+	"USE `shop`;\n" +
+	"--\n" +
+	"-- Temporary view structure for view `report`\n" +
+	"--\n" +
+	"/*!50001 CREATE VIEW `report` AS SELECT \n" +
+	" 1 AS `id`*/;\n" +
+	"DELIMITER ;;\n" +
+	"/*!50003 CREATE*/ /*!50017 DEFINER=`admin`@`%`*/ /*!50003 TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW BEGIN END */;;\n" +
+	"DELIMITER ;\n" +
+	"DELIMITER ;;\n" +
+	"CREATE DEFINER=`admin`@`localhost` PROCEDURE `cleanup`()\n" +
+	"BEGIN\n" +
+	"  DELETE FROM t;\n" +
+	"END ;;\n" +
+	"DELIMITER ;\n" +
+	"--\n" +
+	"-- Final view structure for view `report`\n" +
+	"--\n" +
+	"/*!50001 DROP VIEW IF EXISTS `report`*/;\n" +
+	"/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+	"/*!50013 DEFINER=`admin`@`%` SQL SECURITY DEFINER */\n" +
+	"/*!50001 VIEW `report` AS select `id` AS `id` from `stats`.`daily` */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Current Database: `stats`\n" +
+	"--\n" +
+	"USE `stats`;\n" +
+	"--\n" +
+	"-- Table structure for table `daily`\n" +
+	"--\n" +
+	"CREATE TABLE `daily` (`id` int);\n" +
+	"INSERT INTO `daily` VALUES (1);\n" +
+	"/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n" +
+	"-- Dump completed\n"
+
+func rewriteString(t *testing.T, dump string, options DumpRewriteOptions) string {
+	t.Helper()
+	out, err := io.ReadAll(NewDumpRewriter(strings.NewReader(dump), options))
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestDumpRewriterSkipDefiners(t *testing.T) {
+	out := rewriteString(t, viewsDump, DumpRewriteOptions{SkipDefiners: true})
+	assert.NotContains(t, out, "DEFINER=")
+	assert.Contains(t, out, "/*!50003 CREATE*/ /*!50017 */ /*!50003 TRIGGER `t_bi`")
+	assert.Contains(t, out, "CREATE PROCEDURE `cleanup`()\n")
+	assert.Contains(t, out, "/*!50013 SQL SECURITY DEFINER */\n")
+
+	for _, stmt := range []string{
+		"CREATE DEFINER='admin'@'10.0.0.%' FUNCTION f() RETURNS int RETURN 1;\n",
+		"CREATE ALGORITHM=MERGE DEFINER=CURRENT_USER SQL SECURITY INVOKER VIEW v AS SELECT 1;\n",
+		"CREATE DEFINER = app@localhost EVENT e ON SCHEDULE EVERY 1 DAY DO DELETE FROM t;\n",
+	} {
+		assert.NotContains(t, rewriteString(t, stmt, DumpRewriteOptions{SkipDefiners: true}), "DEFINER", stmt)
+	}
+
+	// Data is never rewritten
+	data := "INSERT INTO `notes` VALUES ('CREATE DEFINER=`a`@`b`');\n"
+	assert.Equal(t, data, rewriteString(t, data, DumpRewriteOptions{SkipDefiners: true}))
+}
+
+func TestDumpRewriterDeferViews(t *testing.T) {
+	out := rewriteString(t, viewsDump, DumpRewriteOptions{DeferViews: true})
+
+	// The same statements, with the final view after the table it selects from
+	assert.Equal(t, len(viewsDump)+len("USE `shop`;\n")+len("USE `stats`;\n"), len(out))
+	table := strings.Index(out, "CREATE TABLE `daily`")
+	view := strings.Index(out, "/*!50001 VIEW `report`")
+	footer := strings.Index(out, "/*!40103 SET TIME_ZONE")
+	assert.Less(t, table, view)
+	assert.Less(t, view, footer)
+	assert.Contains(t, out, "INSERT INTO `daily` VALUES (1);\nUSE `shop`;\n-- Final view structure")
+	assert.Contains(t, out, "from `stats`.`daily` */;\n\n--\nUSE `stats`;\n/*!40103")
+
+	// The placeholder stays in place for views selecting from views
+	assert.Less(t, strings.Index(out, "/*!50001 CREATE VIEW `report`"), table)
+
+	t.Run("plain statements", func(t *testing.T) {
+		dump := "CREATE VIEW v AS\n  SELECT * FROM t;\n" +
+			"CREATE TABLE t (id int);\n" +
+			"CREATE OR REPLACE ALGORITHM=MERGE VIEW w AS SELECT 1;\n" +
+			"INSERT INTO t VALUES (1);\n"
+		out := rewriteString(t, dump, DumpRewriteOptions{DeferViews: true})
+		assert.Equal(t, "CREATE TABLE t (id int);\n"+
+			"INSERT INTO t VALUES (1);\n"+
+			"CREATE VIEW v AS\n  SELECT * FROM t;\n"+
+			"CREATE OR REPLACE ALGORITHM=MERGE VIEW w AS SELECT 1;\n", out)
+	})
+}
+
+func TestDumpRewriterDefiner(t *testing.T) {
+	out := rewriteString(t, viewsDump, DefinerRewrite("app@%", true))
+	assert.Contains(t, out, "/*!50017 DEFINER=`app`@`%` */ /*!50003 TRIGGER")
+	assert.Contains(t, out, "CREATE DEFINER=`app`@`%` PROCEDURE `cleanup`()\n")
+	assert.Contains(t, out, "/*!50013 DEFINER=`app`@`%` SQL SECURITY INVOKER */\n")
+	assert.NotContains(t, out, "admin")
+
+	routine := "CREATE DEFINER=`admin`@`%` FUNCTION f() RETURNS int\n    SQL SECURITY DEFINER\nRETURN 1;\n"
+	assert.Equal(t, "CREATE FUNCTION f() RETURNS int\n    SQL SECURITY INVOKER\nRETURN 1;\n",
+		rewriteString(t, routine, DefinerRewrite(DefinersStrip, true)))
+	assert.Equal(t, routine, rewriteString(t, routine, DefinerRewrite(DefinersKeep, false)))
+}
+
+func TestValidateDefiners(t *testing.T) {
+	for _, definers := range []string{"", DefinersKeep, DefinersStrip, "app@%", "app@10.0.0.%", "user@example.com@localhost"} {
+		assert.NoError(t, ValidateDefiners(definers), definers)
+	}
+	for _, definers := range []string{"remove", "app@", "@localhost", "`app`@`%`"} {
+		assert.True(t, IsValidationError(ValidateDefiners(definers)), definers)
+	}
+}
+
+func TestBackupToWriterRewritesDefiners(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	service := NewService(client, nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: &fakeDumper{data: viewsDump}})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.Compression = CompressionNone
+	options.Definers = DefinersStrip
+
+	var out strings.Builder
+	_, err := service.BackupToWriter(options, &out)
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "DEFINER=")
+
+	options.Engine = EngineMydumper
+	_, err = service.BackupToWriter(options, &out)
+	assert.ErrorContains(t, err, "definers cannot be rewritten in mydumper backups")
+}
@@ -0,0 +1,30 @@
+package backup
+
+import (
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// LatestPreRestoreSnapshot returns the metadata of the most recent completed
+// safety snapshot taken before a restore of database, or nil if there is
+// none. Backups whose metadata cannot be read are skipped.
+func LatestPreRestoreSnapshot(stor *storage.LocalStorage, database string) (*BackupMetadata, error) {
+	backups, err := stor.ListBackups(database)
+	if err != nil {
+		return nil, err
+	}
+
+	// Backups are listed newest first
+	for _, entry := range backups {
+		if entry.Status != StatusCompleted {
+			continue
+		}
+		var metadata BackupMetadata
+		if err := stor.LoadMetadata(database, entry.BackupID, &metadata); err != nil {
+			continue
+		}
+		if metadata.PreRestore != nil {
+			return &metadata, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,57 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestPreRestoreSnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	t.Run("no snapshots", func(t *testing.T) {
+		createRetentionBackup(t, tmpDir, "2025-01-01-120000", time.Now().Add(-72*time.Hour))
+
+		snapshot, err := LatestPreRestoreSnapshot(localStorage, "testdb")
+		require.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	markSnapshot := func(backupID, source string) {
+		var metadata BackupMetadata
+		require.NoError(t, localStorage.LoadMetadata("testdb", backupID, &metadata))
+		metadata.PreRestore = &RestoreLink{Source: source, TargetDatabase: "testdb", RestoredAt: metadata.CreatedAt}
+		require.NoError(t, localStorage.SaveMetadata("testdb", backupID, &metadata))
+	}
+
+	createRetentionBackup(t, tmpDir, "2025-01-02-120000", time.Now().Add(-48*time.Hour))
+	markSnapshot("2025-01-02-120000", "2025-01-01-120000")
+	createRetentionBackup(t, tmpDir, "2025-01-03-120000", time.Now().Add(-24*time.Hour))
+	markSnapshot("2025-01-03-120000", "/tmp/dump.sql")
+	createRetentionBackup(t, tmpDir, "2025-01-04-120000", time.Now())
+
+	t.Run("newest snapshot wins over newer regular backups", func(t *testing.T) {
+		snapshot, err := LatestPreRestoreSnapshot(localStorage, "testdb")
+		require.NoError(t, err)
+		require.NotNil(t, snapshot)
+		assert.Equal(t, "2025-01-03-120000", snapshot.BackupID)
+		assert.Equal(t, "/tmp/dump.sql", snapshot.PreRestore.Source)
+	})
+
+	t.Run("failed snapshots are skipped", func(t *testing.T) {
+		var metadata BackupMetadata
+		require.NoError(t, localStorage.LoadMetadata("testdb", "2025-01-03-120000", &metadata))
+		metadata.Status = StatusFailed
+		require.NoError(t, localStorage.SaveMetadata("testdb", "2025-01-03-120000", &metadata))
+
+		snapshot, err := LatestPreRestoreSnapshot(localStorage, "testdb")
+		require.NoError(t, err)
+		require.NotNil(t, snapshot)
+		assert.Equal(t, "2025-01-02-120000", snapshot.BackupID)
+	})
+}
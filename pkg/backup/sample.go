@@ -0,0 +1,312 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	columnPrefix     = []byte("  `")
+	foreignKeyMarker = []byte("FOREIGN KEY (`")
+	referencesMarker = []byte("REFERENCES `")
+	valuesMarker     = []byte(" VALUES ")
+)
+
+// samplePrecision is how finely Percent is applied: rows are kept when
+// their hash modulo samplePrecision falls below Percent of it.
+const samplePrecision = 10000
+
+// RowSample selects the rows a seeding restore keeps of each table.
+type RowSample struct {
+	// Percent of each table's rows to keep (0 = all)
+	Percent float64
+
+	// MaxRows caps the rows kept of each table (0 = no cap)
+	MaxRows int64
+}
+
+// ParseSamplePercent parses a percentage of rows such as "10%" or "2.5".
+func ParseSamplePercent(s string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, &ValidationError{Field: "sample", Message: fmt.Sprintf("invalid sample: %s (must be a percentage above 0 and up to 100)", s)}
+	}
+	return percent, nil
+}
+
+// Validate checks the sample's bounds.
+func (r RowSample) Validate() error {
+	if r.Percent < 0 || r.Percent > 100 {
+		return &ValidationError{Field: "sample", Message: fmt.Sprintf("invalid sample percentage: %g", r.Percent)}
+	}
+	if r.MaxRows < 0 {
+		return &ValidationError{Field: "max_rows", Message: fmt.Sprintf("invalid row cap: %d", r.MaxRows)}
+	}
+	return nil
+}
+
+func (r RowSample) enabled() bool {
+	return (r.Percent > 0 && r.Percent < 100) || r.MaxRows > 0
+}
+
+// String describes the sample, e.g. "10% of rows, at most 1000 per table".
+func (r RowSample) String() string {
+	var parts []string
+	if r.Percent > 0 && r.Percent < 100 {
+		parts = append(parts, strconv.FormatFloat(r.Percent, 'f', -1, 64)+"% of rows")
+	}
+	if r.MaxRows > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d rows per table", r.MaxRows))
+	}
+	if len(parts) == 0 {
+		return "all rows"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sampleKey is the value deciding whether a table's rows are kept: the
+// table's first column, or the first foreign key of the table's rows
+// along with the table it references.
+type sampleKey struct {
+	table  string
+	column int
+}
+
+// RowSampler reads a mysqldump SQL dump and keeps a sample of the rows of
+// its extended INSERT statements, passing on everything else.
+//
+// Whether a row is kept is decided by a hash of its first column, usually
+// the primary key, so the same rows are kept on every run. Rows of a table
+// with a foreign key are hashed by the key instead, as if they were rows of
+// the table it references, so they are kept along with the row they
+// reference when the key references that table's first column. Rows whose
+// reference is itself sampled through a foreign key, or cut by MaxRows,
+// may be orphaned.
+type RowSampler struct {
+	reader *bufio.Reader
+	sample RowSample
+
+	table   string   // Table of the CREATE TABLE statement being read
+	columns []string // Its columns so far
+	keys    map[string]sampleKey
+	kept    map[string]int64
+
+	out       bytes.Buffer
+	err       error
+	lineStart bool
+	insert    []byte // INSERT statement being read, nil outside one
+}
+
+// NewRowSampler returns a reader of the dump in r with a sample of its
+// rows.
+func NewRowSampler(r io.Reader, sample RowSample) *RowSampler {
+	return &RowSampler{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		sample:    sample,
+		keys:      make(map[string]sampleKey),
+		kept:      make(map[string]int64),
+		lineStart: true,
+	}
+}
+
+// Read implements io.Reader.
+func (s *RowSampler) Read(p []byte) (int, error) {
+	for s.out.Len() == 0 && s.err == nil {
+		fragment, err := s.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			s.filter(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			if s.insert != nil {
+				s.sampleInsert(s.insert)
+				s.insert = nil
+			}
+			s.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			s.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if s.out.Len() > 0 {
+		return s.out.Read(p)
+	}
+	return 0, s.err
+}
+
+// filter passes on a fragment of a line, collecting INSERT statements to
+// sample whole and the columns and foreign keys of the tables created.
+func (s *RowSampler) filter(fragment []byte) {
+	lineStart := s.lineStart
+	s.lineStart = fragment[len(fragment)-1] == '\n'
+
+	if lineStart {
+		switch {
+		case bytes.HasPrefix(fragment, insertIntoPrefix):
+			s.insert = append([]byte{}, fragment...)
+		case bytes.HasPrefix(fragment, createTablePrefix):
+			s.table, s.columns = quotedName(fragment), nil
+		case s.table != "" && bytes.HasPrefix(fragment, columnPrefix):
+			s.columns = append(s.columns, quotedName(fragment))
+		case s.table != "" && bytes.HasPrefix(fragment, constraintPrefix):
+			s.addForeignKey(fragment)
+		case s.table != "" && bytes.HasPrefix(fragment, []byte(")")):
+			s.table = ""
+		}
+	} else if s.insert != nil {
+		s.insert = append(s.insert, fragment...)
+	}
+
+	if s.insert == nil {
+		s.out.Write(fragment)
+		return
+	}
+	if s.lineStart {
+		s.sampleInsert(s.insert)
+		s.insert = nil
+	}
+}
+
+// addForeignKey records the first single-column foreign key of the table
+// being created as the key its rows are sampled by.
+func (s *RowSampler) addForeignKey(line []byte) {
+	if _, ok := s.keys[s.table]; ok {
+		return
+	}
+	start := bytes.Index(line, foreignKeyMarker)
+	references := bytes.Index(line, referencesMarker)
+	if start < 0 || references < start {
+		return
+	}
+	columns := line[start+len(foreignKeyMarker)-1 : references]
+	if bytes.Contains(columns, []byte("`,")) {
+		return
+	}
+	column := quotedName(columns)
+	for i, name := range s.columns {
+		if name == column {
+			s.keys[s.table] = sampleKey{table: quotedName(line[references:]), column: i}
+			return
+		}
+	}
+}
+
+// sampleInsert writes the statement with only the rows kept, or nothing
+// when none are.
+func (s *RowSampler) sampleInsert(statement []byte) {
+	values := bytes.Index(statement, valuesMarker)
+	if values < 0 {
+		s.out.Write(statement)
+		return
+	}
+	table := quotedName(statement)
+	key, ok := s.keys[table]
+	if !ok {
+		key = sampleKey{table: table}
+	}
+
+	tuples, rest := splitTuples(statement[values+len(valuesMarker):])
+	var kept [][]byte
+	for _, tuple := range tuples {
+		if s.keep(table, key, tuple) {
+			kept = append(kept, tuple)
+		}
+	}
+	if len(kept) == 0 {
+		return
+	}
+	s.out.Write(statement[:values+len(valuesMarker)])
+	s.out.Write(bytes.Join(kept, []byte(",")))
+	s.out.Write(rest)
+}
+
+// keep decides whether a row of table is kept.
+func (s *RowSampler) keep(table string, key sampleKey, tuple []byte) bool {
+	if s.sample.MaxRows > 0 && s.kept[table] >= s.sample.MaxRows {
+		return false
+	}
+	if s.sample.Percent > 0 && s.sample.Percent < 100 {
+		value := tupleField(tuple, key.column)
+		if value == nil || string(value) == "NULL" {
+			key, value = sampleKey{table: table}, tupleField(tuple, 0)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key.table))
+		h.Write([]byte{0})
+		h.Write(value)
+		if float64(h.Sum32()%samplePrecision) >= s.sample.Percent*samplePrecision/100 {
+			return false
+		}
+	}
+	s.kept[table]++
+	return true
+}
+
+// splitTuples returns the parenthesized rows at the start of the VALUES
+// list of an INSERT statement, and what follows them.
+func splitTuples(values []byte) ([][]byte, []byte) {
+	var tuples [][]byte
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(values); i++ {
+		c := values[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				tuples = append(tuples, values[start:i+1])
+			}
+		case depth == 0 && c != ',':
+			return tuples, values[i:]
+		}
+	}
+	return tuples, nil
+}
+
+// tupleField returns the nth value of a parenthesized row, or nil.
+func tupleField(tuple []byte, n int) []byte {
+	field, start, depth := 0, 1, 0
+	var quote byte
+	for i := 1; i < len(tuple); i++ {
+		c := tuple[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case (c == ',' || c == ')') && depth == 0:
+			if field == n {
+				return tuple[start:i]
+			}
+			field++
+			start = i + 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usersOrdersDump returns a dump of users and their orders, with n of each in
+// one extended INSERT per table; order i belongs to user i.
+func usersOrdersDump(n int) string {
+	var users, orders []string
+	for i := 1; i <= n; i++ {
+		users = append(users, fmt.Sprintf("(%d,'user, (%d)')", i, i))
+		orders = append(orders, fmt.Sprintf("(%d,%d,'it\\'s')", 1000+i, i))
+	}
+	return "/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+		"CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `user_id` int DEFAULT NULL,\n" +
+		"  `note` varchar(20),\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+		") ENGINE=InnoDB;\n" +
+		"INSERT INTO `orders` VALUES " + strings.Join(orders, ",") + ";\n" +
+		"CREATE TABLE `users` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `name` varchar(20),\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB;\n" +
+		"INSERT INTO `users` VALUES " + strings.Join(users, ",") + ";\n"
+}
+
+// sampledRows returns the rows of the INSERT into table in a dump.
+func sampledRows(t *testing.T, dump, table string) [][]byte {
+	t.Helper()
+	prefix := "INSERT INTO `" + table + "` VALUES "
+	for _, line := range strings.Split(dump, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			rows, rest := splitTuples([]byte(line[len(prefix):]))
+			require.Equal(t, ";", string(rest))
+			return rows
+		}
+	}
+	return nil
+}
+
+func TestRowSampler(t *testing.T) {
+	dump := usersOrdersDump(1000)
+
+	t.Run("percent", func(t *testing.T) {
+		out, err := io.ReadAll(NewRowSampler(strings.NewReader(dump), RowSample{Percent: 10}))
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "CREATE TABLE `users` (\n")
+
+		users := sampledRows(t, string(out), "users")
+		assert.InDelta(t, 100, len(users), 40)
+
+		// Every order is kept along with its user
+		orders := sampledRows(t, string(out), "orders")
+		require.Len(t, orders, len(users))
+		for i, order := range orders {
+			assert.Equal(t, string(tupleField(users[i], 0)), string(tupleField(order, 1)))
+		}
+
+		again, err := io.ReadAll(NewRowSampler(strings.NewReader(dump), RowSample{Percent: 10}))
+		require.NoError(t, err)
+		assert.Equal(t, out, again)
+	})
+
+	t.Run("max rows", func(t *testing.T) {
+		out, err := io.ReadAll(NewRowSampler(strings.NewReader(dump), RowSample{MaxRows: 3}))
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "INSERT INTO `users` VALUES (1,'user, (1)'),(2,'user, (2)'),(3,'user, (3)');\n")
+		assert.Len(t, sampledRows(t, string(out), "orders"), 3)
+	})
+
+	t.Run("no rows kept", func(t *testing.T) {
+		out, err := io.ReadAll(NewRowSampler(strings.NewReader(usersOrdersDump(1)), RowSample{Percent: 0.01}))
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "INSERT")
+		assert.Contains(t, string(out), ") ENGINE=InnoDB;\n")
+	})
+}
+
+func TestTupleField(t *testing.T) {
+	tuple := []byte(`(1,'a,\'b)',NULL,POINT(1,2),"c")`)
+	assert.Equal(t, "1", string(tupleField(tuple, 0)))
+	assert.Equal(t, `'a,\'b)'`, string(tupleField(tuple, 1)))
+	assert.Equal(t, "NULL", string(tupleField(tuple, 2)))
+	assert.Equal(t, "POINT(1,2)", string(tupleField(tuple, 3)))
+	assert.Equal(t, `"c"`, string(tupleField(tuple, 4)))
+	assert.Nil(t, tupleField(tuple, 5))
+}
+
+func TestParseSamplePercent(t *testing.T) {
+	percent, err := ParseSamplePercent("10%")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, percent)
+
+	percent, err = ParseSamplePercent("2.5")
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, percent)
+
+	for _, invalid := range []string{"", "0%", "150%", "ten"} {
+		_, err := ParseSamplePercent(invalid)
+		assert.True(t, IsValidationError(err), invalid)
+	}
+
+	assert.Equal(t, "10% of rows, at most 500 rows per table", RowSample{Percent: 10, MaxRows: 500}.String())
+}
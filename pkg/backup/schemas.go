@@ -0,0 +1,152 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// schemaSetSeparator joins the databases of a multi-database backup into
+// the name it is stored under.
+const schemaSetSeparator = "+"
+
+// schemaSetConnectDatabase is the database restores of multi-database
+// backups connect to. Every account can use it, and the dump switches to
+// its own databases with USE statements.
+const schemaSetConnectDatabase = "information_schema"
+
+// SchemaSetName returns the name a backup of several databases is stored
+// and restored under when it has no configuration name, e.g. "shop+stats".
+func SchemaSetName(databases []string) string {
+	return strings.Join(databases, schemaSetSeparator)
+}
+
+// validateDatabases checks the Databases of backup options.
+func validateDatabases(options *BackupOptions) error {
+	invalid := func(message string) error {
+		return &ValidationError{Field: "Databases", Message: message}
+	}
+	switch {
+	case options.Engine == EngineMydumper:
+		return invalid("multiple databases can only be backed up with mysqldump")
+	case len(options.Tables) > 0 || len(options.ExcludeTables) > 0:
+		return invalid("tables cannot be selected when backing up multiple databases")
+	case options.Indexed:
+		return invalid("backups of multiple databases cannot be indexed")
+	}
+	seen := make(map[string]bool, len(options.Databases))
+	for _, database := range options.Databases {
+		if database == "" || strings.ContainsAny(database, schemaSetSeparator+"/") {
+			return invalid(fmt.Sprintf("invalid database name %q", database))
+		}
+		if seen[database] {
+			return invalid(fmt.Sprintf("database %s is listed twice", database))
+		}
+		seen[database] = true
+	}
+	return nil
+}
+
+// backupCharset returns the default character set of the database the
+// options back up. Databases of a multi-database backup keep their own
+// character sets in the dump's CREATE DATABASE statements, so none is
+// recorded for them.
+func backupCharset(client Introspector, options *BackupOptions) *mysql.Charset {
+	if len(options.Databases) > 0 {
+		return nil
+	}
+	return databaseCharset(client, options.Database)
+}
+
+// SelectSchemas checks that the databases of a multi-database backup to
+// restore are in it and returns them; none selects them all.
+func SelectSchemas(info DatabaseInfo, schemas []string) ([]string, error) {
+	if len(info.Databases) == 0 {
+		if len(schemas) > 0 {
+			return nil, errors.New("databases can only be selected in backups of multiple databases")
+		}
+		return nil, nil
+	}
+	if len(schemas) == 0 {
+		return info.Databases, nil
+	}
+	for _, schema := range schemas {
+		if !slices.Contains(info.Databases, schema) {
+			return nil, fmt.Errorf("no database %s in backup (has %s)", schema, strings.Join(info.Databases, ", "))
+		}
+	}
+	return schemas, nil
+}
+
+// SchemaFilter reads a mysqldump --databases dump and passes on only the
+// session settings around it and the sections of some of its databases.
+type SchemaFilter struct {
+	reader  *bufio.Reader
+	schemas []string
+
+	out       bytes.Buffer
+	err       error
+	lineStart bool
+	keep      bool // Whether the current line is passed on
+}
+
+// NewSchemaFilter returns a reader of the parts of the dump in r needed to
+// restore schemas.
+func NewSchemaFilter(r io.Reader, schemas []string) *SchemaFilter {
+	return &SchemaFilter{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		schemas:   schemas,
+		lineStart: true,
+		keep:      true,
+	}
+}
+
+// Read implements io.Reader.
+func (f *SchemaFilter) Read(p []byte) (int, error) {
+	for f.out.Len() == 0 && f.err == nil {
+		fragment, err := f.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			f.filter(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			f.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			f.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if f.out.Len() > 0 {
+		return f.out.Read(p)
+	}
+	return 0, f.err
+}
+
+// filter passes on a fragment of a line if it belongs to a selected
+// database or to the dump's header or footer.
+func (f *SchemaFilter) filter(fragment []byte) {
+	if f.lineStart {
+		switch {
+		case bytes.HasPrefix(fragment, currentDatabaseMarker):
+			f.keep = slices.Contains(f.schemas, currentDatabaseName(fragment))
+		case hasAnyPrefix(fragment, dumpFooterMarkers):
+			f.keep = true
+		}
+	}
+	f.lineStart = fragment[len(fragment)-1] == '\n'
+	if f.keep {
+		f.out.Write(fragment)
+	}
+}
+
+// currentDatabaseName returns the database named by a "-- Current
+// Database:" comment line.
+func currentDatabaseName(line []byte) string {
+	name := bytes.TrimSuffix(bytes.TrimRight(line[len(currentDatabaseMarker):], "\r\n"), []byte("`"))
+	return strings.ReplaceAll(string(name), "``", "`")
+}
@@ -0,0 +1,110 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFilter(t *testing.T) {
+	out, err := io.ReadAll(NewSchemaFilter(strings.NewReader(viewsDump), []string{"stats"}))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "/*!40101 SET NAMES utf8mb4 */;\n")
+	assert.Contains(t, string(out), "USE `stats`;\n")
+	assert.Contains(t, string(out), "INSERT INTO `daily` VALUES (1);\n/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n-- Dump completed\n")
+	assert.NotContains(t, string(out), "USE `shop`")
+	assert.NotContains(t, string(out), "report")
+
+	assert.Equal(t, "we`ird", currentDatabaseName([]byte("-- Current Database: `we``ird`\n")))
+}
+
+func TestSelectSchemas(t *testing.T) {
+	info := DatabaseInfo{Database: "shop+stats", Databases: []string{"shop", "stats"}}
+
+	schemas, err := SelectSchemas(info, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop", "stats"}, schemas)
+
+	schemas, err = SelectSchemas(info, []string{"stats"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stats"}, schemas)
+
+	_, err = SelectSchemas(info, []string{"billing"})
+	assert.ErrorContains(t, err, "no database billing in backup")
+
+	_, err = SelectSchemas(DatabaseInfo{Database: "shop"}, []string{"shop"})
+	assert.Error(t, err)
+}
+
+func TestBackupToWriterDatabases(t *testing.T) {
+	dumper := &fakeDumper{data: viewsDump}
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	service := NewService(client, nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: dumper})
+
+	options := DefaultOptions()
+	options.Databases = []string{"shop", "stats"}
+	options.Compression = CompressionNone
+
+	_, err := service.BackupToWriter(options, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, "shop+stats", options.Database)
+	assert.Equal(t, []string{"shop", "stats"}, dumper.options.Databases)
+
+	for _, invalid := range []func(*BackupOptions){
+		func(o *BackupOptions) { o.Tables = []string{"orders"} },
+		func(o *BackupOptions) { o.Engine = EngineMydumper },
+		func(o *BackupOptions) { o.Databases = []string{"shop", "shop"} },
+	} {
+		options := DefaultOptions()
+		options.Databases = []string{"shop", "stats"}
+		invalid(options)
+		_, err := service.BackupToWriter(options, io.Discard)
+		assert.True(t, IsValidationError(err), err)
+	}
+}
+
+func TestRestoreServiceRestoreSchemas(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "shop+stats")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), viewsDump)
+	metadata := createTestMetadata(backupID, "shop+stats", backupID+".sql.gz", "gzip")
+	metadata.Database.Databases = []string{"shop", "stats"}
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+
+	// Neither database exists yet; the dump creates them
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer)
+	service.storage = localStorage
+
+	options := &RestoreOptions{Database: "shop+stats", BackupID: backupID}
+	_, err = service.Restore(options)
+	require.NoError(t, err)
+	assert.Equal(t, schemaSetConnectDatabase, restorer.database)
+	assert.Equal(t, viewsDump, restorer.sql)
+
+	options.Schemas = []string{"shop"}
+	_, err = service.Restore(options)
+	require.NoError(t, err)
+	assert.Contains(t, restorer.sql, "USE `shop`;\n")
+	assert.NotContains(t, restorer.sql, "CREATE TABLE `daily`")
+
+	options.Schemas = nil
+	options.TargetDatabase = "copy"
+	_, err = service.Restore(options)
+	assert.True(t, IsRestoreError(err))
+}
@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultSearchContext is how much of a matching row or line is kept as
+// its context when none is given.
+const DefaultSearchContext = 200
+
+// SearchOptions configures a search of a SQL dump.
+type SearchOptions struct {
+	// Pattern is what is searched for
+	Pattern *regexp.Regexp
+
+	// Limit is the number of matches kept with their context; every match
+	// is still counted (0 = no limit)
+	Limit int
+
+	// Context is the longest context kept for a match, in bytes
+	// (0 = DefaultSearchContext)
+	Context int
+}
+
+// SearchMatch is one row, or one line outside INSERT statements, that
+// matches a search.
+type SearchMatch struct {
+	// Table is the table whose section of the dump the match is in, or
+	// empty before the first table
+	Table string
+
+	// Context is the matching row's values or the matching line, cut
+	// around the match when long
+	Context string
+}
+
+// SearchResult holds the matches of a search.
+type SearchResult struct {
+	// Matches lists the matches in dump order, up to the limit
+	Matches []SearchMatch
+
+	// Tables counts the matches in each table
+	Tables map[string]int
+
+	// Total counts every match
+	Total int
+}
+
+// SearchDump searches a mysqldump SQL dump line by line. In INSERT
+// statements each row is matched separately, so a match reports the row
+// that contains it.
+func SearchDump(r io.Reader, options SearchOptions) (*SearchResult, error) {
+	if options.Pattern == nil {
+		return nil, &ValidationError{Field: "Pattern", Message: "search pattern is required"}
+	}
+	if options.Context <= 0 {
+		options.Context = DefaultSearchContext
+	}
+
+	result := &SearchResult{Tables: make(map[string]int)}
+	reader := bufio.NewReaderSize(r, DefaultBufferSize)
+	table := ""
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if name, ok := sectionOwner(line); ok {
+				table = name
+			}
+			if options.Pattern.Match(line) {
+				searchLine(result, options, table, line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// searchLine records the matches of a line already known to match.
+func searchLine(result *SearchResult, options SearchOptions, table string, line []byte) {
+	candidates := [][]byte{bytes.TrimSpace(line)}
+	if bytes.HasPrefix(line, insertIntoPrefix) {
+		candidates = insertRows(line)
+	}
+
+	for _, candidate := range candidates {
+		loc := options.Pattern.FindIndex(candidate)
+		if loc == nil {
+			continue
+		}
+		result.Total++
+		result.Tables[table]++
+		if options.Limit > 0 && len(result.Matches) >= options.Limit {
+			continue
+		}
+		result.Matches = append(result.Matches, SearchMatch{
+			Table:   table,
+			Context: matchContext(candidate, loc, options.Context),
+		})
+	}
+}
+
+// insertRows splits an INSERT statement into its parenthesised rows.
+func insertRows(line []byte) [][]byte {
+	if i := bytes.Index(line, valuesKeyword); i >= 0 {
+		line = line[i+len(valuesKeyword):]
+	}
+
+	var rows [][]byte
+	var depth, start int
+	var inString, escaped bool
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			if c == '\\' {
+				escaped = true
+			} else if c == '\'' {
+				inString = false
+			}
+		case c == '\'':
+			inString = true
+		case c == '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					rows = append(rows, line[start:i+1])
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// matchContext returns text cut to at most size bytes around the match at
+// loc, marking the cuts with "...".
+func matchContext(text []byte, loc []int, size int) string {
+	if len(text) <= size {
+		return string(text)
+	}
+
+	start := loc[0] - (size-(loc[1]-loc[0]))/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + size
+	if end > len(text) {
+		end = len(text)
+		start = end - size
+	}
+
+	context := strings.ToValidUTF8(string(text[start:end]), "")
+	if start > 0 {
+		context = "..." + context
+	}
+	if end < len(text) {
+		context += "..."
+	}
+	return context
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const searchDump = "-- MySQL dump\n" +
+	"-- Table structure for table `users`\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `email` varchar(100) NOT NULL\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `users` VALUES (1,'jane@example.com'),(2,'bob (x), \\'q\\''),(3,'JANE@example.com');\n" +
+	"-- Table structure for table `orders`\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `note` text\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `orders` VALUES (10,'ship to jane@example.com');\n"
+
+func TestSearchDump(t *testing.T) {
+	result, err := SearchDump(strings.NewReader(searchDump), SearchOptions{
+		Pattern: regexp.MustCompile(regexp.QuoteMeta("jane@example.com")),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, map[string]int{"users": 1, "orders": 1}, result.Tables)
+	require.Len(t, result.Matches, 2)
+	assert.Equal(t, SearchMatch{Table: "users", Context: "(1,'jane@example.com')"}, result.Matches[0])
+	assert.Equal(t, SearchMatch{Table: "orders", Context: "(10,'ship to jane@example.com')"}, result.Matches[1])
+}
+
+func TestSearchDumpOptions(t *testing.T) {
+	t.Run("case insensitive with limit", func(t *testing.T) {
+		result, err := SearchDump(strings.NewReader(searchDump), SearchOptions{
+			Pattern: regexp.MustCompile("(?i)jane@"),
+			Limit:   1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 2, result.Tables["users"])
+		assert.Len(t, result.Matches, 1)
+	})
+
+	t.Run("quoted parentheses stay in the row", func(t *testing.T) {
+		result, err := SearchDump(strings.NewReader(searchDump), SearchOptions{Pattern: regexp.MustCompile("bob")})
+		require.NoError(t, err)
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, `(2,'bob (x), \'q\'')`, result.Matches[0].Context)
+	})
+
+	t.Run("schema lines", func(t *testing.T) {
+		result, err := SearchDump(strings.NewReader(searchDump), SearchOptions{Pattern: regexp.MustCompile("`note`")})
+		require.NoError(t, err)
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, SearchMatch{Table: "orders", Context: "`note` text"}, result.Matches[0])
+	})
+
+	t.Run("requires a pattern", func(t *testing.T) {
+		_, err := SearchDump(strings.NewReader(searchDump), SearchOptions{})
+		assert.True(t, IsValidationError(err))
+	})
+}
+
+func TestMatchContext(t *testing.T) {
+	text := []byte(strings.Repeat("a", 50) + "MATCH" + strings.Repeat("b", 50))
+	context := matchContext(text, []int{50, 55}, 15)
+	assert.Equal(t, "...aaaaaMATCHbbbbb...", context)
+
+	assert.Equal(t, "short", matchContext([]byte("short"), []int{0, 5}, 15))
+}
@@ -0,0 +1,26 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// DiscoverSchemas returns the databases on the server client is connected
+// to, sorted and leaving out the system schemas. It is how a server entry
+// that backs up "*" finds its schemas.
+func DiscoverSchemas(client Introspector) ([]string, error) {
+	databases, err := client.GetDatabases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	var schemas []string
+	for _, database := range databases {
+		if !mysql.IsSystemDatabase(database) {
+			schemas = append(schemas, database)
+		}
+	}
+	sort.Strings(schemas)
+	return schemas, nil
+}
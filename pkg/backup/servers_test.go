@@ -0,0 +1,24 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverSchemas(t *testing.T) {
+	client := mysql.NewMockClient()
+	client.Databases = []string{"shop", "mysql", "information_schema", "blog", "performance_schema", "sys"}
+	require.NoError(t, client.Connect())
+
+	schemas, err := DiscoverSchemas(client)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blog", "shop"}, schemas)
+
+	client.Databases = []string{"mysql", "sys"}
+	schemas, err = DiscoverSchemas(client)
+	require.NoError(t, err)
+	assert.Empty(t, schemas)
+}
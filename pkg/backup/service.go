@@ -0,0 +1,918 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// Service orchestrates backup operations.
+type Service struct {
+	client  Introspector
+	engine  Engine
+	storage *storage.LocalStorage
+	config  *mysql.Config
+	verbose bool
+	quota   *Quota
+
+	// logOutput receives debug and warning messages
+	logOutput io.Writer
+
+	// progress receives the bytes dumped so far, if set
+	progress ProgressFunc
+}
+
+// NewService creates a new backup service using the MySQL engine.
+func NewService(client Introspector, stor *storage.LocalStorage, config *mysql.Config) *Service {
+	return &Service{
+		client:    client,
+		engine:    defaultEngine(),
+		storage:   stor,
+		config:    config,
+		verbose:   false,
+		logOutput: os.Stdout,
+	}
+}
+
+// SetLogOutput sets where debug and warning messages are written.
+func (s *Service) SetLogOutput(w io.Writer) {
+	s.logOutput = w
+}
+
+// SetEngine sets the database engine used to dump backups.
+func (s *Service) SetEngine(engine Engine) {
+	s.engine = engine
+}
+
+// SetVerbose enables or disables verbose logging.
+func (s *Service) SetVerbose(verbose bool) {
+	s.verbose = verbose
+}
+
+// SetProgress reports the uncompressed bytes dumped to fn while backing up.
+// Backups made with mydumper do not report progress.
+func (s *Service) SetProgress(fn ProgressFunc) {
+	s.progress = fn
+}
+
+// SetQuota sets the storage quota checked before each backup.
+// A nil quota disables the check.
+func (s *Service) SetQuota(quota *Quota) {
+	s.quota = quota
+}
+
+// getStorageName returns the name to use for storage paths.
+// Uses ConfigName if available, otherwise falls back to Database name.
+func getStorageName(options *BackupOptions) string {
+	if options.ConfigName != "" {
+		return options.ConfigName
+	}
+	return options.Database
+}
+
+// Backup performs a complete backup operation.
+func (s *Service) Backup(options *BackupOptions) (*BackupResult, error) {
+	if options == nil {
+		options = DefaultOptions()
+	}
+
+	// Validate options
+	if err := s.validateOptions(options); err != nil {
+		return nil, err
+	}
+
+	// Get storage name (config name if available, otherwise database name)
+	storageName := getStorageName(options)
+
+	// Ensure database directory exists
+	if err := s.storage.EnsureDatabaseDir(storageName); err != nil {
+		return nil, err
+	}
+
+	// Generate backup ID
+	backupID := s.uniqueBackupID(storageName)
+	startTime := time.Now()
+
+	// Ensure the backup's directory exists (date layouts nest by month)
+	if err := s.storage.EnsureBackupDir(storageName, backupID); err != nil {
+		return nil, err
+	}
+
+	// Initialize result
+	result := &BackupResult{
+		BackupID:  backupID,
+		StartedAt: startTime,
+		Status:    StatusRunning,
+	}
+
+	// Get file paths
+	result.FilePath = s.storage.GetBackupPath(storageName, backupID, options.Compression)
+	if options.Engine == EngineMydumper {
+		result.FilePath = s.storage.GetArchivePath(storageName, backupID)
+	}
+	result.MetadataPath = s.storage.GetMetadataPath(storageName, backupID)
+
+	// Estimate the backup's size once for the space and quota checks
+	result.DatabaseSize = s.backupDatabaseSize(options)
+	estimatedSize := s.estimateSize(options, result.DatabaseSize)
+
+	// Check disk space, keeping it reserved while the backup runs
+	release, err := s.checkDiskSpace(result.FilePath, estimatedSize)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Check storage quota
+	if err := s.checkQuota(storageName, estimatedSize); err != nil {
+		return nil, err
+	}
+
+	// Check for queries that would block the dump's locks
+	if err := s.checkLongQueries(options); err != nil {
+		return nil, err
+	}
+	if err := s.checkClientVersion(options, result); err != nil {
+		return nil, err
+	}
+	result.Charset = backupCharset(s.client, options)
+	result.SQLMode = serverSQLMode(s.client)
+
+	// Create initial metadata
+	metadata := CreateInitialMetadata(backupID, options.Database, s.config, options)
+	metadata.Database.Type = s.engine.Name()
+
+	// Perform backup with cleanup on failure
+	err = s.performBackup(options, result)
+	if err != nil {
+		// Clean up partial backup
+		s.storage.CleanupPartialBackup(storageName, backupID, options.Compression)
+		if options.Engine == EngineMydumper {
+			os.Remove(result.FilePath)
+		}
+
+		// Mark metadata as failed
+		MarkFailed(metadata, err)
+		s.storage.SaveMetadata(storageName, backupID, metadata)
+
+		return nil, err
+	}
+
+	// Calculate final result
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(result.StartedAt)
+	result.Status = StatusCompleted
+
+	// Get dump tool version
+	dumperVersion := result.DumperVersion
+	if options.Engine == EngineMydumper {
+		dumperVersion, _ = CheckMydumper()
+	} else if dumperVersion == "" {
+		dumperVersion, _ = s.engine.DumpToolVersion(MySQLConnection(s.config))
+	}
+
+	// Generate final metadata
+	metaGen := NewMetadataGenerator(s.client)
+	finalMetadata, err := metaGen.Generate(backupID, s.config, result, options, dumperVersion)
+	if err != nil {
+		return nil, WrapMetadataError(backupID, "failed to generate metadata", err)
+	}
+	finalMetadata.Database.Type = s.engine.Name()
+
+	// Save metadata
+	if err := s.storage.SaveMetadata(storageName, backupID, finalMetadata); err != nil {
+		return nil, err
+	}
+
+	// Bring metadata of older backups up to date; failures are not fatal
+	if _, err := MigrateMetadata(s.storage, storageName); err != nil && s.verbose {
+		fmt.Fprintf(s.logOutput, "[WARNING] Failed to migrate backup metadata: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// performBackup executes the actual backup process.
+func (s *Service) performBackup(options *BackupOptions, result *BackupResult) (err error) {
+	if options.Engine == EngineMydumper {
+		return s.performMydumperBackup(options, result)
+	}
+
+	// Create mysqldump options
+	dumpOpts := newDumpOptions(options, result)
+
+	// Create dumper for the configured engine
+	dumper := s.engine.NewDumper(MySQLConnection(s.config))
+	s.attachStderrLogger(dumper)
+
+	// Get dump reader, recording the command for metadata
+	cmdLogger := s.commandLogger(result)
+
+	var dumpReader io.ReadCloser
+	dumpReader, err = dumper.DumpWithCommand(options.Database, dumpOpts, cmdLogger)
+	if err != nil {
+		return WrapBackupError(options.Database, "failed to start dump", err)
+	}
+	defer func() {
+		// Capture any errors from closing (which includes stderr warnings)
+		closeErr := dumpReader.Close()
+		if wr, ok := dumpReader.(WarningReporter); ok {
+			result.Warnings = wr.Warnings()
+		}
+		if closeErr != nil {
+			// If we haven't already set an error, use the close error
+			if err == nil {
+				err = WrapBackupError(options.Database, "mysqldump warnings detected", closeErr)
+			}
+		}
+	}()
+
+	// Watch for queries blocked by the dump; an abort explains the failure
+	input, stopMonitor := s.watchLocks(options, dumpReader)
+	defer func() {
+		if abortErr := stopMonitor(); abortErr != nil {
+			err = WrapBackupError(options.Database, "lock monitor", abortErr)
+		}
+	}()
+	input = rewriteBackup(input, options)
+
+	// Create compressor
+	compressor := NewCompressor(options.Compression)
+	compressor.SetPipelined(true)
+	if options.Indexed {
+		compressor.SetBlockSize(DefaultIndexBlockSize)
+	}
+
+	// Stream dump to compressed file with checksum
+	compressResult, err := compressor.StreamCompress(ProgressReader(input, s.progress), result.FilePath)
+	if err != nil {
+		return WrapBackupError(options.Database, "failed to compress backup", err)
+	}
+
+	// Update result with compression info
+	result.SizeBytes = compressResult.BytesWritten
+	result.Checksum = compressResult.Checksum
+	result.RawChecksum = compressResult.RawChecksum
+	result.Index = compressResult.Index
+
+	// Check if backup size is suspiciously small (might indicate schema-only dump)
+	// Warn if backup is less than 1MB for a database that should be large
+	if result.SizeBytes < 1024*1024 && !options.SchemaOnly {
+		// Print warning but continue with backup
+		if s.verbose {
+			warningMsg := fmt.Sprintf("[WARNING] Backup size is small (%s). ", FormatBytes(result.SizeBytes))
+			warningMsg += "This might indicate:\n"
+			warningMsg += "  - Database is legitimately small\n"
+			warningMsg += "  - Only schema was backed up (check permissions)\n"
+			warningMsg += "  - Tables are empty or have restricted access\n"
+			warningMsg += "  - Verify the database actually contains data\n"
+			fmt.Fprintf(s.logOutput, "%s\n", warningMsg)
+		} else {
+			fmt.Fprintf(s.logOutput, "⚠ Warning: Backup size is small (%s). This might indicate only schema was backed up.\n", FormatBytes(result.SizeBytes))
+			fmt.Fprintf(s.logOutput, "  Verify the database contains data and check MySQL user permissions.\n")
+		}
+		// Continue with backup - don't return error
+	}
+
+	return err
+}
+
+// BackupToWriter dumps and compresses a database directly into w, bypassing
+// managed storage. No metadata is saved and no retention applies.
+func (s *Service) BackupToWriter(options *BackupOptions, w io.Writer) (*BackupResult, error) {
+	if options == nil {
+		options = DefaultOptions()
+	}
+
+	if err := s.validateOptions(options); err != nil {
+		return nil, err
+	}
+
+	if options.Engine == EngineMydumper {
+		return nil, &ValidationError{
+			Field:   "Engine",
+			Message: "mydumper produces multiple files and cannot be streamed",
+		}
+	}
+
+	result := &BackupResult{
+		BackupID:  GenerateBackupID(),
+		StartedAt: time.Now(),
+		Status:    StatusRunning,
+	}
+
+	if err := s.checkLongQueries(options); err != nil {
+		return nil, err
+	}
+	if err := s.checkClientVersion(options, result); err != nil {
+		return nil, err
+	}
+	result.Charset = backupCharset(s.client, options)
+	result.SQLMode = serverSQLMode(s.client)
+
+	dumpOpts := newDumpOptions(options, result)
+
+	cmdLogger := s.commandLogger(result)
+
+	dumper := s.engine.NewDumper(MySQLConnection(s.config))
+	s.attachStderrLogger(dumper)
+	dumpReader, err := dumper.DumpWithCommand(options.Database, dumpOpts, cmdLogger)
+	if err != nil {
+		return nil, WrapBackupError(options.Database, "failed to start dump", err)
+	}
+
+	compressor := NewCompressor(options.Compression)
+	compressor.SetPipelined(true)
+
+	input, stopMonitor := s.watchLocks(options, dumpReader)
+	input = rewriteBackup(input, options)
+	compressResult, err := compressor.Compress(ProgressReader(input, s.progress), w)
+	abortErr := stopMonitor()
+	closeErr := dumpReader.Close()
+	if wr, ok := dumpReader.(WarningReporter); ok {
+		result.Warnings = wr.Warnings()
+	}
+	if abortErr != nil {
+		return nil, WrapBackupError(options.Database, "lock monitor", abortErr)
+	}
+	if err != nil {
+		return nil, WrapBackupError(options.Database, "failed to compress backup", err)
+	}
+	if closeErr != nil {
+		return nil, WrapBackupError(options.Database, "dump failed", closeErr)
+	}
+
+	result.SizeBytes = compressResult.BytesWritten
+	result.Checksum = compressResult.Checksum
+	result.RawChecksum = compressResult.RawChecksum
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(result.StartedAt)
+	result.Status = StatusCompleted
+
+	return result, nil
+}
+
+// performMydumperBackup runs mydumper into a temporary directory next to the
+// backup and packs its output into a single archive.
+func (s *Service) performMydumperBackup(options *BackupOptions, result *BackupResult) error {
+	dumpOpts := newDumpOptions(options, result)
+
+	// Keep the temporary directory on the same filesystem as the backup
+	tmpDir, err := os.MkdirTemp(filepath.Dir(result.FilePath), ".mydumper-")
+	if err != nil {
+		return WrapStorageError(filepath.Dir(result.FilePath), "create", "failed to create temporary dump directory", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cmdLogger := s.commandLogger(result)
+
+	dumper := NewMydumperDumper(s.config)
+	compress := options.Compression == CompressionGzip
+	if err := dumper.DumpToDirectory(options.Database, tmpDir, dumpOpts, options.Threads, compress, cmdLogger); err != nil {
+		return WrapBackupError(options.Database, "mydumper failed", err)
+	}
+
+	archiveResult, err := ArchiveDirectory(tmpDir, result.FilePath)
+	if err != nil {
+		return WrapBackupError(options.Database, "failed to archive mydumper output", err)
+	}
+
+	result.SizeBytes = archiveResult.BytesWritten
+	result.Checksum = archiveResult.Checksum
+	result.RawChecksum = archiveResult.RawChecksum
+
+	return nil
+}
+
+// attachStderrLogger shows the dump tool's stderr as it runs in verbose mode.
+func (s *Service) attachStderrLogger(dumper Dumper) {
+	if sl, ok := dumper.(StderrLogger); ok && s.verbose {
+		sl.SetStderrLogger(func(line string) {
+			fmt.Fprintf(s.logOutput, "[STDERR] %s\n", line)
+		})
+	}
+}
+
+// newDumpOptions creates the dump tool options for a backup, dumping in
+// the database's character set when it is known.
+func newDumpOptions(options *BackupOptions, result *BackupResult) *DumpOptions {
+	dumpOpts := &DumpOptions{
+		Tables:          options.Tables,
+		ExcludeTables:   options.ExcludeTables,
+		SchemaOnly:      options.SchemaOnly,
+		Routines:        true,
+		Triggers:        true,
+		Events:          true,
+		ExtraArgs:       options.DumpArgs,
+		DisableDefaults: options.DisableDumpDefaults,
+		WarningPolicy:   options.WarningPolicy,
+		Databases:       options.Databases,
+	}
+	if result.Charset != nil {
+		dumpOpts.CharacterSet = DumpCharset(result.Charset.Name)
+	}
+	return dumpOpts
+}
+
+// rewriteBackup wraps a dump in the DEFINER and SQL SECURITY rewrites the
+// options ask for.
+func rewriteBackup(r io.Reader, options *BackupOptions) io.Reader {
+	rewrite := DefinerRewrite(options.Definers, options.SQLSecurityInvoker)
+	if !rewrite.enabled() {
+		return r
+	}
+	return NewDumpRewriter(r, rewrite)
+}
+
+// commandLogger returns a logger that records the (password-masked) dump
+// command in result and prints it in verbose mode.
+func (s *Service) commandLogger(result *BackupResult) func(string) {
+	return func(cmd string) {
+		result.DumpCommand = cmd
+		if s.verbose {
+			fmt.Fprintf(s.logOutput, "[DEBUG] Executing: %s\n", cmd)
+		}
+	}
+}
+
+// validateOptions validates backup options.
+func (s *Service) validateOptions(options *BackupOptions) error {
+	if len(options.Databases) > 0 {
+		if err := validateDatabases(options); err != nil {
+			return err
+		}
+		if options.Database == "" {
+			options.Database = SchemaSetName(options.Databases)
+		}
+	}
+	if options.Database == "" {
+		return ErrDatabaseRequired
+	}
+
+	// Validate compression type
+	switch options.Compression {
+	case CompressionGzip, CompressionNone:
+		// Valid
+	case CompressionZstd:
+		return &ValidationError{
+			Field:   "Compression",
+			Message: "zstd compression not yet implemented",
+		}
+	default:
+		return &ValidationError{
+			Field:   "Compression",
+			Message: fmt.Sprintf("invalid compression type: %s", options.Compression),
+		}
+	}
+
+	// Validate engine
+	switch options.Engine {
+	case "", EngineMysqldump, EngineMydumper:
+		// Valid
+	default:
+		return &ValidationError{
+			Field:   "Engine",
+			Message: fmt.Sprintf("invalid engine: %s", options.Engine),
+		}
+	}
+
+	if options.Indexed && (options.Compression != CompressionGzip || options.Engine == EngineMydumper) {
+		return &ValidationError{
+			Field:   "Indexed",
+			Message: "indexed backups require gzip compression and mysqldump",
+		}
+	}
+
+	if len(options.DumpArgs) > 0 || len(options.DisableDumpDefaults) > 0 {
+		if options.Engine == EngineMydumper {
+			return &ValidationError{
+				Field:   "DumpArgs",
+				Message: "mysqldump arguments cannot be used with mydumper",
+			}
+		}
+		if err := ValidateDumpArgs(options.DumpArgs, options.DisableDumpDefaults); err != nil {
+			return err
+		}
+	}
+
+	if options.WarningPolicy != nil {
+		if err := options.WarningPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if options.LongQueryPolicy != nil {
+		if err := options.LongQueryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if options.LockMonitorPolicy != nil {
+		if err := options.LockMonitorPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateDefiners(options.Definers); err != nil {
+		return err
+	}
+	if options.Engine == EngineMydumper && DefinerRewrite(options.Definers, options.SQLSecurityInvoker).enabled() {
+		return &ValidationError{
+			Field:   "Definers",
+			Message: "definers cannot be rewritten in mydumper backups",
+		}
+	}
+
+	if options.Threads < 0 {
+		return &ValidationError{
+			Field:   "Threads",
+			Message: "threads cannot be negative",
+		}
+	}
+
+	// Validate tables and exclude tables don't overlap
+	if len(options.Tables) > 0 && len(options.ExcludeTables) > 0 {
+		return &ValidationError{
+			Field:   "Tables",
+			Message: "cannot specify both tables and exclude_tables",
+		}
+	}
+
+	return nil
+}
+
+// checkDiskSpace verifies there is enough disk space for a backup of
+// estimatedSize bytes written to path, on top of what other backups running
+// in this process still need, and reserves it. The returned function
+// releases the reservation and must be called once the backup has finished.
+func (s *Service) checkDiskSpace(path string, estimatedSize int64) (func(), error) {
+	release, hasSpace, pending, err := reserveSpace(s.storage, path, estimatedSize)
+	if err != nil {
+		return nil, WrapStorageError(s.storage.GetBasePath(), "check", "failed to check disk space", err)
+	}
+
+	if !hasSpace {
+		available, _ := s.storage.CheckDiskSpace()
+		message := fmt.Sprintf("insufficient disk space: need ~%s, have %s", FormatBytes(estimatedSize), FormatBytes(int64(available)))
+		if pending > 0 {
+			message += fmt.Sprintf(" (~%s more held for backups in progress)", FormatBytes(pending))
+		}
+		return nil, &StorageError{
+			Path:    s.storage.GetBasePath(),
+			Op:      "check",
+			Message: message,
+		}
+	}
+
+	return release, nil
+}
+
+// EstimateSize estimates the size of the backup described by options.
+func (s *Service) EstimateSize(options *BackupOptions) int64 {
+	return s.estimateSize(options, s.backupDatabaseSize(options))
+}
+
+// estimateSize estimates the size of the backup described by options of a
+// database of databaseSize bytes (0 if unknown). The ratio seen in the
+// database's previous backups is preferred over the fixed heuristic. Falls
+// back to 1GB if neither history nor the database size is available.
+func (s *Service) estimateSize(options *BackupOptions, databaseSize int64) int64 {
+	if size, err := EstimateSizeFromHistory(s.storage, getStorageName(options), options, databaseSize); err == nil && size > 0 {
+		return size
+	}
+	if databaseSize > 0 {
+		// Estimate compressed size (typically 30-40% of original)
+		return EstimateBackupSize(databaseSize, options.Compression)
+	}
+	return 1024 * 1024 * 1024 // Default 1GB
+}
+
+// databaseSize returns the size of database on the server, or 0 if it
+// cannot be determined.
+func (s *Service) databaseSize(database string) int64 {
+	if s.client == nil || !s.client.IsConnected() {
+		return 0
+	}
+	size, err := s.client.GetDatabaseSize(database)
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
+
+// backupDatabaseSize returns the size on the server of the databases the
+// options back up, or 0 if it cannot be determined.
+func (s *Service) backupDatabaseSize(options *BackupOptions) int64 {
+	if len(options.Databases) == 0 {
+		return s.databaseSize(options.Database)
+	}
+	var total int64
+	for _, database := range options.Databases {
+		total += s.databaseSize(database)
+	}
+	return total
+}
+
+// checkQuota verifies the backup will not push storage usage past the quota.
+func (s *Service) checkQuota(storageName string, needed int64) error {
+	if s.quota == nil || (s.quota.DatabaseLimit <= 0 && s.quota.TotalLimit <= 0) {
+		return nil
+	}
+
+	if s.quota.DatabaseLimit > 0 {
+		usage, err := s.storage.UsageBytes(storageName)
+		if err != nil {
+			return WrapStorageError(s.storage.GetDatabasePath(storageName), "check", "failed to calculate storage usage", err)
+		}
+		if usage+needed > s.quota.DatabaseLimit {
+			return &QuotaExceededError{Scope: storageName, Limit: s.quota.DatabaseLimit, Usage: usage, Needed: needed}
+		}
+	}
+
+	if s.quota.TotalLimit > 0 {
+		usage, err := s.storage.TotalUsageBytes()
+		if err != nil {
+			return WrapStorageError(s.storage.GetBasePath(), "check", "failed to calculate storage usage", err)
+		}
+		if usage+needed > s.quota.TotalLimit {
+			return &QuotaExceededError{Limit: s.quota.TotalLimit, Usage: usage, Needed: needed}
+		}
+	}
+
+	return nil
+}
+
+// checkClientVersion compares the dump tool with the server's release and
+// applies the version check of the options, recording both versions and
+// any mismatch in the result. See ClientCompatibility.
+func (s *Service) checkClientVersion(options *BackupOptions, result *BackupResult) error {
+	if options.Engine == EngineMydumper || options.VersionCheck == VersionCheckIgnore {
+		return nil
+	}
+	clientVersion, err := s.engine.DumpToolVersion(MySQLConnection(s.config))
+	if err != nil {
+		return nil
+	}
+	serverVersion, err := s.client.GetVersion()
+	if err != nil {
+		return nil
+	}
+	result.DumperVersion = clientVersion
+	result.ServerVersion = serverVersion
+
+	warning := ClientCompatibility(clientVersion, serverVersion)
+	if warning == "" {
+		return nil
+	}
+	if options.VersionCheck == VersionCheckFail {
+		return WrapBackupError(options.Database, "version check failed", errors.New(warning))
+	}
+	result.Compatibility = warning
+	fmt.Fprintf(s.logOutput, "⚠ Warning: %s\n", warning)
+	return nil
+}
+
+// checkLongQueries applies the long query policy of the options, printing a
+// warning for each long-running query found.
+func (s *Service) checkLongQueries(options *BackupOptions) error {
+	if options.LongQueryPolicy == nil {
+		return nil
+	}
+
+	warnings, err := options.LongQueryPolicy.Check(s.client)
+	for _, warning := range warnings {
+		fmt.Fprintf(s.logOutput, "⚠ Warning: long-running %s\n", warning)
+	}
+	if err != nil {
+		return WrapBackupError(options.Database, "long query check failed", err)
+	}
+	return nil
+}
+
+// watchLocks starts the lock monitor of the options on the dump. It returns
+// the reader to take the dump from, and a function stopping the monitor that
+// returns why the backup was aborted, if it was.
+func (s *Service) watchLocks(options *BackupOptions, dump io.Reader) (io.Reader, func() error) {
+	if options.LockMonitorPolicy == nil {
+		return dump, func() error { return nil }
+	}
+	monitor := NewLockMonitor(options.LockMonitorPolicy, s.client, func(message string) {
+		fmt.Fprintf(s.logOutput, "⚠ Warning: %s\n", message)
+	})
+	if monitor == nil {
+		return dump, func() error { return nil }
+	}
+	return monitor.Watch(dump), monitor.Stop
+}
+
+// ListBackups lists all backups for a database.
+func (s *Service) ListBackups(database string) ([]BackupListEntry, error) {
+	storageList, err := s.storage.ListBackups(database)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert storage.BackupListEntry to backup.BackupListEntry
+	backupList := make([]BackupListEntry, len(storageList))
+	for i, entry := range storageList {
+		backupList[i] = BackupListEntry{
+			BackupID:     entry.BackupID,
+			Database:     entry.Database,
+			CreatedAt:    entry.CreatedAt,
+			SizeBytes:    entry.SizeBytes,
+			SizeHuman:    entry.SizeHuman,
+			Status:       entry.Status,
+			FilePath:     entry.FilePath,
+			MetadataPath: entry.MetadataPath,
+			Lock:         entry.Lock,
+		}
+	}
+
+	return backupList, nil
+}
+
+// uniqueBackupID generates a backup ID that is not already used in storage.
+// IDs have millisecond resolution, so on collision it waits for the next tick.
+func (s *Service) uniqueBackupID(storageName string) string {
+	for {
+		backupID := GenerateBackupID()
+		if _, err := os.Stat(s.storage.GetMetadataPath(storageName, backupID)); err != nil {
+			return backupID
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// GetBackup retrieves metadata for a specific backup.
+func (s *Service) GetBackup(database, backupID string) (*BackupMetadata, error) {
+	var metadata BackupMetadata
+	err := s.storage.LoadMetadata(database, backupID, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// GetLatestBackup retrieves the most recent backup for a database.
+func (s *Service) GetLatestBackup(database string) (*BackupListEntry, error) {
+	storageEntry, err := s.storage.GetLatestBackup(database)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert storage.BackupListEntry to backup.BackupListEntry
+	return &BackupListEntry{
+		BackupID:     storageEntry.BackupID,
+		Database:     storageEntry.Database,
+		CreatedAt:    storageEntry.CreatedAt,
+		SizeBytes:    storageEntry.SizeBytes,
+		SizeHuman:    storageEntry.SizeHuman,
+		Status:       storageEntry.Status,
+		FilePath:     storageEntry.FilePath,
+		MetadataPath: storageEntry.MetadataPath,
+		Lock:         storageEntry.Lock,
+	}, nil
+}
+
+// DeleteBackup deletes a backup and its metadata.
+func (s *Service) DeleteBackup(database, backupID string) error {
+	return s.storage.DeleteBackup(database, backupID)
+}
+
+// VerifyBackup verifies a backup's integrity by checking its checksum.
+func (s *Service) VerifyBackup(database, backupID string) (bool, error) {
+	// Load metadata
+	var metadata BackupMetadata
+	err := s.storage.LoadMetadata(database, backupID, &metadata)
+	if err != nil {
+		return false, err
+	}
+
+	// Get backup file path
+	backupPath := s.storage.GetBackupPath(database, backupID, metadata.Backup.Compression)
+
+	// Verify checksum
+	valid, err := VerifyChecksum(backupPath, metadata.Backup.FileChecksum())
+	if err != nil {
+		return false, WrapBackupError(database, "failed to verify checksum", err)
+	}
+
+	return valid, nil
+}
+
+// GetBackupSize returns the size of a backup in bytes.
+func (s *Service) GetBackupSize(database, backupID string) (int64, error) {
+	// Load metadata
+	var metadata BackupMetadata
+	err := s.storage.LoadMetadata(database, backupID, &metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	return metadata.Backup.SizeBytes, nil
+}
+
+// QuickBackup performs a backup with default options.
+func (s *Service) QuickBackup(database string) (*BackupResult, error) {
+	options := DefaultOptions()
+	options.Database = database
+	return s.Backup(options)
+}
+
+// SchemaBackup performs a schema-only backup.
+func (s *Service) SchemaBackup(database string) (*BackupResult, error) {
+	options := DefaultOptions()
+	options.Database = database
+	options.SchemaOnly = true
+	return s.Backup(options)
+}
+
+// TableBackup backs up specific tables.
+func (s *Service) TableBackup(database string, tables []string) (*BackupResult, error) {
+	options := DefaultOptions()
+	options.Database = database
+	options.Tables = tables
+	return s.Backup(options)
+}
+
+// BackupWithProgress performs a backup with progress callback.
+// The callback receives progress updates during the backup.
+type ProgressCallback func(progress *BackupProgress)
+
+func (s *Service) BackupWithProgress(options *BackupOptions, callback ProgressCallback) (*BackupResult, error) {
+	// For now, just call regular Backup
+	// Progress tracking will be implemented in a future version
+	if callback != nil {
+		callback(&BackupProgress{
+			Phase:   PhaseConnecting,
+			Message: "Starting backup...",
+		})
+	}
+
+	result, err := s.Backup(options)
+
+	if callback != nil {
+		if err != nil {
+			callback(&BackupProgress{
+				Phase:   PhaseFinalizing,
+				Message: fmt.Sprintf("Backup failed: %v", err),
+			})
+		} else {
+			callback(&BackupProgress{
+				Phase:        PhaseFinalizing,
+				Message:      "Backup completed successfully",
+				BytesWritten: result.SizeBytes,
+			})
+		}
+	}
+
+	return result, err
+}
+
+// EstimateDatabaseSize estimates the size of a database.
+func (s *Service) EstimateDatabaseSize(database string) (int64, error) {
+	if s.client == nil || !s.client.IsConnected() {
+		return 0, fmt.Errorf("client not connected")
+	}
+
+	return s.client.GetDatabaseSize(database)
+}
+
+// CheckConnectivity verifies connection to the database.
+func (s *Service) CheckConnectivity() error {
+	if s.client == nil {
+		return fmt.Errorf("client not initialized")
+	}
+
+	if !s.client.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	return s.client.Ping()
+}
+
+// extractStderrFromError extracts stderr from a DumpError in the error chain.
+func extractStderrFromError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dumpErr *DumpError
+	if errors.As(err, &dumpErr) && dumpErr.Stderr != "" {
+		return dumpErr.Stderr
+	}
+
+	// Check wrapped errors
+	unwrapped := errors.Unwrap(err)
+	if unwrapped != nil {
+		return extractStderrFromError(unwrapped)
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDumper returns a fixed dump and records the options it was called with.
+type fakeDumper struct {
+	data     string
+	closeErr error
+	database string
+	options  *DumpOptions
+}
+
+func (d *fakeDumper) DumpWithCommand(database string, options *DumpOptions, cmdLogger func(string)) (io.ReadCloser, error) {
+	d.database = database
+	d.options = options
+	return &fakeDumpReader{Reader: strings.NewReader(d.data), closeErr: d.closeErr}, nil
+}
+
+type fakeDumpReader struct {
+	io.Reader
+	closeErr error
+}
+
+func (r *fakeDumpReader) Close() error {
+	return r.closeErr
+}
+
+func TestBackupToWriter(t *testing.T) {
+	dumper := &fakeDumper{data: "CREATE TABLE t (id INT);\n"}
+	service := NewService(mysql.NewMockClient(), nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: dumper})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.Tables = []string{"orders"}
+
+	var output bytes.Buffer
+	result, err := service.BackupToWriter(options, &output)
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusCompleted, result.Status)
+	assert.Equal(t, int64(output.Len()), result.SizeBytes)
+	assert.Equal(t, "shop", dumper.database)
+	assert.Equal(t, []string{"orders"}, dumper.options.Tables)
+
+	// Output is a gzip stream of the dump
+	var decompressed bytes.Buffer
+	_, err = NewDecompressor(CompressionGzip).Decompress(&output, &decompressed)
+	require.NoError(t, err)
+	assert.Equal(t, dumper.data, decompressed.String())
+}
+
+func TestBackupToWriterDumpFailure(t *testing.T) {
+	dumper := &fakeDumper{data: "partial", closeErr: errors.New("mysqldump: access denied")}
+	service := NewService(mysql.NewMockClient(), nil, &mysql.Config{})
+	service.SetEngine(&fakeEngine{name: "fake", dumper: dumper})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+
+	_, err := service.BackupToWriter(options, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestBackupToWriterRejectsMydumper(t *testing.T) {
+	service := NewService(mysql.NewMockClient(), nil, &mysql.Config{})
+
+	options := DefaultOptions()
+	options.Database = "shop"
+	options.Engine = EngineMydumper
+
+	_, err := service.BackupToWriter(options, io.Discard)
+	assert.True(t, IsValidationError(err))
+}
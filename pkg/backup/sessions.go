@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+)
+
+// Actions for connections using a database about to be restored.
+const (
+	// SessionActionBlock refuses to restore while connections use the
+	// database (default)
+	SessionActionBlock = "block"
+
+	// SessionActionWarn reports the connections and restores anyway
+	SessionActionWarn = "warn"
+
+	// SessionActionKill kills the connections first
+	SessionActionKill = "kill"
+)
+
+// ValidateSessionAction checks an action for active sessions.
+func ValidateSessionAction(action string) error {
+	switch action {
+	case "", SessionActionBlock, SessionActionWarn, SessionActionKill:
+		return nil
+	default:
+		return &ValidationError{
+			Field:   "sessions",
+			Message: fmt.Sprintf("invalid session action: %s (must be block, warn or kill)", action),
+		}
+	}
+}
+
+// ActiveSessions returns the connections whose current database is one of
+// databases. Servers that cannot list their connections have none.
+func ActiveSessions(client Introspector, databases []string) ([]mysql.Process, error) {
+	inspector, ok := client.(ProcessInspector)
+	if !ok {
+		return nil, nil
+	}
+
+	processes, err := inspector.GetProcessList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+
+	var sessions []mysql.Process
+	for _, process := range processes {
+		if process.Database != "" && slices.Contains(databases, process.Database) {
+			sessions = append(sessions, process)
+		}
+	}
+	return sessions, nil
+}
+
+// DescribeSession formats a connection using a database for warnings.
+func DescribeSession(process mysql.Process) string {
+	description := fmt.Sprintf("connection %d by %s@%s is using %s", process.ID, process.User, process.Host, process.Database)
+	if !process.Idle() {
+		description += ": " + truncateQuery(process.Info)
+	}
+	return description
+}
+
+// CheckSessions applies action to the connections using databases before a
+// restore writes to them. SessionActionBlock returns an error if there are
+// any, SessionActionKill kills them; the connections found are returned.
+func CheckSessions(client Introspector, databases []string, action string) ([]mysql.Process, error) {
+	if err := ValidateSessionAction(action); err != nil {
+		return nil, err
+	}
+
+	sessions, err := ActiveSessions(client, databases)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+
+	switch action {
+	case "", SessionActionBlock:
+		return sessions, fmt.Errorf("%d connections are using %s; pass --force to restore anyway or --kill-sessions to kill them",
+			len(sessions), strings.Join(databases, ", "))
+	case SessionActionKill:
+		inspector := client.(ProcessInspector)
+		for _, session := range sessions {
+			if err := inspector.KillConnection(session.ID); err != nil {
+				return sessions, fmt.Errorf("failed to kill connection %d: %w", session.ID, err)
+			}
+		}
+	}
+	return sessions, nil
+}
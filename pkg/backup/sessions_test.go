@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionClient returns a connected mock client with a query and an idle
+// connection using testdb and a connection using another database.
+func sessionClient() *mysql.MockClient {
+	client := mysql.NewMockClient()
+	client.SetConnected(true)
+	client.Processes = []mysql.Process{
+		{ID: 20, User: "app", Host: "10.0.0.5", Database: "testdb", Command: "Query", Time: time.Second, Info: "SELECT * FROM users"},
+		{ID: 21, User: "app", Host: "10.0.0.6", Database: "testdb", Command: "Sleep", Time: time.Minute},
+		{ID: 22, User: "report", Host: "10.0.0.7", Database: "stats", Command: "Sleep", Time: time.Minute},
+	}
+	return client
+}
+
+func TestCheckSessions(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		client := sessionClient()
+
+		sessions, err := CheckSessions(client, []string{"testdb"}, "")
+		assert.ErrorContains(t, err, "2 connections are using testdb")
+		assert.Len(t, sessions, 2)
+		assert.Equal(t, "connection 20 by app@10.0.0.5 is using testdb: SELECT * FROM users", DescribeSession(sessions[0]))
+		assert.Equal(t, "connection 21 by app@10.0.0.6 is using testdb", DescribeSession(sessions[1]))
+
+		_, err = CheckSessions(client, []string{"billing"}, SessionActionBlock)
+		assert.NoError(t, err)
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		client := sessionClient()
+
+		sessions, err := CheckSessions(client, []string{"testdb", "stats"}, SessionActionWarn)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 3)
+		assert.Equal(t, 0, client.GetCallCount("KillConnection"))
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		client := sessionClient()
+
+		_, err := CheckSessions(client, []string{"testdb"}, SessionActionKill)
+		require.NoError(t, err)
+		assert.Len(t, client.Processes, 1)
+
+		client = sessionClient()
+		client.KillErr = errors.New("access denied")
+		_, err = CheckSessions(client, []string{"testdb"}, SessionActionKill)
+		assert.ErrorContains(t, err, "failed to kill connection 20")
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		_, err := CheckSessions(sessionClient(), []string{"testdb"}, "wait")
+		assert.True(t, IsValidationError(err))
+	})
+}
+
+func TestRestoreServiceRestoreActiveSessions(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), "CREATE TABLE test (id INT);")
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip"))
+
+	setup := func() (*RestoreService, *mysql.MockClient, *fakeRestorer) {
+		restorer := &fakeRestorer{}
+		service := newSourceRestoreService(restorer, "testdb")
+		service.storage = localStorage
+		client := service.client.(*mysql.MockClient)
+		client.Processes = sessionClient().Processes
+		return service, client, restorer
+	}
+
+	service, _, restorer := setup()
+	_, err = service.Restore(&RestoreOptions{Database: "testdb", BackupID: backupID})
+	assert.True(t, IsRestoreError(err))
+	assert.Empty(t, restorer.sql)
+
+	service, client, _ := setup()
+	_, err = service.Restore(&RestoreOptions{Database: "testdb", BackupID: backupID, DryRun: true, SessionAction: SessionActionKill})
+	require.NoError(t, err)
+	assert.Equal(t, 0, client.GetCallCount("KillConnection"))
+
+	service, client, restorer = setup()
+	_, err = service.Restore(&RestoreOptions{Database: "testdb", BackupID: backupID, SessionAction: SessionActionKill})
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.GetCallCount("KillConnection"))
+	assert.Equal(t, "CREATE TABLE test (id INT);", restorer.sql)
+}
@@ -0,0 +1,238 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// StdinSource is the location that selects standard input as a restore source.
+const StdinSource = "-"
+
+// sourceTimeout is how long a download waits for the server to respond,
+// and then for each further piece of the backup. Downloads that keep
+// making progress may take as long as they need, and time spent waiting
+// for the restore to consume data does not count.
+var sourceTimeout = 30 * time.Second
+
+// sourceClient downloads restore sources. It has no overall timeout, which
+// would cut off large backups; openURL bounds every wait instead.
+var sourceClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: sourceTimeout,
+	},
+}
+
+// RestoreSource is a backup stream that lives outside managed storage:
+// a local file, standard input or an HTTP(S) URL.
+type RestoreSource struct {
+	// Location is the path, URL or "-" the source was opened from
+	Location string
+
+	// SizeBytes is the size of the source, or -1 if unknown
+	SizeBytes int64
+
+	// Compression is detected from the location's extension, or empty if unknown
+	Compression string
+
+	reader io.ReadCloser
+}
+
+// OpenRestoreSource opens a restore source from a file path, "-" for stdin,
+// or an http:// or https:// URL.
+func OpenRestoreSource(location string) (*RestoreSource, error) {
+	source := &RestoreSource{
+		Location:  location,
+		SizeBytes: -1,
+	}
+
+	switch {
+	case location == StdinSource:
+		source.reader = io.NopCloser(os.Stdin)
+
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		resp, body, err := openURL(location)
+		if err != nil {
+			return nil, WrapRestoreError("", "failed to download backup", err)
+		}
+		source.reader = body
+		source.SizeBytes = resp.ContentLength
+		source.Compression = compressionFromName(path.Base(resp.Request.URL.Path))
+
+	default:
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, WrapRestoreError("", "failed to open backup file", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, WrapRestoreError("", "failed to stat backup file", err)
+		}
+		if info.IsDir() {
+			file.Close()
+			return nil, WrapRestoreError("", "backup file is a directory", fmt.Errorf("%s", location))
+		}
+		source.reader = file
+		source.SizeBytes = info.Size()
+		source.Compression = compressionFromName(location)
+	}
+
+	return source, nil
+}
+
+// openURL starts downloading location. The download is cancelled when the
+// server keeps a request or read waiting for sourceTimeout.
+func openURL(location string) (*http.Response, io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := time.AfterFunc(sourceTimeout, cancel)
+	stop := func() {
+		timer.Stop()
+		cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	resp, err := sourceClient.Do(req)
+	if err != nil {
+		stop()
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("no response from %s within %s", req.URL.Host, sourceTimeout)
+		}
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		stop()
+		return nil, nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+	timer.Stop()
+	return resp, &idleTimeoutReader{body: resp.Body, ctx: ctx, timer: timer, stop: stop}, nil
+}
+
+// idleTimeoutReader reads a download, timing each read so a server that
+// stops sending cannot block the restore forever.
+type idleTimeoutReader struct {
+	body  io.ReadCloser
+	ctx   context.Context
+	timer *time.Timer
+	stop  func()
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	r.timer.Reset(sourceTimeout)
+	n, err := r.body.Read(p)
+	r.timer.Stop()
+	if err != nil && err != io.EOF && r.ctx.Err() != nil {
+		err = fmt.Errorf("download stalled: no data for %s", sourceTimeout)
+	}
+	return n, err
+}
+
+func (r *idleTimeoutReader) Close() error {
+	r.stop()
+	return r.body.Close()
+}
+
+// Close releases the underlying file or connection.
+func (s *RestoreSource) Close() error {
+	return s.reader.Close()
+}
+
+// compressionFromName returns the compression implied by a file name,
+// or empty if the extension is not recognized.
+func compressionFromName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".gz"):
+		return CompressionGzip
+	case strings.HasSuffix(lower, ".sql"):
+		return CompressionNone
+	default:
+		return ""
+	}
+}
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// sniffCompression detects gzip data by its magic bytes without consuming it.
+func sniffCompression(r *bufio.Reader) string {
+	header, err := r.Peek(len(gzipMagic))
+	if err == nil && string(header) == string(gzipMagic) {
+		return CompressionGzip
+	}
+	return CompressionNone
+}
+
+// normalizeChecksum accepts "sha256:<hex>" or a bare hex digest.
+func normalizeChecksum(checksum string) string {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum != "" && !strings.HasPrefix(checksum, "sha256:") {
+		checksum = "sha256:" + checksum
+	}
+	return checksum
+}
+
+// verifiedReader verifies the source against expectedChecksum before any
+// data reaches the database. Seekable files are hashed in place; other
+// sources are spooled to a temporary file first. The returned cleanup
+// function must always be called.
+func verifiedReader(source *RestoreSource, expectedChecksum string) (io.Reader, func(), error) {
+	noop := func() {}
+	expected := normalizeChecksum(expectedChecksum)
+	if expected == "" {
+		return source.reader, noop, nil
+	}
+
+	hasher := sha256.New()
+
+	if file, ok := source.reader.(*os.File); ok && source.Location != StdinSource {
+		if _, err := copyBuffered(hasher, file, DefaultBufferSize); err != nil {
+			return nil, noop, fmt.Errorf("failed to calculate checksum: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, noop, fmt.Errorf("failed to rewind backup file: %w", err)
+		}
+		if actual := fmt.Sprintf("sha256:%x", hasher.Sum(nil)); actual != expected {
+			return nil, noop, &ChecksumMismatchError{BackupID: source.Location, ExpectedChecksum: expected, ActualChecksum: actual}
+		}
+		return file, noop, nil
+	}
+
+	spool, err := CurrentWorkDir().CreateTemp("cadangkan-restore-*")
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if _, err := copyBuffered(io.MultiWriter(spool, hasher), source.reader, DefaultBufferSize); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if actual := fmt.Sprintf("sha256:%x", hasher.Sum(nil)); actual != expected {
+		cleanup()
+		return nil, noop, &ChecksumMismatchError{BackupID: source.Location, ExpectedChecksum: expected, ActualChecksum: actual}
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("failed to rewind spool file: %w", err)
+	}
+
+	return spool, cleanup, nil
+}
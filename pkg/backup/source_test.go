@@ -0,0 +1,282 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRestorer captures the SQL it is asked to restore.
+type fakeRestorer struct {
+	database string
+	sql      string
+}
+
+func (r *fakeRestorer) RestoreWithCommand(database string, sqlReader io.Reader, cmdLogger func(string)) error {
+	data, err := io.ReadAll(sqlReader)
+	r.database = database
+	r.sql = string(data)
+	return err
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func sha256Checksum(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func newSourceRestoreService(restorer Restorer, databases ...string) *RestoreService {
+	mockClient := mysql.NewMockClient()
+	mockClient.SetConnected(true)
+	mockClient.Databases = databases
+
+	service := NewRestoreService(mockClient, nil, &mysql.Config{Host: "localhost", User: "root"})
+	service.SetEngine(&fakeEngine{name: "fake", restorer: restorer})
+	return service
+}
+
+func TestOpenRestoreSourceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql.gz")
+	require.NoError(t, os.WriteFile(path, gzipBytes(t, "SELECT 1;"), 0644))
+
+	source, err := OpenRestoreSource(path)
+	require.NoError(t, err)
+	defer source.Close()
+
+	assert.Equal(t, CompressionGzip, source.Compression)
+	assert.Greater(t, source.SizeBytes, int64(0))
+}
+
+func TestOpenRestoreSourceErrors(t *testing.T) {
+	_, err := OpenRestoreSource(filepath.Join(t.TempDir(), "missing.sql"))
+	assert.True(t, IsRestoreError(err))
+
+	_, err = OpenRestoreSource(t.TempDir())
+	assert.True(t, IsRestoreError(err))
+}
+
+func TestOpenRestoreSourceURL(t *testing.T) {
+	payload := gzipBytes(t, "SELECT 1;")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/backups/dump.sql.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	source, err := OpenRestoreSource(server.URL + "/backups/dump.sql.gz")
+	require.NoError(t, err)
+	defer source.Close()
+	assert.Equal(t, CompressionGzip, source.Compression)
+	assert.Equal(t, int64(len(payload)), source.SizeBytes)
+
+	_, err = OpenRestoreSource(server.URL + "/missing.sql.gz")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestOpenRestoreSourceURLTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { sourceTimeout = timeout }(sourceTimeout)
+	sourceTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow-reader.sql":
+			w.Write([]byte("SELECT 1;\n"))
+			return
+		case "/stalled.sql":
+			w.Write([]byte("SELECT 1;\n"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	_, err := OpenRestoreSource(server.URL + "/silent.sql")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no response")
+
+	source, err := OpenRestoreSource(server.URL + "/stalled.sql")
+	require.NoError(t, err)
+	defer source.Close()
+	data, err := io.ReadAll(source.reader)
+	assert.Equal(t, "SELECT 1;\n", string(data))
+	assert.ErrorContains(t, err, "download stalled")
+
+	// Time spent by the restore between reads is not a stall
+	source, err = OpenRestoreSource(server.URL + "/slow-reader.sql")
+	require.NoError(t, err)
+	defer source.Close()
+	time.Sleep(3 * sourceTimeout)
+	data, err = io.ReadAll(source.reader)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1;\n", string(data))
+}
+
+func TestSniffCompression(t *testing.T) {
+	gz := bufio.NewReader(bytes.NewReader(gzipBytes(t, "SELECT 1;")))
+	assert.Equal(t, CompressionGzip, sniffCompression(gz))
+
+	plain := bufio.NewReader(strings.NewReader("SELECT 1;"))
+	assert.Equal(t, CompressionNone, sniffCompression(plain))
+
+	// Peeking must not consume input
+	data, _ := io.ReadAll(plain)
+	assert.Equal(t, "SELECT 1;", string(data))
+}
+
+func TestNormalizeChecksum(t *testing.T) {
+	assert.Equal(t, "sha256:abc", normalizeChecksum("ABC"))
+	assert.Equal(t, "sha256:abc", normalizeChecksum(" sha256:abc "))
+	assert.Equal(t, "", normalizeChecksum(""))
+}
+
+func TestRestoreFromSourceFile(t *testing.T) {
+	payload := gzipBytes(t, "CREATE TABLE t (id INT);")
+	path := filepath.Join(t.TempDir(), "dump.sql.gz")
+	require.NoError(t, os.WriteFile(path, payload, 0644))
+
+	source, err := OpenRestoreSource(path)
+	require.NoError(t, err)
+	defer source.Close()
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer, "shop")
+
+	result, err := service.RestoreFromSource(source, &RestoreOptions{
+		Database: "shop",
+		Checksum: sha256Checksum(payload),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RestoreStatusCompleted, result.Status)
+	assert.Equal(t, path, result.Source)
+	assert.Equal(t, "shop", restorer.database)
+	assert.Equal(t, "CREATE TABLE t (id INT);", restorer.sql)
+}
+
+func TestRestoreFromSourceStream(t *testing.T) {
+	payload := gzipBytes(t, "INSERT INTO t VALUES (1);")
+
+	// A non-file source with a checksum is spooled and verified first
+	source := &RestoreSource{
+		Location:  StdinSource,
+		SizeBytes: -1,
+		reader:    io.NopCloser(bytes.NewReader(payload)),
+	}
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer)
+
+	_, err := service.RestoreFromSource(source, &RestoreOptions{
+		Database:       "shop",
+		CreateDatabase: true,
+		Checksum:       strings.TrimPrefix(sha256Checksum(payload), "sha256:"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (1);", restorer.sql)
+}
+
+func TestRestoreFromSourceLogOutput(t *testing.T) {
+	source := &RestoreSource{
+		Location:  StdinSource,
+		SizeBytes: -1,
+		reader:    io.NopCloser(strings.NewReader("SELECT 1;")),
+	}
+
+	var log bytes.Buffer
+	service := newSourceRestoreService(&fakeRestorer{})
+	service.SetVerbose(true)
+	service.SetLogOutput(&log)
+
+	_, err := service.RestoreFromSource(source, &RestoreOptions{Database: "shop", CreateDatabase: true})
+	require.NoError(t, err)
+	assert.Contains(t, log.String(), "[DEBUG] Creating database shop\n")
+}
+
+func TestRestoreFromSourceChecksumMismatch(t *testing.T) {
+	source := &RestoreSource{
+		Location:  StdinSource,
+		SizeBytes: -1,
+		reader:    io.NopCloser(strings.NewReader("SELECT 1;")),
+	}
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer, "shop")
+
+	_, err := service.RestoreFromSource(source, &RestoreOptions{
+		Database: "shop",
+		Checksum: "sha256:deadbeef",
+	})
+	require.Error(t, err)
+	_, ok := err.(*ChecksumMismatchError)
+	assert.True(t, ok)
+	assert.Empty(t, restorer.database, "restore must not run after a checksum mismatch")
+}
+
+func TestRestoreFromSourceMissingDatabase(t *testing.T) {
+	source := &RestoreSource{
+		Location:  StdinSource,
+		SizeBytes: -1,
+		reader:    io.NopCloser(strings.NewReader("SELECT 1;")),
+	}
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer)
+
+	_, err := service.RestoreFromSource(source, &RestoreOptions{Database: "shop"})
+	require.Error(t, err)
+	assert.True(t, IsRestoreError(err))
+	assert.Empty(t, restorer.database)
+}
+
+func TestRestoreFromSourceDryRun(t *testing.T) {
+	source := &RestoreSource{
+		Location:  StdinSource,
+		SizeBytes: -1,
+		reader:    io.NopCloser(strings.NewReader("SELECT 1;")),
+	}
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer)
+
+	result, err := service.RestoreFromSource(source, &RestoreOptions{
+		Database:       "shop",
+		CreateDatabase: true,
+		DryRun:         true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RestoreStatusCompleted, result.Status)
+	assert.Empty(t, restorer.database)
+
+	exists, _ := service.client.DatabaseExists("shop")
+	assert.False(t, exists, "dry-run must not create the database")
+}
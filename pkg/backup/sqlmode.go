@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Session sql_mode choices for a restore besides an explicit mode.
+const (
+	// SQLModeSource restores with the sql_mode of the server the backup
+	// was taken from, as recorded in its metadata
+	SQLModeSource = "source"
+
+	// SQLModeRelaxed restores with a mode that loads dumps of any server:
+	// no strict mode and no ANSI_QUOTES
+	SQLModeRelaxed = "relaxed"
+)
+
+// relaxedSQLMode is the session sql_mode of SQLModeRelaxed. mysqldump sets
+// NO_AUTO_VALUE_ON_ZERO itself around the data of a dump.
+const relaxedSQLMode = "NO_AUTO_VALUE_ON_ZERO,NO_ENGINE_SUBSTITUTION"
+
+// sqlModePattern matches a comma separated list of sql_mode flags.
+var sqlModePattern = regexp.MustCompile(`^[A-Za-z0-9_]+(,[A-Za-z0-9_]+)*$`)
+
+// SQLModeInspector is implemented by introspectors that can read the
+// server's sql_mode. mysql.DatabaseClient satisfies it.
+type SQLModeInspector interface {
+	GetSQLMode() (string, error)
+}
+
+// SQLModeSetter is implemented by restorers whose session sql_mode can be
+// chosen.
+type SQLModeSetter interface {
+	// SetSQLMode sets the session sql_mode of the restore connection
+	SetSQLMode(mode string)
+}
+
+// ValidateSQLMode checks the sql_mode option of a restore: empty,
+// SQLModeSource, SQLModeRelaxed or a list of sql_mode flags such as
+// "STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION".
+func ValidateSQLMode(option string) error {
+	switch option {
+	case "", SQLModeSource, SQLModeRelaxed:
+		return nil
+	}
+	if !sqlModePattern.MatchString(option) {
+		return &ValidationError{
+			Field:   "sql_mode",
+			Message: fmt.Sprintf("invalid sql_mode %q (must be source, relaxed or a comma separated list of modes)", option),
+		}
+	}
+	return nil
+}
+
+// ResolveSQLMode returns the session sql_mode a restore of a backup runs
+// with, or empty to keep the server's. SQLModeSource falls back to the
+// server's mode for backups that did not record one. The resolved mode is
+// validated too, as the one recorded in the metadata ends up in a SET
+// statement just like an explicit one.
+func ResolveSQLMode(option string, info DatabaseInfo) (string, error) {
+	mode := option
+	switch option {
+	case SQLModeSource:
+		mode = info.SQLMode
+	case SQLModeRelaxed:
+		mode = relaxedSQLMode
+	}
+	if err := ValidateSQLMode(mode); err != nil {
+		return "", err
+	}
+	return mode, nil
+}
+
+// serverSQLMode returns the global sql_mode of the server, or empty if the
+// introspector cannot tell.
+func serverSQLMode(client Introspector) string {
+	inspector, ok := client.(SQLModeInspector)
+	if !ok {
+		return ""
+	}
+	mode, err := inspector.GetSQLMode()
+	if err != nil {
+		return ""
+	}
+	return mode
+}
+
+// applySQLMode sets the session sql_mode of a restorer.
+func applySQLMode(restorer Restorer, mode string) {
+	if mode == "" {
+		return
+	}
+	if setter, ok := restorer.(SQLModeSetter); ok {
+		setter.SetSQLMode(mode)
+	}
+}
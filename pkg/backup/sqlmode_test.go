@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSQLMode(t *testing.T) {
+	for _, option := range []string{"", SQLModeSource, SQLModeRelaxed, "ANSI_QUOTES", "STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION"} {
+		assert.NoError(t, ValidateSQLMode(option), option)
+	}
+	for _, option := range []string{"ANSI', sql_log_bin=0 --", "STRICT_TRANS_TABLES,", "a b"} {
+		assert.True(t, IsValidationError(ValidateSQLMode(option)), option)
+	}
+}
+
+func TestResolveSQLMode(t *testing.T) {
+	info := DatabaseInfo{SQLMode: "ANSI_QUOTES,STRICT_ALL_TABLES"}
+
+	tests := []struct {
+		option string
+		info   DatabaseInfo
+		want   string
+	}{
+		{"", info, ""},
+		{SQLModeSource, info, info.SQLMode},
+		{SQLModeSource, DatabaseInfo{}, ""},
+		{SQLModeRelaxed, info, relaxedSQLMode},
+		{"TRADITIONAL", info, "TRADITIONAL"},
+	}
+	for _, tt := range tests {
+		mode, err := ResolveSQLMode(tt.option, tt.info)
+		require.NoError(t, err, tt.option)
+		assert.Equal(t, tt.want, mode, tt.option)
+	}
+
+	// A mode recorded in tampered metadata is validated like an explicit one
+	_, err := ResolveSQLMode(SQLModeSource, DatabaseInfo{SQLMode: "ANSI', sql_log_bin=0 --"})
+	assert.True(t, IsValidationError(err))
+
+	_, err = ResolveSQLMode("a b", info)
+	assert.True(t, IsValidationError(err))
+}
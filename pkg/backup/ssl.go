@@ -0,0 +1,46 @@
+package backup
+
+import "github.com/erickhilda/cadangkan/pkg/database/mysql"
+
+// sslArgs returns the SSL flags of the mysql and mysqldump commands.
+func sslArgs(ssl *mysql.SSLConfig) []string {
+	if ssl == nil {
+		return nil
+	}
+	var args []string
+	if ssl.Mode != "" {
+		args = append(args, "--ssl-mode="+ssl.ToolMode())
+	}
+	if ssl.CA != "" {
+		args = append(args, "--ssl-ca="+ssl.CA)
+	}
+	if ssl.Cert != "" {
+		args = append(args, "--ssl-cert="+ssl.Cert)
+	}
+	if ssl.Key != "" {
+		args = append(args, "--ssl-key="+ssl.Key)
+	}
+	return args
+}
+
+// mydumperSSLArgs returns the SSL flags of the mydumper and myloader
+// commands, which name the certificate flags differently.
+func mydumperSSLArgs(ssl *mysql.SSLConfig) []string {
+	if ssl == nil || ssl.Mode == mysql.SSLModeDisabled {
+		return nil
+	}
+	args := []string{"--ssl"}
+	if ssl.Mode != "" {
+		args = append(args, "--ssl-mode="+ssl.ToolMode())
+	}
+	if ssl.CA != "" {
+		args = append(args, "--ca="+ssl.CA)
+	}
+	if ssl.Cert != "" {
+		args = append(args, "--cert="+ssl.Cert)
+	}
+	if ssl.Key != "" {
+		args = append(args, "--key="+ssl.Key)
+	}
+	return args
+}
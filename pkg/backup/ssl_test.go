@@ -0,0 +1,49 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSLArgs(t *testing.T) {
+	assert.Empty(t, sslArgs(nil))
+	assert.Empty(t, mydumperSSLArgs(nil))
+
+	ssl := &mysql.SSLConfig{
+		Mode: mysql.SSLModeVerifyCA,
+		CA:   "/etc/ca.pem",
+		Cert: "/etc/client.pem",
+		Key:  "/etc/client.key",
+	}
+	assert.Equal(t, []string{
+		"--ssl-mode=VERIFY_CA",
+		"--ssl-ca=/etc/ca.pem",
+		"--ssl-cert=/etc/client.pem",
+		"--ssl-key=/etc/client.key",
+	}, sslArgs(ssl))
+	assert.Equal(t, []string{
+		"--ssl",
+		"--ssl-mode=VERIFY_CA",
+		"--ca=/etc/ca.pem",
+		"--cert=/etc/client.pem",
+		"--key=/etc/client.key",
+	}, mydumperSSLArgs(ssl))
+
+	disabled := &mysql.SSLConfig{Mode: mysql.SSLModeDisabled}
+	assert.Equal(t, []string{"--ssl-mode=DISABLED"}, sslArgs(disabled))
+	assert.Empty(t, mydumperSSLArgs(disabled))
+}
+
+func TestMySQLDumperBuildArgsSSL(t *testing.T) {
+	dumper := NewMySQLDumper(&mysql.Config{
+		Host: "db",
+		Port: 3306,
+		User: "backup",
+		SSL:  &mysql.SSLConfig{Mode: mysql.SSLModeRequired},
+	})
+	args := dumper.buildArgs("shop", &DumpOptions{})
+	assert.Contains(t, args, "--ssl-mode=REQUIRED")
+	assert.Equal(t, "shop", args[len(args)-1])
+}
@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// lineLogWriter passes each complete line written to it to a logger, with
+// secrets masked. It is used to show child process stderr as it arrives.
+type lineLogWriter struct {
+	mu      sync.Mutex
+	logger  func(string)
+	masker  *strings.Replacer
+	partial []byte
+}
+
+// newLineLogWriter creates a lineLogWriter. Empty secrets are ignored.
+func newLineLogWriter(logger func(string), secrets ...string) *lineLogWriter {
+	var pairs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, "***")
+		}
+	}
+	return &lineLogWriter{
+		logger: logger,
+		masker: strings.NewReplacer(pairs...),
+	}
+}
+
+// Write logs every complete line in p and keeps the rest for later.
+func (w *lineLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs any trailing line without a newline.
+func (w *lineLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.log(w.partial)
+		w.partial = nil
+	}
+}
+
+func (w *lineLogWriter) log(line []byte) {
+	text := strings.TrimRight(string(line), "\r")
+	if text == "" {
+		return
+	}
+	w.logger(w.masker.Replace(text))
+}
+
+// stderrWriter returns the writer for a child process's stderr. Output is
+// always captured in buf; when logger is set it is also logged line by line
+// with password masked. flush must be called once the process has exited.
+func stderrWriter(buf *bytes.Buffer, logger func(string), password string) (io.Writer, func()) {
+	if logger == nil {
+		return buf, func() {}
+	}
+	lw := newLineLogWriter(logger, password)
+	return io.MultiWriter(buf, lw), lw.Flush
+}
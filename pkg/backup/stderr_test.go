@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineLogWriter(t *testing.T) {
+	var lines []string
+	w := newLineLogWriter(func(line string) { lines = append(lines, line) }, "hunter2", "")
+
+	w.Write([]byte("mysqldump: [Warning] Using a password hunter2\nskipping tab"))
+	assert.Equal(t, []string{"mysqldump: [Warning] Using a password ***"}, lines)
+
+	w.Write([]byte("le `logs`\r\n\n"))
+	assert.Equal(t, "skipping table `logs`", lines[1])
+
+	w.Write([]byte("no newline"))
+	assert.Len(t, lines, 2)
+	w.Flush()
+	assert.Equal(t, "no newline", lines[2])
+}
+
+func TestStderrWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, flush := stderrWriter(&buf, nil, "secret")
+	w.Write([]byte("secret\n"))
+	flush()
+	assert.Equal(t, "secret\n", buf.String(), "captured stderr is not masked")
+
+	buf.Reset()
+	var lines []string
+	w, flush = stderrWriter(&buf, func(line string) { lines = append(lines, line) }, "secret")
+	w.Write([]byte("bad secret"))
+	flush()
+	assert.Equal(t, "bad secret", buf.String())
+	assert.Equal(t, []string{"bad ***"}, lines)
+}
@@ -0,0 +1,347 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// maxIdentifierLength is the longest table or constraint name MySQL accepts.
+const maxIdentifierLength = 64
+
+var (
+	temporaryViewMarker = []byte("-- Temporary view structure for view `")
+	finalViewMarker     = []byte("-- Final view structure for view `")
+	viewMarkers         = [][]byte{temporaryViewMarker, finalViewMarker}
+
+	// Comments and statements of a table's section that name the table
+	// right after their prefix
+	tableCommentPrefixes = [][]byte{
+		tableMarker,
+		[]byte("-- Dumping data for table `"),
+	}
+	tableStatementPrefixes = [][]byte{
+		[]byte("DROP TABLE IF EXISTS `"),
+		[]byte("CREATE TABLE `"),
+		[]byte("LOCK TABLES `"),
+		[]byte("/*!40000 ALTER TABLE `"),
+		[]byte("INSERT INTO `"),
+	}
+
+	constraintPrefix = []byte("  CONSTRAINT `")
+	triggerStart     = []byte("DELIMITER ;;")
+	triggerEnd       = []byte("DELIMITER ;")
+)
+
+// RestoredTableName returns the default name a table is restored under
+// when it must not replace the current table: <table>_restored_<date>,
+// dated by the backup.
+func RestoredTableName(table string, backupTime time.Time) string {
+	suffix := "_restored_" + backupTime.Format("20060102")
+	if len(table)+len(suffix) > maxIdentifierLength {
+		table = table[:maxIdentifierLength-len(suffix)]
+	}
+	return table + suffix
+}
+
+// Sections of a dump as seen by TableFilter
+const (
+	sectionHeader = iota // Session settings before the first table
+	sectionTable         // The table being restored
+	sectionOther         // Any other table, view or routine
+)
+
+// TableFilter reads a mysqldump SQL dump and passes on only the session
+// settings at its top and the structure and data of one table. With a
+// rename, the table's statements are rewritten to create and fill the new
+// table instead, its foreign key constraints are renamed so they do not
+// clash with the original table's, and its triggers are left out.
+type TableFilter struct {
+	reader *bufio.Reader
+	table  string
+	rename string
+
+	out       bytes.Buffer
+	err       error
+	lineStart bool
+	keep      bool // Whether the current line is passed on
+	section   int
+	inCreate  bool // Inside the table's CREATE TABLE statement
+	inTrigger bool // Inside a trigger definition being left out
+	found     bool
+}
+
+// NewTableFilter returns a reader of the parts of the dump in r needed to
+// restore table, as rename when it is not empty.
+func NewTableFilter(r io.Reader, table, rename string) *TableFilter {
+	if rename == table {
+		rename = ""
+	}
+	return &TableFilter{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		table:     table,
+		rename:    rename,
+		lineStart: true,
+		section:   sectionHeader,
+	}
+}
+
+// Found reports whether the table has been seen in the dump so far.
+func (f *TableFilter) Found() bool {
+	return f.found
+}
+
+// Read implements io.Reader.
+func (f *TableFilter) Read(p []byte) (int, error) {
+	for f.out.Len() == 0 && f.err == nil {
+		fragment, err := f.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			f.filter(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			f.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			f.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if f.out.Len() > 0 {
+		return f.out.Read(p)
+	}
+	return 0, f.err
+}
+
+// filter passes on or drops a fragment of a line.
+func (f *TableFilter) filter(fragment []byte) {
+	if f.lineStart {
+		fragment = f.startLine(fragment)
+	}
+	f.lineStart = fragment[len(fragment)-1] == '\n'
+
+	if !f.keep {
+		return
+	}
+	if f.lineStart && f.inCreate && bytes.HasSuffix(bytes.TrimSpace(fragment), []byte(";")) {
+		f.inCreate = false
+	}
+	f.out.Write(fragment)
+}
+
+// startLine decides whether the line starting with fragment is passed on,
+// and returns the fragment with the table renamed if it is.
+func (f *TableFilter) startLine(fragment []byte) []byte {
+	if name, ok := sectionOwner(fragment); ok {
+		f.section = sectionOther
+		if name == f.table {
+			f.section = sectionTable
+			f.found = true
+		}
+	} else if hasAnyPrefix(fragment, viewMarkers) || hasAnyPrefix(fragment, trailerMarker) {
+		f.section = sectionOther
+	}
+
+	f.keep = f.section != sectionOther
+	if f.section != sectionTable || f.rename == "" {
+		return fragment
+	}
+
+	// Triggers keep their names and would clash with the original's
+	if f.inTrigger {
+		f.keep = false
+		if bytes.Equal(bytes.TrimSpace(fragment), triggerEnd) {
+			f.inTrigger = false
+		}
+		return fragment
+	}
+	if bytes.Equal(bytes.TrimSpace(fragment), triggerStart) {
+		f.keep, f.inTrigger = false, true
+		return fragment
+	}
+
+	if f.inCreate {
+		return f.renameConstraint(fragment)
+	}
+	for _, prefix := range append(tableCommentPrefixes, tableStatementPrefixes...) {
+		quoted := append(append([]byte{}, prefix...), f.table+"`"...)
+		if bytes.HasPrefix(fragment, quoted) {
+			f.inCreate = bytes.HasPrefix(fragment, createTablePrefix)
+			renamed := append(append([]byte{}, prefix...), f.rename+"`"...)
+			return append(renamed, fragment[len(quoted):]...)
+		}
+	}
+	return fragment
+}
+
+// renameConstraint gives a foreign key constraint of the renamed table a
+// name of its own, since constraint names are unique in a database.
+func (f *TableFilter) renameConstraint(fragment []byte) []byte {
+	if !bytes.HasPrefix(fragment, constraintPrefix) {
+		return fragment
+	}
+	rest := fragment[len(constraintPrefix):]
+	end := bytes.IndexByte(rest, '`')
+	if end < 0 {
+		return fragment
+	}
+	renamed := append(append([]byte{}, constraintPrefix...), renamedConstraint(string(rest[:end]), f.table, f.rename)...)
+	return append(renamed, rest[end:]...)
+}
+
+// renamedConstraint returns the name of a constraint of table once the
+// table is restored as rename. Names derived from the table's, like
+// MySQL's generated users_ibfk_1, follow the new name.
+func renamedConstraint(name, table, rename string) string {
+	if len(name) > len(table) && name[:len(table)] == table {
+		name = rename + name[len(table):]
+	} else {
+		name = rename + "_" + name
+	}
+	if len(name) > maxIdentifierLength {
+		name = name[:maxIdentifierLength]
+	}
+	return name
+}
+
+// sectionOwner returns the table whose section a line belongs to when the
+// line names it: the structure marker, or for dumps written without
+// comments, the table's own statements.
+func sectionOwner(fragment []byte) (string, bool) {
+	if name, ok := parseTableMarker(fragment); ok {
+		return name, true
+	}
+	for _, prefix := range tableStatementPrefixes {
+		if !bytes.HasPrefix(fragment, prefix) {
+			continue
+		}
+		rest := fragment[len(prefix):]
+		if end := bytes.IndexByte(rest, '`'); end > 0 {
+			return string(rest[:end]), true
+		}
+	}
+	return "", false
+}
+
+// TableExcluder reads a mysqldump SQL dump and leaves out the structure,
+// data and triggers of some tables, passing on everything else.
+type TableExcluder struct {
+	reader *bufio.Reader
+	tables []string
+
+	out       bytes.Buffer
+	err       error
+	lineStart bool
+	skip      bool // Whether the current line is left out
+}
+
+// NewTableExcluder returns a reader of the dump in r without tables.
+func NewTableExcluder(r io.Reader, tables []string) *TableExcluder {
+	return &TableExcluder{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		tables:    tables,
+		lineStart: true,
+	}
+}
+
+// Read implements io.Reader.
+func (e *TableExcluder) Read(p []byte) (int, error) {
+	for e.out.Len() == 0 && e.err == nil {
+		fragment, err := e.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			e.filter(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			e.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			e.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if e.out.Len() > 0 {
+		return e.out.Read(p)
+	}
+	return 0, e.err
+}
+
+// filter passes on a fragment of a line unless it belongs to an excluded
+// table's section.
+func (e *TableExcluder) filter(fragment []byte) {
+	if e.lineStart {
+		if name, ok := sectionOwner(fragment); ok {
+			e.skip = slices.Contains(e.tables, name)
+		} else if hasAnyPrefix(fragment, viewMarkers) || hasAnyPrefix(fragment, trailerMarker) ||
+			bytes.HasPrefix(fragment, currentDatabaseMarker) || hasAnyPrefix(fragment, dumpFooterMarkers) {
+			e.skip = false
+		}
+	}
+	e.lineStart = fragment[len(fragment)-1] == '\n'
+	if !e.skip {
+		e.out.Write(fragment)
+	}
+}
+
+// DataFilter reads a mysqldump SQL dump and leaves out the INSERT
+// statements of every table but some, passing on all structure.
+type DataFilter struct {
+	reader *bufio.Reader
+	tables []string
+
+	out       bytes.Buffer
+	err       error
+	lineStart bool
+	skip      bool // Whether the current line is left out
+}
+
+// NewDataFilter returns a reader of the dump in r with the data of tables
+// only.
+func NewDataFilter(r io.Reader, tables []string) *DataFilter {
+	return &DataFilter{
+		reader:    bufio.NewReaderSize(r, DefaultBufferSize),
+		tables:    tables,
+		lineStart: true,
+	}
+}
+
+// Read implements io.Reader.
+func (f *DataFilter) Read(p []byte) (int, error) {
+	for f.out.Len() == 0 && f.err == nil {
+		fragment, err := f.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			f.filter(fragment)
+		}
+		switch {
+		case err == io.EOF:
+			f.err = io.EOF
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			f.err = fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	if f.out.Len() > 0 {
+		return f.out.Read(p)
+	}
+	return 0, f.err
+}
+
+// filter passes on a fragment of a line unless it belongs to the INSERT
+// statement of a table whose data is left out.
+func (f *DataFilter) filter(fragment []byte) {
+	if f.lineStart {
+		f.skip = bytes.HasPrefix(fragment, insertIntoPrefix) && !slices.Contains(f.tables, quotedName(fragment))
+	}
+	f.lineStart = fragment[len(fragment)-1] == '\n'
+	if !f.skip {
+		f.out.Write(fragment)
+	}
+}
+
+func hasAnyPrefix(fragment []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(fragment, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tableDump = "-- MySQL dump\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `orders`\n" +
+	"--\n" +
+	"DROP TABLE IF EXISTS `orders`;\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `user_id` int NOT NULL,\n" +
+	"  CONSTRAINT `orders_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+	") ENGINE=InnoDB;\n" +
+	"LOCK TABLES `orders` WRITE;\n" +
+	"INSERT INTO `orders` VALUES (1,1);\n" +
+	"UNLOCK TABLES;\n" +
+	"DELIMITER ;;\n" +
+	"CREATE TRIGGER `orders_audit` AFTER DELETE ON `orders` FOR EACH ROW BEGIN END ;;\n" +
+	"DELIMITER ;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `users`\n" +
+	"--\n" +
+	"DROP TABLE IF EXISTS `users`;\n" +
+	"CREATE TABLE `users` (\n" +
+	"  `id` int NOT NULL\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `users` VALUES (1),(2);\n" +
+	"\n" +
+	"--\n" +
+	"-- Dumping routines for database 'shop'\n" +
+	"--\n"
+
+func readTableFilter(t *testing.T, dump, table, rename string) (string, *TableFilter) {
+	t.Helper()
+	filter := NewTableFilter(strings.NewReader(dump), table, rename)
+	out, err := io.ReadAll(filter)
+	require.NoError(t, err)
+	return string(out), filter
+}
+
+func TestTableFilter(t *testing.T) {
+	t.Run("keeps header and one table", func(t *testing.T) {
+		out, filter := readTableFilter(t, tableDump, "users", "")
+		assert.True(t, filter.Found())
+		assert.Contains(t, out, "SET NAMES utf8mb4")
+		assert.Contains(t, out, "DROP TABLE IF EXISTS `users`;")
+		assert.Contains(t, out, "INSERT INTO `users` VALUES (1),(2);")
+		assert.NotContains(t, out, "orders")
+		assert.NotContains(t, out, "routines")
+	})
+
+	t.Run("renames the table", func(t *testing.T) {
+		out, _ := readTableFilter(t, tableDump, "orders", "orders_restored_20250115")
+		assert.Contains(t, out, "DROP TABLE IF EXISTS `orders_restored_20250115`;")
+		assert.Contains(t, out, "CREATE TABLE `orders_restored_20250115` (")
+		assert.Contains(t, out, "LOCK TABLES `orders_restored_20250115` WRITE;")
+		assert.Contains(t, out, "INSERT INTO `orders_restored_20250115` VALUES (1,1);")
+		assert.Contains(t, out, "CONSTRAINT `orders_restored_20250115_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)")
+		assert.NotContains(t, out, "`orders`")
+		assert.NotContains(t, out, "TRIGGER")
+		assert.NotContains(t, out, "`users` (\n")
+	})
+
+	t.Run("keeps triggers without a rename", func(t *testing.T) {
+		out, _ := readTableFilter(t, tableDump, "orders", "orders")
+		assert.Contains(t, out, "CREATE TRIGGER `orders_audit`")
+	})
+
+	t.Run("dump without comments", func(t *testing.T) {
+		dump := "CREATE TABLE `a` (\n  `id` int\n);\nINSERT INTO `a` VALUES (1);\n" +
+			"CREATE TABLE `b` (\n  `id` int\n);\nINSERT INTO `b` VALUES (2);\n"
+		out, filter := readTableFilter(t, dump, "b", "b_copy")
+		assert.True(t, filter.Found())
+		assert.Equal(t, "CREATE TABLE `b_copy` (\n  `id` int\n);\nINSERT INTO `b_copy` VALUES (2);\n", out)
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		out, filter := readTableFilter(t, tableDump, "missing", "")
+		assert.False(t, filter.Found())
+		assert.Equal(t, "-- MySQL dump\n/*!40101 SET NAMES utf8mb4 */;\n\n--\n", out)
+	})
+}
+
+func TestTableExcluder(t *testing.T) {
+	out, err := io.ReadAll(NewTableExcluder(strings.NewReader(tableDump+"/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n"), []string{"orders"}))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "`orders`")
+	assert.NotContains(t, string(out), "orders_audit")
+	assert.Contains(t, string(out), "SET NAMES utf8mb4")
+	assert.Contains(t, string(out), "INSERT INTO `users` VALUES (1),(2);")
+	assert.Contains(t, string(out), "-- Dumping routines for database 'shop'")
+
+	// The footer after an excluded last table is kept
+	out, err = io.ReadAll(NewTableExcluder(strings.NewReader(tableDump+"/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n"), []string{"users"}))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "`users` VALUES")
+	assert.Contains(t, string(out), "INSERT INTO `orders` VALUES (1,1);")
+
+	// Dumps without comments are split by the tables' statements
+	plain := "CREATE TABLE `cache` (`k` int);\nINSERT INTO `cache` VALUES (1);\nCREATE TABLE `users` (`id` int);\n"
+	out, err = io.ReadAll(NewTableExcluder(strings.NewReader(plain), []string{"cache"}))
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE `users` (`id` int);\n", string(out))
+}
+
+func TestDataFilter(t *testing.T) {
+	out, err := io.ReadAll(NewDataFilter(strings.NewReader(tableDump), []string{"users"}))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(tableDump, "INSERT INTO `orders` VALUES (1,1);\n", "", 1), string(out))
+
+	out, err = io.ReadAll(NewDataFilter(strings.NewReader(tableDump), nil))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "INSERT")
+	assert.Contains(t, string(out), "CREATE TRIGGER `orders_audit`")
+}
+
+func TestRestoredTableName(t *testing.T) {
+	backupTime := time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, "users_restored_20250115", RestoredTableName("users", backupTime))
+
+	long := RestoredTableName(strings.Repeat("t", 64), backupTime)
+	assert.Len(t, long, maxIdentifierLength)
+	assert.True(t, strings.HasSuffix(long, "_restored_20250115"))
+}
+
+func TestRestoreServiceRestoreTable(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	createTestBackupFile(t, filepath.Join(dbPath, backupID+".sql.gz"), tableDump)
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip"))
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer, "testdb")
+	service.storage = localStorage
+
+	options := &RestoreOptions{Database: "testdb", BackupID: backupID, ConfigName: "testdb", Table: "users", TableAs: "users_old"}
+	result, err := service.Restore(options)
+	require.NoError(t, err)
+	assert.Equal(t, RestoreStatusCompleted, result.Status)
+	assert.Contains(t, restorer.sql, "INSERT INTO `users_old` VALUES (1),(2);")
+	assert.NotContains(t, restorer.sql, "orders")
+
+	options.Table = "missing"
+	_, err = service.Restore(options)
+	assert.True(t, IsRestoreError(err))
+	assert.Contains(t, err.Error(), "table not found")
+
+	options.Table, options.TableAs = "", ""
+	options.ExcludeTables = []string{"orders"}
+	_, err = service.Restore(options)
+	require.NoError(t, err)
+	assert.Contains(t, restorer.sql, "INSERT INTO `users` VALUES (1),(2);")
+	assert.NotContains(t, restorer.sql, "orders")
+}
+
+func TestRestoreServiceRestoreIndexedTable(t *testing.T) {
+	tmpDir := t.TempDir()
+	localStorage, err := storage.NewLocalStorage(tmpDir)
+	require.NoError(t, err)
+
+	backupID := "2025-01-15-143022"
+	dbPath := filepath.Join(tmpDir, "testdb")
+	require.NoError(t, os.MkdirAll(dbPath, 0755))
+	indexedPath, index := writeIndexed(t, sampleDump("users", "orders", "items"), 256)
+	data, err := os.ReadFile(indexedPath)
+	require.NoError(t, err)
+
+	// Damage the last block: only the blocks holding users may be read
+	data[len(data)-10] ^= 0xff
+	require.NoError(t, os.WriteFile(filepath.Join(dbPath, backupID+".sql.gz"), data, 0644))
+	metadata := createTestMetadata(backupID, "testdb", backupID+".sql.gz", "gzip")
+	metadata.Backup.Index = index
+	saveMetadata(t, filepath.Join(dbPath, backupID+".meta.json"), metadata)
+
+	restorer := &fakeRestorer{}
+	service := newSourceRestoreService(restorer, "testdb")
+	service.storage = localStorage
+
+	options := &RestoreOptions{Database: "testdb", BackupID: backupID, ConfigName: "testdb", Table: "users"}
+	_, err = service.Restore(options)
+	require.NoError(t, err)
+	assert.Contains(t, restorer.sql, "SET NAMES utf8mb4")
+	assert.Contains(t, restorer.sql, "INSERT INTO `users` VALUES (49);")
+	assert.NotContains(t, restorer.sql, "orders")
+
+	options.Table = "missing"
+	_, err = service.Restore(options)
+	assert.Contains(t, err.Error(), "table not found")
+
+	// Without a table the whole, damaged file is read
+	options.Table = ""
+	_, err = service.Restore(options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,571 @@
+package backup
+
+import (
+	"time"
+
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// BackupOptions defines configuration for a backup operation.
+type BackupOptions struct {
+	// Database name to backup
+	Database string
+
+	// Databases backs up several databases into one archive, with their
+	// CREATE DATABASE statements, instead of Database (mysqldump only).
+	// Database then defaults to SchemaSetName of them
+	Databases []string
+
+	// ConfigName is the configuration name (used for storage paths)
+	// If empty, falls back to using Database name
+	ConfigName string
+
+	// Tables to include (empty means all tables)
+	Tables []string
+
+	// Tables to exclude
+	ExcludeTables []string
+
+	// SchemaOnly backs up only the schema, not data
+	SchemaOnly bool
+
+	// Compression method: "gzip", "zstd", "none"
+	Compression string
+
+	// OutputPath is the directory where backup will be stored
+	// If empty, uses default location (backups/{database}/ in the data directory)
+	OutputPath string
+
+	// Engine is the dump tool: "mysqldump" (default) or "mydumper"
+	Engine string
+
+	// Threads is the number of parallel dump threads (mydumper only, 0 = tool default)
+	Threads int
+
+	// DumpArgs are extra mysqldump flags (mysqldump only)
+	DumpArgs []string
+
+	// DisableDumpDefaults names default mysqldump flags to leave out
+	DisableDumpDefaults []string
+
+	// WarningPolicy decides which dump warnings fail the backup
+	WarningPolicy *WarningPolicy
+
+	// LongQueryPolicy checks for long-running queries before the dump
+	// starts; nil skips the check
+	LongQueryPolicy *LongQueryPolicy
+
+	// LockMonitorPolicy watches for queries blocked while the dump runs
+	// (mysqldump only); nil disables monitoring
+	LockMonitorPolicy *LockMonitorPolicy
+
+	// VersionCheck is what to do when the dump tool does not suit the
+	// server's release: VersionCheckWarn (default), VersionCheckFail or
+	// VersionCheckIgnore (mysqldump only)
+	VersionCheck string
+
+	// Indexed writes gzip output as indexed blocks so single tables can be
+	// read without decompressing the whole backup (mysqldump only)
+	Indexed bool
+
+	// Definers rewrites the DEFINER clauses of views, triggers, routines
+	// and events in the dump: DefinersKeep (default), DefinersStrip or an
+	// account user@host (mysqldump only)
+	Definers string
+
+	// SQLSecurityInvoker rewrites SQL SECURITY DEFINER to SQL SECURITY
+	// INVOKER in the dump (mysqldump only)
+	SQLSecurityInvoker bool
+
+	// PreRestore marks the backup as the safety snapshot taken before a
+	// restore, so the restore can be rolled back
+	PreRestore *RestoreLink
+}
+
+// BackupResult contains the result of a backup operation.
+type BackupResult struct {
+	// BackupID is the unique identifier for this backup (timestamp-based)
+	BackupID string
+
+	// FilePath is the full path to the backup file
+	FilePath string
+
+	// MetadataPath is the full path to the metadata file
+	MetadataPath string
+
+	// SizeBytes is the size of the backup file in bytes
+	SizeBytes int64
+
+	// Duration is how long the backup took
+	Duration time.Duration
+
+	// Checksum is the SHA-256 checksum of the backup file
+	Checksum string
+
+	// RawChecksum is the SHA-256 checksum of the uncompressed dump
+	RawChecksum string
+
+	// Index is the block index of an indexed backup
+	Index *BlockIndex
+
+	// DumpCommand is the dump command that was run, with the password masked
+	DumpCommand string
+
+	// DatabaseSize is the size of the database when the backup started, or
+	// 0 if unknown
+	DatabaseSize int64
+
+	// Warnings printed by the dump tool that did not fail the backup
+	Warnings []string
+
+	// Charset is the default character set of the database when the backup
+	// started, if known
+	Charset *mysql.Charset
+
+	// SQLMode is the server's global sql_mode when the backup started, if
+	// known
+	SQLMode string
+
+	// ServerVersion and DumperVersion are the versions of the server and
+	// the dump tool found when the backup started
+	ServerVersion string
+	DumperVersion string
+
+	// Compatibility is the warning about the dump tool not suiting the
+	// server's release, if any
+	Compatibility string
+
+	// Status indicates the backup outcome
+	Status string
+
+	// StartedAt is when the backup started
+	StartedAt time.Time
+
+	// CompletedAt is when the backup completed
+	CompletedAt time.Time
+
+	// Error contains any error that occurred
+	Error error
+}
+
+// BackupMetadata represents metadata stored with each backup.
+type BackupMetadata struct {
+	// Version of the metadata format
+	Version string `json:"version"`
+
+	// BackupID is the unique identifier for this backup
+	BackupID string `json:"backup_id"`
+
+	// Database information
+	Database DatabaseInfo `json:"database"`
+
+	// CreatedAt is when the backup started
+	CreatedAt time.Time `json:"created_at"`
+
+	// CompletedAt is when the backup finished
+	CompletedAt time.Time `json:"completed_at"`
+
+	// Duration in seconds
+	DurationSeconds int64 `json:"duration_seconds"`
+
+	// Status of the backup: "completed", "failed", "partial"
+	Status string `json:"status"`
+
+	// Backup file information
+	Backup BackupFileInfo `json:"backup"`
+
+	// Options used for this backup
+	Options BackupOptionsInfo `json:"options"`
+
+	// Tool information
+	Tool ToolInfo `json:"tool"`
+
+	// Error message if backup failed
+	Error string `json:"error,omitempty"`
+
+	// Warnings printed by the dump tool that did not fail the backup
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Lock protects the backup from prune and delete, if set
+	Lock *storage.LockInfo `json:"lock,omitempty"`
+
+	// PreRestore links a safety snapshot to the restore it was taken before
+	PreRestore *RestoreLink `json:"pre_restore,omitempty"`
+
+	// Base is the backup an incremental backup depends on; empty for full
+	// backups
+	Base string `json:"base,omitempty"`
+
+	// Restores lists the successful restores of this backup
+	Restores []RestoreRecord `json:"restores,omitempty"`
+
+	// Redactions lists the rows removed from the backup after it was taken
+	Redactions []Redaction `json:"redactions,omitempty"`
+
+	// RepairedAt is when this metadata was reconstructed from the backup
+	// file after the original was lost, if it was
+	RepairedAt *time.Time `json:"repaired_at,omitempty"`
+}
+
+// RestoreRecord records a restore of a backup.
+type RestoreRecord struct {
+	// TargetDatabase is the database the backup was restored into
+	TargetDatabase string `json:"target_database"`
+
+	// Host and Port of the server restored into
+	Host string `json:"host"`
+	Port int    `json:"port"`
+
+	// RestoredAt is when the restore completed
+	RestoredAt time.Time `json:"restored_at"`
+
+	// SnapshotID is the safety snapshot taken before the restore, if any
+	SnapshotID string `json:"snapshot_id,omitempty"`
+}
+
+// RestoreLink records the restore a safety snapshot was taken before.
+type RestoreLink struct {
+	// Source is the backup ID, file or URL that was restored
+	Source string `json:"source"`
+
+	// TargetDatabase is the database the restore overwrote
+	TargetDatabase string `json:"target_database"`
+
+	// RestoredAt is when the restore started, right after the snapshot
+	RestoredAt time.Time `json:"restored_at"`
+}
+
+// DatabaseInfo contains information about the backed up database.
+type DatabaseInfo struct {
+	// Type of database: "mysql", "postgresql", etc.
+	Type string `json:"type"`
+
+	// Host where the database is located
+	Host string `json:"host"`
+
+	// Port the database is listening on
+	Port int `json:"port"`
+
+	// Database name
+	Database string `json:"database"`
+
+	// Databases are the databases of a multi-database backup, see
+	// BackupOptions.Databases; Database is then their SchemaSetName
+	Databases []string `json:"databases,omitempty"`
+
+	// Version of the database server
+	Version string `json:"version"`
+
+	// Charset and Collation are the database's defaults. mysqldump backups
+	// are dumped in Charset, see DumpCharset
+	Charset   string `json:"charset,omitempty"`
+	Collation string `json:"collation,omitempty"`
+
+	// SQLMode is the server's global sql_mode, see SQLModeSource
+	SQLMode string `json:"sql_mode,omitempty"`
+
+	// SizeBytes is the size of the database when the backup started
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+}
+
+// BackupFileInfo contains information about the backup file.
+type BackupFileInfo struct {
+	// File name (relative to backup directory)
+	File string `json:"file"`
+
+	// Size in bytes
+	SizeBytes int64 `json:"size_bytes"`
+
+	// Human-readable size
+	SizeHuman string `json:"size_human"`
+
+	// Compression method used
+	Compression string `json:"compression"`
+
+	// Checksum of the backup file (format: "sha256:...").
+	// Kept for older readers; same as ChecksumFile.
+	Checksum string `json:"checksum"`
+
+	// ChecksumFile is the checksum of the backup file as stored on disk
+	ChecksumFile string `json:"checksum_file,omitempty"`
+
+	// ChecksumRaw is the checksum of the uncompressed dump
+	ChecksumRaw string `json:"checksum_raw,omitempty"`
+
+	// Format of the backup file: empty for a single SQL dump,
+	// "mydumper" for a tar archive of mydumper output
+	Format string `json:"format,omitempty"`
+
+	// Index locates blocks and tables in an indexed gzip backup
+	Index *BlockIndex `json:"index,omitempty"`
+}
+
+// BackupOptionsInfo contains the options used for the backup.
+type BackupOptionsInfo struct {
+	// SchemaOnly indicates if only schema was backed up
+	SchemaOnly bool `json:"schema_only"`
+
+	// Tables that were included (empty means all)
+	Tables []string `json:"tables"`
+
+	// Tables that were excluded
+	ExcludeTables []string `json:"exclude_tables"`
+
+	// DumpCommand is the dump command that was run, with the password masked
+	DumpCommand string `json:"dump_command,omitempty"`
+
+	// Definers and SQLSecurity record how the dump was rewritten, see
+	// BackupOptions
+	Definers    string `json:"definers,omitempty"`
+	SQLSecurity string `json:"sql_security,omitempty"`
+}
+
+// ToolInfo contains information about the tool that created the backup.
+type ToolInfo struct {
+	// Name of the tool
+	Name string `json:"name"`
+
+	// Version of the tool
+	Version string `json:"version"`
+
+	// MySQLDump version used (if applicable)
+	MySQLDumpVersion string `json:"mysqldump_version,omitempty"`
+
+	// Mydumper version used (if applicable)
+	MydumperVersion string `json:"mydumper_version,omitempty"`
+
+	// Compatibility warns that the dump tool did not suit the server's
+	// release, e.g. an older mysqldump
+	Compatibility string `json:"compatibility,omitempty"`
+}
+
+// BackupProgress tracks the progress of an ongoing backup.
+type BackupProgress struct {
+	// Phase of backup: "connecting", "dumping", "compressing", "finalizing"
+	Phase string
+
+	// BytesWritten is the number of bytes written so far
+	BytesWritten int64
+
+	// Message is a human-readable progress message
+	Message string
+
+	// StartedAt is when this backup started
+	StartedAt time.Time
+}
+
+// BackupListEntry represents a single backup in a list of backups.
+type BackupListEntry struct {
+	// BackupID is the unique identifier
+	BackupID string
+
+	// Database name
+	Database string
+
+	// CreatedAt is when the backup was created
+	CreatedAt time.Time
+
+	// SizeBytes is the size of the backup file
+	SizeBytes int64
+
+	// SizeHuman is the human-readable size
+	SizeHuman string
+
+	// Status of the backup
+	Status string
+
+	// FilePath is the full path to the backup file
+	FilePath string
+
+	// MetadataPath is the full path to the metadata file
+	MetadataPath string
+
+	// Lock protects the backup from deletion, if set
+	Lock *storage.LockInfo
+}
+
+// Constants for backup status
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusPartial   = "partial"
+	StatusRunning   = "running"
+)
+
+// Constants for compression types
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionNone = "none"
+)
+
+// Constants for dump engines
+const (
+	EngineMysqldump = "mysqldump"
+	EngineMydumper  = "mydumper"
+)
+
+// Constants for restore engines
+const (
+	RestoreEngineMySQL  = "mysql"
+	RestoreEngineNative = "native"
+)
+
+// Constants for backup file formats
+const (
+	FormatSQL      = ""
+	FormatMydumper = "mydumper"
+)
+
+// Constants for backup phases
+const (
+	PhaseConnecting  = "connecting"
+	PhaseDumping     = "dumping"
+	PhaseCompressing = "compressing"
+	PhaseFinalizing  = "finalizing"
+)
+
+// DefaultOptions returns BackupOptions with sensible defaults.
+func DefaultOptions() *BackupOptions {
+	return &BackupOptions{
+		Compression:   CompressionGzip,
+		SchemaOnly:    false,
+		Tables:        []string{},
+		ExcludeTables: []string{},
+	}
+}
+
+// RestoreOptions defines configuration for a restore operation.
+type RestoreOptions struct {
+	// BackupID is the specific backup to restore (empty = latest)
+	BackupID string
+
+	// Database is the target database name
+	Database string
+
+	// ConfigName is the configuration name (used for storage paths)
+	ConfigName string
+
+	// TargetDatabase is an optional different target database name
+	// If empty, uses Database
+	TargetDatabase string
+
+	// CreateDatabase creates the database if it doesn't exist
+	CreateDatabase bool
+
+	// DryRun validates without executing the restore
+	DryRun bool
+
+	// BackupFirst creates a backup of the target database before restore
+	BackupFirst bool
+
+	// SkipConfirmation skips the confirmation prompt
+	SkipConfirmation bool
+
+	// VerifyFirst verifies the backup checksum in a separate pass before
+	// restoring instead of while streaming into the database
+	VerifyFirst bool
+
+	// DisableFastMode keeps foreign key checks, unique checks and
+	// autocommit on while restoring
+	DisableFastMode bool
+
+	// Engine restores SQL dumps with the mysql command line client
+	// (RestoreEngineMySQL, the default) or through the client library
+	// (RestoreEngineNative)
+	Engine string
+
+	// Checksum is the expected checksum of an external source (optional)
+	Checksum string
+
+	// Compression of an external source; detected when empty
+	Compression string
+
+	// Table restores only this table of a mysqldump backup
+	Table string
+
+	// TableAs restores Table under this name instead, leaving the current
+	// table alone (optional)
+	TableAs string
+
+	// ExcludeTables leaves the structure, data and triggers of these
+	// tables out of a mysqldump restore
+	ExcludeTables []string
+
+	// Atomic restores into a shadow database and swaps its tables into
+	// the target with a single RENAME TABLE once the restore succeeded
+	Atomic bool
+
+	// SessionAction is what to do about connections using the target
+	// database: SessionActionBlock (default), SessionActionWarn or
+	// SessionActionKill
+	SessionAction string
+
+	// Sample keeps only some of the rows of each table, for seeding
+	// development databases
+	Sample RowSample
+
+	// SchemaOnly restores the structure of the dump and the data of
+	// DataTables only, e.g. reference tables for integration tests
+	SchemaOnly bool
+	DataTables []string
+
+	// IgnoreCharset restores even if the server lacks the character set
+	// or collation the backup was taken with
+	IgnoreCharset bool
+
+	// SQLMode is the session sql_mode of the restore: empty keeps the
+	// server's, see ValidateSQLMode for the other choices
+	SQLMode string
+
+	// SkipDefiners and DeferViews rewrite SQL dumps as they are restored,
+	// see DumpRewriteOptions
+	SkipDefiners bool
+	DeferViews   bool
+
+	// Schemas restores only these databases of a multi-database backup
+	// (empty = all of them); see BackupOptions.Databases
+	Schemas []string
+
+	// Plan scans the dump in a dry run and reports what the restore would
+	// execute in RestoreResult.Plan (SQL dumps only)
+	Plan bool
+}
+
+// RestoreResult contains the result of a restore operation.
+type RestoreResult struct {
+	// BackupID is the ID of the backup that was restored
+	BackupID string
+
+	// Source is the file, URL or "-" restored from outside managed storage
+	Source string
+
+	// TargetDatabase is the database that was restored
+	TargetDatabase string
+
+	// Duration is how long the restore took
+	Duration time.Duration
+
+	// Status indicates the restore outcome: "completed", "failed"
+	Status string
+
+	// StartedAt is when the restore started
+	StartedAt time.Time
+
+	// CompletedAt is when the restore completed
+	CompletedAt time.Time
+
+	// Error contains any error that occurred
+	Error error
+
+	// Plan is what the restore would execute, for dry runs with
+	// RestoreOptions.Plan
+	Plan *RestorePlan
+}
+
+// Constants for restore status
+const (
+	RestoreStatusCompleted = "completed"
+	RestoreStatusFailed    = "failed"
+)
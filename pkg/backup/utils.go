@@ -6,17 +6,29 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
+
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// Backup ID layouts. New IDs are UTC with millisecond precision; IDs
+// created before that are second precision in the host's local time.
+const (
+	backupIDLayout       = "2006-01-02-150405.000Z"
+	legacyBackupIDLayout = "2006-01-02-150405"
 )
 
 // GenerateBackupID generates a unique backup ID based on current timestamp.
-// Format: YYYY-MM-DD-HHMMSS (e.g., "2025-01-02-143022")
+// Format: YYYY-MM-DD-HHMMSS.mmmZ in UTC (e.g., "2025-01-02-143022.123Z")
 func GenerateBackupID() string {
-	return time.Now().Format("2006-01-02-150405")
+	return GetBackupIDFromTime(time.Now())
 }
 
-// FormatBytes converts bytes to human-readable format.
+// FormatBytes converts bytes to human-readable format. Like FormatCount, its
+// output does not depend on the locale, so it is stable in scripts and logs.
 func FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -33,6 +45,24 @@ func FormatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %s", float64(bytes)/float64(div), units[exp+1])
 }
 
+// FormatCount formats n with comma thousands separators (e.g., "1,234,567").
+func FormatCount(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String()
+}
+
 // CalculateChecksum calculates SHA-256 checksum of a file.
 // Returns checksum in format "sha256:hexstring"
 func CalculateChecksum(filepath string) (string, error) {
@@ -112,7 +142,7 @@ func CleanupFile(path string) error {
 }
 
 // GetBackupDir returns the backup directory for a given database.
-// If basePath is empty, uses ~/.cadangkan/backups/{database}/
+// If basePath is empty, uses backups/{database}/ in the data directory
 // Otherwise uses {basePath}/{database}/
 func GetBackupDir(database string, basePath string) (string, error) {
 	if database == "" {
@@ -123,11 +153,11 @@ func GetBackupDir(database string, basePath string) (string, error) {
 	if basePath != "" {
 		backupDir = filepath.Join(basePath, database)
 	} else {
-		homeDir, err := os.UserHomeDir()
+		dataDir, err := paths.DataDir()
 		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
+			return "", err
 		}
-		backupDir = filepath.Join(homeDir, ".cadangkan", "backups", database)
+		backupDir = filepath.Join(dataDir, "backups", database)
 	}
 
 	return backupDir, nil
@@ -195,13 +225,18 @@ func CalculateChecksumFromReader(reader io.Reader) (string, error) {
 // GetBackupIDFromTime generates a backup ID from a specific time.
 // Useful for testing or when you need a specific timestamp.
 func GetBackupIDFromTime(t time.Time) string {
-	return t.Format("2006-01-02-150405")
+	return t.UTC().Format(backupIDLayout)
 }
 
 // ParseBackupID parses a backup ID into a time.Time.
+// Legacy IDs without milliseconds are interpreted in local time.
 // Returns error if the ID is not in the expected format.
 func ParseBackupID(backupID string) (time.Time, error) {
-	t, err := time.Parse("2006-01-02-150405", backupID)
+	if t, err := time.Parse(backupIDLayout, backupID); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(legacyBackupIDLayout, backupID, time.Local)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid backup ID format: %w", err)
 	}
@@ -14,9 +14,9 @@ import (
 func TestGenerateBackupID(t *testing.T) {
 	id := GenerateBackupID()
 
-	// Should be in format YYYY-MM-DD-HHMMSS (17 chars)
-	assert.Len(t, id, 17) // 2026-01-02-143022
-	assert.Contains(t, id, "-")
+	// Should be in format YYYY-MM-DD-HHMMSS.mmmZ (22 chars)
+	assert.Len(t, id, 22) // 2026-01-02-143022.123Z
+	assert.True(t, strings.HasSuffix(id, "Z"))
 
 	// Should parse back to a time without error
 	parsed, err := ParseBackupID(id)
@@ -50,6 +50,25 @@ func TestFormatBytes(t *testing.T) {
 	}
 }
 
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{245760000, "245,760,000"},
+		{-1234567, "-1,234,567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatCount(tt.n))
+		})
+	}
+}
+
 func TestCalculateChecksum(t *testing.T) {
 	// Create a temporary file
 	tmpDir := t.TempDir()
@@ -169,9 +188,10 @@ func TestGetBackupDir(t *testing.T) {
 	})
 
 	t.Run("without base path", func(t *testing.T) {
+		t.Setenv("CADANGKAN_HOME", "/srv/cadangkan")
 		dir, err := GetBackupDir("mydb", "")
 		require.NoError(t, err)
-		assert.Contains(t, dir, ".cadangkan/backups/mydb")
+		assert.Equal(t, "/srv/cadangkan/backups/mydb", dir)
 	})
 
 	t.Run("empty database", func(t *testing.T) {
@@ -238,13 +258,24 @@ func TestEstimateBackupSize(t *testing.T) {
 }
 
 func TestGetBackupIDFromTime(t *testing.T) {
-	testTime := time.Date(2025, 1, 2, 14, 30, 22, 0, time.UTC)
+	testTime := time.Date(2025, 1, 2, 14, 30, 22, 123456789, time.UTC)
 	id := GetBackupIDFromTime(testTime)
-	assert.Equal(t, "2025-01-02-143022", id)
+	assert.Equal(t, "2025-01-02-143022.123Z", id)
+
+	// Non-UTC times are converted to UTC
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	id = GetBackupIDFromTime(time.Date(2025, 1, 2, 21, 30, 22, 0, jakarta))
+	assert.Equal(t, "2025-01-02-143022.000Z", id)
 }
 
 func TestParseBackupID(t *testing.T) {
 	t.Run("valid ID", func(t *testing.T) {
+		parsed, err := ParseBackupID("2025-01-02-143022.123Z")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 2, 14, 30, 22, 123000000, time.UTC), parsed)
+	})
+
+	t.Run("legacy ID", func(t *testing.T) {
 		id := "2025-01-02-143022"
 		parsed, err := ParseBackupID(id)
 		require.NoError(t, err)
@@ -263,11 +294,18 @@ func TestParseBackupID(t *testing.T) {
 }
 
 func TestParseBackupIDRoundTrip(t *testing.T) {
-	// Use UTC to avoid timezone issues
-	original := time.Now().UTC().Truncate(time.Second)
+	original := time.Now().Truncate(time.Millisecond)
 	id := GetBackupIDFromTime(original)
 	parsed, err := ParseBackupID(id)
 	require.NoError(t, err)
-	// ParseBackupID returns time in UTC, so compare timestamps
-	assert.Equal(t, original.Unix(), parsed.Unix())
+	assert.True(t, original.Equal(parsed))
+}
+
+func TestBackupIDOrdering(t *testing.T) {
+	// IDs within the same second sort in creation order
+	base := time.Date(2025, 1, 2, 14, 30, 22, 0, time.UTC)
+	first := GetBackupIDFromTime(base.Add(5 * time.Millisecond))
+	second := GetBackupIDFromTime(base.Add(250 * time.Millisecond))
+	assert.NotEqual(t, first, second)
+	assert.Less(t, first, second)
 }
@@ -0,0 +1,153 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Actions for dump tool warnings.
+const (
+	// WarningActionFail fails the backup on any unexpected stderr output
+	WarningActionFail = "fail"
+
+	// WarningActionWarn records warnings but fails only on errors
+	WarningActionWarn = "warn"
+
+	// WarningActionIgnore records all stderr output and never fails
+	WarningActionIgnore = "ignore"
+)
+
+// noisyDumpMessages match stderr lines that mysqldump prints on healthy
+// setups. They are recorded as warnings but never fail a backup.
+var noisyDumpMessages = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)using a password on the command line interface can be insecure`),
+	regexp.MustCompile(`(?i)a (partial )?dump from a server that has gtids`),
+	regexp.MustCompile(`(?i)unknown table 'column_statistics' in information_schema`),
+	regexp.MustCompile(`(?i)is deprecated and will be removed in a future release`),
+}
+
+// dumpErrorPatterns identify stderr lines that mean data is missing from the
+// dump even though the dump tool exited successfully.
+var dumpErrorPatterns = []string{
+	"access denied",
+	"got error",
+	"error:",
+	"couldn't execute",
+	"cannot",
+	"failed",
+	"denied",
+}
+
+// dumpWarningPatterns identify stderr lines that deserve attention.
+var dumpWarningPatterns = []string{
+	"warning:",
+	"[warning]",
+	"mysqldump:",
+}
+
+// WarningPolicy decides which stderr messages of a successful dump fail the
+// backup and which are only recorded.
+type WarningPolicy struct {
+	// Action is WarningActionFail (default), WarningActionWarn or WarningActionIgnore
+	Action string
+
+	// Allow lists patterns of messages that are recorded but never fail
+	Allow []*regexp.Regexp
+}
+
+// DefaultWarningPolicy fails on unexpected output but tolerates known noise.
+func DefaultWarningPolicy() *WarningPolicy {
+	return &WarningPolicy{Action: WarningActionFail}
+}
+
+// NewWarningPolicy builds a policy from an action, WarningActionFail when
+// empty, and regular expressions matching messages that never fail a backup.
+func NewWarningPolicy(action string, allow []string) (*WarningPolicy, error) {
+	policy := DefaultWarningPolicy()
+	if action != "" {
+		policy.Action = action
+	}
+	for _, pattern := range allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   "mysqldump.warnings.allow",
+				Message: fmt.Sprintf("invalid pattern %q: %v", pattern, err),
+			}
+		}
+		policy.Allow = append(policy.Allow, re)
+	}
+
+	return policy, policy.Validate()
+}
+
+// Validate checks the policy action.
+func (p *WarningPolicy) Validate() error {
+	switch p.Action {
+	case "", WarningActionFail, WarningActionWarn, WarningActionIgnore:
+		return nil
+	default:
+		return &ValidationError{
+			Field:   "mysqldump.warnings.action",
+			Message: fmt.Sprintf("invalid warning action: %s (must be fail, warn or ignore)", p.Action),
+		}
+	}
+}
+
+// Classify sorts the stderr output of a successful dump. It returns the
+// lines to record as warnings, and an error if the policy fails the backup.
+func (p *WarningPolicy) Classify(stderr string) ([]string, error) {
+	if p == nil {
+		p = DefaultWarningPolicy()
+	}
+
+	var warnings, failures []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		warnings = append(warnings, line)
+
+		if p.Action == WarningActionIgnore || p.tolerated(line) {
+			continue
+		}
+
+		lower := strings.ToLower(line)
+		if containsAny(lower, dumpErrorPatterns) {
+			failures = append(failures, line)
+		} else if p.Action != WarningActionWarn && containsAny(lower, dumpWarningPatterns) {
+			failures = append(failures, line)
+		}
+	}
+
+	if len(failures) > 0 {
+		return warnings, fmt.Errorf("mysqldump completed but reported warnings: %s", strings.Join(failures, "; "))
+	}
+	return warnings, nil
+}
+
+// tolerated reports whether line is known noise or allowed by the policy.
+func (p *WarningPolicy) tolerated(line string) bool {
+	for _, re := range noisyDumpMessages {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	for _, re := range p.Allow {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
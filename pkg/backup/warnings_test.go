@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarningPolicyClassify(t *testing.T) {
+	stderr := "mysqldump: [Warning] Using a password on the command line interface can be insecure.\n" +
+		"Warning: A partial dump from a server that has GTIDs will by default include the GTIDs\n" +
+		"mysqldump: Couldn't execute 'SHOW VARIABLES': Access denied\n"
+
+	t.Run("known noise is recorded but tolerated", func(t *testing.T) {
+		warnings, err := DefaultWarningPolicy().Classify("mysqldump: [Warning] Using a password on the command line interface can be insecure.\n")
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		warnings, err := DefaultWarningPolicy().Classify("mysqldump: Warning: option 'x' is ignored\n")
+		assert.Error(t, err)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("warn fails only on errors", func(t *testing.T) {
+		policy := &WarningPolicy{Action: WarningActionWarn}
+
+		warnings, err := policy.Classify("mysqldump: Warning: option 'x' is ignored\n")
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 1)
+
+		warnings, err = policy.Classify(stderr)
+		assert.Error(t, err)
+		assert.Len(t, warnings, 3)
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		warnings, err := (&WarningPolicy{Action: WarningActionIgnore}).Classify(stderr)
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 3)
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		policy, err := NewWarningPolicy("", []string{`SHOW VARIABLES`})
+		require.NoError(t, err)
+
+		warnings, err := policy.Classify(stderr)
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 3)
+	})
+
+	t.Run("nil policy uses default", func(t *testing.T) {
+		var policy *WarningPolicy
+		_, err := policy.Classify("mysqldump: Got error: 1045\n")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewWarningPolicy(t *testing.T) {
+	policy, err := NewWarningPolicy("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, WarningActionFail, policy.Action)
+
+	policy, err = NewWarningPolicy(WarningActionWarn, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WarningActionWarn, policy.Action)
+
+	_, err = NewWarningPolicy("retry", nil)
+	assert.True(t, IsValidationError(err))
+
+	_, err = NewWarningPolicy("", []string{"("})
+	assert.True(t, IsValidationError(err))
+}
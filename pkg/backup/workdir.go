@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// workRunPrefix names the per-process run directories inside a work directory.
+const workRunPrefix = "cadangkan-run-"
+
+// WorkDir is where scratch files such as restore spools and extracted
+// archives are created. Each process gets its own run directory inside it,
+// so concurrent runs never see each other's files, and Cleanup removes it.
+type WorkDir struct {
+	Path    string // Base directory (default: the OS temp directory)
+	MinFree int64  // Free bytes required before scratch space is used (0 = no check)
+
+	mu  sync.Mutex
+	run string
+}
+
+var (
+	workDirMu sync.Mutex
+	workDir   = &WorkDir{}
+)
+
+// SetWorkDir sets the work directory scratch files are created in.
+func SetWorkDir(w *WorkDir) {
+	workDirMu.Lock()
+	defer workDirMu.Unlock()
+	workDir = w
+}
+
+// CurrentWorkDir returns the work directory scratch files are created in.
+func CurrentWorkDir() *WorkDir {
+	workDirMu.Lock()
+	defer workDirMu.Unlock()
+	return workDir
+}
+
+// Base returns the directory run directories are created in.
+func (w *WorkDir) Base() string {
+	if w.Path == "" {
+		return os.TempDir()
+	}
+	return w.Path
+}
+
+// RunDir returns the run directory of this process, creating it on first use.
+func (w *WorkDir) RunDir() (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.run != "" {
+		return w.run, nil
+	}
+	base := w.Base()
+	if err := os.MkdirAll(base, 0700); err != nil {
+		return "", WrapStorageError(base, "create", "failed to create work directory", err)
+	}
+	dir, err := os.MkdirTemp(base, fmt.Sprintf("%s%d-", workRunPrefix, os.Getpid()))
+	if err != nil {
+		return "", WrapStorageError(base, "create", "failed to create run directory", err)
+	}
+	w.run = dir
+	return dir, nil
+}
+
+// CheckSpace verifies the work directory has at least MinFree bytes free.
+func (w *WorkDir) CheckSpace() error {
+	if w.MinFree <= 0 {
+		return nil
+	}
+	dir, err := w.RunDir()
+	if err != nil {
+		return err
+	}
+	available, err := CheckDiskSpace(dir)
+	if err != nil {
+		return WrapStorageError(dir, "check", "failed to check disk space", err)
+	}
+	if available < uint64(w.MinFree) {
+		return &StorageError{
+			Path:    w.Base(),
+			Op:      "check",
+			Message: fmt.Sprintf("insufficient space in work directory: need %s free, have %s", FormatBytes(w.MinFree), FormatBytes(int64(available))),
+		}
+	}
+	return nil
+}
+
+// CreateTemp creates a scratch file in the run directory, like os.CreateTemp.
+func (w *WorkDir) CreateTemp(pattern string) (*os.File, error) {
+	if err := w.CheckSpace(); err != nil {
+		return nil, err
+	}
+	dir, err := w.RunDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+// MkdirTemp creates a scratch directory in the run directory, like os.MkdirTemp.
+func (w *WorkDir) MkdirTemp(pattern string) (string, error) {
+	if err := w.CheckSpace(); err != nil {
+		return "", err
+	}
+	dir, err := w.RunDir()
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, pattern)
+}
+
+// Cleanup removes the run directory of this process and everything in it.
+func (w *WorkDir) Cleanup() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.run == "" {
+		return nil
+	}
+	if err := os.RemoveAll(w.run); err != nil {
+		return err
+	}
+	w.run = ""
+	return nil
+}
+
+// CleanStale removes run directories left behind by processes that are no
+// longer running, such as a daemon that was killed. It returns the removed
+// directories.
+func (w *WorkDir) CleanStale() ([]string, error) {
+	base := w.Base()
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		pid, ok := runDirPID(entry.Name())
+		if !ok || !entry.IsDir() || pid == os.Getpid() || processRunning(pid) {
+			continue
+		}
+		path := filepath.Join(base, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// runDirPID returns the process ID in the name of a run directory.
+func runDirPID(name string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, workRunPrefix)
+	if !ok {
+		return 0, false
+	}
+	digits, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(digits)
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// processRunning reports whether a process with the given ID exists. A
+// process owned by another user counts as running.
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
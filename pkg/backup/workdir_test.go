@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkDir(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "work")
+	w := &WorkDir{Path: base}
+
+	file, err := w.CreateTemp("spool-*")
+	require.NoError(t, err)
+	file.Close()
+	dir, err := w.MkdirTemp("extract-")
+	require.NoError(t, err)
+
+	run, err := w.RunDir()
+	require.NoError(t, err)
+	assert.Equal(t, run, filepath.Dir(file.Name()))
+	assert.Equal(t, run, filepath.Dir(dir))
+	assert.Equal(t, base, filepath.Dir(run))
+
+	info, err := os.Stat(base)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	require.NoError(t, w.Cleanup())
+	assert.NoDirExists(t, run)
+	assert.DirExists(t, base)
+
+	// A new run directory is created after cleanup
+	again, err := w.RunDir()
+	require.NoError(t, err)
+	assert.NotEqual(t, run, again)
+	require.NoError(t, w.Cleanup())
+}
+
+func TestWorkDirMinFree(t *testing.T) {
+	w := &WorkDir{Path: t.TempDir(), MinFree: 1 << 50}
+	defer w.Cleanup()
+
+	_, err := w.CreateTemp("spool-*")
+	assert.ErrorContains(t, err, "insufficient space in work directory")
+
+	w.MinFree = 1
+	_, err = w.MkdirTemp("extract-")
+	assert.NoError(t, err)
+}
+
+func TestWorkDirCleanStale(t *testing.T) {
+	base := t.TempDir()
+	w := &WorkDir{Path: base}
+	current, err := w.RunDir()
+	require.NoError(t, err)
+	defer w.Cleanup()
+
+	// PIDs above the kernel maximum never belong to a running process
+	stale := filepath.Join(base, fmt.Sprintf("%s%d-123", workRunPrefix, 1<<30))
+	parent := filepath.Join(base, fmt.Sprintf("%s%d-456", workRunPrefix, os.Getppid()))
+	other := filepath.Join(base, "unrelated")
+	for _, dir := range []string{stale, parent, other} {
+		require.NoError(t, os.Mkdir(dir, 0700))
+	}
+
+	removed, err := w.CleanStale()
+	require.NoError(t, err)
+	assert.Equal(t, []string{stale}, removed)
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, parent)
+	assert.DirExists(t, other)
+	assert.DirExists(t, current)
+}
+
+func TestRunDirPID(t *testing.T) {
+	pid, ok := runDirPID("cadangkan-run-42-123456")
+	assert.True(t, ok)
+	assert.Equal(t, 42, pid)
+
+	for _, name := range []string{"cadangkan-run-x-1", "cadangkan-run-42", "other-42-1", "cadangkan-run--1"} {
+		_, ok := runDirPID(name)
+		assert.False(t, ok, name)
+	}
+}
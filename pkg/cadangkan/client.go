@@ -0,0 +1,446 @@
+package cadangkan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/backupconfig"
+	"github.com/erickhilda/cadangkan/internal/config"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/database/mysql"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+)
+
+// ErrDatabaseNotFound is returned for a database name that is not in the
+// configuration.
+var ErrDatabaseNotFound = errors.New("database not found in config")
+
+// Client backs up, restores and lists the backups of the databases in a
+// cadangkan configuration. It is safe for concurrent use; the configuration
+// is read again for every call, so changes made by the CLI are picked up.
+type Client struct {
+	manager config.Manager
+}
+
+// Backup describes a stored backup.
+type Backup struct {
+	ID          string
+	Database    string // Configured name the backup is stored under
+	Schema      string // Database on the server that was backed up
+	Status      string // "completed", "failed" or "partial"
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	Duration    time.Duration
+	File        string // Path of the backup file
+	SizeBytes   int64
+	Compression string
+	Checksum    string
+	SchemaOnly  bool
+	Tables      []string // Tables included; empty means all
+	Locked      bool     // Protected from prune and delete
+}
+
+// BackupOptions selects what a backup includes. The zero value backs up the
+// whole database with gzip compression.
+type BackupOptions struct {
+	Tables        []string
+	ExcludeTables []string
+	SchemaOnly    bool
+	Compression   string // "gzip" (default), "zstd" or "none"
+
+	// Progress, if set, receives the bytes dumped so far
+	Progress func(bytes int64)
+}
+
+// RestoreOptions selects the backup to restore and where to.
+type RestoreOptions struct {
+	BackupID       string // Default: the latest backup
+	TargetDatabase string // Default: the configured database
+	CreateDatabase bool   // Create the target database if it does not exist
+	DryRun         bool   // Validate the backup without restoring it
+	VerifyFirst    bool   // Verify the checksum before restoring
+	IgnoreCharset  bool   // Restore even if the server lacks the backup's character set
+	SQLMode        string // Session sql_mode, see backup.ValidateSQLMode
+	SkipDefiners   bool   // Remove DEFINER clauses from views, triggers and routines
+	DeferViews     bool   // Create views after all tables
+	Atomic         bool   // Restore into a shadow database and swap its tables in
+	SessionAction  string // Connections using the target: block (default), warn or kill
+	SchemaOnly     bool   // Restore structure and the data of the reference_tables only
+
+	// ExcludeTables are left out of the restore
+	ExcludeTables []string
+
+	// Progress, if set, receives the bytes of the backup file read so far
+	Progress func(bytes int64)
+}
+
+// Restore describes a completed restore.
+type Restore struct {
+	BackupID       string
+	TargetDatabase string
+	Duration       time.Duration
+}
+
+// Open returns a client for the default configuration, config.yaml in the
+// config directory (~/.config/cadangkan or CADANGKAN_HOME), with environment overrides applied as for the
+// CLI.
+func Open() (*Client, error) {
+	manager, err := config.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{manager: manager}, nil
+}
+
+// OpenConfig returns a client for the configuration file at path.
+func OpenConfig(path string) (*Client, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config path: %w", err)
+	}
+	return &Client{manager: config.NewManagerAt(abs)}, nil
+}
+
+// Databases returns the names backups can be taken and listed under: the
+// configured databases, with each server entry replaced by its schemas.
+func (c *Client) Databases() ([]string, error) {
+	cfg, stor, err := c.load("")
+	if err != nil {
+		return nil, err
+	}
+	stored, _ := stor.ListDatabasesIn(cfg.StoragePaths())
+	return cfg.BackupNames(stored), nil
+}
+
+// Backup backs up a configured database and returns the stored backup.
+// Options may be nil.
+func (c *Client) Backup(name string, options *BackupOptions) (*Backup, error) {
+	if options == nil {
+		options = &BackupOptions{}
+	}
+
+	cfg, stor, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	dbConfig, err := database(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	eng, client, mysqlConfig, err := connect(cfg, dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	service := backup.NewService(client, stor, mysqlConfig)
+	service.SetEngine(eng)
+	service.SetLogOutput(io.Discard)
+	if options.Progress != nil {
+		service.SetProgress(options.Progress)
+	}
+
+	backupOptions := &backup.BackupOptions{
+		Database:      dbConfig.Database,
+		ConfigName:    name,
+		Tables:        options.Tables,
+		ExcludeTables: options.ExcludeTables,
+		SchemaOnly:    options.SchemaOnly,
+		Compression:   options.Compression,
+	}
+	if backupOptions.Compression == "" {
+		backupOptions.Compression = backup.CompressionGzip
+	}
+	if dbConfig.Mysqldump != nil {
+		backupOptions.DumpArgs = dbConfig.Mysqldump.ExtraArgs
+		backupOptions.DisableDumpDefaults = dbConfig.Mysqldump.DisableDefaults
+		backupOptions.Definers = dbConfig.Mysqldump.Definers
+		backupOptions.SQLSecurityInvoker = dbConfig.Mysqldump.SQLSecurity == "invoker"
+	}
+	if backupOptions.WarningPolicy, err = backupconfig.WarningPolicy(dbConfig.Mysqldump); err != nil {
+		return nil, err
+	}
+	if backupOptions.LongQueryPolicy, err = backupconfig.LongQueryPolicy(dbConfig.LongQueries); err != nil {
+		return nil, err
+	}
+	if backupOptions.LockMonitorPolicy, err = backupconfig.LockMonitorPolicy(dbConfig.LockMonitor); err != nil {
+		return nil, err
+	}
+	backupOptions.VersionCheck = cfg.GetEffectiveVersionCheck(name)
+
+	// Quotas are enforced, but old backups are never pruned to meet them
+	quotaPolicy, err := backupconfig.QuotaPolicy(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	if quotaPolicy != nil {
+		service.SetQuota(&quotaPolicy.Limits)
+	}
+
+	result, err := service.Backup(backupOptions)
+	if err != nil {
+		return nil, err
+	}
+	return getBackup(stor, name, result.BackupID)
+}
+
+// Restore restores a backup of a configured database. Options may be nil to
+// restore the latest backup into the configured database. Restores are
+// blocked while maintenance mode is on, except dry runs, and recorded in
+// the audit log as the CLI's are.
+func (c *Client) Restore(name string, options *RestoreOptions) (*Restore, error) {
+	if options == nil {
+		options = &RestoreOptions{}
+	}
+
+	cfg, stor, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	dbConfig, err := database(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validating with DryRun changes nothing
+	if !options.DryRun {
+		if err := maintenance.Check("restore"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Connect to the server, not the database, which may not exist yet
+	serverConfig := *dbConfig
+	serverConfig.Database = ""
+	eng, client, mysqlConfig, err := connect(cfg, &serverConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	service := backup.NewRestoreService(client, stor, mysqlConfig)
+	service.SetEngine(eng)
+	service.SetLogOutput(io.Discard)
+	if options.Progress != nil {
+		service.SetProgress(options.Progress)
+	}
+
+	result, err := service.Restore(&backup.RestoreOptions{
+		BackupID:         options.BackupID,
+		Database:         dbConfig.Database,
+		ConfigName:       name,
+		TargetDatabase:   options.TargetDatabase,
+		CreateDatabase:   options.CreateDatabase,
+		DryRun:           options.DryRun,
+		SkipConfirmation: true,
+		VerifyFirst:      options.VerifyFirst,
+		IgnoreCharset:    options.IgnoreCharset,
+		SQLMode:          options.SQLMode,
+		SkipDefiners:     options.SkipDefiners,
+		DeferViews:       options.DeferViews,
+		ExcludeTables:    options.ExcludeTables,
+		Atomic:           options.Atomic,
+		SessionAction:    options.SessionAction,
+		SchemaOnly:       options.SchemaOnly,
+		DataTables:       dbConfig.ReferenceTables,
+	})
+
+	backupID, targetDatabase := options.BackupID, options.TargetDatabase
+	if result != nil {
+		backupID, targetDatabase = result.BackupID, result.TargetDatabase
+	}
+	if targetDatabase == "" {
+		targetDatabase = dbConfig.Database
+	}
+	recordAudit(cfg, audit.ActionRestore, name, backupID, err,
+		fmt.Sprintf("into %s on %s:%d", targetDatabase, dbConfig.Host, dbConfig.Port))
+	if err != nil {
+		return nil, err
+	}
+	return &Restore{
+		BackupID:       result.BackupID,
+		TargetDatabase: result.TargetDatabase,
+		Duration:       result.Duration,
+	}, nil
+}
+
+// ListBackups returns the backups of a database, newest first.
+func (c *Client) ListBackups(name string) ([]Backup, error) {
+	_, stor, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := stor.ListBackups(name)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]Backup, 0, len(entries))
+	for _, entry := range entries {
+		b, err := getBackup(stor, name, entry.BackupID)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, *b)
+	}
+	return backups, nil
+}
+
+// GetBackup returns a backup of a database by ID.
+func (c *Client) GetBackup(name, backupID string) (*Backup, error) {
+	_, stor, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	return getBackup(stor, name, backupID)
+}
+
+// LatestBackup returns the most recent backup of a database.
+func (c *Client) LatestBackup(name string) (*Backup, error) {
+	_, stor, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := stor.GetLatestBackup(name)
+	if err != nil {
+		return nil, err
+	}
+	return getBackup(stor, name, entry.BackupID)
+}
+
+// DeleteBackup deletes a backup of a database. Locked backups cannot be
+// deleted, and no backup can while maintenance mode is on. Deletions are
+// recorded in the audit log.
+func (c *Client) DeleteBackup(name, backupID string) error {
+	cfg, stor, err := c.load(name)
+	if err != nil {
+		return err
+	}
+	if err := maintenance.Check("delete"); err != nil {
+		return err
+	}
+	err = stor.DeleteBackup(name, backupID)
+	recordAudit(cfg, audit.ActionDelete, name, backupID, err, "")
+	return err
+}
+
+// recordAudit appends an entry to the audit log configured in cfg.
+// Failures to write the log never fail the audited operation.
+func recordAudit(cfg *config.Config, action, name, target string, opErr error, details string) {
+	logger, _ := audit.FromConfig(cfg)
+	if logger == nil {
+		return
+	}
+	defer logger.Close()
+
+	entry := audit.NewEntry(action, name, target, opErr)
+	entry.Details = details
+	logger.Record(entry)
+}
+
+// load reads the configuration and opens the backup storage it names for
+// the backups of database name.
+func (c *Client) load(name string) (*config.Config, *storage.LocalStorage, error) {
+	cfg, err := c.manager.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	basePath := ""
+	if cfg.Storage != nil {
+		basePath = cfg.Storage.Path
+	}
+	stor, err := storage.NewLocalStorage(basePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Storage != nil {
+		if err := stor.SetLayout(cfg.Storage.Layout); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cfg, stor.At(cfg.GetEffectiveStoragePath(name)), nil
+}
+
+// database returns the config of a database that can be backed up.
+func database(cfg *config.Config, name string) (*config.DatabaseConfig, error) {
+	dbConfig, exists := cfg.Database(name)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, name)
+	}
+	if dbConfig.IsServer() {
+		return nil, fmt.Errorf("'%s' is a server entry; use one of its databases, e.g. %s", name, config.SchemaName(name, "<schema>"))
+	}
+	return dbConfig, nil
+}
+
+// connect opens a client to the server of a database, retrying as the
+// daemon does.
+func connect(cfg *config.Config, dbConfig *config.DatabaseConfig) (backup.Engine, backup.Introspector, *mysql.Config, error) {
+	password, err := dbConfig.Password()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	eng, err := backup.GetEngine(dbConfig.Type)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	mysqlConfig := &mysql.Config{
+		Host:     dbConfig.Host,
+		Port:     dbConfig.Port,
+		User:     dbConfig.User,
+		Password: password,
+		Database: dbConfig.Database,
+		Timeout:  10 * time.Second,
+		SSL:      backupconfig.SSL(dbConfig.TLS),
+
+		MysqldumpPath: cfg.GetEffectiveMysqldumpPath(dbConfig.Name),
+		MysqlPath:     cfg.GetEffectiveMysqlPath(dbConfig.Name),
+
+		ConnectAttempts: config.DefaultConnectAttempts,
+		ConnectBackoff:  config.DefaultConnectBackoff,
+		AutoReconnect:   true,
+	}
+
+	client, err := eng.NewIntrospector(backup.MySQLConnection(mysqlConfig))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := client.Connect(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return eng, client, mysqlConfig, nil
+}
+
+// getBackup loads the metadata of a backup.
+func getBackup(stor *storage.LocalStorage, name, backupID string) (*Backup, error) {
+	var metadata backup.BackupMetadata
+	if err := stor.LoadMetadata(name, backupID, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &Backup{
+		ID:          metadata.BackupID,
+		Database:    name,
+		Schema:      metadata.Database.Database,
+		Status:      metadata.Status,
+		CreatedAt:   metadata.CreatedAt,
+		CompletedAt: metadata.CompletedAt,
+		Duration:    time.Duration(metadata.DurationSeconds) * time.Second,
+		File:        filepath.Join(stor.GetBackupDir(name, backupID), metadata.Backup.File),
+		SizeBytes:   metadata.Backup.SizeBytes,
+		Compression: metadata.Backup.Compression,
+		Checksum:    metadata.Backup.Checksum,
+		SchemaOnly:  metadata.Options.SchemaOnly,
+		Tables:      metadata.Options.Tables,
+		Locked:      metadata.Lock.Active(time.Now()),
+	}, nil
+}
@@ -0,0 +1,176 @@
+package cadangkan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/audit"
+	"github.com/erickhilda/cadangkan/internal/maintenance"
+	"github.com/erickhilda/cadangkan/internal/paths"
+	"github.com/erickhilda/cadangkan/pkg/backup"
+	"github.com/erickhilda/cadangkan/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestClient returns a client for a config with one database,
+// "production", and the storage its backups go to.
+func openTestClient(t *testing.T) (*Client, *storage.LocalStorage) {
+	t.Helper()
+
+	// Keep maintenance mode and the audit log out of the user's directories
+	dir := t.TempDir()
+	t.Setenv(paths.EnvHome, dir)
+	backupPath := filepath.Join(dir, "backups")
+	configPath := filepath.Join(dir, "config.yaml")
+	configYAML := `version: "1"
+storage:
+  path: ` + backupPath + `
+databases:
+  production:
+    type: mysql
+    host: localhost
+    port: 3306
+    database: app
+    user: root
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configYAML), 0600))
+
+	client, err := OpenConfig(configPath)
+	require.NoError(t, err)
+
+	stor, err := storage.NewLocalStorage(backupPath)
+	require.NoError(t, err)
+	return client, stor
+}
+
+// saveTestBackup stores a completed backup of "production" created at
+// createdAt.
+func saveTestBackup(t *testing.T, stor *storage.LocalStorage, createdAt time.Time) string {
+	t.Helper()
+
+	backupID := createdAt.Format("2006-01-02-150405")
+	require.NoError(t, stor.EnsureBackupDir("production", backupID))
+
+	file := stor.GetBackupPath("production", backupID, backup.CompressionGzip)
+	require.NoError(t, os.WriteFile(file, []byte("dump"), 0600))
+
+	metadata := backup.BackupMetadata{
+		Version:         backup.MetadataVersion,
+		BackupID:        backupID,
+		Database:        backup.DatabaseInfo{Type: "mysql", Database: "app"},
+		CreatedAt:       createdAt,
+		CompletedAt:     createdAt.Add(30 * time.Second),
+		DurationSeconds: 30,
+		Status:          backup.StatusCompleted,
+		Backup: backup.BackupFileInfo{
+			File:        filepath.Base(file),
+			SizeBytes:   4,
+			Compression: backup.CompressionGzip,
+			Checksum:    "sha256:abc",
+		},
+	}
+	require.NoError(t, stor.SaveMetadata("production", backupID, metadata))
+	return backupID
+}
+
+func TestDatabases(t *testing.T) {
+	client, _ := openTestClient(t)
+
+	names, err := client.Databases()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production"}, names)
+}
+
+func TestListBackups(t *testing.T) {
+	client, stor := openTestClient(t)
+
+	now := time.Now().Truncate(time.Second)
+	older := saveTestBackup(t, stor, now.Add(-time.Hour))
+	newer := saveTestBackup(t, stor, now)
+
+	backups, err := client.ListBackups("production")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, newer, backups[0].ID)
+	assert.Equal(t, older, backups[1].ID)
+
+	b := backups[0]
+	assert.Equal(t, "production", b.Database)
+	assert.Equal(t, "app", b.Schema)
+	assert.Equal(t, backup.StatusCompleted, b.Status)
+	assert.Equal(t, 30*time.Second, b.Duration)
+	assert.Equal(t, int64(4), b.SizeBytes)
+	assert.Equal(t, "sha256:abc", b.Checksum)
+	assert.FileExists(t, b.File)
+	assert.False(t, b.Locked)
+}
+
+func TestGetAndLatestBackup(t *testing.T) {
+	client, stor := openTestClient(t)
+
+	now := time.Now().Truncate(time.Second)
+	older := saveTestBackup(t, stor, now.Add(-time.Hour))
+	newer := saveTestBackup(t, stor, now)
+
+	b, err := client.GetBackup("production", older)
+	require.NoError(t, err)
+	assert.Equal(t, older, b.ID)
+
+	latest, err := client.LatestBackup("production")
+	require.NoError(t, err)
+	assert.Equal(t, newer, latest.ID)
+
+	_, err = client.GetBackup("production", "2000-01-01-000000")
+	assert.Error(t, err)
+}
+
+func TestDeleteBackup(t *testing.T) {
+	client, stor := openTestClient(t)
+	backupID := saveTestBackup(t, stor, time.Now())
+
+	require.NoError(t, client.DeleteBackup("production", backupID))
+
+	backups, err := client.ListBackups("production")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	auditPath, err := audit.DefaultPath()
+	require.NoError(t, err)
+	entries, err := audit.ReadEntries(auditPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionDelete, entries[0].Action)
+	assert.Equal(t, backupID, entries[0].Target)
+	assert.Equal(t, audit.ResultSuccess, entries[0].Result)
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	client, stor := openTestClient(t)
+	backupID := saveTestBackup(t, stor, time.Now())
+
+	statePath, err := maintenance.DefaultPath()
+	require.NoError(t, err)
+	_, err = maintenance.Enable(statePath, "migrating")
+	require.NoError(t, err)
+
+	var blocked *maintenance.BlockedError
+	assert.ErrorAs(t, client.DeleteBackup("production", backupID), &blocked)
+	_, err = client.Restore("production", nil)
+	assert.ErrorAs(t, err, &blocked)
+
+	_, err = client.GetBackup("production", backupID)
+	assert.NoError(t, err)
+}
+
+func TestUnknownDatabase(t *testing.T) {
+	client, _ := openTestClient(t)
+
+	_, err := client.Backup("staging", nil)
+	assert.ErrorIs(t, err, ErrDatabaseNotFound)
+
+	_, err = client.Restore("staging", nil)
+	assert.ErrorIs(t, err, ErrDatabaseNotFound)
+}
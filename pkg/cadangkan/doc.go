@@ -0,0 +1,55 @@
+// Package cadangkan lets Go programs back up and restore the databases of a
+// cadangkan configuration, and query their backups, without shelling out to
+// the CLI.
+//
+// A Client works on the same configuration file and backup storage as the
+// cadangkan command, so backups taken through it show up in
+// 'cadangkan backup-list' and can be restored by the CLI, and the other
+// way around.
+//
+// # Quick Start
+//
+// Open the default configuration (~/.config/cadangkan/config.yaml, or in
+// CADANGKAN_HOME) and back up a configured database:
+//
+//	client, err := cadangkan.Open()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	b, err := client.Backup("production", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("Backed up %s (%d bytes)\n", b.ID, b.SizeBytes)
+//
+// # Querying Backups
+//
+// List the backups of a database, newest first, or load one by ID:
+//
+//	backups, err := client.ListBackups("production")
+//	latest, err := client.LatestBackup("production")
+//	b, err := client.GetBackup("production", "2025-01-15-143022")
+//
+// # Restoring
+//
+// Restore the latest backup, or the one given by BackupID, into the
+// configured database or another one:
+//
+//	r, err := client.Restore("production", &cadangkan.RestoreOptions{
+//		TargetDatabase: "production_copy",
+//		CreateDatabase: true,
+//	})
+//
+// Unlike the CLI, the client never asks for confirmation. Callers are
+// responsible for not overwriting data by accident.
+// Like the CLI, it refuses to restore or delete backups while maintenance
+// mode is on ('cadangkan maintenance on') and records both in the audit
+// log.
+//
+// # Lower-Level Packages
+//
+// The client is the stable API. Programs that need more control can use the
+// packages it is built on: backup for the backup and restore services and
+// storage for the backup directory layout.
+package cadangkan
@@ -0,0 +1,57 @@
+package mysql
+
+// Charset is the default character set and collation of a database.
+type Charset struct {
+	Name      string // e.g. "utf8mb4"
+	Collation string // e.g. "utf8mb4_0900_ai_ci"
+}
+
+// databaseCharsetQuery reads the defaults of a database.
+const databaseCharsetQuery = `
+	SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME
+	FROM information_schema.SCHEMATA
+	WHERE SCHEMA_NAME = ?
+`
+
+// collationsQuery lists the collations of a character set.
+const collationsQuery = `
+	SELECT COLLATION_NAME
+	FROM information_schema.COLLATIONS
+	WHERE CHARACTER_SET_NAME = ?
+	ORDER BY COLLATION_NAME
+`
+
+// GetDatabaseCharset returns the default character set and collation of a
+// database. It returns ErrEmptyResult if the database does not exist.
+func (c *Client) GetDatabaseCharset(database string) (*Charset, error) {
+	if database == "" {
+		return nil, &ConfigError{Field: "database", Message: "database name is required"}
+	}
+	result, err := c.Query(databaseCharsetQuery, database)
+	if err != nil {
+		return nil, err
+	}
+	if result.Len() == 0 {
+		return nil, ErrEmptyResult
+	}
+	charset := &Charset{}
+	charset.Name, _ = result.String(0, "DEFAULT_CHARACTER_SET_NAME")
+	charset.Collation, _ = result.String(0, "DEFAULT_COLLATION_NAME")
+	return charset, nil
+}
+
+// GetCollations returns the collations the server has for a character
+// set, none if it does not support the character set.
+func (c *Client) GetCollations(charset string) ([]string, error) {
+	result, err := c.Query(collationsQuery, charset)
+	if err != nil {
+		return nil, err
+	}
+	collations := make([]string, 0, result.Len())
+	for i := range result.Rows {
+		if name, ok := result.String(i, "COLLATION_NAME"); ok {
+			collations = append(collations, name)
+		}
+	}
+	return collations, nil
+}
@@ -3,11 +3,23 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
-	_ "github.com/go-sql-driver/mysql" // MySQL driver
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// openDB and sleep are replaced in tests.
+var (
+	openDB = func(dsn string) (*sql.DB, error) { return sql.Open("mysql", dsn) }
+	sleep  = time.Sleep
 )
 
 // Client represents a MySQL database client.
@@ -49,7 +61,8 @@ func NewClientWithDB(config *Config, db *sql.DB) (*Client, error) {
 	}, nil
 }
 
-// Connect establishes a connection to the MySQL database.
+// Connect establishes a connection to the MySQL database, retrying as
+// configured by ConnectAttempts and ConnectBackoff.
 func (c *Client) Connect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -58,48 +71,142 @@ func (c *Client) Connect() error {
 		return ErrAlreadyConnected
 	}
 
-	db, err := sql.Open("mysql", c.config.DSN())
+	db, err := c.open()
 	if err != nil {
-		return WrapConnectionError(c.config.Host, c.config.Port, "failed to open connection", err)
+		return err
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(c.config.MaxOpenConns)
-	db.SetMaxIdleConns(c.config.MaxIdleConns)
-	db.SetConnMaxLifetime(c.config.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(c.config.ConnMaxIdleTime)
+	c.db = db
+	c.connected = true
+	return nil
+}
 
-	// Test the connection
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
-	defer cancel()
+// Reconnect replaces the connection pool with a new one, retrying as
+// Connect does. The old pool is kept when the server cannot be reached.
+func (c *Client) Reconnect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if err := db.PingContext(ctx); err != nil {
-		db.Close()
-		return WrapConnectionError(c.config.Host, c.config.Port, "failed to ping database", err)
+	if !c.connected || c.db == nil {
+		return ErrNotConnected
+	}
+
+	db, err := c.open()
+	if err != nil {
+		return err
 	}
 
+	c.db.Close()
 	c.db = db
-	c.connected = true
 	return nil
 }
 
-// Ping checks if the database connection is still alive.
+// open opens a connection pool and pings the server. Failed pings are
+// retried with backoff unless the server rejected the connection.
+func (c *Client) open() (*sql.DB, error) {
+	dsnConfig := *c.config
+	if dsnConfig.TLS == "" {
+		tlsParam, err := dsnConfig.SSL.tlsParam(dsnConfig.Host)
+		if err != nil {
+			return nil, WrapConnectionError(c.config.Host, c.config.Port, "invalid SSL configuration", err)
+		}
+		dsnConfig.TLS = tlsParam
+	}
+
+	attempts := c.config.ConnectAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := c.config.ConnectBackoff
+	if backoff <= 0 {
+		backoff = DefaultConnectBackoff
+	}
+
+	var history []ConnectAttempt
+	for {
+		db, err := openDB(dsnConfig.DSN())
+		if err != nil {
+			return nil, WrapConnectionError(c.config.Host, c.config.Port, "failed to open connection", err)
+		}
+
+		// Configure connection pool
+		db.SetMaxOpenConns(c.config.MaxOpenConns)
+		db.SetMaxIdleConns(c.config.MaxIdleConns)
+		db.SetConnMaxLifetime(c.config.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(c.config.ConnMaxIdleTime)
+
+		// Test the connection
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+		db.Close()
+
+		history = append(history, ConnectAttempt{Time: time.Now(), Err: err})
+		if len(history) >= attempts || !retryable(err) {
+			return nil, &ConnectionError{
+				Host:     c.config.Host,
+				Port:     c.config.Port,
+				Message:  "failed to ping database",
+				Err:      err,
+				Attempts: history,
+			}
+		}
+		sleep(backoff)
+		backoff = min(backoff*2, MaxConnectBackoff)
+	}
+}
+
+// retryable reports whether a failed connect may succeed when retried:
+// the server could not be reached or was out of connections, rather than
+// rejecting the credentials or database.
+func retryable(err error) bool {
+	var mysqlErr *driver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1040, 1053, 1203: // Too many connections, server shutdown, max_user_connections
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// deadConnection reports whether err shows the pool's connections are
+// dead, as after the server restarted or dropped idle connections.
+func deadConnection(err error) bool {
+	return errors.Is(err, driver.ErrInvalidConn) ||
+		errors.Is(err, sqldriver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// Ping checks if the database connection is still alive. With
+// AutoReconnect, a pool found dead is replaced by a new one.
 func (c *Client) Ping() error {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-
 	if !c.connected || c.db == nil {
+		c.mu.RUnlock()
 		return ErrNotConnected
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
-	defer cancel()
+	err := c.db.PingContext(ctx)
+	cancel()
+	c.mu.RUnlock()
 
-	if err := c.db.PingContext(ctx); err != nil {
-		return WrapConnectionError(c.config.Host, c.config.Port, "ping failed", err)
+	if err == nil {
+		return nil
 	}
-
-	return nil
+	if c.config.AutoReconnect && deadConnection(err) {
+		return c.Reconnect()
+	}
+	return WrapConnectionError(c.config.Host, c.config.Port, "ping failed", err)
 }
 
 // Close closes the database connection.
@@ -204,6 +311,60 @@ func (c *Client) Execute(query string, args ...interface{}) (sql.Result, error)
 	return result, nil
 }
 
+// ExecuteMulti runs the statements of an SQL script, such as a small dump
+// or a post-restore script, one after the other on a single connection, so
+// session settings like USE and SET FOREIGN_KEY_CHECKS carry over. Pass a
+// StatementScanner to run an SQL file. Statements are not bound by Timeout;
+// cancel ctx to stop. It returns the number of statements run; a failing
+// statement is reported in a QueryError.
+func (c *Client) ExecuteMulti(ctx context.Context, stmts StatementReader) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected || c.db == nil {
+		return 0, ErrNotConnected
+	}
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return 0, WrapConnectionError(c.config.Host, c.config.Port, "failed to get connection", err)
+	}
+	defer conn.Close()
+
+	executed := 0
+	for {
+		stmt, err := stmts.Next()
+		if err == io.EOF {
+			return executed, nil
+		}
+		if err != nil {
+			return executed, fmt.Errorf("failed to read script: %w", err)
+		}
+
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return executed, WrapQueryError(truncateStatement(stmt), fmt.Sprintf("statement %d failed", executed+1), err)
+		}
+		executed++
+	}
+}
+
+// maxErrorStatement is how much of a failing script statement ExecuteMulti
+// keeps in its error; dumped INSERT statements can run to megabytes.
+const maxErrorStatement = 200
+
+// truncateStatement cuts stmt to at most maxErrorStatement bytes on a
+// character boundary, marking the cut with "…".
+func truncateStatement(stmt string) string {
+	if len(stmt) <= maxErrorStatement {
+		return stmt
+	}
+	cut := maxErrorStatement
+	for cut > 0 && !utf8.RuneStart(stmt[cut]) {
+		cut--
+	}
+	return stmt[:cut] + "…"
+}
+
 // GetVersion returns the MySQL server version.
 func (c *Client) GetVersion() (string, error) {
 	c.mu.RLock()
@@ -225,6 +386,42 @@ func (c *Client) GetVersion() (string, error) {
 	return version, nil
 }
 
+// GetSQLMode returns the server's global sql_mode, the mode new
+// connections such as mysqldump's start with.
+func (c *Client) GetSQLMode() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected || c.db == nil {
+		return "", ErrNotConnected
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	var mode string
+	err := c.db.QueryRowContext(ctx, "SELECT @@GLOBAL.sql_mode").Scan(&mode)
+	if err != nil {
+		return "", WrapQueryError("SELECT @@GLOBAL.sql_mode", "failed to get sql_mode", err)
+	}
+
+	return mode, nil
+}
+
+// systemDatabases are the schemas MySQL uses for itself.
+var systemDatabases = map[string]bool{
+	"information_schema": true,
+	"mysql":              true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// IsSystemDatabase reports whether name is a schema MySQL uses for itself
+// rather than an application database.
+func IsSystemDatabase(name string) bool {
+	return systemDatabases[strings.ToLower(name)]
+}
+
 // GetDatabases returns a list of all databases on the server.
 func (c *Client) GetDatabases() ([]string, error) {
 	c.mu.RLock()
@@ -314,6 +511,31 @@ func (c *Client) CreateDatabase(database string) error {
 	return nil
 }
 
+// DropDatabase drops a database if it exists.
+func (c *Client) DropDatabase(database string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected || c.db == nil {
+		return ErrNotConnected
+	}
+
+	if database == "" {
+		return &ConfigError{Field: "database", Message: "database name is required"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", database)
+	_, err := c.db.ExecContext(ctx, query)
+	if err != nil {
+		return WrapQueryError(query, "failed to drop database", err)
+	}
+
+	return nil
+}
+
 // GetTables returns a list of all tables in the specified database.
 func (c *Client) GetTables(database string) ([]string, error) {
 	c.mu.RLock()
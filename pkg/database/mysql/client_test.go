@@ -1,13 +1,20 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	driver "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -113,6 +120,28 @@ func TestConfigValidate(t *testing.T) {
 			wantError: true,
 			errField:  "MaxIdleConns",
 		},
+		{
+			name: "negative connect attempts",
+			config: &Config{
+				Host:            "localhost",
+				Port:            3306,
+				User:            "root",
+				ConnectAttempts: -1,
+			},
+			wantError: true,
+			errField:  "ConnectAttempts",
+		},
+		{
+			name: "negative connect backoff",
+			config: &Config{
+				Host:           "localhost",
+				Port:           3306,
+				User:           "root",
+				ConnectBackoff: -time.Second,
+			},
+			wantError: true,
+			errField:  "ConnectBackoff",
+		},
 	}
 
 	for _, tt := range tests {
@@ -235,6 +264,33 @@ func TestConfigChaining(t *testing.T) {
 	assert.Equal(t, 5*time.Second, config.Timeout)
 }
 
+func TestSSLConfigTLSParam(t *testing.T) {
+	var none *SSLConfig
+	param, err := none.tlsParam("db")
+	require.NoError(t, err)
+	assert.Empty(t, param)
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{SSLModeDisabled, "false"},
+		{SSLModePreferred, "preferred"},
+		{SSLModeRequired, "skip-verify"},
+	}
+	for _, tt := range tests {
+		param, err := (&SSLConfig{Mode: tt.mode}).tlsParam("db")
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, param, tt.mode)
+	}
+
+	// Verifying modes register a configuration with the CA
+	_, err = (&SSLConfig{Mode: SSLModeVerifyCA, CA: "/nonexistent/ca.pem"}).tlsParam("db")
+	assert.Error(t, err)
+
+	assert.Equal(t, "VERIFY_IDENTITY", (&SSLConfig{Mode: SSLModeVerifyIdentity}).ToolMode())
+}
+
 // --- Client Tests ---
 
 func TestNewClient(t *testing.T) {
@@ -374,6 +430,128 @@ func TestClientPing(t *testing.T) {
 	})
 }
 
+// stubOpen makes Connect open sqlmock databases whose pings fail with the
+// given errors in turn, nil for success, and records the backoff waits.
+func stubOpen(t *testing.T, pingErrs ...error) *[]time.Duration {
+	origOpen, origSleep := openDB, sleep
+	t.Cleanup(func() { openDB, sleep = origOpen, origSleep })
+
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	openDB = func(string) (*sql.DB, error) {
+		require.NotEmpty(t, pingErrs, "unexpected connect attempt")
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		mock.ExpectPing().WillReturnError(pingErrs[0])
+		pingErrs = pingErrs[1:]
+		return db, nil
+	}
+	return &waits
+}
+
+func TestClientConnectRetry(t *testing.T) {
+	refused := fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		waits := stubOpen(t, refused, refused, nil)
+		config := NewConfig().WithHost("localhost").WithUser("root").WithConnectRetry(3, 100*time.Millisecond)
+		client, err := NewClient(config)
+		require.NoError(t, err)
+
+		require.NoError(t, client.Connect())
+		assert.True(t, client.IsConnected())
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+	})
+
+	t.Run("records attempts", func(t *testing.T) {
+		stubOpen(t, refused, refused, refused)
+		config := NewConfig().WithHost("localhost").WithUser("root").WithConnectRetry(3, time.Second)
+		client, _ := NewClient(config)
+
+		err := client.Connect()
+		var connErr *ConnectionError
+		require.ErrorAs(t, err, &connErr)
+		assert.Len(t, connErr.Attempts, 3)
+		assert.Contains(t, err.Error(), "after 3 attempts")
+		assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+		assert.False(t, client.IsConnected())
+	})
+
+	t.Run("no retry without attempts", func(t *testing.T) {
+		waits := stubOpen(t, refused)
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+
+		err := client.Connect()
+		assert.True(t, IsConnectionError(err))
+		assert.NotContains(t, err.Error(), "attempts")
+		assert.Empty(t, *waits)
+	})
+
+	t.Run("no retry when access is denied", func(t *testing.T) {
+		stubOpen(t, &driver.MySQLError{Number: 1045, Message: "Access denied"})
+		config := NewConfig().WithHost("localhost").WithUser("root").WithConnectRetry(3, time.Second)
+		client, _ := NewClient(config)
+
+		var connErr *ConnectionError
+		require.ErrorAs(t, client.Connect(), &connErr)
+		assert.Len(t, connErr.Attempts, 1)
+	})
+
+	t.Run("backoff is capped", func(t *testing.T) {
+		waits := stubOpen(t, refused, refused, refused, nil)
+		config := NewConfig().WithHost("localhost").WithUser("root").WithConnectRetry(4, 20*time.Second)
+		client, _ := NewClient(config)
+
+		require.NoError(t, client.Connect())
+		assert.Equal(t, []time.Duration{20 * time.Second, MaxConnectBackoff, MaxConnectBackoff}, *waits)
+	})
+}
+
+func TestClientPingReconnect(t *testing.T) {
+	newClient := func(autoReconnect bool) (*Client, *sql.DB) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		mock.ExpectPing().WillReturnError(fmt.Errorf("write: %w", syscall.EPIPE))
+
+		config := NewConfig().WithHost("localhost").WithUser("root")
+		config.AutoReconnect = autoReconnect
+		client, _ := NewClientWithDB(config, db)
+		return client, db
+	}
+
+	t.Run("replaces a dead pool", func(t *testing.T) {
+		stubOpen(t, nil)
+		client, old := newClient(true)
+
+		require.NoError(t, client.Ping())
+		assert.NotSame(t, old, client.DB())
+		assert.True(t, client.IsConnected())
+	})
+
+	t.Run("keeps the pool when the server is down", func(t *testing.T) {
+		stubOpen(t, fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED))
+		client, old := newClient(true)
+
+		assert.True(t, IsConnectionError(client.Ping()))
+		assert.Same(t, old, client.DB())
+	})
+
+	t.Run("reports the error without auto-reconnect", func(t *testing.T) {
+		stubOpen(t)
+		client, old := newClient(false)
+
+		err := client.Ping()
+		assert.True(t, IsConnectionError(err))
+		assert.Contains(t, err.Error(), "ping failed")
+		assert.Same(t, old, client.DB())
+	})
+
+	t.Run("reconnect when not connected", func(t *testing.T) {
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+		assert.Equal(t, ErrNotConnected, client.Reconnect())
+	})
+}
+
 func TestClientClose(t *testing.T) {
 	t.Run("successful close", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -486,6 +664,15 @@ func TestClientGetDatabases(t *testing.T) {
 	})
 }
 
+func TestIsSystemDatabase(t *testing.T) {
+	for _, name := range []string{"mysql", "information_schema", "PERFORMANCE_SCHEMA", "sys"} {
+		assert.True(t, IsSystemDatabase(name), name)
+	}
+	for _, name := range []string{"shop", "mysql_app", ""} {
+		assert.False(t, IsSystemDatabase(name), name)
+	}
+}
+
 func TestClientDatabaseExists(t *testing.T) {
 	t.Run("database exists", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -563,6 +750,36 @@ func TestClientDatabaseExists(t *testing.T) {
 	})
 }
 
+func TestClientDropDatabase(t *testing.T) {
+	t.Run("successfully drop database", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("DROP DATABASE IF EXISTS `scratch`").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		config := NewConfig().WithHost("localhost").WithUser("root").WithTimeout(5 * time.Second)
+		client, _ := NewClientWithDB(config, db)
+
+		err = client.DropDatabase("scratch")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty database name", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		config := NewConfig().WithHost("localhost").WithUser("root").WithTimeout(5 * time.Second)
+		client, _ := NewClientWithDB(config, db)
+
+		err = client.DropDatabase("")
+		assert.True(t, IsConfigError(err))
+	})
+}
+
 func TestClientCreateDatabase(t *testing.T) {
 	t.Run("successfully create database", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -972,6 +1189,127 @@ func TestClientExecute(t *testing.T) {
 	})
 }
 
+func TestStatementScanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple statements",
+			script: "CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			name:   "several on one line without final delimiter",
+			script: "SET a=1; SET b=2;  \n SELECT 3",
+			want:   []string{"SET a=1", "SET b=2", "SELECT 3"},
+		},
+		{
+			name:   "delimiters in quotes and identifiers",
+			script: "INSERT INTO `x;y` VALUES ('a;b', \"c;d\", 'it''s;', 'esc\\';');\n",
+			want:   []string{"INSERT INTO `x;y` VALUES ('a;b', \"c;d\", 'it''s;', 'esc\\';')"},
+		},
+		{
+			name:   "multi-line string",
+			script: "INSERT INTO a VALUES ('line1;\nline2');\n",
+			want:   []string{"INSERT INTO a VALUES ('line1;\nline2')"},
+		},
+		{
+			name:   "comments",
+			script: "-- MySQL dump\n# note; here\n/*!40101 SET NAMES utf8mb4 */;\nSELECT 1 /* a; b */ + 1; -- trailing\nSELECT 2--1;\n",
+			want:   []string{"/*!40101 SET NAMES utf8mb4 */", "SELECT 1 /* a; b */ + 1", "SELECT 2--1"},
+		},
+		{
+			name: "delimiter lines",
+			script: "DELIMITER ;;\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n  SET NEW.id = 1;\nEND ;;\n" +
+				"delimiter ;\nSELECT 1;\n",
+			want: []string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n  SET NEW.id = 1;\nEND", "SELECT 1"},
+		},
+		{
+			name:   "empty statements",
+			script: ";;\n  ;\n-- only a comment\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewStatementScanner(strings.NewReader(tt.script))
+			var got []string
+			for {
+				stmt, err := scanner.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				got = append(got, stmt)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientExecuteMulti(t *testing.T) {
+	script := "SET FOREIGN_KEY_CHECKS=0;\nINSERT INTO a VALUES ('x;y');\nINSERT INTO b VALUES (2);\n"
+
+	t.Run("runs each statement", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS=0").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO a VALUES ('x;y')").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO b VALUES (2)").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		executed, err := client.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader(script)))
+		require.NoError(t, err)
+		assert.Equal(t, 3, executed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stops at a failing statement", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS=0").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO a VALUES ('x;y')").WillReturnError(errors.New("duplicate key"))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		executed, err := client.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader(script)))
+		assert.Equal(t, 1, executed)
+		assert.True(t, IsQueryError(err))
+		assert.Contains(t, err.Error(), "statement 2 failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("truncates a long failing statement", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		long := "INSERT INTO ab VALUES " + strings.Repeat("('é'),", 1000) + "('x')"
+		mock.ExpectExec(long).WillReturnError(errors.New("duplicate key"))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		_, err = client.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader(long+";")))
+		var queryErr *QueryError
+		require.True(t, errors.As(err, &queryErr))
+		assert.LessOrEqual(t, len(queryErr.Query), maxErrorStatement+len("…"))
+		assert.True(t, strings.HasPrefix(long, strings.TrimSuffix(queryErr.Query, "…")))
+		assert.True(t, strings.HasSuffix(queryErr.Query, "…"))
+		assert.True(t, utf8.ValidString(queryErr.Query))
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+		_, err := client.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader(script)))
+		assert.Equal(t, ErrNotConnected, err)
+	})
+}
+
 func TestClientConcurrentAccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -1290,6 +1628,22 @@ func TestMockClientDatabaseExists(t *testing.T) {
 	})
 }
 
+func TestMockClientDropDatabase(t *testing.T) {
+	mock := NewMockClient()
+	mock.SetConnected(true)
+	mock.Databases = []string{"db1", "scratch"}
+	mock.SetTables("scratch", []string{"users"})
+
+	err := mock.DropDatabase("scratch")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db1"}, mock.Databases)
+	assert.Empty(t, mock.Tables["scratch"])
+	assert.Equal(t, 1, mock.GetCallCount("DropDatabase"))
+
+	mock.SetConnected(false)
+	assert.Equal(t, ErrNotConnected, mock.DropDatabase("db1"))
+}
+
 func TestMockClientCreateDatabase(t *testing.T) {
 	t.Run("create new database", func(t *testing.T) {
 		mock := NewMockClient()
@@ -1370,6 +1724,385 @@ func TestMockClientCallTracking(t *testing.T) {
 	assert.Empty(t, mock.GetCalls())
 }
 
+func TestMockClientExecuteMulti(t *testing.T) {
+	mock := NewMockClient()
+	mock.SetConnected(true)
+
+	executed, err := mock.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader("SELECT 1; SELECT 2;")))
+	require.NoError(t, err)
+	assert.Equal(t, 2, executed)
+	assert.Equal(t, 2, mock.GetCallCount("Execute"))
+
+	mock.ExecErr = errors.New("failed")
+	_, err = mock.ExecuteMulti(context.Background(), NewStatementScanner(strings.NewReader("SELECT 1;")))
+	assert.True(t, IsQueryError(err))
+}
+
+func TestClientQuery(t *testing.T) {
+	t.Run("returns rows as maps", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id", "name", "note"}).
+			AddRow(int64(1), []byte("alice"), nil).
+			AddRow(int64(2), "bob", "hi")
+		mock.ExpectQuery("SELECT id, name, note FROM users WHERE id > ?").WithArgs(0).WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		result, err := client.Query("SELECT id, name, note FROM users WHERE id > ?", 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id", "name", "note"}, result.Columns)
+		assert.Equal(t, 2, result.Len())
+		assert.Equal(t, map[string]interface{}{"id": int64(1), "name": "alice", "note": nil}, result.Rows[0])
+
+		name, ok := result.String(0, "name")
+		assert.True(t, ok)
+		assert.Equal(t, "alice", name)
+		_, ok = result.String(0, "note")
+		assert.False(t, ok, "NULL should not be a string")
+		id, ok := result.Int64(1, "id")
+		assert.True(t, ok)
+		assert.Equal(t, int64(2), id)
+		_, ok = result.Int64(1, "name")
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		result, err := client.Query("SELECT id FROM users")
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Len())
+		assert.NotNil(t, result.Rows)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM users").WillReturnError(errors.New("no such table"))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		_, err = client.Query("SELECT id FROM users")
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+		_, err := client.Query("SELECT 1")
+		assert.Equal(t, ErrNotConnected, err)
+	})
+}
+
+func TestClientGetProcessList(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"ID", "USER", "HOST", "DB", "COMMAND", "TIME", "STATE", "INFO"}).
+			AddRow(int64(42), "app", "10.0.0.5:51234", "shop", "Query", int64(600), "Sending data", "SELECT * FROM orders").
+			AddRow(int64(7), "app", "10.0.0.5:51200", nil, "Sleep", int64(30), "", nil)
+		mock.ExpectQuery("FROM information_schema.PROCESSLIST").WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		processes, err := client.GetProcessList()
+		require.NoError(t, err)
+		require.Len(t, processes, 2)
+
+		assert.Equal(t, Process{ID: 42, User: "app", Host: "10.0.0.5:51234", Database: "shop", Command: "Query",
+			Time: 10 * time.Minute, State: "Sending data", Info: "SELECT * FROM orders"}, processes[0])
+		assert.False(t, processes[0].Idle())
+		assert.Equal(t, "", processes[1].Database)
+		assert.True(t, processes[1].Idle())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+		_, err := client.GetProcessList()
+		assert.Equal(t, ErrNotConnected, err)
+	})
+}
+
+func TestClientKillConnection(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("KILL 42").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("KILL 43").WillReturnError(errors.New("Unknown thread id: 43"))
+
+	client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+	assert.NoError(t, client.KillConnection(42))
+	assert.True(t, IsQueryError(client.KillConnection(43)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClientGetLockWaits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"ID", "USER", "HOST", "DB", "COMMAND", "TIME", "STATE", "INFO"}
+	mock.ExpectQuery("FROM information_schema.PROCESSLIST").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(42), "app", "10.0.0.5:51234", "shop", "Query", int64(45), "Waiting for table metadata lock", "ALTER TABLE orders ADD x INT"))
+	mock.ExpectQuery("FROM information_schema.INNODB_TRX").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(43), "app", "10.0.0.5:51240", "shop", "Query", int64(12), "Waiting for row lock", "UPDATE orders SET x = 1"))
+
+	client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+	waits, err := client.GetLockWaits()
+	require.NoError(t, err)
+	require.Len(t, waits, 2)
+	assert.Equal(t, int64(42), waits[0].ID)
+	assert.Equal(t, 45*time.Second, waits[0].Time)
+	assert.Equal(t, "Waiting for row lock", waits[1].State)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMockClientProcessList(t *testing.T) {
+	mock := NewMockClient()
+	mock.SetConnected(true)
+	mock.Processes = []Process{{ID: 1, Command: "Query"}, {ID: 2, Command: "Sleep"}}
+
+	require.NoError(t, mock.KillConnection(1))
+	processes, err := mock.GetProcessList()
+	require.NoError(t, err)
+	assert.Equal(t, []Process{{ID: 2, Command: "Sleep"}}, processes)
+	assert.Equal(t, 1, mock.GetCallCount("KillConnection"))
+}
+
+func TestClientGetDatabaseCharset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"DEFAULT_CHARACTER_SET_NAME", "DEFAULT_COLLATION_NAME"}
+	mock.ExpectQuery("FROM information_schema.SCHEMATA").WithArgs("shop").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("utf8mb4", "utf8mb4_0900_ai_ci"))
+	mock.ExpectQuery("FROM information_schema.SCHEMATA").WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+	charset, err := client.GetDatabaseCharset("shop")
+	require.NoError(t, err)
+	assert.Equal(t, &Charset{Name: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}, charset)
+
+	_, err = client.GetDatabaseCharset("missing")
+	assert.Equal(t, ErrEmptyResult, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClientGetCollations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.COLLATIONS").WithArgs("utf8mb4").
+		WillReturnRows(sqlmock.NewRows([]string{"COLLATION_NAME"}).
+			AddRow("utf8mb4_bin").AddRow("utf8mb4_general_ci"))
+	mock.ExpectQuery("FROM information_schema.COLLATIONS").WithArgs("utf16le").
+		WillReturnRows(sqlmock.NewRows([]string{"COLLATION_NAME"}))
+
+	client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+	collations, err := client.GetCollations("utf8mb4")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"utf8mb4_bin", "utf8mb4_general_ci"}, collations)
+
+	collations, err = client.GetCollations("utf16le")
+	require.NoError(t, err)
+	assert.Empty(t, collations)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClientGetSQLMode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT @@GLOBAL.sql_mode").
+		WillReturnRows(sqlmock.NewRows([]string{"@@GLOBAL.sql_mode"}).AddRow("STRICT_TRANS_TABLES,ANSI_QUOTES"))
+
+	config := NewConfig().WithHost("localhost").WithUser("root").WithTimeout(5 * time.Second)
+	client, _ := NewClientWithDB(config, db)
+	mode, err := client.GetSQLMode()
+	require.NoError(t, err)
+	assert.Equal(t, "STRICT_TRANS_TABLES,ANSI_QUOTES", mode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClientGetSchemaObjects(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("information_schema.VIEWS").
+		WithArgs("shop", "shop", "shop", "shop").
+		WillReturnRows(sqlmock.NewRows([]string{"views", "triggers", "routines", "events"}).AddRow(2, 1, 0, 3))
+
+	config := NewConfig().WithHost("localhost").WithUser("root").WithTimeout(5 * time.Second)
+	client, _ := NewClientWithDB(config, db)
+	objects, err := client.GetSchemaObjects("shop")
+	require.NoError(t, err)
+	assert.Equal(t, &SchemaObjects{Views: 2, Triggers: 1, Events: 3}, objects)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = client.GetSchemaObjects("")
+	assert.Error(t, err)
+}
+
+func TestClientRenameTables(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("RENAME TABLE `shop`.`orders` TO `old`.`orders`, `new`.`or``ders` TO `shop`.`orders`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	config := NewConfig().WithHost("localhost").WithUser("root").WithTimeout(5 * time.Second)
+	client, _ := NewClientWithDB(config, db)
+	err = client.RenameTables([]TableRename{
+		{FromDatabase: "shop", FromTable: "orders", ToDatabase: "old", ToTable: "orders"},
+		{FromDatabase: "new", FromTable: "or`ders", ToDatabase: "shop", ToTable: "orders"},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, client.RenameTables(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClientQueryStream(t *testing.T) {
+	t.Run("iterates rows", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id", "note"}).
+			AddRow(1, "first").
+			AddRow(2, nil)
+		mock.ExpectQuery("SELECT id, note FROM t").WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		it, err := client.QueryStream(context.Background(), "SELECT id, note FROM t")
+		require.NoError(t, err)
+		defer it.Close()
+
+		assert.Equal(t, []string{"id", "note"}, it.Columns())
+		var got [][]string
+		for it.Next() {
+			row := it.Row()
+			note := "NULL"
+			if row[1] != nil {
+				note = string(row[1])
+			}
+			got = append(got, []string{string(row[0]), note})
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, [][]string{{"1", "first"}, {"2", "NULL"}}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3)
+		mock.ExpectQuery("SELECT id FROM t WHERE id > ?").WithArgs(0).WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		var ids []string
+		count, err := client.ExecuteQueryStream(context.Background(), "SELECT id FROM t WHERE id > ?",
+			func(columns []string, row []sql.RawBytes) error {
+				ids = append(ids, string(row[0]))
+				return nil
+			}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.Equal(t, []string{"1", "2", "3"}, ids)
+	})
+
+	t.Run("callback error stops", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)
+		mock.ExpectQuery("SELECT id FROM t").WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		stop := errors.New("disk full")
+		count, err := client.ExecuteQueryStream(context.Background(), "SELECT id FROM t",
+			func(columns []string, row []sql.RawBytes) error { return stop })
+		assert.Equal(t, stop, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("row error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("connection lost"))
+		mock.ExpectQuery("SELECT id FROM t").WillReturnRows(rows)
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		count, err := client.ExecuteQueryStream(context.Background(), "SELECT id FROM t",
+			func(columns []string, row []sql.RawBytes) error { return nil })
+		assert.True(t, IsQueryError(err))
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM t").WillReturnError(errors.New("no such table"))
+
+		client, _ := NewClientWithDB(NewConfig().WithHost("localhost").WithUser("root"), db)
+		_, err = client.QueryStream(context.Background(), "SELECT id FROM t")
+		assert.True(t, IsQueryError(err))
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		client, _ := NewClient(NewConfig().WithHost("localhost").WithUser("root"))
+		_, err := client.QueryStream(context.Background(), "SELECT 1")
+		assert.Equal(t, ErrNotConnected, err)
+	})
+}
+
+func TestMockClientQueryStream(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+
+	mock := NewMockClient()
+	mock.SetConnected(true)
+	mock.QueryRows = rows
+
+	count, err := mock.ExecuteQueryStream(context.Background(), "SELECT id FROM t",
+		func(columns []string, row []sql.RawBytes) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 1, mock.GetCallCount("QueryStream"))
+}
+
 func TestMockClientNotConnected(t *testing.T) {
 	mock := NewMockClient()
 	// Not connected
@@ -15,6 +15,12 @@ const (
 	DefaultConnMaxIdle    = 30 * time.Second
 )
 
+// Connect retry defaults.
+const (
+	DefaultConnectBackoff = time.Second
+	MaxConnectBackoff     = 30 * time.Second
+)
+
 // Config holds the MySQL connection configuration.
 type Config struct {
 	// Host is the database server hostname or IP address.
@@ -52,6 +58,43 @@ type Config struct {
 
 	// TLS specifies the TLS configuration name (e.g., "true", "false", "skip-verify", or custom).
 	TLS string
+
+	// SSL configures encrypted connections for both the driver, when TLS
+	// is not set, and the client tools.
+	SSL *SSLConfig
+
+	// ConnectAttempts is how many times Connect tries to reach the server
+	// before giving up (default: 1, no retry).
+	ConnectAttempts int
+
+	// ConnectBackoff is the wait before the second attempt, doubled before
+	// each further attempt up to MaxConnectBackoff (default: 1s).
+	ConnectBackoff time.Duration
+
+	// AutoReconnect makes Ping replace the connection pool when its
+	// connections are found dead, e.g. after the server restarted.
+	AutoReconnect bool
+
+	// MysqldumpPath and MysqlPath are the client programs run to dump and
+	// restore (default: mysqldump and mysql found in PATH).
+	MysqldumpPath string
+	MysqlPath     string
+}
+
+// MysqldumpCommand returns the mysqldump program to run.
+func (c *Config) MysqldumpCommand() string {
+	if c != nil && c.MysqldumpPath != "" {
+		return c.MysqldumpPath
+	}
+	return "mysqldump"
+}
+
+// MysqlCommand returns the mysql client program to run.
+func (c *Config) MysqlCommand() string {
+	if c != nil && c.MysqlPath != "" {
+		return c.MysqlPath
+	}
+	return "mysql"
 }
 
 // NewConfig creates a new Config with default values.
@@ -87,6 +130,12 @@ func (c *Config) Validate() error {
 	if c.MaxIdleConns < 0 {
 		return &ConfigError{Field: "MaxIdleConns", Message: "max idle connections must be non-negative"}
 	}
+	if c.ConnectAttempts < 0 {
+		return &ConfigError{Field: "ConnectAttempts", Message: "connect attempts must be non-negative"}
+	}
+	if c.ConnectBackoff < 0 {
+		return &ConfigError{Field: "ConnectBackoff", Message: "connect backoff must be non-negative"}
+	}
 	return nil
 }
 
@@ -182,3 +231,11 @@ func (c *Config) WithTimeout(timeout time.Duration) *Config {
 	c.Timeout = timeout
 	return c
 }
+
+// WithConnectRetry sets the connect attempts and backoff and returns the
+// config for chaining.
+func (c *Config) WithConnectRetry(attempts int, backoff time.Duration) *Config {
+	c.ConnectAttempts = attempts
+	c.ConnectBackoff = backoff
+	return c
+}
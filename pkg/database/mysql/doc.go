@@ -8,6 +8,8 @@
 //
 //   - Connection management with configurable pooling
 //   - Query execution for SELECT and non-SELECT statements
+//   - SQL scripts run statement by statement with ExecuteMulti
+//   - Row-by-row streaming of large results with QueryStream
 //   - Database introspection (list databases, tables, sizes)
 //   - Thread-safe concurrent access
 //   - Comprehensive error handling with custom error types
@@ -77,6 +79,43 @@
 //	fmt.Printf("Database %s has %d tables, total size: %d bytes\n",
 //		info.Name, info.TableCount, info.TotalSize)
 //
+// # SQL Scripts
+//
+// ExecuteMulti runs a script, such as a small dump, without the mysql
+// command line client. StatementScanner splits it into statements like the
+// client does, honoring quotes, comments and DELIMITER lines:
+//
+//	file, err := os.Open("fixup.sql")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer file.Close()
+//
+//	executed, err := client.ExecuteMulti(ctx, mysql.NewStatementScanner(file))
+//
+// # Query Results
+//
+// Query returns a whole result as column names and one map per row, with
+// nil for NULL, so callers need not scan rows themselves:
+//
+//	result, err := client.Query("SELECT id, user, time FROM information_schema.PROCESSLIST")
+//	for i := range result.Rows {
+//		user, _ := result.String(i, "user")
+//		seconds, _ := result.Int64(i, "time")
+//	}
+//
+// # Streaming Results
+//
+// QueryStream and ExecuteQueryStream read a result row by row instead of
+// loading it, for exporting large tables. The driver has no server-side
+// cursors; it reads each row off the connection as it is consumed, which
+// keeps memory use bounded the same way:
+//
+//	count, err := client.ExecuteQueryStream(ctx, "SELECT * FROM orders",
+//		func(columns []string, row []sql.RawBytes) error {
+//			return writer.WriteRow(row) // row is reused after returning
+//		})
+//
 // # Error Handling
 //
 // The package provides custom error types for better error handling:
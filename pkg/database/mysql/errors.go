@@ -4,6 +4,7 @@ package mysql
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Common sentinel errors for the MySQL client.
@@ -27,14 +28,28 @@ type ConnectionError struct {
 	Port    int
 	Message string
 	Err     error
+
+	// Attempts records the failed attempts of a retried connect, the
+	// last of which is Err.
+	Attempts []ConnectAttempt
+}
+
+// ConnectAttempt is a failed attempt to connect.
+type ConnectAttempt struct {
+	Time time.Time
+	Err  error
 }
 
 // Error returns the error message.
 func (e *ConnectionError) Error() string {
+	message := e.Message
+	if len(e.Attempts) > 1 {
+		message = fmt.Sprintf("%s after %d attempts", message, len(e.Attempts))
+	}
 	if e.Err != nil {
-		return fmt.Sprintf("mysql connection error to %s:%d: %s: %v", e.Host, e.Port, e.Message, e.Err)
+		return fmt.Sprintf("mysql connection error to %s:%d: %s: %v", e.Host, e.Port, message, e.Err)
 	}
-	return fmt.Sprintf("mysql connection error to %s:%d: %s", e.Host, e.Port, e.Message)
+	return fmt.Sprintf("mysql connection error to %s:%d: %s", e.Host, e.Port, message)
 }
 
 // Unwrap returns the underlying error.
@@ -1,6 +1,9 @@
 package mysql
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 // DatabaseClient defines the interface for MySQL database operations.
 // This interface enables mocking for unit tests.
@@ -14,10 +17,15 @@ type DatabaseClient interface {
 	// Query execution
 	ExecuteQuery(query string) (*sql.Rows, error)
 	ExecuteQueryArgs(query string, args ...interface{}) (*sql.Rows, error)
+	Query(query string, args ...interface{}) (*QueryResult, error)
 	Execute(query string, args ...interface{}) (sql.Result, error)
+	ExecuteMulti(ctx context.Context, stmts StatementReader) (int, error)
+	QueryStream(ctx context.Context, query string, args ...interface{}) (*RowIterator, error)
+	ExecuteQueryStream(ctx context.Context, query string, fn RowFunc, args ...interface{}) (int64, error)
 
 	// Introspection methods
 	GetVersion() (string, error)
+	GetSQLMode() (string, error)
 	GetDatabases() ([]string, error)
 	GetTables(database string) ([]string, error)
 	GetTableSize(database, table string) (int64, error)
@@ -26,7 +34,15 @@ type DatabaseClient interface {
 	GetTableInfo(database, table string) (*TableInfo, error)
 	GetDatabaseInfo(database string) (*DatabaseInfo, error)
 	CreateDatabase(database string) error
+	DropDatabase(database string) error
 	DatabaseExists(database string) (bool, error)
+	GetProcessList() ([]Process, error)
+	KillConnection(id int64) error
+	GetLockWaits() ([]Process, error)
+	GetDatabaseCharset(database string) (*Charset, error)
+	GetCollations(charset string) ([]string, error)
+	GetSchemaObjects(database string) (*SchemaObjects, error)
+	RenameTables(renames []TableRename) error
 }
 
 // Ensure Client implements DatabaseClient interface.
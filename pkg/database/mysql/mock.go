@@ -1,7 +1,10 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"io"
 	"sync"
 )
 
@@ -18,6 +21,8 @@ type MockClient struct {
 	CloseErr     error
 	Version      string
 	VersionErr   error
+	SQLMode      string
+	SQLModeErr   error
 	Databases    []string
 	DatabasesErr error
 	Tables       map[string][]string // database -> tables
@@ -32,6 +37,17 @@ type MockClient struct {
 	TableInfoErr error
 	DBInfos      map[string]*DatabaseInfo // database -> info
 	DBInfoErr    error
+	Processes    []Process
+	ProcessErr   error
+	KillErr      error
+	LockWaits    []Process
+	LockWaitErr  error
+	Charsets     map[string]*Charset // database -> default charset
+	Collations   map[string][]string // charset -> collations
+	CharsetErr   error
+	Objects      map[string]*SchemaObjects // database -> objects besides tables
+	ObjectsErr   error
+	RenameErr    error
 
 	// Query responses
 	QueryRows  *sql.Rows
@@ -184,6 +200,28 @@ func (m *MockClient) ExecuteQueryArgs(query string, args ...interface{}) (*sql.R
 	return m.QueryRows, nil
 }
 
+// Query simulates executing a query, scanning QueryRows when set.
+func (m *MockClient) Query(query string, args ...interface{}) (*QueryResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	callArgs := append([]interface{}{query}, args...)
+	m.recordCall("Query", callArgs...)
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.QueryErr != nil {
+		return nil, m.QueryErr
+	}
+
+	if m.QueryRows == nil {
+		return &QueryResult{Rows: []map[string]interface{}{}}, nil
+	}
+	return ScanRows(m.QueryRows)
+}
+
 // Execute simulates executing a non-SELECT query.
 func (m *MockClient) Execute(query string, args ...interface{}) (sql.Result, error) {
 	m.mu.RLock()
@@ -203,6 +241,66 @@ func (m *MockClient) Execute(query string, args ...interface{}) (sql.Result, err
 	return m.ExecResult, nil
 }
 
+// ExecuteMulti simulates running a script, recording each statement as an
+// Execute call. ExecErr fails the first statement.
+func (m *MockClient) ExecuteMulti(ctx context.Context, stmts StatementReader) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("ExecuteMulti")
+
+	if !m.connected {
+		return 0, ErrNotConnected
+	}
+
+	executed := 0
+	for {
+		stmt, err := stmts.Next()
+		if err == io.EOF {
+			return executed, nil
+		}
+		if err != nil {
+			return executed, err
+		}
+		m.recordCall("Execute", stmt)
+		if m.ExecErr != nil {
+			return executed, WrapQueryError(stmt, fmt.Sprintf("statement %d failed", executed+1), m.ExecErr)
+		}
+		executed++
+	}
+}
+
+// QueryStream simulates streaming a query, iterating over QueryRows.
+func (m *MockClient) QueryStream(ctx context.Context, query string, args ...interface{}) (*RowIterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	callArgs := append([]interface{}{query}, args...)
+	m.recordCall("QueryStream", callArgs...)
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.QueryErr != nil {
+		return nil, m.QueryErr
+	}
+
+	if m.QueryRows == nil {
+		return nil, fmt.Errorf("mock: QueryRows not set")
+	}
+	return newRowIterator(m.QueryRows)
+}
+
+// ExecuteQueryStream simulates streaming a query, passing the QueryRows to fn.
+func (m *MockClient) ExecuteQueryStream(ctx context.Context, query string, fn RowFunc, args ...interface{}) (int64, error) {
+	it, err := m.QueryStream(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return streamRows(query, it, fn)
+}
+
 // GetVersion returns the mock version.
 func (m *MockClient) GetVersion() (string, error) {
 	m.mu.RLock()
@@ -221,6 +319,24 @@ func (m *MockClient) GetVersion() (string, error) {
 	return m.Version, nil
 }
 
+// GetSQLMode returns the mock sql_mode.
+func (m *MockClient) GetSQLMode() (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetSQLMode")
+
+	if !m.connected {
+		return "", ErrNotConnected
+	}
+
+	if m.SQLModeErr != nil {
+		return "", m.SQLModeErr
+	}
+
+	return m.SQLMode, nil
+}
+
 // GetDatabases returns the mock database list.
 func (m *MockClient) GetDatabases() ([]string, error) {
 	m.mu.RLock()
@@ -265,6 +381,28 @@ func (m *MockClient) CreateDatabase(database string) error {
 	return nil
 }
 
+// DropDatabase removes a database.
+func (m *MockClient) DropDatabase(database string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recordCall("DropDatabase", database)
+
+	if !m.connected {
+		return ErrNotConnected
+	}
+
+	for i, db := range m.Databases {
+		if db == database {
+			m.Databases = append(m.Databases[:i], m.Databases[i+1:]...)
+			break
+		}
+	}
+	delete(m.Tables, database)
+
+	return nil
+}
+
 // DatabaseExists checks if a database exists.
 func (m *MockClient) DatabaseExists(database string) (bool, error) {
 	m.mu.RLock()
@@ -425,6 +563,155 @@ func (m *MockClient) GetDatabaseInfo(database string) (*DatabaseInfo, error) {
 	return &DatabaseInfo{Name: database}, nil
 }
 
+// GetProcessList returns the mock processes.
+func (m *MockClient) GetProcessList() ([]Process, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetProcessList")
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.ProcessErr != nil {
+		return nil, m.ProcessErr
+	}
+
+	return m.Processes, nil
+}
+
+// KillConnection simulates killing a connection, removing it from Processes.
+func (m *MockClient) KillConnection(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recordCall("KillConnection", id)
+
+	if !m.connected {
+		return ErrNotConnected
+	}
+
+	if m.KillErr != nil {
+		return m.KillErr
+	}
+
+	for i, p := range m.Processes {
+		if p.ID == id {
+			m.Processes = append(m.Processes[:i:i], m.Processes[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// GetLockWaits returns the mock lock waits.
+func (m *MockClient) GetLockWaits() ([]Process, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetLockWaits")
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.LockWaitErr != nil {
+		return nil, m.LockWaitErr
+	}
+
+	return m.LockWaits, nil
+}
+
+// GetDatabaseCharset returns the mock charset of a database.
+func (m *MockClient) GetDatabaseCharset(database string) (*Charset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetDatabaseCharset", database)
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.CharsetErr != nil {
+		return nil, m.CharsetErr
+	}
+
+	charset, ok := m.Charsets[database]
+	if !ok {
+		return nil, ErrEmptyResult
+	}
+	return charset, nil
+}
+
+// GetCollations returns the mock collations of a charset.
+func (m *MockClient) GetCollations(charset string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetCollations", charset)
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.CharsetErr != nil {
+		return nil, m.CharsetErr
+	}
+
+	return m.Collations[charset], nil
+}
+
+// GetSchemaObjects returns the mock objects of a database, none if unset.
+func (m *MockClient) GetSchemaObjects(database string) (*SchemaObjects, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.recordCall("GetSchemaObjects", database)
+
+	if !m.connected {
+		return nil, ErrNotConnected
+	}
+
+	if m.ObjectsErr != nil {
+		return nil, m.ObjectsErr
+	}
+
+	if objects, ok := m.Objects[database]; ok {
+		return objects, nil
+	}
+	return &SchemaObjects{}, nil
+}
+
+// RenameTables moves the mock tables as renamed.
+func (m *MockClient) RenameTables(renames []TableRename) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recordCall("RenameTables", renames)
+
+	if !m.connected {
+		return ErrNotConnected
+	}
+
+	if m.RenameErr != nil {
+		return m.RenameErr
+	}
+
+	for _, r := range renames {
+		tables := m.Tables[r.FromDatabase]
+		for i, table := range tables {
+			if table == r.FromTable {
+				m.Tables[r.FromDatabase] = append(tables[:i:i], tables[i+1:]...)
+				break
+			}
+		}
+		m.Tables[r.ToDatabase] = append(m.Tables[r.ToDatabase], r.ToTable)
+	}
+	return nil
+}
+
 // SetConnected allows setting the connection state directly.
 func (m *MockClient) SetConnected(connected bool) {
 	m.mu.Lock()
@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Process is a connection on the server, as listed by SHOW PROCESSLIST.
+type Process struct {
+	ID       int64
+	User     string
+	Host     string
+	Database string // Empty when no database is selected
+	Command  string // e.g. "Query", "Sleep"
+	Time     time.Duration
+	State    string
+	Info     string // The running statement, empty when idle
+}
+
+// Idle reports whether the connection is not running a statement.
+func (p Process) Idle() bool {
+	return p.Command == "Sleep" || p.Command == "Daemon" || p.Info == ""
+}
+
+// processListQuery lists the server's connections other than the client's
+// own. Without the PROCESS privilege only the user's own are listed.
+const processListQuery = `
+	SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO
+	FROM information_schema.PROCESSLIST
+	WHERE ID <> CONNECTION_ID()
+	ORDER BY TIME DESC
+`
+
+// GetProcessList returns the connections on the server, longest running
+// first.
+func (c *Client) GetProcessList() ([]Process, error) {
+	result, err := c.Query(processListQuery)
+	if err != nil {
+		return nil, err
+	}
+	return processes(result), nil
+}
+
+// processes reads the processes of a process list query.
+func processes(result *QueryResult) []Process {
+	list := make([]Process, 0, result.Len())
+	for i := range result.Rows {
+		var p Process
+		p.ID, _ = result.Int64(i, "ID")
+		p.User, _ = result.String(i, "USER")
+		p.Host, _ = result.String(i, "HOST")
+		p.Database, _ = result.String(i, "DB")
+		p.Command, _ = result.String(i, "COMMAND")
+		seconds, _ := result.Int64(i, "TIME")
+		p.Time = time.Duration(seconds) * time.Second
+		p.State, _ = result.String(i, "STATE")
+		p.Info, _ = result.String(i, "INFO")
+		list = append(list, p)
+	}
+	return list
+}
+
+// KillConnection terminates the connection with the given process ID,
+// stopping its running statement.
+func (c *Client) KillConnection(id int64) error {
+	if _, err := c.Execute(fmt.Sprintf("KILL %d", id)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// lockWaitStates are the process list states of statements waiting for a
+// table, metadata or global lock.
+var lockWaitStates = []string{
+	"Waiting for global read lock",
+	"Waiting for table flush",
+	"Waiting for table metadata lock",
+	"Waiting for schema metadata lock",
+	"Waiting for table level lock",
+	"Waiting for commit lock",
+}
+
+// lockWaitQuery lists the connections waiting for a table, metadata or
+// global lock.
+var lockWaitQuery = `
+	SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO
+	FROM information_schema.PROCESSLIST
+	WHERE ID <> CONNECTION_ID() AND STATE IN (?` + strings.Repeat(", ?", len(lockWaitStates)-1) + `)
+`
+
+// rowLockWaitQuery lists the InnoDB transactions waiting for a row lock.
+const rowLockWaitQuery = `
+	SELECT p.ID, p.USER, p.HOST, p.DB, p.COMMAND,
+		TIMESTAMPDIFF(SECOND, t.trx_wait_started, NOW()) AS TIME,
+		'Waiting for row lock' AS STATE, t.trx_query AS INFO
+	FROM information_schema.INNODB_TRX t
+	JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id
+	WHERE t.trx_state = 'LOCK WAIT' AND p.ID <> CONNECTION_ID()
+`
+
+// GetLockWaits returns the connections waiting for a lock: on tables,
+// metadata or the global read lock, as listed in the process list, and on
+// InnoDB rows, as listed in INNODB_TRX. Time is how long they have waited.
+func (c *Client) GetLockWaits() ([]Process, error) {
+	args := make([]interface{}, len(lockWaitStates))
+	for i, state := range lockWaitStates {
+		args[i] = state
+	}
+	result, err := c.Query(lockWaitQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	waits := processes(result)
+
+	result, err = c.Query(rowLockWaitQuery)
+	if err != nil {
+		return nil, err
+	}
+	return append(waits, processes(result)...), nil
+}
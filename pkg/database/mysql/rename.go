@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableRename moves a table to a new name, possibly in another database.
+type TableRename struct {
+	FromDatabase, FromTable string
+	ToDatabase, ToTable     string
+}
+
+// SchemaObjects counts the objects of a database other than base tables.
+// RENAME TABLE cannot move them to another database.
+type SchemaObjects struct {
+	Views    int
+	Triggers int
+	Routines int
+	Events   int
+}
+
+// schemaObjectsQuery counts the views, triggers, routines and events of a
+// database.
+const schemaObjectsQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ?) AS views,
+		(SELECT COUNT(*) FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA = ?) AS triggers,
+		(SELECT COUNT(*) FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA = ?) AS routines,
+		(SELECT COUNT(*) FROM information_schema.EVENTS WHERE EVENT_SCHEMA = ?) AS events
+`
+
+// GetSchemaObjects counts the views, triggers, routines and events of a
+// database.
+func (c *Client) GetSchemaObjects(database string) (*SchemaObjects, error) {
+	if database == "" {
+		return nil, &ConfigError{Field: "database", Message: "database name is required"}
+	}
+	result, err := c.Query(schemaObjectsQuery, database, database, database, database)
+	if err != nil {
+		return nil, err
+	}
+	if result.Len() == 0 {
+		return nil, ErrEmptyResult
+	}
+	objects := &SchemaObjects{}
+	for column, count := range map[string]*int{
+		"views":    &objects.Views,
+		"triggers": &objects.Triggers,
+		"routines": &objects.Routines,
+		"events":   &objects.Events,
+	} {
+		n, _ := result.Int64(0, column)
+		*count = int(n)
+	}
+	return objects, nil
+}
+
+// RenameTables applies renames in a single RENAME TABLE statement, so
+// either all of them take effect at once or none do.
+func (c *Client) RenameTables(renames []TableRename) error {
+	if len(renames) == 0 {
+		return nil
+	}
+	clauses := make([]string, len(renames))
+	for i, r := range renames {
+		clauses[i] = fmt.Sprintf("%s TO %s", qualifiedName(r.FromDatabase, r.FromTable), qualifiedName(r.ToDatabase, r.ToTable))
+	}
+	_, err := c.Execute("RENAME TABLE " + strings.Join(clauses, ", "))
+	return err
+}
+
+// qualifiedName returns `database`.`table` with backquotes in the names
+// escaped.
+func qualifiedName(database, table string) string {
+	quote := func(name string) string {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return quote(database) + "." + quote(table)
+}
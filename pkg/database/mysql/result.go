@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// QueryResult holds the complete result of a SELECT: its column names and
+// one map per row from column name to value. Values are nil for SQL NULL,
+// strings for text and numbers sent as text, and the driver's types
+// (int64, float64, time.Time) otherwise. Use it for small results; stream
+// large ones with QueryStream.
+type QueryResult struct {
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// Len returns the number of rows.
+func (r *QueryResult) Len() int {
+	return len(r.Rows)
+}
+
+// String returns a column of a row as a string, and false for NULL or a
+// missing column.
+func (r *QueryResult) String(row int, column string) (string, bool) {
+	value := r.Rows[row][column]
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Int64 returns a column of a row as an integer, and false for NULL, a
+// missing column or a value that is not a number.
+func (r *QueryResult) Int64(row int, column string) (int64, bool) {
+	switch v := r.Rows[row][column].(type) {
+	case int64:
+		return v, true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// ScanRows reads all rows into a QueryResult and closes rows.
+func ScanRows(rows *sql.Rows) (*QueryResult, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	result := &QueryResult{Columns: columns, Rows: []map[string]interface{}{}}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return result, nil
+}
+
+// Query executes a SELECT query with optional arguments and returns all of
+// its rows.
+func (c *Client) Query(query string, args ...interface{}) (*QueryResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected || c.db == nil {
+		return nil, ErrNotConnected
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, WrapQueryError(query, "query execution failed", err)
+	}
+
+	result, err := ScanRows(rows)
+	if err != nil {
+		return nil, WrapQueryError(query, "failed to read result", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package mysql
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// DefaultDelimiter ends SQL statements until a DELIMITER line changes it.
+const DefaultDelimiter = ";"
+
+// StatementReader returns the statements of a script one at a time, and
+// io.EOF after the last one. StatementScanner implements it.
+type StatementReader interface {
+	Next() (string, error)
+}
+
+// StatementScanner splits an SQL script, such as a mysqldump file, into
+// statements the way the mysql command line client does: delimiters inside
+// quotes, identifiers and comments do not end a statement, `--` and `#`
+// comments are dropped, /* */ comments (including /*!40101 ... */
+// conditional comments) are kept, and DELIMITER lines change the
+// delimiter, as around the triggers and routines of a dump.
+type StatementScanner struct {
+	r         *bufio.Reader
+	delimiter string
+	buf       strings.Builder
+	quote     byte // Open quote character, 0 outside quotes
+	comment   bool // Inside a /* */ comment
+	pending   []string
+	eof       bool
+}
+
+// NewStatementScanner returns a scanner reading the script from r.
+func NewStatementScanner(r io.Reader) *StatementScanner {
+	return &StatementScanner{
+		r:         bufio.NewReaderSize(r, 64*1024),
+		delimiter: DefaultDelimiter,
+	}
+}
+
+// Next returns the next statement without its delimiter, or io.EOF after
+// the last one. A final statement without a delimiter is returned too.
+func (s *StatementScanner) Next() (string, error) {
+	for len(s.pending) == 0 {
+		if s.eof {
+			s.emit()
+			if len(s.pending) == 0 {
+				return "", io.EOF
+			}
+			break
+		}
+
+		line, err := s.r.ReadString('\n')
+		if err == io.EOF {
+			s.eof = true
+		} else if err != nil {
+			return "", err
+		}
+		s.scanLine(line)
+	}
+
+	stmt := s.pending[0]
+	s.pending = s.pending[1:]
+	return stmt, nil
+}
+
+// scanLine adds a line of the script to the current statement, ending
+// statements at delimiters.
+func (s *StatementScanner) scanLine(line string) {
+	if s.quote == 0 && !s.comment && strings.TrimSpace(s.buf.String()) == "" {
+		if fields := strings.Fields(line); len(fields) == 2 && strings.EqualFold(fields[0], "delimiter") {
+			s.delimiter = fields[1]
+			return
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case s.comment:
+			if strings.HasPrefix(line[i:], "*/") {
+				s.comment = false
+				s.buf.WriteString("*/")
+				i++
+				continue
+			}
+		case s.quote != 0:
+			if c == '\\' && s.quote != '`' && i+1 < len(line) {
+				s.buf.WriteString(line[i : i+2])
+				i++
+				continue
+			}
+			if c == s.quote {
+				s.quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			s.quote = c
+		case strings.HasPrefix(line[i:], "/*"):
+			s.comment = true
+			s.buf.WriteString("/*")
+			i++
+			continue
+		case c == '#' || isDashComment(line[i:]):
+			s.buf.WriteByte('\n')
+			return
+		case strings.HasPrefix(line[i:], s.delimiter):
+			s.emit()
+			i += len(s.delimiter) - 1
+			continue
+		}
+		s.buf.WriteByte(c)
+	}
+}
+
+// isDashComment reports whether s starts a `-- ` comment. Like MySQL, the
+// dashes must be followed by whitespace or the end of the line.
+func isDashComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || strings.ContainsRune(" \t\r\n", rune(s[2]))
+}
+
+// emit queues the current statement, unless it is empty.
+func (s *StatementScanner) emit() {
+	if stmt := strings.TrimSpace(s.buf.String()); stmt != "" {
+		s.pending = append(s.pending, stmt)
+	}
+	s.buf.Reset()
+}
@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RowFunc receives the rows of a streamed query. The row is only valid
+// until the function returns: copy the values that must outlive the call.
+// A nil value is SQL NULL. Returning an error stops the query.
+type RowFunc func(columns []string, row []sql.RawBytes) error
+
+// RowIterator reads the rows of a query one at a time, so a large table can
+// be exported without holding the result set in memory. The driver reads
+// each row off the connection when Next is called; the server sends the
+// rest of the result as the client consumes it.
+//
+// The connection is busy until the iterator is closed.
+type RowIterator struct {
+	rows    *sql.Rows
+	columns []string
+	values  []sql.RawBytes
+	dest    []interface{}
+	err     error
+}
+
+// newRowIterator prepares an iterator over rows.
+func newRowIterator(rows *sql.Rows) (*RowIterator, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	return &RowIterator{rows: rows, columns: columns, values: values, dest: dest}, nil
+}
+
+// Columns returns the column names of the result.
+func (it *RowIterator) Columns() []string {
+	return it.columns
+}
+
+// Next reads the next row, returning false at the end of the result or on
+// an error; check Err afterwards.
+func (it *RowIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	if err := it.rows.Scan(it.dest...); err != nil {
+		it.err = fmt.Errorf("failed to scan row: %w", err)
+		return false
+	}
+	return true
+}
+
+// Row returns the current row. Its values are only valid until the next
+// call to Next or Close. A nil value is SQL NULL.
+func (it *RowIterator) Row() []sql.RawBytes {
+	return it.values
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RowIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close releases the connection. It is safe to call more than once.
+func (it *RowIterator) Close() error {
+	return it.rows.Close()
+}
+
+// QueryStream runs a query and returns an iterator over its rows. Unlike
+// ExecuteQuery, the query is not bound by Timeout, as reading a large result
+// can take long; cancel ctx to stop. The caller must close the iterator.
+func (c *Client) QueryStream(ctx context.Context, query string, args ...interface{}) (*RowIterator, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected || c.db == nil {
+		return nil, ErrNotConnected
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, WrapQueryError(query, "query execution failed", err)
+	}
+
+	it, err := newRowIterator(rows)
+	if err != nil {
+		return nil, WrapQueryError(query, "failed to read columns", err)
+	}
+	return it, nil
+}
+
+// ExecuteQueryStream runs a query and calls fn with each row as it is read.
+// It returns the number of rows passed to fn.
+func (c *Client) ExecuteQueryStream(ctx context.Context, query string, fn RowFunc, args ...interface{}) (int64, error) {
+	it, err := c.QueryStream(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return streamRows(query, it, fn)
+}
+
+// streamRows passes the rows of it to fn and closes it.
+func streamRows(query string, it *RowIterator, fn RowFunc) (int64, error) {
+	defer it.Close()
+
+	var count int64
+	for it.Next() {
+		if err := fn(it.Columns(), it.Row()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return count, WrapQueryError(query, "failed to read rows", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,14 @@
+// Package storage manages the on-disk layout of Cadangkan backups: one
+// directory per database holding each backup file next to its
+// <backup-id>.meta.json metadata, optionally grouped by date.
+//
+// LocalStorage lists, loads, locks and deletes backups, and is shared by the
+// backup and restore services of package backup:
+//
+//	stor, err := storage.NewLocalStorage("") // <data dir>/backups
+//	backups, err := stor.ListBackups("mydb")
+//	latest, err := stor.GetLatestBackup("mydb")
+//
+// Metadata that cannot be parsed is moved to a quarantine directory instead
+// of failing the listing; see ListQuarantined.
+package storage
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of stray files
+const (
+	// StrayOrphanedFile is a backup file without metadata
+	StrayOrphanedFile = "orphaned"
+
+	// StrayDanglingMetadata is metadata whose backup file is missing
+	StrayDanglingMetadata = "dangling"
+
+	// StrayTempFile is a temporary file or directory left by an
+	// interrupted write
+	StrayTempFile = "temp"
+)
+
+// backupFileSuffixes are the extensions of backup files.
+var backupFileSuffixes = []string{".sql.gz", ".sql.zst", ".sql", ".tar"}
+
+// StrayFile is a file in a database's backup directory that ListBackups
+// skips, such as the leftovers of a crash.
+type StrayFile struct {
+	Database  string
+	Path      string
+	Kind      string
+	SizeBytes int64
+	ModTime   time.Time
+}
+
+// FindStrayFiles returns the backup files without metadata, the metadata
+// without backup file and the temporary files left by interrupted writes
+// in the backup directory of database. Files modified within minAge are
+// skipped, as they may belong to an operation still in progress.
+// Unreadable metadata is left to ListBackups to quarantine.
+func (s *LocalStorage) FindStrayFiles(database string, minAge time.Duration) ([]StrayFile, error) {
+	dbPath := s.GetDatabasePath(database)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return []StrayFile{}, nil
+	}
+
+	dirs, err := backupDirs(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-minAge)
+	var strays []StrayFile
+	for _, dir := range dirs {
+		found, err := s.findStrayFilesIn(database, dir, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		strays = append(strays, found...)
+	}
+
+	sort.Slice(strays, func(i, j int) bool {
+		return strays[i].Path < strays[j].Path
+	})
+	return strays, nil
+}
+
+// findStrayFilesIn returns the stray files directly in dir.
+func (s *LocalStorage) findStrayFilesIn(database, dir string, cutoff time.Time) ([]StrayFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, &StorageError{
+			Path:    dir,
+			Op:      "read",
+			Message: "failed to read backup directory",
+			Err:     err,
+		}
+	}
+
+	// Backup files referenced by metadata, and the IDs that have metadata
+	// at all, readable or not
+	referenced := make(map[string]bool)
+	withMetadata := make(map[string]bool)
+	var strays []StrayFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".meta.json") {
+			continue
+		}
+		backupID := strings.TrimSuffix(name, ".meta.json")
+		withMetadata[backupID] = true
+
+		metaPath := filepath.Join(dir, name)
+		var meta MetadataStub
+		if err := loadMetadataFile(metaPath, backupID, &meta); err != nil {
+			continue
+		}
+		if meta.Backup.File != "" {
+			referenced[meta.Backup.File] = true
+			if fileExists(filepath.Join(dir, meta.Backup.File)) {
+				continue
+			}
+		}
+		if stray, ok := newStrayFile(database, metaPath, StrayDanglingMetadata); ok && stray.ModTime.Before(cutoff) {
+			strays = append(strays, stray)
+		}
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+
+		var kind string
+		switch {
+		case isTempName(name, entry.IsDir()):
+			kind = StrayTempFile
+		case entry.IsDir() || referenced[name]:
+			continue
+		default:
+			backupID, ok := backupFileID(name)
+			if !ok || withMetadata[backupID] {
+				continue
+			}
+			kind = StrayOrphanedFile
+		}
+
+		stray, ok := newStrayFile(database, path, kind)
+		if ok && stray.ModTime.Before(cutoff) {
+			strays = append(strays, stray)
+		}
+	}
+
+	return strays, nil
+}
+
+// RemoveStrayFile removes a stray file, or a temporary directory with
+// everything in it. Month and year directories of the date layout left
+// empty are removed too.
+func (s *LocalStorage) RemoveStrayFile(stray StrayFile) error {
+	if err := os.RemoveAll(stray.Path); err != nil {
+		return &StorageError{
+			Path:    stray.Path,
+			Op:      "delete",
+			Message: "failed to delete stray file",
+			Err:     err,
+		}
+	}
+
+	dir := filepath.Dir(stray.Path)
+	if dir != s.GetDatabasePath(stray.Database) {
+		if os.Remove(dir) == nil {
+			os.Remove(filepath.Dir(dir))
+		}
+	}
+	return nil
+}
+
+// newStrayFile describes the file at path, summing the sizes of the files
+// in a directory.
+func newStrayFile(database, path, kind string) (StrayFile, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return StrayFile{}, false
+	}
+
+	stray := StrayFile{Database: database, Path: path, Kind: kind, SizeBytes: info.Size(), ModTime: info.ModTime()}
+	if info.IsDir() {
+		stray.SizeBytes = 0
+		filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					stray.SizeBytes += info.Size()
+				}
+			}
+			return nil
+		})
+	}
+	return stray, true
+}
+
+// isTempName reports whether name is a temporary file or directory:
+// atomic writes of metadata, mydumper output being packed, archives being
+// extracted and backups being rewritten by redact.
+func isTempName(name string, dir bool) bool {
+	if dir {
+		return strings.HasPrefix(name, ".mydumper-") || strings.HasPrefix(name, ".myloader-")
+	}
+	return (strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-")) || strings.HasSuffix(name, ".redact")
+}
+
+// backupFileID returns the backup ID of a backup file name.
+func backupFileID(name string) (string, bool) {
+	for _, suffix := range backupFileSuffixes {
+		if id, ok := strings.CutSuffix(name, suffix); ok && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
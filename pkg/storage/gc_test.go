@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindStrayFiles(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := NewLocalStorage(baseDir)
+	require.NoError(t, err)
+
+	dbPath := filepath.Join(baseDir, "shop")
+	old := time.Now().Add(-2 * time.Hour)
+	writeFile := func(name string) string {
+		path := filepath.Join(dbPath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+		require.NoError(t, os.Chtimes(path, old, old))
+		return path
+	}
+
+	writeBackup(t, s, "shop", "2025-01-01-020000", old)
+	writeBackup(t, s, "shop", "2025-01-02-020000", old)
+	dangling := s.GetMetadataPath("shop", "2025-01-02-020000")
+	require.NoError(t, os.Remove(s.GetBackupPath("shop", "2025-01-02-020000", CompressionGzip)))
+	require.NoError(t, os.Chtimes(dangling, old, old))
+
+	orphaned := writeFile("2025-01-03-020000.sql.gz")
+	datedOrphan := writeFile(filepath.Join("2025", "01", "2025-01-04-020000.tar"))
+	tempMeta := writeFile(".2025-01-01-020000.meta.json.tmp-123")
+	redacting := writeFile("2025-01-01-020000.sql.gz.redact")
+	tempDir := filepath.Join(dbPath, ".mydumper-123")
+	require.NoError(t, os.Mkdir(tempDir, 0755))
+	writeFile(filepath.Join(".mydumper-123", "shop.users.sql"))
+	require.NoError(t, os.Chtimes(tempDir, old, old))
+
+	// Recent files may belong to a backup in progress; unknown files and
+	// files of unreadable metadata are not touched
+	writeBackupFile := filepath.Join(dbPath, "2025-01-05-020000.sql.gz")
+	require.NoError(t, os.WriteFile(writeBackupFile, []byte("data"), 0644))
+	writeFile("notes.txt")
+	writeFile("2025-01-06-020000.sql")
+	writeFile("2025-01-06-020000.meta.json")
+
+	strays, err := s.FindStrayFiles("shop", time.Hour)
+	require.NoError(t, err)
+
+	kinds := make(map[string]string)
+	for _, stray := range strays {
+		assert.Equal(t, "shop", stray.Database)
+		kinds[stray.Path] = stray.Kind
+	}
+	assert.Equal(t, map[string]string{
+		dangling:    StrayDanglingMetadata,
+		orphaned:    StrayOrphanedFile,
+		datedOrphan: StrayOrphanedFile,
+		tempMeta:    StrayTempFile,
+		redacting:   StrayTempFile,
+		tempDir:     StrayTempFile,
+	}, kinds)
+
+	for _, stray := range strays {
+		if stray.Path == tempDir {
+			assert.Equal(t, int64(4), stray.SizeBytes)
+		}
+		require.NoError(t, s.RemoveStrayFile(stray))
+	}
+	assert.NoFileExists(t, orphaned)
+	assert.NoDirExists(t, tempDir)
+	assert.NoDirExists(t, filepath.Join(dbPath, "2025"))
+	assert.FileExists(t, writeBackupFile)
+
+	backups, err := s.ListBackups("shop")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "2025-01-01-020000", backups[0].BackupID)
+
+	strays, err = s.FindStrayFiles("missing", time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, strays)
+}
@@ -0,0 +1,659 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erickhilda/cadangkan/internal/fsutil"
+	"github.com/erickhilda/cadangkan/internal/paths"
+)
+
+// LocalStorage manages local file system storage for backups.
+type LocalStorage struct {
+	// basePath is the base directory for all backups
+	// Default: <data dir>/backups, see internal/paths
+	basePath string
+
+	// layout controls where new backups are placed (LayoutFlat or LayoutDate)
+	layout string
+}
+
+// NewLocalStorage creates a new LocalStorage instance.
+// If basePath is empty, uses the backups directory in the data directory
+// (see internal/paths).
+func NewLocalStorage(basePath string) (*LocalStorage, error) {
+	if basePath == "" {
+		var err error
+		if basePath, err = paths.DataFile("backups"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LocalStorage{
+		basePath: basePath,
+		layout:   LayoutFlat,
+	}, nil
+}
+
+// SetLayout sets the directory layout used for new backups.
+// Existing backups are found in either layout.
+func (s *LocalStorage) SetLayout(layout string) error {
+	switch layout {
+	case "":
+		s.layout = LayoutFlat
+	case LayoutFlat, LayoutDate:
+		s.layout = layout
+	default:
+		return fmt.Errorf("unsupported storage layout: %s (use '%s' or '%s')", layout, LayoutFlat, LayoutDate)
+	}
+	return nil
+}
+
+// GetLayout returns the directory layout used for new backups.
+func (s *LocalStorage) GetLayout() string {
+	return s.layout
+}
+
+// At returns storage in the same layout rooted at basePath, such as the
+// backup directory configured for a single database. An empty basePath or
+// the storage's own returns the storage itself.
+func (s *LocalStorage) At(basePath string) *LocalStorage {
+	if basePath == "" || filepath.Clean(basePath) == filepath.Clean(s.basePath) {
+		return s
+	}
+	return &LocalStorage{basePath: basePath, layout: s.layout}
+}
+
+// GetBasePath returns the base path for backups.
+func (s *LocalStorage) GetBasePath() string {
+	return s.basePath
+}
+
+// GetDatabasePath returns the directory path for a specific database.
+func (s *LocalStorage) GetDatabasePath(database string) string {
+	return filepath.Join(s.basePath, database)
+}
+
+// EnsureDatabaseDir ensures the backup directory for a database exists.
+func (s *LocalStorage) EnsureDatabaseDir(database string) error {
+	dbPath := s.GetDatabasePath(database)
+	if err := os.MkdirAll(dbPath, 0755); err != nil {
+		return &StorageError{
+			Path:    dbPath,
+			Op:      "create",
+			Message: "failed to create database directory",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// CheckDiskSpace checks available disk space at the base path.
+func (s *LocalStorage) CheckDiskSpace() (uint64, error) {
+	available, err := checkDiskSpace(s.basePath)
+	if err != nil {
+		return 0, &StorageError{
+			Path:    s.basePath,
+			Op:      "check",
+			Message: "failed to check disk space",
+			Err:     err,
+		}
+	}
+	return available, nil
+}
+
+// HasEnoughSpace checks if there's enough space for estimated backup size.
+func (s *LocalStorage) HasEnoughSpace(estimatedSize int64) (bool, error) {
+	available, err := s.CheckDiskSpace()
+	if err != nil {
+		return false, err
+	}
+
+	// Require estimated size plus 20% buffer
+	requiredSize := uint64(float64(estimatedSize) * 1.2)
+	return available >= requiredSize, nil
+}
+
+// GetBackupDir returns the directory holding a backup's files.
+// Backups that already exist are found in whichever layout they were
+// written with; new backups use the configured layout.
+func (s *LocalStorage) GetBackupDir(database, backupID string) string {
+	dbPath := s.GetDatabasePath(database)
+	datePath := dateDir(dbPath, backupID)
+	if datePath == "" {
+		return dbPath
+	}
+
+	preferred, fallback := dbPath, datePath
+	if s.layout == LayoutDate {
+		preferred, fallback = datePath, dbPath
+	}
+
+	metaName := backupID + ".meta.json"
+	if !fileExists(filepath.Join(preferred, metaName)) && fileExists(filepath.Join(fallback, metaName)) {
+		return fallback
+	}
+	return preferred
+}
+
+// EnsureBackupDir ensures the directory for a backup exists.
+func (s *LocalStorage) EnsureBackupDir(database, backupID string) error {
+	dir := s.GetBackupDir(database, backupID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return &StorageError{
+			Path:    dir,
+			Op:      "create",
+			Message: "failed to create backup directory",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// GetBackupPath returns the full path for a backup file.
+func (s *LocalStorage) GetBackupPath(database, backupID, compression string) string {
+	return getBackupFilePath(s.GetBackupDir(database, backupID), backupID, compression)
+}
+
+// GetArchivePath returns the full path for a multi-file backup archive.
+func (s *LocalStorage) GetArchivePath(database, backupID string) string {
+	return filepath.Join(s.GetBackupDir(database, backupID), backupID+".tar")
+}
+
+// GetMetadataPath returns the full path for a metadata file.
+func (s *LocalStorage) GetMetadataPath(database, backupID string) string {
+	return filepath.Join(s.GetBackupDir(database, backupID), backupID+".meta.json")
+}
+
+// ListBackups lists all backups for a database.
+// Both the flat layout and {yyyy}/{mm} subdirectories are scanned.
+func (s *LocalStorage) ListBackups(database string) ([]BackupListEntry, error) {
+	dbPath := s.GetDatabasePath(database)
+
+	// Check if directory exists
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return []BackupListEntry{}, nil
+	}
+
+	dirs, err := backupDirs(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []BackupListEntry
+	for _, dir := range dirs {
+		entries, err := s.listBackupsIn(database, dir)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, entries...)
+	}
+
+	// Sort by creation time (newest first), breaking ties by ID so the
+	// order is stable across runs
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		return backups[i].BackupID > backups[j].BackupID
+	})
+
+	return backups, nil
+}
+
+// listBackupsIn lists the backups whose metadata lives directly in dir.
+func (s *LocalStorage) listBackupsIn(database, dir string) ([]BackupListEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, &StorageError{
+			Path:    dir,
+			Op:      "read",
+			Message: "failed to read backup directory",
+			Err:     err,
+		}
+	}
+
+	// Find all metadata files
+	var backups []BackupListEntry
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".meta.json") {
+			continue
+		}
+
+		// Parse metadata
+		metaPath := filepath.Join(dir, name)
+		var meta MetadataStub
+		err := loadMetadataFile(metaPath, strings.TrimSuffix(name, ".meta.json"), &meta)
+		if err != nil {
+			// Move corrupt metadata aside so it is not silently ignored
+			var metaErr *MetadataError
+			if errors.As(err, &metaErr) {
+				s.quarantineMetadata(database, metaPath, err)
+			}
+			continue
+		}
+
+		// Find the backup file
+		backupPath := filepath.Join(dir, meta.Backup.File)
+		fileInfo, err := os.Stat(backupPath)
+		if err != nil {
+			// Backup file missing, skip
+			continue
+		}
+
+		backups = append(backups, BackupListEntry{
+			BackupID:     meta.BackupID,
+			Database:     database,
+			CreatedAt:    meta.CreatedAt,
+			SizeBytes:    fileInfo.Size(),
+			SizeHuman:    meta.Backup.SizeHuman,
+			Status:       meta.Status,
+			FilePath:     backupPath,
+			MetadataPath: metaPath,
+			Lock:         meta.Lock,
+		})
+	}
+
+	return backups, nil
+}
+
+// UsageBytes returns the total size of all backups for a database.
+func (s *LocalStorage) UsageBytes(database string) (int64, error) {
+	backups, err := s.ListBackups(database)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, b := range backups {
+		total += b.SizeBytes
+	}
+	return total, nil
+}
+
+// ListDatabases returns the names of the databases with a backup directory.
+func (s *LocalStorage) ListDatabases() ([]string, error) {
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, &StorageError{
+			Path:    s.basePath,
+			Op:      "read",
+			Message: "failed to read backup directory",
+			Err:     err,
+		}
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// ListDatabasesIn returns the databases with backups in the storage or
+// in the storage rooted at any of paths, sorted and without duplicates.
+func (s *LocalStorage) ListDatabasesIn(paths []string) ([]string, error) {
+	names, err := s.ListDatabases()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		other := s.At(path)
+		if other == s {
+			continue
+		}
+		more, err := other.ListDatabases()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, more...)
+	}
+
+	sort.Strings(names)
+	return slices.Compact(names), nil
+}
+
+// TotalUsageBytes returns the total size of all backups across all databases.
+func (s *LocalStorage) TotalUsageBytes() (int64, error) {
+	names, err := s.ListDatabases()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, name := range names {
+		usage, err := s.UsageBytes(name)
+		if err != nil {
+			return 0, err
+		}
+		total += usage
+	}
+	return total, nil
+}
+
+// GetQuarantinePath returns the directory holding unreadable metadata
+// files moved aside for a database.
+func (s *LocalStorage) GetQuarantinePath(database string) string {
+	return filepath.Join(s.GetDatabasePath(database), QuarantineDirName)
+}
+
+// ListQuarantined returns the names of quarantined metadata files for a database.
+func (s *LocalStorage) ListQuarantined(database string) ([]string, error) {
+	entries, err := os.ReadDir(s.GetQuarantinePath(database))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, &StorageError{
+			Path:    s.GetQuarantinePath(database),
+			Op:      "read",
+			Message: "failed to read quarantine directory",
+			Err:     err,
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// quarantineMetadata moves an unreadable metadata file into the quarantine
+// directory and reports it on stderr.
+func (s *LocalStorage) quarantineMetadata(database, metaPath string, cause error) {
+	quarantineDir := s.GetQuarantinePath(database)
+	if err := os.MkdirAll(quarantineDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unreadable metadata %s: %v (failed to quarantine: %v)\n", metaPath, cause, err)
+		return
+	}
+
+	target := filepath.Join(quarantineDir, filepath.Base(metaPath)+"."+time.Now().UTC().Format("20060102T150405"))
+	if err := os.Rename(metaPath, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unreadable metadata %s: %v (failed to quarantine: %v)\n", metaPath, cause, err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: unreadable metadata %s moved to %s: %v\n", metaPath, target, cause)
+}
+
+// SaveMetadata saves backup metadata to a JSON file.
+// metadata should be a struct that can be marshaled to JSON.
+// The file is replaced atomically, so a crash never leaves it half-written.
+func (s *LocalStorage) SaveMetadata(database string, backupID string, metadata interface{}) error {
+	metaPath := s.GetMetadataPath(database, backupID)
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return &MetadataError{
+			BackupID: backupID,
+			Message:  "failed to marshal metadata",
+			Err:      err,
+		}
+	}
+
+	if err := fsutil.WriteFileAtomic(metaPath, data, 0644); err != nil {
+		return &StorageError{
+			Path:    metaPath,
+			Op:      "write",
+			Message: "failed to write metadata file",
+			Err:     err,
+		}
+	}
+
+	return nil
+}
+
+// LoadMetadata loads backup metadata from a JSON file into the provided struct.
+// result should be a pointer to a struct that can be unmarshaled from JSON.
+func (s *LocalStorage) LoadMetadata(database, backupID string, result interface{}) error {
+	return loadMetadataFile(s.GetMetadataPath(database, backupID), backupID, result)
+}
+
+// loadMetadataFile loads the metadata file at metaPath into result.
+func loadMetadataFile(metaPath, backupID string, result interface{}) error {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrBackupNotFound
+		}
+		return &StorageError{
+			Path:    metaPath,
+			Op:      "read",
+			Message: "failed to read metadata file",
+			Err:     err,
+		}
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return &MetadataError{
+			BackupID: backupID,
+			Message:  "failed to unmarshal metadata",
+			Err:      err,
+		}
+	}
+
+	return nil
+}
+
+// DeleteBackup deletes a backup and its metadata.
+func (s *LocalStorage) DeleteBackup(database, backupID string) error {
+	// Load metadata to get backup file name
+	var meta MetadataStub
+	err := s.LoadMetadata(database, backupID, &meta)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to delete locked backups
+	if meta.Lock.Active(time.Now()) {
+		return &BackupLockedError{BackupID: backupID, Until: meta.Lock.Until}
+	}
+
+	// Delete backup file
+	backupDir := s.GetBackupDir(database, backupID)
+	backupPath := filepath.Join(backupDir, meta.Backup.File)
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return &StorageError{
+			Path:    backupPath,
+			Op:      "delete",
+			Message: "failed to delete backup file",
+			Err:     err,
+		}
+	}
+
+	// Delete metadata file
+	metaPath := filepath.Join(backupDir, backupID+".meta.json")
+	if err := os.Remove(metaPath); err != nil && !os.IsNotExist(err) {
+		return &StorageError{
+			Path:    metaPath,
+			Op:      "delete",
+			Message: "failed to delete metadata file",
+			Err:     err,
+		}
+	}
+
+	// Remove month and year directories left empty by the date layout
+	if backupDir != s.GetDatabasePath(database) {
+		if os.Remove(backupDir) == nil {
+			os.Remove(filepath.Dir(backupDir))
+		}
+	}
+
+	return nil
+}
+
+// SetBackupLock sets or clears the lock on a backup.
+// A nil lock removes any existing lock. Other metadata fields are preserved.
+func (s *LocalStorage) SetBackupLock(database, backupID string, lock *LockInfo) error {
+	var fields map[string]json.RawMessage
+	if err := s.LoadMetadata(database, backupID, &fields); err != nil {
+		return err
+	}
+
+	if lock == nil {
+		delete(fields, "lock")
+	} else {
+		data, err := json.Marshal(lock)
+		if err != nil {
+			return &MetadataError{
+				BackupID: backupID,
+				Message:  "failed to marshal lock",
+				Err:      err,
+			}
+		}
+		fields["lock"] = data
+	}
+
+	return s.SaveMetadata(database, backupID, fields)
+}
+
+// CleanupPartialBackup removes a partial backup (both file and metadata if they exist).
+func (s *LocalStorage) CleanupPartialBackup(database, backupID, compression string) error {
+	// Try to delete backup file
+	backupPath := s.GetBackupPath(database, backupID, compression)
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		// Log but don't fail on cleanup errors
+		fmt.Fprintf(os.Stderr, "Warning: failed to cleanup backup file %s: %v\n", backupPath, err)
+	}
+
+	// Try to delete metadata file
+	metaPath := s.GetMetadataPath(database, backupID)
+	if err := os.Remove(metaPath); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cleanup metadata file %s: %v\n", metaPath, err)
+	}
+
+	return nil
+}
+
+// GetLatestBackup returns the most recent backup for a database.
+func (s *LocalStorage) GetLatestBackup(database string) (*BackupListEntry, error) {
+	backups, err := s.ListBackups(database)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) == 0 {
+		return nil, ErrBackupNotFound
+	}
+
+	return &backups[0], nil
+}
+
+// Helper functions
+
+// dateDir returns the {yyyy}/{mm} directory for a backup ID under dbPath,
+// or empty if the ID does not start with a date.
+func dateDir(dbPath, backupID string) string {
+	if len(backupID) < 7 || backupID[4] != '-' || !isDigits(backupID[:4]) || !isDigits(backupID[5:7]) {
+		return ""
+	}
+	return filepath.Join(dbPath, backupID[:4], backupID[5:7])
+}
+
+// backupDirs returns dbPath and any {yyyy}/{mm} subdirectories beneath it.
+func backupDirs(dbPath string) ([]string, error) {
+	dirs := []string{dbPath}
+
+	years, err := os.ReadDir(dbPath)
+	if err != nil {
+		return nil, &StorageError{
+			Path:    dbPath,
+			Op:      "read",
+			Message: "failed to read backup directory",
+			Err:     err,
+		}
+	}
+
+	for _, year := range years {
+		if !year.IsDir() || len(year.Name()) != 4 || !isDigits(year.Name()) {
+			continue
+		}
+		yearPath := filepath.Join(dbPath, year.Name())
+		months, err := os.ReadDir(yearPath)
+		if err != nil {
+			continue
+		}
+		for _, month := range months {
+			if month.IsDir() && len(month.Name()) == 2 && isDigits(month.Name()) {
+				dirs = append(dirs, filepath.Join(yearPath, month.Name()))
+			}
+		}
+	}
+
+	return dirs, nil
+}
+
+// isDigits reports whether s consists only of ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func getBackupFilePath(backupDir, backupID, compression string) string {
+	var ext string
+	switch compression {
+	case CompressionGzip:
+		ext = ".sql.gz"
+	case CompressionZstd:
+		ext = ".sql.zst"
+	case CompressionNone:
+		ext = ".sql"
+	default:
+		ext = ".sql.gz"
+	}
+
+	return filepath.Join(backupDir, backupID+ext)
+}
+
+func checkDiskSpace(path string) (uint64, error) {
+	// Try to stat the path
+	_, err := os.Stat(path)
+	if err != nil {
+		// If path doesn't exist, try parent directory
+		if os.IsNotExist(err) {
+			parentPath := filepath.Dir(path)
+			_, err = os.Stat(parentPath)
+			if err != nil {
+				return 0, err
+			}
+			// Use parent path for disk space check
+			return getDiskSpace(parentPath)
+		}
+		return 0, err
+	}
+
+	// Get filesystem stats using syscall
+	// Note: This is platform-specific (Linux/Unix)
+	// For cross-platform support, we'd need build tags
+	return getDiskSpace(path)
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBackup creates a backup file and metadata stub in the storage's
+// directory for backupID.
+func writeBackup(t *testing.T, s *LocalStorage, database, backupID string, createdAt time.Time) {
+	require.NoError(t, s.EnsureBackupDir(database, backupID))
+
+	backupPath := s.GetBackupPath(database, backupID, CompressionGzip)
+	require.NoError(t, os.WriteFile(backupPath, []byte("data"), 0644))
+
+	var meta MetadataStub
+	meta.BackupID = backupID
+	meta.CreatedAt = createdAt
+	meta.Status = "completed"
+	meta.Backup.File = filepath.Base(backupPath)
+	require.NoError(t, s.SaveMetadata(database, backupID, meta))
+}
+
+func TestSetLayout(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, LayoutFlat, s.GetLayout())
+
+	require.NoError(t, s.SetLayout(LayoutDate))
+	assert.Equal(t, LayoutDate, s.GetLayout())
+
+	require.NoError(t, s.SetLayout(""))
+	assert.Equal(t, LayoutFlat, s.GetLayout())
+
+	assert.Error(t, s.SetLayout("hourly"))
+}
+
+func TestAt(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.SetLayout(LayoutDate))
+
+	assert.Same(t, s, s.At(""))
+	assert.Same(t, s, s.At(s.GetBasePath()+"/"))
+
+	other := s.At("/srv/backups")
+	assert.Equal(t, "/srv/backups", other.GetBasePath())
+	assert.Equal(t, LayoutDate, other.GetLayout())
+}
+
+func TestListDatabasesIn(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	other := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(s.GetDatabasePath("shop"), 0755))
+	require.NoError(t, os.MkdirAll(s.GetDatabasePath("blog"), 0755))
+	require.NoError(t, os.MkdirAll(s.At(other).GetDatabasePath("shop"), 0755))
+	require.NoError(t, os.MkdirAll(s.At(other).GetDatabasePath("orders"), 0755))
+
+	names, err := s.ListDatabasesIn([]string{other, s.GetBasePath(), filepath.Join(other, "missing")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blog", "orders", "shop"}, names)
+}
+
+func TestDateLayout(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := NewLocalStorage(baseDir)
+	require.NoError(t, err)
+
+	// A backup written before switching layouts stays where it is
+	now := time.Now()
+	writeBackup(t, s, "shop", "2024-12-31-230000", now.Add(-time.Hour))
+	require.NoError(t, s.SetLayout(LayoutDate))
+	writeBackup(t, s, "shop", "2025-01-02-143022.123Z", now)
+
+	assert.FileExists(t, filepath.Join(baseDir, "shop", "2024-12-31-230000.meta.json"))
+	assert.FileExists(t, filepath.Join(baseDir, "shop", "2025", "01", "2025-01-02-143022.123Z.meta.json"))
+	assert.Equal(t, filepath.Join(baseDir, "shop"), s.GetBackupDir("shop", "2024-12-31-230000"))
+
+	// Stray directories are ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "shop", "tmp"), 0755))
+
+	backups, err := s.ListBackups("shop")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "2025-01-02-143022.123Z", backups[0].BackupID)
+	assert.Equal(t, "2024-12-31-230000", backups[1].BackupID)
+
+	// Deleting the last backup of a month removes the empty directories
+	require.NoError(t, s.DeleteBackup("shop", "2025-01-02-143022.123Z"))
+	assert.NoDirExists(t, filepath.Join(baseDir, "shop", "2025"))
+
+	require.NoError(t, s.DeleteBackup("shop", "2024-12-31-230000"))
+	backups, err = s.ListBackups("shop")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestListBackupsSortTieBreak(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	createdAt := time.Date(2025, 1, 2, 14, 30, 22, 0, time.UTC)
+	writeBackup(t, s, "shop", "2025-01-02-143022.001Z", createdAt)
+	writeBackup(t, s, "shop", "2025-01-02-143022.002Z", createdAt)
+
+	backups, err := s.ListBackups("shop")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "2025-01-02-143022.002Z", backups[0].BackupID)
+}
+
+func TestSetBackupLockPreservesFields(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	writeBackup(t, s, "shop", "2025-01-02-143022.123Z", time.Now())
+
+	require.NoError(t, s.SetBackupLock("shop", "2025-01-02-143022.123Z", &LockInfo{LockedAt: time.Now(), Reason: "audit"}))
+
+	data, err := os.ReadFile(s.GetMetadataPath("shop", "2025-01-02-143022.123Z"))
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Contains(t, fields, "backup")
+	assert.Contains(t, fields, "lock")
+}
+
+func TestListBackupsQuarantinesCorruptMetadata(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := NewLocalStorage(baseDir)
+	require.NoError(t, err)
+
+	writeBackup(t, s, "shop", "2025-01-02-143022.123Z", time.Now())
+
+	// Simulate metadata truncated by a crash
+	corruptPath := filepath.Join(baseDir, "shop", "2025-01-01-120000.meta.json")
+	require.NoError(t, os.WriteFile(corruptPath, []byte(`{"backup_id": "2025-01`), 0644))
+
+	backups, err := s.ListBackups("shop")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "2025-01-02-143022.123Z", backups[0].BackupID)
+
+	assert.NoFileExists(t, corruptPath)
+	quarantined, err := s.ListQuarantined("shop")
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	assert.Contains(t, quarantined[0], "2025-01-01-120000.meta.json")
+
+	// The quarantine directory is not scanned for backups
+	backups, err = s.ListBackups("shop")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestSaveMetadataLeavesNoTempFiles(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := NewLocalStorage(baseDir)
+	require.NoError(t, err)
+
+	writeBackup(t, s, "shop", "2025-01-02-143022.123Z", time.Now())
+	require.NoError(t, s.SaveMetadata("shop", "2025-01-02-143022.123Z", map[string]string{"status": "completed"}))
+
+	entries, err := os.ReadDir(filepath.Join(baseDir, "shop"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp-")
+	}
+}
+
+func TestListDatabases(t *testing.T) {
+	s, err := NewLocalStorage(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	names, err := s.ListDatabases()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	baseDir := t.TempDir()
+	s, err = NewLocalStorage(baseDir)
+	require.NoError(t, err)
+	writeBackup(t, s, "shop", "2025-01-02-143022.123Z", time.Now())
+	writeBackup(t, s, "production.blog", "2025-01-02-143022.123Z", time.Now())
+	require.NoError(t, os.Mkdir(filepath.Join(baseDir, ".tmp"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "notes.txt"), nil, 0644))
+
+	names, err = s.ListDatabases()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"shop", "production.blog"}, names)
+}
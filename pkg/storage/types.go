@@ -31,6 +31,29 @@ type BackupListEntry struct {
 
 	// MetadataPath is the full path to the metadata file
 	MetadataPath string
+
+	// Lock protects the backup from deletion, if set
+	Lock *LockInfo
+}
+
+// LockInfo marks a backup as protected from prune and delete.
+type LockInfo struct {
+	// LockedAt is when the lock was placed
+	LockedAt time.Time `json:"locked_at"`
+
+	// Until is when the lock expires; nil means it never expires
+	Until *time.Time `json:"until,omitempty"`
+
+	// Reason is an optional note explaining the lock
+	Reason string `json:"reason,omitempty"`
+}
+
+// Active reports whether the lock still protects the backup at now.
+func (l *LockInfo) Active(now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	return l.Until == nil || now.Before(*l.Until)
 }
 
 // MetadataStub is a minimal representation of metadata for listing.
@@ -42,6 +65,7 @@ type MetadataStub struct {
 		File      string `json:"file"`
 		SizeHuman string `json:"size_human"`
 	} `json:"backup"`
+	Lock *LockInfo `json:"lock,omitempty"`
 }
 
 // Constants for compression types
@@ -51,6 +75,19 @@ const (
 	CompressionNone = "none"
 )
 
+// Constants for storage layouts
+const (
+	// LayoutFlat stores every backup directly in {database}/
+	LayoutFlat = "flat"
+
+	// LayoutDate stores backups in {database}/{yyyy}/{mm}/
+	LayoutDate = "date"
+)
+
+// QuarantineDirName is the per-database directory that unreadable
+// metadata files are moved to.
+const QuarantineDirName = ".quarantine"
+
 // Common errors
 var (
 	ErrBackupNotFound = errors.New("backup not found")
@@ -92,3 +129,22 @@ func (e *MetadataError) Error() string {
 func (e *MetadataError) Unwrap() error {
 	return e.Err
 }
+
+// BackupLockedError is returned when deleting a backup that is locked.
+type BackupLockedError struct {
+	BackupID string
+	Until    *time.Time
+}
+
+func (e *BackupLockedError) Error() string {
+	if e.Until != nil {
+		return fmt.Sprintf("backup %s is locked until %s", e.BackupID, e.Until.Format("2006-01-02 15:04:05"))
+	}
+	return fmt.Sprintf("backup %s is locked", e.BackupID)
+}
+
+// IsBackupLocked checks if an error is a BackupLockedError.
+func IsBackupLocked(err error) bool {
+	var lockedErr *BackupLockedError
+	return errors.As(err, &lockedErr)
+}